@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -373,6 +374,34 @@ func (hs *HTTPServer) AdminLogoutUser(c *contextmodel.ReqContext) response.Respo
 	return hs.logoutUserFromAllDevicesInternal(c.Req.Context(), userID)
 }
 
+// swagger:route DELETE /admin/users/{user_id}/mfa admin_users adminResetUserMFA
+//
+// Remove the two-factor authentication of a user, for instance when they lost both their device and their recovery codes.
+// The user signs in with their password only on their next login, unless one of their organizations requires two-factor authentication.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:write` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminResetUserMFA(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := hs.mfaService.Reset(c.Req.Context(), userID); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to reset two-factor authentication", err)
+	}
+
+	return response.Success("Two-factor authentication reset")
+}
+
 // swagger:route GET /admin/users/{user_id}/auth-tokens admin_users adminGetUserAuthTokens
 //
 // Return a list of all auth tokens (devices) that the user currently have logged in from.
@@ -423,6 +452,38 @@ func (hs *HTTPServer) AdminRevokeUserAuthToken(c *contextmodel.ReqContext) respo
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route POST /admin/revoke-auth-tokens admin_users adminRevokeAuthTokens
+//
+// Revoke the auth tokens matching criteria.
+//
+// Revokes the auth tokens (devices) of the members of an organization, the auth tokens created more than a number of days ago, or the auth tokens matching both criteria. The auth token used by the request is kept. Users of the revoked auth tokens (devices) will no longer be logged in and will be required to authenticate again upon next activity.
+// If you are running Grafana Enterprise and have Fine-grained access control enabled, you need to have a permission with action `users:logout` and scope `global.users:*`.
+//
+// Security:
+// - basic:
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) AdminRevokeAuthTokens(c *contextmodel.ReqContext) response.Response {
+	form := dtos.AdminRevokeAuthTokensCommand{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if form.OlderThanDays < 0 {
+		return response.Error(http.StatusBadRequest, "olderThanDays must be positive", nil)
+	}
+
+	cmd := &auth.RevokeTokensCmd{OrgID: form.OrgID}
+	if form.OlderThanDays > 0 {
+		cmd.CreatedBefore = time.Now().AddDate(0, 0, -form.OlderThanDays)
+	}
+	return hs.revokeAuthTokensInternal(c, cmd)
+}
+
 // swagger:parameters adminUpdateUserPassword
 type AdminUpdateUserPasswordParams struct {
 	// in:body
@@ -433,6 +494,13 @@ type AdminUpdateUserPasswordParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:parameters adminRevokeAuthTokens
+type AdminRevokeAuthTokensParams struct {
+	// in:body
+	// required:true
+	Body dtos.AdminRevokeAuthTokensCommand `json:"body"`
+}
+
 // swagger:parameters adminDeleteUser
 type AdminDeleteUserParams struct {
 	// in:path
@@ -454,6 +522,13 @@ type AdminDisableUserParams struct {
 	UserID int64 `json:"user_id"`
 }
 
+// swagger:parameters adminResetUserMFA
+type AdminResetUserMFAParams struct {
+	// in:path
+	// required:true
+	UserID int64 `json:"user_id"`
+}
+
 // swagger:parameters adminGetUserAuthTokens
 type AdminGetUserAuthTokensParams struct {
 	// in:path
@@ -1,9 +1,12 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -16,15 +19,18 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/login/social/socialtest"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/authtest"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
 )
 
 const (
@@ -349,6 +355,98 @@ func Test_AdminUpdateUserPermissions(t *testing.T) {
 	}
 }
 
+func Test_AdminResetUserMFA(t *testing.T) {
+	mfaService := &mfatest.FakeService{}
+	hs := &HTTPServer{Cfg: setting.NewCfg(), mfaService: mfaService}
+
+	sc := setupScenarioContext(t, "/api/admin/users/1/mfa")
+	sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+		sc.context = c
+		return hs.AdminResetUserMFA(c)
+	})
+	sc.m.Delete("/api/admin/users/:id/mfa", sc.defaultHandler)
+
+	sc.fakeReqWithParams("DELETE", sc.url, map[string]string{}).exec()
+
+	assert.Equal(t, http.StatusOK, sc.resp.Code)
+	assert.True(t, mfaService.ResetCalled)
+}
+
+func Test_AdminRevokeAuthTokens(t *testing.T) {
+	tests := []struct {
+		desc         string
+		body         string
+		permissions  []accesscontrol.Permission
+		expectedCode int
+		expectedCmd  *auth.RevokeTokensCmd
+		expectedAge  int
+	}{
+		{
+			desc:         "should revoke sessions of an org",
+			body:         `{"orgId": 2}`,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersLogout, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusOK,
+			expectedCmd:  &auth.RevokeTokensCmd{OrgID: 2},
+		},
+		{
+			desc:         "should revoke old sessions",
+			body:         `{"olderThanDays": 30}`,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersLogout, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusOK,
+			expectedAge:  30,
+		},
+		{
+			desc:         "should fail with a negative age",
+			body:         `{"olderThanDays": -1}`,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersLogout, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should fail without criteria",
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersLogout, Scope: accesscontrol.ScopeGlobalUsersAll}},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should fail without permission",
+			body:         `{"orgId": 2}`,
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var revoked *auth.RevokeTokensCmd
+			server := SetupAPITestServer(t, func(hs *HTTPServer) {
+				hs.Cfg = setting.NewCfg()
+				hs.AuthTokenService = &authtest.FakeUserAuthTokenService{
+					RevokeTokensProvider: func(ctx context.Context, cmd *auth.RevokeTokensCmd) (int64, error) {
+						revoked = cmd
+						if cmd.OrgID == 0 && cmd.CreatedBefore.IsZero() {
+							return 0, auth.ErrRevokeTokensNoFilter
+						}
+						return 3, nil
+					},
+				}
+			})
+
+			req := server.NewPostRequest("/api/admin/revoke-auth-tokens", strings.NewReader(tt.body))
+			res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, tt.permissions)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+
+			if tt.expectedCmd != nil {
+				assert.Equal(t, tt.expectedCmd, revoked)
+			}
+			if tt.expectedAge > 0 {
+				require.NotNil(t, revoked)
+				assert.WithinDuration(t, time.Now().AddDate(0, 0, -tt.expectedAge), revoked.CreatedBefore, time.Minute)
+			}
+		})
+	}
+}
+
 func putAdminScenario(t *testing.T, desc string, url string, routePattern string, role org.RoleType,
 	cmd dtos.AdminUpdateUserPermissionsForm, fn scenarioFunc, sqlStore db.DB, userSvc user.Service) {
 	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
@@ -19,6 +19,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -27,6 +28,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
+	provisioningdashboards "github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/star"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -317,6 +319,7 @@ func (hs *HTTPServer) RestoreDeletedDashboard(c *contextmodel.ReqContext) respon
 		}
 		return response.Error(http.StatusInternalServerError, "Dashboard cannot be restored", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionRestore, dashboardAuditResource(dash), nil, dashboardAuditSummary(dash))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"title":   dash.Title,
@@ -364,6 +367,11 @@ func (hs *HTTPServer) SoftDeleteDashboard(c *contextmodel.ReqContext) response.R
 		return response.Error(http.StatusInternalServerError, "Failed to delete dashboard", err)
 	}
 
+	if err := hs.DependenciesService.RemoveDashboard(c.Req.Context(), c.SignedInUser.GetOrgID(), dash.UID); err != nil {
+		hs.log.Warn("Failed to remove dashboard dependencies", "dashboard", dash.UID, "error", err)
+	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, dashboardAuditResource(dash), dashboardAuditSummary(dash), util.DynMap{"trashed": true})
+
 	return response.JSON(http.StatusOK, util.DynMap{
 		"title":   dash.Title,
 		"message": fmt.Sprintf("Dashboard %s moved to trash", dash.Title),
@@ -464,6 +472,10 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 		return response.Error(http.StatusInternalServerError, "Failed to delete dashboard", err)
 	}
 
+	if err := hs.DependenciesService.RemoveDashboard(c.Req.Context(), c.SignedInUser.GetOrgID(), dash.UID); err != nil {
+		hs.log.Warn("Failed to remove dashboard dependencies", "dashboard", dash.UID, "error", err)
+	}
+
 	if hs.Live != nil {
 		err := hs.Live.GrafanaScope.Dashboards.DashboardDeleted(c.SignedInUser.GetOrgID(), c.SignedInUser, dash.UID)
 		if err != nil {
@@ -471,6 +483,8 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 		}
 	}
 
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, dashboardAuditResource(dash), dashboardAuditSummary(dash), nil)
+
 	return response.JSON(http.StatusOK, util.DynMap{
 		"title":   dash.Title,
 		"message": fmt.Sprintf("Dashboard %s deleted", dash.Title),
@@ -594,6 +608,25 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return apierrors.ToDashboardErrorResponse(ctx, hs.pluginStore, saveErr)
 	}
 
+	// Queue UI edits of provisioned dashboards to be committed back to their provisioning source if it supports it
+	if provisioningData != nil && allowUiUpdate {
+		authorEmail := c.SignedInUser.GetEmail()
+		if authorEmail == "" {
+			authorEmail = c.SignedInUser.GetLogin()
+		}
+		err := hs.ProvisioningService.WriteBackDashboard(ctx, provisioningdashboards.WriteBackCommand{
+			ProvisionerName: provisioningData.Name,
+			ExternalID:      provisioningData.ExternalID,
+			Dashboard:       dashboard.Data,
+			AuthorName:      c.SignedInUser.GetDisplayName(),
+			AuthorEmail:     authorEmail,
+			Message:         cmd.Message,
+		})
+		if err != nil {
+			hs.log.Warn("Failed to queue write back of provisioned dashboard", "uid", dashboard.UID, "provisioner", provisioningData.Name, "error", err)
+		}
+	}
+
 	// Clear permission cache for the user who's created the dashboard, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	if newDashboard {
@@ -606,6 +639,17 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
+	// a failure is not fatal, the dependencies are recomputed in the background
+	if err := hs.DependenciesService.IndexDashboard(ctx, dashboard); err != nil {
+		hs.log.Warn("Failed to update dashboard dependencies", "uid", dashboard.UID, "error", err)
+	}
+
+	action := auditlog.ActionUpdate
+	if newDashboard {
+		action = auditlog.ActionCreate
+	}
+	auditlog.Annotate(ctx, action, dashboardAuditResource(dashboard), nil, dashboardAuditSummary(dashboard))
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":    "success",
@@ -618,6 +662,18 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	})
 }
 
+func dashboardAuditResource(dash *dashboards.Dashboard) auditlog.Resource {
+	return auditlog.Resource{Kind: dashboards.ScopeDashboardsRoot, UID: dash.UID, Name: dash.Title}
+}
+
+func dashboardAuditSummary(dash *dashboards.Dashboard) util.DynMap {
+	return util.DynMap{
+		"title":     dash.Title,
+		"folderUid": dash.FolderUID,
+		"version":   dash.Version,
+	}
+}
+
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
 //
 // Get home dashboard.
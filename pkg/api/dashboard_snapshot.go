@@ -1,14 +1,18 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -92,6 +96,73 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) {
 	}, cmd, hs.dashboardsnapshotsService)
 }
 
+// swagger:route POST /snapshots/generate snapshots generateDashboardSnapshot
+//
+// Generate a snapshot of a stored dashboard. The panel queries are executed by the server with the permissions of the caller
+// for the given time range and template variable values, and the results are embedded in the snapshot.
+//
+// Responses:
+// 200: createDashboardSnapshotResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GenerateDashboardSnapshot(c *contextmodel.ReqContext) response.Response {
+	if !hs.Cfg.SnapshotEnabled {
+		return response.Error(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+	}
+
+	cmd := dashboardsnapshots.GenerateDashboardSnapshotCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	scope := dashboards.ScopeDashboardsProvider.GetResourceScopeUID(cmd.DashboardUID)
+	evaluator := ac.EvalPermission(dashboards.ActionDashboardsRead, scope)
+	// Do not require write permissions when the instance snapshot public mode is enabled
+	if !hs.Cfg.SnapshotPublicMode {
+		evaluator = ac.EvalAll(evaluator, ac.EvalPermission(dashboards.ActionDashboardsWrite, scope))
+	}
+	if canSnapshot, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil || !canSnapshot {
+		return response.Error(http.StatusForbidden, "forbidden", err)
+	}
+
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, cmd.DashboardUID)
+	if rsp != nil {
+		return rsp
+	}
+
+	snapshotDashboard, err := dashboardsnapshots.GenerateSnapshotDashboard(c.Req.Context(), dash.Data, cmd,
+		func(ctx context.Context, from, to string, queries []*simplejson.Json) (*backend.QueryDataResponse, error) {
+			return hs.queryDataService.QueryData(ctx, c.SignedInUser, false, dtos.MetricRequest{
+				From:    from,
+				To:      to,
+				Queries: queries,
+			})
+		})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to generate snapshot", err)
+	}
+
+	name := cmd.Name
+	if name == "" {
+		name = dash.Title
+	}
+	createCmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Name:      name,
+			Dashboard: snapshotDashboard,
+			Expires:   cmd.Expires,
+		},
+	}
+
+	dashboardsnapshots.CreateDashboardSnapshot(c, dashboardsnapshot.SnapshotSharingOptions{
+		SnapshotsEnabled: hs.Cfg.SnapshotEnabled,
+	}, createCmd, hs.dashboardsnapshotsService)
+	return nil
+}
+
 // GET /api/snapshots/:key
 // swagger:route GET /snapshots/{key} snapshots getDashboardSnapshot
 //
@@ -314,6 +385,13 @@ type CreateSnapshotParams struct {
 	Body dashboardsnapshots.CreateDashboardSnapshotCommand `json:"body"`
 }
 
+// swagger:parameters generateDashboardSnapshot
+type GenerateSnapshotParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.GenerateDashboardSnapshotCommand `json:"body"`
+}
+
 // swagger:parameters searchDashboardSnapshots
 type GetSnapshotsParams struct {
 	// Search Query
@@ -36,6 +36,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards/service"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/dashboardversion/dashvertest"
+	"github.com/grafana/grafana/pkg/services/dependencies/dependenciestest"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/folder/folderimpl"
@@ -129,7 +130,7 @@ func newTestLive(t *testing.T, store db.DB) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features, acimpl.ProvideAccessControl(features), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		features, acimpl.ProvideAccessControl(features), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil, nil)
 	require.NoError(t, err)
 	return gLive
 }
@@ -270,6 +271,7 @@ func TestHTTPServer_DeleteDashboardByUID_AccessControl(t *testing.T) {
 
 			hs.LibraryPanelService = &mockLibraryPanelService{}
 			hs.LibraryElementService = &mockLibraryElementService{}
+			hs.DependenciesService = dependenciestest.NewFakeService()
 
 			pubDashService := publicdashboards.NewFakePublicDashboardService(t)
 			pubDashService.On("DeleteByDashboard", mock.Anything, mock.Anything).Return(nil).Maybe()
@@ -907,6 +909,7 @@ func postDashboardScenario(t *testing.T, desc string, url string, routePattern s
 			folderService:         folderService,
 			Features:              featuremgmt.WithFeatures(),
 			accesscontrolService:  actest.FakeService{},
+			DependenciesService:   dependenciestest.NewFakeService(),
 			log:                   log.New("test-logger"),
 		}
 
@@ -986,6 +989,7 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 			dashboardVersionService: fakeDashboardVersionService,
 			accesscontrolService:    actest.FakeService{},
 			folderService:           folderSvc,
+			DependenciesService:     dependenciestest.NewFakeService(),
 		}
 
 		sc := setupScenarioContext(t, url)
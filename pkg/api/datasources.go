@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -191,6 +192,7 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), ds.UID)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, dataSourceAuditResource(ds), dataSourceAuditSummary(ds), nil)
 
 	return response.Success("Data source deleted")
 }
@@ -270,6 +272,7 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), ds.UID)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, dataSourceAuditResource(ds), dataSourceAuditSummary(ds), nil)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -320,6 +323,7 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), dataSource.UID)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, dataSourceAuditResource(dataSource), dataSourceAuditSummary(dataSource), nil)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -477,6 +481,7 @@ func (hs *HTTPServer) AddDataSource(c *contextmodel.ReqContext) response.Respons
 	// Clear permission cache for the user who's created the data source, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, dataSourceAuditResource(dataSource), nil, dataSourceAuditSummary(dataSource))
 
 	ds := hs.convertModelToDtos(c.Req.Context(), dataSource)
 	return response.JSON(http.StatusOK, util.DynMap{
@@ -649,6 +654,7 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	datasourceDTO := hs.convertModelToDtos(c.Req.Context(), dataSource)
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, dataSourceAuditResource(dataSource), dataSourceAuditSummary(ds), dataSourceAuditSummary(dataSource))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource updated",
@@ -658,6 +664,25 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	})
 }
 
+func dataSourceAuditResource(ds *datasources.DataSource) auditlog.Resource {
+	return auditlog.Resource{Kind: datasources.ScopeRoot, UID: ds.UID, Name: ds.Name}
+}
+
+// dataSourceAuditSummary describes a data source in the audit log, secure fields are left out
+func dataSourceAuditSummary(ds *datasources.DataSource) map[string]any {
+	return map[string]any{
+		"name":      ds.Name,
+		"type":      ds.Type,
+		"url":       ds.URL,
+		"access":    ds.Access,
+		"isDefault": ds.IsDefault,
+		"basicAuth": ds.BasicAuth,
+		"user":      ds.User,
+		"database":  ds.Database,
+		"version":   ds.Version,
+	}
+}
+
 func (hs *HTTPServer) getRawDataSourceById(ctx context.Context, id int64, orgID int64) (*datasources.DataSource, error) {
 	query := datasources.GetDataSourceQuery{
 		ID:    id,
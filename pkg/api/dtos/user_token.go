@@ -1,16 +1,29 @@
 package dtos
 
-import "time"
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/geoip"
+)
 
 type UserToken struct {
-	Id                     int64     `json:"id"`
-	IsActive               bool      `json:"isActive"`
-	ClientIp               string    `json:"clientIp"`
-	Device                 string    `json:"device"`
-	OperatingSystem        string    `json:"os"`
-	OperatingSystemVersion string    `json:"osVersion"`
-	Browser                string    `json:"browser"`
-	BrowserVersion         string    `json:"browserVersion"`
-	CreatedAt              time.Time `json:"createdAt"`
-	SeenAt                 time.Time `json:"seenAt"`
+	Id                     int64           `json:"id"`
+	IsActive               bool            `json:"isActive"`
+	ClientIp               string          `json:"clientIp"`
+	Location               *geoip.Location `json:"location,omitempty"`
+	Device                 string          `json:"device"`
+	OperatingSystem        string          `json:"os"`
+	OperatingSystemVersion string          `json:"osVersion"`
+	Browser                string          `json:"browser"`
+	BrowserVersion         string          `json:"browserVersion"`
+	CreatedAt              time.Time       `json:"createdAt"`
+	SeenAt                 time.Time       `json:"seenAt"`
+}
+
+// AdminRevokeAuthTokensCommand selects the auth tokens to revoke, tokens have to match all the criteria that are set
+type AdminRevokeAuthTokensCommand struct {
+	// Revoke the auth tokens of the members of this organization
+	OrgID int64 `json:"orgId"`
+	// Revoke the auth tokens created more than this many days ago
+	OlderThanDays int `json:"olderThanDays"`
 }
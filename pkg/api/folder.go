@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -177,6 +178,7 @@ func (hs *HTTPServer) CreateFolder(c *contextmodel.ReqContext) response.Response
 	// Clear permission cache for the user who's created the folder, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, folderAuditResource(folder), nil, folderAuditSummary(folder))
 
 	folderDTO, err := hs.newToFolderDto(c, folder)
 	if err != nil {
@@ -237,10 +239,12 @@ func (hs *HTTPServer) MoveFolder(c *contextmodel.ReqContext) response.Response {
 		cmd.OrgID = c.SignedInUser.GetOrgID()
 		cmd.UID = web.Params(c.Req)[":uid"]
 		cmd.SignedInUser = c.SignedInUser
+		before := hs.folderAuditBefore(c, cmd.UID)
 		theFolder, err := hs.folderService.Move(c.Req.Context(), &cmd)
 		if err != nil {
 			return response.ErrOrFallback(http.StatusInternalServerError, "move folder failed", err)
 		}
+		auditlog.Annotate(c.Req.Context(), auditlog.ActionMove, folderAuditResource(theFolder), before, folderAuditSummary(theFolder))
 
 		folderDTO, err := hs.newToFolderDto(c, theFolder)
 		if err != nil {
@@ -274,10 +278,12 @@ func (hs *HTTPServer) UpdateFolder(c *contextmodel.ReqContext) response.Response
 	cmd.OrgID = c.SignedInUser.GetOrgID()
 	cmd.UID = web.Params(c.Req)[":uid"]
 	cmd.SignedInUser = c.SignedInUser
+	before := hs.folderAuditBefore(c, cmd.UID)
 	result, err := hs.folderService.Update(c.Req.Context(), &cmd)
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, folderAuditResource(result), before, folderAuditSummary(result))
 	folderDTO, err := hs.newToFolderDto(c, result)
 	if err != nil {
 		return response.Err(err)
@@ -301,6 +307,7 @@ func (hs *HTTPServer) UpdateFolder(c *contextmodel.ReqContext) response.Response
 // 404: notFoundError
 // 500: internalServerError
 func (hs *HTTPServer) DeleteFolder(c *contextmodel.ReqContext) response.Response { // temporarily adding this function to HTTPServer, will be removed from HTTPServer when librarypanels featuretoggle is removed
+	before := hs.folderAuditBefore(c, web.Params(c.Req)[":uid"])
 	err := hs.LibraryElementService.DeleteLibraryElementsInFolder(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
 	if err != nil {
 		if errors.Is(err, model.ErrFolderHasConnectedLibraryElements) {
@@ -320,12 +327,38 @@ func (hs *HTTPServer) DeleteFolder(c *contextmodel.ReqContext) response.Response
 	if err != nil {
 		return apierrors.ToFolderErrorResponse(err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, auditlog.Resource{Kind: dashboards.ScopeFoldersRoot, UID: uid, Name: before["title"]}, before, nil)
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Folder deleted",
 	})
 }
 
+func folderAuditResource(f *folder.Folder) auditlog.Resource {
+	return auditlog.Resource{Kind: dashboards.ScopeFoldersRoot, UID: f.UID, Name: f.Title}
+}
+
+func folderAuditSummary(f *folder.Folder) map[string]string {
+	return map[string]string{
+		"title":       f.Title,
+		"description": f.Description,
+		"parentUid":   f.ParentUID,
+	}
+}
+
+// folderAuditBefore returns the summary of a folder before it is changed, it is only loaded when the
+// request is recorded in the audit log
+func (hs *HTTPServer) folderAuditBefore(c *contextmodel.ReqContext, uid string) map[string]string {
+	if !auditlog.IsAudited(c.Req.Context()) {
+		return nil
+	}
+	f, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{UID: &uid, OrgID: c.SignedInUser.GetOrgID(), SignedInUser: c.SignedInUser})
+	if err != nil {
+		return nil
+	}
+	return folderAuditSummary(f)
+}
+
 // swagger:route GET /folders/{folder_uid}/counts folders getFolderDescendantCounts
 //
 // Gets the count of each descendant of a folder by kind. The folder is identified by UID.
@@ -2,7 +2,9 @@ package api
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,6 +22,7 @@ import (
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/util/errutil/errhttp"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -33,6 +36,7 @@ func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
 			playlistRoute.Get("/", handler.searchPlaylists)
 			playlistRoute.Get("/:uid", handler.getPlaylist)
 			playlistRoute.Get("/:uid/items", handler.getPlaylistItems)
+			playlistRoute.Get("/:uid/current", handler.getPlaylistCurrentItem)
 			playlistRoute.Delete("/:uid", handler.deletePlaylist)
 			playlistRoute.Put("/:uid", handler.updatePlaylist)
 			playlistRoute.Post("/", handler.createPlaylist)
@@ -41,6 +45,7 @@ func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
 			playlistRoute.Get("/", routing.Wrap(hs.SearchPlaylists))
 			playlistRoute.Get("/:uid", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylist))
 			playlistRoute.Get("/:uid/items", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistItems))
+			playlistRoute.Get("/:uid/current", hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistCurrentItem))
 			playlistRoute.Delete("/:uid", middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.DeletePlaylist))
 			playlistRoute.Put("/:uid", middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.UpdatePlaylist))
 			playlistRoute.Post("/", middleware.ReqEditorRole, routing.Wrap(hs.CreatePlaylist))
@@ -142,6 +147,68 @@ func (hs *HTTPServer) GetPlaylistItems(c *contextmodel.ReqContext) response.Resp
 	return response.JSON(http.StatusOK, dto.Items)
 }
 
+// swagger:route GET /playlists/{uid}/current playlists getPlaylistCurrentItem
+//
+// Get the item a kiosk shows at a given time.
+//
+// Items take turns according to their duration and schedule. The response tells when the kiosk should ask again.
+//
+// Responses:
+// 200: getPlaylistCurrentItemResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetPlaylistCurrentItem(c *contextmodel.ReqContext) response.Response {
+	now, loc, err := parseCurrentItemParams(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	dto, err := hs.playlistService.Get(c.Req.Context(), &playlist.GetPlaylistByUidQuery{UID: uid, OrgId: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Playlist not found", err)
+	}
+
+	current, err := playlist.ResolveCurrentItem(dto, now, loc)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, current)
+}
+
+var (
+	errInvalidCurrentItemTime = errutil.BadRequest("playlist.invalidTime",
+		errutil.WithPublicMessage("Time must be a number of milliseconds since the epoch"))
+	errInvalidCurrentItemTimezone = errutil.BadRequest("playlist.invalidTimezone",
+		errutil.WithPublicMessage("Timezone must be an IANA time zone"))
+)
+
+// parseCurrentItemParams reads the time, in milliseconds since the epoch, and the timezone of the kiosk. They default
+// to now and UTC.
+func parseCurrentItemParams(c *contextmodel.ReqContext) (time.Time, *time.Location, error) {
+	now := time.Now()
+	if v := c.Query("time"); v != "" {
+		ms, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return now, nil, errInvalidCurrentItemTime.Errorf("invalid time %q: %w", v, err)
+		}
+		now = time.UnixMilli(ms)
+	}
+
+	loc := time.UTC
+	if v := c.Query("timezone"); v != "" {
+		l, err := time.LoadLocation(v)
+		if err != nil {
+			return now, nil, errInvalidCurrentItemTimezone.Errorf("invalid timezone %q: %w", v, err)
+		}
+		loc = l
+	}
+	return now, loc, nil
+}
+
 // swagger:route DELETE /playlists/{uid} playlists deletePlaylist
 //
 // Delete playlist.
@@ -182,7 +249,7 @@ func (hs *HTTPServer) CreatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	p, err := hs.playlistService.Create(c.Req.Context(), &cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to create playlist", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create playlist", err)
 	}
 
 	return response.JSON(http.StatusOK, p)
@@ -208,7 +275,7 @@ func (hs *HTTPServer) UpdatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	_, err := hs.playlistService.Update(c.Req.Context(), &cmd)
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to save playlist", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to save playlist", err)
 	}
 
 	dto, err := hs.playlistService.Get(c.Req.Context(), &playlist.GetPlaylistByUidQuery{
@@ -245,6 +312,21 @@ type GetPlaylistItemsParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters getPlaylistCurrentItem
+type GetPlaylistCurrentItemParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Time in milliseconds since the epoch, defaults to now
+	// in:query
+	// required:false
+	Time int64 `json:"time"`
+	// IANA time zone of the kiosk used by schedules without a time zone, defaults to UTC
+	// in:query
+	// required:false
+	Timezone string `json:"timezone"`
+}
+
 // swagger:parameters getPlaylistDashboards
 type GetPlaylistDashboardsParams struct {
 	// in:path
@@ -297,6 +379,13 @@ type GetPlaylistItemsResponse struct {
 	Body []playlist.PlaylistItemDTO `json:"body"`
 }
 
+// swagger:response getPlaylistCurrentItemResponse
+type GetPlaylistCurrentItemResponse struct {
+	// The response message
+	// in: body
+	Body *playlist.CurrentItemDTO `json:"body"`
+}
+
 // swagger:response getPlaylistDashboardsResponse
 type GetPlaylistDashboardsResponse struct {
 	// The response message
@@ -390,6 +479,30 @@ func (pk8s *playlistK8sHandler) getPlaylistItems(c *contextmodel.ReqContext) {
 	c.JSON(http.StatusOK, internalplaylist.UnstructuredToLegacyPlaylistDTO(*out).Items)
 }
 
+func (pk8s *playlistK8sHandler) getPlaylistCurrentItem(c *contextmodel.ReqContext) {
+	now, loc, err := parseCurrentItemParams(c)
+	if err != nil {
+		pk8s.writeError(c, err)
+		return
+	}
+	client, ok := pk8s.getClient(c)
+	if !ok {
+		return // error is already sent
+	}
+	uid := web.Params(c.Req)[":uid"]
+	out, err := client.Get(c.Req.Context(), uid, v1.GetOptions{})
+	if err != nil {
+		pk8s.writeError(c, err)
+		return
+	}
+	current, err := playlist.ResolveCurrentItem(internalplaylist.UnstructuredToLegacyPlaylistDTO(*out), now, loc)
+	if err != nil {
+		pk8s.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, current)
+}
+
 func (pk8s *playlistK8sHandler) deletePlaylist(c *contextmodel.ReqContext) {
 	client, ok := pk8s.getClient(c)
 	if !ok {
@@ -4,12 +4,14 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/ua-parser/uap-go/uaparser"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/geoip"
 	"github.com/grafana/grafana/pkg/infra/network"
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -20,6 +22,9 @@ import (
 	"github.com/grafana/grafana/pkg/web"
 )
 
+// uaParser parses the user agent of auth tokens, loading its regular expressions is slow so it's done once
+var uaParser = sync.OnceValue(uaparser.NewFromSaved)
+
 // swagger:route GET /user/auth-tokens signed_in_user getUserAuthTokens
 //
 // Auth tokens of the actual User.
@@ -76,6 +81,31 @@ func (hs *HTTPServer) RevokeUserAuthToken(c *contextmodel.ReqContext) response.R
 	return hs.revokeUserAuthTokenInternal(c, userID, cmd)
 }
 
+// swagger:route POST /user/revoke-other-auth-tokens signed_in_user revokeOtherUserAuthTokens
+//
+// Revoke the other auth tokens of the actual User.
+//
+// Revokes all the auth tokens (devices) of the actual user except the one used by the request. Users of the revoked auth tokens (devices) will no longer be logged in and will be required to authenticate again upon next activity.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (hs *HTTPServer) RevokeOtherUserAuthTokens(c *contextmodel.ReqContext) response.Response {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return response.Error(http.StatusForbidden, "entity not allowed to revoke tokens", nil)
+	}
+
+	userID, err := identity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "failed to parse user id", err)
+	}
+
+	return hs.revokeAuthTokensInternal(c, &auth.RevokeTokensCmd{UserID: userID})
+}
+
 func (hs *HTTPServer) RotateUserAuthTokenRedirect(c *contextmodel.ReqContext) response.Response {
 	if err := hs.rotateToken(c); err != nil {
 		hs.log.FromContext(c.Req.Context()).Debug("Failed to rotate token", "error", err)
@@ -143,6 +173,26 @@ func (hs *HTTPServer) rotateToken(c *contextmodel.ReqContext) error {
 	return nil
 }
 
+// revokeAuthTokensInternal revokes the auth tokens matching cmd, except the one used by the request
+func (hs *HTTPServer) revokeAuthTokensInternal(c *contextmodel.ReqContext, cmd *auth.RevokeTokensCmd) response.Response {
+	if c.UserToken != nil {
+		cmd.ExceptTokenID = c.UserToken.Id
+	}
+
+	count, err := hs.AuthTokenService.RevokeTokens(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, auth.ErrRevokeTokensNoFilter) {
+			return response.Error(http.StatusBadRequest, "At least one criteria is required to revoke auth tokens", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to revoke auth tokens", err)
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{
+		"message": "Auth tokens revoked",
+		"count":   count,
+	})
+}
+
 func (hs *HTTPServer) logoutUserFromAllDevicesInternal(ctx context.Context, userID int64) response.Response {
 	userQuery := user.GetUserByIDQuery{ID: userID}
 
@@ -191,8 +241,7 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 			isActive = true
 		}
 
-		parser := uaparser.NewFromSaved()
-		client := parser.Parse(token.UserAgent)
+		client := uaParser().Parse(token.UserAgent)
 
 		osVersion := ""
 		if client.Os.Major != "" {
@@ -212,17 +261,20 @@ func (hs *HTTPServer) getUserAuthTokensInternal(c *contextmodel.ReqContext, user
 			}
 		}
 
+		// seen_at is cleared when the token is rotated, which only happens while the user is active
 		createdAt := time.Unix(token.CreatedAt, 0)
-		seenAt := time.Unix(token.SeenAt, 0)
+		seenAt := time.Unix(max(token.SeenAt, token.RotatedAt, token.CreatedAt), 0)
 
-		if token.SeenAt == 0 {
-			seenAt = createdAt
+		var location *geoip.Location
+		if hs.geoIP != nil {
+			location = hs.geoIP.Locate(token.ClientIp)
 		}
 
 		result = append(result, &dtos.UserToken{
 			Id:                     token.Id,
 			IsActive:               isActive,
 			ClientIp:               token.ClientIp,
+			Location:               location,
 			Device:                 client.Device.ToString(),
 			OperatingSystem:        client.Os.Family,
 			OperatingSystemVersion: osVersion,
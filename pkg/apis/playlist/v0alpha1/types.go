@@ -63,4 +63,49 @@ type Item struct {
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
 	Value string `json:"value"`
+
+	// Duration overrides the playlist interval while the item is shown.
+	// +optional
+	Duration string `json:"duration,omitempty"`
+
+	// Schedule restricts the item to some days of the week and times of day.
+	// +optional
+	Schedule *ItemSchedule `json:"schedule,omitempty"`
+
+	// Variables override the template variables of the dashboards.
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// TimeRange overrides the time range of the dashboards.
+	// +optional
+	TimeRange *ItemTimeRange `json:"timeRange,omitempty"`
+}
+
+// ItemSchedule is a weekly window during which an item is shown.
+type ItemSchedule struct {
+	// Days of the week from mon to sun, every day when empty.
+	// +optional
+	Days []string `json:"days,omitempty"`
+
+	// From is the time of day the window starts, as HH:MM. Defaults to midnight.
+	// +optional
+	From string `json:"from,omitempty"`
+
+	// To is the time of day the window ends, as HH:MM. Defaults to midnight at the end of the day.
+	// A window that ends before it starts spans midnight.
+	// +optional
+	To string `json:"to,omitempty"`
+
+	// Timezone is the IANA time zone of the window. Defaults to the time zone of the kiosk.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// ItemTimeRange overrides the time range of the dashboards of an item.
+type ItemTimeRange struct {
+	// From is a time such as now-6h.
+	From string `json:"from"`
+
+	// To is a time such as now.
+	To string `json:"to"`
 }
@@ -14,6 +14,23 @@ import (
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Item) DeepCopyInto(out *Item) {
 	*out = *in
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(ItemSchedule)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.TimeRange != nil {
+		in, out := &in.TimeRange, &out.TimeRange
+		*out = new(ItemTimeRange)
+		**out = **in
+	}
 	return
 }
 
@@ -27,6 +44,43 @@ func (in *Item) DeepCopy() *Item {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ItemSchedule) DeepCopyInto(out *ItemSchedule) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ItemSchedule.
+func (in *ItemSchedule) DeepCopy() *ItemSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(ItemSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ItemTimeRange) DeepCopyInto(out *ItemTimeRange) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ItemTimeRange.
+func (in *ItemTimeRange) DeepCopy() *ItemTimeRange {
+	if in == nil {
+		return nil
+	}
+	out := new(ItemTimeRange)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Playlist) DeepCopyInto(out *Playlist) {
 	*out = *in
@@ -93,7 +147,9 @@ func (in *Spec) DeepCopyInto(out *Spec) {
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
 		*out = make([]Item, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
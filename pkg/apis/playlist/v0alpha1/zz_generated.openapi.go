@@ -16,10 +16,12 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item":         schema_pkg_apis_playlist_v0alpha1_Item(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Playlist":     schema_pkg_apis_playlist_v0alpha1_Playlist(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.PlaylistList": schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Spec":         schema_pkg_apis_playlist_v0alpha1_Spec(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item":          schema_pkg_apis_playlist_v0alpha1_Item(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ItemSchedule":  schema_pkg_apis_playlist_v0alpha1_ItemSchedule(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ItemTimeRange": schema_pkg_apis_playlist_v0alpha1_ItemTimeRange(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Playlist":      schema_pkg_apis_playlist_v0alpha1_Playlist(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.PlaylistList":  schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Spec":          schema_pkg_apis_playlist_v0alpha1_Spec(ref),
 	}
 }
 
@@ -47,10 +49,126 @@ func schema_pkg_apis_playlist_v0alpha1_Item(ref common.ReferenceCallback) common
 							Format:      "",
 						},
 					},
+					"duration": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Duration overrides the playlist interval while the item is shown.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule restricts the item to some days of the week and times of day.",
+							Ref:         ref("github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ItemSchedule"),
+						},
+					},
+					"variables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Variables override the template variables of the dashboards.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"timeRange": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TimeRange overrides the time range of the dashboards.",
+							Ref:         ref("github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ItemTimeRange"),
+						},
+					},
 				},
 				Required: []string{"type", "value"},
 			},
 		},
+		Dependencies: []string{
+			"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ItemSchedule", "github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ItemTimeRange"},
+	}
+}
+
+func schema_pkg_apis_playlist_v0alpha1_ItemSchedule(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ItemSchedule is a weekly window during which an item is shown.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"days": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Days of the week from mon to sun, every day when empty.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"from": {
+						SchemaProps: spec.SchemaProps{
+							Description: "From is the time of day the window starts, as HH:MM. Defaults to midnight.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"to": {
+						SchemaProps: spec.SchemaProps{
+							Description: "To is the time of day the window ends, as HH:MM. Defaults to midnight at the end of the day. A window that ends before it starts spans midnight.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone is the IANA time zone of the window. Defaults to the time zone of the kiosk.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func schema_pkg_apis_playlist_v0alpha1_ItemTimeRange(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ItemTimeRange overrides the time range of the dashboards of an item.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"from": {
+						SchemaProps: spec.SchemaProps{
+							Description: "From is a time such as now-6h.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"to": {
+						SchemaProps: spec.SchemaProps{
+							Description: "To is a time such as now.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"from", "to"},
+			},
+		},
 	}
 }
 
@@ -135,6 +135,23 @@ var adminCommands = []*cli.Command{
 			},
 		},
 	},
+	{
+		Name:   "lint-dashboards",
+		Usage:  "lint-dashboards <dashboard file or directory>...",
+		Action: runLintDashboardsCommand(),
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "org-id",
+				Usage: "The organization the dashboard references are resolved in",
+				Value: 1,
+			},
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "Skip the checks that need the database, such as unknown data sources and library panels",
+				Value: false,
+			},
+		},
+	},
 	{
 		Name:  "data-migration",
 		Usage: "Runs a script that migrates or cleanups data in your database",
@@ -0,0 +1,143 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+var errInvalidDashboards = errors.New("dashboards have error-level findings")
+
+// resourceLoader resolves the data sources and library panels referenced by a dashboard. A nil loader lints the
+// dashboards without checking their references.
+type resourceLoader func(ctx context.Context, dashboard *simplejson.Json) (*dashboardlint.Resources, error)
+
+func runLintDashboardsCommand() func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: c}
+		// trailing cfg: arguments override the configuration, the others are dashboards
+		var paths []string
+		for _, arg := range cmd.Args().Slice() {
+			if !strings.HasPrefix(arg, "cfg:") {
+				paths = append(paths, arg)
+			}
+		}
+		if len(paths) == 0 {
+			return fmt.Errorf("at least one dashboard file or directory is required")
+		}
+
+		var load resourceLoader
+		if !cmd.Bool("offline") {
+			runner, err := initializeRunner(cmd)
+			if err != nil {
+				return fmt.Errorf("%v: %w", "failed to initialize runner", err)
+			}
+			orgID := int64(cmd.Int("org-id"))
+			load = func(ctx context.Context, dashboard *simplejson.Json) (*dashboardlint.Resources, error) {
+				return dashboardlint.LoadResources(ctx, runner.SQLStore, orgID, dashboard)
+			}
+		}
+
+		return lintDashboards(c.Context, paths, load)
+	}
+}
+
+func lintDashboards(ctx context.Context, paths []string, load resourceLoader) error {
+	files, err := dashboardFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	invalid := 0
+	for _, file := range files {
+		report, err := lintDashboardFile(ctx, file, load)
+		if err != nil {
+			return fmt.Errorf("failed to lint %s: %w", file, err)
+		}
+		if report.HasErrors() {
+			invalid++
+		}
+		printLintReport(file, report)
+	}
+
+	logger.Infof("\n%d dashboard(s) checked, %d with errors\n", len(files), invalid)
+	if invalid > 0 {
+		return errInvalidDashboards
+	}
+	return nil
+}
+
+// dashboardFiles expands directories to the JSON files they contain
+func dashboardFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && (p == path || strings.HasSuffix(p, ".json")) {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func lintDashboardFile(ctx context.Context, file string, load resourceLoader) (*dashboardlint.Report, error) {
+	// nolint:gosec
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	dashboard, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources *dashboardlint.Resources
+	if load != nil {
+		if resources, err = load(ctx, dashboard); err != nil {
+			return nil, err
+		}
+	}
+	return dashboardlint.Lint(dashboard, resources), nil
+}
+
+func printLintReport(file string, report *dashboardlint.Report) {
+	if len(report.Findings) == 0 {
+		logger.Infof("%s %s\n", file, color.GreenString("✔"))
+		return
+	}
+
+	logger.Infof("%s\n", file)
+	for _, f := range report.Findings {
+		severity := string(f.Severity)
+		switch f.Severity {
+		case dashboardlint.SeverityError:
+			severity = color.RedString(severity)
+		case dashboardlint.SeverityWarning:
+			severity = color.YellowString(severity)
+		}
+		if f.Path != "" {
+			logger.Infof("  %s [%s] %s: %s\n", severity, f.Rule, f.Path, f.Message)
+		} else {
+			logger.Infof("  %s [%s] %s\n", severity, f.Rule, f.Message)
+		}
+	}
+}
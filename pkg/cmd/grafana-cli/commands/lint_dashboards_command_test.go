@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+func TestLintDashboards(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	invalid := filepath.Join(dir, "nested", "invalid.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(invalid), 0750))
+	require.NoError(t, os.WriteFile(valid, []byte(`{"title": "Valid", "schemaVersion": 39, "panels": [{"id": 1, "type": "text"}]}`), 0600))
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"title": "Invalid", "schemaVersion": 39, "panels": [{"id": 1, "type": "text"}, {"id": 1, "type": "text"}]}`), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not a dashboard"), 0600))
+
+	t.Run("directories are expanded to their JSON files", func(t *testing.T) {
+		files, err := dashboardFiles([]string{dir})
+		require.NoError(t, err)
+		assert.Equal(t, []string{invalid, valid}, files)
+	})
+
+	t.Run("valid dashboards", func(t *testing.T) {
+		require.NoError(t, lintDashboards(context.Background(), []string{valid}, nil))
+	})
+
+	t.Run("dashboards with errors fail", func(t *testing.T) {
+		require.ErrorIs(t, lintDashboards(context.Background(), []string{dir}, nil), errInvalidDashboards)
+	})
+
+	t.Run("references are resolved with the loader", func(t *testing.T) {
+		var loaded []string
+		load := func(_ context.Context, dashboard *simplejson.Json) (*dashboardlint.Resources, error) {
+			loaded = append(loaded, dashboard.Get("title").MustString())
+			return &dashboardlint.Resources{LibraryPanels: map[string]bool{}}, nil
+		}
+
+		require.NoError(t, lintDashboards(context.Background(), []string{valid}, load))
+		assert.Equal(t, []string{"Valid"}, loaded)
+	})
+}
@@ -0,0 +1,163 @@
+// Package geoip locates IP addresses with a local database file, such as the free IP to Country Lite and IP to
+// City Lite CSV databases of DB-IP.
+package geoip
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// unknownCountry is the country of reserved ranges, such as private networks
+const unknownCountry = "ZZ"
+
+type Location struct {
+	Country string `json:"country"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
+}
+
+func (l Location) String() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{l.City, l.Region, l.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+type Locator interface {
+	// Locate returns the location of an IP address, or nil when it's unknown
+	Locate(address string) *Location
+}
+
+var _ Locator = (*Database)(nil)
+
+// Database is a list of IP address ranges and their location. The zero value locates nothing.
+type Database struct {
+	ranges    []ipRange
+	locations []Location
+}
+
+type ipRange struct {
+	start, end netip.Addr
+	location   int
+}
+
+func ProvideService(cfg *setting.Cfg) (*Database, error) {
+	if cfg.SessionGeoIPDatabasePath == "" {
+		return &Database{}, nil
+	}
+
+	db, err := Open(cfg.SessionGeoIPDatabasePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load GeoIP database: %w", err)
+	}
+	log.New("geoip").Info("Loaded GeoIP database", "path", cfg.SessionGeoIPDatabasePath, "ranges", len(db.ranges))
+	return db, nil
+}
+
+// Open loads a CSV database file, which is decompressed when its name ends with .gz
+func Open(path string) (*Database, error) {
+	// nolint:gosec
+	// The path comes from the configuration
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = gz.Close() }()
+		r = gz
+	}
+	return Read(r)
+}
+
+// Read loads a CSV database. Each row is the first and last address of a range, followed by either its country or
+// its continent, country, region and city. Further columns are ignored.
+func Read(r io.Reader) (*Database, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	db := &Database{}
+	indexes := make(map[Location]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 3 {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("line %d: expected at least 3 columns, got %d", line, len(record))
+		}
+
+		start, err := netip.ParseAddr(record[0])
+		if err != nil {
+			return nil, err
+		}
+		end, err := netip.ParseAddr(record[1])
+		if err != nil {
+			return nil, err
+		}
+
+		location := Location{Country: record[2]}
+		if len(record) >= 6 {
+			location = Location{Country: record[3], Region: record[4], City: record[5]}
+		}
+		if location.Country == "" || location.Country == unknownCountry {
+			continue
+		}
+
+		index, ok := indexes[location]
+		if !ok {
+			index = len(db.locations)
+			indexes[location] = index
+			db.locations = append(db.locations, location)
+		}
+		db.ranges = append(db.ranges, ipRange{start: start.Unmap(), end: end.Unmap(), location: index})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+func (db *Database) Locate(address string) *Location {
+	ip, err := netip.ParseAddr(strings.Trim(address, "[]"))
+	if err != nil {
+		return nil
+	}
+	ip = ip.Unmap()
+
+	// the range of the address is the last one starting before it
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return ip.Less(db.ranges[i].start)
+	})
+	if i == 0 || db.ranges[i-1].end.Less(ip) {
+		return nil
+	}
+
+	location := db.locations[db.ranges[i-1].location]
+	return &location
+}
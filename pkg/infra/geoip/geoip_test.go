@@ -0,0 +1,56 @@
+package geoip
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatabase_Locate(t *testing.T) {
+	t.Run("city database", func(t *testing.T) {
+		db, err := Read(strings.NewReader(strings.Join([]string{
+			"2001:db8::,2001:db8::ffff,EU,DE,Berlin,Berlin,52.5,13.4",
+			"1.0.0.0,1.0.0.255,OC,AU,Queensland,South Brisbane,-27.4,153.0",
+			"10.0.0.0,10.255.255.255,ZZ,ZZ,,,0,0",
+			"8.8.4.0,8.8.8.255,NA,US,California,Mountain View,37.4,-122.0",
+		}, "\n")))
+		require.NoError(t, err)
+
+		assert.Equal(t, &Location{Country: "AU", Region: "Queensland", City: "South Brisbane"}, db.Locate("1.0.0.1"))
+		assert.Equal(t, &Location{Country: "US", Region: "California", City: "Mountain View"}, db.Locate("8.8.8.8"))
+		assert.Equal(t, &Location{Country: "US", Region: "California", City: "Mountain View"}, db.Locate("::ffff:8.8.8.8"))
+		assert.Equal(t, &Location{Country: "DE", Region: "Berlin", City: "Berlin"}, db.Locate("[2001:db8::1]"))
+
+		assert.Nil(t, db.Locate("10.0.0.1"))
+		assert.Nil(t, db.Locate("1.0.1.0"))
+		assert.Nil(t, db.Locate("0.0.0.1"))
+		assert.Nil(t, db.Locate("2001:db9::"))
+		assert.Nil(t, db.Locate("invalid"))
+	})
+
+	t.Run("country database", func(t *testing.T) {
+		db, err := Read(strings.NewReader("1.0.0.0,1.0.0.255,AU\n1.0.1.0,1.0.3.255,CN\n"))
+		require.NoError(t, err)
+
+		assert.Equal(t, &Location{Country: "CN"}, db.Locate("1.0.2.3"))
+		assert.Equal(t, "CN", db.Locate("1.0.2.3").String())
+	})
+
+	t.Run("empty database", func(t *testing.T) {
+		assert.Nil(t, (&Database{}).Locate("1.0.0.1"))
+	})
+
+	t.Run("invalid database", func(t *testing.T) {
+		_, err := Read(strings.NewReader("1.0.0.0,1.0.0.255\n"))
+		assert.Error(t, err)
+		_, err = Read(strings.NewReader("1.0.0.0,invalid,AU\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestLocation_String(t *testing.T) {
+	assert.Equal(t, "Mountain View, California, US", Location{Country: "US", Region: "California", City: "Mountain View"}.String())
+	assert.Equal(t, "California, US", Location{Country: "US", Region: "California"}.String())
+}
@@ -0,0 +1,60 @@
+package remotecache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+)
+
+const memoryCacheType = "memory"
+
+// memoryStorage keeps the cached items in the memory of the local instance.
+// Items are not shared between instances, so it's only suitable for single instance setups.
+type memoryStorage struct {
+	cache *localcache.CacheService
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		cache: localcache.New(defaultMaxCacheExpiration, 10*time.Minute),
+	}
+}
+
+// Set stores the byte array in the cache
+func (s *memoryStorage) Set(ctx context.Context, key string, data []byte, expires time.Duration) error {
+	if expires == 0 {
+		expires = defaultMaxCacheExpiration
+	}
+
+	s.cache.Set(key, data, expires)
+	return nil
+}
+
+// Get returns the cached value as an byte array
+func (s *memoryStorage) Get(ctx context.Context, key string) ([]byte, error) {
+	item, ok := s.cache.Get(key)
+	if !ok {
+		return nil, ErrCacheItemNotFound
+	}
+
+	return item.([]byte), nil
+}
+
+// Delete deletes a key from the cache
+func (s *memoryStorage) Delete(ctx context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
+
+// Count returns the number of unexpired items with the prefix in the cache
+func (s *memoryStorage) Count(ctx context.Context, prefix string) (int64, error) {
+	var n int64
+	for key := range s.cache.Items() {
+		if strings.HasPrefix(key, prefix) {
+			n++
+		}
+	}
+	return n, nil
+}
@@ -127,6 +127,8 @@ func createClient(opts *setting.RemoteCacheOptions, sqlstore db.DB, secretsServi
 		cache = newMemcachedStorage(opts)
 	case databaseCacheType:
 		cache = newDatabaseCache(sqlstore)
+	case memoryCacheType:
+		cache = newMemoryStorage()
 	default:
 		return nil, ErrInvalidCacheType
 	}
@@ -194,3 +194,11 @@ func (f fakeSecretsService) reverse(input []byte) []byte {
 	}
 	return []byte(string(r))
 }
+
+func TestMemoryCache(t *testing.T) {
+	opts := &setting.RemoteCacheOptions{Name: memoryCacheType}
+
+	client := createTestClient(t, opts, nil)
+	runTestsForClient(t, client)
+	runCountTestsForClient(t, opts, nil)
+}
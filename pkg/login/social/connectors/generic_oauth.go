@@ -29,11 +29,12 @@ const (
 )
 
 var ExtraGenericOAuthSettingKeys = map[string]ExtraKeyInfo{
-	nameAttributePathKey:    {Type: String},
-	loginAttributePathKey:   {Type: String},
-	idTokenAttributeNameKey: {Type: String},
-	teamIdsKey:              {Type: String},
-	allowedOrganizationsKey: {Type: String},
+	nameAttributePathKey:      {Type: String},
+	loginAttributePathKey:     {Type: String},
+	idTokenAttributeNameKey:   {Type: String},
+	teamIdsKey:                {Type: String},
+	allowedOrganizationsKey:   {Type: String},
+	social.TeamSyncMappingKey: {Type: String},
 }
 
 var _ social.SocialConnector = (*SocialGenericOAuth)(nil)
@@ -51,6 +52,7 @@ type SocialGenericOAuth struct {
 	idTokenAttributeName string
 	teamIdsAttributePath string
 	teamIds              []string
+	teamSyncRules        []TeamSyncRule
 }
 
 func NewGenericOAuthProvider(info *social.OAuthInfo, cfg *setting.Cfg, ssoSettings ssosettings.Service, features featuremgmt.FeatureToggles) *SocialGenericOAuth {
@@ -68,6 +70,12 @@ func NewGenericOAuthProvider(info *social.OAuthInfo, cfg *setting.Cfg, ssoSettin
 		allowedOrganizations: util.SplitString(info.Extra[allowedOrganizationsKey]),
 	}
 
+	teamSyncRules, err := parseTeamSyncMapping(info.Extra[social.TeamSyncMappingKey])
+	if err != nil {
+		provider.log.Error("Invalid team sync mapping, teams won't be synced", "error", err)
+	}
+	provider.teamSyncRules = teamSyncRules
+
 	if features.IsEnabledGlobally(featuremgmt.FlagSsoSettingsApi) {
 		ssoSettings.RegisterReloadable(social.GenericOAuthProviderName, provider)
 	}
@@ -103,6 +111,16 @@ func (s *SocialGenericOAuth) Validate(ctx context.Context, settings ssoModels.SS
 		return ssosettings.ErrInvalidOAuthConfig("If Allowed groups is configured then Groups attribute path must be configured.")
 	}
 
+	teamSyncRules, err := parseTeamSyncMapping(info.Extra[social.TeamSyncMappingKey])
+	if err != nil {
+		return ssosettings.ErrInvalidOAuthConfig("Team sync mapping is invalid: " + err.Error())
+	}
+	for _, rule := range teamSyncRules {
+		if rule.GroupsAttributePath == "" && info.GroupsAttributePath == "" {
+			return ssosettings.ErrInvalidOAuthConfig("If a team sync rule has no groups attribute path then Groups attribute path must be configured.")
+		}
+	}
+
 	return nil
 }
 
@@ -119,6 +137,11 @@ func (s *SocialGenericOAuth) Reload(ctx context.Context, settings ssoModels.SSOS
 		return ssosettings.ErrInvalidSettings.Errorf("SSO settings map cannot be converted to OAuthInfo: %v", err)
 	}
 
+	teamSyncRules, err := parseTeamSyncMapping(newInfo.Extra[social.TeamSyncMappingKey])
+	if err != nil {
+		return ssosettings.ErrInvalidSettings.Errorf("invalid team sync mapping: %v", err)
+	}
+
 	s.reloadMutex.Lock()
 	defer s.reloadMutex.Unlock()
 
@@ -134,6 +157,7 @@ func (s *SocialGenericOAuth) Reload(ctx context.Context, settings ssoModels.SSOS
 	s.teamIdsAttributePath = newInfo.TeamIdsAttributePath
 	s.teamIds = util.SplitString(newInfo.Extra[teamIdsKey])
 	s.allowedOrganizations = util.SplitString(newInfo.Extra[allowedOrganizationsKey])
+	s.teamSyncRules = teamSyncRules
 
 	return nil
 }
@@ -313,6 +337,16 @@ func (s *SocialGenericOAuth) UserInfo(ctx context.Context, client *http.Client,
 		return nil, errMissingGroupMembership
 	}
 
+	if s.teamSyncRules != nil {
+		teams, err := mapTeams(s.teamSyncRules, s.groupsAttributePath, toCheck)
+		if err != nil {
+			// don't remove the memberships of the user when the groups can't be extracted
+			s.log.Warn("Failed to map groups to teams", "err", err)
+		} else {
+			userInfo.Teams = teams
+		}
+	}
+
 	s.log.Debug("User info result", "result", userInfo)
 	return userInfo, nil
 }
@@ -0,0 +1,115 @@
+package connectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// TeamSyncRule maps the groups found in the user info of the identity provider to the teams of an organization.
+type TeamSyncRule struct {
+	// GroupsAttributePath is the JMESPath expression returning the groups, it defaults to groups_attribute_path
+	GroupsAttributePath string `json:"groups_attribute_path"`
+	// Match is the regular expression the groups have to match, every group matches when it's empty
+	Match string `json:"match"`
+	// Team is the name of the team the matching groups are mapped to. It can reference the capture groups of Match,
+	// such as $1, and defaults to the name of the group.
+	Team string `json:"team"`
+	// OrgID is the organization of the team
+	OrgID int64 `json:"org_id"`
+
+	match *regexp.Regexp
+}
+
+// parseTeamSyncMapping parses the `team_sync_mapping` setting. It returns nil when team sync isn't configured.
+func parseTeamSyncMapping(raw string) ([]TeamSyncRule, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var rules []TeamSyncRule
+	if err := json.Unmarshal([]byte(raw), &rules); err != nil {
+		return nil, fmt.Errorf("team sync mapping must be a JSON list of rules: %w", err)
+	}
+
+	for i := range rules {
+		if rules[i].OrgID <= 0 {
+			return nil, fmt.Errorf("team sync rule %d: org_id must be set", i)
+		}
+		if rules[i].Match != "" {
+			match, err := regexp.Compile(rules[i].Match)
+			if err != nil {
+				return nil, fmt.Errorf("team sync rule %d: invalid match expression: %w", i, err)
+			}
+			rules[i].match = match
+		}
+	}
+	return rules, nil
+}
+
+// mapTeams returns the names of the teams, by organization, the groups found in the user info are mapped to.
+// Every organization of the rules is part of the result so that the memberships that no longer match are removed.
+func mapTeams(rules []TeamSyncRule, defaultGroupsPath string, sources []*UserInfoJson) (map[int64][]string, error) {
+	teams := make(map[int64][]string)
+	for _, rule := range rules {
+		if _, ok := teams[rule.OrgID]; !ok {
+			teams[rule.OrgID] = []string{}
+		}
+
+		path := rule.GroupsAttributePath
+		if path == "" {
+			path = defaultGroupsPath
+		}
+		if path == "" {
+			continue
+		}
+
+		groups, err := searchGroups(path, sources)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range groups {
+			name, ok := rule.teamName(group)
+			if ok && name != "" && !slices.Contains(teams[rule.OrgID], name) {
+				teams[rule.OrgID] = append(teams[rule.OrgID], name)
+			}
+		}
+	}
+	return teams, nil
+}
+
+// searchGroups returns the groups of the first source where the path matches
+func searchGroups(path string, sources []*UserInfoJson) ([]string, error) {
+	for _, data := range sources {
+		groups, err := util.SearchJSONForStringSliceAttr(path, data.rawJSON)
+		if err != nil {
+			return nil, err
+		}
+		if len(groups) > 0 {
+			return groups, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r TeamSyncRule) teamName(group string) (string, bool) {
+	if r.match == nil {
+		if r.Team == "" {
+			return group, true
+		}
+		return r.Team, true
+	}
+
+	submatches := r.match.FindStringSubmatchIndex(group)
+	if submatches == nil {
+		return "", false
+	}
+	if r.Team == "" {
+		return group, true
+	}
+	return string(r.match.ExpandString(nil, r.Team, group, submatches)), true
+}
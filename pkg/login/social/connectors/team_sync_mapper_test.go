@@ -0,0 +1,85 @@
+package connectors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTeamSyncMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    int
+		wantErr bool
+	}{
+		{name: "should return no rules when not configured", raw: ""},
+		{name: "should parse rules", raw: `[{"match": "^grafana-(.*)$", "team": "$1", "org_id": 1}, {"org_id": 2}]`, want: 2},
+		{name: "should fail on invalid JSON", raw: `grafana:1`, wantErr: true},
+		{name: "should fail on a missing organization", raw: `[{"team": "backend"}]`, wantErr: true},
+		{name: "should fail on an invalid expression", raw: `[{"match": "(", "org_id": 1}]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := parseTeamSyncMapping(tt.raw)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, rules, tt.want)
+		})
+	}
+}
+
+func TestMapTeams(t *testing.T) {
+	sources := []*UserInfoJson{
+		{rawJSON: []byte(`{"groups": ["grafana-backend", "grafana-frontend", "staff"]}`)},
+		{rawJSON: []byte(`{"groups": ["ignored"], "departments": ["sre"]}`)},
+	}
+
+	tests := []struct {
+		name    string
+		mapping string
+		want    map[int64][]string
+	}{
+		{
+			name:    "should map the matching groups using the capture groups",
+			mapping: `[{"match": "^grafana-(.*)$", "team": "$1", "org_id": 1}]`,
+			want:    map[int64][]string{1: {"backend", "frontend"}},
+		},
+		{
+			name:    "should map all the groups to teams of the same name",
+			mapping: `[{"org_id": 2}]`,
+			want:    map[int64][]string{2: {"grafana-backend", "grafana-frontend", "staff"}},
+		},
+		{
+			name:    "should map the matching groups to a fixed team",
+			mapping: `[{"match": "^staff$", "team": "employees", "org_id": 1}, {"match": "^grafana-", "team": "employees", "org_id": 1}]`,
+			want:    map[int64][]string{1: {"employees"}},
+		},
+		{
+			name:    "should search the groups of the rule in every source",
+			mapping: `[{"groups_attribute_path": "departments", "org_id": 3}]`,
+			want:    map[int64][]string{3: {"sre"}},
+		},
+		{
+			name:    "should return the organizations without matching groups",
+			mapping: `[{"match": "^admins$", "org_id": 1}]`,
+			want:    map[int64][]string{1: {}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := parseTeamSyncMapping(tt.mapping)
+			require.NoError(t, err)
+
+			teams, err := mapTeams(rules, "groups", sources)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, teams)
+		})
+	}
+}
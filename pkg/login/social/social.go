@@ -13,6 +13,8 @@ import (
 const (
 	OfflineAccessScope = "offline_access"
 	RoleGrafanaAdmin   = "GrafanaAdmin" // For AzureAD for example this value cannot contain spaces
+	// TeamSyncMappingKey is the extra setting holding the team sync rules of the generic OAuth provider
+	TeamSyncMappingKey = "team_sync_mapping"
 
 	AzureADProviderName      = "azuread"
 	GenericOAuthProviderName = "generic_oauth"
@@ -106,9 +108,11 @@ type BasicUserInfo struct {
 	Role           org.RoleType
 	IsGrafanaAdmin *bool // nil will avoid overriding user's set server admin setting
 	Groups         []string
+	// Teams are the names of the teams, by org id, the user is mapped to by team sync. Nil when team sync isn't configured.
+	Teams map[int64][]string
 }
 
 func (b *BasicUserInfo) String() string {
-	return fmt.Sprintf("Id: %s, Name: %s, Email: %s, Login: %s, Role: %s, Groups: %v",
-		b.Id, b.Name, b.Email, b.Login, b.Role, b.Groups)
+	return fmt.Sprintf("Id: %s, Name: %s, Email: %s, Login: %s, Role: %s, Groups: %v, Teams: %v",
+		b.Id, b.Name, b.Email, b.Login, b.Role, b.Groups, b.Teams)
 }
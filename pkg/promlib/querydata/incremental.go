@@ -0,0 +1,343 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	// defaultOverlapWindow is the most recent part of the range that is always queried, as Prometheus may still
+	// ingest samples for it. It is the same default as the incremental querying of the frontend.
+	defaultOverlapWindow = 10 * time.Minute
+	// minChunkDuration is the minimum length of the cached chunks, chunks are a multiple of the step
+	minChunkDuration = time.Hour
+	chunkCacheTTL    = 24 * time.Hour
+	// maxCachedChunks bounds the memory used by the cache of a data source
+	maxCachedChunks = 10000
+	// evictedChunks is how many of the oldest chunks are evicted when the cache is full
+	evictedChunks = maxCachedChunks / 10
+)
+
+var errNotStitchable = errors.New("frames can't be split by time")
+
+// rangeBoundModifierRegexp matches the @ modifiers resolved against the start or the end of the queried range, the
+// results of such queries change with the range and can't be split into chunks
+var rangeBoundModifierRegexp = regexp.MustCompile(`@\s*(start|end)\s*\(\s*\)`)
+
+// identityHeaders are the headers forwarding the identity of users to Prometheus
+var identityHeaders = []string{
+	backend.OAuthIdentityTokenHeaderName,
+	backend.OAuthIdentityIDTokenHeaderName,
+	backend.CookiesHeaderName,
+	"X-Grafana-Id",
+	"X-Grafana-User",
+}
+
+// rangeCache caches the results of range queries in chunks aligned on step boundaries. Only the chunks that
+// ended before the overlap window are cached, the most recent part of the range is always queried again.
+type rangeCache struct {
+	chunks        *cache.Cache
+	overlapWindow time.Duration
+	now           func() time.Time
+}
+
+func newRangeCache(overlapWindow time.Duration) *rangeCache {
+	return &rangeCache{
+		chunks:        cache.New(chunkCacheTTL, time.Hour),
+		overlapWindow: overlapWindow,
+		now:           time.Now,
+	}
+}
+
+// evictOldest removes the n chunks that were cached first
+func (rc *rangeCache) evictOldest(n int) {
+	items := rc.chunks.Items()
+	keys := make([]string, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	// the chunks are cached with the same TTL, the first to expire are the oldest
+	sort.Slice(keys, func(i, j int) bool {
+		return items[keys[i]].Expiration < items[keys[j]].Expiration
+	})
+	if n > len(keys) {
+		n = len(keys)
+	}
+	for _, key := range keys[:n] {
+		rc.chunks.Delete(key)
+	}
+}
+
+// rangeCacheUser returns who the cached chunks of a request belong to. It's empty when the identity of the user
+// isn't forwarded to Prometheus as the chunks are then shared by all users.
+func rangeCacheUser(req *backend.QueryDataRequest) string {
+	var forwarded []string
+	for _, header := range identityHeaders {
+		if value := req.GetHTTPHeader(header); value != "" {
+			forwarded = append(forwarded, header+"="+value)
+		}
+	}
+	if len(forwarded) == 0 {
+		return ""
+	}
+
+	if req.PluginContext.User != nil && req.PluginContext.User.Login != "" {
+		return "user:" + req.PluginContext.User.Login
+	}
+	sum := sha256.Sum256([]byte(strings.Join(forwarded, "\x00")))
+	return "headers:" + hex.EncodeToString(sum[:])
+}
+
+// segment is a part of the range that is queried at once
+type segment struct {
+	start time.Time
+	end   time.Time
+	// chunks are the starts of the completed chunks of the segment to cache
+	chunks []time.Time
+}
+
+// incrementalRangeQuery queries the chunks of the range that aren't cached and stitches them together with the
+// cached ones. Consecutive missing chunks are queried at once along with the most recent part of the range when
+// it follows them.
+func (s *QueryData) incrementalRangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool, cacheUser string) backend.DataResponse {
+	tr := q.TimeRange()
+	if tr.Step <= 0 || rangeBoundModifierRegexp.MatchString(q.Expr) {
+		return s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+	}
+
+	chunk := chunkDuration(tr.Step)
+	// chunks starting at or after mutableFrom may still change
+	mutableFrom := models.AlignTimeRange(s.rangeCache.now().Add(-s.rangeCache.overlapWindow), chunk, q.UtcOffsetSec)
+	firstChunk := models.AlignTimeRange(tr.Start, chunk, q.UtcOffsetSec)
+	if !firstChunk.Before(mutableFrom) {
+		return s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+	}
+
+	var pieces []data.Frames
+	var segments []*segment
+	var current *segment
+	for start := firstChunk; !start.After(tr.End) && start.Before(mutableFrom); start = start.Add(chunk) {
+		if frames, ok := s.rangeCache.chunks.Get(chunkKey(q, start, enablePrometheusDataplaneFlag, cacheUser)); ok {
+			pieces = append(pieces, frames.(data.Frames))
+			current = nil
+			continue
+		}
+		if current == nil {
+			current = &segment{start: start}
+			segments = append(segments, current)
+			// the segment is stitched in place of its chunks
+			pieces = append(pieces, nil)
+		}
+		current.end = start.Add(chunk - tr.Step)
+		current.chunks = append(current.chunks, start)
+	}
+
+	// the most recent part of the range is queried along with the previous segment when it follows it
+	if !mutableFrom.After(tr.End) {
+		if current == nil {
+			current = &segment{start: mutableFrom}
+			segments = append(segments, current)
+			pieces = append(pieces, nil)
+		}
+		current.end = tr.End
+	}
+
+	segmentIdx := 0
+	for i := range pieces {
+		if pieces[i] != nil {
+			continue
+		}
+		seg := segments[segmentIdx]
+		segmentIdx++
+
+		sq := *q
+		sq.Start, sq.End = seg.start, seg.end
+		res := s.fetchRange(ctx, c, &sq, enablePrometheusDataplaneFlag)
+		if res.Error != nil || res.Status >= 300 {
+			return res
+		}
+		if err := s.cacheChunks(q, seg, chunk, res.Frames, enablePrometheusDataplaneFlag, cacheUser); err != nil {
+			s.log.FromContext(ctx).Debug("Falling back to querying the whole range", "query", q.Expr, "error", err)
+			return s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+		}
+		pieces[i] = res.Frames
+	}
+
+	frames, err := stitchFrames(pieces, tr.Start, tr.End)
+	if err != nil {
+		s.log.FromContext(ctx).Debug("Falling back to querying the whole range", "query", q.Expr, "error", err)
+		return s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+	}
+
+	// Add frame to attach metadata
+	if len(frames) == 0 {
+		frames = append(frames, data.NewFrame(""))
+		addMetadataToMultiFrame(q, frames[0], enablePrometheusDataplaneFlag)
+	}
+	frames[0].Meta.ExecutedQueryString = executedQueryString(q)
+
+	return backend.DataResponse{
+		Frames: frames,
+		Status: backend.StatusOK,
+	}
+}
+
+// cacheChunks caches the frames of the completed chunks of a segment
+func (s *QueryData) cacheChunks(q *models.Query, seg *segment, chunk time.Duration, frames data.Frames, enablePrometheusDataplaneFlag bool, cacheUser string) error {
+	for _, start := range seg.chunks {
+		if s.rangeCache.chunks.ItemCount() >= maxCachedChunks {
+			s.rangeCache.evictOldest(evictedChunks)
+		}
+
+		chunkFrames := data.Frames{}
+		for _, frame := range frames {
+			if len(frame.Fields) == 0 {
+				continue
+			}
+			sliced, err := sliceFrame(frame, start, start.Add(chunk-time.Nanosecond))
+			if err != nil {
+				return err
+			}
+			if sliced.Rows() > 0 {
+				chunkFrames = append(chunkFrames, sliced)
+			}
+		}
+		s.rangeCache.chunks.SetDefault(chunkKey(q, start, enablePrometheusDataplaneFlag, cacheUser), chunkFrames)
+	}
+	return nil
+}
+
+// stitchFrames concatenates the frames of the same series found in the pieces, in order, and only keeps the rows
+// between from and to. The labels and metadata of the series are the ones of their first frame.
+func stitchFrames(pieces []data.Frames, from, to time.Time) (data.Frames, error) {
+	stitched := data.Frames{}
+	bySeries := map[string]*data.Frame{}
+
+	for _, frames := range pieces {
+		for _, frame := range frames {
+			if len(frame.Fields) == 0 {
+				continue
+			}
+
+			sliced, err := sliceFrame(frame, from, to)
+			if err != nil {
+				return nil, err
+			}
+			if sliced.Rows() == 0 {
+				continue
+			}
+
+			key := seriesKey(frame)
+			out, ok := bySeries[key]
+			if !ok {
+				bySeries[key] = sliced
+				stitched = append(stitched, sliced)
+				continue
+			}
+			if len(out.Fields) != len(sliced.Fields) {
+				return nil, errNotStitchable
+			}
+			for i, field := range sliced.Fields {
+				if field.Type() != out.Fields[i].Type() {
+					return nil, errNotStitchable
+				}
+				for row := 0; row < field.Len(); row++ {
+					out.Fields[i].Append(field.CopyAt(row))
+				}
+			}
+		}
+	}
+
+	return stitched, nil
+}
+
+// sliceFrame returns a copy of the frame with the rows between from and to included. The metadata is copied
+// without the executed query string.
+func sliceFrame(frame *data.Frame, from, to time.Time) (*data.Frame, error) {
+	timeIdx := -1
+	for i, field := range frame.Fields {
+		if field.Type() == data.FieldTypeTime {
+			timeIdx = i
+			break
+		}
+	}
+	if timeIdx == -1 {
+		return nil, errNotStitchable
+	}
+
+	out := frame.EmptyCopy()
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		meta.ExecutedQueryString = ""
+		out.Meta = &meta
+	}
+	// the empty copy doesn't keep the config of the fields and gives them empty labels
+	for i, field := range frame.Fields {
+		out.Fields[i].Labels = nil
+		if field.Labels != nil {
+			out.Fields[i].Labels = field.Labels.Copy()
+		}
+		if field.Config != nil {
+			config := *field.Config
+			out.Fields[i].Config = &config
+		}
+	}
+
+	for row := 0; row < frame.Fields[timeIdx].Len(); row++ {
+		t := frame.Fields[timeIdx].At(row).(time.Time)
+		if t.Before(from) || t.After(to) {
+			continue
+		}
+		for i, field := range frame.Fields {
+			out.Fields[i].Append(field.CopyAt(row))
+		}
+	}
+	return out, nil
+}
+
+// seriesKey identifies the series of a frame by its name and the names and labels of its fields
+func seriesKey(frame *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		b.WriteByte(0)
+		b.WriteString(field.Name)
+		b.WriteByte(0)
+		b.WriteString(field.Labels.String())
+	}
+	return b.String()
+}
+
+func chunkKey(q *models.Query, start time.Time, enablePrometheusDataplaneFlag bool, cacheUser string) string {
+	return strings.Join([]string{
+		cacheUser,
+		q.Expr,
+		q.LegendFormat,
+		q.Step.String(),
+		strconv.FormatInt(start.UnixMilli(), 10),
+		strconv.FormatBool(enablePrometheusDataplaneFlag),
+	}, "\x00")
+}
+
+// chunkDuration returns the smallest multiple of the step that is at least minChunkDuration long
+func chunkDuration(step time.Duration) time.Duration {
+	if step >= minChunkDuration {
+		return step
+	}
+	n := (minChunkDuration + step - 1) / step
+	return n * step
+}
@@ -0,0 +1,294 @@
+package querydata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// t0 is the first sample of range_incremental.result.json, which has samples every minute for 6 hours
+var t0 = time.Unix(1699999200, 0).UTC()
+
+func TestIncrementalRangeQuery(t *testing.T) {
+	prom := newRecordedPrometheus(t, "../testdata/range_incremental.result.json")
+
+	cached := newTestQueryData(t, prom, `{"incrementalQueryCaching": true, "incrementalQueryOverlapWindow": "10m"}`)
+	plain := newTestQueryData(t, prom, `{}`)
+
+	run := func(t *testing.T, now, from, to time.Time) ([]queryRange, data.Frames) {
+		t.Helper()
+		cached.rangeCache.now = func() time.Time { return now }
+
+		prom.ranges = nil
+		res, err := cached.Execute(context.Background(), rangeQueryRequest(from, to))
+		require.NoError(t, err)
+		ranges := prom.ranges
+
+		expected, err := plain.Execute(context.Background(), rangeQueryRequest(from, to))
+		require.NoError(t, err)
+
+		require.NoError(t, res.Responses["A"].Error)
+		assertFramesEqual(t, expected.Responses["A"].Frames, res.Responses["A"].Frames)
+		return ranges, res.Responses["A"].Frames
+	}
+
+	t.Run("should query the missing chunks along with the most recent part of the range at once", func(t *testing.T) {
+		now := t0.Add(5*time.Hour + 30*time.Minute)
+		ranges, frames := run(t, now, t0.Add(30*time.Minute+10*time.Second), now)
+
+		// the first chunk is queried as a whole to be cached
+		assert.Equal(t, []queryRange{{start: t0, end: now}}, ranges)
+		require.Len(t, frames, 2)
+		assert.Equal(t, "200", frames[0].Fields[1].Labels["code"])
+	})
+
+	t.Run("should only query the most recent part of the range when the chunks are cached", func(t *testing.T) {
+		now := t0.Add(5*time.Hour + 50*time.Minute)
+		ranges, _ := run(t, now, t0.Add(50*time.Minute), now)
+
+		assert.Equal(t, []queryRange{{start: t0.Add(5 * time.Hour), end: now}}, ranges)
+	})
+
+	t.Run("should not query the data source when all the chunks are cached", func(t *testing.T) {
+		now := t0.Add(6 * time.Hour)
+		ranges, _ := run(t, now, t0.Add(10*time.Minute), t0.Add(3*time.Hour+20*time.Minute))
+
+		assert.Empty(t, ranges)
+	})
+
+	t.Run("should query the whole range when it's within the overlap window", func(t *testing.T) {
+		now := t0.Add(6 * time.Hour)
+		ranges, _ := run(t, now, now.Add(-5*time.Minute), now)
+
+		assert.Equal(t, []queryRange{{start: now.Add(-5 * time.Minute), end: now}}, ranges)
+	})
+}
+
+func TestIncrementalRangeQuery_NotShared(t *testing.T) {
+	prom := newRecordedPrometheus(t, "../testdata/range_incremental.result.json")
+	cached := newTestQueryData(t, prom, `{"incrementalQueryCaching": true}`)
+	now := t0.Add(6 * time.Hour)
+	cached.rangeCache.now = func() time.Time { return now }
+
+	execute := func(t *testing.T, req *backend.QueryDataRequest) []queryRange {
+		t.Helper()
+		prom.ranges = nil
+		res, err := cached.Execute(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["A"].Error)
+		return prom.ranges
+	}
+
+	t.Run("should not share the chunks of users whose identity is forwarded", func(t *testing.T) {
+		asUser := func(login string) *backend.QueryDataRequest {
+			req := rangeQueryRequest(t0, t0.Add(3*time.Hour))
+			req.PluginContext.User = &backend.User{Login: login}
+			req.SetHTTPHeader(backend.OAuthIdentityTokenHeaderName, "Bearer "+login)
+			return req
+		}
+
+		require.NotEmpty(t, execute(t, asUser("alice")))
+		assert.Empty(t, execute(t, asUser("alice")))
+		assert.NotEmpty(t, execute(t, asUser("bob")))
+		assert.NotEmpty(t, execute(t, rangeQueryRequest(t0, t0.Add(3*time.Hour))))
+	})
+
+	t.Run("should not split queries resolved against the bounds of the range", func(t *testing.T) {
+		req := rangeQueryRequest(t0.Add(10*time.Minute), t0.Add(3*time.Hour))
+		req.Queries[0].JSON = []byte(`{"expr": "prometheus_http_requests_total @ end()", "range": true, "interval": "60s"}`)
+
+		execute(t, req)
+		assert.Equal(t, []queryRange{{start: t0.Add(10 * time.Minute), end: t0.Add(3 * time.Hour)}}, execute(t, req))
+	})
+}
+
+func TestRangeCache_EvictOldest(t *testing.T) {
+	rc := newRangeCache(defaultOverlapWindow)
+	for _, key := range []string{"a", "b", "c"} {
+		rc.chunks.SetDefault(key, data.Frames{})
+		time.Sleep(time.Millisecond)
+	}
+
+	rc.evictOldest(2)
+
+	_, ok := rc.chunks.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 1, rc.chunks.ItemCount())
+}
+
+func TestStitchFrames(t *testing.T) {
+	series := func(code string, start time.Time, values ...float64) *data.Frame {
+		times := make([]time.Time, len(values))
+		for i := range values {
+			times[i] = start.Add(time.Duration(i) * time.Minute)
+		}
+		return data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, data.Labels{"code": code}, values),
+		)
+	}
+
+	t.Run("should concatenate the frames of the same series", func(t *testing.T) {
+		frames, err := stitchFrames([]data.Frames{
+			{series("200", t0, 1, 2)},
+			{series("500", t0.Add(2*time.Minute), 30), series("200", t0.Add(2*time.Minute), 3, 4)},
+		}, t0.Add(time.Minute), t0.Add(3*time.Minute))
+		require.NoError(t, err)
+
+		require.Len(t, frames, 2)
+		assert.Equal(t, data.Labels{"code": "200"}, frames[0].Fields[1].Labels)
+		assert.Equal(t, 3, frames[0].Rows())
+		assert.Equal(t, 4.0, frames[0].Fields[1].At(2))
+		assert.Equal(t, data.Labels{"code": "500"}, frames[1].Fields[1].Labels)
+	})
+
+	t.Run("should not modify the stitched frames", func(t *testing.T) {
+		first := data.Frames{series("200", t0, 1, 2)}
+
+		_, err := stitchFrames([]data.Frames{first, {series("200", t0.Add(2*time.Minute), 3)}}, t0, t0.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 2, first[0].Rows())
+	})
+
+	t.Run("should fail on frames without time", func(t *testing.T) {
+		_, err := stitchFrames([]data.Frames{{data.NewFrame("", data.NewField("value", nil, []float64{1}))}}, t0, t0.Add(time.Hour))
+		require.ErrorIs(t, err, errNotStitchable)
+	})
+}
+
+func TestChunkDuration(t *testing.T) {
+	assert.Equal(t, time.Hour, chunkDuration(15*time.Second))
+	assert.Equal(t, 63*time.Minute, chunkDuration(7*time.Minute))
+	assert.Equal(t, 2*time.Hour, chunkDuration(2*time.Hour))
+}
+
+func rangeQueryRequest(from, to time.Time) *backend.QueryDataRequest {
+	return &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			TimeRange: backend.TimeRange{From: from, To: to},
+			Interval:  time.Minute,
+			JSON:      []byte(`{"expr": "prometheus_http_requests_total", "range": true, "interval": "60s", "legendFormat": "{{code}}"}`),
+		}},
+	}
+}
+
+func newTestQueryData(t *testing.T, prom *recordedPrometheus, jsonData string) *QueryData {
+	t.Helper()
+	qd, err := New(&http.Client{Transport: prom}, backend.DataSourceInstanceSettings{
+		URL:      "http://localhost:9090",
+		JSONData: json.RawMessage(jsonData),
+	}, log.New())
+	require.NoError(t, err)
+	return qd
+}
+
+func assertFramesEqual(t *testing.T, expected, actual data.Frames) {
+	t.Helper()
+	expectedJSON, err := json.Marshal(expected)
+	require.NoError(t, err)
+	actualJSON, err := json.Marshal(actual)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(expectedJSON), string(actualJSON))
+}
+
+type queryRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// recordedPrometheus answers range queries with the samples of a recorded response that are within the range
+// and on the step boundaries, like Prometheus does
+type recordedPrometheus struct {
+	result struct {
+		Data struct {
+			Result []struct {
+				Metric map[string]string `json:"metric"`
+				Values [][2]any          `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	ranges []queryRange
+}
+
+func newRecordedPrometheus(t *testing.T, fileName string) *recordedPrometheus {
+	t.Helper()
+	//nolint:gosec
+	b, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+
+	prom := &recordedPrometheus{}
+	require.NoError(t, json.Unmarshal(b, &prom.result))
+	return prom
+}
+
+func (p *recordedPrometheus) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	params, err := url.ParseQuery(string(body))
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := strconv.ParseFloat(params.Get("start"), 64)
+	if err != nil {
+		return nil, err
+	}
+	end, err := strconv.ParseFloat(params.Get("end"), 64)
+	if err != nil {
+		return nil, err
+	}
+	step, err := strconv.ParseFloat(params.Get("step"), 64)
+	if err != nil {
+		return nil, err
+	}
+	p.ranges = append(p.ranges, queryRange{start: time.Unix(int64(start), 0).UTC(), end: time.Unix(int64(end), 0).UTC()})
+
+	type series struct {
+		Metric map[string]string `json:"metric"`
+		Values [][2]any          `json:"values"`
+	}
+	result := []series{}
+	for _, s := range p.result.Data.Result {
+		values := [][2]any{}
+		for _, v := range s.Values {
+			ts := v[0].(float64)
+			if ts < start || ts > end || int64(ts-start)%int64(step) != 0 {
+				continue
+			}
+			values = append(values, v)
+		}
+		if len(values) > 0 {
+			result = append(result, series{Metric: s.Metric, Values: values})
+		}
+	}
+
+	res, err := json.Marshal(map[string]any{
+		"status": "success",
+		"data":   map[string]any{"resultType": "matrix", "result": result},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(res)),
+		Request:    req,
+	}, nil
+}
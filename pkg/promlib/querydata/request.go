@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
 	"go.opentelemetry.io/otel/trace"
@@ -43,6 +44,8 @@ type QueryData struct {
 	URL                string
 	TimeInterval       string
 	exemplarSampler    func() exemplar.Sampler
+	// rangeCache is nil unless incremental query caching is enabled
+	rangeCache *rangeCache
 }
 
 func New(
@@ -70,6 +73,25 @@ func New(
 	// standard deviation sampler is the default for backwards compatibility
 	exemplarSampler := exemplar.NewStandardDeviationSampler
 
+	var rc *rangeCache
+	incrementalQueryCaching, err := maputil.GetBoolOptional(jsonData, "incrementalQueryCaching")
+	if err != nil {
+		return nil, err
+	}
+	if incrementalQueryCaching {
+		overlapWindow := defaultOverlapWindow
+		window, err := maputil.GetStringOptional(jsonData, "incrementalQueryOverlapWindow")
+		if err != nil {
+			return nil, err
+		}
+		if window != "" {
+			if overlapWindow, err = gtime.ParseDuration(window); err != nil {
+				return nil, fmt.Errorf("invalid incremental query overlap window: %w", err)
+			}
+		}
+		rc = newRangeCache(overlapWindow)
+	}
+
 	return &QueryData{
 		intervalCalculator: intervalv2.NewCalculator(),
 		tracer:             tracing.DefaultTracer(),
@@ -79,6 +101,7 @@ func New(
 		ID:                 settings.ID,
 		URL:                settings.URL,
 		exemplarSampler:    exemplarSampler,
+		rangeCache:         rc,
 	}, nil
 }
 
@@ -92,8 +115,9 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	hasPromQLScopeFeatureFlag := cfg.FeatureToggles().IsEnabled("promQLScope")
 	hasPrometheusDataplaneFeatureFlag := cfg.FeatureToggles().IsEnabled("prometheusDataplane")
 
+	cacheUser := rangeCacheUser(req)
 	for _, q := range req.Queries {
-		r := s.handleQuery(ctx, q, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag)
+		r := s.handleQuery(ctx, q, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag, cacheUser)
 		if r == nil {
 			continue
 		}
@@ -103,7 +127,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	return &result, nil
 }
 
-func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool) *backend.DataResponse {
+func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool, cacheUser string) *backend.DataResponse {
 	traceCtx, span := s.tracer.Start(ctx, "datasource.prometheus")
 	defer span.End()
 	query, err := models.Parse(span, bq, s.TimeInterval, s.intervalCalculator, fromAlert, hasPromQLScopeFeatureFlag)
@@ -113,14 +137,14 @@ func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromA
 		}
 	}
 
-	r := s.fetch(traceCtx, s.client, query, hasPrometheusDataplaneFeatureFlag)
+	r := s.fetch(traceCtx, s.client, query, hasPrometheusDataplaneFeatureFlag, cacheUser)
 	if r == nil {
 		s.log.FromContext(ctx).Debug("Received nil response from runQuery", "query", query.Expr)
 	}
 	return r
 }
 
-func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *models.Query, enablePrometheusDataplane bool) *backend.DataResponse {
+func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *models.Query, enablePrometheusDataplane bool, cacheUser string) *backend.DataResponse {
 	logger := s.log.FromContext(traceCtx)
 	logger.Debug("Sending query", "start", q.Start, "end", q.End, "step", q.Step, "query", q.Expr)
 
@@ -137,7 +161,7 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 	}
 
 	if q.RangeQuery {
-		res := s.rangeQuery(traceCtx, client, q, enablePrometheusDataplane)
+		res := s.rangeQuery(traceCtx, client, q, enablePrometheusDataplane, cacheUser)
 		if res.Error != nil {
 			if dr.Error == nil {
 				dr.Error = res.Error
@@ -164,7 +188,14 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 	return dr
 }
 
-func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool, cacheUser string) backend.DataResponse {
+	if s.rangeCache != nil {
+		return s.incrementalRangeQuery(ctx, c, q, enablePrometheusDataplaneFlag, cacheUser)
+	}
+	return s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+}
+
+func (s *QueryData) fetchRange(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
 	res, err := c.QueryRange(ctx, q)
 	if err != nil {
 		return backend.DataResponse{
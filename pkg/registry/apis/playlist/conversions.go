@@ -17,12 +17,9 @@ import (
 )
 
 func LegacyUpdateCommandToUnstructured(cmd playlistsvc.UpdatePlaylistCommand) unstructured.Unstructured {
-	items := []map[string]string{}
+	items := []any{}
 	for _, item := range cmd.Items {
-		items = append(items, map[string]string{
-			"type":  item.Type,
-			"value": item.Value,
-		})
+		items = append(items, itemToUnstructured(item))
 	}
 	obj := unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -37,6 +34,23 @@ func LegacyUpdateCommandToUnstructured(cmd playlistsvc.UpdatePlaylistCommand) un
 	return obj
 }
 
+// itemToUnstructured converts the item through JSON so that it only holds the types unstructured objects accept
+func itemToUnstructured(item playlistsvc.PlaylistItem) map[string]any {
+	obj := map[string]any{}
+	b, err := json.Marshal(convertToK8sItem(playlistsvc.PlaylistItemDTO{
+		Type:      item.Type,
+		Value:     item.Value,
+		Duration:  item.Duration,
+		Schedule:  item.Schedule,
+		Variables: item.Variables,
+		TimeRange: item.TimeRange,
+	}))
+	if err == nil {
+		_ = json.Unmarshal(b, &obj)
+	}
+	return obj
+}
+
 func UnstructuredToLegacyPlaylist(item unstructured.Unstructured) *playlistsvc.Playlist {
 	spec := item.Object["spec"].(map[string]any)
 	return &playlistsvc.Playlist{
@@ -71,10 +85,7 @@ func convertToK8sResource(v *playlistsvc.PlaylistDTO, namespacer request.Namespa
 		Interval: v.Interval,
 	}
 	for _, item := range v.Items {
-		spec.Items = append(spec.Items, playlist.Item{
-			Type:  playlist.ItemType(item.Type),
-			Value: item.Value,
-		})
+		spec.Items = append(spec.Items, convertToK8sItem(item))
 	}
 
 	p := &playlist.Playlist{
@@ -116,14 +127,59 @@ func convertToLegacyUpdateCommand(p *playlist.Playlist, orgId int64) (*playlists
 		if item.Type == playlist.ItemTypeDashboardById {
 			return nil, fmt.Errorf("unsupported item type: %s", item.Type)
 		}
-		cmd.Items = append(cmd.Items, playlistsvc.PlaylistItem{
-			Type:  string(item.Type),
-			Value: item.Value,
-		})
+		cmd.Items = append(cmd.Items, convertToLegacyItem(item))
 	}
 	return cmd, nil
 }
 
+func convertToK8sItem(item playlistsvc.PlaylistItemDTO) playlist.Item {
+	v := playlist.Item{
+		Type:      playlist.ItemType(item.Type),
+		Value:     item.Value,
+		Duration:  item.Duration,
+		Variables: item.Variables,
+	}
+	if item.Schedule != nil {
+		v.Schedule = &playlist.ItemSchedule{
+			Days:     item.Schedule.Days,
+			From:     item.Schedule.From,
+			To:       item.Schedule.To,
+			Timezone: item.Schedule.Timezone,
+		}
+	}
+	if item.TimeRange != nil {
+		v.TimeRange = &playlist.ItemTimeRange{
+			From: item.TimeRange.From,
+			To:   item.TimeRange.To,
+		}
+	}
+	return v
+}
+
+func convertToLegacyItem(item playlist.Item) playlistsvc.PlaylistItem {
+	v := playlistsvc.PlaylistItem{
+		Type:      string(item.Type),
+		Value:     item.Value,
+		Duration:  item.Duration,
+		Variables: item.Variables,
+	}
+	if item.Schedule != nil {
+		v.Schedule = &playlistsvc.ItemSchedule{
+			Days:     item.Schedule.Days,
+			From:     item.Schedule.From,
+			To:       item.Schedule.To,
+			Timezone: item.Schedule.Timezone,
+		}
+	}
+	if item.TimeRange != nil {
+		v.TimeRange = &playlistsvc.ItemTimeRange{
+			From: item.TimeRange.From,
+			To:   item.TimeRange.To,
+		}
+	}
+	return v
+}
+
 // Read legacy ID from metadata annotations
 func getLegacyID(item *unstructured.Unstructured) int64 {
 	meta, err := utils.MetaAccessor(item)
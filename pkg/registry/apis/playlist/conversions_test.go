@@ -68,3 +68,30 @@ func TestPlaylistConversion(t *testing.T) {
 		}
 	  }`, string(out))
 }
+
+func TestPlaylistItemConversion(t *testing.T) {
+	item := playlist.PlaylistItemDTO{
+		Type:      "dashboard_by_uid",
+		Value:     "ops",
+		Duration:  "30s",
+		Schedule:  &playlist.ItemSchedule{Days: []string{"mon"}, From: "08:00", To: "18:00", Timezone: "Europe/Paris"},
+		Variables: map[string]string{"env": "prod"},
+		TimeRange: &playlist.ItemTimeRange{From: "now-6h", To: "now"},
+	}
+	dst := convertToK8sResource(&playlist.PlaylistDTO{Uid: "abc", Name: "TV", Interval: "5m", Items: []playlist.PlaylistItemDTO{item}},
+		request.GetNamespaceMapper(nil))
+
+	cmd, err := convertToLegacyUpdateCommand(dst, 1)
+	require.NoError(t, err)
+	require.Equal(t, []playlist.PlaylistItem{{
+		Type:      item.Type,
+		Value:     item.Value,
+		Duration:  item.Duration,
+		Schedule:  item.Schedule,
+		Variables: item.Variables,
+		TimeRange: item.TimeRange,
+	}}, cmd.Items)
+
+	obj := LegacyUpdateCommandToUnstructured(*cmd)
+	require.Equal(t, []playlist.PlaylistItemDTO{item}, UnstructuredToLegacyPlaylistDTO(obj).Items)
+}
@@ -0,0 +1,183 @@
+package accessrequest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	// anyone can request access, reviewers are checked against the permissions of each folder
+	routeRegister.Group("/api/access-requests", func(r routing.RouteRegister) {
+		r.Get("/", routing.Wrap(s.listHandler))
+		r.Post("/", routing.Wrap(s.createHandler))
+		r.Post("/:id/approve", routing.Wrap(s.approveHandler))
+		r.Post("/:id/deny", routing.Wrap(s.denyHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+}
+
+// swagger:route GET /access-requests access_requests listAccessRequests
+//
+// List the access requests of the signed in user and the access requests they can review.
+//
+// Requests can be reviewed by users who can manage the permissions of the requested folder.
+//
+// Responses:
+// 200: listAccessRequestsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) listHandler(c *contextmodel.ReqContext) response.Response {
+	if errResp := requireUser(c); errResp != nil {
+		return errResp
+	}
+
+	requests, err := s.List(c.Req.Context(), c.SignedInUser, Status(c.Query("status")))
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list access requests", err)
+	}
+
+	now := s.now()
+	dtos := make([]DTO, 0, len(requests))
+	for _, req := range requests {
+		dtos = append(dtos, req.ToDTO(now))
+	}
+	return response.JSON(http.StatusOK, dtos)
+}
+
+// swagger:route POST /access-requests access_requests createAccessRequest
+//
+// Request a permission on a folder for a limited time.
+//
+// Responses:
+// 200: accessRequestResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) createHandler(c *contextmodel.ReqContext) response.Response {
+	if errResp := requireUser(c); errResp != nil {
+		return errResp
+	}
+	cmd := CreateCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	req, err := s.Create(c.Req.Context(), c.SignedInUser, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create access request", err)
+	}
+	return response.JSON(http.StatusOK, req.ToDTO(s.now()))
+}
+
+// swagger:route POST /access-requests/{access_request_id}/approve access_requests approveAccessRequest
+//
+// Approve an access request, the requested permission is granted until it expires.
+//
+// Requires permission to manage the permissions of the requested folder.
+//
+// Responses:
+// 200: accessRequestResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) approveHandler(c *contextmodel.ReqContext) response.Response {
+	return s.reviewHandler(c, s.Approve, "Failed to approve access request")
+}
+
+// swagger:route POST /access-requests/{access_request_id}/deny access_requests denyAccessRequest
+//
+// Deny an access request.
+//
+// Requires permission to manage the permissions of the requested folder.
+//
+// Responses:
+// 200: accessRequestResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) denyHandler(c *contextmodel.ReqContext) response.Response {
+	return s.reviewHandler(c, s.Deny, "Failed to deny access request")
+}
+
+type reviewFunc func(ctx context.Context, reviewer identity.Requester, id int64, cmd ReviewCommand) (*AccessRequest, error)
+
+func (s *Service) reviewHandler(c *contextmodel.ReqContext, review reviewFunc, failure string) response.Response {
+	if errResp := requireUser(c); errResp != nil {
+		return errResp
+	}
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+	cmd := ReviewCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	req, err := review(c.Req.Context(), c.SignedInUser, id, cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, failure, err)
+	}
+	return response.JSON(http.StatusOK, req.ToDTO(s.now()))
+}
+
+func requireUser(c *contextmodel.ReqContext) response.Response {
+	if namespace, _ := c.SignedInUser.GetNamespacedID(); namespace != identity.NamespaceUser {
+		return response.Error(http.StatusForbidden, "Access requests are only available to users", nil)
+	}
+	return nil
+}
+
+// swagger:parameters listAccessRequests
+type ListAccessRequestsParams struct {
+	// Only return the requests with this status, one of pending, approved or denied
+	// in:query
+	// required:false
+	Status string `json:"status"`
+}
+
+// swagger:parameters createAccessRequest
+type CreateAccessRequestParams struct {
+	// in:body
+	// required:true
+	Body CreateCommand
+}
+
+// swagger:parameters approveAccessRequest denyAccessRequest
+type ReviewAccessRequestParams struct {
+	// in:path
+	// required:true
+	AccessRequestID int64 `json:"access_request_id"`
+	// in:body
+	// required:false
+	Body ReviewCommand
+}
+
+// swagger:response listAccessRequestsResponse
+type ListAccessRequestsResponse struct {
+	// in: body
+	Body []DTO `json:"body"`
+}
+
+// swagger:response accessRequestResponse
+type AccessRequestResponse struct {
+	// in: body
+	Body DTO `json:"body"`
+}
@@ -0,0 +1,116 @@
+package accessrequest
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusDenied   Status = "denied"
+	// StatusExpired is the status of approved requests once their grant expired, it's never stored
+	StatusExpired Status = "expired"
+)
+
+var (
+	ErrNotFound          = errutil.NotFound("accessrequest.notFound", errutil.WithPublicMessage("Access request not found"))
+	ErrNotPending        = errutil.Conflict("accessrequest.notPending", errutil.WithPublicMessage("Access request was already reviewed"))
+	ErrAlreadyRequested  = errutil.Conflict("accessrequest.alreadyRequested", errutil.WithPublicMessage("An access request to this folder is already pending"))
+	ErrAlreadyGranted    = errutil.Conflict("accessrequest.alreadyGranted", errutil.WithPublicMessage("The user already has a permanent permission on this folder"))
+	ErrSelfReview        = errutil.Forbidden("accessrequest.selfReview", errutil.WithPublicMessage("Access requests can't be reviewed by their requester"))
+	ErrInvalidPermission = errutil.BadRequest("accessrequest.invalidPermission", errutil.WithPublicMessage("Permission must be one of View, Edit or Admin"))
+	ErrInvalidDuration   = errutil.BadRequest("accessrequest.invalidDuration")
+	ErrMissingReason     = errutil.BadRequest("accessrequest.missingJustification", errutil.WithPublicMessage("A justification is required"))
+	ErrFolderNotFound    = errutil.NotFound("accessrequest.folderNotFound", errutil.WithPublicMessage("Folder not found"))
+)
+
+// permissions are the folder permissions that can be requested
+var permissions = []string{"View", "Edit", "Admin"}
+
+// AccessRequest is the request of a user for a permission on a folder. Once approved, the permission is granted until
+// ExpiresAt and revoked by the expiry job of the access control service.
+type AccessRequest struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	UserID        int64  `xorm:"user_id"`
+	FolderUID     string `xorm:"folder_uid"`
+	Permission    string
+	Justification string
+	// Duration is how long the permission was requested for, in seconds
+	Duration      int64
+	Status        Status
+	ReviewerID    int64  `xorm:"reviewer_id"`
+	ReviewComment string `xorm:"review_comment"`
+	// ExpiresAt is the unix time the granted permission is revoked at, it's set once the request is approved
+	ExpiresAt int64 `xorm:"expires_at"`
+	Created   time.Time
+	Updated   time.Time
+}
+
+func (AccessRequest) TableName() string {
+	return "access_request"
+}
+
+// DTO is the API representation of an access request
+type DTO struct {
+	ID            int64      `json:"id"`
+	UserID        int64      `json:"userId"`
+	FolderUID     string     `json:"folderUid"`
+	Permission    string     `json:"permission"`
+	Justification string     `json:"justification"`
+	Duration      string     `json:"duration"`
+	Status        Status     `json:"status"`
+	ReviewerID    int64      `json:"reviewerId,omitempty"`
+	ReviewComment string     `json:"reviewComment,omitempty"`
+	Expires       *time.Time `json:"expires,omitempty"`
+	Created       time.Time  `json:"created"`
+	Updated       time.Time  `json:"updated"`
+}
+
+func (r *AccessRequest) ToDTO(now time.Time) DTO {
+	dto := DTO{
+		ID:            r.ID,
+		UserID:        r.UserID,
+		FolderUID:     r.FolderUID,
+		Permission:    r.Permission,
+		Justification: r.Justification,
+		Duration:      (time.Duration(r.Duration) * time.Second).String(),
+		Status:        r.Status,
+		ReviewerID:    r.ReviewerID,
+		ReviewComment: r.ReviewComment,
+		Created:       r.Created,
+		Updated:       r.Updated,
+	}
+	if r.ExpiresAt > 0 {
+		expires := time.Unix(r.ExpiresAt, 0)
+		dto.Expires = &expires
+		if r.Status == StatusApproved && !expires.After(now) {
+			dto.Status = StatusExpired
+		}
+	}
+	return dto
+}
+
+type CreateCommand struct {
+	FolderUID     string `json:"folderUid" binding:"Required"`
+	Permission    string `json:"permission" binding:"Required"`
+	Justification string `json:"justification"`
+	// Duration is how long the permission is requested for, such as 4h
+	Duration string `json:"duration" binding:"Required"`
+}
+
+type ReviewCommand struct {
+	// Duration overrides how long an approved permission is granted for, it defaults to the requested duration
+	Duration string `json:"duration"`
+	Comment  string `json:"comment"`
+}
+
+type ListQuery struct {
+	OrgID  int64
+	UserID int64
+	Status Status
+}
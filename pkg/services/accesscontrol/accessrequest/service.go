@@ -0,0 +1,248 @@
+// Package accessrequest lets users request a permission on a folder for a limited time. Users who can manage the
+// permissions of the folder approve or deny the requests, approved permissions are granted until they expire.
+package accessrequest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type Service struct {
+	store             *sqlStore
+	db                db.DB
+	cfg               *setting.Cfg
+	accessControl     ac.AccessControl
+	acService         ac.Service
+	folderPermissions ac.FolderPermissionsService
+	dashboardService  dashboards.DashboardService
+	log               log.Logger
+	now               func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg, sql db.DB, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	acService ac.Service, folderPermissions ac.FolderPermissionsService, dashboardService dashboards.DashboardService,
+) *Service {
+	s := &Service{
+		store:             &sqlStore{db: sql},
+		db:                sql,
+		cfg:               cfg,
+		accessControl:     accessControl,
+		acService:         acService,
+		folderPermissions: folderPermissions,
+		dashboardService:  dashboardService,
+		log:               log.New("accessrequest"),
+		now:               time.Now,
+	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
+}
+
+// Create stores the request of a user for a permission on a folder
+func (s *Service) Create(ctx context.Context, requester identity.Requester, cmd CreateCommand) (*AccessRequest, error) {
+	userID, err := identity.UserIdentifier(requester.GetNamespacedID())
+	if err != nil {
+		return nil, err
+	}
+
+	if !slices.Contains(permissions, cmd.Permission) {
+		return nil, ErrInvalidPermission.Errorf("invalid permission %q", cmd.Permission)
+	}
+	if strings.TrimSpace(cmd.Justification) == "" {
+		return nil, ErrMissingReason.Errorf("missing justification")
+	}
+	duration, err := s.parseDuration(cmd.Duration)
+	if err != nil {
+		return nil, err
+	}
+
+	folder, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: cmd.FolderUID, OrgID: requester.GetOrgID()})
+	if err != nil || !folder.IsFolder {
+		if err != nil && !errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, err
+		}
+		return nil, ErrFolderNotFound.Errorf("folder %s not found", cmd.FolderUID)
+	}
+
+	now := s.now()
+	req := &AccessRequest{
+		OrgID:         requester.GetOrgID(),
+		UserID:        userID,
+		FolderUID:     cmd.FolderUID,
+		Permission:    cmd.Permission,
+		Justification: strings.TrimSpace(cmd.Justification),
+		Duration:      int64(duration / time.Second),
+		Status:        StatusPending,
+		Created:       now,
+		Updated:       now,
+	}
+	if err := s.store.create(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// List returns the requests of an organization that were made by the user or that the user can review
+func (s *Service) List(ctx context.Context, usr identity.Requester, status Status) ([]*AccessRequest, error) {
+	userID, err := identity.UserIdentifier(usr.GetNamespacedID())
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := s.store.list(ctx, ListQuery{OrgID: usr.GetOrgID(), Status: status})
+	if err != nil {
+		return nil, err
+	}
+
+	canReview := make(map[string]bool)
+	result := make([]*AccessRequest, 0, len(requests))
+	for _, req := range requests {
+		if req.UserID != userID {
+			allowed, ok := canReview[req.FolderUID]
+			if !ok {
+				if allowed, err = s.canReview(ctx, usr, req); err != nil {
+					return nil, err
+				}
+				canReview[req.FolderUID] = allowed
+			}
+			if !allowed {
+				continue
+			}
+		}
+		result = append(result, req)
+	}
+	return result, nil
+}
+
+// Approve grants the requested permission to the requester until it expires
+func (s *Service) Approve(ctx context.Context, reviewer identity.Requester, id int64, cmd ReviewCommand) (*AccessRequest, error) {
+	req, err := s.getForReview(ctx, reviewer, id)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	if cmd.Duration != "" {
+		if duration, err = s.parseDuration(cmd.Duration); err != nil {
+			return nil, err
+		}
+	}
+
+	now := s.now()
+	expires := now.Add(duration)
+	req.Status = StatusApproved
+	req.ReviewComment = cmd.Comment
+	req.ExpiresAt = expires.Unix()
+	req.Updated = now
+
+	err = s.db.InTransaction(ctx, func(ctx context.Context) error {
+		if err := s.store.review(ctx, req); err != nil {
+			return err
+		}
+		// a user has a single managed permission per folder, setting the requested one would replace a permanent one
+		current, err := s.folderPermissions.GetPermissions(ctx, reviewer, req.FolderUID)
+		if err != nil {
+			return err
+		}
+		for _, p := range current {
+			if p.UserId == req.UserID && p.IsManaged && !p.IsInherited && p.Expires.IsZero() {
+				return ErrAlreadyGranted.Errorf("user %d already has a permission on folder %s", req.UserID, req.FolderUID)
+			}
+		}
+		_, err = s.folderPermissions.SetPermissions(ctx, req.OrgID, req.FolderUID, ac.SetResourcePermissionCommand{
+			UserID:     req.UserID,
+			Permission: req.Permission,
+			Expires:    expires,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.FromContext(ctx).Info("Access request approved", "id", req.ID, "userId", req.UserID, "folderUid", req.FolderUID,
+		"permission", req.Permission, "expires", expires)
+	s.acService.ClearUserPermissionCache(&user.SignedInUser{UserID: req.UserID, OrgID: req.OrgID})
+	return req, nil
+}
+
+// Deny rejects a pending request
+func (s *Service) Deny(ctx context.Context, reviewer identity.Requester, id int64, cmd ReviewCommand) (*AccessRequest, error) {
+	req, err := s.getForReview(ctx, reviewer, id)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Status = StatusDenied
+	req.ReviewComment = cmd.Comment
+	req.Updated = s.now()
+	if err := s.store.review(ctx, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// getForReview returns a pending request the reviewer is allowed to review
+func (s *Service) getForReview(ctx context.Context, reviewer identity.Requester, id int64) (*AccessRequest, error) {
+	reviewerID, err := identity.UserIdentifier(reviewer.GetNamespacedID())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := s.store.get(ctx, reviewer.GetOrgID(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed, err := s.canReview(ctx, reviewer, req)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		// don't tell whether the request exists
+		return nil, ErrNotFound.Errorf("user %d can't review access request %d", reviewerID, id)
+	}
+	if req.UserID == reviewerID {
+		return nil, ErrSelfReview.Errorf("user %d can't review their own access request", reviewerID)
+	}
+	if req.Status != StatusPending {
+		return nil, ErrNotPending.Errorf("access request %d is %s", id, req.Status)
+	}
+
+	req.ReviewerID = reviewerID
+	return req, nil
+}
+
+// canReview tells whether the user can manage the permissions of the folder of a request
+func (s *Service) canReview(ctx context.Context, usr identity.Requester, req *AccessRequest) (bool, error) {
+	return s.accessControl.Evaluate(ctx, usr, ac.EvalPermission(dashboards.ActionFoldersPermissionsWrite,
+		dashboards.ScopeFoldersProvider.GetResourceScopeUID(req.FolderUID)))
+}
+
+func (s *Service) parseDuration(value string) (time.Duration, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		invalid := ErrInvalidDuration.Errorf("invalid duration %q", value)
+		invalid.PublicMessage = "Duration must be positive, such as 4h"
+		return 0, invalid
+	}
+
+	if maxDuration := s.cfg.RBACAccessRequestMaxDuration; maxDuration > 0 && duration > maxDuration {
+		invalid := ErrInvalidDuration.Errorf("duration %s exceeds %s", duration, maxDuration)
+		invalid.PublicMessage = "Duration can't exceed " + maxDuration.String()
+		return 0, invalid
+	}
+	return duration, nil
+}
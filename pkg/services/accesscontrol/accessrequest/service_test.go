@@ -0,0 +1,144 @@
+package accessrequest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type recordingPermissionsService struct {
+	actest.FakePermissionsService
+	commands []accesscontrol.SetResourcePermissionCommand
+}
+
+func (r *recordingPermissionsService) SetPermissions(ctx context.Context, orgID int64, resourceID string, commands ...accesscontrol.SetResourcePermissionCommand) ([]accesscontrol.ResourcePermission, error) {
+	r.commands = append(r.commands, commands...)
+	return nil, nil
+}
+
+func setupTestService(t *testing.T, canReview bool) (*Service, *recordingPermissionsService) {
+	t.Helper()
+
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{UID: "folder", IsFolder: true}, nil).Maybe()
+
+	cfg := setting.NewCfg()
+	cfg.RBACAccessRequestMaxDuration = 24 * time.Hour
+	testDB := db.InitTestDB(t)
+	permissions := &recordingPermissionsService{}
+	return &Service{
+		store:             &sqlStore{db: testDB},
+		db:                testDB,
+		cfg:               cfg,
+		accessControl:     actest.FakeAccessControl{ExpectedEvaluate: canReview},
+		acService:         actest.FakeService{},
+		folderPermissions: permissions,
+		dashboardService:  dashboardService,
+		log:               log.NewNopLogger(),
+		now:               func() time.Time { return time.Unix(1700000000, 0) },
+	}, permissions
+}
+
+func TestIntegrationAccessRequest(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	requester := &user.SignedInUser{UserID: 2, OrgID: 1}
+	reviewer := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	t.Run("should grant the requested permission until it expires", func(t *testing.T) {
+		s, permissions := setupTestService(t, true)
+
+		req, err := s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "Edit", Justification: "incident", Duration: "4h"})
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, req.Status)
+
+		_, err = s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "View", Justification: "incident", Duration: "1h"})
+		require.ErrorIs(t, err, ErrAlreadyRequested)
+
+		_, err = s.Approve(ctx, requester, req.ID, ReviewCommand{})
+		require.ErrorIs(t, err, ErrSelfReview)
+
+		approved, err := s.Approve(ctx, reviewer, req.ID, ReviewCommand{Duration: "1h", Comment: "ok"})
+		require.NoError(t, err)
+		assert.Equal(t, StatusApproved, approved.Status)
+
+		expires := s.now().Add(time.Hour)
+		require.Len(t, permissions.commands, 1)
+		assert.Equal(t, accesscontrol.SetResourcePermissionCommand{UserID: 2, Permission: "Edit", Expires: expires}, permissions.commands[0])
+
+		_, err = s.Deny(ctx, reviewer, req.ID, ReviewCommand{})
+		require.ErrorIs(t, err, ErrNotPending)
+
+		stored, err := s.store.get(ctx, 1, req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stored.ReviewerID)
+		assert.Equal(t, StatusApproved, stored.ToDTO(expires.Add(-time.Minute)).Status)
+		assert.Equal(t, StatusExpired, stored.ToDTO(expires).Status)
+	})
+
+	t.Run("should not replace a permanent permission", func(t *testing.T) {
+		s, permissions := setupTestService(t, true)
+		permissions.ExpectedPermissions = []accesscontrol.ResourcePermission{{UserId: 2, IsManaged: true}}
+
+		req, err := s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "Admin", Justification: "incident", Duration: "1h"})
+		require.NoError(t, err)
+
+		_, err = s.Approve(ctx, reviewer, req.ID, ReviewCommand{})
+		require.ErrorIs(t, err, ErrAlreadyGranted)
+		assert.Empty(t, permissions.commands)
+
+		stored, err := s.store.get(ctx, 1, req.ID)
+		require.NoError(t, err)
+		assert.Equal(t, StatusPending, stored.Status, "failed approvals should be rolled back")
+	})
+
+	t.Run("should validate requests", func(t *testing.T) {
+		s, _ := setupTestService(t, true)
+
+		_, err := s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "Owner", Justification: "incident", Duration: "1h"})
+		require.ErrorIs(t, err, ErrInvalidPermission)
+		_, err = s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "View", Justification: " ", Duration: "1h"})
+		require.ErrorIs(t, err, ErrMissingReason)
+		_, err = s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "View", Justification: "incident", Duration: "48h"})
+		require.ErrorIs(t, err, ErrInvalidDuration)
+		_, err = s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "View", Justification: "incident", Duration: "-1h"})
+		require.ErrorIs(t, err, ErrInvalidDuration)
+	})
+
+	t.Run("should only list requests the user made or can review", func(t *testing.T) {
+		s, _ := setupTestService(t, false)
+
+		req, err := s.Create(ctx, requester, CreateCommand{FolderUID: "folder", Permission: "View", Justification: "incident", Duration: "1h"})
+		require.NoError(t, err)
+
+		requests, err := s.List(ctx, requester, "")
+		require.NoError(t, err)
+		assert.Len(t, requests, 1)
+
+		requests, err = s.List(ctx, reviewer, StatusPending)
+		require.NoError(t, err)
+		assert.Empty(t, requests)
+
+		_, err = s.Deny(ctx, reviewer, req.ID, ReviewCommand{})
+		require.ErrorIs(t, err, ErrNotFound)
+	})
+}
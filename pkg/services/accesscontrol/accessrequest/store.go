@@ -0,0 +1,72 @@
+package accessrequest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) create(ctx context.Context, req *AccessRequest) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND user_id = ? AND folder_uid = ? AND status = ?",
+			req.OrgID, req.UserID, req.FolderUID, StatusPending).Exist(&AccessRequest{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return ErrAlreadyRequested.Errorf("user %d already requested access to folder %s", req.UserID, req.FolderUID)
+		}
+
+		_, err = sess.Insert(req)
+		return err
+	})
+}
+
+func (s *sqlStore) get(ctx context.Context, orgID, id int64) (*AccessRequest, error) {
+	req := &AccessRequest{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND id = ?", orgID, id).Get(req)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrNotFound.Errorf("access request %d not found", id)
+		}
+		return nil
+	})
+	return req, err
+}
+
+func (s *sqlStore) list(ctx context.Context, query ListQuery) ([]*AccessRequest, error) {
+	result := make([]*AccessRequest, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		q := sess.Where("org_id = ?", query.OrgID)
+		if query.UserID != 0 {
+			q = q.And("user_id = ?", query.UserID)
+		}
+		if query.Status != "" {
+			q = q.And("status = ?", query.Status)
+		}
+		return q.Desc("id").Find(&result)
+	})
+	return result, err
+}
+
+// review stores the outcome of a pending request, it fails when the request was reviewed concurrently
+func (s *sqlStore) review(ctx context.Context, req *AccessRequest) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("id = ? AND status = ?", req.ID, StatusPending).
+			Cols("status", "reviewer_id", "review_comment", "expires_at", "updated").Update(req)
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrNotPending.Errorf("access request %d was already reviewed", req.ID)
+		}
+		return nil
+	})
+}
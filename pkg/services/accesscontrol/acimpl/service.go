@@ -36,6 +36,8 @@ var _ plugins.RoleRegistry = &Service{}
 
 const (
 	cacheTTL = 60 * time.Second
+	// expiredPermissionsInterval is how often permissions that expired are revoked
+	expiredPermissionsInterval = 30 * time.Second
 )
 
 var SharedWithMeFolderPermission = accesscontrol.Permission{
@@ -286,7 +288,7 @@ func (s *Service) getCachedPermissions(ctx context.Context, key string, getPermi
 		return nil, err
 	}
 
-	s.cache.Set(key, permissions, cacheTTL)
+	s.cachePermissions(key, permissions)
 	return permissions, nil
 }
 
@@ -323,7 +325,7 @@ func (s *Service) getCachedTeamsPermissions(ctx context.Context, user identity.R
 
 		for teamID, teamPermissions := range teamsPermissions {
 			key := accesscontrol.GetTeamPermissionCacheKey(teamID, orgID)
-			s.cache.Set(key, teamPermissions, cacheTTL)
+			s.cachePermissions(key, teamPermissions)
 			permissions = append(permissions, teamPermissions...)
 		}
 	}
@@ -331,6 +333,70 @@ func (s *Service) getCachedTeamsPermissions(ctx context.Context, user identity.R
 	return permissions, nil
 }
 
+// cachePermissions caches permissions until the earliest of them expires, for cacheTTL at most
+func (s *Service) cachePermissions(key string, permissions []accesscontrol.Permission) {
+	if ttl := permissionsCacheTTL(permissions, time.Now()); ttl > 0 {
+		s.cache.Set(key, permissions, ttl)
+	}
+}
+
+func permissionsCacheTTL(permissions []accesscontrol.Permission, now time.Time) time.Duration {
+	ttl := cacheTTL
+	for _, p := range permissions {
+		if p.ExpiresAt == 0 {
+			continue
+		}
+		if untilExpiry := time.Unix(p.ExpiresAt, 0).Sub(now); untilExpiry < ttl {
+			ttl = untilExpiry
+		}
+	}
+	return ttl
+}
+
+// Run revokes the permissions that expired
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(expiredPermissionsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.revokeExpiredPermissions(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// revokeExpiredPermissions deletes the permissions and role assignments that expired and clears the permission cache
+// of the organizations they belonged to. Expired grants are already left out of the permission queries and cache
+// entries don't outlive the grants they hold, this only keeps the tables clean.
+func (s *Service) revokeExpiredPermissions(ctx context.Context) {
+	orgIDs, err := s.store.DeleteExpiredPermissions(ctx, time.Now())
+	if err != nil {
+		s.log.Error("Failed to revoke expired permissions", "error", err)
+		return
+	}
+
+	for _, orgID := range orgIDs {
+		s.log.Debug("Revoked expired permissions", "orgId", orgID)
+		s.clearOrgPermissionCache(orgID)
+	}
+}
+
+// clearOrgPermissionCache removes the cached permissions of all users, teams and basic roles of an organization
+func (s *Service) clearOrgPermissionCache(orgID int64) {
+	prefixes := accesscontrol.GetOrgPermissionCacheKeyPrefixes(orgID)
+	for key := range s.cache.Items() {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(key, prefix) {
+				s.cache.Delete(key)
+				break
+			}
+		}
+	}
+}
+
 func (s *Service) ClearUserPermissionCache(user identity.Requester) {
 	s.cache.Delete(accesscontrol.GetPermissionCacheKey(user))
 	s.cache.Delete(accesscontrol.GetUserDirectPermissionCacheKey(user))
@@ -554,7 +620,7 @@ func (s *Service) searchUserPermissions(ctx context.Context, orgID int64, search
 	permissions = append(permissions, dbPermissions[userID]...)
 
 	key := accesscontrol.GetSearchPermissionCacheKey(&user.SignedInUser{UserID: userID, OrgID: orgID}, searchOptions)
-	s.cache.Set(key, permissions, cacheTTL)
+	s.cachePermissions(key, permissions)
 
 	return permissions, nil
 }
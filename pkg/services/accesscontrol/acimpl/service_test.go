@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -883,3 +884,43 @@ func TestService_DeleteExternalServiceRole(t *testing.T) {
 		})
 	}
 }
+
+func TestService_RevokeExpiredPermissions(t *testing.T) {
+	ac := setupTestEnv(t)
+	ac.store = actest.FakeStore{ExpectedExpiredOrgIDs: []int64{1}}
+
+	revoked := &user.SignedInUser{OrgID: 1, UserID: 2, NamespacedID: identity.MustParseNamespaceID("user:2")}
+	other := &user.SignedInUser{OrgID: 10, UserID: 2, NamespacedID: identity.MustParseNamespaceID("user:2")}
+	keys := map[string]bool{
+		accesscontrol.GetPermissionCacheKey(revoked):                                 false,
+		accesscontrol.GetUserDirectPermissionCacheKey(revoked):                       false,
+		accesscontrol.GetTeamPermissionCacheKey(3, 1):                                false,
+		accesscontrol.GetBasicRolePermissionCacheKey(string(roletype.RoleViewer), 1): false,
+		accesscontrol.GetPermissionCacheKey(other):                                   true,
+		accesscontrol.GetUserDirectPermissionCacheKey(other):                         true,
+		accesscontrol.GetTeamPermissionCacheKey(3, 10):                               true,
+	}
+	for key := range keys {
+		ac.cache.Set(key, []accesscontrol.Permission{{Action: "folders:read", Scope: "folders:uid:abc"}}, cacheTTL)
+	}
+
+	ac.revokeExpiredPermissions(context.Background())
+
+	for key, kept := range keys {
+		_, ok := ac.cache.Get(key)
+		assert.Equal(t, kept, ok, key)
+	}
+}
+
+func TestPermissionsCacheTTL(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	permanent := accesscontrol.Permission{Action: "folders:read", Scope: "folders:uid:abc"}
+	expiring := func(in time.Duration) accesscontrol.Permission {
+		return accesscontrol.Permission{Action: "folders:write", Scope: "folders:uid:abc", ExpiresAt: now.Add(in).Unix()}
+	}
+
+	assert.Equal(t, cacheTTL, permissionsCacheTTL([]accesscontrol.Permission{permanent}, now))
+	assert.Equal(t, cacheTTL, permissionsCacheTTL([]accesscontrol.Permission{permanent, expiring(time.Hour)}, now))
+	assert.Equal(t, 10*time.Second, permissionsCacheTTL([]accesscontrol.Permission{expiring(time.Minute), expiring(10 * time.Second)}, now))
+	assert.LessOrEqual(t, permissionsCacheTTL([]accesscontrol.Permission{expiring(-time.Second)}, now), time.Duration(0))
+}
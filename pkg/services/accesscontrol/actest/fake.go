@@ -2,6 +2,7 @@ package actest
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -81,6 +82,7 @@ type FakeStore struct {
 	ExpectedTeamsPermissions      map[int64][]accesscontrol.Permission
 	ExpectedUsersPermissions      map[int64][]accesscontrol.Permission
 	ExpectedUsersRoles            map[int64][]string
+	ExpectedExpiredOrgIDs         []int64
 	ExpectedErr                   error
 }
 
@@ -120,6 +122,10 @@ func (f FakeStore) DeleteExternalServiceRole(ctx context.Context, externalServic
 	return f.ExpectedErr
 }
 
+func (f FakeStore) DeleteExpiredPermissions(ctx context.Context, before time.Time) ([]int64, error) {
+	return f.ExpectedExpiredOrgIDs, f.ExpectedErr
+}
+
 var _ accesscontrol.PermissionsService = new(FakePermissionsService)
 
 type FakePermissionsService struct {
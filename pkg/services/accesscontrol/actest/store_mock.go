@@ -8,6 +8,8 @@ import (
 	accesscontrol "github.com/grafana/grafana/pkg/services/accesscontrol"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // MockStore is an autogenerated mock type for the Store type
@@ -15,6 +17,36 @@ type MockStore struct {
 	mock.Mock
 }
 
+// DeleteExpiredPermissions provides a mock function with given fields: ctx, before
+func (_m *MockStore) DeleteExpiredPermissions(ctx context.Context, before time.Time) ([]int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredPermissions")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // DeleteExternalServiceRole provides a mock function with given fields: ctx, externalServiceID
 func (_m *MockStore) DeleteExternalServiceRole(ctx context.Context, externalServiceID string) error {
 	ret := _m.Called(ctx, externalServiceID)
@@ -41,3 +41,17 @@ func GetBasicRolePermissionCacheKey(role string, orgID int64) string {
 func GetTeamPermissionCacheKey(teamID int64, orgID int64) string {
 	return fmt.Sprintf("rbac-permissions-team-%d-%d", orgID, teamID)
 }
+
+// GetOrgPermissionCacheKeyPrefixes returns the prefixes of the permission cache keys of an organization, the global
+// organization's prefix covers the keys of all organizations
+func GetOrgPermissionCacheKeyPrefixes(orgID int64) []string {
+	if orgID == GlobalOrgID {
+		return []string{"rbac-permissions-"}
+	}
+	return []string{
+		fmt.Sprintf("rbac-permissions-%d-", orgID),
+		fmt.Sprintf("rbac-permissions-direct-%d-", orgID),
+		fmt.Sprintf("rbac-permissions-basic-role-%d-", orgID),
+		fmt.Sprintf("rbac-permissions-team-%d-", orgID),
+	}
+}
@@ -5,29 +5,32 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 )
 
 const (
-	// userAssignsSQL is a query to select all users assignments.
-	userAssignsSQL = `SELECT ur.user_id, ur.org_id, ur.role_id
-	FROM user_role AS ur`
+	// userAssignsSQL is a query to select all users assignments that have not expired.
+	userAssignsSQL = `SELECT ur.user_id, ur.org_id, ur.role_id, ur.expires_at
+	FROM user_role AS ur
+	WHERE (ur.expires_at = 0 OR ur.expires_at > ?)`
 
-	// teamAssignsSQL is a query to select all users' team assignments.
-	teamAssignsSQL = `SELECT tm.user_id, tr.org_id, tr.role_id
+	// teamAssignsSQL is a query to select all users' team assignments that have not expired.
+	teamAssignsSQL = `SELECT tm.user_id, tr.org_id, tr.role_id, tr.expires_at
 	FROM team_role AS tr
-	INNER JOIN team_member AS tm ON tm.team_id = tr.team_id`
+	INNER JOIN team_member AS tm ON tm.team_id = tr.team_id
+	WHERE (tr.expires_at = 0 OR tr.expires_at > ?)`
 
 	// basicRoleAssignsSQL is a query to select all users basic role (Admin, Editor, Viewer, None) assignments.
-	basicRoleAssignsSQL = `SELECT ou.user_id, ou.org_id, br.role_id
+	basicRoleAssignsSQL = `SELECT ou.user_id, ou.org_id, br.role_id, 0 AS expires_at
 	FROM builtin_role AS br
 	INNER JOIN org_user AS ou ON ou.role = br.role`
 
 	// grafanaAdminAssignsSQL is a query to select all grafana admin users.
 	// it has to be formatted with the quoted user table.
-	grafanaAdminAssignsSQL = `SELECT sa.user_id, br.org_id, br.role_id
+	grafanaAdminAssignsSQL = `SELECT sa.user_id, br.org_id, br.role_id, 0 AS expires_at
 	FROM builtin_role AS br
 	INNER JOIN (
 		SELECT u.id AS user_id
@@ -36,6 +39,13 @@ const (
 	WHERE br.role = ?`
 )
 
+// expiresAtSQL selects when a permission granted through a role assignment expires, the earliest of the permission
+// and the assignment expiries. It's zero when neither of them expires.
+func expiresAtSQL(permission, assignment string) string {
+	return fmt.Sprintf(`CASE WHEN %[2]s.expires_at > 0 AND (%[1]s.expires_at = 0 OR %[2]s.expires_at < %[1]s.expires_at)
+		THEN %[2]s.expires_at ELSE %[1]s.expires_at END AS expires_at`, permission, assignment)
+}
+
 func ProvideService(sql db.DB) *AccessControlStore {
 	return &AccessControlStore{sql}
 }
@@ -57,10 +67,13 @@ func (s *AccessControlStore) GetUserPermissions(ctx context.Context, query acces
 		q := `
 		SELECT
 			permission.action,
-			permission.scope
+			permission.scope,
+			` + expiresAtSQL("permission", "all_role") + `
 			FROM permission
 			INNER JOIN role ON role.id = permission.role_id
+			AND (permission.expires_at = 0 OR permission.expires_at > ?)
 		` + filter
+		params = append([]any{time.Now().Unix()}, params...)
 
 		if len(query.RolePrefixes) > 0 {
 			rolePrefixesFilter, filterParams := accesscontrol.RolePrefixesFilter(query.RolePrefixes)
@@ -87,15 +100,17 @@ func (s *AccessControlStore) GetBasicRolesPermissions(ctx context.Context, query
 }
 
 type teamPermission struct {
-	TeamID int64 `xorm:"team_id"`
-	Action string
-	Scope  string
+	TeamID    int64 `xorm:"team_id"`
+	Action    string
+	Scope     string
+	ExpiresAt int64 `xorm:"expires_at"`
 }
 
 func (p teamPermission) Permission() accesscontrol.Permission {
 	return accesscontrol.Permission{
-		Action: p.Action,
-		Scope:  p.Scope,
+		Action:    p.Action,
+		Scope:     p.Scope,
+		ExpiresAt: p.ExpiresAt,
 	}
 }
 
@@ -114,21 +129,25 @@ func (s *AccessControlStore) GetTeamsPermissions(ctx context.Context, query acce
 		SELECT
 			permission.action,
 			permission.scope,
-			all_role.team_id
+			all_role.team_id,
+			` + expiresAtSQL("permission", "all_role") + `
 		FROM permission
 		INNER JOIN role ON role.id = permission.role_id
+		AND (permission.expires_at = 0 OR permission.expires_at > ?)
 		INNER JOIN (
-			SELECT tr.role_id, tr.team_id FROM team_role as tr
+			SELECT tr.role_id, tr.team_id, tr.expires_at FROM team_role as tr
 			WHERE tr.team_id IN(?` + strings.Repeat(", ?", len(teams)-1) + `)
 			  AND tr.org_id = ?
+			  AND (tr.expires_at = 0 OR tr.expires_at > ?)
 		) as all_role ON role.id = all_role.role_id
 		`
 
-		params := make([]any, 0)
+		now := time.Now().Unix()
+		params := []any{now}
 		for _, team := range teams {
 			params = append(params, team)
 		}
-		params = append(params, orgID)
+		params = append(params, orgID, now)
 
 		if len(rolePrefixes) > 0 {
 			rolePrefixesFilter, filterParams := accesscontrol.RolePrefixesFilter(rolePrefixes)
@@ -157,9 +176,10 @@ func (s *AccessControlStore) GetTeamsPermissions(ctx context.Context, query acce
 // SearchUsersPermissions returns the list of user permissions in specific organization indexed by UserID
 func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID int64, options accesscontrol.SearchOptions) (map[int64][]accesscontrol.Permission, error) {
 	type UserRBACPermission struct {
-		UserID int64  `xorm:"user_id"`
-		Action string `xorm:"action"`
-		Scope  string `xorm:"scope"`
+		UserID    int64  `xorm:"user_id"`
+		Action    string `xorm:"action"`
+		Scope     string `xorm:"scope"`
+		ExpiresAt int64  `xorm:"expires_at"`
 	}
 	dbPerms := make([]UserRBACPermission, 0)
 
@@ -178,17 +198,19 @@ func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID i
 			roleNameFilterJoin = "INNER JOIN role AS r ON up.role_id = r.id"
 		}
 
-		params := []any{}
+		now := time.Now().Unix()
+		params := []any{now}
 
 		direct := userAssignsSQL
 		if options.NamespacedID != "" {
-			direct += " WHERE ur.user_id = ?"
+			direct += " AND ur.user_id = ?"
 			params = append(params, userID)
 		}
 
 		team := teamAssignsSQL
+		params = append(params, now)
 		if options.NamespacedID != "" {
-			team += " WHERE tm.user_id = ?"
+			team += " AND tm.user_id = ?"
 			params = append(params, userID)
 		}
 
@@ -210,7 +232,8 @@ func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID i
 		SELECT
 			user_id,
 			p.action,
-			p.scope
+			p.scope,
+			` + expiresAtSQL("p", "up") + `
 		FROM (
 			` + direct + `
 			UNION ALL
@@ -222,8 +245,9 @@ func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID i
 		) AS up ` + roleNameFilterJoin + `
 		INNER JOIN permission AS p ON up.role_id = p.role_id
 		WHERE (up.org_id = ? OR up.org_id = ?)
+		AND (p.expires_at = 0 OR p.expires_at > ?)
 		`
-		params = append(params, orgID, accesscontrol.GlobalOrgID)
+		params = append(params, orgID, accesscontrol.GlobalOrgID, now)
 
 		if options.ActionPrefix != "" {
 			q += ` AND p.action LIKE ?`
@@ -256,7 +280,11 @@ func (s *AccessControlStore) SearchUsersPermissions(ctx context.Context, orgID i
 
 	mapped := map[int64][]accesscontrol.Permission{}
 	for i := range dbPerms {
-		mapped[dbPerms[i].UserID] = append(mapped[dbPerms[i].UserID], accesscontrol.Permission{Action: dbPerms[i].Action, Scope: dbPerms[i].Scope})
+		mapped[dbPerms[i].UserID] = append(mapped[dbPerms[i].UserID], accesscontrol.Permission{
+			Action:    dbPerms[i].Action,
+			Scope:     dbPerms[i].Scope,
+			ExpiresAt: dbPerms[i].ExpiresAt,
+		})
 	}
 
 	return mapped, nil
@@ -422,3 +450,33 @@ func (s *AccessControlStore) DeleteTeamPermissions(ctx context.Context, orgID, t
 	})
 	return err
 }
+
+// DeleteExpiredPermissions deletes the permissions and role assignments that expired before a time and returns the
+// organizations they belonged to
+func (s *AccessControlStore) DeleteExpiredPermissions(ctx context.Context, before time.Time) ([]int64, error) {
+	var orgIDs []int64
+	err := s.sql.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		q := `SELECT role.org_id FROM permission
+		INNER JOIN role ON role.id = permission.role_id
+		WHERE permission.expires_at > 0 AND permission.expires_at <= ?
+		UNION
+		SELECT org_id FROM user_role WHERE expires_at > 0 AND expires_at <= ?
+		UNION
+		SELECT org_id FROM team_role WHERE expires_at > 0 AND expires_at <= ?`
+		if err := sess.SQL(q, before.Unix(), before.Unix(), before.Unix()).Find(&orgIDs); err != nil {
+			return err
+		}
+
+		if len(orgIDs) == 0 {
+			return nil
+		}
+
+		for _, table := range []string{"permission", "user_role", "team_role"} {
+			if _, err := sess.Exec("DELETE FROM "+table+" WHERE expires_at > 0 AND expires_at <= ?", before.Unix()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return orgIDs, err
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -393,6 +394,97 @@ func TestAccessControlStore_DeleteTeamPermissions(t *testing.T) {
 	})
 }
 
+func TestAccessControlStore_DeleteExpiredPermissions(t *testing.T) {
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	_, err := permissionsStore.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+		Actions:    []string{"dashboards:write"},
+		Resource:   "dashboards",
+		ResourceID: "1",
+		Expires:    time.Now().Add(time.Hour),
+	}, nil)
+	require.NoError(t, err)
+
+	_, err = permissionsStore.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: user.ID}, rs.SetResourcePermissionCommand{
+		Actions:    []string{"dashboards:read"},
+		Resource:   "dashboards",
+		ResourceID: "2",
+	}, nil)
+	require.NoError(t, err)
+
+	orgIDs, err := store.DeleteExpiredPermissions(context.Background(), time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, orgIDs)
+
+	orgIDs, err = store.DeleteExpiredPermissions(context.Background(), time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, orgIDs)
+
+	permissions, err := store.GetUserPermissions(context.Background(), accesscontrol.GetUserPermissionsQuery{
+		OrgID:  1,
+		UserID: user.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, permissions, 1)
+	assert.Equal(t, "dashboards:read", permissions[0].Action)
+}
+
+func TestAccessControlStore_ExpiredGrants(t *testing.T) {
+	store, permissionsStore, usrSvc, teamSvc, _, sql := setupTestEnv(t)
+	user, _ := createUserAndTeam(t, sql, usrSvc, teamSvc, 1)
+
+	expires := time.Now().Add(time.Hour)
+	for resourceID, cmd := range map[string]rs.SetResourcePermissionCommand{
+		"1": {Actions: []string{"dashboards:write"}, Expires: time.Now().Add(-time.Minute)},
+		"2": {Actions: []string{"dashboards:read"}},
+		"3": {Actions: []string{"dashboards:delete"}, Expires: expires},
+	} {
+		cmd.Resource = "dashboards"
+		cmd.ResourceID = resourceID
+		_, err := permissionsStore.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: user.ID}, cmd, nil)
+		require.NoError(t, err)
+	}
+
+	query := accesscontrol.GetUserPermissionsQuery{OrgID: 1, UserID: user.ID}
+	permissions, err := store.GetUserPermissions(context.Background(), query)
+	require.NoError(t, err)
+	require.Len(t, permissions, 2)
+	expiresAt := map[string]int64{}
+	for _, p := range permissions {
+		expiresAt[p.Action] = p.ExpiresAt
+	}
+	assert.Equal(t, map[string]int64{"dashboards:read": 0, "dashboards:delete": expires.Unix()}, expiresAt)
+
+	t.Run("expired role assignments grant nothing", func(t *testing.T) {
+		err := sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE user_role SET expires_at = ? WHERE user_id = ?", time.Now().Add(-time.Minute).Unix(), user.ID)
+			return err
+		})
+		require.NoError(t, err)
+
+		permissions, err := store.GetUserPermissions(context.Background(), query)
+		require.NoError(t, err)
+		assert.Empty(t, permissions)
+
+		usersPermissions, err := store.SearchUsersPermissions(context.Background(), 1, accesscontrol.SearchOptions{ActionPrefix: "dashboards:"})
+		require.NoError(t, err)
+		assert.Empty(t, usersPermissions[user.ID])
+
+		orgIDs, err := store.DeleteExpiredPermissions(context.Background(), time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, []int64{1}, orgIDs)
+
+		var assignments int64
+		err = sql.WithDbSession(context.Background(), func(sess *db.Session) error {
+			assignments, err = sess.Where("user_id = ?", user.ID).Count(&accesscontrol.UserRole{})
+			return err
+		})
+		require.NoError(t, err)
+		assert.Zero(t, assignments)
+	})
+}
+
 func createUserAndTeam(t *testing.T, store db.DB, userSrv user.Service, teamSvc team.Service, orgID int64) (*user.User, team.Team) {
 	t.Helper()
 
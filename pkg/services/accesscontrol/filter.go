@@ -4,6 +4,7 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 )
@@ -127,20 +128,24 @@ func SetAcceptListForTest(list map[string]struct{}) func() {
 	}
 }
 
+// UserRolesFilter joins the roles assigned to a user, directly, through teams or through basic roles. Expired
+// assignments are left out, the expiry of the others is selected as all_role.expires_at.
 func UserRolesFilter(orgID, userID int64, teamIDs []int64, roles []string) (string, []any) {
 	var params []any
 	builder := strings.Builder{}
+	now := time.Now().Unix()
 
 	// This is an additional security. We should never have permissions granted to userID 0.
 	// Only allow real users to get user/team permissions (anonymous/apikeys)
 	if userID > 0 {
 		builder.WriteString(`
-			SELECT ur.role_id
+			SELECT ur.role_id, ur.expires_at
 			FROM user_role AS ur
 			WHERE ur.user_id = ?
 			AND (ur.org_id = ? OR ur.org_id = ?)
+			AND (ur.expires_at = 0 OR ur.expires_at > ?)
 		`)
-		params = []any{userID, orgID, GlobalOrgID}
+		params = []any{userID, orgID, GlobalOrgID, now}
 	}
 
 	if len(teamIDs) > 0 {
@@ -148,14 +153,15 @@ func UserRolesFilter(orgID, userID int64, teamIDs []int64, roles []string) (stri
 			builder.WriteString("UNION")
 		}
 		builder.WriteString(`
-			SELECT tr.role_id FROM team_role as tr
+			SELECT tr.role_id, tr.expires_at FROM team_role as tr
 			WHERE tr.team_id IN(?` + strings.Repeat(", ?", len(teamIDs)-1) + `)
 			AND tr.org_id = ?
+			AND (tr.expires_at = 0 OR tr.expires_at > ?)
 		`)
 		for _, id := range teamIDs {
 			params = append(params, id)
 		}
-		params = append(params, orgID)
+		params = append(params, orgID, now)
 	}
 
 	if len(roles) != 0 {
@@ -164,7 +170,7 @@ func UserRolesFilter(orgID, userID int64, teamIDs []int64, roles []string) (stri
 		}
 
 		builder.WriteString(`
-			SELECT br.role_id FROM builtin_role AS br
+			SELECT br.role_id, 0 AS expires_at FROM builtin_role AS br
 			WHERE br.role IN (?` + strings.Repeat(", ?", len(roles)-1) + `)
 			AND (br.org_id = ? OR br.org_id = ?)
 		`)
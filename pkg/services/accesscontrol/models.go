@@ -168,6 +168,9 @@ type TeamRole struct {
 	RoleID int64 `json:"roleId" xorm:"role_id"`
 	TeamID int64 `json:"teamId" xorm:"team_id"`
 
+	// ExpiresAt is the unix time the assignment is revoked at, it's zero for permanent assignments
+	ExpiresAt int64 `json:"expiresAt,omitempty" xorm:"expires_at"`
+
 	Created time.Time
 }
 
@@ -177,6 +180,9 @@ type UserRole struct {
 	RoleID int64 `json:"roleId" xorm:"role_id"`
 	UserID int64 `json:"userId" xorm:"user_id"`
 
+	// ExpiresAt is the unix time the assignment is revoked at, it's zero for permanent assignments
+	ExpiresAt int64 `json:"expiresAt,omitempty" xorm:"expires_at"`
+
 	Created time.Time
 }
 
@@ -201,6 +207,9 @@ type Permission struct {
 	Attribute  string `json:"-"`
 	Identifier string `json:"-"`
 
+	// ExpiresAt is the unix time the permission is revoked at, it's zero for permanent permissions
+	ExpiresAt int64 `json:"-" xorm:"expires_at"`
+
 	Updated time.Time `json:"updated"`
 	Created time.Time `json:"created"`
 }
@@ -256,6 +265,8 @@ type ResourcePermission struct {
 	IsServiceAccount bool
 	Created          time.Time
 	Updated          time.Time
+	// Expires is when the permission is revoked, it's zero for permanent permissions
+	Expires time.Time
 }
 
 func (p *ResourcePermission) Contains(targetActions []string) bool {
@@ -286,6 +297,8 @@ type SetResourcePermissionCommand struct {
 	TeamID      int64  `json:"teamId,omitempty"`
 	BuiltinRole string `json:"builtInRole,omitempty"`
 	Permission  string `json:"permission"`
+	// Expires is when the permission is revoked, the permission is permanent when it's zero
+	Expires time.Time `json:"expires,omitempty"`
 }
 
 type SaveExternalServiceRoleCommand struct {
@@ -87,6 +87,12 @@ func ProvideTeamPermissions(
 			if err != nil {
 				return err
 			}
+			// memberships synced from an identity provider can only be changed by the sync
+			if !user.IsExternal {
+				if err := teamimpl.PreventExternalTeamMemberUpdateHook(session, user.ID, orgID, teamId); err != nil {
+					return err
+				}
+			}
 			switch permission {
 			case "Member":
 				return teamimpl.AddOrUpdateTeamMemberHook(session, user.ID, orgID, teamId, user.IsExternal, 0)
@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
@@ -100,20 +102,21 @@ func (a *api) getDescription(c *contextmodel.ReqContext) response.Response {
 }
 
 type resourcePermissionDTO struct {
-	ID               int64    `json:"id"`
-	RoleName         string   `json:"roleName"`
-	IsManaged        bool     `json:"isManaged"`
-	IsInherited      bool     `json:"isInherited"`
-	IsServiceAccount bool     `json:"isServiceAccount"`
-	UserID           int64    `json:"userId,omitempty"`
-	UserLogin        string   `json:"userLogin,omitempty"`
-	UserAvatarUrl    string   `json:"userAvatarUrl,omitempty"`
-	Team             string   `json:"team,omitempty"`
-	TeamID           int64    `json:"teamId,omitempty"`
-	TeamAvatarUrl    string   `json:"teamAvatarUrl,omitempty"`
-	BuiltInRole      string   `json:"builtInRole,omitempty"`
-	Actions          []string `json:"actions"`
-	Permission       string   `json:"permission"`
+	ID               int64      `json:"id"`
+	RoleName         string     `json:"roleName"`
+	IsManaged        bool       `json:"isManaged"`
+	IsInherited      bool       `json:"isInherited"`
+	IsServiceAccount bool       `json:"isServiceAccount"`
+	UserID           int64      `json:"userId,omitempty"`
+	UserLogin        string     `json:"userLogin,omitempty"`
+	UserAvatarUrl    string     `json:"userAvatarUrl,omitempty"`
+	Team             string     `json:"team,omitempty"`
+	TeamID           int64      `json:"teamId,omitempty"`
+	TeamAvatarUrl    string     `json:"teamAvatarUrl,omitempty"`
+	BuiltInRole      string     `json:"builtInRole,omitempty"`
+	Actions          []string   `json:"actions"`
+	Permission       string     `json:"permission"`
+	Expires          *time.Time `json:"expires,omitempty"`
 }
 
 // swagger:parameters getResourcePermissions
@@ -163,6 +166,11 @@ func (a *api) getPermissions(c *contextmodel.ReqContext) response.Response {
 				teamAvatarUrl = dtos.GetGravatarUrlWithDefault(a.cfg, p.TeamEmail, p.Team)
 			}
 
+			var expires *time.Time
+			if !p.Expires.IsZero() {
+				expires = &p.Expires
+			}
+
 			dto = append(dto, resourcePermissionDTO{
 				ID:               p.ID,
 				RoleName:         p.RoleName,
@@ -178,6 +186,7 @@ func (a *api) getPermissions(c *contextmodel.ReqContext) response.Response {
 				IsManaged:        p.IsManaged,
 				IsInherited:      p.IsInherited,
 				IsServiceAccount: p.IsServiceAccount,
+				Expires:          expires,
 			})
 		}
 	}
@@ -187,6 +196,8 @@ func (a *api) getPermissions(c *contextmodel.ReqContext) response.Response {
 
 type setPermissionCommand struct {
 	Permission string `json:"permission"`
+	// Expires is when the permission is revoked, the permission is permanent when it's not set
+	Expires time.Time `json:"expires,omitempty"`
 }
 
 type setPermissionsCommand struct {
@@ -238,10 +249,18 @@ func (a *api) setUserPermission(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
-	_, err = a.service.SetUserPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), accesscontrol.User{ID: userID}, resourceID, cmd.Permission)
+	before := a.auditSummary(c, resourceID)
+	if cmd.Expires.IsZero() {
+		_, err = a.service.SetUserPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), accesscontrol.User{ID: userID}, resourceID, cmd.Permission)
+	} else {
+		_, err = a.service.SetPermissions(c.Req.Context(), c.SignedInUser.GetOrgID(), resourceID, accesscontrol.SetResourcePermissionCommand{
+			UserID: userID, Permission: cmd.Permission, Expires: cmd.Expires,
+		})
+	}
 	if err != nil {
 		return response.Err(err)
 	}
+	a.audit(c, resourceID, before)
 
 	return permissionSetResponse(cmd)
 }
@@ -291,10 +310,18 @@ func (a *api) setTeamPermission(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
-	_, err = a.service.SetTeamPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID, resourceID, cmd.Permission)
+	before := a.auditSummary(c, resourceID)
+	if cmd.Expires.IsZero() {
+		_, err = a.service.SetTeamPermission(c.Req.Context(), c.SignedInUser.GetOrgID(), teamID, resourceID, cmd.Permission)
+	} else {
+		_, err = a.service.SetPermissions(c.Req.Context(), c.SignedInUser.GetOrgID(), resourceID, accesscontrol.SetResourcePermissionCommand{
+			TeamID: teamID, Permission: cmd.Permission, Expires: cmd.Expires,
+		})
+	}
 	if err != nil {
 		return response.Err(err)
 	}
+	a.audit(c, resourceID, before)
 
 	return permissionSetResponse(cmd)
 }
@@ -341,10 +368,12 @@ func (a *api) setBuiltinRolePermission(c *contextmodel.ReqContext) response.Resp
 		return response.Error(http.StatusBadRequest, "bad request data", err)
 	}
 
+	before := a.auditSummary(c, resourceID)
 	_, err := a.service.SetBuiltInRolePermission(c.Req.Context(), c.SignedInUser.GetOrgID(), builtInRole, resourceID, cmd.Permission)
 	if err != nil {
 		return response.Err(err)
 	}
+	a.audit(c, resourceID, before)
 
 	return permissionSetResponse(cmd)
 }
@@ -386,10 +415,12 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusBadRequest, "Bad request data: "+err.Error(), err)
 	}
 
+	before := a.auditSummary(c, resourceID)
 	_, err := a.service.SetPermissions(c.Req.Context(), c.SignedInUser.GetOrgID(), resourceID, cmd.Permissions...)
 	if err != nil {
 		return response.Err(err)
 	}
+	a.audit(c, resourceID, before)
 
 	return response.Success("Permissions updated")
 }
@@ -401,3 +432,39 @@ func permissionSetResponse(cmd setPermissionCommand) response.Response {
 	}
 	return response.Success(message)
 }
+
+type auditPermission struct {
+	User        string `json:"user,omitempty"`
+	Team        string `json:"team,omitempty"`
+	BuiltInRole string `json:"builtInRole,omitempty"`
+	Permission  string `json:"permission"`
+}
+
+// auditSummary lists the managed permissions of a resource for the audit log. The permissions are only loaded
+// when the request is audited.
+func (a *api) auditSummary(c *contextmodel.ReqContext, resourceID string) []auditPermission {
+	if !auditlog.IsAudited(c.Req.Context()) {
+		return nil
+	}
+
+	permissions, err := a.service.GetPermissions(c.Req.Context(), c.SignedInUser, resourceID)
+	if err != nil {
+		return nil
+	}
+
+	summary := make([]auditPermission, 0, len(permissions))
+	for _, p := range permissions {
+		if !p.IsManaged || p.IsInherited {
+			continue
+		}
+		if permission := a.service.MapActions(p); permission != "" {
+			summary = append(summary, auditPermission{User: p.UserLogin, Team: p.Team, BuiltInRole: p.BuiltInRole, Permission: permission})
+		}
+	}
+	return summary
+}
+
+func (a *api) audit(c *contextmodel.ReqContext, resourceID string, before []auditPermission) {
+	resource := auditlog.Resource{Kind: a.service.options.Resource + ".permissions", UID: resourceID}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, resource, before, a.auditSummary(c, resourceID))
+}
@@ -9,6 +9,7 @@ const (
 	invalidAssignmentMessage = `Assignment [{{ .Public.assignment }}] is invalid for this resource type`
 	invalidParamMessage      = `Param [{{ .Public.param }}] is invalid`
 	invalidRequestBody       = `Request body is invalid: {{ .Public.reason }}`
+	invalidExpiryMessage     = `Expiry is invalid: {{ .Public.reason }}`
 )
 
 var (
@@ -20,6 +21,8 @@ var (
 				MustTemplate(invalidPermissionMessage, errutil.WithPublic(invalidPermissionMessage))
 	ErrInvalidAssignment = errutil.BadRequest("resourcePermissions.invalidAssignment").
 				MustTemplate(invalidAssignmentMessage, errutil.WithPublic(invalidAssignmentMessage))
+	ErrInvalidExpiry = errutil.BadRequest("resourcePermissions.invalidExpiry").
+				MustTemplate(invalidExpiryMessage, errutil.WithPublic(invalidExpiryMessage))
 )
 
 func ErrInvalidParamData(param string, err error) errutil.TemplateData {
@@ -54,3 +57,11 @@ func ErrInvalidAssignmentData(assignment string) errutil.TemplateData {
 		},
 	}
 }
+
+func ErrInvalidExpiryData(reason string) errutil.TemplateData {
+	return errutil.TemplateData{
+		Public: map[string]any{
+			"reason": reason,
+		},
+	}
+}
@@ -1,6 +1,8 @@
 package resourcepermissions
 
 import (
+	"time"
+
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 )
@@ -11,6 +13,8 @@ type SetResourcePermissionCommand struct {
 	ResourceID        string
 	ResourceAttribute string
 	Permission        string
+	// Expires is when the permission is revoked, the permission is permanent when it's zero
+	Expires time.Time
 }
 
 type SetResourcePermissionsCommand struct {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -241,6 +242,10 @@ func (s *Service) SetPermissions(
 			return nil, err
 		}
 
+		if err := s.validateExpiry(cmd); err != nil {
+			return nil, err
+		}
+
 		dbCommands = append(dbCommands, SetResourcePermissionsCommand{
 			User:        accesscontrol.User{ID: cmd.UserID},
 			TeamID:      cmd.TeamID,
@@ -251,6 +256,7 @@ func (s *Service) SetPermissions(
 				ResourceID:        resourceID,
 				ResourceAttribute: s.options.ResourceAttribute,
 				Permission:        cmd.Permission,
+				Expires:           cmd.Expires,
 			},
 		})
 	}
@@ -299,6 +305,32 @@ func (s *Service) validateResource(ctx context.Context, orgID int64, resourceID
 	return nil
 }
 
+// validateExpiry checks that expiring permissions are granted for the future. Permissions of resources with hooks
+// can't expire, the expiry job removes them from the database only and would leave the hooks' changes behind.
+func (s *Service) validateExpiry(cmd accesscontrol.SetResourcePermissionCommand) error {
+	if cmd.Expires.IsZero() || cmd.Permission == "" {
+		return nil
+	}
+
+	if !cmd.Expires.After(time.Now()) {
+		return ErrInvalidExpiry.Build(ErrInvalidExpiryData("expiry must be in the future"))
+	}
+
+	hasHook := false
+	switch {
+	case cmd.UserID != 0:
+		hasHook = s.options.OnSetUser != nil
+	case cmd.TeamID != 0:
+		hasHook = s.options.OnSetTeam != nil
+	default:
+		hasHook = s.options.OnSetBuiltInRole != nil
+	}
+	if hasHook {
+		return ErrInvalidExpiry.Build(ErrInvalidExpiryData("permissions of this resource type can't expire"))
+	}
+	return nil
+}
+
 func (s *Service) validateUser(ctx context.Context, orgID, userID int64) error {
 	if !s.options.Assignments.Users {
 		return ErrInvalidAssignment.Build(ErrInvalidAssignmentData("users"))
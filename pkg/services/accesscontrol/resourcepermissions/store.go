@@ -40,7 +40,8 @@ type flatResourcePermission struct {
 	TeamEmail        string
 	Team             string
 	BuiltInRole      string
-	IsServiceAccount bool `xorm:"is_service_account"`
+	IsServiceAccount bool  `xorm:"is_service_account"`
+	ExpiresAt        int64 `xorm:"expires_at"`
 	Created          time.Time
 	Updated          time.Time
 }
@@ -273,6 +274,15 @@ func (s *store) setResourcePermission(
 		return nil, err
 	}
 
+	// the expiry applies to the whole assignment, granting the permission again replaces it
+	var expiresAt int64
+	if !cmd.Expires.IsZero() {
+		expiresAt = cmd.Expires.Unix()
+	}
+	if _, err := sess.Exec("UPDATE permission SET expires_at = ? WHERE role_id = ? AND scope = ?", expiresAt, role.ID, scope); err != nil {
+		return nil, err
+	}
+
 	permissions, err := s.getPermissions(sess, cmd.Resource, cmd.ResourceID, cmd.ResourceAttribute, role.ID)
 	if err != nil {
 		return nil, err
@@ -498,6 +508,11 @@ func flatPermissionsToResourcePermission(scope string, permissions []flatResourc
 	}
 
 	first := permissions[0]
+	var expires time.Time
+	if first.ExpiresAt > 0 {
+		expires = time.Unix(first.ExpiresAt, 0)
+	}
+
 	return &accesscontrol.ResourcePermission{
 		ID:               first.ID,
 		RoleName:         first.RoleName,
@@ -515,6 +530,7 @@ func flatPermissionsToResourcePermission(scope string, permissions []flatResourc
 		IsManaged:        first.IsManaged(scope),
 		IsInherited:      first.IsInherited(scope),
 		IsServiceAccount: first.IsServiceAccount,
+		Expires:          expires,
 	}
 }
 
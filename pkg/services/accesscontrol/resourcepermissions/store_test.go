@@ -115,6 +115,35 @@ func TestIntegrationStore_SetUserResourcePermission(t *testing.T) {
 	}
 }
 
+func TestIntegrationStore_SetUserResourcePermissionExpiry(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store, _, _ := setupTestEnv(t)
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	added, err := store.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: 1}, SetResourcePermissionCommand{
+		Actions:    []string{"folders:read", "folders:write"},
+		Resource:   "folders",
+		ResourceID: "abc",
+		Permission: "Edit",
+		Expires:    expires,
+	}, nil)
+	require.NoError(t, err)
+	assert.True(t, expires.Equal(added.Expires))
+
+	// granting the permission again without expiry makes it permanent
+	added, err = store.SetUserResourcePermission(context.Background(), 1, accesscontrol.User{ID: 1}, SetResourcePermissionCommand{
+		Actions:    []string{"folders:read"},
+		Resource:   "folders",
+		ResourceID: "abc",
+		Permission: "View",
+	}, nil)
+	require.NoError(t, err)
+	assert.Len(t, added.Actions, 1)
+	assert.True(t, added.Expires.IsZero())
+}
+
 type setTeamResourcePermissionTest struct {
 	desc              string
 	orgID             int64
@@ -0,0 +1,307 @@
+package annotationimport
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// defaultCSVSource scopes the external IDs of CSV imports that do not name a source
+const defaultCSVSource = "csv"
+
+const (
+	// maxSourceLength leaves room for the csv: prefix in the source column
+	maxSourceLength     = 90
+	maxExternalIDLength = 190
+)
+
+// tokenLength is the number of random characters of a webhook token
+const tokenLength = 32
+
+// Service imports organization annotations from webhooks sent by external tools and from CSV documents. Events that
+// carry an external ID are imported once per source.
+type Service interface {
+	CreateWebhook(ctx context.Context, cmd *CreateWebhookCommand) (*Webhook, error)
+	UpdateWebhook(ctx context.Context, cmd *UpdateWebhookCommand) (*Webhook, error)
+	// RegenerateToken replaces the token of a webhook and returns the webhook with its new token
+	RegenerateToken(ctx context.Context, cmd *RegenerateTokenCommand) (*Webhook, error)
+	DeleteWebhook(ctx context.Context, cmd *DeleteWebhookCommand) error
+	GetWebhook(ctx context.Context, query *GetWebhookQuery) (*Webhook, error)
+	SearchWebhooks(ctx context.Context, query *SearchWebhooksQuery) ([]*Webhook, error)
+	// IngestWebhook imports the events of a payload posted to a webhook
+	IngestWebhook(ctx context.Context, cmd *IngestWebhookCommand) (*ImportResult, error)
+	ImportCSV(ctx context.Context, cmd *ImportCSVCommand) (*ImportResult, error)
+}
+
+type AnnotationImportService struct {
+	db              db.DB
+	store           *sqlStore
+	annotationsRepo annotations.Repository
+	accessControl   accesscontrol.AccessControl
+	routeRegister   routing.RouteRegister
+	now             func() time.Time
+}
+
+func ProvideService(sql db.DB, routeRegister routing.RouteRegister, annotationsRepo annotations.Repository,
+	accessControl accesscontrol.AccessControl) *AnnotationImportService {
+	s := &AnnotationImportService{
+		db:              sql,
+		store:           &sqlStore{db: sql},
+		annotationsRepo: annotationsRepo,
+		accessControl:   accessControl,
+		routeRegister:   routeRegister,
+		now:             time.Now,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *AnnotationImportService) CreateWebhook(ctx context.Context, cmd *CreateWebhookCommand) (*Webhook, error) {
+	if err := validateSettings(&cmd.WebhookSettings); err != nil {
+		return nil, err
+	}
+
+	uid := cmd.UID
+	if uid == "" {
+		uid = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(uid) || util.IsShortUIDTooLong(uid) {
+		return nil, ErrInvalidUID.Errorf("invalid uid %q", uid)
+	} else if _, err := s.store.get(ctx, cmd.OrgID, uid); err == nil {
+		return nil, ErrWebhookExists.Errorf("webhook %s already exists", uid)
+	} else if !errors.Is(err, ErrWebhookNotFound) {
+		return nil, ErrInternal.Errorf("failed to check webhook uid: %w", err)
+	}
+
+	now := s.now()
+	webhook := &Webhook{
+		UID:       uid,
+		OrgID:     cmd.OrgID,
+		Name:      cmd.Name,
+		Mapping:   cmd.Mapping,
+		Tags:      cmd.Tags,
+		Created:   now,
+		Updated:   now,
+		CreatedBy: cmd.UserID,
+	}
+	if err := setToken(webhook); err != nil {
+		return nil, err
+	}
+	if err := s.store.insert(ctx, webhook); err != nil {
+		return nil, ErrInternal.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (s *AnnotationImportService) UpdateWebhook(ctx context.Context, cmd *UpdateWebhookCommand) (*Webhook, error) {
+	if err := validateSettings(&cmd.WebhookSettings); err != nil {
+		return nil, err
+	}
+
+	webhook, err := s.store.get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Name = cmd.Name
+	webhook.Mapping = cmd.Mapping
+	webhook.Tags = cmd.Tags
+	webhook.Updated = s.now()
+	if err := s.store.update(ctx, webhook); err != nil {
+		return nil, ErrInternal.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (s *AnnotationImportService) RegenerateToken(ctx context.Context, cmd *RegenerateTokenCommand) (*Webhook, error) {
+	webhook, err := s.store.get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setToken(webhook); err != nil {
+		return nil, err
+	}
+	webhook.Updated = s.now()
+	if err := s.store.update(ctx, webhook); err != nil {
+		return nil, ErrInternal.Errorf("failed to update webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+func (s *AnnotationImportService) DeleteWebhook(ctx context.Context, cmd *DeleteWebhookCommand) error {
+	return s.store.delete(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *AnnotationImportService) GetWebhook(ctx context.Context, query *GetWebhookQuery) (*Webhook, error) {
+	return s.store.get(ctx, query.OrgID, query.UID)
+}
+
+func (s *AnnotationImportService) SearchWebhooks(ctx context.Context, query *SearchWebhooksQuery) ([]*Webhook, error) {
+	return s.store.search(ctx, query)
+}
+
+func (s *AnnotationImportService) IngestWebhook(ctx context.Context, cmd *IngestWebhookCommand) (*ImportResult, error) {
+	webhook, err := s.authenticateWebhook(ctx, cmd.UID, cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := compileMapping(webhook.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	records, err := mapping.mapPayload(cmd.Payload, webhook.Tags, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.importRecords(ctx, webhook.OrgID, webhook.CreatedBy, webhookSource(webhook.UID), records)
+}
+
+// authenticateWebhook returns the webhook with the uid whose token matches. Payloads are posted without a signed
+// in user, so the token also selects the organization when several organizations use the same uid.
+func (s *AnnotationImportService) authenticateWebhook(ctx context.Context, uid string, token string) (*Webhook, error) {
+	webhooks, err := s.store.getByUID(ctx, uid)
+	if err != nil {
+		return nil, ErrInternal.Errorf("failed to get webhook: %w", err)
+	}
+	if len(webhooks) == 0 {
+		return nil, ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+	}
+
+	hash := []byte(hashToken(token))
+	for _, webhook := range webhooks {
+		// webhooks created before tokens were introduced have no hash and reject every payload until their
+		// token is regenerated
+		if webhook.TokenHash != "" && subtle.ConstantTimeCompare(hash, []byte(webhook.TokenHash)) == 1 {
+			return webhook, nil
+		}
+	}
+	return nil, ErrInvalidToken.Errorf("invalid token for webhook %s", uid)
+}
+
+func (s *AnnotationImportService) ImportCSV(ctx context.Context, cmd *ImportCSVCommand) (*ImportResult, error) {
+	records, err := parseCSV(cmd.CSV, cmd.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	source := strings.TrimSpace(cmd.Source)
+	if source == "" {
+		source = defaultCSVSource
+	}
+	if len(source) > maxSourceLength {
+		return nil, ErrInvalidSource.Errorf("source is longer than %d characters", maxSourceLength)
+	}
+	return s.importRecords(ctx, cmd.OrgID, cmd.UserID, "csv:"+source, records)
+}
+
+// importRecords saves the records whose external ID was not imported from the source before. The annotations and
+// the external IDs are written in one transaction so that a failed import can be retried.
+func (s *AnnotationImportService) importRecords(ctx context.Context, orgID, userID int64, source string, records []record) (*ImportResult, error) {
+	for i, r := range records {
+		if len(r.externalID) > maxExternalIDLength {
+			return nil, ErrInvalidExternalID.Errorf("external id of annotation %d is longer than %d characters", i, maxExternalIDLength)
+		}
+	}
+
+	result := &ImportResult{}
+	err := s.db.InTransaction(ctx, func(ctx context.Context) error {
+		ids := make([]string, 0, len(records))
+		for _, r := range records {
+			if r.externalID != "" {
+				ids = append(ids, r.externalID)
+			}
+		}
+		existing, err := s.store.existingExternalIDs(ctx, orgID, source, ids)
+		if err != nil {
+			return err
+		}
+
+		now := s.now()
+		items := make([]annotations.Item, 0, len(records))
+		imported := make([]externalID, 0, len(ids))
+		for _, r := range records {
+			if r.externalID != "" {
+				if existing[r.externalID] {
+					result.Duplicates++
+					continue
+				}
+				// the same event can be sent twice in one payload
+				existing[r.externalID] = true
+				imported = append(imported, externalID{OrgID: orgID, Source: source, ExternalID: r.externalID, Created: now})
+			}
+
+			item := r.item
+			item.OrgID = orgID
+			item.UserID = userID
+			item.Data = simplejson.NewFromAny(map[string]any{"source": source})
+			if r.externalID != "" {
+				item.Data.Set("externalId", r.externalID)
+			}
+			items = append(items, item)
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+		if err := s.annotationsRepo.SaveMany(ctx, items); err != nil {
+			return err
+		}
+		result.Imported = len(items)
+		return s.store.insertExternalIDs(ctx, imported)
+	})
+	if err != nil {
+		// errors such as tags exceeding the maximum length are the caller's
+		var gfErr errutil.Error
+		if errors.As(err, &gfErr) {
+			return nil, err
+		}
+		return nil, ErrInternal.Errorf("failed to import annotations: %w", err)
+	}
+	return result, nil
+}
+
+func validateSettings(settings *WebhookSettings) error {
+	settings.Name = strings.TrimSpace(settings.Name)
+	if settings.Name == "" {
+		return ErrInvalidName.Errorf("webhook name is empty")
+	}
+	_, err := compileMapping(settings.Mapping)
+	return err
+}
+
+// webhookSource scopes the external IDs of the events of a webhook
+func webhookSource(uid string) string {
+	return "webhook:" + uid
+}
+
+// setToken generates a new token for the webhook, only its hash is stored
+func setToken(webhook *Webhook) error {
+	token, err := util.GetRandomString(tokenLength)
+	if err != nil {
+		return ErrInternal.Errorf("failed to generate webhook token: %w", err)
+	}
+	webhook.Token = token
+	webhook.TokenHash = hashToken(token)
+	return nil
+}
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
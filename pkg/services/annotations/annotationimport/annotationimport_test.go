@@ -0,0 +1,214 @@
+package annotationimport
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+// fakeRepository records the annotations saved in batches, it fails when err is set
+type fakeRepository struct {
+	annotations.Repository
+	saved []annotations.Item
+	err   error
+}
+
+func (f *fakeRepository) SaveMany(_ context.Context, items []annotations.Item) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.saved = append(f.saved, items...)
+	return nil
+}
+
+func setupTestService(t *testing.T) (*AnnotationImportService, *fakeRepository) {
+	t.Helper()
+	repo := &fakeRepository{}
+	return ProvideService(db.InitTestDB(t), routing.NewRouteRegister(), repo, actest.FakeAccessControl{}), repo
+}
+
+func TestIntegrationAnnotationImport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	deployWebhook := &CreateWebhookCommand{
+		UID:    "deploys",
+		OrgID:  1,
+		UserID: 2,
+		WebhookSettings: WebhookSettings{
+			Name:    "Deploys",
+			Mapping: Mapping{Time: "time", Text: "message", ExternalID: "id"},
+			Tags:    []string{"deploy"},
+		},
+	}
+
+	t.Run("webhook events are imported once", func(t *testing.T) {
+		s, repo := setupTestService(t)
+		webhook, err := s.CreateWebhook(ctx, deployWebhook)
+		require.NoError(t, err)
+
+		ingest := func(payload string) *ImportResult {
+			result, err := s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: webhook.Token, Payload: []byte(payload)})
+			require.NoError(t, err)
+			return result
+		}
+
+		result := ingest(`[{"id": "a", "time": 1714550400, "message": "v1"}, {"id": "a", "time": 1714550400, "message": "v1"}, {"id": "b", "time": 1714550500, "message": "v2"}]`)
+		assert.Equal(t, &ImportResult{Imported: 2, Duplicates: 1}, result)
+
+		result = ingest(`{"id": "b", "time": 1714550500, "message": "v2"}`)
+		assert.Equal(t, &ImportResult{Imported: 0, Duplicates: 1}, result)
+
+		require.Len(t, repo.saved, 2)
+		first := repo.saved[0]
+		assert.Equal(t, int64(1), first.OrgID)
+		assert.Equal(t, int64(2), first.UserID)
+		assert.Equal(t, "v1", first.Text)
+		assert.Equal(t, int64(1714550400000), first.Epoch)
+		assert.Equal(t, []string{"deploy"}, first.Tags)
+		assert.Equal(t, "webhook:deploys", first.Data.Get("source").MustString())
+		assert.Equal(t, "a", first.Data.Get("externalId").MustString())
+	})
+
+	t.Run("external IDs are scoped to their source", func(t *testing.T) {
+		s, repo := setupTestService(t)
+		webhook, err := s.CreateWebhook(ctx, deployWebhook)
+		require.NoError(t, err)
+
+		_, err = s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: webhook.Token, Payload: []byte(`{"id": "a", "message": "v1"}`)})
+		require.NoError(t, err)
+
+		csv := []byte("time,text,externalId\n1714550400,v1,a\n1714550500,no id,\n")
+		result, err := s.ImportCSV(ctx, &ImportCSVCommand{OrgID: 1, Source: "ci", CSV: csv})
+		require.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 2}, result)
+
+		result, err = s.ImportCSV(ctx, &ImportCSVCommand{OrgID: 1, Source: "ci", CSV: csv})
+		require.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 1, Duplicates: 1}, result)
+
+		result, err = s.ImportCSV(ctx, &ImportCSVCommand{OrgID: 2, Source: "ci", CSV: csv})
+		require.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 2}, result)
+
+		assert.Len(t, repo.saved, 6)
+	})
+
+	t.Run("external IDs are not recorded when saving fails", func(t *testing.T) {
+		s, repo := setupTestService(t)
+		csv := []byte("time,text,externalId\n1714550400,v1,a\n")
+
+		repo.err = errors.New("database is locked")
+		_, err := s.ImportCSV(ctx, &ImportCSVCommand{OrgID: 1, CSV: csv})
+		require.ErrorIs(t, err, ErrInternal)
+
+		repo.err = annotations.ErrBaseTagLimitExceeded.Errorf("too many tags")
+		_, err = s.ImportCSV(ctx, &ImportCSVCommand{OrgID: 1, CSV: csv})
+		require.ErrorIs(t, err, annotations.ErrBaseTagLimitExceeded)
+
+		repo.err = nil
+		result, err := s.ImportCSV(ctx, &ImportCSVCommand{OrgID: 1, CSV: csv})
+		require.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 1}, result)
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		s, _ := setupTestService(t)
+
+		webhook, err := s.CreateWebhook(ctx, deployWebhook)
+		require.NoError(t, err)
+		assert.Equal(t, Mapping{Time: "time", Text: "message", ExternalID: "id"}, webhook.Mapping)
+
+		_, err = s.CreateWebhook(ctx, deployWebhook)
+		require.ErrorIs(t, err, ErrWebhookExists)
+
+		_, err = s.CreateWebhook(ctx, &CreateWebhookCommand{OrgID: 1, WebhookSettings: WebhookSettings{Name: "No text", Mapping: Mapping{Time: "time"}}})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		updated, err := s.UpdateWebhook(ctx, &UpdateWebhookCommand{
+			UID:             "deploys",
+			OrgID:           1,
+			WebhookSettings: WebhookSettings{Name: "Releases", Mapping: Mapping{Text: "title"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Releases", updated.Name)
+
+		found, err := s.GetWebhook(ctx, &GetWebhookQuery{UID: "deploys", OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, Mapping{Text: "title"}, found.Mapping)
+		assert.Empty(t, found.Tags)
+
+		_, err = s.GetWebhook(ctx, &GetWebhookQuery{UID: "deploys", OrgID: 2})
+		require.ErrorIs(t, err, ErrWebhookNotFound)
+
+		require.NoError(t, s.DeleteWebhook(ctx, &DeleteWebhookCommand{UID: "deploys", OrgID: 1}))
+		webhooks, err := s.SearchWebhooks(ctx, &SearchWebhooksQuery{OrgID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, webhooks)
+
+		_, err = s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: webhook.Token, Payload: []byte(`{}`)})
+		require.ErrorIs(t, err, ErrWebhookNotFound)
+	})
+
+	t.Run("payloads require the token of the webhook", func(t *testing.T) {
+		s, repo := setupTestService(t)
+		webhook, err := s.CreateWebhook(ctx, deployWebhook)
+		require.NoError(t, err)
+		require.Len(t, webhook.Token, tokenLength)
+
+		// the token is not returned after creation
+		found, err := s.GetWebhook(ctx, &GetWebhookQuery{UID: "deploys", OrgID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, found.Token)
+
+		payload := []byte(`{"id": "a", "message": "v1"}`)
+		for _, token := range []string{"", "invalid", found.TokenHash} {
+			_, err = s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: token, Payload: payload})
+			require.ErrorIs(t, err, ErrInvalidToken)
+		}
+
+		regenerated, err := s.RegenerateToken(ctx, &RegenerateTokenCommand{UID: "deploys", OrgID: 1})
+		require.NoError(t, err)
+		require.NotEqual(t, webhook.Token, regenerated.Token)
+
+		_, err = s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: webhook.Token, Payload: payload})
+		require.ErrorIs(t, err, ErrInvalidToken)
+
+		result, err := s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: regenerated.Token, Payload: payload})
+		require.NoError(t, err)
+		assert.Equal(t, &ImportResult{Imported: 1}, result)
+		assert.Len(t, repo.saved, 1)
+	})
+
+	t.Run("payloads are imported in the organization of the webhook", func(t *testing.T) {
+		s, repo := setupTestService(t)
+		_, err := s.CreateWebhook(ctx, deployWebhook)
+		require.NoError(t, err)
+		other := *deployWebhook
+		other.OrgID, other.UserID = 2, 3
+		webhook, err := s.CreateWebhook(ctx, &other)
+		require.NoError(t, err)
+
+		_, err = s.IngestWebhook(ctx, &IngestWebhookCommand{UID: "deploys", Token: webhook.Token, Payload: []byte(`{"id": "a", "message": "v1"}`)})
+		require.NoError(t, err)
+
+		require.Len(t, repo.saved, 1)
+		assert.Equal(t, int64(2), repo.saved[0].OrgID)
+		assert.Equal(t, int64(3), repo.saved[0].UserID)
+	})
+}
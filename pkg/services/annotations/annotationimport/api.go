@@ -0,0 +1,319 @@
+package annotationimport
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// maxBodySize is the maximum size of a webhook payload or a CSV document
+const maxBodySize = 10 << 20
+
+// tokenHeader carries the token of the webhook a payload is posted to
+const tokenHeader = "X-Grafana-Webhook-Token"
+
+func (s *AnnotationImportService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	manage := authorize(ac.EvalPermission(ac.ActionAnnotationsWrite, ac.ScopeAnnotationsTypeOrganization))
+	create := authorize(ac.EvalPermission(ac.ActionAnnotationsCreate, ac.ScopeAnnotationsTypeOrganization))
+
+	s.routeRegister.Group("/api/annotations/webhooks", func(webhooks routing.RouteRegister) {
+		webhooks.Get("/", manage, routing.Wrap(s.searchHandler))
+		webhooks.Post("/", manage, routing.Wrap(s.createHandler))
+		webhooks.Get("/:uid", manage, routing.Wrap(s.getHandler))
+		webhooks.Put("/:uid", manage, routing.Wrap(s.updateHandler))
+		webhooks.Delete("/:uid", manage, routing.Wrap(s.deleteHandler))
+		webhooks.Post("/:uid/token", manage, routing.Wrap(s.regenerateTokenHandler))
+	}, middleware.ReqSignedIn)
+	// payloads are posted by external tools that have no Grafana session, the webhook token authenticates them
+	s.routeRegister.Post("/api/annotations/webhooks/:uid/events", routing.Wrap(s.ingestHandler))
+	s.routeRegister.Post("/api/annotations/import/csv", middleware.ReqSignedIn, create, routing.Wrap(s.importCSVHandler))
+}
+
+// swagger:route GET /annotations/webhooks annotations searchAnnotationWebhooks
+//
+// Get all annotation webhooks of the organization.
+//
+// Responses:
+// 200: searchAnnotationWebhooksResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *AnnotationImportService) searchHandler(c *contextmodel.ReqContext) response.Response {
+	webhooks, err := s.SearchWebhooks(c.Req.Context(), &SearchWebhooksQuery{OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get webhooks", err)
+	}
+	return response.JSON(http.StatusOK, webhooks)
+}
+
+// swagger:route POST /annotations/webhooks annotations createAnnotationWebhook
+//
+// Create an annotation webhook.
+//
+// Events posted to the webhook are turned into organization annotations using the JMESPath expressions of its mapping.
+// The response contains the token of the webhook, it is not returned again.
+//
+// Responses:
+// 200: getAnnotationWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *AnnotationImportService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+	webhook, err := s.CreateWebhook(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route GET /annotations/webhooks/{uid} annotations getAnnotationWebhook
+//
+// Get an annotation webhook by UID.
+//
+// Responses:
+// 200: getAnnotationWebhookResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationImportService) getHandler(c *contextmodel.ReqContext) response.Response {
+	webhook, err := s.GetWebhook(c.Req.Context(), &GetWebhookQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route PUT /annotations/webhooks/{uid} annotations updateAnnotationWebhook
+//
+// Update an annotation webhook.
+//
+// Responses:
+// 200: getAnnotationWebhookResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationImportService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdateWebhookCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	webhook, err := s.UpdateWebhook(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route POST /annotations/webhooks/{uid}/token annotations regenerateAnnotationWebhookToken
+//
+// Regenerate the token of an annotation webhook.
+//
+// Payloads sent with the previous token are rejected. The response contains the new token, it is not returned again.
+//
+// Responses:
+// 200: getAnnotationWebhookResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationImportService) regenerateTokenHandler(c *contextmodel.ReqContext) response.Response {
+	webhook, err := s.RegenerateToken(c.Req.Context(), &RegenerateTokenCommand{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, webhook)
+}
+
+// swagger:route DELETE /annotations/webhooks/{uid} annotations deleteAnnotationWebhook
+//
+// Delete an annotation webhook.
+//
+// The annotations imported through the webhook are kept, but the record of their external IDs is removed.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationImportService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeleteWebhook(c.Req.Context(), &DeleteWebhookCommand{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Webhook deleted")
+}
+
+// swagger:route POST /annotations/webhooks/{uid}/events annotations postAnnotationWebhookEvents
+//
+// Import the events of a webhook payload as annotations.
+//
+// The body is the JSON payload sent by the external tool and the token of the webhook is sent in the X-Grafana-Webhook-Token header, no other authentication is required. The annotations are created in the organization of the webhook on behalf of the user who created it. Events with an external ID that was already imported through the webhook are skipped.
+//
+// Responses:
+// 200: importAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationImportService) ingestHandler(c *contextmodel.ReqContext) response.Response {
+	body, resp := readBody(c)
+	if resp != nil {
+		return resp
+	}
+
+	result, err := s.IngestWebhook(c.Req.Context(), &IngestWebhookCommand{
+		UID:     web.Params(c.Req)[":uid"],
+		Token:   c.Req.Header.Get(tokenHeader),
+		Payload: body,
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /annotations/import/csv annotations importAnnotationsCSV
+//
+// Import annotations from a CSV document.
+//
+// The first row names the columns. The `time` and `text` columns are required, `timeEnd`, `tags` and `externalId` are optional. Times are RFC 3339 dates or Unix timestamps in seconds or milliseconds and tags are separated by commas. Rows with an external ID that was already imported from the same `source` are skipped.
+//
+// Responses:
+// 200: importAnnotationsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *AnnotationImportService) importCSVHandler(c *contextmodel.ReqContext) response.Response {
+	body, resp := readBody(c)
+	if resp != nil {
+		return resp
+	}
+
+	result, err := s.ImportCSV(c.Req.Context(), &ImportCSVCommand{
+		OrgID:  c.SignedInUser.GetOrgID(),
+		UserID: c.SignedInUser.UserID,
+		Source: c.Query("source"),
+		Tags:   c.QueryStrings("tags"),
+		CSV:    body,
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func readBody(c *contextmodel.ReqContext) ([]byte, response.Response) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Resp, c.Req.Body, maxBodySize))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, response.Error(http.StatusRequestEntityTooLarge, "Request body is too large", err)
+		}
+		return nil, response.Error(http.StatusBadRequest, "Failed to read request body", err)
+	}
+	return body, nil
+}
+
+// swagger:parameters createAnnotationWebhook
+type CreateAnnotationWebhookParams struct {
+	// in:body
+	// required:true
+	Body CreateWebhookCommand `json:"body"`
+}
+
+// swagger:parameters updateAnnotationWebhook
+type UpdateAnnotationWebhookParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body WebhookSettings `json:"body"`
+}
+
+// swagger:parameters getAnnotationWebhook deleteAnnotationWebhook regenerateAnnotationWebhookToken
+type AnnotationWebhookUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters postAnnotationWebhookEvents
+type PostAnnotationWebhookEventsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:header
+	// required:true
+	Token string `json:"X-Grafana-Webhook-Token"`
+	// in:body
+	// required:true
+	Body any `json:"body"`
+}
+
+// swagger:parameters importAnnotationsCSV
+type ImportAnnotationsCSVParams struct {
+	// Scopes the external IDs of the rows, defaults to csv
+	// in:query
+	// required:false
+	Source string `json:"source"`
+	// Tags added to every annotation
+	// in:query
+	// required:false
+	Tags []string `json:"tags"`
+	// in:body
+	// required:true
+	Body string `json:"body"`
+}
+
+// swagger:response searchAnnotationWebhooksResponse
+type SearchAnnotationWebhooksResponse struct {
+	// in: body
+	Body []*Webhook `json:"body"`
+}
+
+// swagger:response getAnnotationWebhookResponse
+type GetAnnotationWebhookResponse struct {
+	// in: body
+	Body *Webhook `json:"body"`
+}
+
+// swagger:response importAnnotationsResponse
+type ImportAnnotationsResponse struct {
+	// in: body
+	Body ImportResult `json:"body"`
+}
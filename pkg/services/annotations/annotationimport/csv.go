@@ -0,0 +1,94 @@
+package annotationimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+)
+
+// csvColumns are the recognized columns of a CSV import, header names are matched case insensitively and the
+// snake case spellings are accepted too
+var csvColumns = map[string]string{
+	"time":        "time",
+	"timeend":     "timeEnd",
+	"time_end":    "timeEnd",
+	"text":        "text",
+	"tags":        "tags",
+	"externalid":  "externalId",
+	"external_id": "externalId",
+}
+
+// parseCSV turns the rows of a CSV document into annotations
+func parseCSV(data []byte, staticTags []string) ([]record, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, ErrInvalidCSV.Errorf("the CSV is empty")
+	}
+	if err != nil {
+		return nil, ErrInvalidCSV.Errorf("%w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// strip the byte order mark spreadsheets put before the first column
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if column, ok := csvColumns[name]; ok {
+			columns[column] = i
+		}
+	}
+	for _, required := range []string{"time", "text"} {
+		if _, ok := columns[required]; !ok {
+			return nil, ErrInvalidCSV.Errorf("the header has no %s column", required)
+		}
+	}
+
+	records := make([]record, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidCSV.Errorf("%w", err)
+		}
+		if len(records) == maxItems {
+			return nil, ErrTooManyItems.Errorf("the CSV has more than %d rows", maxItems)
+		}
+
+		cell := func(column string) string {
+			i, ok := columns[column]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		r := record{externalID: cell("externalId")}
+		r.item.Text = cell("text")
+		if r.item.Text == "" {
+			return nil, ErrInvalidCSV.Errorf("line %d: text is missing", line)
+		}
+		if r.item.Epoch, err = parseTimeString(cell("time")); err != nil {
+			return nil, ErrInvalidCSV.Errorf("line %d: %w", line, err)
+		}
+		if end := cell("timeEnd"); end != "" {
+			if r.item.EpochEnd, err = parseTimeString(end); err != nil {
+				return nil, ErrInvalidCSV.Errorf("line %d: %w", line, err)
+			}
+		}
+		r.item.Tags = append(r.item.Tags, staticTags...)
+		for _, tag := range strings.Split(cell("tags"), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				r.item.Tags = append(r.item.Tags, tag)
+			}
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
@@ -0,0 +1,112 @@
+package annotationimport
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// externalIDBatchSize keeps the number of query parameters below the SQLite limit
+const externalIDBatchSize = 500
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) insert(ctx context.Context, webhook *Webhook) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(webhook)
+		return err
+	})
+}
+
+func (s *sqlStore) update(ctx context.Context, webhook *Webhook) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(webhook.ID).AllCols().Update(webhook)
+		return err
+	})
+}
+
+func (s *sqlStore) get(ctx context.Context, orgID int64, uid string) (*Webhook, error) {
+	webhook := &Webhook{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(webhook)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+		}
+		return nil
+	})
+	return webhook, err
+}
+
+// getByUID returns the webhooks of every organization with the uid, uids are only unique within an organization
+func (s *sqlStore) getByUID(ctx context.Context, uid string) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("uid = ?", uid).Find(&webhooks)
+	})
+	return webhooks, err
+}
+
+func (s *sqlStore) search(ctx context.Context, query *SearchWebhooksQuery) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ?", query.OrgID).Asc("name").Find(&webhooks)
+	})
+	return webhooks, err
+}
+
+// delete removes a webhook together with the external IDs imported through it
+func (s *sqlStore) delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			affected, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Delete(&Webhook{})
+			if err != nil {
+				return err
+			}
+			if affected == 0 {
+				return ErrWebhookNotFound.Errorf("webhook %s not found", uid)
+			}
+			_, err = sess.Exec("DELETE FROM annotation_external_id WHERE org_id = ? AND source = ?", orgID, webhookSource(uid))
+			return err
+		})
+	})
+}
+
+// existingExternalIDs returns the external IDs among ids that were already imported from a source
+func (s *sqlStore) existingExternalIDs(ctx context.Context, orgID int64, source string, ids []string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		for start := 0; start < len(ids); start += externalIDBatchSize {
+			end := min(start+externalIDBatchSize, len(ids))
+			found := make([]string, 0)
+			err := sess.Table("annotation_external_id").
+				Cols("external_id").
+				Where("org_id = ? AND source = ?", orgID, source).
+				In("external_id", ids[start:end]).
+				Find(&found)
+			if err != nil {
+				return err
+			}
+			for _, id := range found {
+				existing[id] = true
+			}
+		}
+		return nil
+	})
+	return existing, err
+}
+
+func (s *sqlStore) insertExternalIDs(ctx context.Context, rows []externalID) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.BulkInsert("annotation_external_id", rows, sqlstore.NativeSettingsForDialect(s.db.GetDialect()))
+		return err
+	})
+}
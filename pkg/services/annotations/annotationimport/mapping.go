@@ -0,0 +1,227 @@
+package annotationimport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmespath/go-jmespath"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// compiledMapping is a webhook mapping whose JMESPath expressions were checked, expressions that are not set are
+// empty
+type compiledMapping struct {
+	events     string
+	time       string
+	timeEnd    string
+	text       string
+	tags       string
+	externalID string
+}
+
+func compileMapping(m Mapping) (*compiledMapping, error) {
+	if strings.TrimSpace(m.Text) == "" {
+		return nil, ErrInvalidMapping.Errorf("the text path is required")
+	}
+
+	compiled := &compiledMapping{}
+	fields := []struct {
+		expr   string
+		target *string
+	}{
+		{m.Events, &compiled.events},
+		{m.Time, &compiled.time},
+		{m.TimeEnd, &compiled.timeEnd},
+		{m.Text, &compiled.text},
+		{m.Tags, &compiled.tags},
+		{m.ExternalID, &compiled.externalID},
+	}
+	for _, f := range fields {
+		expr := strings.TrimSpace(f.expr)
+		if expr == "" {
+			continue
+		}
+		if _, err := jmespath.Compile(expr); err != nil {
+			return nil, ErrInvalidMapping.Errorf("invalid path %q: %w", expr, err)
+		}
+		*f.target = expr
+	}
+	return compiled, nil
+}
+
+// mapPayload turns a webhook payload into the annotations of its events
+func (m *compiledMapping) mapPayload(payload []byte, staticTags []string, now time.Time) ([]record, error) {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, ErrInvalidPayload.Errorf("payload is not valid JSON: %w", err)
+	}
+
+	selected := document
+	if m.events != "" {
+		var err error
+		if selected, err = search(m.events, document); err != nil {
+			return nil, ErrInvalidPayload.Errorf("%w", err)
+		}
+	}
+	var events []any
+	switch v := selected.(type) {
+	case nil:
+	case []any:
+		events = v
+	default:
+		events = []any{v}
+	}
+	if len(events) > maxItems {
+		return nil, ErrTooManyItems.Errorf("payload has %d events, the maximum is %d", len(events), maxItems)
+	}
+
+	records := make([]record, 0, len(events))
+	for i, event := range events {
+		r, err := m.mapEvent(event, staticTags, now)
+		if err != nil {
+			return nil, ErrInvalidPayload.Errorf("event %d: %w", i, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+func (m *compiledMapping) mapEvent(event any, staticTags []string, now time.Time) (record, error) {
+	r := record{}
+
+	text, err := search(m.text, event)
+	if err != nil {
+		return r, err
+	}
+	r.item.Text = firstString(text)
+	if r.item.Text == "" {
+		return r, fmt.Errorf("text is missing")
+	}
+
+	r.item.Epoch = now.UnixMilli()
+	if m.time != "" {
+		value, err := search(m.time, event)
+		if err != nil {
+			return r, err
+		}
+		if value == nil {
+			return r, fmt.Errorf("time is missing")
+		}
+		epoch, err := parseTime(value)
+		if err != nil {
+			return r, err
+		}
+		r.item.Epoch = epoch
+	}
+	if m.timeEnd != "" {
+		value, err := search(m.timeEnd, event)
+		if err != nil {
+			return r, err
+		}
+		if value != nil {
+			epoch, err := parseTime(value)
+			if err != nil {
+				return r, err
+			}
+			r.item.EpochEnd = epoch
+		}
+	}
+
+	r.item.Tags = append(r.item.Tags, staticTags...)
+	if m.tags != "" {
+		tags, err := search(m.tags, event)
+		if err != nil {
+			return r, err
+		}
+		r.item.Tags = append(r.item.Tags, stringsOf(tags)...)
+	}
+
+	if m.externalID != "" {
+		externalID, err := search(m.externalID, event)
+		if err != nil {
+			return r, err
+		}
+		r.externalID = firstString(externalID)
+	}
+	return r, nil
+}
+
+// search evaluates a JMESPath expression of the mapping like the attribute paths of the OAuth providers, it selects
+// nothing in empty events
+func search(expr string, event any) (any, error) {
+	if event == nil {
+		return nil, nil
+	}
+	return util.SearchJSONForAttr(expr, event)
+}
+
+// parseTime returns the Unix time in milliseconds of a timestamp. Numbers below 1e11 are taken as seconds, which
+// covers dates up to the year 5138, and larger numbers as milliseconds.
+func parseTime(value any) (int64, error) {
+	switch v := value.(type) {
+	case json.Number:
+		return parseTimeString(v.String())
+	case float64:
+		return toMillis(v), nil
+	case string:
+		return parseTimeString(v)
+	}
+	return 0, fmt.Errorf("invalid time %v", value)
+}
+
+func parseTimeString(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return toMillis(n), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected an RFC 3339 date or a Unix timestamp", s)
+	}
+	return t.UnixMilli(), nil
+}
+
+func toMillis(n float64) int64 {
+	if n < 1e11 {
+		return int64(n * 1000)
+	}
+	return int64(n)
+}
+
+// firstString returns the selected value, or the first one of a list, as a string
+func firstString(value any) string {
+	if s := stringsOf(value); len(s) > 0 {
+		return s[0]
+	}
+	return ""
+}
+
+// stringsOf returns the strings held by a scalar value or a list of scalar values
+func stringsOf(value any) []string {
+	switch v := value.(type) {
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return []string{v}
+		}
+	case json.Number:
+		return []string{v.String()}
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, nested := item.([]any); !nested {
+				values = append(values, stringsOf(item)...)
+			}
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package annotationimport
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMapPayload(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("github deployment event", func(t *testing.T) {
+		mapping, err := compileMapping(Mapping{
+			Time:       "deployment.created_at",
+			Text:       "deployment.description",
+			Tags:       "deployment.environment",
+			ExternalID: "deployment.id",
+		})
+		require.NoError(t, err)
+
+		records, err := mapping.mapPayload([]byte(`{
+			"action": "created",
+			"deployment": {"id": 1234567890123, "created_at": "2024-04-30T08:15:00Z", "description": "Deploy v1.2.3", "environment": "production"}
+		}`), []string{"deploy"}, now)
+		require.NoError(t, err)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "1234567890123", records[0].externalID)
+		assert.Equal(t, "Deploy v1.2.3", records[0].item.Text)
+		assert.Equal(t, time.Date(2024, 4, 30, 8, 15, 0, 0, time.UTC).UnixMilli(), records[0].item.Epoch)
+		assert.Equal(t, []string{"deploy", "production"}, records[0].item.Tags)
+	})
+
+	t.Run("events selected from the payload", func(t *testing.T) {
+		mapping, err := compileMapping(Mapping{
+			Events:  "builds",
+			Time:    "started",
+			TimeEnd: "finished",
+			Text:    "name",
+			Tags:    "labels",
+		})
+		require.NoError(t, err)
+
+		records, err := mapping.mapPayload([]byte(`{"builds": [
+			{"name": "build 1", "started": 1714550400, "finished": 1714550460000, "labels": ["ci", "main"]},
+			{"name": "build 2", "started": "1714550500", "finished": null}
+		]}`), nil, now)
+		require.NoError(t, err)
+
+		require.Len(t, records, 2)
+		assert.Equal(t, int64(1714550400000), records[0].item.Epoch)
+		assert.Equal(t, int64(1714550460000), records[0].item.EpochEnd)
+		assert.Equal(t, []string{"ci", "main"}, records[0].item.Tags)
+		assert.Equal(t, int64(1714550500000), records[1].item.Epoch)
+		assert.Equal(t, int64(0), records[1].item.EpochEnd)
+		assert.Empty(t, records[0].externalID)
+	})
+
+	t.Run("arrays are lists of events and time defaults to now", func(t *testing.T) {
+		mapping, err := compileMapping(Mapping{Text: "message"})
+		require.NoError(t, err)
+
+		records, err := mapping.mapPayload([]byte(`[{"message": "a"}, {"message": "b"}]`), nil, now)
+		require.NoError(t, err)
+
+		require.Len(t, records, 2)
+		assert.Equal(t, now.UnixMilli(), records[1].item.Epoch)
+	})
+
+	t.Run("jmespath projections and functions", func(t *testing.T) {
+		mapping, err := compileMapping(Mapping{
+			Text: "join(' ', commits[*].message)",
+			Tags: "labels[?name != 'internal'].name",
+		})
+		require.NoError(t, err)
+
+		records, err := mapping.mapPayload([]byte(`{
+			"commits": [{"message": "Fix login"}, {"message": "Bump version"}],
+			"labels": [{"name": "deploy"}, {"name": "internal"}, {"name": "backend"}]
+		}`), nil, now)
+		require.NoError(t, err)
+
+		require.Len(t, records, 1)
+		assert.Equal(t, "Fix login Bump version", records[0].item.Text)
+		assert.Equal(t, []string{"deploy", "backend"}, records[0].item.Tags)
+	})
+
+	t.Run("invalid payloads", func(t *testing.T) {
+		mapping, err := compileMapping(Mapping{Text: "message", Time: "time"})
+		require.NoError(t, err)
+
+		for _, payload := range []string{
+			`not json`,
+			`{"time": "2024-04-30T08:15:00Z"}`,
+			`{"message": "no time"}`,
+			`{"message": "bad time", "time": "yesterday"}`,
+		} {
+			_, err := mapping.mapPayload([]byte(payload), nil, now)
+			require.ErrorIs(t, err, ErrInvalidPayload, payload)
+		}
+	})
+
+	t.Run("invalid mappings", func(t *testing.T) {
+		_, err := compileMapping(Mapping{Time: "time"})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+
+		_, err = compileMapping(Mapping{Text: "commits[0"})
+		require.ErrorIs(t, err, ErrInvalidMapping)
+	})
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Run("rows", func(t *testing.T) {
+		records, err := parseCSV([]byte("\ufeffTime,text,Tags,external_id,timeEnd\n"+
+			"2024-04-30T08:15:00Z,Deploy v1,\"deploy, backend\",run-1,\n"+
+			"1714550400,\"Deploy v2, hotfix\",,run-2,1714550460\n"), []string{"ci"})
+		require.NoError(t, err)
+
+		require.Len(t, records, 2)
+		assert.Equal(t, "run-1", records[0].externalID)
+		assert.Equal(t, "Deploy v1", records[0].item.Text)
+		assert.Equal(t, time.Date(2024, 4, 30, 8, 15, 0, 0, time.UTC).UnixMilli(), records[0].item.Epoch)
+		assert.Equal(t, []string{"ci", "deploy", "backend"}, records[0].item.Tags)
+		assert.Equal(t, "Deploy v2, hotfix", records[1].item.Text)
+		assert.Equal(t, int64(1714550400000), records[1].item.Epoch)
+		assert.Equal(t, int64(1714550460000), records[1].item.EpochEnd)
+		assert.Equal(t, []string{"ci"}, records[1].item.Tags)
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		for _, doc := range []string{
+			"",
+			"text\nno time column\n",
+			"time,text\n2024-04-30T08:15:00Z,\n",
+			"time,text\nyesterday,Deploy\n",
+			"time,text\n\"unclosed,Deploy\n",
+		} {
+			_, err := parseCSV([]byte(doc), nil)
+			require.ErrorIs(t, err, ErrInvalidCSV, doc)
+		}
+	})
+}
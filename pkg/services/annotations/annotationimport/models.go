@@ -0,0 +1,193 @@
+package annotationimport
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// maxItems is the maximum number of annotations imported by a single request
+const maxItems = 10000
+
+var (
+	ErrWebhookNotFound   = errutil.NotFound("annotationimport.webhookNotFound", errutil.WithPublicMessage("Annotation webhook not found"))
+	ErrWebhookExists     = errutil.Conflict("annotationimport.webhookExists", errutil.WithPublicMessage("An annotation webhook with the same UID already exists"))
+	ErrInvalidName       = errutil.BadRequest("annotationimport.invalidName", errutil.WithPublicMessage("Webhook name is required"))
+	ErrInvalidUID        = errutil.BadRequest("annotationimport.invalidUID", errutil.WithPublicMessage("Invalid webhook UID"))
+	ErrInvalidMapping    = errutil.BadRequest("annotationimport.invalidMapping", errutil.WithPublicMessage("Invalid webhook mapping"))
+	ErrInvalidPayload    = errutil.BadRequest("annotationimport.invalidPayload", errutil.WithPublicMessage("Invalid payload"))
+	ErrInvalidCSV        = errutil.BadRequest("annotationimport.invalidCSV", errutil.WithPublicMessage("Invalid CSV"))
+	ErrInvalidSource     = errutil.BadRequest("annotationimport.invalidSource", errutil.WithPublicMessage("Invalid import source"))
+	ErrInvalidExternalID = errutil.BadRequest("annotationimport.invalidExternalID", errutil.WithPublicMessage("Invalid external ID"))
+	ErrTooManyItems      = errutil.BadRequest("annotationimport.tooManyItems", errutil.WithPublicMessage("Too many annotations in a single import"))
+	ErrInvalidToken      = errutil.Unauthorized("annotationimport.invalidToken", errutil.WithPublicMessage("Invalid webhook token"))
+	ErrInternal          = errutil.Internal("annotationimport.internal")
+)
+
+// Mapping tells where the fields of an annotation are found in a webhook payload. Each field is a JMESPath
+// expression such as deployment.created_at or commits[*].id, like the attribute paths of the OAuth providers.
+type Mapping struct {
+	// Events selects the events of a payload that contains several of them. By default the payload is a single
+	// event, or a list of events when it is a JSON array.
+	Events string `json:"events,omitempty"`
+	// Time of the event, as an RFC 3339 string or a Unix timestamp in seconds or milliseconds. Defaults to the
+	// time the event is received.
+	Time string `json:"time,omitempty"`
+	// TimeEnd makes the annotation a region
+	TimeEnd string `json:"timeEnd,omitempty"`
+	// required: true
+	Text string `json:"text"`
+	// Tags may select a single string or a list of strings
+	Tags string `json:"tags,omitempty"`
+	// ExternalID identifies an event in the tool that sent it. Events with an ID that was already imported through
+	// the same webhook are skipped.
+	ExternalID string `json:"externalId,omitempty"`
+}
+
+// FromDB is called by xorm when reading the column
+func (m *Mapping) FromDB(data []byte) error {
+	*m = Mapping{}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, m)
+}
+
+// ToDB is called by xorm when writing the column
+func (m *Mapping) ToDB() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// Tags are added to every annotation imported through a webhook
+type Tags []string
+
+// FromDB is called by xorm when reading the column
+func (t *Tags) FromDB(data []byte) error {
+	*t = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// ToDB is called by xorm when writing the column
+func (t *Tags) ToDB() ([]byte, error) {
+	if *t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Webhook turns the payloads posted by an external tool, such as a CI system, into organization annotations
+type Webhook struct {
+	ID      int64     `json:"id" xorm:"pk autoincr 'id'"`
+	UID     string    `json:"uid" xorm:"uid"`
+	OrgID   int64     `json:"orgId" xorm:"org_id"`
+	Name    string    `json:"name"`
+	Mapping Mapping   `json:"mapping"`
+	Tags    Tags      `json:"tags"`
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+	// CreatedBy is the user the imported annotations are attributed to
+	CreatedBy int64 `json:"createdBy" xorm:"created_by"`
+	// Token must be sent in the X-Grafana-Webhook-Token header of the payloads. It is only returned when the
+	// webhook is created or its token is regenerated, only its hash is stored.
+	Token     string `json:"token,omitempty" xorm:"-"`
+	TokenHash string `json:"-" xorm:"token_hash"`
+}
+
+func (Webhook) TableName() string {
+	return "annotation_webhook"
+}
+
+// externalID records an event that was imported so that it is not imported again
+type externalID struct {
+	ID         int64     `xorm:"pk autoincr 'id'"`
+	OrgID      int64     `xorm:"org_id"`
+	Source     string    `xorm:"source"`
+	ExternalID string    `xorm:"external_id"`
+	Created    time.Time `xorm:"created"`
+}
+
+func (externalID) TableName() string {
+	return "annotation_external_id"
+}
+
+// WebhookSettings are the user editable properties of a webhook
+type WebhookSettings struct {
+	// required: true
+	Name string `json:"name"`
+	// required: true
+	Mapping Mapping `json:"mapping"`
+	// Tags added to every annotation
+	Tags []string `json:"tags"`
+}
+
+type CreateWebhookCommand struct {
+	WebhookSettings
+	// Optional, generated when empty
+	UID    string `json:"uid"`
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+}
+
+type UpdateWebhookCommand struct {
+	WebhookSettings
+	UID   string `json:"-"`
+	OrgID int64  `json:"-"`
+}
+
+// RegenerateTokenCommand replaces the token of a webhook, payloads sent with the previous token are rejected
+type RegenerateTokenCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type DeleteWebhookCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type GetWebhookQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type SearchWebhooksQuery struct {
+	OrgID int64
+}
+
+// IngestWebhookCommand imports the events of a webhook payload. The webhook is authenticated by its token alone,
+// the annotations are saved in its organization on behalf of its creator.
+type IngestWebhookCommand struct {
+	UID     string
+	Token   string
+	Payload []byte
+}
+
+// ImportCSVCommand imports the rows of a CSV document. The header row names the columns, time and text are required
+// and timeEnd, tags and externalId are optional. Tags are separated by commas.
+type ImportCSVCommand struct {
+	OrgID  int64
+	UserID int64
+	// Source scopes the external IDs of the rows, rows with an ID already imported from the same source are skipped
+	Source string
+	// Tags are added to every annotation
+	Tags []string
+	CSV  []byte
+}
+
+// ImportResult tells how many annotations were created and how many were skipped because their external ID was
+// already imported
+type ImportResult struct {
+	Imported   int `json:"imported"`
+	Duplicates int `json:"duplicates"`
+}
+
+// record is an annotation to import with the external ID it is deduplicated by
+type record struct {
+	item       annotations.Item
+	externalID string
+}
@@ -0,0 +1,176 @@
+package annotationretention
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const maxNameLength = 190
+
+// Service manages the retention policies of annotations. The policies are applied by the annotation cleanup job.
+type Service interface {
+	CreatePolicy(ctx context.Context, cmd *CreatePolicyCommand) (*Policy, error)
+	UpdatePolicy(ctx context.Context, cmd *UpdatePolicyCommand) (*Policy, error)
+	DeletePolicy(ctx context.Context, cmd *DeletePolicyCommand) error
+	GetPolicy(ctx context.Context, query *GetPolicyQuery) (*Policy, error)
+	SearchPolicies(ctx context.Context, query *SearchPoliciesQuery) ([]*Policy, error)
+	// GetAllPolicies returns the policies of every organization
+	GetAllPolicies(ctx context.Context) ([]*Policy, error)
+}
+
+type AnnotationRetentionService struct {
+	store         *sqlStore
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	now           func() time.Time
+}
+
+func ProvideService(sql db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *AnnotationRetentionService {
+	s := &AnnotationRetentionService{
+		store:         &sqlStore{db: sql},
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		now:           time.Now,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *AnnotationRetentionService) CreatePolicy(ctx context.Context, cmd *CreatePolicyCommand) (*Policy, error) {
+	if err := validateSettings(&cmd.PolicySettings); err != nil {
+		return nil, err
+	}
+
+	uid := cmd.UID
+	if uid == "" {
+		uid = util.GenerateShortUID()
+	} else if !util.IsValidShortUID(uid) || util.IsShortUIDTooLong(uid) {
+		return nil, ErrInvalidUID.Errorf("invalid uid %q", uid)
+	} else if _, err := s.store.get(ctx, cmd.OrgID, uid); err == nil {
+		return nil, ErrPolicyExists.Errorf("retention policy %s already exists", uid)
+	} else if !errors.Is(err, ErrPolicyNotFound) {
+		return nil, ErrInternal.Errorf("failed to check retention policy uid: %w", err)
+	}
+
+	now := s.now()
+	policy := &Policy{
+		UID:         uid,
+		OrgID:       cmd.OrgID,
+		Provisioned: cmd.Provisioned,
+		Created:     now,
+	}
+	applySettings(policy, &cmd.PolicySettings, now)
+	if err := s.store.insert(ctx, policy); err != nil {
+		return nil, ErrInternal.Errorf("failed to create retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (s *AnnotationRetentionService) UpdatePolicy(ctx context.Context, cmd *UpdatePolicyCommand) (*Policy, error) {
+	if err := validateSettings(&cmd.PolicySettings); err != nil {
+		return nil, err
+	}
+
+	policy, err := s.store.get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Provisioned && !cmd.Provisioned {
+		return nil, ErrPolicyProvisioned.Errorf("retention policy %s is provisioned", cmd.UID)
+	}
+
+	policy.Provisioned = cmd.Provisioned
+	applySettings(policy, &cmd.PolicySettings, s.now())
+	if err := s.store.update(ctx, policy); err != nil {
+		return nil, ErrInternal.Errorf("failed to update retention policy: %w", err)
+	}
+	return policy, nil
+}
+
+func (s *AnnotationRetentionService) DeletePolicy(ctx context.Context, cmd *DeletePolicyCommand) error {
+	policy, err := s.store.get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return err
+	}
+	if policy.Provisioned && !cmd.Provisioned {
+		return ErrPolicyProvisioned.Errorf("retention policy %s is provisioned", cmd.UID)
+	}
+	return s.store.delete(ctx, policy.ID)
+}
+
+func (s *AnnotationRetentionService) GetPolicy(ctx context.Context, query *GetPolicyQuery) (*Policy, error) {
+	return s.store.get(ctx, query.OrgID, query.UID)
+}
+
+func (s *AnnotationRetentionService) SearchPolicies(ctx context.Context, query *SearchPoliciesQuery) ([]*Policy, error) {
+	return s.store.search(ctx, query.OrgID)
+}
+
+func (s *AnnotationRetentionService) GetAllPolicies(ctx context.Context) ([]*Policy, error) {
+	return s.store.search(ctx, 0)
+}
+
+// MaxAgeDuration returns the parsed MaxAge of the policy, zero when it is not set
+func (p *Policy) MaxAgeDuration() (time.Duration, error) {
+	if p.MaxAge == "" {
+		return 0, nil
+	}
+	return gtime.ParseDuration(p.MaxAge)
+}
+
+func validateSettings(settings *PolicySettings) error {
+	settings.Name = strings.TrimSpace(settings.Name)
+	if settings.Name == "" || len(settings.Name) > maxNameLength {
+		return ErrInvalidPolicy.Errorf("name must be between 1 and %d characters", maxNameLength)
+	}
+
+	for i, t := range settings.Tags {
+		settings.Tags[i] = strings.TrimSpace(t)
+		if settings.Tags[i] == "" {
+			return ErrInvalidPolicy.Errorf("tag %d is empty", i)
+		}
+	}
+
+	settings.DashboardUID = strings.TrimSpace(settings.DashboardUID)
+	if settings.DashboardUID != "" && (!util.IsValidShortUID(settings.DashboardUID) || util.IsShortUIDTooLong(settings.DashboardUID)) {
+		return ErrInvalidPolicy.Errorf("invalid dashboard uid %q", settings.DashboardUID)
+	}
+
+	settings.MaxAge = strings.TrimSpace(settings.MaxAge)
+	if settings.MaxAge == "" && settings.MaxCount == 0 {
+		return ErrInvalidPolicy.Errorf("maxAge or maxCount is required")
+	}
+	if settings.MaxAge != "" {
+		maxAge, err := gtime.ParseDuration(settings.MaxAge)
+		if err != nil {
+			return ErrInvalidPolicy.Errorf("invalid maxAge %q: %w", settings.MaxAge, err)
+		}
+		if maxAge <= 0 {
+			return ErrInvalidPolicy.Errorf("maxAge must be positive")
+		}
+	}
+	if settings.MaxCount < 0 {
+		return ErrInvalidPolicy.Errorf("maxCount must not be negative")
+	}
+	return nil
+}
+
+func applySettings(policy *Policy, settings *PolicySettings, now time.Time) {
+	policy.Name = settings.Name
+	policy.Tags = settings.Tags
+	policy.DashboardUID = settings.DashboardUID
+	policy.MaxAge = settings.MaxAge
+	policy.MaxCount = settings.MaxCount
+	policy.Updated = now
+}
@@ -0,0 +1,103 @@
+package annotationretention
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestValidateSettings(t *testing.T) {
+	valid := []PolicySettings{
+		{Name: "Deploys", Tags: []string{"deploy"}, MaxAge: "2y"},
+		{Name: "Dashboard", DashboardUID: "noisy", MaxCount: 100},
+		{Name: "Everything", MaxAge: "90d", MaxCount: 100000},
+	}
+	for _, settings := range valid {
+		require.NoError(t, validateSettings(&settings), settings.Name)
+	}
+
+	invalid := []PolicySettings{
+		{Name: " ", MaxAge: "30d"},
+		{Name: "No limit", Tags: []string{"deploy"}},
+		{Name: "Empty tag", Tags: []string{" "}, MaxAge: "30d"},
+		{Name: "Bad age", MaxAge: "soon"},
+		{Name: "Negative age", MaxAge: "-1d"},
+		{Name: "Negative count", MaxCount: -1},
+		{Name: "Bad dashboard", DashboardUID: "not/a/uid", MaxAge: "30d"},
+	}
+	for _, settings := range invalid {
+		require.ErrorIs(t, validateSettings(&settings), ErrInvalidPolicy, settings.Name)
+	}
+}
+
+func TestIntegrationAnnotationRetentionService(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	s := ProvideService(db.InitTestDB(t), routing.NewRouteRegister(), actest.FakeAccessControl{})
+
+	policy, err := s.CreatePolicy(ctx, &CreatePolicyCommand{
+		UID:            "deploys",
+		OrgID:          1,
+		PolicySettings: PolicySettings{Name: " Deploys ", Tags: []string{"deploy"}, MaxAge: "2y"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Deploys", policy.Name)
+
+	_, err = s.CreatePolicy(ctx, &CreatePolicyCommand{UID: "deploys", OrgID: 1, PolicySettings: PolicySettings{Name: "Deploys", MaxCount: 1}})
+	require.ErrorIs(t, err, ErrPolicyExists)
+
+	_, err = s.CreatePolicy(ctx, &CreatePolicyCommand{
+		UID:            "ci",
+		OrgID:          2,
+		Provisioned:    true,
+		PolicySettings: PolicySettings{Name: "CI", Tags: []string{"ci"}, MaxAge: "30d"},
+	})
+	require.NoError(t, err)
+
+	updated, err := s.UpdatePolicy(ctx, &UpdatePolicyCommand{UID: "deploys", OrgID: 1, PolicySettings: PolicySettings{Name: "Deploys", MaxCount: 500}})
+	require.NoError(t, err)
+	assert.Empty(t, updated.MaxAge)
+
+	found, err := s.GetPolicy(ctx, &GetPolicyQuery{UID: "deploys", OrgID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(500), found.MaxCount)
+	assert.Empty(t, found.Tags)
+
+	policies, err := s.SearchPolicies(ctx, &SearchPoliciesQuery{OrgID: 2})
+	require.NoError(t, err)
+	require.Len(t, policies, 1)
+	assert.Equal(t, Tags{"ci"}, policies[0].Tags)
+
+	all, err := s.GetAllPolicies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	t.Run("provisioned policies can only be changed through provisioning", func(t *testing.T) {
+		_, err := s.UpdatePolicy(ctx, &UpdatePolicyCommand{UID: "ci", OrgID: 2, PolicySettings: PolicySettings{Name: "CI", MaxAge: "1y"}})
+		require.ErrorIs(t, err, ErrPolicyProvisioned)
+
+		err = s.DeletePolicy(ctx, &DeletePolicyCommand{UID: "ci", OrgID: 2})
+		require.ErrorIs(t, err, ErrPolicyProvisioned)
+
+		err = s.DeletePolicy(ctx, &DeletePolicyCommand{UID: "ci", OrgID: 2, Provisioned: true})
+		require.NoError(t, err)
+	})
+
+	require.NoError(t, s.DeletePolicy(ctx, &DeletePolicyCommand{UID: "deploys", OrgID: 1}))
+	_, err = s.GetPolicy(ctx, &GetPolicyQuery{UID: "deploys", OrgID: 1})
+	require.ErrorIs(t, err, ErrPolicyNotFound)
+}
@@ -0,0 +1,92 @@
+package annotationretentiontest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
+)
+
+var _ annotationretention.Service = (*FakeService)(nil)
+
+// FakeService keeps policies in memory
+type FakeService struct {
+	Policies      []*annotationretention.Policy
+	ExpectedError error
+}
+
+func NewFakeService(policies ...*annotationretention.Policy) *FakeService {
+	return &FakeService{Policies: policies}
+}
+
+func (f *FakeService) CreatePolicy(ctx context.Context, cmd *annotationretention.CreatePolicyCommand) (*annotationretention.Policy, error) {
+	if f.ExpectedError != nil {
+		return nil, f.ExpectedError
+	}
+	if _, err := f.GetPolicy(ctx, &annotationretention.GetPolicyQuery{UID: cmd.UID, OrgID: cmd.OrgID}); err == nil {
+		return nil, annotationretention.ErrPolicyExists.Errorf("retention policy %s already exists", cmd.UID)
+	}
+	policy := &annotationretention.Policy{UID: cmd.UID, OrgID: cmd.OrgID, Provisioned: cmd.Provisioned}
+	setSettings(policy, &cmd.PolicySettings)
+	f.Policies = append(f.Policies, policy)
+	return policy, nil
+}
+
+func (f *FakeService) UpdatePolicy(ctx context.Context, cmd *annotationretention.UpdatePolicyCommand) (*annotationretention.Policy, error) {
+	policy, err := f.GetPolicy(ctx, &annotationretention.GetPolicyQuery{UID: cmd.UID, OrgID: cmd.OrgID})
+	if err != nil {
+		return nil, err
+	}
+	policy.Provisioned = cmd.Provisioned
+	setSettings(policy, &cmd.PolicySettings)
+	return policy, nil
+}
+
+func (f *FakeService) DeletePolicy(ctx context.Context, cmd *annotationretention.DeletePolicyCommand) error {
+	if f.ExpectedError != nil {
+		return f.ExpectedError
+	}
+	for i, p := range f.Policies {
+		if p.OrgID == cmd.OrgID && p.UID == cmd.UID {
+			f.Policies = append(f.Policies[:i], f.Policies[i+1:]...)
+			return nil
+		}
+	}
+	return annotationretention.ErrPolicyNotFound.Errorf("retention policy %s not found", cmd.UID)
+}
+
+func (f *FakeService) GetPolicy(ctx context.Context, query *annotationretention.GetPolicyQuery) (*annotationretention.Policy, error) {
+	if f.ExpectedError != nil {
+		return nil, f.ExpectedError
+	}
+	for _, p := range f.Policies {
+		if p.OrgID == query.OrgID && p.UID == query.UID {
+			return p, nil
+		}
+	}
+	return nil, annotationretention.ErrPolicyNotFound.Errorf("retention policy %s not found", query.UID)
+}
+
+func (f *FakeService) SearchPolicies(ctx context.Context, query *annotationretention.SearchPoliciesQuery) ([]*annotationretention.Policy, error) {
+	if f.ExpectedError != nil {
+		return nil, f.ExpectedError
+	}
+	policies := make([]*annotationretention.Policy, 0)
+	for _, p := range f.Policies {
+		if p.OrgID == query.OrgID {
+			policies = append(policies, p)
+		}
+	}
+	return policies, nil
+}
+
+func (f *FakeService) GetAllPolicies(ctx context.Context) ([]*annotationretention.Policy, error) {
+	return f.Policies, f.ExpectedError
+}
+
+func setSettings(policy *annotationretention.Policy, settings *annotationretention.PolicySettings) {
+	policy.Name = settings.Name
+	policy.Tags = settings.Tags
+	policy.DashboardUID = settings.DashboardUID
+	policy.MaxAge = settings.MaxAge
+	policy.MaxCount = settings.MaxCount
+}
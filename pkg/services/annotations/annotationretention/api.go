@@ -0,0 +1,180 @@
+package annotationretention
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *AnnotationRetentionService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	read := authorize(ac.EvalPermission(ac.ActionAnnotationsRead, ac.ScopeAnnotationsTypeOrganization))
+	// policies delete annotations on behalf of the user who manages them
+	manage := authorize(ac.EvalPermission(ac.ActionAnnotationsDelete, ac.ScopeAnnotationsTypeOrganization))
+
+	s.routeRegister.Group("/api/annotations/retention-policies", func(policies routing.RouteRegister) {
+		policies.Get("/", read, routing.Wrap(s.searchHandler))
+		policies.Post("/", manage, routing.Wrap(s.createHandler))
+		policies.Get("/:uid", read, routing.Wrap(s.getHandler))
+		policies.Put("/:uid", manage, routing.Wrap(s.updateHandler))
+		policies.Delete("/:uid", manage, routing.Wrap(s.deleteHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /annotations/retention-policies annotations searchAnnotationRetentionPolicies
+//
+// Get all annotation retention policies of the organization.
+//
+// Responses:
+// 200: searchAnnotationRetentionPoliciesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *AnnotationRetentionService) searchHandler(c *contextmodel.ReqContext) response.Response {
+	policies, err := s.SearchPolicies(c.Req.Context(), &SearchPoliciesQuery{OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get retention policies", err)
+	}
+	return response.JSON(http.StatusOK, policies)
+}
+
+// swagger:route POST /annotations/retention-policies annotations createAnnotationRetentionPolicy
+//
+// Create an annotation retention policy.
+//
+// The cleanup job deletes the annotations matched by the policy that are older than `maxAge` or beyond the `maxCount` most recent ones.
+//
+// Responses:
+// 200: getAnnotationRetentionPolicyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *AnnotationRetentionService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreatePolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	policy, err := s.CreatePolicy(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route GET /annotations/retention-policies/{uid} annotations getAnnotationRetentionPolicy
+//
+// Get an annotation retention policy by UID.
+//
+// Responses:
+// 200: getAnnotationRetentionPolicyResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationRetentionService) getHandler(c *contextmodel.ReqContext) response.Response {
+	policy, err := s.GetPolicy(c.Req.Context(), &GetPolicyQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /annotations/retention-policies/{uid} annotations updateAnnotationRetentionPolicy
+//
+// Update an annotation retention policy.
+//
+// Provisioned policies cannot be updated.
+//
+// Responses:
+// 200: getAnnotationRetentionPolicyResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationRetentionService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := UpdatePolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	policy, err := s.UpdatePolicy(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route DELETE /annotations/retention-policies/{uid} annotations deleteAnnotationRetentionPolicy
+//
+// Delete an annotation retention policy.
+//
+// Provisioned policies cannot be deleted.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *AnnotationRetentionService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	err := s.DeletePolicy(c.Req.Context(), &DeletePolicyCommand{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.Success("Retention policy deleted")
+}
+
+// swagger:parameters createAnnotationRetentionPolicy
+type CreateAnnotationRetentionPolicyParams struct {
+	// in:body
+	// required:true
+	Body CreatePolicyCommand `json:"body"`
+}
+
+// swagger:parameters updateAnnotationRetentionPolicy
+type UpdateAnnotationRetentionPolicyParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body PolicySettings `json:"body"`
+}
+
+// swagger:parameters getAnnotationRetentionPolicy deleteAnnotationRetentionPolicy
+type AnnotationRetentionPolicyUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response searchAnnotationRetentionPoliciesResponse
+type SearchAnnotationRetentionPoliciesResponse struct {
+	// in: body
+	Body []*Policy `json:"body"`
+}
+
+// swagger:response getAnnotationRetentionPolicyResponse
+type GetAnnotationRetentionPolicyResponse struct {
+	// in: body
+	Body *Policy `json:"body"`
+}
@@ -0,0 +1,59 @@
+package annotationretention
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) insert(ctx context.Context, policy *Policy) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(policy)
+		return err
+	})
+}
+
+func (s *sqlStore) update(ctx context.Context, policy *Policy) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(policy.ID).AllCols().Update(policy)
+		return err
+	})
+}
+
+func (s *sqlStore) get(ctx context.Context, orgID int64, uid string) (*Policy, error) {
+	policy := &Policy{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(policy)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrPolicyNotFound.Errorf("retention policy %s not found", uid)
+		}
+		return nil
+	})
+	return policy, err
+}
+
+// search returns the policies of an organization, or of every organization when orgID is zero
+func (s *sqlStore) search(ctx context.Context, orgID int64) ([]*Policy, error) {
+	policies := make([]*Policy, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if orgID != 0 {
+			sess.Where("org_id = ?", orgID)
+		}
+		return sess.Asc("org_id", "name").Find(&policies)
+	})
+	return policies, err
+}
+
+func (s *sqlStore) delete(ctx context.Context, id int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Delete(&Policy{})
+		return err
+	})
+}
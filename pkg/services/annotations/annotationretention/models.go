@@ -0,0 +1,107 @@
+package annotationretention
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrPolicyNotFound    = errutil.NotFound("annotationretention.policyNotFound", errutil.WithPublicMessage("Annotation retention policy not found"))
+	ErrPolicyExists      = errutil.Conflict("annotationretention.policyExists", errutil.WithPublicMessage("An annotation retention policy with the same UID already exists"))
+	ErrPolicyProvisioned = errutil.BadRequest("annotationretention.policyProvisioned", errutil.WithPublicMessage("Provisioned annotation retention policies cannot be changed"))
+	ErrInvalidUID        = errutil.BadRequest("annotationretention.invalidUID", errutil.WithPublicMessage("Invalid retention policy UID"))
+	ErrInvalidPolicy     = errutil.BadRequest("annotationretention.invalidPolicy", errutil.WithPublicMessage("Invalid retention policy"))
+	ErrInternal          = errutil.Internal("annotationretention.internal")
+)
+
+// Tags select the annotations a policy applies to, an annotation must have all of them
+type Tags []string
+
+// FromDB is called by xorm when reading the column
+func (t *Tags) FromDB(data []byte) error {
+	*t = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, t)
+}
+
+// ToDB is called by xorm when writing the column
+func (t *Tags) ToDB() ([]byte, error) {
+	if *t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+// Policy deletes the annotations of an organization that are older than MaxAge or beyond the MaxCount most recent
+// ones. It applies to the annotations with all of its Tags and, when DashboardUID is set, to the annotations of that
+// dashboard only. A policy with neither applies to every annotation of the organization.
+//
+// Policies only ever delete annotations: an annotation is deleted as soon as one policy, or the global
+// [annotations] settings, say so.
+type Policy struct {
+	ID           int64  `json:"id" xorm:"pk autoincr 'id'"`
+	UID          string `json:"uid" xorm:"uid"`
+	OrgID        int64  `json:"orgId" xorm:"org_id"`
+	Name         string `json:"name"`
+	Tags         Tags   `json:"tags"`
+	DashboardUID string `json:"dashboardUid" xorm:"dashboard_uid"`
+	// MaxAge is a duration such as 30d or 2y, see gtime.ParseDuration
+	MaxAge      string    `json:"maxAge" xorm:"max_age"`
+	MaxCount    int64     `json:"maxCount" xorm:"max_count"`
+	Provisioned bool      `json:"provisioned"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
+
+func (Policy) TableName() string {
+	return "annotation_retention_policy"
+}
+
+// PolicySettings are the user editable properties of a policy
+type PolicySettings struct {
+	// required: true
+	Name string `json:"name"`
+	// Annotations must have all the tags for the policy to apply to them
+	Tags []string `json:"tags"`
+	// Restricts the policy to the annotations of a dashboard
+	DashboardUID string `json:"dashboardUid"`
+	// Annotations older than this duration are deleted, for example 30d or 2y
+	MaxAge string `json:"maxAge"`
+	// Only the most recent annotations are kept
+	MaxCount int64 `json:"maxCount"`
+}
+
+type CreatePolicyCommand struct {
+	PolicySettings
+	// Optional, generated when empty
+	UID   string `json:"uid"`
+	OrgID int64  `json:"-"`
+	// Provisioned policies can only be changed through provisioning
+	Provisioned bool `json:"-"`
+}
+
+type UpdatePolicyCommand struct {
+	PolicySettings
+	UID         string `json:"-"`
+	OrgID       int64  `json:"-"`
+	Provisioned bool   `json:"-"`
+}
+
+type DeletePolicyCommand struct {
+	UID         string
+	OrgID       int64
+	Provisioned bool
+}
+
+type GetPolicyQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type SearchPoliciesQuery struct {
+	OrgID int64
+}
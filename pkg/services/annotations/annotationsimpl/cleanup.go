@@ -2,20 +2,31 @@ package annotationsimpl
 
 import (
 	"context"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 // CleanupServiceImpl is responsible for cleaning old annotations.
 type CleanupServiceImpl struct {
-	store store
+	store            store
+	retentionService annotationretention.Service
+	log              log.Logger
+
+	policyDeletedAnnotations *prometheus.CounterVec
 }
 
-func ProvideCleanupService(db db.DB, cfg *setting.Cfg) *CleanupServiceImpl {
+func ProvideCleanupService(db db.DB, cfg *setting.Cfg, retentionService annotationretention.Service, registerer prometheus.Registerer) *CleanupServiceImpl {
 	return &CleanupServiceImpl{
-		store: NewXormStore(cfg, log.New("annotations"), db, nil),
+		store:                    NewXormStore(cfg, log.New("annotations"), db, nil),
+		retentionService:         retentionService,
+		log:                      log.New("annotations.cleanup"),
+		policyDeletedAnnotations: newPolicyDeletedAnnotationsMetric(registerer),
 	}
 }
 
@@ -26,9 +37,10 @@ const (
 )
 
 // Run deletes old annotations created by alert rules, API
-// requests and human made in the UI. It subsequently deletes orphaned rows
-// from the annotation_tag table. Cleanup actions are performed in batches
-// so that no query takes too long to complete.
+// requests and human made in the UI, then the annotations expired by
+// the retention policies of each organization. It subsequently deletes
+// orphaned rows from the annotation_tag table. Cleanup actions are
+// performed in batches so that no query takes too long to complete.
 //
 // Returns the number of annotation and annotation_tag rows deleted. If an
 // error occurs, it returns the number of rows affected so far.
@@ -51,8 +63,58 @@ func (cs *CleanupServiceImpl) Run(ctx context.Context, cfg *setting.Cfg) (int64,
 	if err != nil {
 		return totalCleanedAnnotations, 0, err
 	}
+
+	affected, err = cs.cleanByRetentionPolicies(ctx)
+	totalCleanedAnnotations += affected
+	if err != nil {
+		return totalCleanedAnnotations, 0, err
+	}
+
 	if totalCleanedAnnotations > 0 {
 		affected, err = cs.store.CleanOrphanedAnnotationTags(ctx)
 	}
 	return totalCleanedAnnotations, affected, err
 }
+
+// cleanByRetentionPolicies applies every retention policy in turn. A policy that cannot be applied, for instance
+// because its maximum age no longer parses, is logged and skipped so that it does not block the others.
+func (cs *CleanupServiceImpl) cleanByRetentionPolicies(ctx context.Context) (int64, error) {
+	policies, err := cs.retentionService.GetAllPolicies(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalAffected int64
+	for _, policy := range policies {
+		if ctx.Err() != nil {
+			return totalAffected, ctx.Err()
+		}
+
+		affected, err := cs.store.CleanAnnotationsByPolicy(ctx, policy)
+		totalAffected += affected
+		if affected > 0 {
+			cs.policyDeletedAnnotations.WithLabelValues(strconv.FormatInt(policy.OrgID, 10), policy.UID).Add(float64(affected))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return totalAffected, err
+			}
+			cs.log.Error("Failed to apply annotation retention policy", "orgId", policy.OrgID, "uid", policy.UID, "error", err)
+		}
+	}
+	return totalAffected, nil
+}
+
+func newPolicyDeletedAnnotationsMetric(registerer prometheus.Registerer) *prometheus.CounterVec {
+	policyDeletedAnnotations := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "annotations",
+		Name:      "retention_policy_deleted_total",
+		Help:      "Number of annotations deleted by each retention policy",
+	},
+		[]string{"org_id", "policy_uid"})
+	if registerer != nil {
+		registerer.MustRegister(policyDeletedAnnotations)
+	}
+	return policyDeletedAnnotations
+}
@@ -6,12 +6,21 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention/annotationretentiontest"
+	"github.com/grafana/grafana/pkg/services/annotations/testutil"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -128,7 +137,7 @@ func TestIntegrationAnnotationCleanUp(t *testing.T) {
 
 			cfg := setting.NewCfg()
 			cfg.AnnotationCleanupJobBatchSize = int64(test.annotationCleanupJobBatchSize)
-			cleaner := ProvideCleanupService(fakeSQL, cfg)
+			cleaner := ProvideCleanupService(fakeSQL, cfg, annotationretentiontest.NewFakeService(), nil)
 			affectedAnnotations, affectedAnnotationTags, err := cleaner.Run(context.Background(), test.cfg)
 			require.NoError(t, err)
 
@@ -205,6 +214,71 @@ func TestIntegrationOldAnnotationsAreDeletedFirst(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestIntegrationAnnotationCleanUpByRetentionPolicy(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test")
+	}
+
+	fakeSQL := db.InitTestDB(t)
+	t.Cleanup(func() {
+		err := fakeSQL.WithDbSession(context.Background(), func(session *db.Session) error {
+			_, deleteAnnotationErr := session.Exec("DELETE FROM annotation")
+			_, deleteAnnotationTagErr := session.Exec("DELETE FROM annotation_tag")
+			return errors.Join(deleteAnnotationErr, deleteAnnotationTagErr)
+		})
+		assert.NoError(t, err)
+	})
+
+	cfg := setting.NewCfg()
+	cfg.AnnotationCleanupJobBatchSize = 1
+	repo := NewXormStore(cfg, log.New("annotation.test"), fakeSQL, tagimpl.ProvideService(fakeSQL))
+	dashboard := testutil.CreateDashboard(t, fakeSQL, cfg, featuremgmt.WithFeatures(), dashboards.SaveDashboardCommand{
+		UserID:    1,
+		OrgID:     1,
+		Dashboard: simplejson.NewFromAny(map[string]any{"title": "Retention"}),
+	})
+
+	for _, item := range []*annotations.Item{
+		{OrgID: 1, Text: "old deploy", Tags: []string{"deploy"}},
+		{OrgID: 1, Text: "old ci", Tags: []string{"ci", "build:main"}},
+		{OrgID: 1, Text: "old ci of another branch", Tags: []string{"ci", "build:dev"}},
+		{OrgID: 1, Text: "new ci", Tags: []string{"ci", "build:main"}},
+		{OrgID: 2, Text: "old ci of another org", Tags: []string{"ci", "build:main"}},
+		{OrgID: 1, DashboardID: dashboard.ID, Text: "first dashboard annotation"},
+		{OrgID: 1, DashboardID: dashboard.ID, Text: "second dashboard annotation"},
+	} {
+		require.NoError(t, repo.Add(context.Background(), item))
+	}
+	err := fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE annotation SET created = ? WHERE text LIKE 'old%'", time.Now().AddDate(-1, 0, 0).UnixMilli())
+		return err
+	})
+	require.NoError(t, err)
+
+	retentionService := annotationretentiontest.NewFakeService(
+		&annotationretention.Policy{UID: "deploys", OrgID: 1, Tags: []string{"deploy"}, MaxAge: "2y"},
+		&annotationretention.Policy{UID: "main-builds", OrgID: 1, Tags: []string{"ci", "build:main"}, MaxAge: "30d"},
+		&annotationretention.Policy{UID: "invalid", OrgID: 1, MaxAge: "soon"},
+		&annotationretention.Policy{UID: "dashboard", OrgID: 1, DashboardUID: dashboard.UID, MaxCount: 1},
+	)
+	cleaner := ProvideCleanupService(fakeSQL, cfg, retentionService, prometheus.NewRegistry())
+	affectedAnnotations, affectedAnnotationTags, err := cleaner.Run(context.Background(), &setting.Cfg{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), affectedAnnotations)
+	assert.Equal(t, int64(2), affectedAnnotationTags)
+
+	texts := make([]string, 0)
+	err = fakeSQL.WithDbSession(context.Background(), func(sess *db.Session) error {
+		return sess.Table("annotation").Cols("text").Asc("id").Find(&texts)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"old deploy", "old ci of another branch", "new ci", "old ci of another org", "second dashboard annotation"}, texts)
+
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(cleaner.policyDeletedAnnotations.WithLabelValues("1", "main-builds")))
+	assert.Equal(t, float64(1), promtestutil.ToFloat64(cleaner.policyDeletedAnnotations.WithLabelValues("1", "dashboard")))
+	assert.Equal(t, float64(0), promtestutil.ToFloat64(cleaner.policyDeletedAnnotations.WithLabelValues("1", "deploys")))
+}
+
 func assertAnnotationCount(t *testing.T, fakeSQL db.DB, sql string, expectedCount int64) {
 	t.Helper()
 
@@ -6,6 +6,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/annotations/accesscontrol"
 
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -31,5 +32,6 @@ type writeStore interface {
 	Update(ctx context.Context, item *annotations.Item) error
 	Delete(ctx context.Context, params *annotations.DeleteParams) error
 	CleanAnnotations(ctx context.Context, cfg setting.AnnotationCleanupSettings, annotationType string) (int64, error)
+	CleanAnnotationsByPolicy(ctx context.Context, policy *annotationretention.Policy) (int64, error)
 	CleanOrphanedAnnotationTags(ctx context.Context) (int64, error)
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/services/tag"
@@ -564,6 +565,82 @@ func (r *xormRepositoryImpl) CleanAnnotations(ctx context.Context, cfg setting.A
 	return totalAffected, nil
 }
 
+// CleanAnnotationsByPolicy deletes the annotations matched by a retention policy that are older than its maximum age
+// or beyond its maximum count, using the same batched strategy as CleanAnnotations.
+func (r *xormRepositoryImpl) CleanAnnotationsByPolicy(ctx context.Context, policy *annotationretention.Policy) (int64, error) {
+	maxAge, err := policy.MaxAgeDuration()
+	if err != nil {
+		return 0, err
+	}
+
+	filter, args := r.retentionPolicyFilter(policy)
+	var totalAffected int64
+	if maxAge > 0 {
+		cutoffDate := timeNow().Add(-maxAge).UnixNano() / int64(time.Millisecond)
+		affected, err := untilDoneOrCancelled(ctx, func() (int64, error) {
+			cond := fmt.Sprintf(`%s AND created < %v ORDER BY id DESC %s`, filter, cutoffDate, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
+			ids, err := r.fetchIDs(ctx, "annotation", cond, args...)
+			if err != nil {
+				return 0, err
+			}
+
+			return r.deleteByIDs(ctx, "annotation", ids)
+		})
+		totalAffected += affected
+		if err != nil {
+			return totalAffected, err
+		}
+	}
+
+	if policy.MaxCount > 0 {
+		affected, err := untilDoneOrCancelled(ctx, func() (int64, error) {
+			cond := fmt.Sprintf(`%s ORDER BY id DESC %s`, filter, r.db.GetDialect().LimitOffset(r.cfg.AnnotationCleanupJobBatchSize, policy.MaxCount))
+			ids, err := r.fetchIDs(ctx, "annotation", cond, args...)
+			if err != nil {
+				return 0, err
+			}
+
+			return r.deleteByIDs(ctx, "annotation", ids)
+		})
+		totalAffected += affected
+		if err != nil {
+			return totalAffected, err
+		}
+	}
+
+	return totalAffected, nil
+}
+
+// retentionPolicyFilter returns the condition matching the annotations a policy applies to. Tags are matched the
+// same way as when searching annotations: an annotation must have all of them.
+func (r *xormRepositoryImpl) retentionPolicyFilter(policy *annotationretention.Policy) (string, []any) {
+	filter := "org_id = ?"
+	args := []any{policy.OrgID}
+
+	if policy.DashboardUID != "" {
+		filter += " AND dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ? AND uid = ?)"
+		args = append(args, policy.OrgID, policy.DashboardUID)
+	}
+
+	tags := tag.ParseTagPairs(policy.Tags)
+	if len(tags) > 0 {
+		keyValueFilters := make([]string, 0, len(tags))
+		for _, t := range tags {
+			if t.Value == "" {
+				keyValueFilters = append(keyValueFilters, "(tag."+r.db.GetDialect().Quote("key")+" = ?)")
+				args = append(args, t.Key)
+			} else {
+				keyValueFilters = append(keyValueFilters, "(tag."+r.db.GetDialect().Quote("key")+" = ? AND tag."+r.db.GetDialect().Quote("value")+" = ?)")
+				args = append(args, t.Key, t.Value)
+			}
+		}
+		filter += fmt.Sprintf(` AND (SELECT COUNT(1) FROM annotation_tag at INNER JOIN tag ON tag.id = at.tag_id WHERE at.annotation_id = annotation.id AND (%s)) = %d`,
+			strings.Join(keyValueFilters, " OR "), len(tags))
+	}
+
+	return filter, args
+}
+
 func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (int64, error) {
 	return untilDoneOrCancelled(ctx, func() (int64, error) {
 		cond := fmt.Sprintf(`NOT EXISTS (SELECT 1 FROM annotation a WHERE annotation_id = a.id) %s`, r.db.GetDialect().Limit(r.cfg.AnnotationCleanupJobBatchSize))
@@ -576,7 +653,7 @@ func (r *xormRepositoryImpl) CleanOrphanedAnnotationTags(ctx context.Context) (i
 	})
 }
 
-func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition string) ([]int64, error) {
+func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition string, args ...any) ([]int64, error) {
 	sql := fmt.Sprintf(`SELECT id FROM %s`, table)
 	if condition == "" {
 		return nil, fmt.Errorf("condition must be supplied; cannot fetch IDs from entire table")
@@ -584,7 +661,7 @@ func (r *xormRepositoryImpl) fetchIDs(ctx context.Context, table, condition stri
 	sql += fmt.Sprintf(` WHERE %s`, condition)
 	ids := make([]int64, 0)
 	err := r.db.WithDbSession(ctx, func(session *db.Session) error {
-		return session.SQL(sql).Find(&ids)
+		return session.SQL(sql, args...).Find(&ids)
 	})
 	return ids, err
 }
@@ -133,13 +133,29 @@ func testIntegrationApiKeyDataAccess(t *testing.T, fn getStore) {
 
 			assert.Nil(t, key.LastUsedAt)
 
-			err = ss.UpdateAPIKeyLastUsedDate(context.Background(), key.ID)
+			err = ss.UpdateAPIKeyLastUsed(context.Background(), key.ID, "10.0.0.1")
 			require.NoError(t, err)
 
 			query := apikey.GetByNameQuery{KeyName: "last-update-at", OrgID: 1}
 			key, err = ss.GetApiKeyByName(context.Background(), &query)
 			assert.Nil(t, err)
 			assert.NotNil(t, key.LastUsedAt)
+			assert.Equal(t, "10.0.0.1", key.LastUsedIP)
+		})
+
+		t.Run("Add a key with permissions and allowed networks", func(t *testing.T) {
+			cmd := apikey.AddCommand{
+				OrgID: 1, Name: "restricted", Key: "asd4",
+				Permissions:  []apikey.Permission{{Action: "dashboards:write", Scope: "folders:uid:ci"}},
+				AllowedCIDRs: []string{"10.0.0.0/8"},
+			}
+			_, err := ss.AddAPIKey(context.Background(), &cmd)
+			require.NoError(t, err)
+
+			key, err := ss.GetApiKeyByName(context.Background(), &apikey.GetByNameQuery{KeyName: "restricted", OrgID: 1})
+			require.NoError(t, err)
+			assert.Equal(t, cmd.Permissions, key.Permissions)
+			assert.Equal(t, cmd.AllowedCIDRs, key.AllowedCIDRs)
 		})
 
 		t.Run("Add a key with negative lifespan", func(t *testing.T) {
@@ -125,6 +125,8 @@ func (ss *sqlStore) AddAPIKey(ctx context.Context, cmd *apikey.AddCommand) (res
 			Expires:          expires,
 			ServiceAccountId: cmd.ServiceAccountID,
 			IsRevoked:        &isRevoked,
+			Permissions:      cmd.Permissions,
+			AllowedCIDRs:     cmd.AllowedCIDRs,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
@@ -184,10 +186,10 @@ func (ss *sqlStore) GetAPIKeyByHash(ctx context.Context, hash string) (*apikey.A
 	return &key, err
 }
 
-func (ss *sqlStore) UpdateAPIKeyLastUsedDate(ctx context.Context, tokenID int64) error {
+func (ss *sqlStore) UpdateAPIKeyLastUsed(ctx context.Context, tokenID int64, ip string) error {
 	now := timeNow()
 	return ss.db.WithDbSession(ctx, func(sess *db.Session) error {
-		if _, err := sess.Table("api_key").ID(tokenID).Cols("last_used_at").Update(&apikey.APIKey{LastUsedAt: &now}); err != nil {
+		if _, err := sess.Table("api_key").ID(tokenID).Cols("last_used_at", "last_used_ip").Update(&apikey.APIKey{LastUsedAt: &now, LastUsedIP: ip}); err != nil {
 			return err
 		}
 
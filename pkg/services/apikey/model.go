@@ -28,6 +28,21 @@ type APIKey struct {
 	Expires          *int64       `db:"expires"`
 	ServiceAccountId *int64       `db:"service_account_id"`
 	IsRevoked        *bool        `xorm:"is_revoked" db:"is_revoked"`
+	// Permissions restrict a service account token to a subset of the permissions of its service account.
+	// The token has all the permissions of its service account when empty.
+	Permissions []Permission `xorm:"json" db:"permissions"`
+	// AllowedCIDRs are the networks the key can be used from, it can be used from anywhere when empty
+	AllowedCIDRs []string `xorm:"'allowed_cidrs' json" db:"allowed_cidrs"`
+	LastUsedIP   string   `xorm:"last_used_ip" db:"last_used_ip"`
+	// RotationDue is set on service account tokens older than the configured rotation age
+	RotationDue bool `xorm:"rotation_due" db:"rotation_due"`
+}
+
+// Permission is an action and scope a service account token is allowed to use.
+// An empty scope allows the action on all the scopes the service account has.
+type Permission struct {
+	Action string `json:"action"`
+	Scope  string `json:"scope,omitempty"`
 }
 
 func (k APIKey) TableName() string { return "api_key" }
@@ -40,6 +55,8 @@ type AddCommand struct {
 	Key              string       `json:"-"`
 	SecondsToLive    int64        `json:"secondsToLive"`
 	ServiceAccountID *int64       `json:"-"`
+	Permissions      []Permission `json:"-"`
+	AllowedCIDRs     []string     `json:"-"`
 }
 
 type DeleteCommand struct {
@@ -0,0 +1,131 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// ActionRead is the RBAC action for reading the audit log of an organization
+const ActionRead = "auditlogs:read"
+
+// Audit actions, handlers describe what a request changed with one of them
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionMove    = "move"
+	ActionRestore = "restore"
+)
+
+// maxSummarySize is the size limit of the before and after summaries of an entry
+const maxSummarySize = 8 * 1024
+
+var ErrInvalidQuery = errutil.BadRequest("auditlog.invalidQuery")
+
+type Service interface {
+	// Record stores an entry, handlers should use Annotate to describe the change made by a request instead
+	Record(ctx context.Context, entry *Entry) error
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+}
+
+// Entry is a record of a change made to Grafana
+type Entry struct {
+	ID      int64     `xorm:"pk autoincr 'id'" json:"id"`
+	OrgID   int64     `xorm:"org_id" json:"orgId"`
+	Created time.Time `json:"created"`
+
+	// Actor is the namespaced ID of the identity making the change, such as user:1 or service-account:2
+	Actor      string `json:"actor"`
+	ActorLogin string `json:"actorLogin"`
+
+	Action       string `json:"action"`
+	ResourceKind string `json:"resourceKind"`
+	ResourceUID  string `xorm:"resource_uid" json:"resourceUid"`
+	ResourceName string `json:"resourceName"`
+	// Before and After are JSON summaries of the resource
+	Before string `xorm:"before_state" json:"before,omitempty"`
+	After  string `xorm:"after_state" json:"after,omitempty"`
+
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Route      string `json:"route"`
+	Status     int    `json:"status"`
+	RemoteAddr string `json:"remoteAddr"`
+	UserAgent  string `json:"userAgent"`
+	TraceID    string `xorm:"trace_id" json:"traceId,omitempty"`
+}
+
+func (e *Entry) TableName() string {
+	return "audit_log"
+}
+
+// Resource identifies what an entry is about. Kinds follow the RBAC resource names, such as datasources or
+// dashboards.permissions.
+type Resource struct {
+	Kind string
+	UID  string
+	Name string
+}
+
+type SearchQuery struct {
+	OrgID        int64
+	Actor        string
+	Action       string
+	ResourceKind string
+	ResourceUID  string
+	From         time.Time
+	To           time.Time
+	Page         int
+	Limit        int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Entries    []*Entry `json:"entries"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
+
+type entryKey struct{}
+
+// WithEntry attaches the entry recorded for a request to its context
+func WithEntry(ctx context.Context, entry *Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// IsAudited returns true when the request in ctx is recorded in the audit log. Handlers use it to skip loading the
+// previous state of resources when nothing records it.
+func IsAudited(ctx context.Context) bool {
+	_, ok := ctx.Value(entryKey{}).(*Entry)
+	return ok
+}
+
+// Annotate describes the change made by the request in ctx. before and after are summaries of the resource, which
+// must not contain secrets. It does nothing when the request isn't audited.
+func Annotate(ctx context.Context, action string, resource Resource, before, after any) {
+	entry, ok := ctx.Value(entryKey{}).(*Entry)
+	if !ok {
+		return
+	}
+	entry.Action = action
+	entry.ResourceKind = resource.Kind
+	entry.ResourceUID = resource.UID
+	entry.ResourceName = resource.Name
+	entry.Before = summary(before)
+	entry.After = summary(after)
+}
+
+func summary(v any) string {
+	data, err := json.Marshal(v)
+	// nil maps and pointers are marshaled as null
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	if len(data) > maxSummarySize {
+		data, _ = json.Marshal(map[string]any{"truncated": true, "size": len(data)})
+	}
+	return string(data)
+}
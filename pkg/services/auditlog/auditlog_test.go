@@ -0,0 +1,42 @@
+package auditlog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotate(t *testing.T) {
+	t.Run("does nothing when the request isn't audited", func(t *testing.T) {
+		ctx := context.Background()
+		assert.False(t, IsAudited(ctx))
+		Annotate(ctx, ActionCreate, Resource{Kind: "dashboards"}, nil, nil)
+	})
+
+	t.Run("describes the change in the entry of the request", func(t *testing.T) {
+		entry := &Entry{}
+		ctx := WithEntry(context.Background(), entry)
+		assert.True(t, IsAudited(ctx))
+
+		var before map[string]string
+		Annotate(ctx, ActionUpdate, Resource{Kind: "dashboards", UID: "abc", Name: "Home"}, before, map[string]any{"title": "Home"})
+
+		assert.Equal(t, ActionUpdate, entry.Action)
+		assert.Equal(t, "dashboards", entry.ResourceKind)
+		assert.Equal(t, "abc", entry.ResourceUID)
+		assert.Equal(t, "Home", entry.ResourceName)
+		assert.Empty(t, entry.Before)
+		assert.JSONEq(t, `{"title": "Home"}`, entry.After)
+	})
+
+	t.Run("truncates large summaries", func(t *testing.T) {
+		entry := &Entry{}
+		ctx := WithEntry(context.Background(), entry)
+
+		Annotate(ctx, ActionCreate, Resource{Kind: "dashboards"}, nil, map[string]string{"title": strings.Repeat("a", maxSummarySize)})
+
+		assert.JSONEq(t, `{"truncated": true, "size": 8204}`, entry.After)
+	})
+}
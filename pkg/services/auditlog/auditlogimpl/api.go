@@ -0,0 +1,45 @@
+package auditlogimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+
+	routeRegister.Get("/api/audit-logs", middleware.ReqSignedIn, authorize(ac.EvalPermission(auditlog.ActionRead)), routing.Wrap(s.handleSearch))
+}
+
+// handleSearch lists the audit log entries of the organization, newest first. from and to are epoch
+// timestamps in milliseconds.
+func (s *Service) handleSearch(c *contextmodel.ReqContext) response.Response {
+	query := &auditlog.SearchQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		Actor:        c.Query("actor"),
+		Action:       c.Query("action"),
+		ResourceKind: c.Query("resourceKind"),
+		ResourceUID:  c.Query("resourceUid"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+
+	result, err := s.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search the audit log", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
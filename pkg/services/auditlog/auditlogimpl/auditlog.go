@@ -0,0 +1,173 @@
+package auditlogimpl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	cleanUpInterval = time.Hour
+	defaultPerPage  = 100
+	maxPerPage      = 1000
+)
+
+// defaultExcludePaths are mutating endpoints that don't change anything, such as queries
+var defaultExcludePaths = []string{
+	"/api/ds/query",
+	"/api/frontend-metrics",
+	"/api/dashboards/calculate-diff",
+	"/api/user/helpflags",
+	"/api/user/stars",
+	"/api/query-history",
+	"/api/search-v2",
+	"/api/datasources/proxy/",
+	"/api/live/",
+}
+
+var _ auditlog.Service = (*Service)(nil)
+
+type Service struct {
+	accessControl ac.AccessControl
+	store         *sqlStore
+	sink          *fileSink
+	log           log.Logger
+	now           func() time.Time
+
+	enabled        bool
+	maxAge         time.Duration
+	excludePaths   []string
+	trustedProxies []*net.IPNet
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	sql db.DB,
+	httpServer *api.HTTPServer,
+	routeRegister routing.RouteRegister,
+	accessControl ac.AccessControl,
+	accesscontrolService ac.Service,
+) (*Service, error) {
+	section := cfg.SectionWithEnvOverrides("audit")
+	s := &Service{
+		accessControl: accessControl,
+		store:         &sqlStore{db: sql},
+		log:           log.New("auditlog"),
+		now:           time.Now,
+		enabled:       section.Key("enabled").MustBool(false),
+		excludePaths:  section.Key("exclude_paths").Strings(" "),
+		// forwarded headers are only trusted from the configured reverse proxies
+		trustedProxies: cfg.TrustedProxies,
+	}
+
+	if !s.enabled {
+		return s, nil
+	}
+
+	maxAge, err := gtime.ParseDuration(section.Key("max_age").MustString("90d"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid [audit] max_age: %w", err)
+	}
+	s.maxAge = maxAge
+
+	if len(s.excludePaths) == 0 {
+		s.excludePaths = defaultExcludePaths
+	}
+
+	if path := section.Key("export_path").MustString(""); path != "" {
+		sink, err := newFileSink(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open audit log export file: %w", err)
+		}
+		s.sink = sink
+	}
+
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+
+	httpServer.AddMiddleware(s.middleware)
+	s.registerAPIEndpoints(routeRegister)
+
+	return s, nil
+}
+
+func (s *Service) Record(ctx context.Context, entry *auditlog.Entry) error {
+	if entry.Created.IsZero() {
+		entry.Created = s.now()
+	}
+	if err := s.store.insert(ctx, entry); err != nil {
+		return err
+	}
+	if s.sink != nil {
+		if err := s.sink.write(entry); err != nil {
+			s.log.Warn("Failed to export audit log entry", "error", err)
+		}
+	}
+	return nil
+}
+
+func (s *Service) Search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultPerPage
+	}
+	if query.Limit > maxPerPage {
+		return nil, auditlog.ErrInvalidQuery.Errorf("perpage cannot be larger than %d", maxPerPage)
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && query.From.After(query.To) {
+		return nil, auditlog.ErrInvalidQuery.Errorf("from must be before to")
+	}
+	return s.store.search(ctx, query)
+}
+
+// Run removes entries older than the configured max age
+func (s *Service) Run(ctx context.Context) error {
+	if !s.enabled {
+		return nil
+	}
+	if s.sink != nil {
+		defer func() {
+			if err := s.sink.close(); err != nil {
+				s.log.Warn("Failed to close audit log export file", "error", err)
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(cleanUpInterval)
+	defer ticker.Stop()
+	for {
+		s.cleanup(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	if s.maxAge <= 0 {
+		return
+	}
+	deleted, err := s.store.deleteOlderThan(ctx, s.now().Add(-s.maxAge))
+	if err != nil {
+		s.log.Error("Failed to remove old audit log entries", "error", err)
+		return
+	}
+	if deleted > 0 {
+		s.log.Debug("Removed old audit log entries", "count", deleted)
+	}
+}
@@ -0,0 +1,161 @@
+package auditlogimpl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func setupTestService(t *testing.T) *Service {
+	t.Helper()
+
+	return &Service{
+		store:        &sqlStore{db: db.InitTestDB(t)},
+		log:          log.NewNopLogger(),
+		now:          time.Now,
+		enabled:      true,
+		maxAge:       24 * time.Hour,
+		excludePaths: defaultExcludePaths,
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	s := setupTestService(t)
+
+	server := webtest.NewServer(t, routing.NewRouteRegister())
+	server.Mux.UseMiddleware(s.middleware)
+	server.Mux.Post("/api/datasources", func(c *contextmodel.ReqContext) {
+		assert.True(t, auditlog.IsAudited(c.Req.Context()))
+		auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, auditlog.Resource{Kind: "datasources", UID: "abc", Name: "Loki"},
+			nil, map[string]any{"type": "loki"})
+		c.JSON(http.StatusOK, nil)
+	})
+	server.Mux.Put("/api/org/preferences", func(c *contextmodel.ReqContext) {
+		c.JSON(http.StatusOK, nil)
+	})
+	server.Mux.Post("/api/ds/query", func(c *contextmodel.ReqContext) {
+		assert.False(t, auditlog.IsAudited(c.Req.Context()))
+		c.JSON(http.StatusOK, nil)
+	})
+	server.Mux.Get("/api/datasources", func(c *contextmodel.ReqContext) {
+		assert.False(t, auditlog.IsAudited(c.Req.Context()))
+		c.JSON(http.StatusOK, nil)
+	})
+
+	usr := &user.SignedInUser{UserID: 1, OrgID: 2, Login: "admin"}
+	send := func(method, target string) {
+		req := server.NewRequest(method, target, strings.NewReader("{}"))
+		req.Header.Set("User-Agent", "test")
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		res, err := server.Send(webtest.RequestWithSignedInUser(req, usr))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+	}
+
+	send(http.MethodPost, "/api/datasources")
+	send(http.MethodPut, "/api/org/preferences")
+	send(http.MethodPost, "/api/ds/query")
+	send(http.MethodGet, "/api/datasources")
+
+	result, err := s.Search(context.Background(), &auditlog.SearchQuery{OrgID: 2})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 2)
+
+	updated, created := result.Entries[0], result.Entries[1]
+	assert.Equal(t, auditlog.ActionCreate, created.Action)
+	assert.Equal(t, "user:1", created.Actor)
+	assert.Equal(t, "admin", created.ActorLogin)
+	assert.Equal(t, "datasources", created.ResourceKind)
+	assert.Equal(t, "abc", created.ResourceUID)
+	assert.Equal(t, "Loki", created.ResourceName)
+	assert.Empty(t, created.Before)
+	assert.JSONEq(t, `{"type": "loki"}`, created.After)
+	assert.Equal(t, http.MethodPost, created.Method)
+	assert.Equal(t, "/api/datasources", created.Path)
+	assert.Equal(t, http.StatusOK, created.Status)
+	assert.Equal(t, "test", created.UserAgent)
+	// the forwarded header is ignored, the test client is not a trusted proxy
+	assert.Equal(t, "127.0.0.1", created.RemoteAddr)
+
+	assert.Equal(t, auditlog.ActionUpdate, updated.Action)
+	assert.Equal(t, "/api/org/preferences", updated.Path)
+	assert.Empty(t, updated.ResourceKind)
+
+	_, loopback, err := net.ParseCIDR("127.0.0.0/8")
+	require.NoError(t, err)
+	s.trustedProxies = []*net.IPNet{loopback}
+	send(http.MethodPut, "/api/org/preferences")
+
+	result, err = s.Search(context.Background(), &auditlog.SearchQuery{OrgID: 2})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 3)
+	assert.Equal(t, "203.0.113.7", result.Entries[0].RemoteAddr)
+}
+
+func TestSearch(t *testing.T) {
+	s := setupTestService(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	entries := []*auditlog.Entry{
+		{OrgID: 1, Created: now.Add(-3 * time.Hour), Actor: "user:1", Action: auditlog.ActionCreate, ResourceKind: "dashboards", ResourceUID: "a"},
+		{OrgID: 1, Created: now.Add(-2 * time.Hour), Actor: "user:2", Action: auditlog.ActionUpdate, ResourceKind: "dashboards", ResourceUID: "a"},
+		{OrgID: 1, Created: now.Add(-time.Hour), Actor: "user:1", Action: auditlog.ActionDelete, ResourceKind: "datasources", ResourceUID: "b"},
+		{OrgID: 2, Created: now, Actor: "user:1", Action: auditlog.ActionCreate, ResourceKind: "dashboards", ResourceUID: "a"},
+	}
+	for _, e := range entries {
+		require.NoError(t, s.Record(ctx, e))
+	}
+
+	search := func(query auditlog.SearchQuery) []int64 {
+		t.Helper()
+		result, err := s.Search(ctx, &query)
+		require.NoError(t, err)
+		ids := make([]int64, 0, len(result.Entries))
+		for _, e := range result.Entries {
+			ids = append(ids, e.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int64{entries[2].ID, entries[1].ID, entries[0].ID}, search(auditlog.SearchQuery{OrgID: 1}))
+	assert.Equal(t, []int64{entries[2].ID, entries[0].ID}, search(auditlog.SearchQuery{OrgID: 1, Actor: "user:1"}))
+	assert.Equal(t, []int64{entries[1].ID}, search(auditlog.SearchQuery{OrgID: 1, Action: auditlog.ActionUpdate}))
+	assert.Equal(t, []int64{entries[1].ID, entries[0].ID}, search(auditlog.SearchQuery{OrgID: 1, ResourceKind: "dashboards", ResourceUID: "a"}))
+	assert.Equal(t, []int64{entries[1].ID}, search(auditlog.SearchQuery{OrgID: 1, From: now.Add(-150 * time.Minute), To: now.Add(-90 * time.Minute)}))
+	assert.Equal(t, []int64{entries[1].ID}, search(auditlog.SearchQuery{OrgID: 1, Page: 2, Limit: 1}))
+
+	result, err := s.Search(ctx, &auditlog.SearchQuery{OrgID: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.TotalCount)
+	assert.Equal(t, 1, result.Page)
+	assert.Equal(t, 2, result.PerPage)
+
+	_, err = s.Search(ctx, &auditlog.SearchQuery{OrgID: 1, Limit: maxPerPage + 1})
+	assert.ErrorIs(t, err, auditlog.ErrInvalidQuery)
+
+	t.Run("cleanup removes entries older than the max age", func(t *testing.T) {
+		s.maxAge = 90 * time.Minute
+		s.cleanup(ctx)
+		assert.Equal(t, []int64{entries[2].ID}, search(auditlog.SearchQuery{OrgID: 1}))
+	})
+}
@@ -0,0 +1,86 @@
+package auditlogimpl
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// middleware records the requests changing Grafana. Handlers describe the change with auditlog.Annotate, other
+// mutating API requests are recorded with the action derived from their method.
+func (s *Service) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := contexthandler.FromContext(r.Context())
+		if c == nil || !s.shouldAudit(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		entry := &auditlog.Entry{}
+		// This modifies both r and c.Req since they point to the same value
+		*c.Req = *c.Req.WithContext(auditlog.WithEntry(c.Req.Context(), entry))
+
+		next.ServeHTTP(w, r)
+
+		// Requests that aren't signed in are rejected before changing anything
+		if !c.IsSignedIn {
+			return
+		}
+
+		annotated := entry.Action != ""
+		if !annotated {
+			entry.Action = actionFromMethod(r.Method)
+		}
+
+		entry.OrgID = c.SignedInUser.GetOrgID()
+		entry.Actor = c.SignedInUser.GetID().String()
+		entry.ActorLogin = c.SignedInUser.GetLogin()
+		entry.Method = r.Method
+		entry.Path = r.URL.Path
+		entry.Status = c.Resp.Status()
+		entry.RemoteAddr = web.ClientIP(c.Req, s.trustedProxies)
+		entry.UserAgent = r.UserAgent()
+		entry.TraceID = tracing.TraceIDFromContext(c.Req.Context(), false)
+		if route, ok := middleware.RouteOperationName(c.Req); ok {
+			entry.Route = route
+		}
+
+		// The entry is recorded even when the client has gone away
+		if err := s.Record(context.WithoutCancel(c.Req.Context()), entry); err != nil {
+			s.log.Error("Failed to record audit log entry", "error", err, "path", entry.Path)
+		}
+	})
+}
+
+// shouldAudit returns true for the mutating API requests that aren't excluded by the configuration
+func (s *Service) shouldAudit(r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+		return false
+	}
+	if !strings.HasPrefix(r.URL.Path, "/api/") {
+		return false
+	}
+	for _, prefix := range s.excludePaths {
+		if strings.HasPrefix(r.URL.Path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func actionFromMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return auditlog.ActionCreate
+	case http.MethodDelete:
+		return auditlog.ActionDelete
+	default:
+		return auditlog.ActionUpdate
+	}
+}
@@ -0,0 +1,24 @@
+package auditlogimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var auditLogReaderRole = accesscontrol.RoleDTO{
+	Name:        "fixed:auditlogs:reader",
+	DisplayName: "Audit log reader",
+	Description: "Read the audit log of the organization",
+	Group:       "Audit log",
+	Permissions: []accesscontrol.Permission{
+		{Action: auditlog.ActionRead},
+	},
+}
+
+func declareFixedRoles(ac accesscontrol.Service) error {
+	return ac.DeclareFixedRoles(accesscontrol.RoleRegistration{
+		Role:   auditLogReaderRole,
+		Grants: []string{string(org.RoleAdmin), accesscontrol.RoleGrafanaAdmin},
+	})
+}
@@ -0,0 +1,44 @@
+package auditlogimpl
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+// fileSink exports entries to a file as JSON lines, so that they can be shipped to a log pipeline
+type fileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	// Safe to ignore gosec warning G304, the path comes from the configuration file.
+	// nolint:gosec
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: file}, nil
+}
+
+func (s *fileSink) write(entry *auditlog.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(data)
+	return err
+}
+
+func (s *fileSink) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,82 @@
+package auditlogimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/auditlog"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) insert(ctx context.Context, entry *auditlog.Entry) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+func (s *sqlStore) search(ctx context.Context, query *auditlog.SearchQuery) (*auditlog.SearchResult, error) {
+	result := &auditlog.SearchResult{
+		Entries: []*auditlog.Entry{},
+		Page:    query.Page,
+		PerPage: query.Limit,
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		filters := []string{"org_id = ?"}
+		params := []any{query.OrgID}
+		if query.Actor != "" {
+			filters = append(filters, "actor = ?")
+			params = append(params, query.Actor)
+		}
+		if query.Action != "" {
+			filters = append(filters, "action = ?")
+			params = append(params, query.Action)
+		}
+		if query.ResourceKind != "" {
+			filters = append(filters, "resource_kind = ?")
+			params = append(params, query.ResourceKind)
+		}
+		if query.ResourceUID != "" {
+			filters = append(filters, "resource_uid = ?")
+			params = append(params, query.ResourceUID)
+		}
+		if !query.From.IsZero() {
+			filters = append(filters, "created >= ?")
+			params = append(params, query.From)
+		}
+		if !query.To.IsZero() {
+			filters = append(filters, "created <= ?")
+			params = append(params, query.To)
+		}
+		where := strings.Join(filters, " AND ")
+
+		count, err := sess.Where(where, params...).Count(&auditlog.Entry{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		offset := (query.Page - 1) * query.Limit
+		return sess.Where(where, params...).Desc("created", "id").Limit(query.Limit, offset).Find(&result.Entries)
+	})
+	return result, err
+}
+
+// deleteOlderThan removes the entries created before t and returns how many were removed
+func (s *sqlStore) deleteOlderThan(ctx context.Context, t time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM audit_log WHERE created < ?", t)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"time"
 
 	"github.com/grafana/grafana/pkg/models/usertoken"
 	"github.com/grafana/grafana/pkg/registry"
@@ -20,8 +21,9 @@ const (
 
 // Typed errors
 var (
-	ErrUserTokenNotFound   = errors.New("user token not found")
-	ErrInvalidSessionToken = usertoken.ErrInvalidSessionToken
+	ErrUserTokenNotFound    = errors.New("user token not found")
+	ErrInvalidSessionToken  = usertoken.ErrInvalidSessionToken
+	ErrRevokeTokensNoFilter = errors.New("at least one of the user, the organization and the creation time of the tokens to revoke is required")
 )
 
 type (
@@ -58,6 +60,18 @@ type RevokeAuthTokenCmd struct {
 	AuthTokenId int64 `json:"authTokenId"`
 }
 
+// RevokeTokensCmd selects the tokens to revoke, tokens have to match all the criteria that are set
+type RevokeTokensCmd struct {
+	// UserID selects the tokens of a user
+	UserID int64
+	// OrgID selects the tokens of the members of an organization
+	OrgID int64
+	// CreatedBefore selects the tokens created before this time
+	CreatedBefore time.Time
+	// ExceptTokenID keeps a token, usually the one of the caller
+	ExceptTokenID int64
+}
+
 type RotateCommand struct {
 	// token is the un-hashed token
 	UnHashedToken string
@@ -73,6 +87,9 @@ type UserTokenService interface {
 	RotateToken(ctx context.Context, cmd RotateCommand) (*UserToken, error)
 	RevokeToken(ctx context.Context, token *UserToken, soft bool) error
 	RevokeAllUserTokens(ctx context.Context, userID int64) error
+	// RevokeTokens revokes the tokens matching cmd and returns how many were revoked. Revoked tokens are rejected from
+	// their next request.
+	RevokeTokens(ctx context.Context, cmd *RevokeTokensCmd) (int64, error)
 	GetUserToken(ctx context.Context, userID, userTokenID int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userID int64) ([]*UserToken, error)
 	ActiveTokenCount(ctx context.Context, userID *int64) (int64, error)
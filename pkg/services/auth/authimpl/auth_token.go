@@ -355,6 +355,50 @@ func (s *UserAuthTokenService) RevokeAllUserTokens(ctx context.Context, userId i
 	})
 }
 
+func (s *UserAuthTokenService) RevokeTokens(ctx context.Context, cmd *auth.RevokeTokensCmd) (int64, error) {
+	filters := []string{}
+	params := []any{getTime().Unix()}
+	if cmd.UserID > 0 {
+		filters = append(filters, "user_id = ?")
+		params = append(params, cmd.UserID)
+	}
+	if cmd.OrgID > 0 {
+		filters = append(filters, "user_id IN (SELECT user_id FROM org_user WHERE org_id = ?)")
+		params = append(params, cmd.OrgID)
+	}
+	if !cmd.CreatedBefore.IsZero() {
+		filters = append(filters, "created_at < ?")
+		params = append(params, cmd.CreatedBefore.Unix())
+	}
+	// never revoke all the tokens at once
+	if len(filters) == 0 {
+		return 0, auth.ErrRevokeTokensNoFilter
+	}
+	if cmd.ExceptTokenID > 0 {
+		filters = append(filters, "id <> ?")
+		params = append(params, cmd.ExceptTokenID)
+	}
+
+	var affected int64
+	err := s.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
+		// tokens are soft revoked, so that the next request made with them fails with a revoked token error
+		sql := "UPDATE user_auth_token SET revoked_at = ? WHERE revoked_at = 0 AND " + strings.Join(filters, " AND ")
+		res, err := dbSession.Exec(append([]any{sql}, params...)...)
+		if err != nil {
+			return err
+		}
+
+		affected, err = res.RowsAffected()
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.log.FromContext(ctx).Debug("User tokens revoked", "userID", cmd.UserID, "orgID", cmd.OrgID, "createdBefore", cmd.CreatedBefore, "count", affected)
+	return affected, nil
+}
+
 func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, userIds []int64) error {
 	return s.sqlStore.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
 		if len(userIds) == 0 {
@@ -637,3 +637,68 @@ func TestIntegrationTokenCount(t *testing.T) {
 	require.Nil(t, err)
 	require.Equal(t, int64(0), count)
 }
+
+func TestIntegrationRevokeTokens(t *testing.T) {
+	ctx := createTestContext(t)
+
+	now := time.Date(2018, 12, 13, 13, 45, 0, 0, time.UTC)
+	defer func() { getTime = time.Now }()
+
+	createToken := func(userID int64, createdAt time.Time) *auth.UserToken {
+		getTime = func() time.Time { return createdAt }
+		userToken, err := ctx.tokenService.CreateToken(context.Background(), &user.User{ID: userID},
+			net.ParseIP("192.168.10.11"), "some user agent")
+		require.Nil(t, err)
+		return userToken
+	}
+
+	old := createToken(1, now.Add(-72*time.Hour))
+	current := createToken(1, now)
+	other := createToken(1, now)
+	oldMember := createToken(2, now.Add(-72*time.Hour))
+	member := createToken(3, now)
+	getTime = func() time.Time { return now }
+
+	err := ctx.sqlstore.WithDbSession(context.Background(), func(sess *db.Session) error {
+		for _, userID := range []int64{2, 3} {
+			if _, err := sess.Exec("INSERT INTO org_user (org_id, user_id, role, created, updated) VALUES (?, ?, ?, ?, ?)",
+				2, userID, "Viewer", now, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.Nil(t, err)
+
+	revoke := func(cmd auth.RevokeTokensCmd) int64 {
+		t.Helper()
+		count, err := ctx.tokenService.RevokeTokens(context.Background(), &cmd)
+		require.Nil(t, err)
+		return count
+	}
+
+	_, err = ctx.tokenService.RevokeTokens(context.Background(), &auth.RevokeTokensCmd{ExceptTokenID: current.Id})
+	require.ErrorIs(t, err, auth.ErrRevokeTokensNoFilter)
+
+	assert.Equal(t, int64(2), revoke(auth.RevokeTokensCmd{UserID: 1, ExceptTokenID: current.Id}))
+	_, err = ctx.tokenService.LookupToken(context.Background(), old.UnhashedToken)
+	var revokedErr *auth.TokenRevokedError
+	require.ErrorAs(t, err, &revokedErr)
+	_, err = ctx.tokenService.LookupToken(context.Background(), other.UnhashedToken)
+	require.ErrorAs(t, err, &revokedErr)
+	_, err = ctx.tokenService.LookupToken(context.Background(), current.UnhashedToken)
+	require.Nil(t, err)
+
+	assert.Equal(t, int64(1), revoke(auth.RevokeTokensCmd{CreatedBefore: now.Add(-24 * time.Hour)}))
+	_, err = ctx.tokenService.LookupToken(context.Background(), oldMember.UnhashedToken)
+	require.ErrorAs(t, err, &revokedErr)
+
+	assert.Equal(t, int64(1), revoke(auth.RevokeTokensCmd{OrgID: 2}))
+	_, err = ctx.tokenService.LookupToken(context.Background(), member.UnhashedToken)
+	require.ErrorAs(t, err, &revokedErr)
+
+	tokens, err := ctx.tokenService.GetUserTokens(context.Background(), 1)
+	require.Nil(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, current.Id, tokens[0].Id)
+}
@@ -21,6 +21,7 @@ type FakeUserAuthTokenService struct {
 	LookupTokenProvider          func(ctx context.Context, unhashedToken string) (*auth.UserToken, error)
 	RevokeTokenProvider          func(ctx context.Context, token *auth.UserToken, soft bool) error
 	RevokeAllUserTokensProvider  func(ctx context.Context, userID int64) error
+	RevokeTokensProvider         func(ctx context.Context, cmd *auth.RevokeTokensCmd) (int64, error)
 	ActiveTokenCountProvider     func(ctx context.Context, userID *int64) (int64, error)
 	GetUserTokenProvider         func(ctx context.Context, userID, userTokenID int64) (*auth.UserToken, error)
 	GetUserTokensProvider        func(ctx context.Context, userID int64) ([]*auth.UserToken, error)
@@ -51,6 +52,9 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		RevokeAllUserTokensProvider: func(ctx context.Context, userId int64) error {
 			return nil
 		},
+		RevokeTokensProvider: func(ctx context.Context, cmd *auth.RevokeTokensCmd) (int64, error) {
+			return 0, nil
+		},
 		BatchRevokedTokenProvider: func(ctx context.Context, userIds []int64) error {
 			return nil
 		},
@@ -92,6 +96,10 @@ func (s *FakeUserAuthTokenService) RevokeAllUserTokens(ctx context.Context, user
 	return s.RevokeAllUserTokensProvider(context.Background(), userId)
 }
 
+func (s *FakeUserAuthTokenService) RevokeTokens(ctx context.Context, cmd *auth.RevokeTokensCmd) (int64, error) {
+	return s.RevokeTokensProvider(ctx, cmd)
+}
+
 func (s *FakeUserAuthTokenService) ActiveTokenCount(ctx context.Context, userID *int64) (int64, error) {
 	return s.ActiveTokenCountProvider(context.Background(), userID)
 }
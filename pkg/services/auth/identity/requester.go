@@ -49,6 +49,9 @@ type Requester interface {
 	GetAuthenticatedBy() string
 	// IsAuthenticatedBy returns true if entity was authenticated by any of supplied providers.
 	IsAuthenticatedBy(providers ...string) bool
+	// HasRestrictedPermissions returns true if the permissions of the entity are restricted by its credentials,
+	// such as a service account token with permissions. Only the permissions of the entity grant access then.
+	HasRestrictedPermissions() bool
 	// IsNil returns true if the identity is nil
 	// FIXME: remove this method once all services are using an interface
 	IsNil() bool
@@ -28,6 +28,7 @@ const (
 	ClientForm        = "auth.client.form"
 	ClientProxy       = "auth.client.proxy"
 	ClientSAML        = "auth.client.saml"
+	ClientMFA         = "auth.client.mfa"
 )
 
 const (
@@ -68,6 +69,9 @@ type FetchPermissionsParams struct {
 	ActionsLookup []string
 	// Roles permissions will be directly added to the identity permissions
 	Roles []string
+	// RestrictedPermissions will restrict the permissions to the scopes, grouped by action, they cover or are covered by.
+	// An action without scopes keeps all its scopes. No restriction is applied when nil.
+	RestrictedPermissions map[string][]string
 }
 
 type PostAuthHookFn func(ctx context.Context, identity *Identity, r *Request) error
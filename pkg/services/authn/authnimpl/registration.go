@@ -14,10 +14,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/team"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -35,11 +37,12 @@ func ProvideRegistration(
 	features *featuremgmt.FeatureManager, oauthTokenService oauthtoken.OAuthTokenService,
 	socialService social.Service, cache *remotecache.RemoteCache,
 	ldapService service.LDAP, settingsProviderService setting.Provider,
+	mfaService mfa.Service, teamService team.Service, teamPermissionsService accesscontrol.TeamPermissionsService,
 ) Registration {
 	logger := log.New("authn.registration")
 
 	authnSvc.RegisterClient(clients.ProvideRender(renderService))
-	authnSvc.RegisterClient(clients.ProvideAPIKey(apikeyService))
+	authnSvc.RegisterClient(clients.ProvideAPIKey(cfg, apikeyService))
 
 	if cfg.LoginCookieName != "" {
 		authnSvc.RegisterClient(clients.ProvideSession(cfg, sessionService, authInfoService))
@@ -61,13 +64,14 @@ func ProvideRegistration(
 
 	// if we have password clients configure check if basic auth or form auth is enabled
 	if len(passwordClients) > 0 {
-		passwordClient := clients.ProvidePassword(loginAttempts, passwordClients...)
+		passwordClient := clients.ProvidePassword(cfg, loginAttempts, passwordClients...)
 		if cfg.BasicAuthEnabled {
-			authnSvc.RegisterClient(clients.ProvideBasic(passwordClient))
+			authnSvc.RegisterClient(clients.ProvideBasic(passwordClient, mfaService))
 		}
 
 		if !cfg.DisableLoginForm {
-			authnSvc.RegisterClient(clients.ProvideForm(passwordClient))
+			authnSvc.RegisterClient(clients.ProvideForm(passwordClient, mfaService))
+			authnSvc.RegisterClient(clients.ProvideMFA(cfg, mfaService))
 		}
 	}
 
@@ -101,6 +105,8 @@ func ProvideRegistration(
 	authnSvc.RegisterPostAuthHook(orgSync.SyncOrgRolesHook, 30)
 	authnSvc.RegisterPostAuthHook(userSync.SyncLastSeenHook, 130)
 	authnSvc.RegisterPostAuthHook(sync.ProvideOAuthTokenSync(oauthTokenService, sessionService, socialService).SyncOauthTokenHook, 60)
+	teamSync := sync.ProvideTeamSync(teamService, teamPermissionsService, orgService, socialService, oauthTokenService)
+	authnSvc.RegisterPostAuthHook(teamSync.SyncTeamsHook, 70)
 	authnSvc.RegisterPostAuthHook(userSync.FetchSyncedUserHook, 100)
 
 	rbacSync := sync.ProvideRBACSync(accessControlService)
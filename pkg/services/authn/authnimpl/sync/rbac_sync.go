@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"errors"
+	"slices"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
@@ -57,10 +58,52 @@ func (s *RBACSync) SyncPermissionsHook(ctx context.Context, ident *authn.Identit
 		grouped = filtered
 	}
 
+	// Restrict access to the permissions allowed to the credentials, such as a service account token
+	if restricted := ident.ClientParams.FetchPermissionsParams.RestrictedPermissions; restricted != nil {
+		grouped = restrictPermissions(grouped, restricted)
+	}
+
 	ident.Permissions[ident.OrgID] = grouped
 	return nil
 }
 
+// restrictPermissions returns the intersection of permissions and restricted permissions, both grouped by action.
+// For each action, the narrowest of two matching scopes is kept.
+func restrictPermissions(permissions, restricted map[string][]string) map[string][]string {
+	result := make(map[string][]string, len(restricted))
+	for action, restrictedScopes := range restricted {
+		scopes, ok := permissions[action]
+		if !ok {
+			continue
+		}
+		if len(restrictedScopes) == 0 {
+			result[action] = scopes
+			continue
+		}
+
+		kept := make([]string, 0, len(restrictedScopes))
+		for _, scope := range scopes {
+			// unscoped actions can't be narrowed
+			if scope == "" {
+				kept = append(kept, scope)
+				continue
+			}
+			for _, restrictedScope := range restrictedScopes {
+				if accesscontrol.EvalPermission(action, restrictedScope).Evaluate(map[string][]string{action: {scope}}) {
+					kept = append(kept, restrictedScope)
+				} else if accesscontrol.EvalPermission(action, scope).Evaluate(map[string][]string{action: {restrictedScope}}) {
+					kept = append(kept, scope)
+				}
+			}
+		}
+		if len(kept) > 0 {
+			slices.Sort(kept)
+			result[action] = slices.Compact(kept)
+		}
+	}
+	return result
+}
+
 func (s *RBACSync) fetchPermissions(ctx context.Context, ident *authn.Identity) ([]accesscontrol.Permission, error) {
 	permissions := make([]accesscontrol.Permission, 0, 8)
 	roles := ident.ClientParams.FetchPermissionsParams.Roles
@@ -50,6 +50,35 @@ func TestRBACSync_SyncPermission(t *testing.T) {
 	}
 }
 
+func TestRestrictPermissions(t *testing.T) {
+	permissions := map[string][]string{
+		"dashboards:read":   {"dashboards:*", "folders:*"},
+		"dashboards:write":  {"folders:uid:ci", "folders:uid:prod"},
+		"folders:read":      {"folders:uid:ci"},
+		"users:read":        {""},
+		"datasources:query": {"datasources:*"},
+	}
+
+	restricted := restrictPermissions(permissions, map[string][]string{
+		// narrower than the permission
+		"dashboards:read": {"folders:uid:ci"},
+		// wider than the permission
+		"dashboards:write": {"folders:*"},
+		// disjoint from the permission
+		"folders:read": {"folders:uid:prod"},
+		// all the scopes of the permission
+		"users:read": nil,
+		// not a permission of the identity
+		"teams:read": nil,
+	})
+
+	assert.Equal(t, map[string][]string{
+		"dashboards:read":  {"folders:uid:ci"},
+		"dashboards:write": {"folders:uid:ci", "folders:uid:prod"},
+		"users:read":       {""},
+	}, restricted)
+}
+
 func TestRBACSync_SyncCloudRoles(t *testing.T) {
 	type testCase struct {
 		desc           string
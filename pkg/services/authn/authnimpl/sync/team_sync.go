@@ -0,0 +1,222 @@
+package sync
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/login/social"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/oauthtoken"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// syncedTokenTTL is how long the access token teams were last synced with is remembered
+const syncedTokenTTL = 24 * time.Hour
+
+func ProvideTeamSync(
+	teamService team.Service, teamPermissions accesscontrol.TeamPermissionsService, orgService org.Service,
+	socialService social.Service, oauthTokenService oauthtoken.OAuthTokenService,
+) *TeamSync {
+	return &TeamSync{
+		teamService:       teamService,
+		teamPermissions:   teamPermissions,
+		orgService:        orgService,
+		socialService:     socialService,
+		oauthTokenService: oauthTokenService,
+		syncedTokens:      localcache.New(syncedTokenTTL, time.Hour),
+		log:               log.New("team.sync"),
+	}
+}
+
+// TeamSync keeps the team memberships of users in line with the groups provided by the generic OAuth identity provider.
+// Memberships are synced on login and again whenever the access token of the session is refreshed.
+type TeamSync struct {
+	teamService       team.Service
+	teamPermissions   accesscontrol.TeamPermissionsService
+	orgService        org.Service
+	socialService     social.Service
+	oauthTokenService oauthtoken.OAuthTokenService
+	// syncedTokens holds the access token teams were last synced with, by user
+	syncedTokens *localcache.CacheService
+
+	log log.Logger
+}
+
+func (s *TeamSync) SyncTeamsHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
+	if !id.ID.IsNamespace(authn.NamespaceUser) {
+		return nil
+	}
+
+	userID, err := id.ID.ParseInt()
+	if err != nil {
+		return nil
+	}
+
+	ctxLogger := s.log.FromContext(ctx).New("id", id.ID, "login", id.Login)
+
+	if id.ClientParams.SyncTeams {
+		if id.ExternalTeams == nil {
+			return nil
+		}
+		if err := s.syncTeams(ctx, userID, id.ExternalTeams); err != nil {
+			ctxLogger.Error("Failed to sync teams", "error", err)
+			return err
+		}
+		if id.OAuthToken != nil {
+			s.syncedTokens.Set(syncedTokenKey(userID), id.OAuthToken.AccessToken, syncedTokenTTL)
+		}
+		return nil
+	}
+
+	// the groups can only change on token refresh for sessions of generic OAuth users
+	if id.SessionToken == nil || id.GetAuthenticatedBy() != login.GenericOAuthModule {
+		return nil
+	}
+
+	info := s.socialService.GetOAuthInfoProvider(social.GenericOAuthProviderName)
+	if info == nil || info.Extra[social.TeamSyncMappingKey] == "" {
+		return nil
+	}
+
+	token := s.oauthTokenService.GetCurrentOAuthToken(ctx, id)
+	if token == nil {
+		return nil
+	}
+	if synced, ok := s.syncedTokens.Get(syncedTokenKey(userID)); ok && synced == token.AccessToken {
+		return nil
+	}
+
+	teams, err := s.fetchTeams(ctx, token)
+	if err != nil {
+		// the memberships are kept until the groups can be fetched again
+		ctxLogger.Warn("Failed to fetch groups to sync teams", "error", err)
+		return nil
+	}
+	if teams != nil {
+		if err := s.syncTeams(ctx, userID, teams); err != nil {
+			ctxLogger.Error("Failed to sync teams", "error", err)
+			return nil
+		}
+	}
+	s.syncedTokens.Set(syncedTokenKey(userID), token.AccessToken, syncedTokenTTL)
+	return nil
+}
+
+// fetchTeams returns the teams the user is mapped to with the groups returned for the token
+func (s *TeamSync) fetchTeams(ctx context.Context, token *oauth2.Token) (map[int64][]string, error) {
+	connector, err := s.socialService.GetConnector(social.GenericOAuthProviderName)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := s.socialService.GetOAuthHttpClient(social.GenericOAuthProviderName)
+	if err != nil {
+		return nil, err
+	}
+
+	clientCtx := context.WithValue(ctx, oauth2.HTTPClient, httpClient)
+	userInfo, err := connector.UserInfo(ctx, connector.Client(clientCtx, token), token)
+	if err != nil {
+		return nil, err
+	}
+	return userInfo.Teams, nil
+}
+
+// syncTeams adds the user to the teams they are mapped to and removes the synced memberships that no longer match.
+// Memberships that weren't created by the sync are left untouched.
+func (s *TeamSync) syncTeams(ctx context.Context, userID int64, teams map[int64][]string) error {
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return err
+	}
+	memberOf := make(map[int64]bool, len(orgs))
+	for _, o := range orgs {
+		memberOf[o.OrgID] = true
+	}
+
+	for orgID, names := range teams {
+		wanted := map[int64]bool{}
+		// users can only be members of the teams of their organizations
+		if memberOf[orgID] {
+			if wanted, err = s.teamIDs(ctx, orgID, names); err != nil {
+				return err
+			}
+		}
+
+		current, err := s.teamService.GetUserTeamMemberships(ctx, orgID, userID, true)
+		if err != nil {
+			return err
+		}
+
+		for _, membership := range current {
+			if wanted[membership.TeamID] {
+				delete(wanted, membership.TeamID)
+				continue
+			}
+			if err := s.setMembership(ctx, orgID, userID, membership.TeamID, ""); err != nil {
+				return err
+			}
+		}
+
+		for teamID := range wanted {
+			// don't take over the memberships that were added manually
+			isMember, err := s.teamService.IsTeamMember(ctx, orgID, teamID, userID)
+			if err != nil {
+				return err
+			}
+			if isMember {
+				continue
+			}
+			if err := s.setMembership(ctx, orgID, userID, teamID, team.MemberPermissionName); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// teamIDs resolves the names of teams of an organization, the teams that don't exist are skipped
+func (s *TeamSync) teamIDs(ctx context.Context, orgID int64, names []string) (map[int64]bool, error) {
+	// teams are looked up on behalf of the sync, not of the user being synced
+	searcher := &user.SignedInUser{
+		OrgID:       orgID,
+		Permissions: map[int64]map[string][]string{orgID: {accesscontrol.ActionTeamsRead: {accesscontrol.ScopeTeamsAll}}},
+	}
+
+	ids := make(map[int64]bool, len(names))
+	for _, name := range names {
+		result, err := s.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+			OrgID:        orgID,
+			Name:         name,
+			SignedInUser: searcher,
+			HiddenUsers:  map[string]struct{}{},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Teams) == 0 {
+			s.log.FromContext(ctx).Debug("Skipping team sync to a team that doesn't exist", "orgId", orgID, "team", name)
+			continue
+		}
+		ids[result.Teams[0].ID] = true
+	}
+	return ids, nil
+}
+
+func (s *TeamSync) setMembership(ctx context.Context, orgID, userID, teamID int64, permission string) error {
+	_, err := s.teamPermissions.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID, IsExternal: true},
+		strconv.FormatInt(teamID, 10), permission)
+	return err
+}
+
+func syncedTokenKey(userID int64) string {
+	return "team-sync-" + strconv.FormatInt(userID, 10)
+}
@@ -0,0 +1,117 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/grafana/grafana/pkg/login/social/socialtest"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/oauthtoken/oauthtokentest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+)
+
+type fakeTeamService struct {
+	teamtest.FakeService
+	teamsByName map[string]int64
+}
+
+func (f *fakeTeamService) SearchTeams(ctx context.Context, query *team.SearchTeamsQuery) (team.SearchTeamQueryResult, error) {
+	result := team.SearchTeamQueryResult{Teams: []*team.TeamDTO{}}
+	if id, ok := f.teamsByName[query.Name]; ok {
+		result.Teams = append(result.Teams, &team.TeamDTO{ID: id, OrgID: query.OrgID, Name: query.Name})
+	}
+	return result, nil
+}
+
+type membershipChange struct {
+	teamID     string
+	permission string
+}
+
+type recordingTeamPermissions struct {
+	actest.FakePermissionsService
+	changes []membershipChange
+}
+
+func (r *recordingTeamPermissions) SetUserPermission(ctx context.Context, orgID int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	if !user.IsExternal {
+		return nil, team.ErrTeamMemberExternallyManaged.Errorf("not synced")
+	}
+	r.changes = append(r.changes, membershipChange{teamID: resourceID, permission: permission})
+	return nil, nil
+}
+
+func TestTeamSync_SyncTeamsHook(t *testing.T) {
+	setup := func(members []*team.TeamMemberDTO, isMember bool) (*TeamSync, *recordingTeamPermissions) {
+		teamService := &fakeTeamService{
+			FakeService: teamtest.FakeService{ExpectedMembers: members, ExpectedIsMember: isMember},
+			teamsByName: map[string]int64{"backend": 1, "frontend": 2},
+		}
+		permissions := &recordingTeamPermissions{}
+		orgService := &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1, Role: org.RoleViewer}}}
+		s := ProvideTeamSync(teamService, permissions, orgService, &socialtest.FakeSocialService{}, &oauthtokentest.MockService{})
+		return s, permissions
+	}
+
+	login := func(teams map[int64][]string) *authn.Identity {
+		return &authn.Identity{
+			ID:            authn.NewNamespaceID(authn.NamespaceUser, 1),
+			ExternalTeams: teams,
+			OAuthToken:    &oauth2.Token{AccessToken: "token"},
+			ClientParams:  authn.ClientParams{SyncTeams: true},
+		}
+	}
+
+	t.Run("should add the user to the mapped teams", func(t *testing.T) {
+		s, permissions := setup(nil, false)
+
+		err := s.SyncTeamsHook(context.Background(), login(map[int64][]string{1: {"backend", "unknown"}}), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []membershipChange{{teamID: "1", permission: team.MemberPermissionName}}, permissions.changes)
+
+		synced, ok := s.syncedTokens.Get(syncedTokenKey(1))
+		require.True(t, ok)
+		assert.Equal(t, "token", synced)
+	})
+
+	t.Run("should remove the synced memberships that no longer match", func(t *testing.T) {
+		s, permissions := setup([]*team.TeamMemberDTO{{TeamID: 1, External: true}, {TeamID: 2, External: true}}, true)
+
+		err := s.SyncTeamsHook(context.Background(), login(map[int64][]string{1: {"frontend"}}), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []membershipChange{{teamID: "1", permission: ""}}, permissions.changes)
+	})
+
+	t.Run("should not take over memberships that were added manually", func(t *testing.T) {
+		s, permissions := setup(nil, true)
+
+		err := s.SyncTeamsHook(context.Background(), login(map[int64][]string{1: {"backend"}}), nil)
+		require.NoError(t, err)
+		assert.Empty(t, permissions.changes)
+	})
+
+	t.Run("should remove the synced memberships of organizations the user left", func(t *testing.T) {
+		s, permissions := setup([]*team.TeamMemberDTO{{TeamID: 1, External: true}}, false)
+
+		err := s.SyncTeamsHook(context.Background(), login(map[int64][]string{2: {"backend"}}), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []membershipChange{{teamID: "1", permission: ""}}, permissions.changes)
+	})
+
+	t.Run("should not sync teams when team sync isn't configured", func(t *testing.T) {
+		s, permissions := setup([]*team.TeamMemberDTO{{TeamID: 1, External: true}}, false)
+
+		err := s.SyncTeamsHook(context.Background(), login(nil), nil)
+		require.NoError(t, err)
+		assert.Empty(t, permissions.changes)
+	})
+}
@@ -3,6 +3,7 @@ package clients
 import (
 	"context"
 	"errors"
+	"net"
 	"strings"
 	"time"
 
@@ -13,8 +14,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
 )
 
 var (
@@ -22,20 +25,23 @@ var (
 	errAPIKeyExpired     = errutil.Unauthorized("api-key.expired", errutil.WithPublicMessage("Expired API key"))
 	errAPIKeyRevoked     = errutil.Unauthorized("api-key.revoked", errutil.WithPublicMessage("Revoked API key"))
 	errAPIKeyOrgMismatch = errutil.Unauthorized("api-key.organization-mismatch", errutil.WithPublicMessage("API key does not belong to the requested organization"))
+	errAPIKeyNetwork     = errutil.Unauthorized("api-key.network-not-allowed", errutil.WithPublicMessage("API key cannot be used from this network"))
 )
 
 var _ authn.HookClient = new(APIKey)
 var _ authn.ContextAwareClient = new(APIKey)
 var _ authn.IdentityResolverClient = new(APIKey)
 
-func ProvideAPIKey(apiKeyService apikey.Service) *APIKey {
+func ProvideAPIKey(cfg *setting.Cfg, apiKeyService apikey.Service) *APIKey {
 	return &APIKey{
+		cfg:           cfg,
 		log:           log.New(authn.ClientAPIKey),
 		apiKeyService: apiKeyService,
 	}
 }
 
 type APIKey struct {
+	cfg           *setting.Cfg
 	log           log.Logger
 	apiKeyService apikey.Service
 }
@@ -61,6 +67,11 @@ func (s *APIKey) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ide
 		return nil, err
 	}
 
+	// the allowed networks can't be bypassed with forwarded headers unless they come from a trusted proxy
+	if err := validateApiKeyNetwork(key, web.ClientIP(r.HTTPRequest, s.cfg.TrustedProxies)); err != nil {
+		return nil, err
+	}
+
 	// if the api key don't belong to a service account construct the identity and return it
 	if key.ServiceAccountId == nil || *key.ServiceAccountId < 1 {
 		return newAPIKeyIdentity(key), nil
@@ -173,13 +184,18 @@ func (s *APIKey) Hook(ctx context.Context, identity *authn.Identity, r *authn.Re
 		return nil
 	}
 
+	var ip string
+	if r.HTTPRequest != nil {
+		ip = web.ClientIP(r.HTTPRequest, s.cfg.TrustedProxies)
+	}
+
 	go func(apikeyID int64) {
 		defer func() {
 			if err := recover(); err != nil {
 				s.log.Error("Panic during user last seen sync", "err", err)
 			}
 		}()
-		if err := s.apiKeyService.UpdateAPIKeyLastUsedDate(context.Background(), apikeyID); err != nil {
+		if err := s.apiKeyService.UpdateAPIKeyLastUsed(context.Background(), apikeyID, ip); err != nil {
 			s.log.Warn("Failed to update last use date for api key", "id", apikeyID)
 		}
 	}(id)
@@ -253,6 +269,30 @@ func validateApiKey(orgID int64, key *apikey.APIKey) error {
 	return nil
 }
 
+// validateApiKeyNetwork checks the key is used from one of its allowed networks
+func validateApiKeyNetwork(key *apikey.APIKey, addr string) error {
+	if len(key.AllowedCIDRs) == 0 {
+		return nil
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return errAPIKeyNetwork.Errorf("API key used from unknown address %q", addr)
+	}
+
+	for _, cidr := range key.AllowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return nil
+		}
+	}
+
+	return errAPIKeyNetwork.Errorf("API key used from %s which is not an allowed network", addr)
+}
+
 func newAPIKeyIdentity(key *apikey.APIKey) *authn.Identity {
 	return &authn.Identity{
 		ID:              authn.NewNamespaceID(authn.NamespaceAPIKey, key.ID),
@@ -268,6 +308,30 @@ func newServiceAccountIdentity(key *apikey.APIKey) *authn.Identity {
 		ID:              authn.NewNamespaceID(authn.NamespaceServiceAccount, *key.ServiceAccountId),
 		OrgID:           key.OrgID,
 		AuthenticatedBy: login.APIKeyAuthModule,
-		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		ClientParams: authn.ClientParams{
+			FetchSyncedUser:        true,
+			SyncPermissions:        true,
+			FetchPermissionsParams: authn.FetchPermissionsParams{RestrictedPermissions: groupTokenPermissions(key.Permissions)},
+		},
+	}
+}
+
+// groupTokenPermissions groups the permissions of a token by action, an empty scope allows all the scopes of the action
+func groupTokenPermissions(permissions []apikey.Permission) map[string][]string {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	grouped := make(map[string][]string, len(permissions))
+	unscoped := make(map[string]bool)
+	for _, p := range permissions {
+		if p.Scope == "" {
+			unscoped[p.Action] = true
+		}
+		grouped[p.Action] = append(grouped[p.Action], p.Scope)
+	}
+	for action := range unscoped {
+		grouped[action] = nil
 	}
+	return grouped
 }
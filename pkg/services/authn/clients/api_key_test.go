@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 var (
@@ -108,11 +109,99 @@ func TestAPIKey_Authenticate(t *testing.T) {
 			},
 			expectedErr: errAPIKeyOrgMismatch,
 		},
+		{
+			desc: "should restrict the permissions of a service account token",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				RemoteAddr: "10.1.2.3:5678",
+				Header:     map[string][]string{"Authorization": {"Bearer " + secret}},
+			}},
+			expectedKey: &apikey.APIKey{
+				ID:               1,
+				OrgID:            1,
+				Key:              hash,
+				ServiceAccountId: intPtr(1),
+				AllowedCIDRs:     []string{"192.168.0.0/16", "10.0.0.0/8"},
+				Permissions: []apikey.Permission{
+					{Action: "dashboards:write", Scope: "folders:uid:ci"},
+					{Action: "dashboards:read", Scope: "folders:uid:ci"},
+					{Action: "dashboards:read"},
+				},
+			},
+			expectedIdentity: &authn.Identity{
+				ID:    authn.MustParseNamespaceID("service-account:1"),
+				OrgID: 1,
+				ClientParams: authn.ClientParams{
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+					FetchPermissionsParams: authn.FetchPermissionsParams{RestrictedPermissions: map[string][]string{
+						"dashboards:write": {"folders:uid:ci"},
+						"dashboards:read":  nil,
+					}},
+				},
+				AuthenticatedBy: login.APIKeyAuthModule,
+			},
+		},
+		{
+			desc: "should fail for service account token used from another network",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				RemoteAddr: "172.16.0.1:5678",
+				Header:     map[string][]string{"Authorization": {"Bearer " + secret}},
+			}},
+			expectedKey: &apikey.APIKey{
+				ID:               1,
+				OrgID:            1,
+				Key:              hash,
+				ServiceAccountId: intPtr(1),
+				AllowedCIDRs:     []string{"10.0.0.0/8"},
+			},
+			expectedErr: errAPIKeyNetwork,
+		},
+		{
+			desc: "should ignore forwarded headers from untrusted clients when checking the network",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				RemoteAddr: "172.16.0.1:5678",
+				Header: map[string][]string{
+					"Authorization":   {"Bearer " + secret},
+					"X-Forwarded-For": {"10.1.2.3"},
+				},
+			}},
+			expectedKey: &apikey.APIKey{
+				ID:               1,
+				OrgID:            1,
+				Key:              hash,
+				ServiceAccountId: intPtr(1),
+				AllowedCIDRs:     []string{"10.0.0.0/8"},
+			},
+			expectedErr: errAPIKeyNetwork,
+		},
+		{
+			desc: "should allow service account token used from an allowed IPv6 network",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				RemoteAddr: "[2001:db8::1]:5678",
+				Header:     map[string][]string{"Authorization": {"Bearer " + secret}},
+			}},
+			expectedKey: &apikey.APIKey{
+				ID:               1,
+				OrgID:            1,
+				Key:              hash,
+				ServiceAccountId: intPtr(1),
+				AllowedCIDRs:     []string{"2001:db8::/32"},
+			},
+			expectedIdentity: &authn.Identity{
+				ID:    authn.MustParseNamespaceID("service-account:1"),
+				OrgID: 1,
+				ClientParams: authn.ClientParams{
+					FetchSyncedUser: true,
+					SyncPermissions: true,
+				},
+				AuthenticatedBy: login.APIKeyAuthModule,
+			},
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{ExpectedAPIKey: tt.expectedKey})
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{ExpectedAPIKey: tt.expectedKey})
 
 			identity, err := c.Authenticate(context.Background(), tt.req)
 			if tt.expectedErr != nil {
@@ -179,7 +268,7 @@ func TestAPIKey_Test(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{})
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{})
 			assert.Equal(t, tt.expected, c.Test(context.Background(), tt.req))
 		})
 	}
@@ -272,7 +361,7 @@ func TestAPIKey_GetAPIKeyIDFromIdentity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{
 				ExpectedError:  tt.expectedError,
 				ExpectedAPIKey: tt.expectedKey,
 			})
@@ -360,7 +449,7 @@ func TestAPIKey_ResolveIdentity(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideAPIKey(&apikeytest.Service{
+			c := ProvideAPIKey(setting.NewCfg(), &apikeytest.Service{
 				ExpectedAPIKey: tt.exptedApiKey,
 			})
 
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
@@ -13,12 +15,13 @@ var (
 
 var _ authn.ContextAwareClient = new(Basic)
 
-func ProvideBasic(client authn.PasswordClient) *Basic {
-	return &Basic{client}
+func ProvideBasic(client authn.PasswordClient, mfaService mfa.Service) *Basic {
+	return &Basic{client, mfaService}
 }
 
 type Basic struct {
-	client authn.PasswordClient
+	client     authn.PasswordClient
+	mfaService mfa.Service
 }
 
 func (c *Basic) String() string {
@@ -35,7 +38,26 @@ func (c *Basic) Authenticate(ctx context.Context, r *authn.Request) (*authn.Iden
 		return nil, errDecodingBasicAuthHeader.Errorf("failed to decode basic auth header")
 	}
 
-	return c.client.AuthenticatePassword(ctx, r, username, password)
+	identity, err := c.client.AuthenticatePassword(ctx, r, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	// basic auth has no second step, so a password is not enough for users who need a second factor
+	if identity.AuthenticatedBy == login.PasswordAuthModule {
+		userID, err := identity.ID.ParseInt()
+		if err != nil {
+			return nil, err
+		}
+		required, err := c.mfaService.IsRequired(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, errMFABasicAuth.Errorf("user %d needs a second factor", userID)
+		}
+	}
+	return identity, nil
 }
 
 func (c *Basic) IsEnabled() bool {
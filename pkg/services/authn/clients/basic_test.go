@@ -9,6 +9,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
 )
 
 func TestBasic_Authenticate(t *testing.T) {
@@ -16,6 +18,7 @@ func TestBasic_Authenticate(t *testing.T) {
 		desc             string
 		req              *authn.Request
 		client           authn.PasswordClient
+		mfaRequired      bool
 		expectedErr      error
 		expectedIdentity *authn.Identity
 	}
@@ -32,11 +35,18 @@ func TestBasic_Authenticate(t *testing.T) {
 			req:         &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{authorizationHeaderName: {}}}},
 			expectedErr: errDecodingBasicAuthHeader,
 		},
+		{
+			desc:        "should fail when user needs a second factor",
+			req:         &authn.Request{HTTPRequest: &http.Request{Header: map[string][]string{authorizationHeaderName: {encodeBasicAuth("user", "password")}}}},
+			client:      authntest.FakePasswordClient{ExpectedIdentity: &authn.Identity{ID: authn.MustParseNamespaceID("user:1"), AuthenticatedBy: login.PasswordAuthModule}},
+			mfaRequired: true,
+			expectedErr: errMFABasicAuth,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideBasic(tt.client)
+			c := ProvideBasic(tt.client, &mfatest.FakeService{ExpectedRequired: tt.mfaRequired})
 
 			identity, err := c.Authenticate(context.Background(), tt.req)
 			if tt.expectedErr != nil {
@@ -89,7 +99,7 @@ func TestBasic_Test(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideBasic(authntest.FakePasswordClient{})
+			c := ProvideBasic(authntest.FakePasswordClient{}, &mfatest.FakeService{})
 			assert.Equal(t, tt.expected, c.Test(context.Background(), tt.req))
 		})
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -14,12 +15,13 @@ var (
 
 var _ authn.Client = new(Form)
 
-func ProvideForm(client authn.PasswordClient) *Form {
-	return &Form{client}
+func ProvideForm(client authn.PasswordClient, mfaService mfa.Service) *Form {
+	return &Form{client, mfaService}
 }
 
 type Form struct {
-	client authn.PasswordClient
+	client     authn.PasswordClient
+	mfaService mfa.Service
 }
 
 type loginForm struct {
@@ -36,7 +38,15 @@ func (c *Form) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ident
 	if err := web.Bind(r.HTTPRequest, &form); err != nil {
 		return nil, errBadForm.Errorf("failed to parse request: %w", err)
 	}
-	return c.client.AuthenticatePassword(ctx, r, form.Username, form.Password)
+
+	identity, err := c.client.AuthenticatePassword(ctx, r, form.Username, form.Password)
+	if err != nil {
+		return nil, err
+	}
+	if err := challengePasswordLogin(ctx, c.mfaService, identity, form.Username); err != nil {
+		return nil, err
+	}
+	return identity, nil
 }
 
 func (c *Form) IsEnabled() bool {
@@ -2,21 +2,28 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 func TestForm_Authenticate(t *testing.T) {
 	type testCase struct {
 		desc        string
 		req         *authn.Request
+		challenge   *mfa.Challenge
 		expectedErr error
 	}
 
@@ -36,13 +43,53 @@ func TestForm_Authenticate(t *testing.T) {
 			}},
 			expectedErr: errBadForm,
 		},
+		{
+			desc: "should return error when a second factor is required",
+			req: &authn.Request{HTTPRequest: &http.Request{
+				Header: map[string][]string{"Content-Type": {"application/json"}},
+				Body:   io.NopCloser(strings.NewReader(`{"user": "test", "password": "test"}`)),
+			}},
+			challenge:   &mfa.Challenge{Token: "token"},
+			expectedErr: errMFARequired,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideForm(&authntest.FakePasswordClient{})
+			c := ProvideForm(
+				&authntest.FakePasswordClient{ExpectedIdentity: &authn.Identity{ID: authn.MustParseNamespaceID("user:1"), AuthenticatedBy: login.PasswordAuthModule}},
+				&mfatest.FakeService{ExpectedChallenge: tt.challenge},
+			)
 			_, err := c.Authenticate(context.Background(), tt.req)
 			assert.ErrorIs(t, err, tt.expectedErr)
 		})
 	}
 }
+
+func TestForm_AuthenticateChallengePayload(t *testing.T) {
+	c := ProvideForm(
+		&authntest.FakePasswordClient{ExpectedIdentity: &authn.Identity{ID: authn.MustParseNamespaceID("user:1"), AuthenticatedBy: login.PasswordAuthModule}},
+		&mfatest.FakeService{ExpectedChallenge: &mfa.Challenge{Token: "token", EnrollmentRequired: true}},
+	)
+	_, err := c.Authenticate(context.Background(), &authn.Request{HTTPRequest: &http.Request{
+		Header: map[string][]string{"Content-Type": {"application/json"}},
+		Body:   io.NopCloser(strings.NewReader(`{"user": "test", "password": "test"}`)),
+	}})
+
+	var errutilErr errutil.Error
+	require.True(t, errors.As(err, &errutilErr))
+	assert.Equal(t, map[string]any{"challenge": "token", "enrollmentRequired": true}, errutilErr.PublicPayload)
+}
+
+func TestForm_AuthenticateSkipsChallengeForOtherModules(t *testing.T) {
+	c := ProvideForm(
+		&authntest.FakePasswordClient{ExpectedIdentity: &authn.Identity{ID: authn.MustParseNamespaceID("user:1"), AuthenticatedBy: login.LDAPAuthModule}},
+		&mfatest.FakeService{ExpectedChallenge: &mfa.Challenge{Token: "token"}},
+	)
+	identity, err := c.Authenticate(context.Background(), &authn.Request{HTTPRequest: &http.Request{
+		Header: map[string][]string{"Content-Type": {"application/json"}},
+		Body:   io.NopCloser(strings.NewReader(`{"user": "test", "password": "test"}`)),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, login.LDAPAuthModule, identity.AuthenticatedBy)
+}
@@ -0,0 +1,93 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var (
+	errBadMFAForm  = errutil.BadRequest("mfa-auth.invalid", errutil.WithPublicMessage("bad two-factor authentication data"))
+	errMFARequired = errutil.Unauthorized("mfa-auth.required").MustTemplate(
+		"second factor required",
+		errutil.WithPublic("Two-factor authentication required"),
+	)
+	errMFABasicAuth = errutil.Unauthorized("mfa-auth.basic-auth", errutil.WithPublicMessage("Basic authentication is not available to users with two-factor authentication"))
+)
+
+var _ authn.Client = new(MFA)
+
+func ProvideMFA(cfg *setting.Cfg, service mfa.Service) *MFA {
+	return &MFA{cfg, service}
+}
+
+// MFA completes a password login that was interrupted by a second factor challenge, see Form.
+type MFA struct {
+	cfg     *setting.Cfg
+	service mfa.Service
+}
+
+type mfaForm struct {
+	Challenge string `json:"challenge" binding:"Required"`
+	Code      string `json:"code" binding:"Required"`
+}
+
+func (c *MFA) Name() string {
+	return authn.ClientMFA
+}
+
+func (c *MFA) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	form := mfaForm{}
+	if err := web.Bind(r.HTTPRequest, &form); err != nil {
+		return nil, errBadMFAForm.Errorf("failed to parse request: %w", err)
+	}
+
+	userID, err := c.service.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{
+		Token:     form.Challenge,
+		Code:      form.Code,
+		IPAddress: web.ClientIP(r.HTTPRequest, c.cfg.TrustedProxies),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r.SetMeta(authn.MetaKeyAuthModule, "grafana")
+	return &authn.Identity{
+		ID:              authn.NewNamespaceID(authn.NamespaceUser, userID),
+		OrgID:           r.OrgID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: login.PasswordAuthModule,
+	}, nil
+}
+
+func (c *MFA) IsEnabled() bool {
+	return true
+}
+
+// challengePasswordLogin interrupts the login of a user who signed in with a Grafana password and needs a second
+// factor. The returned error carries the challenge the client must answer through the MFA client.
+func challengePasswordLogin(ctx context.Context, service mfa.Service, identity *authn.Identity, username string) error {
+	if identity.AuthenticatedBy != login.PasswordAuthModule {
+		return nil
+	}
+	userID, err := identity.ID.ParseInt()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := service.Challenge(ctx, &mfa.ChallengeQuery{UserID: userID, Login: username})
+	if err != nil || challenge == nil {
+		return err
+	}
+	return errMFARequired.Build(errutil.TemplateData{
+		Public: map[string]any{
+			"challenge":          challenge.Token,
+			"enrollmentRequired": challenge.EnrollmentRequired,
+		},
+	})
+}
@@ -184,6 +184,7 @@ func (c *OAuth) Authenticate(ctx context.Context, r *authn.Request) (*authn.Iden
 		AuthenticatedBy: c.moduleName,
 		AuthID:          userInfo.Id,
 		Groups:          userInfo.Groups,
+		ExternalTeams:   userInfo.Teams,
 		OAuthToken:      token,
 		OrgRoles:        orgRoles,
 		ClientParams: authn.ClientParams{
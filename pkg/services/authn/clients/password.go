@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -18,11 +19,12 @@ var (
 
 var _ authn.PasswordClient = new(Password)
 
-func ProvidePassword(loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
-	return &Password{loginAttempts, clients, log.New("authn.password")}
+func ProvidePassword(cfg *setting.Cfg, loginAttempts loginattempt.Service, clients ...authn.PasswordClient) *Password {
+	return &Password{cfg, loginAttempts, clients, log.New("authn.password")}
 }
 
 type Password struct {
+	cfg           *setting.Cfg
 	loginAttempts loginattempt.Service
 	clients       []authn.PasswordClient
 	log           log.Logger
@@ -39,6 +41,19 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 		return nil, errPasswordAuthFailed.Errorf("too many consecutive incorrect login attempts for user - login for user temporarily blocked")
 	}
 
+	var remoteAddr string
+	if r.HTTPRequest != nil {
+		// the address the attempts are throttled by can't be spoofed with forwarded headers
+		remoteAddr = web.ClientIP(r.HTTPRequest, c.cfg.TrustedProxies)
+	}
+	ok, err = c.loginAttempts.ValidateIPAddress(ctx, remoteAddr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errPasswordAuthFailed.Errorf("too many incorrect login attempts from %s - login temporarily blocked", remoteAddr)
+	}
+
 	if len(password) == 0 {
 		return nil, errPasswordAuthFailed.Errorf("no password provided")
 	}
@@ -57,7 +72,7 @@ func (c *Password) AuthenticatePassword(ctx context.Context, r *authn.Request, u
 	}
 
 	if errors.Is(clientErrs, errInvalidPassword) {
-		_ = c.loginAttempts.Add(ctx, username, web.RemoteAddr(r.HTTPRequest))
+		_ = c.loginAttempts.Add(ctx, username, remoteAddr)
 	}
 
 	return nil, errPasswordAuthFailed.Errorf("failed to authenticate identity: %w", clientErrs)
@@ -2,13 +2,17 @@ package clients
 
 import (
 	"context"
+	"net"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
 	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestPassword_AuthenticatePassword(t *testing.T) {
@@ -67,7 +71,7 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvidePassword(loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin}, tt.clients...)
+			c := ProvidePassword(setting.NewCfg(), loginattempttest.FakeLoginAttemptService{ExpectedValid: !tt.blockLogin}, tt.clients...)
 
 			identity, err := c.AuthenticatePassword(context.Background(), tt.req, tt.username, tt.password)
 			if tt.expectedErr != nil {
@@ -80,3 +84,31 @@ func TestPassword_AuthenticatePassword(t *testing.T) {
 		})
 	}
 }
+
+func TestPassword_AuthenticatePasswordClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		desc           string
+		trustedProxies []*net.IPNet
+		expectedIP     string
+	}{
+		{desc: "should ignore forwarded headers of untrusted clients", expectedIP: "10.0.0.1"},
+		{desc: "should use forwarded headers of trusted proxies", trustedProxies: []*net.IPNet{proxies}, expectedIP: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			cfg := setting.NewCfg()
+			cfg.TrustedProxies = tt.trustedProxies
+			loginAttempts := &loginattempttest.MockLoginAttemptService{ExpectedValid: true}
+			c := ProvidePassword(cfg, loginAttempts, authntest.FakePasswordClient{ExpectedIdentity: &authn.Identity{}})
+
+			req := &http.Request{RemoteAddr: "10.0.0.1:4321", Header: http.Header{"X-Forwarded-For": {"203.0.113.7"}}}
+			_, err := c.AuthenticatePassword(context.Background(), &authn.Request{HTTPRequest: req}, "test", "test")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedIP, loginAttempts.ValidatedIPAddress)
+		})
+	}
+}
@@ -58,6 +58,9 @@ type Identity struct {
 	// idP Groups that the entity is a member of. This is only populated if the
 	// identity provider supports groups.
 	Groups []string
+	// ExternalTeams are the names of the teams, by org id, the identity provider maps the entity to.
+	// Only populated when team sync is configured for the identity provider.
+	ExternalTeams map[int64][]string
 	// OAuthToken is the OAuth token used to authenticate the entity.
 	OAuthToken *oauth2.Token
 	// SessionToken is the session token used to authenticate the entity.
@@ -92,6 +95,10 @@ func (i *Identity) GetAuthenticatedBy() string {
 	return i.AuthenticatedBy
 }
 
+func (i *Identity) HasRestrictedPermissions() bool {
+	return i.ClientParams.FetchPermissionsParams.RestrictedPermissions != nil
+}
+
 func (i *Identity) GetCacheKey() string {
 	namespace, id := i.GetNamespacedID()
 	if !i.HasUniqueId() {
@@ -222,6 +229,8 @@ func (i *Identity) SignedInUser() *user.SignedInUser {
 		Permissions:     i.Permissions,
 		IDToken:         i.IDToken,
 		NamespacedID:    i.ID,
+
+		RestrictedPermissions: i.HasRestrictedPermissions(),
 	}
 
 	if i.ID.IsNamespace(NamespaceAPIKey) {
@@ -0,0 +1,37 @@
+package caching
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *OSSCachingService) registerAPIEndpoints(router routing.RouteRegister) {
+	authorize := accesscontrol.Middleware(s.accessControl)
+	uidScope := datasources.ScopeProvider.GetResourceScopeUID(accesscontrol.Parameter(":uid"))
+
+	router.Group("/api/datasources/uid/:uid/cache", func(cacheRoute routing.RouteRegister) {
+		cacheRoute.Post("/clean", authorize(accesscontrol.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.cleanDataSourceCache))
+	})
+}
+
+// POST /api/datasources/uid/:uid/cache/clean
+//
+// Removes the cached query results of a data source. It requires the `datasources:write` permission on the data source.
+func (s *OSSCachingService) cleanDataSourceCache(c *contextmodel.ReqContext) response.Response {
+	if !s.cfg.Enabled {
+		return response.Error(http.StatusNotFound, "Query caching is not enabled", nil)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if err := s.PurgeDataSource(c.Req.Context(), c.SignedInUser.GetOrgID(), uid); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to clean the query cache of the data source", err)
+	}
+
+	return response.Success("Query cache of the data source cleaned")
+}
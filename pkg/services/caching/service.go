@@ -2,8 +2,27 @@ package caching
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/contexthandler"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
 )
 
 const (
@@ -35,8 +54,31 @@ type CachedResourceDataResponse struct {
 	UpdateCacheFn CacheResourceResponseFn
 }
 
-func ProvideCachingService() *OSSCachingService {
-	return &OSSCachingService{}
+func ProvideCachingService(cfg *setting.Cfg, cache remotecache.CacheStorage, routeRegister routing.RouteRegister,
+	accessControl accesscontrol.AccessControl, features featuremgmt.FeatureToggles, promRegisterer prometheus.Registerer,
+) *OSSCachingService {
+	s := &OSSCachingService{
+		cfg:            cfg.QueryCaching,
+		sendUserHeader: cfg.SendUserHeader,
+		cache:          cache,
+		accessControl:  accessControl,
+		features:       features,
+		log:            log.New("query-caching"),
+		queryRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metrics.ExporterName,
+			Subsystem: "caching",
+			Name:      "query_cache_requests_total",
+			Help:      "number of query requests handled by the query cache, by data source type and cache status",
+		}, []string{"datasource_type", "cache"}),
+	}
+
+	if err := promRegisterer.Register(s.queryRequests); err != nil {
+		s.log.Error("Error registering prometheus collector 'query_cache_requests_total'", "error", err)
+	}
+
+	s.registerAPIEndpoints(routeRegister)
+
+	return s
 }
 
 type CachingService interface {
@@ -49,16 +91,258 @@ type CachingService interface {
 	HandleResourceRequest(context.Context, *backend.CallResourceRequest) (bool, CachedResourceDataResponse)
 }
 
-// Implementation of interface - does nothing
+const (
+	queryCacheKeyPrefix           = "query-cache-"
+	queryCacheGenerationKeyPrefix = "query-cache-generation-"
+)
+
+// OSSCachingService caches the results of data source queries in the remote cache.
+// Resource requests aren't cached.
+//
+// The results are cached for the TTL of the data source and keyed on the data source, the queries and their
+// time range aligned to the TTL. Cleaning the cache of a data source changes its generation, which is part
+// of the key, so that the results cached before are never read again and expire on their own. The results of
+// data sources which can authorize each user differently are keyed on the user too.
 type OSSCachingService struct {
+	cfg            setting.QueryCachingSettings
+	sendUserHeader bool
+	cache          remotecache.CacheStorage
+	accessControl  accesscontrol.AccessControl
+	features       featuremgmt.FeatureToggles
+	log            log.Logger
+
+	queryRequests *prometheus.CounterVec
+}
+
+// queryCachingSettings are the query caching settings found in the JSON data of data sources and queries
+type queryCachingSettings struct {
+	// TTL is in milliseconds, a negative TTL set on a data source disables caching
+	TTL             int64                        `json:"queryCachingTTL"`
+	OAuthPassThru   bool                         `json:"oauthPassThru"`
+	KeepCookies     []string                     `json:"keepCookies"`
+	TeamHTTPHeaders *datasources.TeamHTTPHeaders `json:"teamHttpHeaders"`
 }
 
 func (s *OSSCachingService) HandleQueryRequest(ctx context.Context, req *backend.QueryDataRequest) (bool, CachedQueryDataResponse) {
-	return false, CachedQueryDataResponse{}
+	if !s.cfg.Enabled || s.cache == nil || req == nil || req.PluginContext.DataSourceInstanceSettings == nil {
+		return false, CachedQueryDataResponse{}
+	}
+
+	ds := req.PluginContext.DataSourceInstanceSettings
+	ctxLogger := s.log.FromContext(ctx).New("datasourceUid", ds.UID)
+
+	ttl, ok := s.queryTTL(req)
+	if !ok {
+		s.setStatus(ctx, ds.Type, StatusDisabled)
+		return false, CachedQueryDataResponse{}
+	}
+
+	generation, err := s.generation(ctx, req.PluginContext.OrgID, ds.UID)
+	if err != nil {
+		ctxLogger.Warn("Failed to read the query cache generation", "error", err)
+		s.setStatus(ctx, ds.Type, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	key, err := queryCacheKey(req, generation, s.userKey(req), ttl)
+	if err != nil {
+		ctxLogger.Warn("Failed to compute the query cache key", "error", err)
+		s.setStatus(ctx, ds.Type, StatusError)
+		return false, CachedQueryDataResponse{}
+	}
+
+	cached := CachedQueryDataResponse{UpdateCacheFn: s.updateQueryCacheFn(key, ttl)}
+
+	// the cache-busting header skips the cached results, the fresh ones replace them
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.SkipQueryCache {
+		s.setStatus(ctx, ds.Type, StatusBypass)
+		return false, cached
+	}
+
+	data, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			ctxLogger.Warn("Failed to read the query cache", "error", err)
+			s.setStatus(ctx, ds.Type, StatusError)
+			return false, CachedQueryDataResponse{}
+		}
+		s.setStatus(ctx, ds.Type, StatusMiss)
+		return false, cached
+	}
+
+	resp := &backend.QueryDataResponse{}
+	if err := json.Unmarshal(data, resp); err != nil {
+		// the entry is overwritten with the fresh results
+		ctxLogger.Warn("Failed to decode the cached query results", "error", err)
+		s.setStatus(ctx, ds.Type, StatusMiss)
+		return false, cached
+	}
+
+	s.setStatus(ctx, ds.Type, StatusHit)
+	cached.Response = resp
+	return true, cached
 }
 
 func (s *OSSCachingService) HandleResourceRequest(ctx context.Context, req *backend.CallResourceRequest) (bool, CachedResourceDataResponse) {
 	return false, CachedResourceDataResponse{}
 }
 
+// PurgeDataSource removes the cached query results of a data source
+func (s *OSSCachingService) PurgeDataSource(ctx context.Context, orgID int64, dsUID string) error {
+	return s.cache.Set(ctx, generationKey(orgID, dsUID), []byte(util.GenerateShortUID()), 0)
+}
+
+func (s *OSSCachingService) updateQueryCacheFn(key string, ttl time.Duration) CacheQueryResponseFn {
+	return func(ctx context.Context, resp *backend.QueryDataResponse) {
+		if resp == nil {
+			return
+		}
+		// errors are not cached so that the queries are retried
+		for _, r := range resp.Responses {
+			if r.Error != nil {
+				return
+			}
+		}
+
+		data, err := json.Marshal(resp)
+		if err != nil {
+			s.log.FromContext(ctx).Warn("Failed to encode the query results", "error", err)
+			return
+		}
+		if s.cfg.MaxValueSize > 0 && len(data) > s.cfg.MaxValueSize {
+			s.log.FromContext(ctx).Debug("Skipping caching of large query results", "size", len(data))
+			return
+		}
+
+		if err := s.cache.Set(ctx, key, data, ttl); err != nil {
+			s.log.FromContext(ctx).Warn("Failed to cache the query results", "error", err)
+		}
+	}
+}
+
+// queryTTL returns how long the results of the request are cached, it returns false when the data source
+// disables caching or forwards the identity of the user as results could differ between users.
+func (s *OSSCachingService) queryTTL(req *backend.QueryDataRequest) (time.Duration, bool) {
+	var dsSettings queryCachingSettings
+	if len(req.PluginContext.DataSourceInstanceSettings.JSONData) > 0 {
+		if err := json.Unmarshal(req.PluginContext.DataSourceInstanceSettings.JSONData, &dsSettings); err != nil {
+			return 0, false
+		}
+	}
+	if dsSettings.TTL < 0 || dsSettings.OAuthPassThru {
+		return 0, false
+	}
+
+	ttl := s.cfg.TTL
+	if dsSettings.TTL > 0 {
+		ttl = time.Duration(dsSettings.TTL) * time.Millisecond
+	}
+
+	// panels can override the TTL of their queries
+	for _, q := range req.Queries {
+		var querySettings queryCachingSettings
+		if len(q.JSON) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(q.JSON, &querySettings); err == nil && querySettings.TTL > 0 {
+			ttl = time.Duration(querySettings.TTL) * time.Millisecond
+			break
+		}
+	}
+
+	return ttl, ttl >= time.Second
+}
+
+// generation returns the current generation of the cache of a data source
+func (s *OSSCachingService) generation(ctx context.Context, orgID int64, dsUID string) (string, error) {
+	generation, err := s.cache.Get(ctx, generationKey(orgID, dsUID))
+	if err == nil {
+		return string(generation), nil
+	}
+	if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+		return "", err
+	}
+
+	// the previous generation has expired along with its results
+	next := util.GenerateShortUID()
+	if err := s.cache.Set(ctx, generationKey(orgID, dsUID), []byte(next), 0); err != nil {
+		return "", err
+	}
+	return next, nil
+}
+
+// userKey returns the user the results of the request are cached for. It's empty when the results are shared by
+// all the users, unless the identity, the login, the cookies or the team headers of users are sent to the data source.
+func (s *OSSCachingService) userKey(req *backend.QueryDataRequest) string {
+	if req.PluginContext.User == nil {
+		return ""
+	}
+
+	perUser := s.sendUserHeader || s.features.IsEnabledGlobally(featuremgmt.FlagIdForwarding)
+	if !perUser {
+		var dsSettings queryCachingSettings
+		// the JSON data was already decoded by queryTTL
+		_ = json.Unmarshal(req.PluginContext.DataSourceInstanceSettings.JSONData, &dsSettings)
+		perUser = len(dsSettings.KeepCookies) > 0 || (dsSettings.TeamHTTPHeaders != nil && len(dsSettings.TeamHTTPHeaders.Headers) > 0)
+	}
+	if !perUser {
+		return ""
+	}
+	return req.PluginContext.User.Login
+}
+
+func (s *OSSCachingService) setStatus(ctx context.Context, dsType, status string) {
+	s.queryRequests.WithLabelValues(dsType, status).Inc()
+	if reqCtx := contexthandler.FromContext(ctx); reqCtx != nil && reqCtx.Resp != nil {
+		reqCtx.Resp.Header().Set(XCacheHeader, status)
+	}
+}
+
+// queryCacheKey hashes the data source, the generation of its cache, the user the results are cached for and the
+// queries with their time range aligned to the TTL so that the requests made within the same TTL window share the
+// results.
+func queryCacheKey(req *backend.QueryDataRequest, generation, user string, ttl time.Duration) (string, error) {
+	h := sha256.New()
+	ds := req.PluginContext.DataSourceInstanceSettings
+	for _, v := range []string{strconv.FormatInt(req.PluginContext.OrgID, 10), ds.UID, generation, user} {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	for _, q := range req.Queries {
+		var model map[string]any
+		if len(q.JSON) > 0 {
+			if err := json.Unmarshal(q.JSON, &model); err != nil {
+				return "", err
+			}
+		}
+		// the TTL doesn't change the results
+		delete(model, "queryCachingTTL")
+		// maps are encoded with sorted keys
+		encoded, err := json.Marshal(model)
+		if err != nil {
+			return "", err
+		}
+
+		h.Write([]byte(q.RefID))
+		h.Write([]byte{0})
+		h.Write([]byte(q.QueryType))
+		h.Write([]byte{0})
+		h.Write(encoded)
+		h.Write([]byte{0})
+		_ = binary.Write(h, binary.BigEndian, []int64{
+			q.MaxDataPoints,
+			int64(q.Interval),
+			q.TimeRange.From.Truncate(ttl).UnixMilli(),
+			q.TimeRange.To.Truncate(ttl).UnixMilli(),
+		})
+	}
+
+	return queryCacheKeyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func generationKey(orgID int64, dsUID string) string {
+	return queryCacheGenerationKeyPrefix + strconv.FormatInt(orgID, 10) + "-" + dsUID
+}
+
 var _ CachingService = &OSSCachingService{}
@@ -0,0 +1,208 @@
+package caching
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/contexthandler/ctxkey"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestOSSCachingService_HandleQueryRequest(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 10, 0, time.UTC)
+
+	setupWithCfg := func(cfg *setting.Cfg, features featuremgmt.FeatureToggles) *OSSCachingService {
+		cfg.QueryCaching = setting.QueryCachingSettings{Enabled: true, TTL: time.Minute, MaxValueSize: 1024 * 1024}
+		return ProvideCachingService(cfg, remotecache.NewFakeCacheStorage(), routing.NewRouteRegister(), actest.FakeAccessControl{}, features, prometheus.NewRegistry())
+	}
+
+	setup := func(t *testing.T) *OSSCachingService {
+		return setupWithCfg(setting.NewCfg(), featuremgmt.WithFeatures())
+	}
+
+	request := func(jsonData string, to time.Time) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID: 1,
+				User:  &backend.User{Login: "alice"},
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+					UID:      "prometheus",
+					Type:     "prometheus",
+					JSONData: []byte(jsonData),
+				},
+			},
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      []byte(`{"refId": "A", "expr": "up", "queryCachingTTL": 0}`),
+				TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+			}},
+		}
+	}
+
+	response := func() *backend.QueryDataResponse {
+		return &backend.QueryDataResponse{Responses: backend.Responses{
+			"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1, 0}))}},
+		}}
+	}
+
+	reqContext := func(skipCache bool) (context.Context, *contextmodel.ReqContext) {
+		req := httptest.NewRequest(http.MethodPost, "/api/ds/query", nil)
+		reqCtx := &contextmodel.ReqContext{
+			Context:        &web.Context{Req: req, Resp: web.NewResponseWriter(req.Method, httptest.NewRecorder())},
+			SkipQueryCache: skipCache,
+		}
+		return ctxkey.Set(context.Background(), reqCtx), reqCtx
+	}
+
+	t.Run("should return the cached results of the same queries", func(t *testing.T) {
+		s := setup(t)
+
+		ctx, reqCtx := reqContext(false)
+		hit, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		require.False(t, hit)
+		assert.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+		require.NotNil(t, cached.UpdateCacheFn)
+		cached.UpdateCacheFn(ctx, response())
+
+		// the time range is aligned to the TTL
+		ctx, reqCtx = reqContext(false)
+		hit, cached = s.HandleQueryRequest(ctx, request("{}", now.Add(30*time.Second)))
+		require.True(t, hit)
+		assert.Equal(t, StatusHit, reqCtx.Resp.Header().Get(XCacheHeader))
+		assert.Equal(t, response().Responses["A"].Frames[0].Fields[0].Len(), cached.Response.Responses["A"].Frames[0].Fields[0].Len())
+
+		ctx, _ = reqContext(false)
+		hit, _ = s.HandleQueryRequest(ctx, request("{}", now.Add(time.Minute)))
+		assert.False(t, hit)
+	})
+
+	t.Run("should skip the cached results with the cache-busting header", func(t *testing.T) {
+		s := setup(t)
+
+		ctx, _ := reqContext(false)
+		_, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		cached.UpdateCacheFn(ctx, response())
+
+		ctx, reqCtx := reqContext(true)
+		hit, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		assert.False(t, hit)
+		assert.Equal(t, StatusBypass, reqCtx.Resp.Header().Get(XCacheHeader))
+		assert.NotNil(t, cached.UpdateCacheFn)
+	})
+
+	t.Run("should not return the results cached before the cache of the data source was cleaned", func(t *testing.T) {
+		s := setup(t)
+
+		ctx, _ := reqContext(false)
+		_, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		cached.UpdateCacheFn(ctx, response())
+
+		require.NoError(t, s.PurgeDataSource(context.Background(), 1, "prometheus"))
+
+		ctx, reqCtx := reqContext(false)
+		hit, _ := s.HandleQueryRequest(ctx, request("{}", now))
+		assert.False(t, hit)
+		assert.Equal(t, StatusMiss, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+
+	t.Run("should not cache failed queries", func(t *testing.T) {
+		s := setup(t)
+
+		ctx, _ := reqContext(false)
+		_, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		cached.UpdateCacheFn(ctx, &backend.QueryDataResponse{Responses: backend.Responses{"A": {Error: errors.New("timeout")}}})
+
+		ctx, _ = reqContext(false)
+		hit, _ := s.HandleQueryRequest(ctx, request("{}", now))
+		assert.False(t, hit)
+	})
+
+	t.Run("should not cache the queries of data sources disabling caching", func(t *testing.T) {
+		for _, jsonData := range []string{`{"queryCachingTTL": -1}`, `{"oauthPassThru": true}`} {
+			s := setup(t)
+
+			ctx, reqCtx := reqContext(false)
+			hit, cached := s.HandleQueryRequest(ctx, request(jsonData, now))
+			assert.False(t, hit)
+			assert.Nil(t, cached.UpdateCacheFn)
+			assert.Equal(t, StatusDisabled, reqCtx.Resp.Header().Get(XCacheHeader))
+		}
+	})
+
+	t.Run("should cache the results for each user when the data source can authorize users differently", func(t *testing.T) {
+		sendUserHeader := setting.NewCfg()
+		sendUserHeader.SendUserHeader = true
+
+		for name, tt := range map[string]struct {
+			s        *OSSCachingService
+			jsonData string
+		}{
+			"team headers": {setup(t), `{"teamHttpHeaders": {"headers": {"1": [{"header": "X-Team", "value": "a"}]}}}`},
+			"kept cookies": {setup(t), `{"keepCookies": ["session"]}`},
+			"user header":  {setupWithCfg(sendUserHeader, featuremgmt.WithFeatures()), "{}"},
+			"forwarded ID": {setupWithCfg(setting.NewCfg(), featuremgmt.WithFeatures(featuremgmt.FlagIdForwarding)), "{}"},
+		} {
+			t.Run(name, func(t *testing.T) {
+				ctx, _ := reqContext(false)
+				_, cached := tt.s.HandleQueryRequest(ctx, request(tt.jsonData, now))
+				cached.UpdateCacheFn(ctx, response())
+
+				ctx, _ = reqContext(false)
+				hit, _ := tt.s.HandleQueryRequest(ctx, request(tt.jsonData, now))
+				assert.True(t, hit)
+
+				other := request(tt.jsonData, now)
+				other.PluginContext.User = &backend.User{Login: "bob"}
+				ctx, _ = reqContext(false)
+				hit, _ = tt.s.HandleQueryRequest(ctx, other)
+				assert.False(t, hit)
+			})
+		}
+
+		// the results of other data sources are shared
+		s := setup(t)
+		ctx, _ := reqContext(false)
+		_, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		cached.UpdateCacheFn(ctx, response())
+
+		other := request("{}", now)
+		other.PluginContext.User = &backend.User{Login: "bob"}
+		ctx, _ = reqContext(false)
+		hit, _ := s.HandleQueryRequest(ctx, other)
+		assert.True(t, hit)
+	})
+
+	t.Run("should use the TTL of the data source", func(t *testing.T) {
+		s := setup(t)
+
+		ttl, ok := s.queryTTL(request(`{"queryCachingTTL": 300000}`, now))
+		require.True(t, ok)
+		assert.Equal(t, 5*time.Minute, ttl)
+	})
+
+	t.Run("should not cache anything when query caching is disabled", func(t *testing.T) {
+		s := &OSSCachingService{}
+
+		ctx, reqCtx := reqContext(false)
+		hit, cached := s.HandleQueryRequest(ctx, request("{}", now))
+		assert.False(t, hit)
+		assert.Nil(t, cached.UpdateCacheFn)
+		assert.Empty(t, reqCtx.Resp.Header().Get(XCacheHeader))
+	})
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/ngalert/image"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/queryhistory"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	tempuser "github.com/grafana/grafana/pkg/services/temp_user"
@@ -41,12 +42,14 @@ type CleanUpService struct {
 	tempUserService           tempuser.Service
 	annotationCleaner         annotations.Cleaner
 	dashboardService          dashboards.DashboardService
+	publicDashboardService    publicdashboards.Service
 }
 
 func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockService,
 	shortURLService shorturls.Service, sqlstore db.DB, queryHistoryService queryhistory.Service,
 	dashboardVersionService dashver.Service, dashSnapSvc dashboardsnapshots.Service, deleteExpiredImageService *image.DeleteExpiredService,
-	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService) *CleanUpService {
+	tempUserService tempuser.Service, tracer tracing.Tracer, annotationCleaner annotations.Cleaner, dashboardService dashboards.DashboardService,
+	publicDashboardService publicdashboards.Service) *CleanUpService {
 	s := &CleanUpService{
 		Cfg:                       cfg,
 		ServerLockService:         serverLockService,
@@ -61,6 +64,7 @@ func ProvideService(cfg *setting.Cfg, serverLockService *serverlock.ServerLockSe
 		tracer:                    tracer,
 		annotationCleaner:         annotationCleaner,
 		dashboardService:          dashboardService,
+		publicDashboardService:    publicDashboardService,
 	}
 	return s
 }
@@ -107,6 +111,7 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"delete stale query history", srv.deleteStaleQueryHistory},
 		{"expire old email verifications", srv.expireOldVerifications},
 		{"cleanup trash dashboards", srv.cleanUpTrashDashboards},
+		{"delete expired public dashboard access log", srv.deleteExpiredPublicDashboardAccessLog},
 	}
 
 	logger := srv.log.FromContext(ctx)
@@ -206,6 +211,16 @@ func (srv *CleanUpService) deleteExpiredSnapshots(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredPublicDashboardAccessLog(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	deleted, err := srv.publicDashboardService.DeleteExpiredAccessLog(ctx)
+	if err != nil {
+		logger.Error("Failed to delete expired public dashboard access log", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired public dashboard access log", "rows affected", deleted)
+	}
+}
+
 func (srv *CleanUpService) deleteExpiredDashboardVersions(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	cmd := dashver.DeleteExpiredVersionsCommand{}
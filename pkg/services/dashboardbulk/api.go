@@ -0,0 +1,110 @@
+package dashboardbulk
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *DashboardBulkService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	canModify := ac.EvalAny(
+		ac.EvalPermission(dashboards.ActionDashboardsWrite),
+		ac.EvalPermission(dashboards.ActionDashboardsDelete),
+	)
+
+	s.routeRegister.Group("/api/dashboards/bulk", func(bulk routing.RouteRegister) {
+		bulk.Post("/preview", authorize(canModify), routing.Wrap(s.previewHandler))
+		bulk.Post("/apply", authorize(canModify), routing.Wrap(s.applyHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route POST /dashboards/bulk/preview dashboards previewBulkDashboardChanges
+//
+// Preview the changes of a bulk dashboard operation.
+//
+// Returns the dashboards the operations would modify or delete without saving anything.
+//
+// Responses:
+// 200: bulkDashboardChangesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DashboardBulkService) previewHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := Command{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.User = c.SignedInUser
+
+	result, err := s.Preview(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// swagger:route POST /dashboards/bulk/apply dashboards applyBulkDashboardChanges
+//
+// Apply a bulk dashboard operation.
+//
+// All selected dashboards are modified in a single transaction, if one of them cannot be saved none is. Each modified
+// dashboard gets one new version.
+//
+// Responses:
+// 200: bulkDashboardChangesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 412: preconditionFailedError
+// 500: internalServerError
+func (s *DashboardBulkService) applyHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := Command{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.User = c.SignedInUser
+
+	result, err := s.Apply(c.Req.Context(), &cmd)
+	if err != nil {
+		return errorResponse(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// errorResponse reports which dashboard failed to save, with the status code of the dashboard error
+func errorResponse(err error) response.Response {
+	var dashErr *DashboardError
+	if !errors.As(err, &dashErr) {
+		return response.Err(err)
+	}
+
+	var dashboardErr dashboards.DashboardErr
+	if errors.As(dashErr.Err, &dashboardErr) {
+		return response.Error(dashboardErr.StatusCode, dashErr.Error(), err)
+	}
+	return response.ErrOrFallback(http.StatusInternalServerError, dashErr.Error(), err)
+}
+
+// swagger:parameters previewBulkDashboardChanges applyBulkDashboardChanges
+type BulkDashboardChangesParams struct {
+	// in:body
+	// required:true
+	Body Command `json:"body"`
+}
+
+// swagger:response bulkDashboardChangesResponse
+type BulkDashboardChangesResponse struct {
+	// in: body
+	Body Result `json:"body"`
+}
@@ -0,0 +1,234 @@
+package dashboardbulk
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dependencies"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/sqlstore/searchstore"
+)
+
+// Service applies the same set of operations to many dashboards at once. Changes are computed up front, so that they
+// can be previewed, and applied in a single transaction: either every dashboard is modified or none is.
+type Service interface {
+	// Preview returns the changes a command would make without saving them.
+	Preview(ctx context.Context, cmd *Command) (*Result, error)
+	// Apply saves the changes of a command, creating one new version per modified dashboard.
+	Apply(ctx context.Context, cmd *Command) (*Result, error)
+}
+
+type DashboardBulkService struct {
+	db                     db.DB
+	dashboardService       dashboards.DashboardService
+	libraryElementService  libraryelements.Service
+	publicDashboardService publicdashboards.Service
+	dependenciesService    dependencies.Service
+	routeRegister          routing.RouteRegister
+	accessControl          accesscontrol.AccessControl
+	features               featuremgmt.FeatureToggles
+	log                    log.Logger
+}
+
+func ProvideService(sql db.DB, routeRegister routing.RouteRegister, dashboardService dashboards.DashboardService,
+	libraryElementService libraryelements.Service, publicDashboardService publicdashboards.Service,
+	dependenciesService dependencies.Service, accessControl accesscontrol.AccessControl,
+	features featuremgmt.FeatureToggles) *DashboardBulkService {
+	s := &DashboardBulkService{
+		db:                     sql,
+		dashboardService:       dashboardService,
+		libraryElementService:  libraryElementService,
+		publicDashboardService: publicDashboardService,
+		dependenciesService:    dependenciesService,
+		routeRegister:          routeRegister,
+		accessControl:          accessControl,
+		features:               features,
+		log:                    log.New("dashboardbulk"),
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// plannedChange is a selected dashboard with the operations already applied to its JSON
+type plannedChange struct {
+	dashboard *dashboards.Dashboard
+	change    *DashboardChange
+}
+
+func (s *DashboardBulkService) Preview(ctx context.Context, cmd *Command) (*Result, error) {
+	planned, unchanged, err := s.plan(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return newResult(planned, unchanged, false), nil
+}
+
+func (s *DashboardBulkService) Apply(ctx context.Context, cmd *Command) (*Result, error) {
+	planned, unchanged, err := s.plan(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := make([]*dashboards.Dashboard, 0, len(planned))
+	err = s.db.InTransaction(ctx, func(ctx context.Context) error {
+		saved = saved[:0]
+		for _, p := range planned {
+			if p.change.Action == ActionDelete {
+				if err := s.deleteDashboard(ctx, cmd.OrgID, p.dashboard); err != nil {
+					return &DashboardError{UID: p.dashboard.UID, Err: err}
+				}
+				continue
+			}
+
+			dash, err := s.dashboardService.SaveDashboard(ctx, &dashboards.SaveDashboardDTO{
+				OrgID:     cmd.OrgID,
+				User:      cmd.User,
+				Message:   cmd.Message,
+				Dashboard: p.dashboard,
+			}, false)
+			if err != nil {
+				return &DashboardError{UID: p.dashboard.UID, Err: err}
+			}
+			p.change.Version = dash.Version
+			saved = append(saved, dash)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the dependency index is rebuilt in the background as well, a failure here only delays the update
+	for _, p := range planned {
+		if p.change.Action == ActionDelete {
+			if err := s.dependenciesService.RemoveDashboard(ctx, cmd.OrgID, p.dashboard.UID); err != nil {
+				s.log.Warn("Failed to remove dashboard dependencies", "dashboard", p.dashboard.UID, "error", err)
+			}
+		}
+	}
+	for _, dash := range saved {
+		if err := s.dependenciesService.IndexDashboard(ctx, dash); err != nil {
+			s.log.Warn("Failed to index dashboard dependencies", "dashboard", dash.UID, "error", err)
+		}
+	}
+
+	return newResult(planned, unchanged, true), nil
+}
+
+// plan resolves the selection and applies the operations to each dashboard in memory. It fails if the user is not
+// allowed to modify one of the affected dashboards.
+func (s *DashboardBulkService) plan(ctx context.Context, cmd *Command) ([]*plannedChange, int, error) {
+	if cmd.Selection.isEmpty() {
+		return nil, 0, ErrEmptySelection.Errorf("no selection criteria")
+	}
+	if err := validateOperations(cmd.Operations); err != nil {
+		return nil, 0, err
+	}
+
+	hits, err := s.dashboardService.FindDashboards(ctx, &dashboards.FindPersistedDashboardsQuery{
+		OrgId:         cmd.OrgID,
+		SignedInUser:  cmd.User,
+		DashboardUIDs: cmd.Selection.UIDs,
+		FolderUIDs:    cmd.Selection.FolderUIDs,
+		Tags:          cmd.Selection.Tags,
+		Title:         cmd.Selection.Query,
+		Type:          searchstore.TypeDashboard,
+		Limit:         maxDashboards + 1,
+	})
+	if err != nil {
+		return nil, 0, ErrInternal.Errorf("failed to search dashboards: %w", err)
+	}
+	if len(hits) > maxDashboards {
+		return nil, 0, ErrSelectionTooLarge.Errorf("selection matches more than %d dashboards", maxDashboards)
+	}
+
+	action := ActionUpdate
+	if cmd.Operations[0].Type == OperationDelete {
+		action = ActionDelete
+	}
+
+	planned := make([]*plannedChange, 0, len(hits))
+	unchanged := 0
+	for _, hit := range hits {
+		dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: cmd.OrgID, UID: hit.UID})
+		if err != nil {
+			if errors.Is(err, dashboards.ErrDashboardNotFound) {
+				continue
+			}
+			return nil, 0, ErrInternal.Errorf("failed to get dashboard %s: %w", hit.UID, err)
+		}
+
+		// permissions depend on the current folder, check them before a move is applied
+		permissionErr := s.checkPermission(ctx, cmd, dash, action)
+		change := applyOperations(dash, cmd.Operations)
+		if change == nil {
+			unchanged++
+			continue
+		}
+		if permissionErr != nil {
+			return nil, 0, permissionErr
+		}
+		planned = append(planned, &plannedChange{dashboard: dash, change: change})
+	}
+
+	return planned, unchanged, nil
+}
+
+func (s *DashboardBulkService) checkPermission(ctx context.Context, cmd *Command, dash *dashboards.Dashboard, action Action) error {
+	g, err := guardian.NewByDashboard(ctx, dash, cmd.OrgID, cmd.User)
+	if err != nil {
+		return ErrInternal.Errorf("failed to check permissions of dashboard %s: %w", dash.UID, err)
+	}
+
+	var allowed bool
+	if action == ActionDelete {
+		allowed, err = g.CanDelete()
+	} else {
+		allowed, err = g.CanSave()
+	}
+	if err != nil {
+		return ErrInternal.Errorf("failed to check permissions of dashboard %s: %w", dash.UID, err)
+	}
+	if !allowed {
+		return ErrAccessDenied.Errorf("not allowed to %s dashboard %s", action, dash.UID)
+	}
+	return nil
+}
+
+// deleteDashboard removes a dashboard the same way the dashboard API does, moving it to the trash when dashboards
+// can be restored
+func (s *DashboardBulkService) deleteDashboard(ctx context.Context, orgID int64, dash *dashboards.Dashboard) error {
+	if s.features.IsEnabledGlobally(featuremgmt.FlagDashboardRestore) {
+		return s.dashboardService.SoftDeleteDashboard(ctx, orgID, dash.UID)
+	}
+
+	if err := s.libraryElementService.DisconnectElementsFromDashboard(ctx, dash.ID); err != nil {
+		return err
+	}
+	if err := s.publicDashboardService.DeleteByDashboard(ctx, dash); err != nil {
+		return err
+	}
+	return s.dashboardService.DeleteDashboard(ctx, dash.ID, orgID)
+}
+
+func newResult(planned []*plannedChange, unchanged int, applied bool) *Result {
+	result := &Result{
+		Dashboards: make([]DashboardChange, 0, len(planned)),
+		Unchanged:  unchanged,
+		Applied:    applied,
+	}
+	for _, p := range planned {
+		result.Dashboards = append(result.Dashboards, *p.change)
+	}
+	return result
+}
@@ -0,0 +1,259 @@
+package dashboardbulk
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/dependencies/dependenciestest"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeLibraryElementService struct {
+	libraryelements.Service
+	disconnected []int64
+}
+
+func (f *fakeLibraryElementService) DisconnectElementsFromDashboard(_ context.Context, dashboardID int64) error {
+	f.disconnected = append(f.disconnected, dashboardID)
+	return nil
+}
+
+type testEnv struct {
+	dashboardStore   dashboards.Store
+	dashboardService *dashboards.FakeDashboardService
+	libraryElements  *fakeLibraryElementService
+	dependencies     *dependenciestest.FakeService
+	service          *DashboardBulkService
+	// failUID is a dashboard that fails to save, after the ones before it were written
+	failUID string
+}
+
+// setupTestEnv stores three dashboards, a and b tagged prod and c tagged dev. The fake dashboard service saves
+// through the real store so that rollbacks can be observed.
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	for uid, tag := range map[string]string{"a": "prod", "b": "prod", "c": "dev"} {
+		_, err := dashboardStore.SaveDashboard(context.Background(), dashboards.SaveDashboardCommand{
+			OrgID: 1,
+			Dashboard: simplejson.NewFromAny(map[string]any{
+				"uid":   uid,
+				"title": "Dashboard " + uid,
+				"tags":  []any{tag},
+			}),
+		})
+		require.NoError(t, err)
+	}
+
+	env := &testEnv{
+		dashboardStore:   dashboardStore,
+		dashboardService: dashboards.NewFakeDashboardService(t),
+		libraryElements:  &fakeLibraryElementService{},
+		dependencies:     dependenciestest.NewFakeService(),
+	}
+
+	env.dashboardService.On("FindDashboards", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, query *dashboards.FindPersistedDashboardsQuery) ([]dashboards.DashboardSearchProjection, error) {
+			hits := make([]dashboards.DashboardSearchProjection, 0)
+			for _, uid := range []string{"a", "b", "c"} {
+				dash, err := dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: query.OrgId, UID: uid})
+				if err != nil {
+					return nil, err
+				}
+				if len(query.DashboardUIDs) > 0 && !slices.Contains(query.DashboardUIDs, uid) {
+					continue
+				}
+				if len(query.Tags) > 0 && !slices.ContainsFunc(dash.GetTags(), func(tag string) bool { return slices.Contains(query.Tags, tag) }) {
+					continue
+				}
+				hits = append(hits, dashboards.DashboardSearchProjection{ID: dash.ID, UID: dash.UID, Title: dash.Title})
+			}
+			return hits, nil
+		}).Maybe()
+	env.dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(
+		func(ctx context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+			return dashboardStore.GetDashboard(ctx, query)
+		}).Maybe()
+	env.dashboardService.On("SaveDashboard", mock.Anything, mock.Anything, false).Return(
+		func(ctx context.Context, dto *dashboards.SaveDashboardDTO, _ bool) (*dashboards.Dashboard, error) {
+			if dto.Dashboard.UID == env.failUID {
+				return nil, dashboards.ErrDashboardVersionMismatch
+			}
+			return dashboardStore.SaveDashboard(ctx, dashboards.SaveDashboardCommand{
+				OrgID:     dto.OrgID,
+				Message:   dto.Message,
+				Dashboard: dto.Dashboard.Data,
+			})
+		}).Maybe()
+	env.dashboardService.On("DeleteDashboard", mock.Anything, mock.Anything, int64(1)).Return(
+		func(ctx context.Context, id int64, orgID int64) error {
+			return dashboardStore.DeleteDashboard(ctx, &dashboards.DeleteDashboardCommand{OrgID: orgID, ID: id})
+		}).Maybe()
+
+	publicDashboardService := publicdashboards.NewFakePublicDashboardService(t)
+	publicDashboardService.On("DeleteByDashboard", mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveValue: true})
+
+	env.service = ProvideService(sqlStore, routing.NewRouteRegister(), env.dashboardService, env.libraryElements,
+		publicDashboardService, env.dependencies, actest.FakeAccessControl{}, featuremgmt.WithFeatures())
+	return env
+}
+
+func (e *testEnv) getDashboard(t *testing.T, uid string) *dashboards.Dashboard {
+	t.Helper()
+	dash, err := e.dashboardStore.GetDashboard(context.Background(), &dashboards.GetDashboardQuery{OrgID: 1, UID: uid})
+	require.NoError(t, err)
+	return dash
+}
+
+func newCommand(selection Selection, ops ...Operation) *Command {
+	return &Command{
+		OrgID:      1,
+		User:       &user.SignedInUser{OrgID: 1, UserID: 1},
+		Selection:  selection,
+		Operations: ops,
+		Message:    "bulk update",
+	}
+}
+
+func TestIntegrationDashboardBulk(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	addTag := Operation{Type: OperationAddTags, Tags: []string{"team-a"}}
+
+	t.Run("preview does not save", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		result, err := env.service.Preview(ctx, newCommand(Selection{Tags: []string{"prod"}}, addTag))
+		require.NoError(t, err)
+
+		assert.False(t, result.Applied)
+		require.Len(t, result.Dashboards, 2)
+		assert.Equal(t, DashboardChange{
+			UID:     "a",
+			Title:   "Dashboard a",
+			Action:  ActionUpdate,
+			Changes: []FieldChange{{Field: "tags", Old: []string{"prod"}, New: []string{"prod", "team-a"}}},
+		}, result.Dashboards[0])
+		assert.Equal(t, []string{"prod"}, env.getDashboard(t, "a").GetTags())
+		env.dashboardService.AssertNotCalled(t, "SaveDashboard", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("apply saves one version per dashboard", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		result, err := env.service.Apply(ctx, newCommand(Selection{Tags: []string{"prod"}},
+			addTag,
+			Operation{Type: OperationSetTime, Time: &TimeSettings{From: "now-7d"}},
+		))
+		require.NoError(t, err)
+
+		assert.True(t, result.Applied)
+		require.Len(t, result.Dashboards, 2)
+		for _, change := range result.Dashboards {
+			dash := env.getDashboard(t, change.UID)
+			assert.Equal(t, 2, dash.Version)
+			assert.Equal(t, 2, change.Version)
+			assert.Equal(t, []string{"prod", "team-a"}, dash.GetTags())
+			assert.Equal(t, "now-7d", dash.Data.GetPath("time", "from").MustString())
+		}
+		assert.Equal(t, 1, env.getDashboard(t, "c").Version)
+		assert.Equal(t, []string{"a", "b"}, env.dependencies.IndexedDashboards)
+	})
+
+	t.Run("dashboards already matching are left unchanged", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		result, err := env.service.Apply(ctx, newCommand(Selection{UIDs: []string{"a", "c"}},
+			Operation{Type: OperationAddTags, Tags: []string{"dev"}},
+		))
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, result.Unchanged)
+		require.Len(t, result.Dashboards, 1)
+		assert.Equal(t, "a", result.Dashboards[0].UID)
+		assert.Equal(t, 1, env.getDashboard(t, "c").Version)
+	})
+
+	t.Run("nothing is saved when a dashboard cannot be edited", func(t *testing.T) {
+		env := setupTestEnv(t)
+		guardian.MockDashboardGuardian(&guardian.FakeDashboardGuardian{CanSaveUIDs: []string{"a"}})
+
+		_, err := env.service.Apply(ctx, newCommand(Selection{Tags: []string{"prod"}}, addTag))
+		require.ErrorIs(t, err, ErrAccessDenied)
+
+		env.dashboardService.AssertNotCalled(t, "SaveDashboard", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("changes are rolled back when a dashboard fails to save", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.failUID = "b"
+
+		_, err := env.service.Apply(ctx, newCommand(Selection{Tags: []string{"prod"}}, addTag))
+		require.ErrorIs(t, err, dashboards.ErrDashboardVersionMismatch)
+
+		var dashErr *DashboardError
+		require.ErrorAs(t, err, &dashErr)
+		assert.Equal(t, "b", dashErr.UID)
+
+		a := env.getDashboard(t, "a")
+		assert.Equal(t, 1, a.Version)
+		assert.Equal(t, []string{"prod"}, a.GetTags())
+		assert.Empty(t, env.dependencies.IndexedDashboards)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		env := setupTestEnv(t)
+		c := env.getDashboard(t, "c")
+
+		result, err := env.service.Apply(ctx, newCommand(Selection{UIDs: []string{"c"}}, Operation{Type: OperationDelete}))
+		require.NoError(t, err)
+
+		require.Len(t, result.Dashboards, 1)
+		assert.Equal(t, ActionDelete, result.Dashboards[0].Action)
+		_, err = env.dashboardStore.GetDashboard(ctx, &dashboards.GetDashboardQuery{OrgID: 1, UID: "c"})
+		require.ErrorIs(t, err, dashboards.ErrDashboardNotFound)
+		assert.Equal(t, []int64{c.ID}, env.libraryElements.disconnected)
+		assert.Equal(t, []string{"c"}, env.dependencies.RemovedDashboards)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		env := setupTestEnv(t)
+
+		_, err := env.service.Preview(ctx, newCommand(Selection{}, addTag))
+		require.ErrorIs(t, err, ErrEmptySelection)
+
+		_, err = env.service.Preview(ctx, newCommand(Selection{Tags: []string{"prod"}}))
+		require.ErrorIs(t, err, ErrInvalidOperation)
+	})
+}
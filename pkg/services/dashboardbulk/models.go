@@ -0,0 +1,133 @@
+package dashboardbulk
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+// maxDashboards is the largest number of dashboards a single bulk request can select
+const maxDashboards = 1000
+
+var (
+	ErrEmptySelection    = errutil.BadRequest("dashboardbulk.emptySelection", errutil.WithPublicMessage("A selection of dashboards is required"))
+	ErrSelectionTooLarge = errutil.BadRequest("dashboardbulk.selectionTooLarge", errutil.WithPublicMessage(fmt.Sprintf("A bulk request cannot select more than %d dashboards", maxDashboards)))
+	ErrInvalidOperation  = errutil.BadRequest("dashboardbulk.invalidOperation")
+	ErrAccessDenied      = errutil.Forbidden("dashboardbulk.accessDenied")
+	ErrInternal          = errutil.Internal("dashboardbulk.internal")
+)
+
+// OperationType is the kind of change applied to every selected dashboard
+type OperationType string
+
+const (
+	OperationMove              OperationType = "move"
+	OperationAddTags           OperationType = "addTags"
+	OperationRemoveTags        OperationType = "removeTags"
+	OperationReplaceDatasource OperationType = "replaceDatasource"
+	OperationSetTime           OperationType = "setTime"
+	OperationDelete            OperationType = "delete"
+)
+
+// Selection picks the dashboards a bulk request applies to. When several criteria are set a dashboard has to match
+// all of them.
+type Selection struct {
+	UIDs       []string `json:"uids,omitempty"`
+	FolderUIDs []string `json:"folderUids,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+	// Query matches the dashboard title
+	Query string `json:"query,omitempty"`
+}
+
+func (s Selection) isEmpty() bool {
+	return len(s.UIDs) == 0 && len(s.FolderUIDs) == 0 && len(s.Tags) == 0 && s.Query == ""
+}
+
+// DatasourceReplacement swaps every panel, query and template variable reference to a data source for another one
+type DatasourceReplacement struct {
+	FromUID string `json:"fromUid"`
+	ToUID   string `json:"toUid"`
+	// ToType is set on object references when the new data source is of a different type
+	ToType string `json:"toType,omitempty"`
+}
+
+// TimeSettings are the dashboard time settings to set, empty values are left unchanged
+type TimeSettings struct {
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Refresh  string `json:"refresh,omitempty"`
+}
+
+func (t TimeSettings) isEmpty() bool {
+	return t.From == "" && t.To == "" && t.Timezone == "" && t.Refresh == ""
+}
+
+// Operation is a single change to apply, only the fields of its type are used
+type Operation struct {
+	Type OperationType `json:"type"`
+	// FolderUID is the destination of a move, empty moves the dashboards to the root folder
+	FolderUID  string                 `json:"folderUid,omitempty"`
+	Tags       []string               `json:"tags,omitempty"`
+	Datasource *DatasourceReplacement `json:"datasource,omitempty"`
+	Time       *TimeSettings          `json:"time,omitempty"`
+}
+
+// Command is a bulk request, operations are applied in order to every selected dashboard
+type Command struct {
+	OrgID      int64              `json:"-"`
+	User       identity.Requester `json:"-"`
+	Selection  Selection          `json:"selection"`
+	Operations []Operation        `json:"operations"`
+	// Message is stored with the new version of every updated dashboard
+	Message string `json:"message,omitempty"`
+}
+
+// Action is what happens to a selected dashboard
+type Action string
+
+const (
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+// FieldChange is the old and new value of a dashboard field
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// DashboardChange describes the effect of a bulk request on a dashboard
+type DashboardChange struct {
+	UID       string        `json:"uid"`
+	Title     string        `json:"title"`
+	FolderUID string        `json:"folderUid"`
+	Action    Action        `json:"action"`
+	Changes   []FieldChange `json:"changes,omitempty"`
+	// Version is the new dashboard version, only set once the changes are applied
+	Version int `json:"version,omitempty"`
+}
+
+// Result lists the dashboards modified by a bulk request
+type Result struct {
+	Dashboards []DashboardChange `json:"dashboards"`
+	// Unchanged is the number of selected dashboards the operations had no effect on
+	Unchanged int  `json:"unchanged"`
+	Applied   bool `json:"applied"`
+}
+
+// DashboardError is returned when applying the changes to a dashboard fails, no dashboard is modified then
+type DashboardError struct {
+	UID string
+	Err error
+}
+
+func (e *DashboardError) Error() string {
+	return fmt.Sprintf("dashboard %s: %s", e.UID, e.Err)
+}
+
+func (e *DashboardError) Unwrap() error {
+	return e.Err
+}
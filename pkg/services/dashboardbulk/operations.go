@@ -0,0 +1,193 @@
+package dashboardbulk
+
+import (
+	"slices"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func validateOperations(ops []Operation) error {
+	if len(ops) == 0 {
+		return ErrInvalidOperation.Errorf("at least one operation is required")
+	}
+
+	for i, op := range ops {
+		switch op.Type {
+		case OperationMove:
+		case OperationAddTags, OperationRemoveTags:
+			if len(op.Tags) == 0 {
+				return ErrInvalidOperation.Errorf("operation %d: %s requires tags", i, op.Type)
+			}
+		case OperationReplaceDatasource:
+			if op.Datasource == nil || op.Datasource.FromUID == "" || op.Datasource.ToUID == "" {
+				return ErrInvalidOperation.Errorf("operation %d: %s requires the uid of both data sources", i, op.Type)
+			}
+			if op.Datasource.FromUID == op.Datasource.ToUID {
+				return ErrInvalidOperation.Errorf("operation %d: %s requires two different data sources", i, op.Type)
+			}
+		case OperationSetTime:
+			if op.Time == nil || op.Time.isEmpty() {
+				return ErrInvalidOperation.Errorf("operation %d: %s requires at least one time setting", i, op.Type)
+			}
+		case OperationDelete:
+			if len(ops) > 1 {
+				return ErrInvalidOperation.Errorf("operation %d: %s cannot be combined with other operations", i, op.Type)
+			}
+		default:
+			return ErrInvalidOperation.Errorf("operation %d: unknown type %q", i, op.Type)
+		}
+	}
+	return nil
+}
+
+// applyOperations changes the dashboard in place and returns the resulting change, nil when the operations have no
+// effect on it
+func applyOperations(dash *dashboards.Dashboard, ops []Operation) *DashboardChange {
+	change := &DashboardChange{
+		UID:       dash.UID,
+		Title:     dash.Title,
+		FolderUID: dash.FolderUID,
+		Action:    ActionUpdate,
+	}
+
+	before := snapshot(dash)
+	for _, op := range ops {
+		switch op.Type {
+		case OperationMove:
+			dash.FolderUID = op.FolderUID
+			// the folder id takes precedence when the uid is empty, reset it so that dashboards can move to the root
+			// nolint:staticcheck
+			dash.FolderID = 0
+		case OperationAddTags:
+			tags := dash.GetTags()
+			for _, tag := range op.Tags {
+				if !slices.Contains(tags, tag) {
+					tags = append(tags, tag)
+				}
+			}
+			setTags(dash, tags)
+		case OperationRemoveTags:
+			tags := slices.DeleteFunc(dash.GetTags(), func(tag string) bool {
+				return slices.Contains(op.Tags, tag)
+			})
+			setTags(dash, tags)
+		case OperationReplaceDatasource:
+			if replaceDatasourceRefs(dash.Data.Interface(), op.Datasource) > 0 {
+				change.Changes = append(change.Changes, FieldChange{Field: "datasource", Old: op.Datasource.FromUID, New: op.Datasource.ToUID})
+			}
+		case OperationSetTime:
+			if op.Time.From != "" {
+				dash.Data.SetPath([]string{"time", "from"}, op.Time.From)
+			}
+			if op.Time.To != "" {
+				dash.Data.SetPath([]string{"time", "to"}, op.Time.To)
+			}
+			if op.Time.Timezone != "" {
+				dash.Data.Set("timezone", op.Time.Timezone)
+			}
+			if op.Time.Refresh != "" {
+				dash.Data.Set("refresh", op.Time.Refresh)
+			}
+		case OperationDelete:
+			change.Action = ActionDelete
+			return change
+		}
+	}
+
+	change.Changes = append(diff(before, snapshot(dash)), change.Changes...)
+	if len(change.Changes) == 0 {
+		return nil
+	}
+	return change
+}
+
+// setTags stores the tags as a generic array, which is what the dashboard JSON holds once decoded
+func setTags(dash *dashboards.Dashboard, tags []string) {
+	values := make([]any, 0, len(tags))
+	for _, tag := range tags {
+		values = append(values, tag)
+	}
+	dash.Data.Set("tags", values)
+}
+
+// fields are the dashboard settings the operations can change, data source references are tracked while replacing
+type fields struct {
+	folderUID string
+	tags      []string
+	timeFrom  string
+	timeTo    string
+	timezone  string
+	refresh   string
+}
+
+func snapshot(dash *dashboards.Dashboard) fields {
+	return fields{
+		folderUID: dash.FolderUID,
+		tags:      dash.GetTags(),
+		timeFrom:  dash.Data.GetPath("time", "from").MustString(),
+		timeTo:    dash.Data.GetPath("time", "to").MustString(),
+		timezone:  dash.Data.Get("timezone").MustString(),
+		refresh:   dash.Data.Get("refresh").MustString(),
+	}
+}
+
+func diff(before, after fields) []FieldChange {
+	changes := make([]FieldChange, 0)
+	if before.folderUID != after.folderUID {
+		changes = append(changes, FieldChange{Field: "folderUid", Old: before.folderUID, New: after.folderUID})
+	}
+	if !slices.Equal(before.tags, after.tags) {
+		changes = append(changes, FieldChange{Field: "tags", Old: before.tags, New: after.tags})
+	}
+	for _, f := range []struct {
+		name       string
+		old, value string
+	}{
+		{"time.from", before.timeFrom, after.timeFrom},
+		{"time.to", before.timeTo, after.timeTo},
+		{"timezone", before.timezone, after.timezone},
+		{"refresh", before.refresh, after.refresh},
+	} {
+		if f.old != f.value {
+			changes = append(changes, FieldChange{Field: f.name, Old: f.old, New: f.value})
+		}
+	}
+	return changes
+}
+
+// replaceDatasourceRefs walks the dashboard JSON and updates the data source references of panels, queries, annotations
+// and template variables, both the object references and the legacy uid strings. It returns the number of references
+// replaced.
+func replaceDatasourceRefs(node any, r *DatasourceReplacement) int {
+	count := 0
+	switch v := node.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if key == "datasource" {
+				switch ref := value.(type) {
+				case string:
+					if ref == r.FromUID {
+						v[key] = r.ToUID
+						count++
+					}
+					continue
+				case map[string]any:
+					if uid, _ := ref["uid"].(string); uid == r.FromUID {
+						ref["uid"] = r.ToUID
+						if r.ToType != "" {
+							ref["type"] = r.ToType
+						}
+						count++
+					}
+					continue
+				}
+			}
+			count += replaceDatasourceRefs(value, r)
+		}
+	case []any:
+		for _, item := range v {
+			count += replaceDatasourceRefs(item, r)
+		}
+	}
+	return count
+}
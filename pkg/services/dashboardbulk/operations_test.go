@@ -0,0 +1,130 @@
+package dashboardbulk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func testDashboard(t *testing.T, body string) *dashboards.Dashboard {
+	t.Helper()
+	data, err := simplejson.NewJson([]byte(body))
+	require.NoError(t, err)
+	dash := dashboards.NewDashboardFromJson(data)
+	dash.FolderUID = "folder"
+	return dash
+}
+
+func TestValidateOperations(t *testing.T) {
+	tests := []struct {
+		name  string
+		ops   []Operation
+		valid bool
+	}{
+		{name: "no operations", ops: nil},
+		{name: "move to root", ops: []Operation{{Type: OperationMove}}, valid: true},
+		{name: "add tags", ops: []Operation{{Type: OperationAddTags, Tags: []string{"a"}}}, valid: true},
+		{name: "remove without tags", ops: []Operation{{Type: OperationRemoveTags}}},
+		{name: "replace data source", ops: []Operation{{Type: OperationReplaceDatasource, Datasource: &DatasourceReplacement{FromUID: "a", ToUID: "b"}}}, valid: true},
+		{name: "replace data source with itself", ops: []Operation{{Type: OperationReplaceDatasource, Datasource: &DatasourceReplacement{FromUID: "a", ToUID: "a"}}}},
+		{name: "replace data source without target", ops: []Operation{{Type: OperationReplaceDatasource, Datasource: &DatasourceReplacement{FromUID: "a"}}}},
+		{name: "set time", ops: []Operation{{Type: OperationSetTime, Time: &TimeSettings{Refresh: "1m"}}}, valid: true},
+		{name: "set empty time", ops: []Operation{{Type: OperationSetTime, Time: &TimeSettings{}}}},
+		{name: "delete", ops: []Operation{{Type: OperationDelete}}, valid: true},
+		{name: "delete with other operations", ops: []Operation{{Type: OperationDelete}, {Type: OperationMove}}},
+		{name: "unknown", ops: []Operation{{Type: "rename"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateOperations(tt.ops)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidOperation)
+			}
+		})
+	}
+}
+
+func TestApplyOperations(t *testing.T) {
+	t.Run("move, tags and time settings", func(t *testing.T) {
+		dash := testDashboard(t, `{"uid": "dash", "title": "Dash", "tags": ["prod", "old"], "time": {"from": "now-6h", "to": "now"}}`)
+
+		change := applyOperations(dash, []Operation{
+			{Type: OperationMove, FolderUID: "other"},
+			{Type: OperationAddTags, Tags: []string{"team-a", "prod"}},
+			{Type: OperationRemoveTags, Tags: []string{"old"}},
+			{Type: OperationSetTime, Time: &TimeSettings{From: "now-24h", Timezone: "utc"}},
+		})
+
+		require.NotNil(t, change)
+		assert.Equal(t, ActionUpdate, change.Action)
+		assert.Equal(t, "folder", change.FolderUID)
+		assert.Equal(t, []FieldChange{
+			{Field: "folderUid", Old: "folder", New: "other"},
+			{Field: "tags", Old: []string{"prod", "old"}, New: []string{"prod", "team-a"}},
+			{Field: "time.from", Old: "now-6h", New: "now-24h"},
+			{Field: "timezone", Old: "", New: "utc"},
+		}, change.Changes)
+
+		assert.Equal(t, "other", dash.FolderUID)
+		assert.Equal(t, []string{"prod", "team-a"}, dash.GetTags())
+		assert.Equal(t, "now-24h", dash.Data.GetPath("time", "from").MustString())
+		assert.Equal(t, "now", dash.Data.GetPath("time", "to").MustString())
+	})
+
+	t.Run("replace data source references", func(t *testing.T) {
+		dash := testDashboard(t, `{
+			"uid": "dash",
+			"title": "Dash",
+			"panels": [
+				{"id": 1, "datasource": {"uid": "old", "type": "prometheus"}, "targets": [{"refId": "A", "datasource": {"uid": "old"}}]},
+				{"id": 2, "datasource": "old"},
+				{"id": 3, "type": "row", "panels": [{"id": 4, "datasource": {"uid": "other"}}]}
+			],
+			"templating": {"list": [{"name": "job", "type": "query", "datasource": {"uid": "old", "type": "prometheus"}}]}
+		}`)
+
+		change := applyOperations(dash, []Operation{
+			{Type: OperationReplaceDatasource, Datasource: &DatasourceReplacement{FromUID: "old", ToUID: "new", ToType: "mimir"}},
+		})
+
+		require.NotNil(t, change)
+		assert.Equal(t, []FieldChange{{Field: "datasource", Old: "old", New: "new"}}, change.Changes)
+
+		panels := dash.Data.Get("panels")
+		assert.Equal(t, "new", panels.GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "mimir", panels.GetIndex(0).GetPath("datasource", "type").MustString())
+		assert.Equal(t, "new", panels.GetIndex(0).Get("targets").GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "new", panels.GetIndex(1).Get("datasource").MustString())
+		assert.Equal(t, "other", panels.GetIndex(2).Get("panels").GetIndex(0).GetPath("datasource", "uid").MustString())
+		assert.Equal(t, "new", dash.Data.GetPath("templating", "list").GetIndex(0).GetPath("datasource", "uid").MustString())
+	})
+
+	t.Run("no effect", func(t *testing.T) {
+		dash := testDashboard(t, `{"uid": "dash", "title": "Dash", "tags": ["prod"]}`)
+
+		change := applyOperations(dash, []Operation{
+			{Type: OperationMove, FolderUID: "folder"},
+			{Type: OperationAddTags, Tags: []string{"prod"}},
+			{Type: OperationReplaceDatasource, Datasource: &DatasourceReplacement{FromUID: "old", ToUID: "new"}},
+		})
+
+		assert.Nil(t, change)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		dash := testDashboard(t, `{"uid": "dash", "title": "Dash"}`)
+
+		change := applyOperations(dash, []Operation{{Type: OperationDelete}})
+
+		require.NotNil(t, change)
+		assert.Equal(t, ActionDelete, change.Action)
+		assert.Empty(t, change.Changes)
+	})
+}
@@ -0,0 +1,65 @@
+package dashboardlint
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *DashboardLintService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	canEdit := ac.EvalAny(
+		ac.EvalPermission(dashboards.ActionDashboardsCreate),
+		ac.EvalPermission(dashboards.ActionDashboardsWrite),
+	)
+
+	s.routeRegister.Post("/api/dashboards/lint", middleware.ReqSignedIn, authorize(canEdit), routing.Wrap(s.lintHandler))
+}
+
+// swagger:route POST /dashboards/lint dashboards lintDashboard
+//
+// Check a dashboard for problems.
+//
+// Validates the dashboard JSON against the dashboard schema and checks its references to data sources, library panels
+// and variables. Findings with the error severity break the dashboard.
+//
+// Responses:
+// 200: lintDashboardResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DashboardLintService) lintHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := LintRequest{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if cmd.Dashboard == nil {
+		return response.Err(ErrInvalidDashboard.Errorf("missing dashboard"))
+	}
+
+	report, err := s.Lint(c.Req.Context(), c.SignedInUser.GetOrgID(), cmd.Dashboard)
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:parameters lintDashboard
+type LintDashboardParams struct {
+	// in:body
+	// required:true
+	Body LintRequest `json:"body"`
+}
+
+// swagger:response lintDashboardResponse
+type LintDashboardResponse struct {
+	// in: body
+	Body Report `json:"body"`
+}
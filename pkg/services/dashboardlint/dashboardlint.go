@@ -0,0 +1,73 @@
+package dashboardlint
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// Service checks dashboard JSON for problems that break the dashboard or that should be fixed, such as references to
+// data sources that do not exist or panels sharing an id.
+type Service interface {
+	// Lint checks a dashboard against the schema and the semantic rules, resolving its references in the organization.
+	Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*Report, error)
+}
+
+type DashboardLintService struct {
+	db            db.DB
+	routeRegister routing.RouteRegister
+	accessControl accesscontrol.AccessControl
+}
+
+func ProvideService(sql db.DB, routeRegister routing.RouteRegister, accessControl accesscontrol.AccessControl) *DashboardLintService {
+	s := &DashboardLintService{
+		db:            sql,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+func (s *DashboardLintService) Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*Report, error) {
+	resources, err := LoadResources(ctx, s.db, orgID, dashboard)
+	if err != nil {
+		return nil, ErrInternal.Errorf("failed to load dashboard references: %w", err)
+	}
+	return Lint(dashboard, resources), nil
+}
+
+// LoadResources looks up the data sources of the organization and the library panels referenced by the dashboard.
+func LoadResources(ctx context.Context, sql db.DB, orgID int64, dashboard *simplejson.Json) (*Resources, error) {
+	lookup, err := kdash.LoadDatasourceLookup(ctx, orgID, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool)
+	if uids := LibraryPanelUIDs(dashboard); len(uids) > 0 {
+		found := make([]string, 0, len(uids))
+		err := sql.WithDbSession(ctx, func(sess *db.Session) error {
+			return sess.Table("library_element").
+				Cols("uid").
+				Where("org_id = ? AND kind = ?", orgID, int64(model.PanelElement)).
+				In("uid", uids).
+				Find(&found)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, uid := range found {
+			existing[uid] = true
+		}
+	}
+
+	return &Resources{DataSources: lookup, LibraryPanels: existing}, nil
+}
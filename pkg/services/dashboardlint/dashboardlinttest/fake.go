@@ -0,0 +1,29 @@
+package dashboardlinttest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+)
+
+var _ dashboardlint.Service = (*FakeService)(nil)
+
+type FakeService struct {
+	ExpectedReport *dashboardlint.Report
+	ExpectedError  error
+
+	LintedTitles []string
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) Lint(ctx context.Context, orgID int64, dashboard *simplejson.Json) (*dashboardlint.Report, error) {
+	f.LintedTitles = append(f.LintedTitles, dashboard.Get("title").MustString())
+	if f.ExpectedReport == nil {
+		return &dashboardlint.Report{}, f.ExpectedError
+	}
+	return f.ExpectedReport, f.ExpectedError
+}
@@ -0,0 +1,462 @@
+package dashboardlint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/kinds/dashboard"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// deprecatedPanels maps the panel types that are no longer supported to the panel replacing them
+var deprecatedPanels = map[string]string{
+	"graph":                    "timeseries",
+	"table-old":                "table",
+	"singlestat":               "stat",
+	"grafana-singlestat-panel": "stat",
+	"grafana-piechart-panel":   "piechart",
+	"grafana-worldmap-panel":   "geomap",
+}
+
+// variablePattern matches the $var, ${var}, ${var:format} and [[var]] syntaxes, it is the one used by the frontend
+var variablePattern = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+// macros are the data source macros that use the variable syntax without being dashboard variables
+var macros = map[string]bool{
+	"timeFilter":  true,
+	"interval":    true,
+	"interval_ms": true,
+	"col":         true,
+}
+
+// Resources resolves the references of a dashboard to resources of its organization. The rules that need a resource
+// are skipped when it is nil.
+type Resources struct {
+	DataSources kdash.DatasourceLookup
+	// LibraryPanels holds the uid of the library panels referenced by the dashboard that exist
+	LibraryPanels map[string]bool
+}
+
+// panel is a panel with its location in the dashboard JSON
+type panel struct {
+	path  string
+	model map[string]any
+}
+
+// Lint checks a dashboard against the dashboard kind schema and the semantic rules.
+func Lint(data *simplejson.Json, resources *Resources) *Report {
+	if resources == nil {
+		resources = &Resources{}
+	}
+
+	report := &Report{Findings: make([]Finding, 0)}
+	model, _ := data.Interface().(map[string]any)
+	if model == nil {
+		report.add(Finding{Rule: RuleSchema, Severity: SeverityError, Message: "dashboard must be a JSON object"})
+		return report
+	}
+
+	top, nested := panels(model)
+	all := append(append([]panel{}, top...), nested...)
+
+	checkSchema(report, model, all)
+	checkTitle(report, model)
+	checkPanelIDs(report, all)
+	checkDataSources(report, model, all, resources.DataSources)
+	checkVariables(report, model, all)
+	checkGridPos(report, top)
+	for _, row := range top {
+		if children := rowPanels(row); len(children) > 0 {
+			checkGridPos(report, children)
+		}
+	}
+	checkPanelTypes(report, all)
+	checkLibraryPanels(report, all, resources.LibraryPanels)
+
+	return report
+}
+
+// LibraryPanelUIDs returns the uid of the library panels referenced by a dashboard
+func LibraryPanelUIDs(data *simplejson.Json) []string {
+	model, _ := data.Interface().(map[string]any)
+	if model == nil {
+		return nil
+	}
+
+	top, nested := panels(model)
+	uids := make([]string, 0)
+	for _, p := range append(top, nested...) {
+		if uid := libraryPanelUID(p); uid != "" {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
+
+// panels returns the top level panels and the panels nested in collapsed rows
+func panels(model map[string]any) ([]panel, []panel) {
+	top := make([]panel, 0)
+	nested := make([]panel, 0)
+	list, _ := model["panels"].([]any)
+	for i, item := range list {
+		m, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		p := panel{path: fmt.Sprintf("panels[%d]", i), model: m}
+		top = append(top, p)
+		nested = append(nested, rowPanels(p)...)
+	}
+	return top, nested
+}
+
+func rowPanels(row panel) []panel {
+	children := make([]panel, 0)
+	if str(row.model, "type") != "row" {
+		return children
+	}
+	list, _ := row.model["panels"].([]any)
+	for i, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			children = append(children, panel{path: fmt.Sprintf("%s.panels[%d]", row.path, i), model: m})
+		}
+	}
+	return children
+}
+
+// checkSchema decodes the dashboard into the types generated from the dashboard kind. The schema only applies from
+// the schema version the frontend migrations hand off at, older dashboards are migrated when they are loaded.
+func checkSchema(report *Report, model map[string]any, all []panel) {
+	schemaVersion, _ := number(model["schemaVersion"])
+	if int(schemaVersion) < dashboard.HandoffSchemaVersion {
+		report.add(Finding{
+			Rule:     RuleSchemaVersion,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("schema version %d is older than %d, the dashboard is migrated when loaded and the schema is not checked", int(schemaVersion), dashboard.HandoffSchemaVersion),
+			Path:     "schemaVersion",
+		})
+		return
+	}
+
+	spec := make(map[string]any, len(model))
+	for key, value := range model {
+		spec[key] = value
+	}
+	// a disabled refresh used to be stored as false
+	if _, ok := spec["refresh"].(bool); ok {
+		delete(spec, "refresh")
+	}
+	decode(report, "", spec, &dashboard.Spec{})
+
+	for _, p := range all {
+		if str(p.model, "type") == "row" {
+			decode(report, p.path, p.model, &dashboard.RowPanel{})
+			continue
+		}
+		decode(report, p.path, p.model, &dashboard.Panel{})
+		if str(p.model, "type") == "" && libraryPanelUID(p) == "" {
+			report.add(Finding{Rule: RuleSchema, Severity: SeverityError, Message: "panel has no type", Path: p.path})
+		}
+	}
+}
+
+func decode(report *Report, path string, value any, target any) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		report.add(Finding{Rule: RuleSchema, Severity: SeverityError, Message: err.Error(), Path: path})
+		return
+	}
+
+	err = json.Unmarshal(body, target)
+	if err == nil {
+		return
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		report.add(Finding{
+			Rule:     RuleSchema,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value),
+			Path:     joinPath(path, typeErr.Field),
+		})
+		return
+	}
+	report.add(Finding{Rule: RuleSchema, Severity: SeverityError, Message: err.Error(), Path: path})
+}
+
+func checkTitle(report *Report, model map[string]any) {
+	if strings.TrimSpace(str(model, "title")) == "" {
+		report.add(Finding{Rule: RuleMissingTitle, Severity: SeverityError, Message: "dashboard has no title", Path: "title"})
+	}
+}
+
+func checkPanelIDs(report *Report, all []panel) {
+	seen := make(map[int64]string, len(all))
+	for _, p := range all {
+		id, ok := number(p.model["id"])
+		if !ok {
+			if libraryPanelUID(p) == "" {
+				report.add(Finding{Rule: RuleMissingPanelID, Severity: SeverityWarning, Message: "panel has no id", Path: p.path})
+			}
+			continue
+		}
+		if first, ok := seen[int64(id)]; ok {
+			report.add(Finding{
+				Rule:     RuleDuplicatePanelID,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("panel id %d is already used by %s", int64(id), first),
+				Path:     p.path,
+			})
+			continue
+		}
+		seen[int64(id)] = p.path
+	}
+}
+
+// checkDataSources reports the references to data sources that do not exist in the organization. References through
+// variables and the special mixed and dashboard data sources are not checked.
+func checkDataSources(report *Report, model map[string]any, all []panel, lookup kdash.DatasourceLookup) {
+	if lookup == nil {
+		return
+	}
+
+	check := func(path string, ref any) {
+		uid := dataSourceUID(ref)
+		if uid == "" || strings.HasPrefix(uid, "$") || uid == "-- Mixed --" || uid == "-- Dashboard --" || uid == "__expr__" {
+			return
+		}
+		if lookup.ByRef(&kdash.DataSourceRef{UID: uid}) == nil {
+			report.add(Finding{
+				Rule:     RuleUnknownDataSource,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("data source %q does not exist", uid),
+				Path:     path,
+			})
+		}
+	}
+
+	for _, p := range all {
+		check(p.path+".datasource", p.model["datasource"])
+		targets, _ := p.model["targets"].([]any)
+		for i, target := range targets {
+			if m, ok := target.(map[string]any); ok {
+				check(fmt.Sprintf("%s.targets[%d].datasource", p.path, i), m["datasource"])
+			}
+		}
+	}
+	for i, v := range listOf(model, "templating") {
+		check(fmt.Sprintf("templating.list[%d].datasource", i), v["datasource"])
+	}
+	for i, a := range listOf(model, "annotations") {
+		check(fmt.Sprintf("annotations.list[%d].datasource", i), a["datasource"])
+	}
+}
+
+// checkVariables reports the variables used by panels, queries and other variables that are not defined
+func checkVariables(report *Report, model map[string]any, all []panel) {
+	defined := make(map[string]bool)
+	variables := listOf(model, "templating")
+	for _, v := range variables {
+		defined[str(v, "name")] = true
+	}
+
+	check := func(path string, value any) {
+		for _, name := range variableNames(value) {
+			if defined[name] {
+				continue
+			}
+			report.add(Finding{
+				Rule:     RuleUndefinedVariable,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("variable %q is not defined", name),
+				Path:     path,
+			})
+		}
+	}
+
+	for _, p := range all {
+		check(p.path+".title", p.model["title"])
+		check(p.path+".datasource", p.model["datasource"])
+		check(p.path+".targets", p.model["targets"])
+		if repeat := str(p.model, "repeat"); repeat != "" && !defined[repeat] {
+			report.add(Finding{
+				Rule:     RuleUndefinedVariable,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("panel repeats over variable %q which is not defined", repeat),
+				Path:     p.path + ".repeat",
+			})
+		}
+	}
+	for i, v := range variables {
+		check(fmt.Sprintf("templating.list[%d].query", i), v["query"])
+		check(fmt.Sprintf("templating.list[%d].datasource", i), v["datasource"])
+	}
+}
+
+// variableNames returns the variables used in the strings of a JSON value, builtin variables and data source macros
+// are left out
+func variableNames(value any) []string {
+	names := make([]string, 0)
+	switch v := value.(type) {
+	case string:
+		for _, match := range variablePattern.FindAllStringSubmatch(v, -1) {
+			name := match[1] + match[2] + match[4]
+			if name == "" || strings.HasPrefix(name, "__") || macros[name] || strings.HasPrefix(name, "tag_") || isNumber(name) {
+				continue
+			}
+			names = append(names, name)
+		}
+	case map[string]any:
+		for _, item := range v {
+			names = append(names, variableNames(item)...)
+		}
+	case []any:
+		for _, item := range v {
+			names = append(names, variableNames(item)...)
+		}
+	}
+	return names
+}
+
+// checkGridPos reports the panels that overlap each other, panels without a position are placed by the frontend
+func checkGridPos(report *Report, list []panel) {
+	type rect struct{ x, y, w, h float64 }
+	rects := make([]*rect, len(list))
+	for i, p := range list {
+		pos, ok := p.model["gridPos"].(map[string]any)
+		if !ok {
+			continue
+		}
+		r := &rect{}
+		r.x, _ = number(pos["x"])
+		r.y, _ = number(pos["y"])
+		r.w, _ = number(pos["w"])
+		r.h, _ = number(pos["h"])
+		rects[i] = r
+	}
+
+	for i, a := range rects {
+		if a == nil {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			b := rects[j]
+			if b == nil {
+				continue
+			}
+			if a.x < b.x+b.w && b.x < a.x+a.w && a.y < b.y+b.h && b.y < a.y+a.h {
+				report.add(Finding{
+					Rule:     RuleOverlappingPanels,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("panel overlaps %s", list[j].path),
+					Path:     list[i].path + ".gridPos",
+				})
+			}
+		}
+	}
+}
+
+func checkPanelTypes(report *Report, all []panel) {
+	for _, p := range all {
+		panelType := str(p.model, "type")
+		if replacement, ok := deprecatedPanels[panelType]; ok {
+			report.add(Finding{
+				Rule:     RuleDeprecatedPanel,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("panel type %q is deprecated, use %q instead", panelType, replacement),
+				Path:     p.path + ".type",
+			})
+		}
+	}
+}
+
+func checkLibraryPanels(report *Report, all []panel, existing map[string]bool) {
+	if existing == nil {
+		return
+	}
+	for _, p := range all {
+		if uid := libraryPanelUID(p); uid != "" && !existing[uid] {
+			report.add(Finding{
+				Rule:     RuleUnknownLibraryPanel,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("library panel %q does not exist", uid),
+				Path:     p.path + ".libraryPanel",
+			})
+		}
+	}
+}
+
+func libraryPanelUID(p panel) string {
+	ref, _ := p.model["libraryPanel"].(map[string]any)
+	return str(ref, "uid")
+}
+
+// dataSourceUID returns the uid of an object reference or the uid or name of a legacy string reference
+func dataSourceUID(ref any) string {
+	switch v := ref.(type) {
+	case string:
+		return v
+	case map[string]any:
+		return str(v, "uid")
+	}
+	return ""
+}
+
+// listOf returns the items of the list held by a dashboard section such as templating or annotations
+func listOf(model map[string]any, section string) []map[string]any {
+	items := make([]map[string]any, 0)
+	container, _ := model[section].(map[string]any)
+	list, _ := container["list"].([]any)
+	for _, item := range list {
+		if m, ok := item.(map[string]any); ok {
+			items = append(items, m)
+		}
+	}
+	return items
+}
+
+func str(m map[string]any, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+// number returns the value of a JSON number, which depending on how the dashboard was decoded is a float64, a
+// json.Number or an integer
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPath(path, field string) string {
+	switch {
+	case path == "":
+		return field
+	case field == "":
+		return path
+	}
+	return path + "." + field
+}
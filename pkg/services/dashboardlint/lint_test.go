@@ -0,0 +1,200 @@
+package dashboardlint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+func lint(t *testing.T, body string, resources *Resources) *Report {
+	t.Helper()
+	data, err := simplejson.NewJson([]byte(body))
+	require.NoError(t, err)
+	return Lint(data, resources)
+}
+
+func findings(report *Report, rule string) []Finding {
+	found := make([]Finding, 0)
+	for _, f := range report.Findings {
+		if f.Rule == rule {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+func testResources() *Resources {
+	return &Resources{
+		DataSources: kdash.CreateDatasourceLookup([]*kdash.DatasourceQueryResult{
+			{UID: "prom", Type: "prometheus", Name: "Prometheus", IsDefault: true},
+		}),
+		LibraryPanels: map[string]bool{"lib": true},
+	}
+}
+
+func TestLint(t *testing.T) {
+	t.Run("valid dashboard", func(t *testing.T) {
+		report := lint(t, `{
+			"title": "Valid",
+			"schemaVersion": 39,
+			"templating": {"list": [{"name": "job", "type": "query", "datasource": {"uid": "prom"}, "query": "label_values(job)"}]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "Requests $job", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8},
+					"datasource": {"uid": "prom"}, "targets": [{"refId": "A", "expr": "rate(x{job=\"$job\"}[$__rate_interval])"}]},
+				{"id": 2, "libraryPanel": {"uid": "lib", "name": "Library"}, "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 3, "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 8, "w": 24, "h": 1}, "panels": [
+					{"id": 4, "type": "stat", "datasource": {"uid": "-- Mixed --"}, "gridPos": {"x": 0, "y": 9, "w": 24, "h": 8}}
+				]}
+			]
+		}`, testResources())
+
+		assert.Empty(t, report.Findings)
+		assert.False(t, report.HasErrors())
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		report := lint(t, `[]`, nil)
+
+		require.Len(t, report.Findings, 1)
+		assert.Equal(t, RuleSchema, report.Findings[0].Rule)
+		assert.True(t, report.HasErrors())
+	})
+
+	t.Run("old schema version is not checked against the schema", func(t *testing.T) {
+		report := lint(t, `{"title": "Old", "schemaVersion": 16, "panels": [{"id": 1, "type": "graph", "datasource": "Prometheus"}]}`, nil)
+
+		assert.Len(t, findings(report, RuleSchemaVersion), 1)
+		assert.Empty(t, findings(report, RuleSchema))
+		assert.Equal(t, 0, report.Errors)
+	})
+
+	t.Run("schema", func(t *testing.T) {
+		report := lint(t, `{"title": "Schema", "schemaVersion": 39, "panels": [{"id": 1, "type": "stat", "gridPos": "top"}, {"id": 2}]}`, nil)
+
+		schema := findings(report, RuleSchema)
+		require.Len(t, schema, 2)
+		assert.Equal(t, "panels[0].gridPos", schema[0].Path)
+		assert.Equal(t, Finding{Rule: RuleSchema, Severity: SeverityError, Message: "panel has no type", Path: "panels[1]"}, schema[1])
+	})
+
+	t.Run("missing title", func(t *testing.T) {
+		report := lint(t, `{"title": " ", "schemaVersion": 39}`, nil)
+
+		assert.Equal(t, []Finding{{Rule: RuleMissingTitle, Severity: SeverityError, Message: "dashboard has no title", Path: "title"}}, report.Findings)
+	})
+
+	t.Run("panel ids", func(t *testing.T) {
+		report := lint(t, `{"title": "Ids", "schemaVersion": 39, "panels": [
+			{"id": 1, "type": "text"},
+			{"type": "text"},
+			{"id": 3, "type": "row", "panels": [{"id": 1, "type": "text"}]}
+		]}`, nil)
+
+		assert.Equal(t, []Finding{{Rule: RuleMissingPanelID, Severity: SeverityWarning, Message: "panel has no id", Path: "panels[1]"}}, findings(report, RuleMissingPanelID))
+		assert.Equal(t, []Finding{{
+			Rule:     RuleDuplicatePanelID,
+			Severity: SeverityError,
+			Message:  "panel id 1 is already used by panels[0]",
+			Path:     "panels[2].panels[0]",
+		}}, findings(report, RuleDuplicatePanelID))
+	})
+
+	t.Run("unknown data sources", func(t *testing.T) {
+		report := lint(t, `{
+			"title": "Data sources",
+			"schemaVersion": 39,
+			"annotations": {"list": [{"name": "Annotations", "datasource": {"uid": "grafana"}}]},
+			"templating": {"list": [{"name": "ds", "type": "datasource", "query": "prometheus"}, {"name": "job", "datasource": {"uid": "missing"}}]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "datasource": {"uid": "$ds"}, "targets": [{"refId": "A", "datasource": {"uid": "gone"}}]},
+				{"id": 2, "type": "timeseries", "datasource": {"uid": "Prometheus"}, "targets": [{"refId": "A", "datasource": {"uid": "__expr__"}}]}
+			]
+		}`, testResources())
+
+		unknown := findings(report, RuleUnknownDataSource)
+		require.Len(t, unknown, 2)
+		assert.Equal(t, "panels[0].targets[0].datasource", unknown[0].Path)
+		assert.Equal(t, "templating.list[1].datasource", unknown[1].Path)
+		assert.Equal(t, 2, report.Errors)
+	})
+
+	t.Run("undefined variables", func(t *testing.T) {
+		report := lint(t, `{
+			"title": "Variables",
+			"schemaVersion": 39,
+			"templating": {"list": [{"name": "env", "query": "label_values(up{env=\"$region\"}, env)"}]},
+			"panels": [
+				{"id": 1, "type": "timeseries", "title": "${env} [[host]]", "repeat": "pod",
+					"targets": [{"refId": "A", "rawSql": "SELECT 1 WHERE $__timeFilter(time) AND $timeFilter AND $1"}]}
+			]
+		}`, nil)
+
+		undefined := findings(report, RuleUndefinedVariable)
+		require.Len(t, undefined, 3)
+		assert.Equal(t, "panels[0].title", undefined[0].Path)
+		assert.Equal(t, `variable "host" is not defined`, undefined[0].Message)
+		assert.Equal(t, "panels[0].repeat", undefined[1].Path)
+		assert.Equal(t, "templating.list[0].query", undefined[2].Path)
+		assert.Equal(t, `variable "region" is not defined`, undefined[2].Message)
+		assert.Equal(t, 0, report.Errors)
+	})
+
+	t.Run("overlapping panels", func(t *testing.T) {
+		report := lint(t, `{"title": "Grid", "schemaVersion": 39, "panels": [
+			{"id": 1, "type": "text", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+			{"id": 2, "type": "text", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+			{"id": 3, "type": "text", "gridPos": {"x": 6, "y": 4, "w": 12, "h": 8}},
+			{"id": 4, "type": "row", "gridPos": {"x": 0, "y": 12, "w": 24, "h": 1}, "panels": [
+				{"id": 5, "type": "text", "gridPos": {"x": 0, "y": 13, "w": 12, "h": 8}},
+				{"id": 6, "type": "text", "gridPos": {"x": 0, "y": 20, "w": 12, "h": 8}}
+			]}
+		]}`, nil)
+
+		overlapping := findings(report, RuleOverlappingPanels)
+		require.Len(t, overlapping, 3)
+		assert.Equal(t, Finding{Rule: RuleOverlappingPanels, Severity: SeverityWarning, Message: "panel overlaps panels[0]", Path: "panels[2].gridPos"}, overlapping[0])
+		assert.Equal(t, "panel overlaps panels[1]", overlapping[1].Message)
+		assert.Equal(t, "panels[3].panels[1].gridPos", overlapping[2].Path)
+	})
+
+	t.Run("deprecated panels", func(t *testing.T) {
+		report := lint(t, `{"title": "Deprecated", "schemaVersion": 39, "panels": [{"id": 1, "type": "graph"}, {"id": 2, "type": "timeseries"}]}`, nil)
+
+		assert.Equal(t, []Finding{{
+			Rule:     RuleDeprecatedPanel,
+			Severity: SeverityWarning,
+			Message:  `panel type "graph" is deprecated, use "timeseries" instead`,
+			Path:     "panels[0].type",
+		}}, findings(report, RuleDeprecatedPanel))
+	})
+
+	t.Run("unknown library panels", func(t *testing.T) {
+		body := `{"title": "Library", "schemaVersion": 39, "panels": [{"id": 1, "libraryPanel": {"uid": "lib"}}, {"id": 2, "libraryPanel": {"uid": "deleted"}}]}`
+
+		report := lint(t, body, testResources())
+		assert.Equal(t, []Finding{{
+			Rule:     RuleUnknownLibraryPanel,
+			Severity: SeverityError,
+			Message:  `library panel "deleted" does not exist`,
+			Path:     "panels[1].libraryPanel",
+		}}, report.Findings)
+
+		report = lint(t, body, nil)
+		assert.Empty(t, report.Findings)
+	})
+}
+
+func TestLibraryPanelUIDs(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{"panels": [
+		{"id": 1, "libraryPanel": {"uid": "a"}},
+		{"id": 2, "type": "text"},
+		{"id": 3, "type": "row", "panels": [{"id": 4, "libraryPanel": {"uid": "b"}}]}
+	]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"a", "b"}, LibraryPanelUIDs(data))
+}
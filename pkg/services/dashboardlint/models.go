@@ -0,0 +1,71 @@
+package dashboardlint
+
+import (
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrInvalidDashboard = errutil.BadRequest("dashboardlint.invalidDashboard", errutil.WithPublicMessage("A dashboard JSON object is required"))
+	ErrInternal         = errutil.Internal("dashboardlint.internal")
+)
+
+// Severity tells how serious a finding is. Dashboards with error findings are broken, the others still load but
+// should be fixed.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Rules identify the check that produced a finding
+const (
+	RuleSchema              = "schema"
+	RuleSchemaVersion       = "schema-version"
+	RuleMissingTitle        = "missing-title"
+	RuleDuplicatePanelID    = "duplicate-panel-id"
+	RuleMissingPanelID      = "missing-panel-id"
+	RuleUnknownDataSource   = "unknown-datasource"
+	RuleUndefinedVariable   = "undefined-variable"
+	RuleOverlappingPanels   = "overlapping-panels"
+	RuleDeprecatedPanel     = "deprecated-panel"
+	RuleUnknownLibraryPanel = "unknown-library-panel"
+)
+
+// Finding is a single problem found in a dashboard
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	// Path is the location of the problem in the dashboard JSON, e.g. panels[2].targets[0]
+	Path string `json:"path,omitempty"`
+}
+
+// Report lists the findings of a dashboard in the order they were found
+type Report struct {
+	Findings []Finding `json:"findings"`
+	Errors   int       `json:"errors"`
+	Warnings int       `json:"warnings"`
+}
+
+func (r *Report) add(f Finding) {
+	r.Findings = append(r.Findings, f)
+	switch f.Severity {
+	case SeverityError:
+		r.Errors++
+	case SeverityWarning:
+		r.Warnings++
+	}
+}
+
+// HasErrors reports whether the dashboard has at least one error finding
+func (r *Report) HasErrors() bool {
+	return r.Errors > 0
+}
+
+// LintRequest is the body of a lint request
+type LintRequest struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+}
@@ -5,3 +5,5 @@ import (
 )
 
 var ErrBaseNotFound = errutil.NotFound("dashboardsnapshots.not-found", errutil.WithPublicMessage("Snapshot not found"))
+
+var ErrInvalidTimeRange = errutil.BadRequest("dashboardsnapshots.invalid-time-range")
@@ -0,0 +1,475 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// GenerateDashboardSnapshotCommand requests a snapshot of a stored dashboard where the
+// panel data is queried by the server instead of being posted by the browser.
+type GenerateDashboardSnapshotCommand struct {
+	// UID of the dashboard to snapshot
+	// required: true
+	DashboardUID string `json:"dashboardUid" binding:"Required"`
+	// Snapshot name, defaults to the dashboard title
+	Name string `json:"name"`
+	// Start of the time range, absolute or relative. Defaults to the dashboard time range.
+	From string `json:"from"`
+	// End of the time range, absolute or relative. Defaults to the dashboard time range.
+	To string `json:"to"`
+	// Template variable values by variable name. Variables that are not set keep their current value.
+	Variables map[string][]string `json:"variables"`
+	// When the snapshot should expire in seconds. Default is never to expire.
+	Expires int64 `json:"expires"`
+}
+
+// QueryDataFunc executes the queries of a single panel for the given absolute time range,
+// expressed in epoch milliseconds.
+type QueryDataFunc func(ctx context.Context, from, to string, queries []*simplejson.Json) (*backend.QueryDataResponse, error)
+
+// dashboardDatasourceUID is the datasource that reuses the results of another panel.
+const dashboardDatasourceUID = "-- Dashboard --"
+
+// PanelData holds the query results of a single dashboard panel.
+type PanelData struct {
+	ID     int64
+	Title  string
+	Frames data.Frames
+}
+
+// GenerateSnapshotDashboard queries every panel of the dashboard and returns a copy of it with the
+// results embedded as snapshot data, in the same shape the frontend produces when sharing a snapshot.
+// Panels whose queries fail are kept with the error as a notice of their snapshot data.
+func GenerateSnapshotDashboard(ctx context.Context, dashboard *simplejson.Json, cmd GenerateDashboardSnapshotCommand, queryData QueryDataFunc) (*common.Unstructured, error) {
+	// work on a copy so the stored dashboard is never modified
+	dash, err := copyDashboard(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fromTime, toTime, err := snapshotTimeRange(dash, cmd, now)
+	if err != nil {
+		return nil, err
+	}
+	variables := snapshotVariables(dash, cmd)
+
+	for _, panel := range flattenPanels(dash) {
+		frames, err := queryPanel(ctx, panel, fromTime, toTime, now, variables, queryData)
+		if err != nil {
+			frames = append(frames, errorFrame(err))
+		}
+		if frames != nil {
+			panel.Set("snapshotData", frames)
+		}
+
+		// the snapshot must not leak how the data was queried
+		panel.Set("targets", []any{})
+		panel.Set("links", []any{})
+		panel.Set("datasource", nil)
+	}
+
+	scrubTemplating(dash, variables)
+	dash.Set("time", map[string]any{
+		"from": fromTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+		"to":   toTime.UTC().Format("2006-01-02T15:04:05.000Z"),
+	})
+	dash.Del("id")
+
+	// round trip through JSON so the frames are stored as plain JSON values
+	raw, err := dash.Encode()
+	if err != nil {
+		return nil, err
+	}
+	result := &common.Unstructured{}
+	if err := json.Unmarshal(raw, &result.Object); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryDashboardPanels queries every panel of the dashboard like GenerateSnapshotDashboard does
+// and returns the results of the panels that have queries, in dashboard order.
+func QueryDashboardPanels(ctx context.Context, dashboard *simplejson.Json, cmd GenerateDashboardSnapshotCommand, queryData QueryDataFunc) ([]PanelData, error) {
+	dash, err := copyDashboard(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	fromTime, toTime, err := snapshotTimeRange(dash, cmd, now)
+	if err != nil {
+		return nil, err
+	}
+	variables := snapshotVariables(dash, cmd)
+
+	var panels []PanelData
+	for _, panel := range flattenPanels(dash) {
+		frames, err := queryPanel(ctx, panel, fromTime, toTime, now, variables, queryData)
+		if err != nil {
+			return nil, err
+		}
+		if frames == nil {
+			continue
+		}
+		panels = append(panels, PanelData{
+			ID:     panel.Get("id").MustInt64(),
+			Title:  InterpolateVariables(panel.Get("title").MustString(), variables),
+			Frames: frames,
+		})
+	}
+	return panels, nil
+}
+
+func copyDashboard(dashboard *simplejson.Json) (*simplejson.Json, error) {
+	raw, err := dashboard.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return simplejson.NewJson(raw)
+}
+
+// snapshotTimeRange resolves the time range of the command, defaulting to the one of the dashboard.
+func snapshotTimeRange(dash *simplejson.Json, cmd GenerateDashboardSnapshotCommand, now time.Time) (time.Time, time.Time, error) {
+	from, to := cmd.From, cmd.To
+	if from == "" {
+		from = dash.GetPath("time", "from").MustString("now-6h")
+	}
+	if to == "" {
+		to = dash.GetPath("time", "to").MustString("now")
+	}
+	timeRange := legacydata.DataTimeRange{From: from, To: to, Now: now}
+	fromTime, err := timeRange.ParseFrom()
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidTimeRange.Errorf("invalid from time %q: %w", from, err)
+	}
+	toTime, err := timeRange.ParseTo()
+	if err != nil {
+		return time.Time{}, time.Time{}, ErrInvalidTimeRange.Errorf("invalid to time %q: %w", to, err)
+	}
+	if !fromTime.Before(toTime) {
+		return time.Time{}, time.Time{}, ErrInvalidTimeRange.Errorf("invalid time range: from must be before to")
+	}
+	return fromTime, toTime, nil
+}
+
+// panelTimeRange applies the relative time and time shift overrides of a panel to the dashboard time range,
+// like the frontend does: the relative time replaces the range and the time shift moves it back.
+func panelTimeRange(panel *simplejson.Json, from, to, now time.Time, variables map[string][]string) (time.Time, time.Time, error) {
+	if timeFrom := strings.TrimSpace(InterpolateVariables(panel.Get("timeFrom").MustString(), variables)); timeFrom != "" {
+		relativeFrom, relativeTo := "now-"+timeFrom, "now"
+		if strings.HasPrefix(timeFrom, "now") {
+			// rounded ranges like now/d cover the whole unit
+			relativeFrom = timeFrom
+			if strings.Contains(timeFrom, "/") {
+				relativeTo = timeFrom
+			}
+		}
+		timeRange := legacydata.DataTimeRange{From: relativeFrom, To: relativeTo, Now: now}
+		var err error
+		if from, err = timeRange.ParseFrom(); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid relative time %q: %w", timeFrom, err)
+		}
+		if to, err = timeRange.ParseTo(); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid relative time %q: %w", timeFrom, err)
+		}
+	}
+
+	if timeShift := strings.TrimSpace(InterpolateVariables(panel.Get("timeShift").MustString(), variables)); timeShift != "" {
+		shift, err := gtime.ParseDuration(timeShift)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid time shift %q: %w", timeShift, err)
+		}
+		from, to = from.Add(-shift), to.Add(-shift)
+	}
+	return from, to, nil
+}
+
+// snapshotVariables returns the current values of the dashboard variables overridden by the ones of the command.
+func snapshotVariables(dash *simplejson.Json, cmd GenerateDashboardSnapshotCommand) map[string][]string {
+	variables := dashboardVariableValues(dash)
+	for name, values := range cmd.Variables {
+		variables[name] = values
+	}
+	return variables
+}
+
+// queryPanel executes the queries of a panel, it returns nil frames when the panel has nothing to query.
+// When some of the queries fail the frames of the other queries are returned along with the error.
+func queryPanel(ctx context.Context, panel *simplejson.Json, from, to, now time.Time, variables map[string][]string, queryData QueryDataFunc) (data.Frames, error) {
+	queries := panelQueries(panel, variables)
+	if len(queries) == 0 {
+		return nil, nil
+	}
+
+	from, to, err := panelTimeRange(panel, from, to, now, variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query panel %d: %w", panel.Get("id").MustInt64(), err)
+	}
+
+	fromMs := strconv.FormatInt(from.UnixMilli(), 10)
+	toMs := strconv.FormatInt(to.UnixMilli(), 10)
+	resp, err := queryData(ctx, fromMs, toMs, queries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query panel %d: %w", panel.Get("id").MustInt64(), err)
+	}
+	frames, err := responseFrames(resp, queries)
+	if err != nil {
+		return frames, fmt.Errorf("failed to query panel %d: %w", panel.Get("id").MustInt64(), err)
+	}
+	return frames, nil
+}
+
+// errorFrame returns an empty frame with the error as a notice, which the panel shows like a query error.
+func errorFrame(err error) *data.Frame {
+	return data.NewFrame("").SetMeta(&data.FrameMeta{
+		Notices: []data.Notice{{Severity: data.NoticeSeverityError, Text: err.Error()}},
+	})
+}
+
+// flattenPanels returns all panels of the dashboard including the ones nested in collapsed rows.
+func flattenPanels(dash *simplejson.Json) []*simplejson.Json {
+	var panels []*simplejson.Json
+	for _, panelObj := range dash.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(panelObj)
+		if panel.Get("type").MustString() == "row" {
+			for _, nested := range panel.Get("panels").MustArray() {
+				panels = append(panels, simplejson.NewFromAny(nested))
+			}
+			continue
+		}
+		panels = append(panels, panel)
+	}
+	return panels
+}
+
+// panelQueries returns the interpolated queries of a panel that should be executed.
+func panelQueries(panel *simplejson.Json, variables map[string][]string) []*simplejson.Json {
+	panelDatasource := panel.Get("datasource").Interface()
+	if datasourceUID(panel) == dashboardDatasourceUID {
+		return nil
+	}
+
+	hasExpression := false
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		if datasourceUID(simplejson.NewFromAny(queryObj)) == "__expr__" {
+			hasExpression = true
+		}
+	}
+
+	var queries []*simplejson.Json
+	for _, queryObj := range panel.Get("targets").MustArray() {
+		query := simplejson.NewFromAny(queryObj)
+		// hidden queries are kept when expressions may depend on them, the expression handler drops them
+		if !hasExpression && query.Get("hide").MustBool() {
+			continue
+		}
+		if _, ok := query.CheckGet("datasource"); !ok {
+			query.Set("datasource", panelDatasource)
+		}
+
+		interpolated, err := simplejson.NewFromAny(interpolateValue(query.Interface(), variables)).Encode()
+		if err != nil {
+			continue
+		}
+		q, err := simplejson.NewJson(interpolated)
+		if err != nil {
+			continue
+		}
+		queries = append(queries, q)
+	}
+	return queries
+}
+
+func datasourceUID(obj *simplejson.Json) string {
+	uid := obj.Get("datasource").Get("uid").MustString()
+	// before 8.3 the datasource was referenced by name
+	if uid == "" {
+		uid = obj.Get("datasource").MustString()
+	}
+	return uid
+}
+
+// responseFrames returns the frames of the response ordered as the panel queries, along with the errors
+// of the failed queries.
+func responseFrames(resp *backend.QueryDataResponse, queries []*simplejson.Json) (data.Frames, error) {
+	frames := data.Frames{}
+	if resp == nil {
+		return frames, nil
+	}
+	var errs []error
+	for _, query := range queries {
+		refID := query.Get("refId").MustString("A")
+		res, ok := resp.Responses[refID]
+		if !ok {
+			continue
+		}
+		if res.Error != nil {
+			errs = append(errs, fmt.Errorf("query %s: %w", refID, res.Error))
+			continue
+		}
+		for _, frame := range res.Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			frames = append(frames, frame)
+		}
+	}
+	return frames, errors.Join(errs...)
+}
+
+// dashboardVariableValues returns the current values of the dashboard template variables.
+func dashboardVariableValues(dash *simplejson.Json) map[string][]string {
+	values := make(map[string][]string)
+	for _, variableObj := range dash.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+		if name == "" {
+			continue
+		}
+		current := variable.GetPath("current", "value")
+		if arr, err := current.StringArray(); err == nil {
+			values[name] = arr
+		} else if s, err := current.String(); err == nil {
+			values[name] = []string{s}
+		}
+	}
+	return values
+}
+
+// scrubTemplating sets the variables to the values used for the snapshot and removes their queries.
+func scrubTemplating(dash *simplejson.Json, variables map[string][]string) {
+	for _, variableObj := range dash.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		values, ok := variables[variable.Get("name").MustString()]
+		if !ok {
+			continue
+		}
+
+		var current map[string]any
+		if len(values) == 1 {
+			current = map[string]any{"text": values[0], "value": values[0], "selected": true}
+		} else {
+			current = map[string]any{"text": strings.Join(values, " + "), "value": values, "selected": true}
+		}
+		variable.Set("current", current)
+		variable.Set("options", []any{current})
+		if variable.Get("type").MustString() == "query" {
+			variable.Set("query", "")
+			// never refresh
+			variable.Set("refresh", 0)
+		}
+	}
+}
+
+// interpolateValue replaces template variables in every string of the query model.
+func interpolateValue(value any, variables map[string][]string) any {
+	switch v := value.(type) {
+	case string:
+		return InterpolateVariables(v, variables)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, item := range v {
+			out[key] = interpolateValue(item, variables)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = interpolateValue(item, variables)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// variableRegex matches $var, ${var}, ${var:format} and [[var]] or [[var:format]], like the frontend template service.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\${(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?}`)
+
+// InterpolateVariables replaces template variable references in the text. Unknown variables are left as is.
+// Multiple values are formatted as a glob unless another format is requested.
+func InterpolateVariables(text string, variables map[string][]string) string {
+	return variableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[6]
+		}
+
+		values, ok := variables[name]
+		if !ok {
+			return match
+		}
+		return formatVariableValues(values, format)
+	})
+}
+
+func formatVariableValues(values []string, format string) string {
+	if len(values) == 1 && format != "regex" && format != "json" {
+		switch format {
+		case "singlequote":
+			return "'" + strings.ReplaceAll(values[0], "'", "\\'") + "'"
+		case "doublequote":
+			return `"` + strings.ReplaceAll(values[0], `"`, `\"`) + `"`
+		default:
+			return values[0]
+		}
+	}
+
+	switch format {
+	case "csv", "raw":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = regexp.QuoteMeta(value)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = "'" + strings.ReplaceAll(value, "'", "\\'") + "'"
+		}
+		return strings.Join(quoted, ",")
+	case "doublequote":
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+		}
+		return strings.Join(quoted, ",")
+	case "json":
+		b, err := json.Marshal(values)
+		if err != nil {
+			return strings.Join(values, ",")
+		}
+		return string(b)
+	default:
+		if len(values) == 0 {
+			return ""
+		}
+		return "{" + strings.Join(values, ",") + "}"
+	}
+}
@@ -0,0 +1,223 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const generateTestDashboard = `{
+	"id": 12,
+	"uid": "snap-dash",
+	"title": "Test dashboard",
+	"time": {"from": "now-1h", "to": "now"},
+	"templating": {"list": [
+		{"name": "host", "type": "query", "query": "label_values(host)", "refresh": 1, "current": {"text": "a", "value": "a"}},
+		{"name": "env", "type": "custom", "current": {"text": "prod", "value": "prod"}}
+	]},
+	"panels": [
+		{"id": 1, "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}, "links": [{"url": "http://example.com"}],
+			"targets": [{"refId": "A", "expr": "up{host=~\"${host:regex}\", env=\"$env\"}"}, {"refId": "B", "expr": "hidden", "hide": true}]},
+		{"id": 2, "type": "text"},
+		{"id": 3, "type": "row", "collapsed": true, "panels": [
+			{"id": 4, "type": "stat", "datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"refId": "A", "expr": "[[env]]"}]}
+		]}
+	]
+}`
+
+func TestGenerateSnapshotDashboard(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(generateTestDashboard))
+	require.NoError(t, err)
+
+	var calls [][]*simplejson.Json
+	queryData := func(_ context.Context, from, to string, queries []*simplejson.Json) (*backend.QueryDataResponse, error) {
+		require.NotEmpty(t, from)
+		require.NotEmpty(t, to)
+		calls = append(calls, queries)
+		resp := backend.NewQueryDataResponse()
+		for _, q := range queries {
+			refID := q.Get("refId").MustString()
+			resp.Responses[refID] = backend.DataResponse{
+				Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))},
+			}
+		}
+		return resp, nil
+	}
+
+	result, err := GenerateSnapshotDashboard(context.Background(), dash, GenerateDashboardSnapshotCommand{
+		DashboardUID: "snap-dash",
+		Variables:    map[string][]string{"host": {"a.example", "b"}},
+	}, queryData)
+	require.NoError(t, err)
+
+	require.Len(t, calls, 2)
+	require.Len(t, calls[0], 1)
+	require.Equal(t, `up{host=~"(a\.example|b)", env="prod"}`, calls[0][0].Get("expr").MustString())
+	require.Equal(t, "prom", calls[0][0].GetPath("datasource", "uid").MustString())
+	require.Equal(t, "prod", calls[1][0].Get("expr").MustString())
+
+	snapshot := simplejson.NewFromAny(result.Object)
+	_, hasID := snapshot.CheckGet("id")
+	require.False(t, hasID)
+	require.Equal(t, "snap-dash", snapshot.Get("uid").MustString())
+	require.NotEqual(t, "now-1h", snapshot.GetPath("time", "from").MustString())
+
+	panel := snapshot.Get("panels").GetIndex(0)
+	require.Empty(t, panel.Get("targets").MustArray())
+	require.Empty(t, panel.Get("links").MustArray())
+	require.Nil(t, panel.Get("datasource").Interface())
+	frames := panel.Get("snapshotData").MustArray()
+	require.Len(t, frames, 1)
+	require.Equal(t, "A", simplejson.NewFromAny(frames[0]).GetPath("schema", "refId").MustString())
+
+	nested := snapshot.Get("panels").GetIndex(2).Get("panels").GetIndex(0)
+	require.Len(t, nested.Get("snapshotData").MustArray(), 1)
+
+	host := snapshot.GetPath("templating", "list").GetIndex(0)
+	require.Equal(t, []any{"a.example", "b"}, host.GetPath("current", "value").MustArray())
+	require.Equal(t, "", host.Get("query").MustString())
+	require.Equal(t, 0, host.Get("refresh").MustInt())
+
+	// the stored dashboard is not modified
+	require.Equal(t, int64(12), dash.Get("id").MustInt64())
+	require.Len(t, dash.Get("panels").GetIndex(0).Get("targets").MustArray(), 2)
+}
+
+func TestQueryDashboardPanels(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(generateTestDashboard))
+	require.NoError(t, err)
+
+	panels, err := QueryDashboardPanels(context.Background(), dash, GenerateDashboardSnapshotCommand{DashboardUID: "snap-dash"},
+		func(_ context.Context, _, _ string, queries []*simplejson.Json) (*backend.QueryDataResponse, error) {
+			resp := backend.NewQueryDataResponse()
+			for _, q := range queries {
+				resp.Responses[q.Get("refId").MustString()] = backend.DataResponse{
+					Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))},
+				}
+			}
+			return resp, nil
+		})
+	require.NoError(t, err)
+
+	// the text panel has no queries
+	require.Len(t, panels, 2)
+	require.Equal(t, int64(1), panels[0].ID)
+	require.Equal(t, int64(4), panels[1].ID)
+	require.Len(t, panels[0].Frames, 1)
+	require.Equal(t, "A", panels[0].Frames[0].RefID)
+
+	// the stored dashboard is not modified
+	_, hasDatasource := dash.Get("panels").GetIndex(2).Get("panels").GetIndex(0).Get("targets").GetIndex(0).CheckGet("datasource")
+	require.False(t, hasDatasource)
+}
+
+func TestGenerateSnapshotDashboard_QueryError(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(generateTestDashboard))
+	require.NoError(t, err)
+
+	result, err := GenerateSnapshotDashboard(context.Background(), dash, GenerateDashboardSnapshotCommand{DashboardUID: "snap-dash"},
+		func(_ context.Context, _, _ string, queries []*simplejson.Json) (*backend.QueryDataResponse, error) {
+			resp := backend.NewQueryDataResponse()
+			if strings.HasPrefix(queries[0].Get("expr").MustString(), "up") {
+				resp.Responses["A"] = backend.DataResponse{Error: context.DeadlineExceeded}
+				return resp, nil
+			}
+			resp.Responses["A"] = backend.DataResponse{
+				Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1}))},
+			}
+			return resp, nil
+		})
+	require.NoError(t, err)
+
+	// the failing panel keeps the error, the other panels are snapshotted
+	snapshot := simplejson.NewFromAny(result.Object)
+	frames := snapshot.Get("panels").GetIndex(0).Get("snapshotData").MustArray()
+	require.Len(t, frames, 1)
+	notices := simplejson.NewFromAny(frames[0]).GetPath("schema", "meta", "notices")
+	require.Equal(t, "error", notices.GetIndex(0).Get("severity").MustString())
+	require.Contains(t, notices.GetIndex(0).Get("text").MustString(), context.DeadlineExceeded.Error())
+
+	nested := snapshot.Get("panels").GetIndex(2).Get("panels").GetIndex(0)
+	require.Len(t, nested.Get("snapshotData").MustArray(), 1)
+	_, hasMeta := simplejson.NewFromAny(nested.Get("snapshotData").MustArray()[0]).GetPath("schema").CheckGet("meta")
+	require.False(t, hasMeta)
+}
+
+func TestGenerateSnapshotDashboard_InvalidTimeRange(t *testing.T) {
+	dash, err := simplejson.NewJson([]byte(generateTestDashboard))
+	require.NoError(t, err)
+
+	_, err = GenerateSnapshotDashboard(context.Background(), dash, GenerateDashboardSnapshotCommand{From: "now", To: "now-1h"}, nil)
+	require.ErrorIs(t, err, ErrInvalidTimeRange)
+}
+
+func TestPanelTimeRange(t *testing.T) {
+	now := time.Date(2024, 5, 10, 12, 30, 0, 0, time.UTC)
+	from, to := now.Add(-6*time.Hour), now
+
+	tests := []struct {
+		name         string
+		panel        string
+		expectedFrom time.Time
+		expectedTo   time.Time
+	}{
+		{name: "no overrides", panel: `{}`, expectedFrom: from, expectedTo: to},
+		{name: "relative time", panel: `{"timeFrom": "2h"}`, expectedFrom: now.Add(-2 * time.Hour), expectedTo: now},
+		{name: "relative time from variable", panel: `{"timeFrom": "$range"}`, expectedFrom: now.Add(-30 * time.Minute), expectedTo: now},
+		{name: "time shift", panel: `{"timeShift": "1d"}`, expectedFrom: from.Add(-24 * time.Hour), expectedTo: to.Add(-24 * time.Hour)},
+		{name: "relative time and time shift", panel: `{"timeFrom": "1h", "timeShift": "1h"}`, expectedFrom: now.Add(-2 * time.Hour), expectedTo: now.Add(-time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			panel, err := simplejson.NewJson([]byte(tt.panel))
+			require.NoError(t, err)
+
+			panelFrom, panelTo, err := panelTimeRange(panel, from, to, now, map[string][]string{"range": {"30m"}})
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedFrom, panelFrom.UTC())
+			require.Equal(t, tt.expectedTo, panelTo.UTC())
+		})
+	}
+
+	panel, err := simplejson.NewJson([]byte(`{"timeShift": "soon"}`))
+	require.NoError(t, err)
+	_, _, err = panelTimeRange(panel, from, to, now, nil)
+	require.Error(t, err)
+}
+
+func TestInterpolateVariables(t *testing.T) {
+	variables := map[string][]string{
+		"single": {"a"},
+		"multi":  {"a", "b"},
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "$single", expected: "a"},
+		{text: "${single}", expected: "a"},
+		{text: "[[single]]", expected: "a"},
+		{text: "${single:singlequote}", expected: "'a'"},
+		{text: "$multi", expected: "{a,b}"},
+		{text: "${multi:csv}", expected: "a,b"},
+		{text: "${multi:pipe}", expected: "a|b"},
+		{text: "${multi:regex}", expected: "(a|b)"},
+		{text: "${multi:doublequote}", expected: `"a","b"`},
+		{text: "${multi:json}", expected: `["a","b"]`},
+		{text: "[[multi:pipe]]", expected: "a|b"},
+		{text: "$unknown", expected: "$unknown"},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, InterpolateVariables(tt.text, variables), tt.text)
+	}
+}
@@ -0,0 +1,175 @@
+package dependencies
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *DependenciesService) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+	uidParam := ac.Parameter(":uid")
+
+	s.routeRegister.Group("/api/dependencies", func(entities routing.RouteRegister) {
+		entities.Get("/datasources/:uid", authorize(ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(uidParam))), routing.Wrap(s.dataSourceDependentsHandler))
+		if s.features.IsEnabledGlobally(featuremgmt.FlagLibraryPanelRBAC) {
+			entities.Get("/library-panels/:uid", authorize(ac.EvalPermission(libraryelements.ActionLibraryPanelsRead, libraryelements.ScopeLibraryPanelsProvider.GetResourceScopeUID(uidParam))), routing.Wrap(s.libraryPanelDependentsHandler))
+		} else {
+			entities.Get("/library-panels/:uid", routing.Wrap(s.libraryPanelDependentsHandler))
+		}
+		entities.Get("/folders/:uid", authorize(ac.EvalPermission(dashboards.ActionFoldersRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(uidParam))), routing.Wrap(s.folderDependentsHandler))
+		entities.Get("/dashboards/:uid", authorize(ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uidParam))), routing.Wrap(s.dashboardDependenciesHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /dependencies/datasources/{uid} dependencies getDataSourceDependents
+//
+// Get the dashboards, panels, alert rules, correlations and public dashboards that use a data source.
+//
+// Only the resources the signed in user can read are returned.
+//
+// Responses:
+// 200: getDependentsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DependenciesService) dataSourceDependentsHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetDataSourceDependents(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.Err(ErrInternal.Errorf("failed to get data source dependents: %w", err))
+	}
+	return s.filteredDependents(c, result)
+}
+
+// swagger:route GET /dependencies/library-panels/{uid} dependencies getLibraryPanelDependents
+//
+// Get the dashboards, panels, alert rules and public dashboards that use a library panel.
+//
+// Only the resources the signed in user can read are returned.
+//
+// Responses:
+// 200: getDependentsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DependenciesService) libraryPanelDependentsHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetLibraryPanelDependents(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.Err(ErrInternal.Errorf("failed to get library panel dependents: %w", err))
+	}
+	return s.filteredDependents(c, result)
+}
+
+// swagger:route GET /dependencies/folders/{uid} dependencies getFolderDependents
+//
+// Get the dashboards, alert rules and public dashboards stored in a folder and its subfolders.
+//
+// Only the resources the signed in user can read are returned.
+//
+// Responses:
+// 200: getDependentsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *DependenciesService) folderDependentsHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetFolderDependents(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.Err(ErrInternal.Errorf("failed to get folder dependents: %w", err))
+	}
+	return s.filteredDependents(c, result)
+}
+
+// swagger:route GET /dependencies/dashboards/{uid} dependencies getDashboardDependencies
+//
+// Get the data sources and library panels used by a dashboard.
+//
+// Responses:
+// 200: getDashboardDependenciesResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *DependenciesService) dashboardDependenciesHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := s.GetDashboardDependencies(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":uid"])
+	if err != nil {
+		return response.Err(err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// filteredDependents drops the resources the signed in user is not allowed to read
+func (s *DependenciesService) filteredDependents(c *contextmodel.ReqContext, result *Dependents) response.Response {
+	ctx := c.Req.Context()
+	canRead := func(evaluator ac.Evaluator) (bool, error) {
+		return s.accessControl.Evaluate(ctx, c.SignedInUser, evaluator)
+	}
+
+	filtered := newDependents()
+	readable := make(map[string]bool, len(result.Dashboards))
+	for _, d := range result.Dashboards {
+		ok, err := canRead(ac.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(d.UID)))
+		if err != nil {
+			return response.Err(ErrInternal.Errorf("failed to evaluate dashboard permissions: %w", err))
+		}
+		if ok {
+			readable[d.UID] = true
+			filtered.Dashboards = append(filtered.Dashboards, d)
+		}
+	}
+
+	for _, p := range result.PublicDashboards {
+		if readable[p.DashboardUID] {
+			filtered.PublicDashboards = append(filtered.PublicDashboards, p)
+		}
+	}
+
+	for _, r := range result.AlertRules {
+		ok, err := canRead(ac.EvalPermission(ac.ActionAlertingRuleRead, dashboards.ScopeFoldersProvider.GetResourceScopeUID(r.FolderUID)))
+		if err != nil {
+			return response.Err(ErrInternal.Errorf("failed to evaluate alert rule permissions: %w", err))
+		}
+		if ok {
+			filtered.AlertRules = append(filtered.AlertRules, r)
+		}
+	}
+
+	for _, corr := range result.Correlations {
+		ok, err := canRead(ac.EvalPermission(datasources.ActionRead, datasources.ScopeProvider.GetResourceScopeUID(corr.SourceUID)))
+		if err != nil {
+			return response.Err(ErrInternal.Errorf("failed to evaluate data source permissions: %w", err))
+		}
+		if ok {
+			filtered.Correlations = append(filtered.Correlations, corr)
+		}
+	}
+
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// swagger:parameters getDataSourceDependents getLibraryPanelDependents getFolderDependents getDashboardDependencies
+type GetDependenciesParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:response getDependentsResponse
+type GetDependentsResponse struct {
+	// in: body
+	Body Dependents `json:"body"`
+}
+
+// swagger:response getDashboardDependenciesResponse
+type GetDashboardDependenciesResponse struct {
+	// in: body
+	Body DashboardDependencies `json:"body"`
+}
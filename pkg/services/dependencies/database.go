@@ -0,0 +1,228 @@
+package dependencies
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+type dashboardVersion struct {
+	OrgID   int64  `xorm:"org_id"`
+	UID     string `xorm:"uid"`
+	Version int    `xorm:"version"`
+}
+
+type dashboardRow struct {
+	UID       string `xorm:"uid"`
+	Title     string `xorm:"title"`
+	FolderUID string `xorm:"folder_uid"`
+}
+
+type correlationRow struct {
+	UID       string  `xorm:"uid"`
+	Label     string  `xorm:"label"`
+	SourceUID string  `xorm:"source_uid"`
+	TargetUID *string `xorm:"target_uid"`
+}
+
+type publicDashboardRow struct {
+	UID          string `xorm:"uid"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	IsEnabled    bool   `xorm:"is_enabled"`
+}
+
+// replace stores the dependencies of a dashboard version, dropping the ones computed for the previous version
+func (s *sqlStore) replace(ctx context.Context, orgID int64, dashboardUID string, version int, deps []*dependency) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&dependency{}); err != nil {
+			return err
+		}
+		if _, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&indexedDashboard{}); err != nil {
+			return err
+		}
+
+		for _, dep := range deps {
+			if _, err := sess.Insert(dep); err != nil {
+				return err
+			}
+		}
+
+		_, err := sess.Insert(&indexedDashboard{
+			OrgID:        orgID,
+			DashboardUID: dashboardUID,
+			Version:      version,
+			Updated:      time.Now(),
+		})
+		return err
+	})
+}
+
+func (s *sqlStore) remove(ctx context.Context, orgID int64, dashboardUID string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&dependency{}); err != nil {
+			return err
+		}
+		_, err := sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).Delete(&indexedDashboard{})
+		return err
+	})
+}
+
+func (s *sqlStore) findByTarget(ctx context.Context, orgID int64, kind Kind, targetUID string) ([]*dependency, error) {
+	deps := make([]*dependency, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND kind = ? AND target_uid = ?", orgID, kind, targetUID).
+			Asc("dashboard_uid", "panel_id").
+			Find(&deps)
+	})
+	return deps, err
+}
+
+func (s *sqlStore) findByDashboard(ctx context.Context, orgID int64, dashboardUID string) ([]*dependency, error) {
+	deps := make([]*dependency, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND dashboard_uid = ?", orgID, dashboardUID).
+			Asc("kind", "target_uid", "panel_id").
+			Find(&deps)
+	})
+	return deps, err
+}
+
+// indexedVersions returns the dashboard version each indexed dashboard was computed from
+func (s *sqlStore) indexedVersions(ctx context.Context) (map[dashboardKey]int, error) {
+	rows := make([]*indexedDashboard, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[dashboardKey]int, len(rows))
+	for _, row := range rows {
+		versions[dashboardKey{OrgID: row.OrgID, UID: row.DashboardUID}] = row.Version
+	}
+	return versions, nil
+}
+
+// dashboardVersions returns the current version of every dashboard that is not in the trash
+func (s *sqlStore) dashboardVersions(ctx context.Context) ([]*dashboardVersion, error) {
+	rows := make([]*dashboardVersion, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard").
+			Cols("org_id", "uid", "version").
+			Where("is_folder = " + s.db.GetDialect().BooleanStr(false) + " AND deleted IS NULL").
+			Find(&rows)
+	})
+	return rows, err
+}
+
+func (s *sqlStore) getDashboard(ctx context.Context, orgID int64, uid string) (*dashboards.Dashboard, error) {
+	dash := &dashboards.Dashboard{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Table("dashboard").
+			Where("org_id = ? AND uid = ? AND is_folder = "+s.db.GetDialect().BooleanStr(false)+" AND deleted IS NULL", orgID, uid).
+			Get(dash)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrDashboardNotFound.Errorf("dashboard %s not found", uid)
+		}
+		return nil
+	})
+	return dash, err
+}
+
+func (s *sqlStore) getDashboards(ctx context.Context, orgID int64, uids []string) ([]*dashboardRow, error) {
+	rows := make([]*dashboardRow, 0)
+	if len(uids) == 0 {
+		return rows, nil
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard").
+			Cols("uid", "title", "folder_uid").
+			Where("org_id = ? AND is_folder = "+s.db.GetDialect().BooleanStr(false)+" AND deleted IS NULL", orgID).
+			In("uid", uids).
+			Asc("title").
+			Find(&rows)
+	})
+	return rows, err
+}
+
+// folderTree returns the UID of the folder and of all its descendants
+func (s *sqlStore) folderTree(ctx context.Context, orgID int64, folderUID string) ([]string, error) {
+	uids := []string{folderUID}
+	seen := map[string]bool{folderUID: true}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		parents := []string{folderUID}
+		for len(parents) > 0 {
+			children := make([]string, 0)
+			err := sess.Table("dashboard").
+				Cols("uid").
+				Where("org_id = ? AND is_folder = "+s.db.GetDialect().BooleanStr(true)+" AND deleted IS NULL", orgID).
+				In("folder_uid", parents).
+				Find(&children)
+			if err != nil {
+				return err
+			}
+
+			parents = parents[:0]
+			for _, uid := range children {
+				if !seen[uid] {
+					seen[uid] = true
+					uids = append(uids, uid)
+					parents = append(parents, uid)
+				}
+			}
+		}
+		return nil
+	})
+	return uids, err
+}
+
+func (s *sqlStore) getDashboardsInFolders(ctx context.Context, orgID int64, folderUIDs []string) ([]*dashboardRow, error) {
+	rows := make([]*dashboardRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard").
+			Cols("uid", "title", "folder_uid").
+			Where("org_id = ? AND is_folder = "+s.db.GetDialect().BooleanStr(false)+" AND deleted IS NULL", orgID).
+			In("folder_uid", folderUIDs).
+			Asc("title").
+			Find(&rows)
+	})
+	return rows, err
+}
+
+func (s *sqlStore) getCorrelations(ctx context.Context, orgID int64, datasourceUID string) ([]*correlationRow, error) {
+	rows := make([]*correlationRow, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("correlation").
+			Cols("uid", "label", "source_uid", "target_uid").
+			Where("org_id = ? AND (source_uid = ? OR target_uid = ?)", orgID, datasourceUID, datasourceUID).
+			Asc("label").
+			Find(&rows)
+	})
+	return rows, err
+}
+
+func (s *sqlStore) getPublicDashboards(ctx context.Context, orgID int64, dashboardUIDs []string) ([]*publicDashboardRow, error) {
+	rows := make([]*publicDashboardRow, 0)
+	if len(dashboardUIDs) == 0 {
+		return rows, nil
+	}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("dashboard_public").
+			Cols("uid", "dashboard_uid", "is_enabled").
+			Where("org_id = ?", orgID).
+			In("dashboard_uid", dashboardUIDs).
+			Asc("dashboard_uid").
+			Find(&rows)
+	})
+	return rows, err
+}
@@ -0,0 +1,379 @@
+package dependencies
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// reconcileInterval is how often dashboards saved outside of the HTTP API, e.g. by provisioning, are picked up
+const reconcileInterval = 5 * time.Minute
+
+// Service finds the resources that depend on a data source, library panel or folder, and the resources a dashboard
+// depends on. Dashboard references are kept in an index that is updated when a dashboard is saved, alert rules,
+// correlations and public dashboards are looked up when the dependents are requested.
+type Service interface {
+	// IndexDashboard recomputes the dependencies of a saved dashboard.
+	IndexDashboard(ctx context.Context, dash *dashboards.Dashboard) error
+	// RemoveDashboard drops the dependencies of a deleted dashboard.
+	RemoveDashboard(ctx context.Context, orgID int64, dashboardUID string) error
+	GetDataSourceDependents(ctx context.Context, orgID int64, uid string) (*Dependents, error)
+	GetLibraryPanelDependents(ctx context.Context, orgID int64, uid string) (*Dependents, error)
+	GetFolderDependents(ctx context.Context, orgID int64, uid string) (*Dependents, error)
+	GetDashboardDependencies(ctx context.Context, orgID int64, uid string) (*DashboardDependencies, error)
+}
+
+// AlertRuleStore is the subset of the alerting rule store used to find rules referencing a resource.
+type AlertRuleStore interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+}
+
+type DependenciesService struct {
+	db            db.DB
+	store         *sqlStore
+	ruleStore     AlertRuleStore
+	routeRegister routing.RouteRegister
+	accessControl accesscontrol.AccessControl
+	features      featuremgmt.FeatureToggles
+	log           log.Logger
+}
+
+func ProvideService(sql db.DB, routeRegister routing.RouteRegister, ruleStore AlertRuleStore,
+	accessControl accesscontrol.AccessControl, features featuremgmt.FeatureToggles) *DependenciesService {
+	s := &DependenciesService{
+		db:            sql,
+		store:         &sqlStore{db: sql},
+		ruleStore:     ruleStore,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		features:      features,
+		log:           log.New("dependencies"),
+	}
+
+	s.registerAPIEndpoints()
+
+	return s
+}
+
+// Run keeps the index in sync with dashboards that were not saved through the HTTP API and backfills it on first start.
+func (s *DependenciesService) Run(ctx context.Context) error {
+	ticker := time.NewTicker(reconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.reconcile(ctx); err != nil {
+			s.log.Error("Failed to update dashboard dependencies", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *DependenciesService) IndexDashboard(ctx context.Context, dash *dashboards.Dashboard) error {
+	if dash.IsFolder {
+		return nil
+	}
+
+	lookup, err := kdash.LoadDatasourceLookup(ctx, dash.OrgID, s.db)
+	if err != nil {
+		return err
+	}
+	return s.index(ctx, lookup, dash)
+}
+
+func (s *DependenciesService) RemoveDashboard(ctx context.Context, orgID int64, dashboardUID string) error {
+	return s.store.remove(ctx, orgID, dashboardUID)
+}
+
+func (s *DependenciesService) index(ctx context.Context, lookup kdash.DatasourceLookup, dash *dashboards.Dashboard) error {
+	deps, err := extractDependencies(ctx, lookup, dash)
+	if err != nil {
+		// keep track of the version so that an unparsable dashboard is not retried on every reconciliation
+		s.log.Warn("Failed to read dashboard dependencies", "dashboardUid", dash.UID, "orgId", dash.OrgID, "error", err)
+		deps = nil
+	}
+	return s.store.replace(ctx, dash.OrgID, dash.UID, dash.Version, deps)
+}
+
+// reconcile indexes the dashboards whose version differs from the indexed one and drops the deleted ones
+func (s *DependenciesService) reconcile(ctx context.Context) error {
+	indexed, err := s.store.indexedVersions(ctx)
+	if err != nil {
+		return err
+	}
+	current, err := s.store.dashboardVersions(ctx)
+	if err != nil {
+		return err
+	}
+
+	lookups := make(map[int64]kdash.DatasourceLookup)
+	updated := 0
+	for _, d := range current {
+		key := dashboardKey{OrgID: d.OrgID, UID: d.UID}
+		version, ok := indexed[key]
+		delete(indexed, key)
+		if ok && version == d.Version {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		lookup, ok := lookups[d.OrgID]
+		if !ok {
+			lookup, err = kdash.LoadDatasourceLookup(ctx, d.OrgID, s.db)
+			if err != nil {
+				return err
+			}
+			lookups[d.OrgID] = lookup
+		}
+
+		dash, err := s.store.getDashboard(ctx, d.OrgID, d.UID)
+		if err != nil {
+			// deleted in the meantime, it will be dropped on the next run
+			s.log.Debug("Skipping dashboard", "dashboardUid", d.UID, "orgId", d.OrgID, "error", err)
+			continue
+		}
+		if err := s.index(ctx, lookup, dash); err != nil {
+			return err
+		}
+		updated++
+	}
+
+	for key := range indexed {
+		if err := s.store.remove(ctx, key.OrgID, key.UID); err != nil {
+			return err
+		}
+	}
+
+	if updated > 0 || len(indexed) > 0 {
+		s.log.Debug("Updated dashboard dependencies", "indexed", updated, "removed", len(indexed))
+	}
+	return nil
+}
+
+func (s *DependenciesService) GetDataSourceDependents(ctx context.Context, orgID int64, uid string) (*Dependents, error) {
+	deps, err := s.store.findByTarget(ctx, orgID, KindDataSource, uid)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.dashboardDependents(ctx, orgID, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID})
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		for _, q := range rule.Data {
+			if q.DatasourceUID == uid {
+				result.AlertRules = append(result.AlertRules, toAlertRuleRef(rule))
+				break
+			}
+		}
+	}
+
+	correlations, err := s.store.getCorrelations(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range correlations {
+		ref := CorrelationRef{UID: c.UID, Label: c.Label, SourceUID: c.SourceUID}
+		if c.TargetUID != nil {
+			ref.TargetUID = *c.TargetUID
+		}
+		result.Correlations = append(result.Correlations, ref)
+	}
+
+	return result, nil
+}
+
+func (s *DependenciesService) GetLibraryPanelDependents(ctx context.Context, orgID int64, uid string) (*Dependents, error) {
+	deps, err := s.store.findByTarget(ctx, orgID, KindLibraryPanel, uid)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.dashboardDependents(ctx, orgID, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	// rules linked to one of the panels showing the library panel
+	for _, d := range result.Dashboards {
+		rules, err := s.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID, DashboardUID: d.UID})
+		if err != nil {
+			return nil, err
+		}
+		for _, rule := range rules {
+			if rule.PanelID == nil {
+				continue
+			}
+			for _, p := range d.Panels {
+				if p.ID == *rule.PanelID {
+					result.AlertRules = append(result.AlertRules, toAlertRuleRef(rule))
+					break
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *DependenciesService) GetFolderDependents(ctx context.Context, orgID int64, uid string) (*Dependents, error) {
+	folderUIDs, err := s.store.folderTree(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.store.getDashboardsInFolders(ctx, orgID, folderUIDs)
+	if err != nil {
+		return nil, err
+	}
+	result := newDependents()
+	for _, row := range rows {
+		result.Dashboards = append(result.Dashboards, DashboardRef{UID: row.UID, Title: row.Title, FolderUID: row.FolderUID})
+	}
+
+	rules, err := s.ruleStore.ListAlertRules(ctx, &ngmodels.ListAlertRulesQuery{OrgID: orgID, NamespaceUIDs: folderUIDs})
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		result.AlertRules = append(result.AlertRules, toAlertRuleRef(rule))
+	}
+
+	if err := s.addPublicDashboards(ctx, orgID, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *DependenciesService) GetDashboardDependencies(ctx context.Context, orgID int64, uid string) (*DashboardDependencies, error) {
+	rows, err := s.store.getDashboards(ctx, orgID, []string{uid})
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrDashboardNotFound.Errorf("dashboard %s not found", uid)
+	}
+
+	deps, err := s.store.findByDashboard(ctx, orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DashboardDependencies{
+		UID:           rows[0].UID,
+		Title:         rows[0].Title,
+		FolderUID:     rows[0].FolderUID,
+		DataSources:   make([]DataSourceDependency, 0),
+		LibraryPanels: make([]LibraryPanelDependency, 0),
+	}
+	for _, dep := range deps {
+		panel := PanelRef{ID: dep.PanelID, Title: dep.PanelTitle}
+		switch dep.Kind {
+		case KindDataSource:
+			if n := len(result.DataSources); n > 0 && result.DataSources[n-1].UID == dep.TargetUID {
+				result.DataSources[n-1].Panels = append(result.DataSources[n-1].Panels, panel)
+				continue
+			}
+			result.DataSources = append(result.DataSources, DataSourceDependency{UID: dep.TargetUID, Type: dep.TargetType, Panels: []PanelRef{panel}})
+		case KindLibraryPanel:
+			if n := len(result.LibraryPanels); n > 0 && result.LibraryPanels[n-1].UID == dep.TargetUID {
+				result.LibraryPanels[n-1].Panels = append(result.LibraryPanels[n-1].Panels, panel)
+				continue
+			}
+			result.LibraryPanels = append(result.LibraryPanels, LibraryPanelDependency{UID: dep.TargetUID, Panels: []PanelRef{panel}})
+		}
+	}
+
+	return result, nil
+}
+
+// dashboardDependents groups the indexed panel references by dashboard and adds the public dashboards exposing them
+func (s *DependenciesService) dashboardDependents(ctx context.Context, orgID int64, deps []*dependency) (*Dependents, error) {
+	panels := make(map[string][]PanelRef)
+	for _, dep := range deps {
+		panels[dep.DashboardUID] = append(panels[dep.DashboardUID], PanelRef{ID: dep.PanelID, Title: dep.PanelTitle})
+	}
+	uids := make([]string, 0, len(panels))
+	for uid := range panels {
+		uids = append(uids, uid)
+	}
+	sort.Strings(uids)
+
+	rows, err := s.store.getDashboards(ctx, orgID, uids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := newDependents()
+	for _, row := range rows {
+		result.Dashboards = append(result.Dashboards, DashboardRef{
+			UID:       row.UID,
+			Title:     row.Title,
+			FolderUID: row.FolderUID,
+			Panels:    panels[row.UID],
+		})
+	}
+
+	if err := s.addPublicDashboards(ctx, orgID, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *DependenciesService) addPublicDashboards(ctx context.Context, orgID int64, result *Dependents) error {
+	uids := make([]string, 0, len(result.Dashboards))
+	for _, d := range result.Dashboards {
+		uids = append(uids, d.UID)
+	}
+
+	rows, err := s.store.getPublicDashboards(ctx, orgID, uids)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		result.PublicDashboards = append(result.PublicDashboards, PublicDashboardRef{
+			UID:          row.UID,
+			DashboardUID: row.DashboardUID,
+			IsEnabled:    row.IsEnabled,
+		})
+	}
+	return nil
+}
+
+func newDependents() *Dependents {
+	return &Dependents{
+		Dashboards:       make([]DashboardRef, 0),
+		AlertRules:       make([]AlertRuleRef, 0),
+		Correlations:     make([]CorrelationRef, 0),
+		PublicDashboards: make([]PublicDashboardRef, 0),
+	}
+}
+
+func toAlertRuleRef(rule *ngmodels.AlertRule) AlertRuleRef {
+	return AlertRuleRef{
+		UID:       rule.UID,
+		Title:     rule.Title,
+		FolderUID: rule.NamespaceUID,
+		RuleGroup: rule.RuleGroup,
+	}
+}
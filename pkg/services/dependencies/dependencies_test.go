@@ -0,0 +1,267 @@
+package dependencies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	dsservice "github.com/grafana/grafana/pkg/services/datasources/service"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type fakeRuleStore struct {
+	rules []*ngmodels.AlertRule
+}
+
+func (f *fakeRuleStore) ListAlertRules(_ context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error) {
+	result := ngmodels.RulesGroup{}
+	for _, rule := range f.rules {
+		if rule.OrgID != query.OrgID {
+			continue
+		}
+		if query.DashboardUID != "" && (rule.DashboardUID == nil || *rule.DashboardUID != query.DashboardUID) {
+			continue
+		}
+		if len(query.NamespaceUIDs) > 0 {
+			found := false
+			for _, uid := range query.NamespaceUIDs {
+				found = found || uid == rule.NamespaceUID
+			}
+			if !found {
+				continue
+			}
+		}
+		result = append(result, rule)
+	}
+	return result, nil
+}
+
+type testEnv struct {
+	sqlStore       db.DB
+	dashboardStore dashboards.Store
+	ruleStore      *fakeRuleStore
+	service        *DependenciesService
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+	require.NoError(t, err)
+
+	dsStore := dsservice.CreateStore(sqlStore, log.New("test"))
+	for _, cmd := range []*datasources.AddDataSourceCommand{
+		{OrgID: 1, UID: "prom", Name: "Prometheus", Type: "prometheus", Access: datasources.DS_ACCESS_PROXY, IsDefault: true},
+		{OrgID: 1, UID: "loki", Name: "Loki", Type: "loki", Access: datasources.DS_ACCESS_PROXY},
+	} {
+		_, err := dsStore.AddDataSource(context.Background(), cmd)
+		require.NoError(t, err)
+	}
+
+	ruleStore := &fakeRuleStore{}
+	service := ProvideService(sqlStore, routing.NewRouteRegister(), ruleStore, actest.FakeAccessControl{}, featuremgmt.WithFeatures())
+
+	return &testEnv{
+		sqlStore:       sqlStore,
+		dashboardStore: dashboardStore,
+		ruleStore:      ruleStore,
+		service:        service,
+	}
+}
+
+func (e *testEnv) saveDashboard(t *testing.T, uid string, folderUID string, isFolder bool, panels ...map[string]any) *dashboards.Dashboard {
+	t.Helper()
+	dash, err := e.dashboardStore.SaveDashboard(context.Background(), dashboards.SaveDashboardCommand{
+		OrgID:     1,
+		FolderUID: folderUID,
+		IsFolder:  isFolder,
+		Overwrite: true,
+		Dashboard: simplejson.NewFromAny(map[string]any{
+			"uid":    uid,
+			"title":  "Dashboard " + uid,
+			"panels": panels,
+		}),
+	})
+	require.NoError(t, err)
+	return dash
+}
+
+func dashboardUIDs(refs []DashboardRef) []string {
+	uids := make([]string, 0, len(refs))
+	for _, r := range refs {
+		uids = append(uids, r.UID)
+	}
+	return uids
+}
+
+func TestIntegrationDependencies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	env := setupTestEnv(t)
+
+	env.saveDashboard(t, "folder", "", true)
+	env.saveDashboard(t, "subfolder", "folder", true)
+	cpu := env.saveDashboard(t, "cpu", "folder", false,
+		map[string]any{"id": 1, "type": "timeseries", "title": "CPU", "datasource": map[string]any{"uid": "prom"}},
+		map[string]any{"id": 2, "title": "Shared", "libraryPanel": map[string]any{"uid": "lib1", "name": "Shared"}},
+	)
+	logs := env.saveDashboard(t, "logs", "subfolder", false,
+		map[string]any{"id": 1, "type": "logs", "title": "Logs", "datasource": "Loki"},
+	)
+	env.saveDashboard(t, "other", "", false,
+		map[string]any{"id": 3, "type": "stat", "title": "Shared elsewhere", "libraryPanel": map[string]any{"uid": "lib1", "name": "Shared"}},
+	)
+
+	dashboardUID := "cpu"
+	panelID := int64(2)
+	env.ruleStore.rules = []*ngmodels.AlertRule{
+		{OrgID: 1, UID: "rule-prom", Title: "High CPU", NamespaceUID: "folder", RuleGroup: "group", Data: []ngmodels.AlertQuery{
+			{RefID: "A", DatasourceUID: "prom"},
+			{RefID: "B", DatasourceUID: "__expr__"},
+		}},
+		{OrgID: 1, UID: "rule-loki", Title: "Errors", NamespaceUID: "subfolder", RuleGroup: "group", Data: []ngmodels.AlertQuery{
+			{RefID: "A", DatasourceUID: "loki"},
+		}},
+		{OrgID: 1, UID: "rule-panel", Title: "Shared panel", NamespaceUID: "other-folder", RuleGroup: "group", DashboardUID: &dashboardUID, PanelID: &panelID, Data: []ngmodels.AlertQuery{
+			{RefID: "A", DatasourceUID: "loki"},
+		}},
+	}
+
+	err := env.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		target := "prom"
+		if _, err := sess.Insert(&correlations.Correlation{UID: "corr", OrgID: 1, SourceUID: "loki", TargetUID: &target, Label: "Logs to metrics"}); err != nil {
+			return err
+		}
+		_, err := sess.Insert(&publicdashboardModels.PublicDashboard{
+			Uid:          "pub",
+			DashboardUid: "cpu",
+			OrgId:        1,
+			AccessToken:  "token",
+			IsEnabled:    true,
+			Share:        publicdashboardModels.PublicShareType,
+			TimeSettings: &publicdashboardModels.TimeSettings{},
+		})
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Run("reconcile indexes existing dashboards", func(t *testing.T) {
+		require.NoError(t, env.service.reconcile(ctx))
+
+		versions, err := env.service.store.indexedVersions(ctx)
+		require.NoError(t, err)
+		assert.Len(t, versions, 3)
+		assert.Equal(t, cpu.Version, versions[dashboardKey{OrgID: 1, UID: "cpu"}])
+	})
+
+	t.Run("data source dependents", func(t *testing.T) {
+		result, err := env.service.GetDataSourceDependents(ctx, 1, "prom")
+		require.NoError(t, err)
+
+		require.Len(t, result.Dashboards, 1)
+		assert.Equal(t, DashboardRef{UID: "cpu", Title: "Dashboard cpu", FolderUID: "folder", Panels: []PanelRef{{ID: 1, Title: "CPU"}}}, result.Dashboards[0])
+		require.Len(t, result.AlertRules, 1)
+		assert.Equal(t, AlertRuleRef{UID: "rule-prom", Title: "High CPU", FolderUID: "folder", RuleGroup: "group"}, result.AlertRules[0])
+		assert.Equal(t, []CorrelationRef{{UID: "corr", Label: "Logs to metrics", SourceUID: "loki", TargetUID: "prom"}}, result.Correlations)
+		assert.Equal(t, []PublicDashboardRef{{UID: "pub", DashboardUID: "cpu", IsEnabled: true}}, result.PublicDashboards)
+	})
+
+	t.Run("data source referenced by name is resolved", func(t *testing.T) {
+		result, err := env.service.GetDataSourceDependents(ctx, 1, "loki")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"logs"}, dashboardUIDs(result.Dashboards))
+		assert.Len(t, result.AlertRules, 2)
+		assert.Len(t, result.Correlations, 1)
+		assert.Empty(t, result.PublicDashboards)
+	})
+
+	t.Run("library panel dependents", func(t *testing.T) {
+		result, err := env.service.GetLibraryPanelDependents(ctx, 1, "lib1")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"cpu", "other"}, dashboardUIDs(result.Dashboards))
+		require.Len(t, result.AlertRules, 1)
+		assert.Equal(t, "rule-panel", result.AlertRules[0].UID)
+		assert.Len(t, result.PublicDashboards, 1)
+	})
+
+	t.Run("folder dependents include subfolders", func(t *testing.T) {
+		result, err := env.service.GetFolderDependents(ctx, 1, "folder")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"cpu", "logs"}, dashboardUIDs(result.Dashboards))
+		assert.Len(t, result.AlertRules, 2)
+		assert.Len(t, result.PublicDashboards, 1)
+	})
+
+	t.Run("dashboard dependencies", func(t *testing.T) {
+		result, err := env.service.GetDashboardDependencies(ctx, 1, "cpu")
+		require.NoError(t, err)
+
+		assert.Equal(t, &DashboardDependencies{
+			UID:           "cpu",
+			Title:         "Dashboard cpu",
+			FolderUID:     "folder",
+			DataSources:   []DataSourceDependency{{UID: "prom", Type: "prometheus", Panels: []PanelRef{{ID: 1, Title: "CPU"}}}},
+			LibraryPanels: []LibraryPanelDependency{{UID: "lib1", Panels: []PanelRef{{ID: 2, Title: "Shared"}}}},
+		}, result)
+
+		_, err = env.service.GetDashboardDependencies(ctx, 1, "missing")
+		require.ErrorIs(t, err, ErrDashboardNotFound)
+	})
+
+	t.Run("saving a dashboard updates its dependencies", func(t *testing.T) {
+		logs.Data.Set("panels", []any{
+			map[string]any{"id": 1, "type": "logs", "title": "Logs", "datasource": map[string]any{"uid": "prom"}},
+		})
+		saved, err := env.dashboardStore.SaveDashboard(ctx, dashboards.SaveDashboardCommand{OrgID: 1, FolderUID: "subfolder", Overwrite: true, Dashboard: logs.Data})
+		require.NoError(t, err)
+		require.NoError(t, env.service.IndexDashboard(ctx, saved))
+
+		result, err := env.service.GetDataSourceDependents(ctx, 1, "loki")
+		require.NoError(t, err)
+		assert.Empty(t, result.Dashboards)
+
+		result, err = env.service.GetDataSourceDependents(ctx, 1, "prom")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"cpu", "logs"}, dashboardUIDs(result.Dashboards))
+	})
+
+	t.Run("deleted dashboards are removed", func(t *testing.T) {
+		require.NoError(t, env.dashboardStore.DeleteDashboard(ctx, &dashboards.DeleteDashboardCommand{OrgID: 1, ID: cpu.ID}))
+		require.NoError(t, env.service.reconcile(ctx))
+
+		result, err := env.service.GetLibraryPanelDependents(ctx, 1, "lib1")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"other"}, dashboardUIDs(result.Dashboards))
+
+		deps, err := env.service.store.findByDashboard(ctx, 1, "cpu")
+		require.NoError(t, err)
+		assert.Empty(t, deps)
+	})
+}
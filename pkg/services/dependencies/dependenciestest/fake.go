@@ -0,0 +1,49 @@
+package dependenciestest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dependencies"
+)
+
+var _ dependencies.Service = (*FakeService)(nil)
+
+type FakeService struct {
+	ExpectedDependents   *dependencies.Dependents
+	ExpectedDependencies *dependencies.DashboardDependencies
+	ExpectedError        error
+
+	IndexedDashboards []string
+	RemovedDashboards []string
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) IndexDashboard(ctx context.Context, dash *dashboards.Dashboard) error {
+	f.IndexedDashboards = append(f.IndexedDashboards, dash.UID)
+	return f.ExpectedError
+}
+
+func (f *FakeService) RemoveDashboard(ctx context.Context, orgID int64, dashboardUID string) error {
+	f.RemovedDashboards = append(f.RemovedDashboards, dashboardUID)
+	return f.ExpectedError
+}
+
+func (f *FakeService) GetDataSourceDependents(ctx context.Context, orgID int64, uid string) (*dependencies.Dependents, error) {
+	return f.ExpectedDependents, f.ExpectedError
+}
+
+func (f *FakeService) GetLibraryPanelDependents(ctx context.Context, orgID int64, uid string) (*dependencies.Dependents, error) {
+	return f.ExpectedDependents, f.ExpectedError
+}
+
+func (f *FakeService) GetFolderDependents(ctx context.Context, orgID int64, uid string) (*dependencies.Dependents, error) {
+	return f.ExpectedDependents, f.ExpectedError
+}
+
+func (f *FakeService) GetDashboardDependencies(ctx context.Context, orgID int64, uid string) (*dependencies.DashboardDependencies, error) {
+	return f.ExpectedDependencies, f.ExpectedError
+}
@@ -0,0 +1,90 @@
+package dependencies
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+// extractDependencies parses the dashboard JSON and returns the data sources and library panels used by its panels,
+// including the panels nested in collapsed rows. Data sources referenced by name, template variable or as the default
+// data source are resolved to their UID through the lookup.
+func extractDependencies(ctx context.Context, lookup kdash.DatasourceLookup, dash *dashboards.Dashboard) ([]*dependency, error) {
+	if dash.Data == nil {
+		return nil, nil
+	}
+
+	body, err := dash.Data.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	summary, _, err := kdash.NewStaticDashboardSummaryBuilder(lookup, false)(ctx, dash.UID, body)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]*dependency, 0)
+	for _, panel := range summary.Nested {
+		panelID := panelIDFromSummaryUID(panel.UID)
+		// the data sources of a library panel belong to the library panel, the one filled in here is the default
+		isLibraryPanel := false
+		for _, ref := range panel.References {
+			if ref.Family == entity.StandardKindLibraryPanel {
+				isLibraryPanel = true
+			}
+		}
+
+		for _, ref := range panel.References {
+			if ref.Identifier == "" || isUnresolvedDataSource(ref.Identifier) {
+				continue
+			}
+
+			dep := &dependency{
+				OrgID:        dash.OrgID,
+				DashboardUID: dash.UID,
+				PanelID:      panelID,
+				PanelTitle:   panel.Name,
+				TargetUID:    ref.Identifier,
+			}
+			switch ref.Family {
+			case entity.StandardKindDataSource:
+				if isLibraryPanel {
+					continue
+				}
+				dep.Kind = KindDataSource
+				dep.TargetType = ref.Type
+			case entity.StandardKindLibraryPanel:
+				dep.Kind = KindLibraryPanel
+			default:
+				continue
+			}
+			deps = append(deps, dep)
+		}
+	}
+
+	return deps, nil
+}
+
+// isUnresolvedDataSource reports whether a reference is a template variable or a special data source, the summary
+// only resolves them for top level panels
+func isUnresolvedDataSource(uid string) bool {
+	return strings.HasPrefix(uid, "$") || uid == "-- Mixed --" || uid == "-- Dashboard --"
+}
+
+// panelIDFromSummaryUID returns the panel id of a nested summary, its UID has the form <dashboard uid>#<panel id>
+func panelIDFromSummaryUID(uid string) int64 {
+	idx := strings.LastIndex(uid, "#")
+	if idx < 0 {
+		return 0
+	}
+	id, err := strconv.ParseInt(uid[idx+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
@@ -0,0 +1,79 @@
+package dependencies
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
+)
+
+func testLookup() kdash.DatasourceLookup {
+	return kdash.CreateDatasourceLookup([]*kdash.DatasourceQueryResult{
+		{UID: "prom", Type: "prometheus", Name: "Prometheus", IsDefault: true},
+		{UID: "loki", Type: "loki", Name: "Loki"},
+	})
+}
+
+func testDashboard(uid string, panels ...map[string]any) *dashboards.Dashboard {
+	dash := dashboards.NewDashboardFromJson(simplejson.NewFromAny(map[string]any{
+		"uid":    uid,
+		"title":  "Dashboard " + uid,
+		"panels": panels,
+	}))
+	dash.OrgID = 1
+	return dash
+}
+
+func TestExtractDependencies(t *testing.T) {
+	dash := testDashboard("dash",
+		map[string]any{"id": 1, "type": "timeseries", "title": "CPU", "datasource": map[string]any{"uid": "prom", "type": "prometheus"}},
+		map[string]any{"id": 2, "type": "logs", "title": "Logs", "datasource": "Loki"},
+		map[string]any{"id": 3, "title": "Shared", "libraryPanel": map[string]any{"uid": "lib1", "name": "Shared"}},
+		map[string]any{"id": 4, "type": "timeseries", "title": "Mixed", "datasource": map[string]any{"uid": "-- Mixed --"}, "targets": []any{
+			map[string]any{"refId": "A", "datasource": map[string]any{"uid": "loki"}},
+			map[string]any{"refId": "B", "datasource": map[string]any{"uid": "prom"}},
+		}},
+		map[string]any{"id": 5, "type": "row", "title": "Row", "collapsed": true, "panels": []any{
+			map[string]any{"id": 6, "type": "stat", "title": "Nested", "datasource": map[string]any{"uid": "prom"}},
+		}},
+		map[string]any{"id": 7, "type": "timeseries", "title": "Default"},
+	)
+
+	deps, err := extractDependencies(context.Background(), testLookup(), dash)
+	require.NoError(t, err)
+
+	type ref struct {
+		PanelID    int64
+		PanelTitle string
+		Kind       Kind
+		TargetUID  string
+		TargetType string
+	}
+	actual := make([]ref, 0, len(deps))
+	for _, d := range deps {
+		assert.Equal(t, int64(1), d.OrgID)
+		assert.Equal(t, "dash", d.DashboardUID)
+		actual = append(actual, ref{d.PanelID, d.PanelTitle, d.Kind, d.TargetUID, d.TargetType})
+	}
+
+	assert.Equal(t, []ref{
+		{1, "CPU", KindDataSource, "prom", "prometheus"},
+		{2, "Logs", KindDataSource, "loki", "loki"},
+		{3, "Shared", KindLibraryPanel, "lib1", ""},
+		{4, "Mixed", KindDataSource, "loki", "loki"},
+		{4, "Mixed", KindDataSource, "prom", "prometheus"},
+		{6, "Nested", KindDataSource, "prom", "prometheus"},
+		{7, "Default", KindDataSource, "prom", "prometheus"},
+	}, actual)
+}
+
+func TestPanelIDFromSummaryUID(t *testing.T) {
+	assert.Equal(t, int64(12), panelIDFromSummaryUID("dash#12"))
+	assert.Equal(t, int64(0), panelIDFromSummaryUID("dash"))
+	assert.Equal(t, int64(0), panelIDFromSummaryUID("dash#x"))
+}
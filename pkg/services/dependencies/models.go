@@ -0,0 +1,121 @@
+package dependencies
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrDashboardNotFound = errutil.NotFound("dependencies.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
+	ErrInternal          = errutil.Internal("dependencies.internal")
+)
+
+// Kind is the type of resource a dashboard panel depends on
+type Kind string
+
+const (
+	KindDataSource   Kind = "datasource"
+	KindLibraryPanel Kind = "library_panel"
+)
+
+// dependency is a single reference from a dashboard panel to a data source or a library panel
+type dependency struct {
+	ID           int64  `xorm:"pk autoincr 'id'"`
+	OrgID        int64  `xorm:"org_id"`
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	PanelTitle   string `xorm:"panel_title"`
+	Kind         Kind   `xorm:"kind"`
+	TargetUID    string `xorm:"target_uid"`
+	TargetType   string `xorm:"target_type"`
+}
+
+func (dependency) TableName() string {
+	return "dashboard_dependency"
+}
+
+// indexedDashboard records which dashboard version the stored dependencies were computed from
+type indexedDashboard struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	OrgID        int64     `xorm:"org_id"`
+	DashboardUID string    `xorm:"dashboard_uid"`
+	Version      int       `xorm:"version"`
+	Updated      time.Time `xorm:"updated"`
+}
+
+func (indexedDashboard) TableName() string {
+	return "dashboard_dependency_index"
+}
+
+type dashboardKey struct {
+	OrgID int64
+	UID   string
+}
+
+// PanelRef identifies a panel within a dashboard
+type PanelRef struct {
+	ID    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// DashboardRef is a dashboard that references a resource, with the panels doing so
+type DashboardRef struct {
+	UID       string     `json:"uid"`
+	Title     string     `json:"title"`
+	FolderUID string     `json:"folderUid"`
+	Panels    []PanelRef `json:"panels,omitempty"`
+}
+
+// AlertRuleRef is an alert rule that references a resource
+type AlertRuleRef struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUid"`
+	RuleGroup string `json:"ruleGroup"`
+}
+
+// CorrelationRef is a correlation from or to a data source
+type CorrelationRef struct {
+	UID       string `json:"uid"`
+	Label     string `json:"label"`
+	SourceUID string `json:"sourceUid"`
+	TargetUID string `json:"targetUid,omitempty"`
+}
+
+// PublicDashboardRef is a public dashboard exposing an affected dashboard
+type PublicDashboardRef struct {
+	UID          string `json:"uid"`
+	DashboardUID string `json:"dashboardUid"`
+	IsEnabled    bool   `json:"isEnabled"`
+}
+
+// Dependents lists everything that would be affected if a resource was removed
+type Dependents struct {
+	Dashboards       []DashboardRef       `json:"dashboards"`
+	AlertRules       []AlertRuleRef       `json:"alertRules"`
+	Correlations     []CorrelationRef     `json:"correlations"`
+	PublicDashboards []PublicDashboardRef `json:"publicDashboards"`
+}
+
+// DataSourceDependency is a data source used by the panels of a dashboard
+type DataSourceDependency struct {
+	UID    string     `json:"uid"`
+	Type   string     `json:"type,omitempty"`
+	Panels []PanelRef `json:"panels"`
+}
+
+// LibraryPanelDependency is a library panel used by the panels of a dashboard
+type LibraryPanelDependency struct {
+	UID    string     `json:"uid"`
+	Panels []PanelRef `json:"panels"`
+}
+
+// DashboardDependencies lists everything a dashboard depends on
+type DashboardDependencies struct {
+	UID           string                   `json:"uid"`
+	Title         string                   `json:"title"`
+	FolderUID     string                   `json:"folderUid"`
+	DataSources   []DataSourceDependency   `json:"dataSources"`
+	LibraryPanels []LibraryPanelDependency `json:"libraryPanels"`
+}
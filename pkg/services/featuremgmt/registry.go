@@ -1275,6 +1275,13 @@ var (
 			HideFromDocs:      true,
 			HideFromAdminPage: true,
 		},
+		{
+			Name:         "lokiServerSideQuerySplitting",
+			Description:  "Split long Loki range queries into time chunks on the server",
+			Stage:        FeatureStageExperimental,
+			FrontendOnly: false,
+			Owner:        grafanaObservabilityLogsSquad,
+		},
 	}
 )
 
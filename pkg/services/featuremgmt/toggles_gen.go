@@ -690,4 +690,8 @@ const (
 	// FlagDashboardRestore
 	// Enables deleted dashboard restore feature
 	FlagDashboardRestore = "dashboardRestore"
+
+	// FlagLokiServerSideQuerySplitting
+	// Split long Loki range queries into time chunks on the server
+	FlagLokiServerSideQuerySplitting = "lokiServerSideQuerySplitting"
 )
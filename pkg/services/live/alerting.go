@@ -0,0 +1,34 @@
+package live
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// alertmanagerSender delivers alerts produced by pipeline frame outputs to the
+// embedded Alertmanager of an organization.
+type alertmanagerSender struct {
+	alertNG *ngalert.AlertNG
+}
+
+func (s *alertmanagerSender) SendAlerts(ctx context.Context, orgID int64, alerts apimodels.PostableAlerts) error {
+	if s.alertNG == nil || s.alertNG.MultiOrgAlertmanager == nil {
+		return errors.New("unified alerting is not enabled")
+	}
+	am, err := s.alertNG.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+	if err != nil {
+		return err
+	}
+	return am.PutAlerts(ctx, alerts)
+}
+
+// dryRunAlertSender discards alerts, it is used when testing channel rules so that
+// converting sample data never notifies anyone.
+type dryRunAlertSender struct{}
+
+func (s dryRunAlertSender) SendAlerts(_ context.Context, _ int64, _ apimodels.PostableAlerts) error {
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
@@ -49,6 +50,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/live/pushws"
 	"github.com/grafana/grafana/pkg/services/live/runstream"
 	"github.com/grafana/grafana/pkg/services/live/survey"
+	"github.com/grafana/grafana/pkg/services/ngalert"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
@@ -78,7 +80,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, alertNG *ngalert.AlertNG, tracer tracing.Tracer) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -97,6 +99,8 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		},
 		usageStatsService: usageStatsService,
 		orgService:        orgService,
+		alertSender:       &alertmanagerSender{alertNG: alertNG},
+		tracer:            tracer,
 	}
 
 	logger.Debug("GrafanaLive initialization", "ha", g.IsHA())
@@ -164,6 +168,12 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 
 	g.ManagedStreamRunner = managedStreamRunner
 
+	if cfg.LivePipelineEnabled {
+		if err := g.initPipeline(); err != nil {
+			return nil, err
+		}
+	}
+
 	g.contextGetter = liveplugin.NewContextGetter(g.PluginContextProvider, g.DataSourceCache)
 	pipelinedChannelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, g.Pipeline)
 	numLocalSubscribersGetter := liveplugin.NewNumLocalSubscribersGetter(node)
@@ -398,6 +408,8 @@ type GrafanaLive struct {
 	ManagedStreamRunner *managedstream.Runner
 	Pipeline            *pipeline.Pipeline
 	pipelineStorage     pipeline.Storage
+	alertSender         pipeline.AlertSender
+	tracer              tracing.Tracer
 
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
@@ -1108,6 +1120,39 @@ func (s *DryRunRuleStorage) ListChannelRules(_ context.Context, _ int64) ([]pipe
 	return s.ChannelRules, nil
 }
 
+// initPipeline runs the channel rules stored in the data path for the messages
+// published to their channels.
+func (g *GrafanaLive) initPipeline() error {
+	storage := &pipeline.FileStorage{
+		DataPath:       g.Cfg.DataPath,
+		SecretsService: g.SecretsService,
+	}
+	g.pipelineStorage = storage
+	channelRuleGetter := pipeline.NewCacheSegmentedTree(g.pipelineRuleBuilder(storage, g.alertSender, pipeline.NewExpressionAlertStorage()))
+	p, err := pipeline.New(channelRuleGetter)
+	if err != nil {
+		return err
+	}
+	g.Pipeline = p
+	return nil
+}
+
+// pipelineRuleBuilder builds the channel rules of the storage, expression alerts
+// are sent with the alert sender.
+func (g *GrafanaLive) pipelineRuleBuilder(storage pipeline.Storage, alertSender pipeline.AlertSender, expressionAlerts *pipeline.ExpressionAlertStorage) *pipeline.StorageRuleBuilder {
+	return &pipeline.StorageRuleBuilder{
+		Node:                 g.node,
+		ManagedStream:        g.ManagedStreamRunner,
+		FrameStorage:         pipeline.NewFrameStorage(),
+		Storage:              storage,
+		ChannelHandlerGetter: g,
+		SecretsService:       g.SecretsService,
+		AlertSender:          alertSender,
+		ExpressionAlerts:     expressionAlerts,
+		Tracer:               g.tracer,
+	}
+}
+
 // HandlePipelineConvertTestHTTP ...
 func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext) response.Response {
 	body, err := io.ReadAll(c.Req.Body)
@@ -1122,13 +1167,8 @@ func (g *GrafanaLive) HandlePipelineConvertTestHTTP(c *contextmodel.ReqContext)
 	storage := &DryRunRuleStorage{
 		ChannelRules: req.ChannelRules,
 	}
-	builder := &pipeline.StorageRuleBuilder{
-		Node:                 g.node,
-		ManagedStream:        g.ManagedStreamRunner,
-		FrameStorage:         pipeline.NewFrameStorage(),
-		Storage:              storage,
-		ChannelHandlerGetter: g,
-	}
+	// Dry runs must not notify anyone nor change the state of real expression alerts.
+	builder := g.pipelineRuleBuilder(storage, dryRunAlertSender{}, pipeline.NewExpressionAlertStorage())
 	channelRuleGetter := pipeline.NewCacheSegmentedTree(builder)
 	pipe, err := pipeline.New(channelRuleGetter)
 	if err != nil {
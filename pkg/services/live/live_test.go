@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http/httptest"
 	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -11,11 +13,13 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
@@ -36,12 +40,51 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil, nil)
 
 	// Proceeds without live HA if redis is unavaialble
 	require.NoError(t, err)
 }
 
+type fakeAlertSender struct {
+	orgIDs []int64
+	alerts []apimodels.PostableAlerts
+}
+
+func (s *fakeAlertSender) SendAlerts(_ context.Context, orgID int64, alerts apimodels.PostableAlerts) error {
+	s.orgIDs = append(s.orgIDs, orgID)
+	s.alerts = append(s.alerts, alerts)
+	return nil
+}
+
+func Test_initPipeline_ExpressionAlerts(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.DataPath = t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(cfg.DataPath, "pipeline"), 0750))
+	rules := `{"rules": [{"pattern": "stream/test/cpu", "settings": {
+		"converter": {"type": "jsonAuto"},
+		"frameOutputs": [{"type": "expressionAlert", "expressionAlert": {
+			"expression": "$cpu > 90", "variables": {"cpu": "cpu"}, "alertName": "HighCPU"
+		}}]
+	}}]}`
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.DataPath, "pipeline", "live-channel-rules.json"), []byte(rules), 0600))
+
+	sender := &fakeAlertSender{}
+	g := &GrafanaLive{Cfg: cfg, alertSender: sender, tracer: tracing.InitializeTracerForTest()}
+	require.NoError(t, g.initPipeline())
+
+	ok, err := g.Pipeline.ProcessInput(context.Background(), 1, "stream/test/cpu", []byte(`{"cpu": 50}`))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Empty(t, sender.alerts)
+
+	_, err = g.Pipeline.ProcessInput(context.Background(), 1, "stream/test/cpu", []byte(`{"cpu": 95}`))
+	require.NoError(t, err)
+	require.Equal(t, []int64{1}, sender.orgIDs)
+	require.Len(t, sender.alerts, 1)
+	require.Equal(t, "HighCPU", sender.alerts[0].PostableAlerts[0].Labels["alertname"])
+}
+
 func Test_runConcurrentlyIfNeeded_Concurrent(t *testing.T) {
 	doneCh := make(chan struct{})
 	f := func() {
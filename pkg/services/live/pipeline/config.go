@@ -100,15 +100,16 @@ type DataOutputterConfig struct {
 }
 
 type FrameOutputterConfig struct {
-	Type                    string                     `json:"type" ts_type:"Omit<keyof FrameOutputterConfig, 'type'>"`
-	ManagedStreamConfig     *ManagedStreamOutputConfig `json:"managedStream,omitempty"`
-	MultipleOutputterConfig *MultipleOutputterConfig   `json:"multiple,omitempty"`
-	RedirectOutputConfig    *RedirectOutputConfig      `json:"redirect,omitempty"`
-	ConditionalOutputConfig *ConditionalOutputConfig   `json:"conditional,omitempty"`
-	ThresholdOutputConfig   *ThresholdOutputConfig     `json:"threshold,omitempty"`
-	RemoteWriteOutputConfig *RemoteWriteOutputConfig   `json:"remoteWrite,omitempty"`
-	LokiOutputConfig        *LokiOutputConfig          `json:"loki,omitempty"`
-	ChangeLogOutputConfig   *ChangeLogOutputConfig     `json:"changeLog,omitempty"`
+	Type                    string                       `json:"type" ts_type:"Omit<keyof FrameOutputterConfig, 'type'>"`
+	ManagedStreamConfig     *ManagedStreamOutputConfig   `json:"managedStream,omitempty"`
+	MultipleOutputterConfig *MultipleOutputterConfig     `json:"multiple,omitempty"`
+	RedirectOutputConfig    *RedirectOutputConfig        `json:"redirect,omitempty"`
+	ConditionalOutputConfig *ConditionalOutputConfig     `json:"conditional,omitempty"`
+	ThresholdOutputConfig   *ThresholdOutputConfig       `json:"threshold,omitempty"`
+	RemoteWriteOutputConfig *RemoteWriteOutputConfig     `json:"remoteWrite,omitempty"`
+	LokiOutputConfig        *LokiOutputConfig            `json:"loki,omitempty"`
+	ChangeLogOutputConfig   *ChangeLogOutputConfig       `json:"changeLog,omitempty"`
+	ExpressionAlertConfig   *ExpressionAlertOutputConfig `json:"expressionAlert,omitempty"`
 }
 
 type MultipleFrameConditionCheckerConfig struct {
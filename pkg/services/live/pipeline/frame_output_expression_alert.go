@@ -0,0 +1,458 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/live/orgchannel"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type ExpressionAlertOutputConfig struct {
+	// Expression is a math expression (see pkg/expr) evaluated over reduced window values,
+	// for example "$cpu > 90 && $load > 4". Non-zero results are considered alerting.
+	Expression string `json:"expression"`
+	// Variables maps expression variable names (without $) to frame field names.
+	Variables map[string]string `json:"variables"`
+	// Reducer is applied to the window values of every variable before evaluation. Defaults to last.
+	Reducer mathexp.ReducerID `json:"reducer,omitempty"`
+	// WindowMilliseconds is the size of the sliding window values are kept for.
+	WindowMilliseconds int64 `json:"windowMilliseconds"`
+	// LabelFields lists string fields whose row values become alert labels.
+	LabelFields []string          `json:"labelFields,omitempty"`
+	AlertName   string            `json:"alertName"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Channel is an optional channel to output state transition frames to.
+	Channel string `json:"channel,omitempty"`
+}
+
+// AlertSender delivers alerts to the Alertmanager of an organization.
+type AlertSender interface {
+	SendAlerts(ctx context.Context, orgID int64, alerts apimodels.PostableAlerts) error
+}
+
+const (
+	expressionAlertDefaultWindow = time.Minute
+	// expressionAlertResendDelay is how often alerting instances are re-sent so that
+	// Alertmanager does not resolve them on its own.
+	expressionAlertResendDelay = time.Minute
+	// expressionAlertResolveTimeout is how long Alertmanager keeps an alert firing
+	// after it was last sent.
+	expressionAlertResolveTimeout = 4 * expressionAlertResendDelay
+	// expressionAlertEvictionInterval is how often the state of channels which
+	// stopped reporting is looked for.
+	expressionAlertEvictionInterval = time.Minute
+)
+
+// ExpressionAlertOutput evaluates a math expression over a sliding window of streamed
+// values and sends alerts to Alertmanager when the result changes state.
+type ExpressionAlertOutput struct {
+	alertSender AlertSender
+	storage     *ExpressionAlertStorage
+	tracer      tracing.Tracer
+	config      ExpressionAlertOutputConfig
+	expr        *mathexp.Expr
+	now         func() time.Time
+}
+
+func NewExpressionAlertOutput(alertSender AlertSender, storage *ExpressionAlertStorage, tracer tracing.Tracer, config ExpressionAlertOutputConfig) (*ExpressionAlertOutput, error) {
+	if config.AlertName == "" {
+		return nil, errors.New("alert name is required")
+	}
+	if len(config.Variables) == 0 {
+		return nil, errors.New("at least one variable is required")
+	}
+	if config.Reducer == "" {
+		config.Reducer = mathexp.ReducerLast
+	}
+	if _, err := mathexp.GetReduceFunc(config.Reducer); err != nil {
+		return nil, err
+	}
+	e, err := mathexp.New(config.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %w", err)
+	}
+	return &ExpressionAlertOutput{
+		alertSender: alertSender,
+		storage:     storage,
+		tracer:      tracer,
+		config:      config,
+		expr:        e,
+		now:         time.Now,
+	}, nil
+}
+
+const FrameOutputTypeExpressionAlert = "expressionAlert"
+
+func (out *ExpressionAlertOutput) Type() string {
+	return FrameOutputTypeExpressionAlert
+}
+
+func (out *ExpressionAlertOutput) OutputFrame(ctx context.Context, vars Vars, frame *data.Frame) ([]*ChannelFrame, error) {
+	if frame == nil {
+		return nil, nil
+	}
+	now := out.now()
+
+	window := expressionAlertDefaultWindow
+	if out.config.WindowMilliseconds > 0 {
+		window = time.Duration(out.config.WindowMilliseconds) * time.Millisecond
+	}
+
+	// Once the window is empty and Alertmanager resolved the sent alerts on its own
+	// nothing is left to keep for an idle channel.
+	state := out.storage.get(vars.OrgID, vars.Channel, out.config.AlertName, now, window+expressionAlertResolveTimeout)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	out.appendFrame(state, frame, now)
+	state.prune(now.Add(-window))
+
+	mathVars, err := out.reduceVars(state)
+	if err != nil {
+		return nil, err
+	}
+	results, err := out.expr.Execute(out.config.AlertName, mathVars, out.tracer)
+	if err != nil {
+		return nil, fmt.Errorf("error evaluating expression: %w", err)
+	}
+
+	evaluated := map[string]expressionAlertResult{}
+	for _, value := range results.Values {
+		var v *float64
+		switch val := value.(type) {
+		case mathexp.Number:
+			v = val.GetFloat64Value()
+		case mathexp.Scalar:
+			v = val.GetFloat64Value()
+		case mathexp.NoData:
+			continue
+		default:
+			return nil, fmt.Errorf("expression must return numbers, got %s", value.Type())
+		}
+		labels := out.alertLabels(value.GetLabels())
+		evaluated[labels.String()] = expressionAlertResult{
+			labels:   labels,
+			value:    v,
+			alerting: v != nil && !math.IsNaN(*v) && *v != 0,
+		}
+	}
+
+	var alerts apimodels.PostableAlerts
+	var transitions []expressionAlertTransition
+	for key, res := range evaluated {
+		instance, ok := state.instances[key]
+		if !ok {
+			instance = &expressionAlertInstance{labels: res.labels}
+			state.instances[key] = instance
+		}
+		changed := instance.alerting != res.alerting
+		instance.value = res.value
+		if changed {
+			instance.alerting = res.alerting
+			transitions = append(transitions, expressionAlertTransition{labels: res.labels, value: res.value, alerting: res.alerting})
+			if res.alerting {
+				instance.startsAt = now
+			}
+		}
+		if res.alerting && (changed || now.Sub(instance.lastSentAt) >= expressionAlertResendDelay) {
+			alerts.PostableAlerts = append(alerts.PostableAlerts, out.postableAlert(instance, now.Add(expressionAlertResolveTimeout)))
+			instance.lastSentAt = now
+		} else if changed && !res.alerting {
+			alerts.PostableAlerts = append(alerts.PostableAlerts, out.postableAlert(instance, now))
+			instance.lastSentAt = now
+		}
+	}
+	// Instances which are no longer returned by the expression are resolved.
+	for key, instance := range state.instances {
+		if _, ok := evaluated[key]; ok {
+			continue
+		}
+		if instance.alerting {
+			alerts.PostableAlerts = append(alerts.PostableAlerts, out.postableAlert(instance, now))
+			transitions = append(transitions, expressionAlertTransition{labels: instance.labels, alerting: false})
+		}
+		delete(state.instances, key)
+	}
+
+	if len(alerts.PostableAlerts) > 0 {
+		if out.alertSender == nil {
+			return nil, errors.New("no alert sender configured")
+		}
+		if err := out.alertSender.SendAlerts(ctx, vars.OrgID, alerts); err != nil {
+			return nil, fmt.Errorf("error sending alerts: %w", err)
+		}
+	}
+
+	if out.config.Channel == "" || len(transitions) == 0 {
+		return nil, nil
+	}
+	return []*ChannelFrame{{
+		Channel: out.config.Channel,
+		Frame:   transitionsToFrame(transitions, now),
+	}}, nil
+}
+
+// appendFrame adds values of configured variable fields to the window.
+func (out *ExpressionAlertOutput) appendFrame(state *expressionAlertState, frame *data.Frame, now time.Time) {
+	timeIndex := -1
+	labelFieldIndexes := map[string]int{}
+	for i, f := range frame.Fields {
+		if timeIndex < 0 && f.Type().Time() {
+			timeIndex = i
+		}
+		for _, name := range out.config.LabelFields {
+			if f.Name == name && (f.Type() == data.FieldTypeString || f.Type() == data.FieldTypeNullableString) {
+				labelFieldIndexes[name] = i
+			}
+		}
+	}
+
+	for varName, fieldName := range out.config.Variables {
+		for _, f := range frame.Fields {
+			if f.Name != fieldName || !f.Type().Numeric() {
+				continue
+			}
+			for row := 0; row < f.Len(); row++ {
+				t := now
+				if timeIndex >= 0 {
+					if v, ok := frame.Fields[timeIndex].ConcreteAt(row); ok {
+						if ts, ok := v.(time.Time); ok {
+							t = ts
+						}
+					}
+				}
+				var labels data.Labels
+				if f.Labels != nil {
+					labels = f.Labels.Copy()
+				}
+				for name, idx := range labelFieldIndexes {
+					v, ok := frame.Fields[idx].ConcreteAt(row)
+					if !ok {
+						continue
+					}
+					if labels == nil {
+						labels = data.Labels{}
+					}
+					labels[name] = v.(string)
+				}
+				value, err := f.NullableFloatAt(row)
+				if err != nil {
+					continue
+				}
+				state.append(varName, labels, t, value)
+			}
+		}
+	}
+}
+
+func (out *ExpressionAlertOutput) reduceVars(state *expressionAlertState) (mathexp.Vars, error) {
+	mathVars := mathexp.Vars{}
+	for varName := range out.config.Variables {
+		results := mathexp.Results{}
+		for _, w := range state.windows[varName] {
+			s := mathexp.NewSeries(varName, w.labels, 0)
+			for i := range w.times {
+				s.AppendPoint(w.times[i], w.values[i])
+			}
+			n, err := s.Reduce(varName, out.config.Reducer, nil)
+			if err != nil {
+				return nil, err
+			}
+			results.Values = append(results.Values, n)
+		}
+		if len(results.Values) == 0 {
+			results.Values = append(results.Values, mathexp.NewNoData())
+		}
+		mathVars[varName] = results
+	}
+	return mathVars, nil
+}
+
+func (out *ExpressionAlertOutput) alertLabels(resultLabels data.Labels) data.Labels {
+	labels := data.Labels{}
+	for k, v := range resultLabels {
+		labels[k] = v
+	}
+	for k, v := range out.config.Labels {
+		labels[k] = v
+	}
+	labels["alertname"] = out.config.AlertName
+	return labels
+}
+
+func (out *ExpressionAlertOutput) postableAlert(instance *expressionAlertInstance, endsAt time.Time) amv2.PostableAlert {
+	annotations := amv2.LabelSet{}
+	for k, v := range out.config.Annotations {
+		annotations[k] = v
+	}
+	if instance.value != nil {
+		annotations["__value_string__"] = fmt.Sprintf("%v", *instance.value)
+	}
+	return amv2.PostableAlert{
+		Annotations: annotations,
+		StartsAt:    strfmt.DateTime(instance.startsAt),
+		EndsAt:      strfmt.DateTime(endsAt),
+		Alert: amv2.Alert{
+			Labels: amv2.LabelSet(instance.labels),
+		},
+	}
+}
+
+func transitionsToFrame(transitions []expressionAlertTransition, now time.Time) *data.Frame {
+	fTime := data.NewFieldFromFieldType(data.FieldTypeTime, 0)
+	fTime.Name = "time"
+	fLabels := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	fLabels.Name = "labels"
+	fState := data.NewFieldFromFieldType(data.FieldTypeString, 0)
+	fState.Name = "state"
+	fValue := data.NewFieldFromFieldType(data.FieldTypeNullableFloat64, 0)
+	fValue.Name = "value"
+	for _, t := range transitions {
+		fTime.Append(now)
+		fLabels.Append(t.labels.String())
+		if t.alerting {
+			fState.Append("alerting")
+		} else {
+			fState.Append("normal")
+		}
+		fValue.Append(t.value)
+	}
+	return data.NewFrame("state", fTime, fLabels, fState, fValue)
+}
+
+type expressionAlertResult struct {
+	labels   data.Labels
+	value    *float64
+	alerting bool
+}
+
+type expressionAlertTransition struct {
+	labels   data.Labels
+	value    *float64
+	alerting bool
+}
+
+type expressionAlertInstance struct {
+	labels     data.Labels
+	value      *float64
+	alerting   bool
+	startsAt   time.Time
+	lastSentAt time.Time
+}
+
+type expressionAlertWindow struct {
+	labels data.Labels
+	times  []time.Time
+	values []*float64
+}
+
+type expressionAlertState struct {
+	// updatedAt and ttl are guarded by the mutex of the storage.
+	updatedAt time.Time
+	ttl       time.Duration
+
+	mu sync.Mutex
+	// windows holds window values by variable name and series labels.
+	windows   map[string]map[string]*expressionAlertWindow
+	instances map[string]*expressionAlertInstance
+}
+
+func (s *expressionAlertState) append(varName string, labels data.Labels, t time.Time, value *float64) {
+	series, ok := s.windows[varName]
+	if !ok {
+		series = map[string]*expressionAlertWindow{}
+		s.windows[varName] = series
+	}
+	key := labels.String()
+	w, ok := series[key]
+	if !ok {
+		w = &expressionAlertWindow{labels: labels}
+		series[key] = w
+	}
+	w.times = append(w.times, t)
+	w.values = append(w.values, value)
+}
+
+// prune removes values older than the provided time and drops empty series. Values
+// are not required to be sorted by time since frames may carry out of order rows.
+func (s *expressionAlertState) prune(from time.Time) {
+	for varName, series := range s.windows {
+		for key, w := range series {
+			n := 0
+			for i, t := range w.times {
+				if t.Before(from) {
+					continue
+				}
+				w.times[n] = t
+				w.values[n] = w.values[i]
+				n++
+			}
+			w.times = w.times[:n]
+			w.values = w.values[:n]
+			if len(w.times) == 0 {
+				delete(series, key)
+			}
+		}
+		if len(series) == 0 {
+			delete(s.windows, varName)
+		}
+	}
+}
+
+// ExpressionAlertStorage keeps expression alert windows and instance states in memory
+// so that they survive channel rule rebuilds. The state of channels which stop
+// reporting is evicted. Not usable in HA setup.
+type ExpressionAlertStorage struct {
+	mu          sync.Mutex
+	states      map[string]*expressionAlertState
+	lastEvicted time.Time
+}
+
+func NewExpressionAlertStorage() *ExpressionAlertStorage {
+	return &ExpressionAlertStorage{
+		states: map[string]*expressionAlertState{},
+	}
+}
+
+// get returns the state of the alert of the channel, it is evicted once it isn't
+// used for longer than the ttl.
+func (s *ExpressionAlertStorage) get(orgID int64, channel string, alertName string, now time.Time, ttl time.Duration) *expressionAlertState {
+	key := orgchannel.PrependOrgID(orgID, channel) + "/" + alertName
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastEvicted) >= expressionAlertEvictionInterval {
+		s.evictIdle(now)
+		s.lastEvicted = now
+	}
+	state, ok := s.states[key]
+	if !ok {
+		state = &expressionAlertState{
+			windows:   map[string]map[string]*expressionAlertWindow{},
+			instances: map[string]*expressionAlertInstance{},
+		}
+		s.states[key] = state
+	}
+	state.updatedAt = now
+	state.ttl = ttl
+	return state
+}
+
+func (s *ExpressionAlertStorage) evictIdle(now time.Time) {
+	for key, state := range s.states {
+		if now.Sub(state.updatedAt) > state.ttl {
+			delete(s.states, key)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+type fakeAlertSender struct {
+	orgIDs []int64
+	alerts []apimodels.PostableAlerts
+}
+
+func (s *fakeAlertSender) SendAlerts(_ context.Context, orgID int64, alerts apimodels.PostableAlerts) error {
+	s.orgIDs = append(s.orgIDs, orgID)
+	s.alerts = append(s.alerts, alerts)
+	return nil
+}
+
+func expressionAlertTestFrame(ts time.Time, value float64) *data.Frame {
+	return data.NewFrame("test",
+		data.NewField("time", nil, []time.Time{ts}),
+		data.NewField("cpu", data.Labels{"host": "a"}, []float64{value}),
+	)
+}
+
+func TestExpressionAlertOutput_StateTransitions(t *testing.T) {
+	sender := &fakeAlertSender{}
+	out, err := NewExpressionAlertOutput(sender, NewExpressionAlertStorage(), tracing.InitializeTracerForTest(), ExpressionAlertOutputConfig{
+		Expression: "$cpu > 90",
+		Variables:  map[string]string{"cpu": "cpu"},
+		AlertName:  "HighCPU",
+		Labels:     map[string]string{"team": "noc"},
+		Channel:    "stream/test/state",
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	out.now = func() time.Time { return now }
+	vars := Vars{OrgID: 1, Channel: "stream/test/cpu"}
+
+	channelFrames, err := out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 50))
+	require.NoError(t, err)
+	require.Nil(t, channelFrames)
+	require.Empty(t, sender.alerts)
+
+	now = now.Add(time.Second)
+	channelFrames, err = out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 95))
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+	require.Equal(t, "stream/test/state", channelFrames[0].Channel)
+	require.Len(t, sender.alerts, 1)
+	require.Equal(t, []int64{1}, sender.orgIDs)
+	firing := sender.alerts[0].PostableAlerts
+	require.Len(t, firing, 1)
+	require.Equal(t, "HighCPU", firing[0].Labels["alertname"])
+	require.Equal(t, "a", firing[0].Labels["host"])
+	require.Equal(t, "noc", firing[0].Labels["team"])
+	require.True(t, time.Time(firing[0].EndsAt).After(now))
+
+	// No state change, alert is not re-sent before the resend delay.
+	now = now.Add(time.Second)
+	channelFrames, err = out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 96))
+	require.NoError(t, err)
+	require.Nil(t, channelFrames)
+	require.Len(t, sender.alerts, 1)
+
+	now = now.Add(time.Second)
+	channelFrames, err = out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 10))
+	require.NoError(t, err)
+	require.Len(t, channelFrames, 1)
+	require.Len(t, sender.alerts, 2)
+	resolved := sender.alerts[1].PostableAlerts
+	require.Len(t, resolved, 1)
+	require.Equal(t, now, time.Time(resolved[0].EndsAt))
+}
+
+func TestExpressionAlertOutput_Window(t *testing.T) {
+	sender := &fakeAlertSender{}
+	out, err := NewExpressionAlertOutput(sender, NewExpressionAlertStorage(), tracing.InitializeTracerForTest(), ExpressionAlertOutputConfig{
+		Expression:         "$cpu > 90",
+		Variables:          map[string]string{"cpu": "cpu"},
+		Reducer:            "mean",
+		WindowMilliseconds: 10000,
+		AlertName:          "HighCPU",
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	out.now = func() time.Time { return now }
+	vars := Vars{OrgID: 1, Channel: "stream/test/cpu"}
+
+	_, err = out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 100))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 1)
+
+	// Mean of 100 and 60 is below the threshold.
+	now = now.Add(time.Second)
+	_, err = out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 60))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 2)
+
+	// Once earlier values leave the window only the latest one is evaluated.
+	now = now.Add(20 * time.Second)
+	_, err = out.OutputFrame(context.Background(), vars, expressionAlertTestFrame(now, 100))
+	require.NoError(t, err)
+	require.Len(t, sender.alerts, 3)
+}
+
+func TestExpressionAlertState_PruneUnsorted(t *testing.T) {
+	now := time.Now()
+	state := &expressionAlertState{
+		windows:   map[string]map[string]*expressionAlertWindow{},
+		instances: map[string]*expressionAlertInstance{},
+	}
+	v1, v2, v3 := 1.0, 2.0, 3.0
+	state.append("cpu", nil, now, &v1)
+	state.append("cpu", nil, now.Add(-time.Hour), &v2)
+	state.append("cpu", nil, now.Add(time.Second), &v3)
+
+	state.prune(now.Add(-time.Minute))
+
+	w := state.windows["cpu"][data.Labels(nil).String()]
+	require.NotNil(t, w)
+	require.Equal(t, []time.Time{now, now.Add(time.Second)}, w.times)
+	require.Equal(t, []*float64{&v1, &v3}, w.values)
+}
+
+func TestExpressionAlertStorage_EvictIdle(t *testing.T) {
+	storage := NewExpressionAlertStorage()
+	now := time.Now()
+	ttl := time.Minute + expressionAlertResolveTimeout
+
+	idle := storage.get(1, "stream/test/idle", "HighCPU", now, ttl)
+	active := storage.get(1, "stream/test/active", "HighCPU", now, ttl)
+
+	// The idle channel stops reporting, its state is kept until the ttl passed.
+	now = now.Add(ttl)
+	require.Same(t, active, storage.get(1, "stream/test/active", "HighCPU", now, ttl))
+	require.Len(t, storage.states, 2)
+
+	now = now.Add(expressionAlertEvictionInterval)
+	require.Same(t, active, storage.get(1, "stream/test/active", "HighCPU", now, ttl))
+	require.Len(t, storage.states, 1)
+	require.NotSame(t, idle, storage.get(1, "stream/test/idle", "HighCPU", now, ttl))
+}
+
+func TestNewExpressionAlertOutput_Validation(t *testing.T) {
+	_, err := NewExpressionAlertOutput(nil, NewExpressionAlertStorage(), nil, ExpressionAlertOutputConfig{
+		Expression: "$cpu > 90",
+		Variables:  map[string]string{"cpu": "cpu"},
+	})
+	require.Error(t, err)
+
+	_, err = NewExpressionAlertOutput(nil, NewExpressionAlertStorage(), nil, ExpressionAlertOutputConfig{
+		Expression: "$cpu >",
+		Variables:  map[string]string{"cpu": "cpu"},
+		AlertName:  "HighCPU",
+	})
+	require.Error(t, err)
+
+	_, err = NewExpressionAlertOutput(nil, NewExpressionAlertStorage(), nil, ExpressionAlertOutputConfig{
+		Expression: "$cpu > 90",
+		Variables:  map[string]string{"cpu": "cpu"},
+		Reducer:    "unknown",
+		AlertName:  "HighCPU",
+	})
+	require.Error(t, err)
+}
@@ -48,6 +48,11 @@ var FrameOutputsRegistry = []EntityInfo{
 		Type:        FrameOutputTypeLoki,
 		Description: "output frame as JSON to Loki",
 	},
+	{
+		Type:        FrameOutputTypeExpressionAlert,
+		Description: "evaluate math expression over a sliding window and send alerts to Alertmanager on state change",
+		Example:     ExpressionAlertOutputConfig{},
+	},
 }
 
 var ConvertersRegistry = []EntityInfo{
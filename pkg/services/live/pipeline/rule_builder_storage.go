@@ -6,6 +6,7 @@ import (
 
 	"github.com/centrifugal/centrifuge"
 
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/secrets"
 )
@@ -17,6 +18,9 @@ type StorageRuleBuilder struct {
 	Storage              Storage
 	ChannelHandlerGetter ChannelHandlerGetter
 	SecretsService       secrets.Service
+	AlertSender          AlertSender
+	ExpressionAlerts     *ExpressionAlertStorage
+	Tracer               tracing.Tracer
 }
 
 func (f *StorageRuleBuilder) extractSubscriber(config *SubscriberConfig) (Subscriber, error) {
@@ -249,6 +253,11 @@ func (f *StorageRuleBuilder) extractFrameOutputter(config *FrameOutputterConfig,
 			return nil, missingConfiguration
 		}
 		return NewChangeLogFrameOutput(f.FrameStorage, *config.ChangeLogOutputConfig), nil
+	case FrameOutputTypeExpressionAlert:
+		if config.ExpressionAlertConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewExpressionAlertOutput(f.AlertSender, f.ExpressionAlerts, f.Tracer, *config.ExpressionAlertConfig)
 	default:
 		return nil, fmt.Errorf("unknown output type: %s", config.Type)
 	}
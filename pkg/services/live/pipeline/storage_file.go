@@ -240,6 +240,10 @@ func (f *FileStorage) readRules() (ChannelRules, error) {
 	// Safe to ignore gosec warning G304.
 	// nolint:gosec
 	ruleBytes, err := os.ReadFile(ruleFile)
+	if errors.Is(err, os.ErrNotExist) {
+		// No channel rule was saved yet.
+		return ChannelRules{}, nil
+	}
 	if err != nil {
 		return ChannelRules{}, fmt.Errorf("can't read pipeline rules: %s: %w", f.ruleFilePath(), err)
 	}
@@ -309,6 +313,10 @@ func (f *FileStorage) readWriteConfigs() (WriteConfigs, error) {
 	// Safe to ignore gosec warning G304.
 	// nolint:gosec
 	bytes, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		// No write config was saved yet.
+		return WriteConfigs{}, nil
+	}
 	if err != nil {
 		return WriteConfigs{}, fmt.Errorf("can't read %s file: %w", filePath, err)
 	}
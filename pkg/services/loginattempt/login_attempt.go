@@ -2,6 +2,14 @@ package loginattempt
 
 import (
 	"context"
+	"time"
+)
+
+// Kinds of lockouts, login attempts are throttled by username, by source IP address and by subnet of the source IP address
+const (
+	KindUsername  = "username"
+	KindIPAddress = "ip"
+	KindSubnet    = "subnet"
 )
 
 type Service interface {
@@ -10,6 +18,9 @@ type Service interface {
 	// Validate checks if username has to many login attempts inside a window.
 	// Will return true if provided username do not have too many attempts.
 	Validate(ctx context.Context, username string) (bool, error)
+	// ValidateIPAddress checks if the IP address or its subnet have too many login attempts inside a window.
+	// Will return true if neither of them have too many attempts.
+	ValidateIPAddress(ctx context.Context, IPAddress string) (bool, error)
 	// Reset resets all login attempts attached to username
 	Reset(ctx context.Context, username string) error
 }
@@ -18,5 +29,15 @@ type LoginAttempt struct {
 	Id        int64
 	Username  string
 	IpAddress string
+	IpSubnet  string
 	Created   int64
 }
+
+// Lockout is a username, IP address or subnet that can't log in until LockedUntil
+type Lockout struct {
+	Kind        string    `json:"kind"`
+	Key         string    `json:"key"`
+	Attempts    int64     `json:"attempts"`
+	LastAttempt time.Time `json:"lastAttempt"`
+	LockedUntil time.Time `json:"lockedUntil"`
+}
@@ -0,0 +1,43 @@
+package loginattemptimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	authorize := ac.Middleware(s.accessControl)
+
+	routeRegister.Get("/api/admin/login-lockouts", middleware.ReqSignedIn,
+		authorize(ac.EvalPermission(ac.ActionUsersRead, ac.ScopeGlobalUsersAll)), routing.Wrap(s.handleListLockouts))
+	routeRegister.Delete("/api/admin/login-lockouts", middleware.ReqSignedIn,
+		authorize(ac.EvalPermission(ac.ActionUsersWrite, ac.ScopeGlobalUsersAll)), routing.Wrap(s.handleClearLockout))
+}
+
+// handleListLockouts lists the usernames, IP addresses and subnets that can't log in at the moment
+func (s *Service) handleListLockouts(c *contextmodel.ReqContext) response.Response {
+	lockouts, err := s.Lockouts(c.Req.Context())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list login lockouts", err)
+	}
+	return response.JSON(http.StatusOK, lockouts)
+}
+
+// handleClearLockout lifts the lockout of the username, IP address or subnet given by the kind and key query
+// parameters
+func (s *Service) handleClearLockout(c *contextmodel.ReqContext) response.Response {
+	kind, key := c.Query("kind"), c.Query("key")
+	if key == "" {
+		return response.Error(http.StatusBadRequest, "Missing lockout key", nil)
+	}
+
+	if err := s.ClearLockout(c.Req.Context(), kind, key); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to clear login lockout", err)
+	}
+	return response.Success("Login lockout cleared")
+}
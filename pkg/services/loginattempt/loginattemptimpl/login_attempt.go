@@ -2,34 +2,67 @@ package loginattemptimpl
 
 import (
 	"context"
+	"errors"
+	"net"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/serverlock"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 const (
 	maxInvalidLoginAttempts int64 = 5
 	loginAttemptsWindow           = time.Minute * 5
+	lockedEmailTemplate           = "login_locked"
 )
 
-func ProvideService(db db.DB, cfg *setting.Cfg, lock *serverlock.ServerLockService) *Service {
-	return &Service{
-		&xormStore{db: db, now: time.Now},
-		cfg,
-		lock,
-		log.New("login_attempt"),
+var errInvalidKind = errutil.BadRequest("loginattempt.invalidKind")
+
+// lockoutKinds are the kinds of keys login attempts are throttled by, in the order they are listed
+var lockoutKinds = []string{loginattempt.KindUsername, loginattempt.KindIPAddress, loginattempt.KindSubnet}
+
+func ProvideService(
+	db db.DB,
+	cfg *setting.Cfg,
+	lock *serverlock.ServerLockService,
+	routeRegister routing.RouteRegister,
+	accessControl ac.AccessControl,
+	userService user.Service,
+	emailSender notifications.EmailSender,
+) *Service {
+	s := &Service{
+		store:         &xormStore{db: db, now: time.Now},
+		cfg:           cfg,
+		lock:          lock,
+		logger:        log.New("login_attempt"),
+		accessControl: accessControl,
+		userService:   userService,
+		emailSender:   emailSender,
 	}
+	s.registerAPIEndpoints(routeRegister)
+	return s
 }
 
 type Service struct {
-	store  store
-	cfg    *setting.Cfg
-	lock   *serverlock.ServerLockService
-	logger log.Logger
+	store         store
+	cfg           *setting.Cfg
+	lock          *serverlock.ServerLockService
+	logger        log.Logger
+	accessControl ac.AccessControl
+	userService   user.Service
+	emailSender   notifications.EmailSender
+	// notifying tracks the lockout emails being sent
+	notifying sync.WaitGroup
 }
 
 func (s *Service) Run(ctx context.Context) error {
@@ -54,11 +87,20 @@ func (s *Service) Add(ctx context.Context, username, IPAddress string) error {
 		return nil
 	}
 
+	username = strings.ToLower(username)
 	_, err := s.store.CreateLoginAttempt(ctx, CreateLoginAttemptCommand{
-		Username:  strings.ToLower(username),
+		Username:  username,
 		IpAddress: IPAddress,
+		IpSubnet:  ipSubnet(IPAddress),
 	})
-	return err
+	if err != nil {
+		return err
+	}
+
+	if s.cfg.BruteForceLoginProtectionNotifyUser {
+		s.notifyLockout(ctx, username, IPAddress)
+	}
+	return nil
 }
 
 func (s *Service) Reset(ctx context.Context, username string) error {
@@ -70,27 +112,191 @@ func (s *Service) Validate(ctx context.Context, username string) (bool, error) {
 		return true, nil
 	}
 
-	loginAttemptCountQuery := GetUserLoginAttemptCountQuery{
-		Username: strings.ToLower(username),
-		Since:    time.Now().Add(-loginAttemptsWindow),
+	return s.validate(ctx, loginattempt.KindUsername, strings.ToLower(username))
+}
+
+func (s *Service) ValidateIPAddress(ctx context.Context, IPAddress string) (bool, error) {
+	if s.cfg.DisableBruteForceLoginProtection || IPAddress == "" {
+		return true, nil
+	}
+
+	ok, err := s.validate(ctx, loginattempt.KindIPAddress, IPAddress)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	if subnet := ipSubnet(IPAddress); subnet != "" {
+		return s.validate(ctx, loginattempt.KindSubnet, subnet)
+	}
+	return true, nil
+}
+
+// Lockouts lists the usernames, IP addresses and subnets that can't log in at the moment
+func (s *Service) Lockouts(ctx context.Context) ([]*loginattempt.Lockout, error) {
+	now := time.Now()
+	lockouts := make([]*loginattempt.Lockout, 0)
+	for _, kind := range lockoutKinds {
+		maxAttempts := s.maxAttempts(kind)
+		if maxAttempts <= 0 {
+			continue
+		}
+
+		groups, err := s.store.GetLoginAttemptGroups(ctx, GetLoginAttemptGroupsQuery{
+			Kind:        kind,
+			Since:       now.Add(-s.window()),
+			MinAttempts: maxAttempts,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range groups {
+			lockedUntil := s.lockedUntil(kind, group)
+			if !lockedUntil.After(now) {
+				continue
+			}
+			lockouts = append(lockouts, &loginattempt.Lockout{
+				Kind:        kind,
+				Key:         group.Key,
+				Attempts:    group.Attempts,
+				LastAttempt: time.Unix(group.Latest, 0),
+				LockedUntil: lockedUntil,
+			})
+		}
+	}
+	return lockouts, nil
+}
+
+// ClearLockout removes the login attempts of a username, IP address or subnet, which lifts its lockout
+func (s *Service) ClearLockout(ctx context.Context, kind, key string) error {
+	if kind == loginattempt.KindUsername {
+		key = strings.ToLower(key)
 	}
+	return s.store.DeleteLoginAttemptsByKey(ctx, DeleteLoginAttemptsByKeyCommand{Kind: kind, Key: key})
+}
 
-	count, err := s.store.GetUserLoginAttemptCount(ctx, loginAttemptCountQuery)
+func (s *Service) validate(ctx context.Context, kind, key string) (bool, error) {
+	if s.maxAttempts(kind) <= 0 {
+		return true, nil
+	}
+
+	stats, err := s.store.GetLoginAttemptStats(ctx, GetLoginAttemptStatsQuery{
+		Kind:  kind,
+		Key:   key,
+		Since: time.Now().Add(-s.window()),
+	})
 	if err != nil {
 		return false, err
 	}
 
-	if count >= maxInvalidLoginAttempts {
-		return false, nil
+	return !s.lockedUntil(kind, stats).After(time.Now()), nil
+}
+
+// lockedUntil returns the end of the lockout of a key, which is zero when the key has less attempts than the limit.
+// The lockout starts at the latest attempt and doubles with every attempt past the limit.
+func (s *Service) lockedUntil(kind string, stats LoginAttemptStats) time.Time {
+	maxAttempts := s.maxAttempts(kind)
+	if maxAttempts <= 0 || stats.Attempts < maxAttempts {
+		return time.Time{}
 	}
 
-	return true, nil
+	duration, maxDuration := s.lockoutDuration(), s.maxLockoutDuration()
+	for i := maxAttempts; i < stats.Attempts && duration < maxDuration; i++ {
+		duration *= 2
+	}
+	return time.Unix(stats.Latest, 0).Add(min(duration, maxDuration))
+}
+
+func (s *Service) maxAttempts(kind string) int64 {
+	switch kind {
+	case loginattempt.KindUsername:
+		if s.cfg.BruteForceLoginProtectionMaxAttempts > 0 {
+			return s.cfg.BruteForceLoginProtectionMaxAttempts
+		}
+		return maxInvalidLoginAttempts
+	case loginattempt.KindIPAddress:
+		return s.cfg.BruteForceLoginProtectionIPMaxAttempts
+	case loginattempt.KindSubnet:
+		return s.cfg.BruteForceLoginProtectionSubnetMaxAttempts
+	}
+	return 0
+}
+
+func (s *Service) lockoutDuration() time.Duration {
+	if s.cfg.BruteForceLoginProtectionLockoutDuration > 0 {
+		return s.cfg.BruteForceLoginProtectionLockoutDuration
+	}
+	return loginAttemptsWindow
+}
+
+func (s *Service) maxLockoutDuration() time.Duration {
+	return max(s.cfg.BruteForceLoginProtectionMaxLockoutDuration, s.lockoutDuration())
+}
+
+// window is how long login attempts are counted for, attempts have to be kept for as long as the longest lockout
+func (s *Service) window() time.Duration {
+	return max(loginAttemptsWindow, s.maxLockoutDuration())
+}
+
+// notifyLockout emails the user when their login just got locked. Lockouts of unknown usernames are ignored. The
+// user is looked up and emailed in the background so that failed logins take the same time whether the user exists
+// or not, and a slow mail queue doesn't hold the login request.
+func (s *Service) notifyLockout(ctx context.Context, username, IPAddress string) {
+	stats, err := s.store.GetLoginAttemptStats(ctx, GetLoginAttemptStatsQuery{
+		Kind:  loginattempt.KindUsername,
+		Key:   username,
+		Since: time.Now().Add(-s.window()),
+	})
+	if err != nil {
+		s.logger.FromContext(ctx).Warn("Failed to count login attempts", "error", err)
+		return
+	}
+	// further attempts extend the lockout, only the first one is notified
+	if stats.Attempts != s.maxAttempts(loginattempt.KindUsername) {
+		return
+	}
+
+	lockedUntil := s.lockedUntil(loginattempt.KindUsername, stats)
+	ctx = context.WithoutCancel(ctx)
+	s.notifying.Add(1)
+	go func() {
+		defer s.notifying.Done()
+		s.sendLockoutEmail(ctx, username, IPAddress, lockedUntil)
+	}()
+}
+
+func (s *Service) sendLockoutEmail(ctx context.Context, username, IPAddress string, lockedUntil time.Time) {
+	logger := s.logger.FromContext(ctx)
+
+	usr, err := s.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: username})
+	if err != nil {
+		if !errors.Is(err, user.ErrUserNotFound) {
+			logger.Warn("Failed to get locked user", "error", err)
+		}
+		return
+	}
+	if usr.Email == "" {
+		return
+	}
+
+	err = s.emailSender.SendEmailCommandHandler(ctx, &notifications.SendEmailCommand{
+		To:       []string{usr.Email},
+		Template: lockedEmailTemplate,
+		Data: map[string]any{
+			"Name":        usr.NameOrFallback(),
+			"IPAddress":   IPAddress,
+			"LockedUntil": lockedUntil.UTC().Format(time.RFC1123),
+		},
+	})
+	if err != nil {
+		logger.Warn("Failed to send login lockout email", "userId", usr.ID, "error", err)
+	}
 }
 
 func (s *Service) cleanup(ctx context.Context) {
 	err := s.lock.LockAndExecute(ctx, "delete old login attempts", time.Minute*10, func(context.Context) {
 		cmd := DeleteOldLoginAttemptsCommand{
-			OlderThan: time.Now().Add(time.Minute * -10),
+			OlderThan: time.Now().Add(-max(time.Minute*10, s.window())),
 		}
 		if deletedLogs, err := s.store.DeleteOldLoginAttempts(ctx, cmd); err != nil {
 			s.logger.Error("Problem deleting expired login attempts", "error", err.Error())
@@ -103,3 +309,18 @@ func (s *Service) cleanup(ctx context.Context) {
 		s.logger.Error("Failed to lock and execute cleanup of old login attempts", "error", err)
 	}
 }
+
+// ipSubnet returns the /24 subnet of IPv4 addresses and the /64 subnet of IPv6 addresses, or an empty string when the
+// address can't be parsed
+func ipSubnet(address string) string {
+	ip := net.ParseIP(strings.Trim(address, "[]"))
+	if ip == nil {
+		return ""
+	}
+
+	mask := net.CIDRMask(64, 128)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, mask = ip4, net.CIDRMask(24, 32)
+	}
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
@@ -3,11 +3,18 @@ package loginattemptimpl
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
@@ -67,8 +74,9 @@ func TestService_Validate(t *testing.T) {
 			cfg.DisableBruteForceLoginProtection = tt.disabled
 			service := &Service{
 				store: fakeStore{
-					ExpectedCount: tt.loginAttempts,
-					ExpectedErr:   tt.expectedErr,
+					ExpectedCount:  tt.loginAttempts,
+					ExpectedLatest: time.Now().Unix(),
+					ExpectedErr:    tt.expectedErr,
 				},
 				cfg: cfg,
 			}
@@ -85,7 +93,7 @@ func TestLoginAttempts(t *testing.T) {
 	cfg := setting.NewCfg()
 	cfg.DisableBruteForceLoginProtection = false
 	db := db.InitTestDB(t)
-	service := ProvideService(db, cfg, nil)
+	service := ProvideService(db, cfg, nil, routing.NewRouteRegister(), actest.FakeAccessControl{}, usertest.NewUserServiceFake(), nil)
 
 	// add multiple login attempts with different uppercases, they all should be counted as the same user
 	_ = service.Add(ctx, "admin", "[::1]")
@@ -105,11 +113,116 @@ func TestLoginAttempts(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestService_lockedUntil(t *testing.T) {
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtectionMaxAttempts = 3
+	cfg.BruteForceLoginProtectionLockoutDuration = time.Minute
+	cfg.BruteForceLoginProtectionMaxLockoutDuration = 10 * time.Minute
+	service := &Service{cfg: cfg}
+
+	latest := time.Now().Truncate(time.Second)
+	for attempts, expected := range map[int64]time.Duration{
+		3: time.Minute,
+		4: 2 * time.Minute,
+		5: 4 * time.Minute,
+		6: 8 * time.Minute,
+		7: 10 * time.Minute,
+		8: 10 * time.Minute,
+	} {
+		lockedUntil := service.lockedUntil(loginattempt.KindUsername, LoginAttemptStats{Attempts: attempts, Latest: latest.Unix()})
+		assert.Equal(t, latest.Add(expected), lockedUntil, "attempts: %d", attempts)
+	}
+
+	assert.True(t, service.lockedUntil(loginattempt.KindUsername, LoginAttemptStats{Attempts: 2, Latest: latest.Unix()}).IsZero())
+	// IP address limits are disabled
+	assert.True(t, service.lockedUntil(loginattempt.KindIPAddress, LoginAttemptStats{Attempts: 100, Latest: latest.Unix()}).IsZero())
+}
+
+func TestIPSubnet(t *testing.T) {
+	assert.Equal(t, "192.168.1.0/24", ipSubnet("192.168.1.17"))
+	assert.Equal(t, "10.0.0.0/24", ipSubnet("::ffff:10.0.0.1"))
+	assert.Equal(t, "2001:db8:0:1::/64", ipSubnet("2001:db8:0:1:2:3:4:5"))
+	assert.Equal(t, "::/64", ipSubnet("[::1]"))
+	assert.Equal(t, "", ipSubnet("invalid"))
+	assert.Equal(t, "", ipSubnet(""))
+}
+
+func TestLoginAttemptsByIPAddress(t *testing.T) {
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtectionIPMaxAttempts = 3
+	cfg.BruteForceLoginProtectionSubnetMaxAttempts = 5
+	service := ProvideService(db.InitTestDB(t), cfg, nil, routing.NewRouteRegister(), actest.FakeAccessControl{}, usertest.NewUserServiceFake(), nil)
+
+	validate := func(address string) bool {
+		t.Helper()
+		ok, err := service.ValidateIPAddress(ctx, address)
+		require.NoError(t, err)
+		return ok
+	}
+
+	// attempts are counted for the IP address whatever the username
+	require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+	require.NoError(t, service.Add(ctx, "editor", "10.0.0.1"))
+	require.NoError(t, service.Add(ctx, "viewer", "10.0.0.1"))
+	assert.False(t, validate("10.0.0.1"))
+	assert.True(t, validate("10.0.0.2"))
+
+	require.NoError(t, service.Add(ctx, "admin", "10.0.0.3"))
+	require.NoError(t, service.Add(ctx, "admin", "10.0.0.4"))
+	assert.False(t, validate("10.0.0.2"))
+	assert.True(t, validate("10.0.1.1"))
+
+	lockouts, err := service.Lockouts(ctx)
+	require.NoError(t, err)
+	keys := make([]string, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		keys = append(keys, lockout.Kind+":"+lockout.Key)
+	}
+	assert.Equal(t, []string{"ip:10.0.0.1", "subnet:10.0.0.0/24"}, keys)
+
+	require.NoError(t, service.ClearLockout(ctx, loginattempt.KindSubnet, "10.0.0.0/24"))
+	assert.True(t, validate("10.0.0.2"))
+	assert.True(t, validate("10.0.0.1"))
+
+	err = service.ClearLockout(ctx, "email", "admin@example.com")
+	assert.ErrorIs(t, err, errInvalidKind)
+}
+
+func TestLoginAttemptsNotifyUser(t *testing.T) {
+	ctx := context.Background()
+	cfg := setting.NewCfg()
+	cfg.BruteForceLoginProtectionMaxAttempts = 2
+	cfg.BruteForceLoginProtectionNotifyUser = true
+	userService := usertest.NewUserServiceFake()
+	userService.ExpectedUser = &user.User{ID: 1, Login: "admin", Email: "admin@example.com", Name: "Admin"}
+	emailSender := notifications.MockNotificationService()
+	service := ProvideService(db.InitTestDB(t), cfg, nil, routing.NewRouteRegister(), actest.FakeAccessControl{}, userService, emailSender)
+
+	require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+	service.notifying.Wait()
+	assert.Empty(t, emailSender.Email.To)
+
+	require.NoError(t, service.Add(ctx, "Admin", "10.0.0.1"))
+	service.notifying.Wait()
+	assert.Equal(t, []string{"admin@example.com"}, emailSender.Email.To)
+	assert.Equal(t, lockedEmailTemplate, emailSender.Email.Template)
+	assert.Equal(t, "Admin", emailSender.Email.Data["Name"])
+	assert.Equal(t, "10.0.0.1", emailSender.Email.Data["IPAddress"])
+
+	// further attempts extend the lockout without notifying the user again
+	emailSender.Email = notifications.SendEmailCommand{}
+	require.NoError(t, service.Add(ctx, "admin", "10.0.0.1"))
+	service.notifying.Wait()
+	assert.Empty(t, emailSender.Email.To)
+}
+
 var _ store = new(fakeStore)
 
 type fakeStore struct {
 	ExpectedErr         error
 	ExpectedCount       int64
+	ExpectedLatest      int64
 	ExpectedDeletedRows int64
 }
 
@@ -128,3 +241,15 @@ func (f fakeStore) DeleteOldLoginAttempts(ctx context.Context, command DeleteOld
 func (f fakeStore) DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error {
 	return f.ExpectedErr
 }
+
+func (f fakeStore) GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error) {
+	return LoginAttemptStats{Key: query.Key, Attempts: f.ExpectedCount, Latest: f.ExpectedLatest}, f.ExpectedErr
+}
+
+func (f fakeStore) GetLoginAttemptGroups(ctx context.Context, query GetLoginAttemptGroupsQuery) ([]LoginAttemptStats, error) {
+	return nil, f.ExpectedErr
+}
+
+func (f fakeStore) DeleteLoginAttemptsByKey(ctx context.Context, cmd DeleteLoginAttemptsByKeyCommand) error {
+	return f.ExpectedErr
+}
@@ -7,6 +7,7 @@ import (
 type CreateLoginAttemptCommand struct {
 	Username  string
 	IpAddress string
+	IpSubnet  string
 }
 
 type GetUserLoginAttemptCountQuery struct {
@@ -14,6 +15,28 @@ type GetUserLoginAttemptCountQuery struct {
 	Since    time.Time
 }
 
+// GetLoginAttemptStatsQuery counts the login attempts of a username, IP address or subnet, depending on Kind
+type GetLoginAttemptStatsQuery struct {
+	Kind  string
+	Key   string
+	Since time.Time
+}
+
+// GetLoginAttemptGroupsQuery lists the usernames, IP addresses or subnets, depending on Kind, with at least
+// MinAttempts login attempts
+type GetLoginAttemptGroupsQuery struct {
+	Kind        string
+	Since       time.Time
+	MinAttempts int64
+}
+
+type LoginAttemptStats struct {
+	Key      string `xorm:"attempt_key"`
+	Attempts int64  `xorm:"attempts"`
+	// Latest is the creation time of the latest attempt, in unix seconds
+	Latest int64 `xorm:"latest"`
+}
+
 type DeleteOldLoginAttemptsCommand struct {
 	OlderThan time.Time
 }
@@ -21,3 +44,8 @@ type DeleteOldLoginAttemptsCommand struct {
 type DeleteLoginAttemptsCommand struct {
 	Username string
 }
+
+type DeleteLoginAttemptsByKeyCommand struct {
+	Kind string
+	Key  string
+}
@@ -18,6 +18,24 @@ type store interface {
 	DeleteOldLoginAttempts(ctx context.Context, cmd DeleteOldLoginAttemptsCommand) (int64, error)
 	DeleteLoginAttempts(ctx context.Context, cmd DeleteLoginAttemptsCommand) error
 	GetUserLoginAttemptCount(ctx context.Context, query GetUserLoginAttemptCountQuery) (int64, error)
+	GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error)
+	GetLoginAttemptGroups(ctx context.Context, query GetLoginAttemptGroupsQuery) ([]LoginAttemptStats, error)
+	DeleteLoginAttemptsByKey(ctx context.Context, cmd DeleteLoginAttemptsByKeyCommand) error
+}
+
+// kindColumns are the login_attempt columns login attempts are throttled by
+var kindColumns = map[string]string{
+	loginattempt.KindUsername:  "username",
+	loginattempt.KindIPAddress: "ip_address",
+	loginattempt.KindSubnet:    "ip_subnet",
+}
+
+func kindColumn(kind string) (string, error) {
+	column, ok := kindColumns[kind]
+	if !ok {
+		return "", errInvalidKind.Errorf("invalid lockout kind %q", kind)
+	}
+	return column, nil
 }
 
 func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAttemptCommand) (result loginattempt.LoginAttempt, err error) {
@@ -25,6 +43,7 @@ func (xs *xormStore) CreateLoginAttempt(ctx context.Context, cmd CreateLoginAtte
 		loginAttempt := loginattempt.LoginAttempt{
 			Username:  cmd.Username,
 			IpAddress: cmd.IpAddress,
+			IpSubnet:  cmd.IpSubnet,
 			Created:   xs.now().Unix(),
 		}
 
@@ -82,3 +101,47 @@ func (xs *xormStore) GetUserLoginAttemptCount(ctx context.Context, query GetUser
 
 	return total, err
 }
+
+func (xs *xormStore) GetLoginAttemptStats(ctx context.Context, query GetLoginAttemptStatsQuery) (LoginAttemptStats, error) {
+	stats := LoginAttemptStats{Key: query.Key}
+	column, err := kindColumn(query.Kind)
+	if err != nil {
+		return stats, err
+	}
+
+	err = xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "SELECT COUNT(*) AS attempts, COALESCE(MAX(created), 0) AS latest FROM login_attempt WHERE " +
+			column + " = ? AND created >= ?"
+		_, err := sess.SQL(rawSQL, query.Key, query.Since.Unix()).Get(&stats)
+		return err
+	})
+	return stats, err
+}
+
+func (xs *xormStore) GetLoginAttemptGroups(ctx context.Context, query GetLoginAttemptGroupsQuery) ([]LoginAttemptStats, error) {
+	column, err := kindColumn(query.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]LoginAttemptStats, 0)
+	err = xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "SELECT " + column + " AS attempt_key, COUNT(*) AS attempts, MAX(created) AS latest FROM login_attempt" +
+			" WHERE created >= ? AND " + column + " IS NOT NULL AND " + column + " <> ''" +
+			" GROUP BY " + column + " HAVING COUNT(*) >= ? ORDER BY latest DESC"
+		return sess.SQL(rawSQL, query.Since.Unix(), query.MinAttempts).Find(&groups)
+	})
+	return groups, err
+}
+
+func (xs *xormStore) DeleteLoginAttemptsByKey(ctx context.Context, cmd DeleteLoginAttemptsByKeyCommand) error {
+	column, err := kindColumn(cmd.Kind)
+	if err != nil {
+		return err
+	}
+
+	return xs.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM login_attempt WHERE "+column+" = ?", cmd.Key)
+		return err
+	})
+}
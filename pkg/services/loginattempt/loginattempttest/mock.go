@@ -9,9 +9,12 @@ import (
 var _ loginattempt.Service = new(MockLoginAttemptService)
 
 type MockLoginAttemptService struct {
-	AddCalled      bool
-	ResetCalled    bool
-	ValidateCalled bool
+	AddCalled               bool
+	ResetCalled             bool
+	ValidateCalled          bool
+	ValidateIPAddressCalled bool
+	// ValidatedIPAddress is the address of the last ValidateIPAddress call
+	ValidatedIPAddress string
 
 	ExpectedValid bool
 	ExpectedErr   error
@@ -31,3 +34,9 @@ func (f *MockLoginAttemptService) Validate(ctx context.Context, username string)
 	f.ValidateCalled = true
 	return f.ExpectedValid, f.ExpectedErr
 }
+
+func (f *MockLoginAttemptService) ValidateIPAddress(ctx context.Context, IPAddress string) (bool, error) {
+	f.ValidateIPAddressCalled = true
+	f.ValidatedIPAddress = IPAddress
+	return f.ExpectedValid, f.ExpectedErr
+}
@@ -0,0 +1,68 @@
+package mfa
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrNotEnrolled      = errutil.NotFound("mfa.notEnrolled", errutil.WithPublicMessage("Two-factor authentication is not set up"))
+	ErrAlreadyEnrolled  = errutil.Conflict("mfa.alreadyEnrolled", errutil.WithPublicMessage("Two-factor authentication is already set up"))
+	ErrInvalidCode      = errutil.Unauthorized("mfa.invalidCode", errutil.WithPublicMessage("Invalid authentication code"))
+	ErrInvalidChallenge = errutil.Unauthorized("mfa.invalidChallenge", errutil.WithPublicMessage("The login has expired, sign in again"))
+	ErrTooManyAttempts  = errutil.Unauthorized("mfa.tooManyAttempts", errutil.WithPublicMessage("Too many consecutive incorrect login attempts, login temporarily blocked"))
+	ErrInternal         = errutil.Internal("mfa.internal")
+)
+
+// Service handles the TOTP second factor of users signing in with a Grafana password.
+type Service interface {
+	// Challenge starts the second step of a password login. It returns nil when the user
+	// has not set up a second factor and none of their organizations requires one.
+	Challenge(ctx context.Context, query *ChallengeQuery) (*Challenge, error)
+	// VerifyChallenge checks a TOTP or recovery code against a challenge and returns the
+	// ID of the user who passed it. A challenge can only be passed once.
+	VerifyChallenge(ctx context.Context, cmd *VerifyChallengeCommand) (int64, error)
+	// IsRequired tells whether a user needs a second factor to sign in.
+	IsRequired(ctx context.Context, userID int64) (bool, error)
+	// Reset removes the second factor of a user, for instance when they lost their device
+	// and their recovery codes.
+	Reset(ctx context.Context, userID int64) error
+}
+
+type ChallengeQuery struct {
+	UserID int64
+	Login  string
+}
+
+// Challenge is handed to the client after the password step of a login
+type Challenge struct {
+	Token string `json:"challenge"`
+	// EnrollmentRequired is set when an organization of the user requires a second
+	// factor that the user has not set up yet. The client must enroll the user with the
+	// challenge before verifying it.
+	EnrollmentRequired bool `json:"enrollmentRequired"`
+}
+
+type VerifyChallengeCommand struct {
+	Token string
+	// Code is either a TOTP code or a recovery code
+	Code string
+	// IPAddress of the client, recorded with failed attempts
+	IPAddress string
+}
+
+// Enrollment holds what the user needs to configure their authenticator app. It is only
+// returned once.
+type Enrollment struct {
+	Secret        string   `json:"secret"`
+	URL           string   `json:"url"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type Status struct {
+	Enabled                bool `json:"enabled"`
+	Pending                bool `json:"pending"`
+	Required               bool `json:"required"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
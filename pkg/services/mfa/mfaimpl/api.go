@@ -0,0 +1,301 @@
+package mfaimpl
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/user/mfa", func(user routing.RouteRegister) {
+		user.Get("/", routing.Wrap(s.getStatusHandler))
+		user.Post("/enroll", routing.Wrap(s.enrollHandler))
+		user.Post("/activate", routing.Wrap(s.activateHandler))
+		user.Post("/disable", routing.Wrap(s.disableHandler))
+		user.Post("/recovery-codes", routing.Wrap(s.regenerateRecoveryCodesHandler))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	s.routeRegister.Group("/api/org/mfa", func(org routing.RouteRegister) {
+		org.Get("/", authorize(ac.EvalPermission(ac.ActionOrgsRead)), routing.Wrap(s.getOrgSettingsHandler))
+		org.Put("/", authorize(ac.EvalPermission(ac.ActionOrgsWrite)), routing.Wrap(s.updateOrgSettingsHandler))
+	}, middleware.ReqSignedIn)
+
+	// the user is not signed in yet, the challenge of the login proves they sent the right password
+	s.routeRegister.Post("/api/login/mfa/enroll", routing.Wrap(s.enrollWithChallengeHandler))
+}
+
+// swagger:route GET /user/mfa signed_in_user getUserMFAStatus
+//
+// Get the two-factor authentication status of the signed in user.
+//
+// Responses:
+// 200: getUserMFAStatusResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getStatusHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	status, err := s.GetStatus(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get two-factor authentication status", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+// swagger:route POST /user/mfa/enroll signed_in_user enrollUserMFA
+//
+// Start setting up two-factor authentication for the signed in user.
+//
+// Returns the TOTP secret, an otpauth URL for authenticator apps and recovery codes. They are only returned once.
+// Two-factor authentication is enabled once a first code is sent to `/user/mfa/activate`.
+//
+// Responses:
+// 200: enrollMFAResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) enrollHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	enrollment, err := s.Enroll(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set up two-factor authentication", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+// swagger:route POST /user/mfa/activate signed_in_user activateUserMFA
+//
+// Enable two-factor authentication for the signed in user with a first code from their authenticator app.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) activateHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	form := codeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.Activate(c.Req.Context(), userID, form.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to enable two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication enabled")
+}
+
+// swagger:route POST /user/mfa/disable signed_in_user disableUserMFA
+//
+// Disable two-factor authentication for the signed in user.
+//
+// Requires a code from the authenticator app or a recovery code.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) disableHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	form := codeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.Disable(c.Req.Context(), userID, form.Code); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to disable two-factor authentication", err)
+	}
+	return response.Success("Two-factor authentication disabled")
+}
+
+// swagger:route POST /user/mfa/recovery-codes signed_in_user regenerateUserMFARecoveryCodes
+//
+// Replace the recovery codes of the signed in user.
+//
+// Requires a code from the authenticator app or a recovery code. The previous recovery codes stop working.
+//
+// Responses:
+// 200: regenerateMFARecoveryCodesResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *Service) regenerateRecoveryCodesHandler(c *contextmodel.ReqContext) response.Response {
+	userID, errResp := signedInUserID(c)
+	if errResp != nil {
+		return errResp
+	}
+	form := codeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	codes, err := s.RegenerateRecoveryCodes(c.Req.Context(), userID, form.Code)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to replace recovery codes", err)
+	}
+	return response.JSON(http.StatusOK, map[string][]string{"recoveryCodes": codes})
+}
+
+// swagger:route GET /org/mfa org getOrgMFASettings
+//
+// Get the two-factor authentication policy of the current organization.
+//
+// Responses:
+// 200: getOrgMFASettingsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) getOrgSettingsHandler(c *contextmodel.ReqContext) response.Response {
+	settings, err := s.GetOrgSettings(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get two-factor authentication policy", err)
+	}
+	return response.JSON(http.StatusOK, settings)
+}
+
+// swagger:route PUT /org/mfa org updateOrgMFASettings
+//
+// Update the two-factor authentication policy of the current organization.
+//
+// When required, members who sign in with a Grafana password must set up two-factor authentication during their
+// next login.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *Service) updateOrgSettingsHandler(c *contextmodel.ReqContext) response.Response {
+	settings := OrgSettings{}
+	if err := web.Bind(c.Req, &settings); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := s.UpdateOrgSettings(c.Req.Context(), c.SignedInUser.GetOrgID(), &settings); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to update two-factor authentication policy", err)
+	}
+	return response.Success("Two-factor authentication policy updated")
+}
+
+// swagger:route POST /login/mfa/enroll login enrollMFAWithChallenge
+//
+// Set up two-factor authentication during a login.
+//
+// Used when the password step of a login returned a challenge with `enrollmentRequired`. The login completes by
+// sending the challenge and a first code to `/login/mfa`.
+//
+// Responses:
+// 200: enrollMFAResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 409: conflictError
+// 500: internalServerError
+func (s *Service) enrollWithChallengeHandler(c *contextmodel.ReqContext) response.Response {
+	form := challengeForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	enrollment, err := s.EnrollWithChallenge(c.Req.Context(), form.Challenge)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to set up two-factor authentication", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+func signedInUserID(c *contextmodel.ReqContext) (int64, response.Response) {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return 0, response.Error(http.StatusForbidden, "Two-factor authentication is only available to users", nil)
+	}
+	userID, err := identity.IntIdentifier(namespace, identifier)
+	if err != nil {
+		return 0, response.Error(http.StatusInternalServerError, "Failed to parse user id", err)
+	}
+	return userID, nil
+}
+
+// swagger:parameters activateUserMFA disableUserMFA regenerateUserMFARecoveryCodes
+type MFACodeParams struct {
+	// in:body
+	// required:true
+	Body struct {
+		// Code from the authenticator app, or a recovery code
+		Code string `json:"code"`
+	}
+}
+
+// swagger:parameters updateOrgMFASettings
+type UpdateOrgMFASettingsParams struct {
+	// in:body
+	// required:true
+	Body OrgSettings
+}
+
+// swagger:parameters enrollMFAWithChallenge
+type EnrollMFAWithChallengeParams struct {
+	// in:body
+	// required:true
+	Body struct {
+		Challenge string `json:"challenge"`
+	}
+}
+
+// swagger:response getUserMFAStatusResponse
+type GetUserMFAStatusResponse struct {
+	// in: body
+	Body mfa.Status `json:"body"`
+}
+
+// swagger:response enrollMFAResponse
+type EnrollMFAResponse struct {
+	// in: body
+	Body mfa.Enrollment `json:"body"`
+}
+
+// swagger:response regenerateMFARecoveryCodesResponse
+type RegenerateMFARecoveryCodesResponse struct {
+	// in: body
+	Body struct {
+		RecoveryCodes []string `json:"recoveryCodes"`
+	} `json:"body"`
+}
+
+// swagger:response getOrgMFASettingsResponse
+type GetOrgMFASettingsResponse struct {
+	// in: body
+	Body OrgSettings `json:"body"`
+}
@@ -0,0 +1,442 @@
+package mfaimpl
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	challengeTTL         = 5 * time.Minute
+	challengeKeyPrefix   = "mfa-challenge-"
+	maxChallengeAttempts = 5
+
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+)
+
+var _ mfa.Service = (*Service)(nil)
+
+type Service struct {
+	store         *sqlStore
+	secrets       secrets.Service
+	cache         remotecache.CacheStorage
+	loginAttempts loginattempt.Service
+	routeRegister routing.RouteRegister
+	accessControl ac.AccessControl
+	log           log.Logger
+	now           func() time.Time
+
+	enabled bool
+	issuer  string
+}
+
+func ProvideService(
+	cfg *setting.Cfg, sql db.DB, secretsService secrets.Service, cache remotecache.CacheStorage,
+	loginAttempts loginattempt.Service, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+) *Service {
+	section := cfg.SectionWithEnvOverrides("auth.mfa")
+	s := &Service{
+		store:         &sqlStore{db: sql},
+		secrets:       secretsService,
+		cache:         cache,
+		loginAttempts: loginAttempts,
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		log:           log.New("mfa"),
+		now:           time.Now,
+		enabled:       section.Key("enabled").MustBool(true),
+		issuer:        section.Key("issuer").MustString("Grafana"),
+	}
+
+	if s.enabled {
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+func (s *Service) Challenge(ctx context.Context, query *mfa.ChallengeQuery) (*mfa.Challenge, error) {
+	if !s.enabled {
+		return nil, nil
+	}
+
+	enrolled, err := s.isEnrolled(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !enrolled {
+		required, err := s.store.isRequiredByOrg(ctx, query.UserID)
+		if err != nil || !required {
+			return nil, err
+		}
+	}
+
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, mfa.ErrInternal.Errorf("failed to generate challenge: %w", err)
+	}
+	state := &challengeState{UserID: query.UserID, Login: query.Login, Enroll: !enrolled}
+	if err := s.saveChallenge(ctx, token, state); err != nil {
+		return nil, err
+	}
+	return &mfa.Challenge{Token: token, EnrollmentRequired: !enrolled}, nil
+}
+
+func (s *Service) VerifyChallenge(ctx context.Context, cmd *mfa.VerifyChallengeCommand) (int64, error) {
+	state, err := s.getChallenge(ctx, cmd.Token)
+	if err != nil {
+		return 0, err
+	}
+
+	ok, err := s.loginAttempts.Validate(ctx, state.Login)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, mfa.ErrTooManyAttempts.Errorf("too many consecutive incorrect login attempts for user %d", state.UserID)
+	}
+	ok, err = s.loginAttempts.ValidateIPAddress(ctx, cmd.IPAddress)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, mfa.ErrTooManyAttempts.Errorf("too many incorrect login attempts from %s", cmd.IPAddress)
+	}
+
+	row, err := s.store.get(ctx, state.UserID)
+	if err != nil {
+		return 0, err
+	}
+	if !row.Enabled && !state.Enroll {
+		return 0, mfa.ErrInvalidChallenge.Errorf("second factor of user %d was removed during the login", state.UserID)
+	}
+
+	if err := s.verifyCode(ctx, row, cmd.Code); err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) {
+			s.failChallenge(ctx, cmd, state)
+		}
+		return 0, err
+	}
+
+	if !row.Enabled {
+		if err := s.store.enable(ctx, row.ID); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := s.cache.Delete(ctx, challengeKey(cmd.Token)); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to delete second factor challenge", "userId", state.UserID, "error", err)
+	}
+	return state.UserID, nil
+}
+
+// failChallenge records a wrong code, both for the challenge and for the brute force login protection
+func (s *Service) failChallenge(ctx context.Context, cmd *mfa.VerifyChallengeCommand, state *challengeState) {
+	logger := s.log.FromContext(ctx)
+	if err := s.loginAttempts.Add(ctx, state.Login, cmd.IPAddress); err != nil {
+		logger.Warn("Failed to record login attempt", "userId", state.UserID, "error", err)
+	}
+
+	state.Attempts++
+	var err error
+	if state.Attempts >= maxChallengeAttempts {
+		err = s.cache.Delete(ctx, challengeKey(cmd.Token))
+	} else {
+		err = s.saveChallenge(ctx, cmd.Token, state)
+	}
+	if err != nil {
+		logger.Warn("Failed to update second factor challenge", "userId", state.UserID, "error", err)
+	}
+}
+
+func (s *Service) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	if !s.enabled {
+		return false, nil
+	}
+	enrolled, err := s.isEnrolled(ctx, userID)
+	if err != nil || enrolled {
+		return enrolled, err
+	}
+	return s.store.isRequiredByOrg(ctx, userID)
+}
+
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	return s.store.delete(ctx, userID)
+}
+
+// GetStatus returns the second factor status of a user
+func (s *Service) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	status := &mfa.Status{}
+	row, err := s.store.get(ctx, userID)
+	switch {
+	case err == nil:
+		status.Enabled = row.Enabled
+		status.Pending = !row.Enabled
+		status.RecoveryCodesRemaining = len(row.RecoveryCodes)
+	case !errors.Is(err, mfa.ErrNotEnrolled):
+		return nil, err
+	}
+
+	status.Required, err = s.store.isRequiredByOrg(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// Enroll generates a new secret and recovery codes for a user. The second factor is pending until the user sends a
+// first code, see Activate.
+func (s *Service) Enroll(ctx context.Context, userID int64, login string) (*mfa.Enrollment, error) {
+	enrolled, err := s.isEnrolled(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if enrolled {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user %d already has a second factor", userID)
+	}
+
+	secret, err := mfa.GenerateSecret()
+	if err != nil {
+		return nil, mfa.ErrInternal.Errorf("failed to generate secret: %w", err)
+	}
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return nil, mfa.ErrInternal.Errorf("failed to encrypt secret: %w", err)
+	}
+	codes, hashes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, mfa.ErrInternal.Errorf("failed to generate recovery codes: %w", err)
+	}
+
+	now := s.now()
+	err = s.store.replace(ctx, &userMFA{
+		UserID:        userID,
+		Secret:        base64.StdEncoding.EncodeToString(encrypted),
+		RecoveryCodes: hashes,
+		Created:       now,
+		Updated:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &mfa.Enrollment{
+		Secret:        secret,
+		URL:           mfa.KeyURL(s.issuer, login, secret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// EnrollWithChallenge enrolls the user of a login challenge when their organization requires a second factor
+func (s *Service) EnrollWithChallenge(ctx context.Context, token string) (*mfa.Enrollment, error) {
+	state, err := s.getChallenge(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !state.Enroll {
+		return nil, mfa.ErrAlreadyEnrolled.Errorf("user %d already has a second factor", state.UserID)
+	}
+	return s.Enroll(ctx, state.UserID, state.Login)
+}
+
+// Activate enables a pending second factor once the user sent a valid code
+func (s *Service) Activate(ctx context.Context, userID int64, code string) error {
+	row, err := s.store.get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if row.Enabled {
+		return mfa.ErrAlreadyEnrolled.Errorf("user %d already has a second factor", userID)
+	}
+	if err := s.verifyCode(ctx, row, code); err != nil {
+		return err
+	}
+	return s.store.enable(ctx, row.ID)
+}
+
+// Disable removes the second factor of a user who proves they still own it
+func (s *Service) Disable(ctx context.Context, userID int64, code string) error {
+	row, err := s.enabledRow(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.verifyCode(ctx, row, code); err != nil {
+		return err
+	}
+	return s.store.delete(ctx, userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	row, err := s.enabledRow(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCode(ctx, row, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes(userID)
+	if err != nil {
+		return nil, mfa.ErrInternal.Errorf("failed to generate recovery codes: %w", err)
+	}
+	if err := s.store.setRecoveryCodes(ctx, row.ID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *Service) GetOrgSettings(ctx context.Context, orgID int64) (*OrgSettings, error) {
+	setting, err := s.store.getOrgSetting(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &OrgSettings{Required: setting.Required}, nil
+}
+
+func (s *Service) UpdateOrgSettings(ctx context.Context, orgID int64, settings *OrgSettings) error {
+	return s.store.saveOrgSetting(ctx, &orgSetting{OrgID: orgID, Required: settings.Required})
+}
+
+func (s *Service) isEnrolled(ctx context.Context, userID int64) (bool, error) {
+	row, err := s.store.get(ctx, userID)
+	if errors.Is(err, mfa.ErrNotEnrolled) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return row.Enabled, nil
+}
+
+func (s *Service) enabledRow(ctx context.Context, userID int64) (*userMFA, error) {
+	row, err := s.store.get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !row.Enabled {
+		return nil, mfa.ErrNotEnrolled.Errorf("second factor of user %d is not enabled", userID)
+	}
+	return row, nil
+}
+
+// verifyCode checks a TOTP code or, once the second factor is enabled, a recovery code. Recovery codes can only be
+// used once.
+func (s *Service) verifyCode(ctx context.Context, row *userMFA, code string) error {
+	code = normalizeCode(code)
+	if len(code) == 6 {
+		encrypted, err := base64.StdEncoding.DecodeString(row.Secret)
+		if err != nil {
+			return mfa.ErrInternal.Errorf("failed to decode secret: %w", err)
+		}
+		secret, err := s.secrets.Decrypt(ctx, encrypted)
+		if err != nil {
+			return mfa.ErrInternal.Errorf("failed to decrypt secret: %w", err)
+		}
+
+		step, ok := mfa.ValidateCode(string(secret), code, s.now())
+		if !ok {
+			return mfa.ErrInvalidCode.Errorf("invalid code for user %d", row.UserID)
+		}
+		used, err := s.store.useStep(ctx, row.ID, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return mfa.ErrInvalidCode.Errorf("code was already used by user %d", row.UserID)
+		}
+		return nil
+	}
+
+	if !row.Enabled {
+		return mfa.ErrInvalidCode.Errorf("recovery codes cannot be used before the second factor is enabled")
+	}
+	hash := hashRecoveryCode(row.UserID, code)
+	for i, h := range row.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			remaining := append(append([]string{}, row.RecoveryCodes[:i]...), row.RecoveryCodes[i+1:]...)
+			return s.store.setRecoveryCodes(ctx, row.ID, remaining)
+		}
+	}
+	return mfa.ErrInvalidCode.Errorf("invalid recovery code for user %d", row.UserID)
+}
+
+func (s *Service) saveChallenge(ctx context.Context, token string, state *challengeState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return mfa.ErrInternal.Errorf("failed to encode challenge: %w", err)
+	}
+	if err := s.cache.Set(ctx, challengeKey(token), data, challengeTTL); err != nil {
+		return mfa.ErrInternal.Errorf("failed to store challenge: %w", err)
+	}
+	return nil
+}
+
+func (s *Service) getChallenge(ctx context.Context, token string) (*challengeState, error) {
+	if token == "" {
+		return nil, mfa.ErrInvalidChallenge.Errorf("empty challenge")
+	}
+	data, err := s.cache.Get(ctx, challengeKey(token))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, mfa.ErrInvalidChallenge.Errorf("challenge not found or expired")
+		}
+		return nil, mfa.ErrInternal.Errorf("failed to get challenge: %w", err)
+	}
+
+	state := &challengeState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, mfa.ErrInternal.Errorf("failed to decode challenge: %w", err)
+	}
+	return state, nil
+}
+
+// challengeKey hashes the challenge so that the cache does not hold usable tokens
+func challengeKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return challengeKeyPrefix + hex.EncodeToString(sum[:])
+}
+
+// newRecoveryCodes returns recovery codes formatted for the user, and their hashes to store
+func newRecoveryCodes(userID int64) ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(10, []byte(recoveryCodeAlphabet)...)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashes = append(hashes, hashRecoveryCode(userID, code))
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode does not need a slow hash: unlike passwords, recovery codes are random and long enough not to be
+// guessed from their hash
+func hashRecoveryCode(userID int64, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s", userID, normalizeCode(code))))
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeCode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(code))
+}
@@ -0,0 +1,191 @@
+package mfaimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/loginattempt/loginattempttest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) nextPeriod() {
+	c.now = c.now.Add(30 * time.Second)
+}
+
+func setupTestService(t *testing.T) (*Service, db.DB, *testClock) {
+	t.Helper()
+
+	testDB := db.InitTestDB(t)
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	return &Service{
+		store:         &sqlStore{db: testDB},
+		secrets:       fakes.NewFakeSecretsService(),
+		cache:         remotecache.NewFakeCacheStorage(),
+		loginAttempts: loginattempttest.FakeLoginAttemptService{ExpectedValid: true},
+		log:           log.NewNopLogger(),
+		now:           clock.Now,
+		enabled:       true,
+		issuer:        "Grafana",
+	}, testDB, clock
+}
+
+func code(t *testing.T, secret string, clock *testClock) string {
+	t.Helper()
+	c, err := mfa.GenerateCode(secret, clock.now)
+	require.NoError(t, err)
+	return c
+}
+
+func TestIntegrationMFA(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, _, clock := setupTestService(t)
+
+	challenge, err := s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 1, Login: "user1"})
+	require.NoError(t, err)
+	require.Nil(t, challenge, "users without a second factor should not be challenged")
+
+	enrollment, err := s.Enroll(ctx, 1, "user1")
+	require.NoError(t, err)
+	assert.Len(t, enrollment.RecoveryCodes, recoveryCodeCount)
+	assert.Contains(t, enrollment.URL, "otpauth://totp/Grafana:user1")
+
+	challenge, err = s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 1, Login: "user1"})
+	require.NoError(t, err)
+	require.Nil(t, challenge, "pending second factors should not be challenged")
+
+	err = s.Activate(ctx, 1, "000000")
+	require.ErrorIs(t, err, mfa.ErrInvalidCode)
+	require.NoError(t, s.Activate(ctx, 1, code(t, enrollment.Secret, clock)))
+
+	t.Run("should verify a challenge once", func(t *testing.T) {
+		challenge, err := s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 1, Login: "user1"})
+		require.NoError(t, err)
+		require.NotNil(t, challenge)
+		assert.False(t, challenge.EnrollmentRequired)
+
+		// the code used to activate the second factor cannot be used again
+		_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: code(t, enrollment.Secret, clock)})
+		require.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+		clock.nextPeriod()
+		userID, err := s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: code(t, enrollment.Secret, clock)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), userID)
+
+		clock.nextPeriod()
+		_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: code(t, enrollment.Secret, clock)})
+		require.ErrorIs(t, err, mfa.ErrInvalidChallenge)
+	})
+
+	t.Run("should accept a recovery code once", func(t *testing.T) {
+		challenge, err := s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 1, Login: "user1"})
+		require.NoError(t, err)
+
+		userID, err := s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: enrollment.RecoveryCodes[0]})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), userID)
+
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+
+		challenge, err = s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 1, Login: "user1"})
+		require.NoError(t, err)
+		_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: enrollment.RecoveryCodes[0]})
+		require.ErrorIs(t, err, mfa.ErrInvalidCode)
+	})
+
+	t.Run("should drop a challenge after too many wrong codes", func(t *testing.T) {
+		challenge, err := s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 1, Login: "user1"})
+		require.NoError(t, err)
+
+		for i := 0; i < maxChallengeAttempts; i++ {
+			_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: "000000"})
+			require.ErrorIs(t, err, mfa.ErrInvalidCode)
+		}
+		clock.nextPeriod()
+		_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: code(t, enrollment.Secret, clock)})
+		require.ErrorIs(t, err, mfa.ErrInvalidChallenge)
+	})
+
+	t.Run("should be required until reset", func(t *testing.T) {
+		required, err := s.IsRequired(ctx, 1)
+		require.NoError(t, err)
+		assert.True(t, required)
+
+		require.NoError(t, s.Reset(ctx, 1))
+
+		required, err = s.IsRequired(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, required)
+	})
+}
+
+func TestIntegrationMFAOrgRequirement(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, testDB, clock := setupTestService(t)
+
+	err := testDB.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(&org.OrgUser{OrgID: 1, UserID: 2, Role: org.RoleViewer, Created: clock.now, Updated: clock.now})
+		return err
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.UpdateOrgSettings(ctx, 1, &OrgSettings{Required: true}))
+
+	settings, err := s.GetOrgSettings(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, settings.Required)
+
+	required, err := s.IsRequired(ctx, 2)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	challenge, err := s.Challenge(ctx, &mfa.ChallengeQuery{UserID: 2, Login: "user2"})
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.EnrollmentRequired)
+
+	enrollment, err := s.EnrollWithChallenge(ctx, challenge.Token)
+	require.NoError(t, err)
+
+	userID, err := s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{Token: challenge.Token, Code: code(t, enrollment.Secret, clock)})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), userID)
+
+	status, err := s.GetStatus(ctx, 2)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Required)
+
+	_, err = s.EnrollWithChallenge(ctx, challenge.Token)
+	require.ErrorIs(t, err, mfa.ErrInvalidChallenge)
+}
@@ -0,0 +1,57 @@
+package mfaimpl
+
+import (
+	"time"
+)
+
+// userMFA is the second factor of a user. The TOTP secret is encrypted by the secrets service and base64 encoded,
+// the recovery codes are hashed.
+type userMFA struct {
+	ID            int64 `xorm:"pk autoincr 'id'"`
+	UserID        int64 `xorm:"user_id"`
+	Secret        string
+	RecoveryCodes []string `xorm:"json"`
+	// Enabled is false until the user proves their authenticator app works by sending a first code
+	Enabled bool
+	// LastUsedStep is the TOTP time step of the last accepted code, a code cannot be used twice
+	LastUsedStep int64
+	Created      time.Time
+	Updated      time.Time
+}
+
+func (userMFA) TableName() string {
+	return "user_mfa"
+}
+
+// orgSetting tells whether the members of an organization must use a second factor
+type orgSetting struct {
+	ID       int64 `xorm:"pk autoincr 'id'"`
+	OrgID    int64 `xorm:"org_id"`
+	Required bool
+	Updated  time.Time
+}
+
+func (orgSetting) TableName() string {
+	return "mfa_org_setting"
+}
+
+// challengeState is kept in the remote cache between the password and the TOTP steps of a login
+type challengeState struct {
+	UserID   int64  `json:"userId"`
+	Login    string `json:"login"`
+	Enroll   bool   `json:"enroll"`
+	Attempts int    `json:"attempts"`
+}
+
+// OrgSettings is the second factor policy of an organization
+type OrgSettings struct {
+	Required bool `json:"required"`
+}
+
+type codeForm struct {
+	Code string `json:"code" binding:"Required"`
+}
+
+type challengeForm struct {
+	Challenge string `json:"challenge" binding:"Required"`
+}
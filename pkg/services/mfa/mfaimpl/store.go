@@ -0,0 +1,117 @@
+package mfaimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) get(ctx context.Context, userID int64) (*userMFA, error) {
+	row := &userMFA{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("user_id = ?", userID).Get(row)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return mfa.ErrNotEnrolled.Errorf("user %d has no second factor", userID)
+		}
+		return nil
+	})
+	return row, err
+}
+
+// replace stores a new second factor for a user, dropping the previous one
+func (s *sqlStore) replace(ctx context.Context, row *userMFA) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM user_mfa WHERE user_id = ?", row.UserID); err != nil {
+			return err
+		}
+		_, err := sess.Insert(row)
+		return err
+	})
+}
+
+func (s *sqlStore) enable(ctx context.Context, id int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Cols("enabled", "updated").Update(&userMFA{Enabled: true, Updated: time.Now()})
+		return err
+	})
+}
+
+func (s *sqlStore) setRecoveryCodes(ctx context.Context, id int64, hashes []string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(id).Cols("recovery_codes", "updated").Update(&userMFA{RecoveryCodes: hashes, Updated: time.Now()})
+		return err
+	})
+}
+
+// useStep records that the code of a time step was accepted. It returns false when a code of that step, or a
+// later one, was already used.
+func (s *sqlStore) useStep(ctx context.Context, id int64, step int64) (bool, error) {
+	var used bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa SET last_used_step = ?, updated = ? WHERE id = ? AND last_used_step < ?",
+			step, time.Now(), id, step)
+		if err != nil {
+			return err
+		}
+		affected, err := res.RowsAffected()
+		used = affected == 1
+		return err
+	})
+	return used, err
+}
+
+func (s *sqlStore) delete(ctx context.Context, userID int64) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM user_mfa WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+// isRequiredByOrg tells whether any organization the user is a member of requires a second factor
+func (s *sqlStore) isRequiredByOrg(ctx context.Context, userID int64) (bool, error) {
+	var required bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		required, err = sess.SQL(`SELECT 1 FROM mfa_org_setting
+			INNER JOIN org_user ON org_user.org_id = mfa_org_setting.org_id
+			WHERE org_user.user_id = ? AND mfa_org_setting.required = ?`, userID, true).Exist()
+		return err
+	})
+	return required, err
+}
+
+func (s *sqlStore) getOrgSetting(ctx context.Context, orgID int64) (*orgSetting, error) {
+	setting := &orgSetting{OrgID: orgID}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Where("org_id = ?", orgID).Get(setting)
+		return err
+	})
+	return setting, err
+}
+
+func (s *sqlStore) saveOrgSetting(ctx context.Context, setting *orgSetting) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		existing := &orgSetting{}
+		exists, err := sess.Where("org_id = ?", setting.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+		setting.Updated = time.Now()
+		if exists {
+			setting.ID = existing.ID
+			_, err = sess.ID(existing.ID).AllCols().Update(setting)
+			return err
+		}
+		_, err = sess.Insert(setting)
+		return err
+	})
+}
@@ -0,0 +1,35 @@
+package mfatest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+var _ mfa.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedChallenge *mfa.Challenge
+	ExpectedUserID    int64
+	ExpectedRequired  bool
+	ExpectedErr       error
+
+	ResetCalled bool
+}
+
+func (f *FakeService) Challenge(ctx context.Context, query *mfa.ChallengeQuery) (*mfa.Challenge, error) {
+	return f.ExpectedChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) VerifyChallenge(ctx context.Context, cmd *mfa.VerifyChallengeCommand) (int64, error) {
+	return f.ExpectedUserID, f.ExpectedErr
+}
+
+func (f *FakeService) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	return f.ExpectedRequired, f.ExpectedErr
+}
+
+func (f *FakeService) Reset(ctx context.Context, userID int64) error {
+	f.ResetCalled = true
+	return f.ExpectedErr
+}
@@ -0,0 +1,74 @@
+package mfa
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret is the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890", base32 encoded
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateCode(t *testing.T) {
+	// RFC 6238 appendix B, truncated to 6 digits
+	vectors := map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	}
+	for unix, code := range vectors {
+		step, ok := ValidateCode(rfc6238Secret, code, time.Unix(unix, 0))
+		require.True(t, ok, unix)
+		assert.Equal(t, unix/30, step)
+	}
+
+	t.Run("accepts codes of the previous and next period", func(t *testing.T) {
+		step, ok := ValidateCode(rfc6238Secret, "081804", time.Unix(1111111109+30, 0))
+		require.True(t, ok)
+		assert.Equal(t, int64(1111111109/30), step)
+
+		_, ok = ValidateCode(rfc6238Secret, "081804", time.Unix(1111111109+60, 0))
+		require.False(t, ok)
+	})
+
+	t.Run("rejects malformed codes and secrets", func(t *testing.T) {
+		_, ok := ValidateCode(rfc6238Secret, "28708", time.Unix(59, 0))
+		require.False(t, ok)
+		_, ok = ValidateCode("not base32!", "287082", time.Unix(59, 0))
+		require.False(t, ok)
+	})
+}
+
+func TestGenerateCode(t *testing.T) {
+	code, err := GenerateCode(rfc6238Secret, time.Unix(1234567890, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "005924", code)
+
+	_, err = GenerateCode("not base32!", time.Unix(59, 0))
+	require.Error(t, err)
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestKeyURL(t *testing.T) {
+	u, err := url.Parse(KeyURL("Grafana", "admin@example.com", rfc6238Secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Grafana:admin@example.com", u.Path)
+	assert.Equal(t, rfc6238Secret, u.Query().Get("secret"))
+	assert.Equal(t, "Grafana", u.Query().Get("issuer"))
+}
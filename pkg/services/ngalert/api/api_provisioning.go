@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/ngalert/api/hcl"
@@ -112,6 +113,7 @@ func (srv *ProvisioningSrv) RoutePutPolicyTree(c *contextmodel.ReqContext, tree
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, auditlog.Resource{Kind: auditKindPolicies}, nil, map[string]any{"receiver": tree.Receiver, "provenance": provenance})
 
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "policies updated"})
 }
@@ -121,6 +123,7 @@ func (srv *ProvisioningSrv) RouteResetPolicyTree(c *contextmodel.ReqContext) res
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, auditlog.Resource{Kind: auditKindPolicies}, nil, map[string]any{"receiver": tree.Receiver})
 	return response.JSON(http.StatusAccepted, tree)
 }
 
@@ -170,6 +173,7 @@ func (srv *ProvisioningSrv) RoutePostContactPoint(c *contextmodel.ReqContext, cp
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, contactPointAuditResource(contactPoint), nil, contactPointAuditSummary(contactPoint))
 	return response.JSON(http.StatusAccepted, contactPoint)
 }
 
@@ -186,6 +190,7 @@ func (srv *ProvisioningSrv) RoutePutContactPoint(c *contextmodel.ReqContext, cp
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, contactPointAuditResource(cp), nil, contactPointAuditSummary(cp))
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "contactpoint updated"})
 }
 
@@ -194,6 +199,7 @@ func (srv *ProvisioningSrv) RouteDeleteContactPoint(c *contextmodel.ReqContext,
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete contact point", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, auditlog.Resource{Kind: auditKindContactPoints, UID: UID}, nil, nil)
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "contactpoint deleted"})
 }
 
@@ -231,6 +237,7 @@ func (srv *ProvisioningSrv) RoutePutTemplate(c *contextmodel.ReqContext, body de
 		}
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, auditlog.Resource{Kind: auditKindTemplates, UID: name, Name: name}, nil, nil)
 	return response.JSON(http.StatusAccepted, modified)
 }
 
@@ -239,6 +246,7 @@ func (srv *ProvisioningSrv) RouteDeleteTemplate(c *contextmodel.ReqContext, name
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, auditlog.Resource{Kind: auditKindTemplates, UID: name, Name: name}, nil, nil)
 	return response.JSON(http.StatusNoContent, nil)
 }
 
@@ -287,6 +295,7 @@ func (srv *ProvisioningSrv) RoutePostMuteTiming(c *contextmodel.ReqContext, mt d
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to create mute timing", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, auditlog.Resource{Kind: auditKindMuteTimings, UID: created.Name, Name: created.Name}, nil, created)
 	return response.JSON(http.StatusCreated, created)
 }
 
@@ -297,6 +306,7 @@ func (srv *ProvisioningSrv) RoutePutMuteTiming(c *contextmodel.ReqContext, mt de
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update mute timing", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, auditlog.Resource{Kind: auditKindMuteTimings, UID: name, Name: name}, nil, updated)
 	return response.JSON(http.StatusAccepted, updated)
 }
 
@@ -305,6 +315,7 @@ func (srv *ProvisioningSrv) RouteDeleteMuteTiming(c *contextmodel.ReqContext, na
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to delete mute timing", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, auditlog.Resource{Kind: auditKindMuteTimings, UID: name, Name: name}, nil, nil)
 	return response.JSON(http.StatusNoContent, nil)
 }
 
@@ -351,6 +362,8 @@ func (srv *ProvisioningSrv) RoutePostAlertRule(c *contextmodel.ReqContext, ar de
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
 
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, alertRuleAuditResource(createdAlertRule), nil, alertRuleAuditSummary(createdAlertRule))
+
 	resp := ProvisionedAlertRuleFromAlertRule(createdAlertRule, alerting_models.Provenance(provenance))
 	return response.JSON(http.StatusCreated, resp)
 }
@@ -363,6 +376,7 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 	updated.OrgID = c.SignedInUser.GetOrgID()
 	updated.UID = UID
 	provenance := determineProvenance(c)
+	before := srv.alertRuleAuditBefore(c, UID)
 	updatedAlertRule, err := srv.alertRules.UpdateAlertRule(c.Req.Context(), c.SignedInUser, updated, alerting_models.Provenance(provenance))
 	if errors.Is(err, alerting_models.ErrAlertRuleUniqueConstraintViolation) {
 		return ErrResp(http.StatusBadRequest, err, "")
@@ -380,16 +394,20 @@ func (srv *ProvisioningSrv) RoutePutAlertRule(c *contextmodel.ReqContext, ar def
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
 
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, alertRuleAuditResource(updatedAlertRule), before, alertRuleAuditSummary(updatedAlertRule))
+
 	resp := ProvisionedAlertRuleFromAlertRule(updatedAlertRule, alerting_models.Provenance(provenance))
 	return response.JSON(http.StatusOK, resp)
 }
 
 func (srv *ProvisioningSrv) RouteDeleteAlertRule(c *contextmodel.ReqContext, UID string) response.Response {
 	provenance := determineProvenance(c)
+	before := srv.alertRuleAuditBefore(c, UID)
 	err := srv.alertRules.DeleteAlertRule(c.Req.Context(), c.SignedInUser, UID, alerting_models.Provenance(provenance))
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, auditlog.Resource{Kind: auditKindAlertRules, UID: UID}, before, nil)
 	return response.JSON(http.StatusNoContent, "")
 }
 
@@ -494,6 +512,8 @@ func (srv *ProvisioningSrv) RoutePutAlertRuleGroup(c *contextmodel.ReqContext, a
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, alertRuleGroupAuditResource(folderUID, group), nil,
+		map[string]any{"folderUid": folderUID, "interval": ag.Interval, "rules": len(ag.Rules)})
 	return response.JSON(http.StatusOK, ag)
 }
 
@@ -503,9 +523,60 @@ func (srv *ProvisioningSrv) RouteDeleteAlertRuleGroup(c *contextmodel.ReqContext
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, alertRuleGroupAuditResource(folderUID, group), nil, nil)
 	return response.JSON(http.StatusNoContent, "")
 }
 
+// Kinds of the provisioned alerting resources in the audit log
+const (
+	auditKindAlertRules    = "alert.rules"
+	auditKindRuleGroups    = "alert.rule-groups"
+	auditKindContactPoints = "alert.contact-points"
+	auditKindPolicies      = "alert.notification-policies"
+	auditKindTemplates     = "alert.templates"
+	auditKindMuteTimings   = "alert.mute-timings"
+)
+
+func contactPointAuditResource(cp definitions.EmbeddedContactPoint) auditlog.Resource {
+	return auditlog.Resource{Kind: auditKindContactPoints, UID: cp.UID, Name: cp.Name}
+}
+
+// contactPointAuditSummary leaves the settings out as they may contain secrets
+func contactPointAuditSummary(cp definitions.EmbeddedContactPoint) map[string]any {
+	return map[string]any{"name": cp.Name, "type": cp.Type, "disableResolveMessage": cp.DisableResolveMessage}
+}
+
+func alertRuleAuditResource(rule alerting_models.AlertRule) auditlog.Resource {
+	return auditlog.Resource{Kind: auditKindAlertRules, UID: rule.UID, Name: rule.Title}
+}
+
+func alertRuleAuditSummary(rule alerting_models.AlertRule) map[string]any {
+	return map[string]any{
+		"title":     rule.Title,
+		"folderUid": rule.NamespaceUID,
+		"ruleGroup": rule.RuleGroup,
+		"isPaused":  rule.IsPaused,
+		"version":   rule.Version,
+	}
+}
+
+// alertRuleAuditBefore returns the summary of an alert rule before it is changed, it is only loaded when the
+// request is recorded in the audit log
+func (srv *ProvisioningSrv) alertRuleAuditBefore(c *contextmodel.ReqContext, UID string) map[string]any {
+	if !auditlog.IsAudited(c.Req.Context()) {
+		return nil
+	}
+	rule, _, err := srv.alertRules.GetAlertRule(c.Req.Context(), c.SignedInUser, UID)
+	if err != nil {
+		return nil
+	}
+	return alertRuleAuditSummary(rule)
+}
+
+func alertRuleGroupAuditResource(folderUID, group string) auditlog.Resource {
+	return auditlog.Resource{Kind: auditKindRuleGroups, UID: folderUID + "/" + group, Name: group}
+}
+
 func determineProvenance(ctx *contextmodel.ReqContext) definitions.Provenance {
 	if _, disabled := ctx.Req.Header[disableProvenanceHeaderName]; disabled {
 		return definitions.Provenance(alerting_models.ProvenanceNone)
@@ -244,6 +244,7 @@ func (ss *sqlStore) Delete(ctx context.Context, cmd *org.DeleteOrgCommand) error
 			"DELETE FROM team_role WHERE org_id = ?",
 			"DELETE FROM user_role WHERE org_id = ?",
 			"DELETE FROM builtin_role WHERE org_id = ?",
+			"DELETE FROM mfa_org_setting WHERE org_id = ?",
 		}
 
 		// Add registered deletes
@@ -817,6 +818,7 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_mfa WHERE user_id = ?",
 	}
 	return deletes
 }
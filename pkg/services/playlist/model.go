@@ -2,12 +2,16 @@ package playlist
 
 import (
 	"errors"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 // Typed errors
 var (
 	ErrPlaylistNotFound        = errors.New("Playlist not found")
 	ErrCommandValidationFailed = errors.New("command missing required fields")
+	ErrInvalidItem             = errutil.BadRequest("playlist.invalidItem", errutil.WithPublicMessage("Invalid playlist item"))
+	ErrNoActiveItem            = errutil.NotFound("playlist.noActiveItem", errutil.WithPublicMessage("No playlist item is scheduled at this time"))
 )
 
 // Playlist model
@@ -68,15 +72,54 @@ type PlaylistItemDTO struct {
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
 	Value string `json:"value"`
+
+	// Duration overrides the playlist interval while the item is shown.
+	Duration string `json:"duration,omitempty"`
+
+	// Schedule restricts the item to some days of the week and times of day.
+	Schedule *ItemSchedule `json:"schedule,omitempty"`
+
+	// Variables override the template variables of the dashboards.
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// TimeRange overrides the time range of the dashboards.
+	TimeRange *ItemTimeRange `json:"timeRange,omitempty"`
+}
+
+// ItemSchedule is a weekly window during which a playlist item is shown
+type ItemSchedule struct {
+	// Days of the week from mon to sun, every day when empty.
+	Days []string `json:"days,omitempty"`
+
+	// From is the time of day the window starts, as HH:MM. Defaults to midnight.
+	From string `json:"from,omitempty"`
+
+	// To is the time of day the window ends, as HH:MM. Defaults to midnight at the end of the day.
+	// A window that ends before it starts spans midnight and belongs to the day it starts.
+	To string `json:"to,omitempty"`
+
+	// Timezone is the IANA time zone of the window. Defaults to the time zone of the kiosk.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+type ItemTimeRange struct {
+	// From is a time such as now-6h
+	From string `json:"from"`
+	// To is a time such as now
+	To string `json:"to"`
 }
 
 type PlaylistItem struct {
-	Id         int64  `db:"id"`
-	PlaylistId int64  `db:"playlist_id"`
-	Type       string `json:"type" db:"type"`
-	Value      string `json:"value" db:"value"`
-	Order      int    `json:"order" db:"order"`
-	Title      string `json:"title" db:"title"`
+	Id         int64             `db:"id"`
+	PlaylistId int64             `db:"playlist_id"`
+	Type       string            `json:"type" db:"type"`
+	Value      string            `json:"value" db:"value"`
+	Order      int               `json:"order" db:"order"`
+	Title      string            `json:"title" db:"title"`
+	Duration   string            `json:"duration,omitempty" db:"duration"`
+	Schedule   *ItemSchedule     `json:"schedule,omitempty" xorm:"json" db:"schedule"`
+	Variables  map[string]string `json:"variables,omitempty" xorm:"json" db:"variables"`
+	TimeRange  *ItemTimeRange    `json:"timeRange,omitempty" xorm:"json" db:"time_range"`
 }
 
 type Playlists []*Playlist
@@ -127,3 +170,18 @@ type GetPlaylistItemsByUidQuery struct {
 	PlaylistUID string
 	OrgId       int64
 }
+
+// CurrentItemDTO is the item a kiosk shows at a given time
+type CurrentItemDTO struct {
+	Item PlaylistItemDTO `json:"item"`
+
+	// Index of the item in the playlist.
+	Index int `json:"index"`
+
+	// Unix timestamp in milliseconds of the start of the item's turn.
+	Start int64 `json:"start"`
+
+	// Unix timestamp in milliseconds at which the kiosk should ask for the current item again, at the end of the
+	// item's turn or when the scheduled items change, whichever comes first.
+	End int64 `json:"end"`
+}
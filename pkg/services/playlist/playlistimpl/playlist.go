@@ -27,12 +27,18 @@ func ProvideService(db db.DB, tracer tracing.Tracer) playlist.Service {
 func (s *Service) Create(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Create")
 	defer span.End()
+	if err := playlist.ValidateItems(cmd.Items); err != nil {
+		return nil, err
+	}
 	return s.store.Insert(ctx, cmd)
 }
 
 func (s *Service) Update(ctx context.Context, cmd *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Update")
 	defer span.End()
+	if err := playlist.ValidateItems(cmd.Items); err != nil {
+		return nil, err
+	}
 	return s.store.Update(ctx, cmd)
 }
 
@@ -60,6 +66,10 @@ func (s *Service) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*
 	for i := 0; i < len(rawItems); i++ {
 		items[i].Type = rawItems[i].Type
 		items[i].Value = rawItems[i].Value
+		items[i].Duration = rawItems[i].Duration
+		items[i].Schedule = rawItems[i].Schedule
+		items[i].Variables = rawItems[i].Variables
+		items[i].TimeRange = rawItems[i].TimeRange
 
 		// Add the unused title to the result
 		title := rawItems[i].Title
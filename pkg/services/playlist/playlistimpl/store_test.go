@@ -53,6 +53,41 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 			require.Equal(t, len(items), len(storedPlaylistItems))
 		})
 
+		t.Run("Can store item schedules and overrides", func(t *testing.T) {
+			items := []playlist.PlaylistItem{
+				{
+					Value:     "ops",
+					Type:      "dashboard_by_uid",
+					Duration:  "30s",
+					Schedule:  &playlist.ItemSchedule{Days: []string{"mon", "fri"}, From: "08:00", To: "18:00", Timezone: "Europe/Paris"},
+					Variables: map[string]string{"env": "prod"},
+					TimeRange: &playlist.ItemTimeRange{From: "now-6h", To: "now"},
+				},
+				{Value: "graphite", Type: "dashboard_by_tag"},
+			}
+			cmd := playlist.CreatePlaylistCommand{Name: "Office TV", Interval: "5m", OrgId: 3, Items: items}
+			p, err := playlistStore.Insert(context.Background(), &cmd)
+			require.NoError(t, err)
+
+			stored, err := playlistStore.GetItems(context.Background(), &playlist.GetPlaylistItemsByUidQuery{PlaylistUID: p.UID, OrgId: 3})
+			require.NoError(t, err)
+			require.Len(t, stored, 2)
+			require.Equal(t, items[0].Schedule, stored[0].Schedule)
+			require.Equal(t, items[0].Variables, stored[0].Variables)
+			require.Equal(t, items[0].TimeRange, stored[0].TimeRange)
+			require.Equal(t, "30s", stored[0].Duration)
+			require.Nil(t, stored[1].Schedule)
+			require.Empty(t, stored[1].Variables)
+
+			all, err := playlistStore.ListAll(context.Background(), 3)
+			require.NoError(t, err)
+			require.Len(t, all, 1)
+			require.Equal(t, []playlist.PlaylistItemDTO{
+				{Type: "dashboard_by_uid", Value: "ops", Duration: "30s", Schedule: items[0].Schedule, Variables: items[0].Variables, TimeRange: items[0].TimeRange},
+				{Type: "dashboard_by_tag", Value: "graphite"},
+			}, all[0].Items)
+		})
+
 		t.Run("Can update playlist", func(t *testing.T) {
 			time.Sleep(time.Millisecond * 2)
 			items := []playlist.PlaylistItem{
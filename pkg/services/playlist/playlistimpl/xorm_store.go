@@ -2,6 +2,8 @@ package playlistimpl
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -52,6 +54,10 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComma
 				Value:      item.Value,
 				Order:      order + 1,
 				Title:      item.Title,
+				Duration:   item.Duration,
+				Schedule:   item.Schedule,
+				Variables:  item.Variables,
+				TimeRange:  item.TimeRange,
 			})
 		}
 
@@ -108,6 +114,10 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 				Value:      item.Value,
 				Order:      index + 1,
 				Title:      item.Title,
+				Duration:   item.Duration,
+				Schedule:   item.Schedule,
+				Variables:  item.Variables,
+				TimeRange:  item.TimeRange,
 			})
 		}
 
@@ -199,8 +209,10 @@ func (s *sqlStore) ListAll(ctx context.Context, orgId int64) ([]playlist.Playlis
 	var playlistId int64
 	var itemType string
 	var itemValue string
+	var itemDuration, itemSchedule, itemVariables, itemTimeRange sql.NullString
 
-	rows, err := db.Query(ctx, `SELECT playlist.id,playlist_item.type,playlist_item.value
+	rows, err := db.Query(ctx, `SELECT playlist.id,playlist_item.type,playlist_item.value,
+		playlist_item.duration,playlist_item.schedule,playlist_item.variables,playlist_item.time_range
 		FROM playlist_item 
 		JOIN playlist ON playlist_item.playlist_id = playlist.id
 		WHERE playlist.org_id = ?
@@ -209,7 +221,7 @@ func (s *sqlStore) ListAll(ctx context.Context, orgId int64) ([]playlist.Playlis
 		return nil, err
 	}
 	for rows.Next() {
-		err = rows.Scan(&playlistId, &itemType, &itemValue)
+		err = rows.Scan(&playlistId, &itemType, &itemValue, &itemDuration, &itemSchedule, &itemVariables, &itemTimeRange)
 		if err != nil {
 			return nil, err
 		}
@@ -217,15 +229,34 @@ func (s *sqlStore) ListAll(ctx context.Context, orgId int64) ([]playlist.Playlis
 		if !ok {
 			return nil, fmt.Errorf("could not find playlist by id")
 		}
-		items := append(playlists[idx].Items, playlist.PlaylistItemDTO{
-			Type:  itemType,
-			Value: itemValue,
-		})
+		item := playlist.PlaylistItemDTO{
+			Type:     itemType,
+			Value:    itemValue,
+			Duration: itemDuration.String,
+		}
+		if err := unmarshalColumn(itemSchedule, &item.Schedule); err != nil {
+			return nil, err
+		}
+		if err := unmarshalColumn(itemVariables, &item.Variables); err != nil {
+			return nil, err
+		}
+		if err := unmarshalColumn(itemTimeRange, &item.TimeRange); err != nil {
+			return nil, err
+		}
+		items := append(playlists[idx].Items, item)
 		playlists[idx].Items = items
 	}
 	return playlists, err
 }
 
+// unmarshalColumn decodes a JSON column written by xorm, where a nil value is stored as null
+func unmarshalColumn(column sql.NullString, v any) error {
+	if !column.Valid || column.String == "" || column.String == "null" {
+		return nil
+	}
+	return json.Unmarshal([]byte(column.String), v)
+}
+
 func (s *sqlStore) GetItems(ctx context.Context, query *playlist.GetPlaylistItemsByUidQuery) ([]playlist.PlaylistItem, error) {
 	var playlistItems = make([]playlist.PlaylistItem, 0)
 	if query.PlaylistUID == "" || query.OrgId == 0 {
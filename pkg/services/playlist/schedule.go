@@ -0,0 +1,211 @@
+package playlist
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// defaultInterval matches the interval used by the frontend when the playlist interval cannot be parsed
+const defaultInterval = 5 * time.Minute
+
+// minItemDuration is the shortest turn of an item, shorter durations would make kiosks reload constantly
+const minItemDuration = time.Second
+
+const minutesPerDay = 24 * 60
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ValidateItems checks the duration, schedule and overrides of playlist items. Days are normalized to lower case.
+func ValidateItems(items []PlaylistItem) error {
+	for i := range items {
+		item := &items[i]
+		if item.Duration != "" {
+			d, err := gtime.ParseDuration(item.Duration)
+			if err != nil || d <= 0 {
+				return ErrInvalidItem.Errorf("item %d: invalid duration %q", i, item.Duration)
+			}
+			if d < minItemDuration {
+				return ErrInvalidItem.Errorf("item %d: duration %q is shorter than %s", i, item.Duration, minItemDuration)
+			}
+		}
+
+		if item.Schedule != nil {
+			if err := validateSchedule(item.Schedule); err != nil {
+				return ErrInvalidItem.Errorf("item %d: %w", i, err)
+			}
+		}
+
+		for name := range item.Variables {
+			if strings.TrimSpace(name) == "" {
+				return ErrInvalidItem.Errorf("item %d: variable name is empty", i)
+			}
+		}
+
+		if item.TimeRange != nil && (item.TimeRange.From == "" || item.TimeRange.To == "") {
+			return ErrInvalidItem.Errorf("item %d: time range needs both from and to", i)
+		}
+	}
+	return nil
+}
+
+func validateSchedule(schedule *ItemSchedule) error {
+	for i, day := range schedule.Days {
+		day = strings.ToLower(strings.TrimSpace(day))
+		if _, ok := weekdays[day]; !ok {
+			return ErrInvalidItem.Errorf("invalid day %q", schedule.Days[i])
+		}
+		schedule.Days[i] = day
+	}
+	if _, err := parseClock(schedule.From, 0); err != nil {
+		return err
+	}
+	if _, err := parseClock(schedule.To, minutesPerDay); err != nil {
+		return err
+	}
+	if schedule.Timezone != "" {
+		if _, err := time.LoadLocation(schedule.Timezone); err != nil {
+			return ErrInvalidItem.Errorf("invalid timezone %q", schedule.Timezone)
+		}
+	}
+	return nil
+}
+
+// parseClock returns the minutes since midnight of a HH:MM time of day, or def when it is empty
+func parseClock(clock string, def int) (int, error) {
+	if clock == "" {
+		return def, nil
+	}
+	hours, minutes, ok := strings.Cut(clock, ":")
+	h, herr := strconv.Atoi(hours)
+	m, merr := strconv.Atoi(minutes)
+	if !ok || len(hours) != 2 || len(minutes) != 2 || herr != nil || merr != nil || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, ErrInvalidItem.Errorf("invalid time of day %q, expected HH:MM", clock)
+	}
+	return h*60 + m, nil
+}
+
+// ResolveCurrentItem returns the item a kiosk shows at now. The items scheduled at now take turns, each for its
+// duration or the playlist interval, in a cycle aligned on the Unix epoch so that every kiosk showing the playlist
+// shows the same item. A dashboard_by_tag item takes a single turn. Schedules without a timezone are evaluated in loc.
+func ResolveCurrentItem(p *PlaylistDTO, now time.Time, loc *time.Location) (*CurrentItemDTO, error) {
+	interval, err := gtime.ParseDuration(p.Interval)
+	if err != nil || interval <= 0 {
+		interval = defaultInterval
+	}
+	if interval < minItemDuration {
+		interval = minItemDuration
+	}
+
+	type turn struct {
+		index    int
+		duration time.Duration
+	}
+	turns := make([]turn, 0, len(p.Items))
+	var cycle time.Duration
+	end := time.Time{}
+	for i, item := range p.Items {
+		if item.Schedule != nil {
+			active, change := evalSchedule(item.Schedule, now, loc)
+			if end.IsZero() || change.Before(end) {
+				end = change
+			}
+			if !active {
+				continue
+			}
+		}
+
+		// items saved before durations were validated may be shorter than the minimum
+		duration := interval
+		if d, err := gtime.ParseDuration(item.Duration); err == nil && d >= minItemDuration {
+			duration = d
+		}
+		turns = append(turns, turn{index: i, duration: duration})
+		cycle += duration
+	}
+	if len(turns) == 0 {
+		return nil, ErrNoActiveItem.Errorf("no item of playlist %s is scheduled at %s", p.Uid, now.Format(time.RFC3339))
+	}
+
+	// every turn lasts at least minItemDuration, the guard keeps the modulo safe if that changes
+	if cycle.Milliseconds() == 0 {
+		return nil, ErrNoActiveItem.Errorf("playlist %s has no item with a duration", p.Uid)
+	}
+	offset := time.Duration(now.UnixMilli()%cycle.Milliseconds()) * time.Millisecond
+	for _, t := range turns {
+		if offset < t.duration {
+			start := now.Add(-offset)
+			turnEnd := start.Add(t.duration)
+			if end.IsZero() || turnEnd.Before(end) {
+				end = turnEnd
+			}
+			return &CurrentItemDTO{
+				Item:  p.Items[t.index],
+				Index: t.index,
+				Start: start.UnixMilli(),
+				End:   end.UnixMilli(),
+			}, nil
+		}
+		offset -= t.duration
+	}
+	// unreachable, the offset is smaller than the cycle
+	return nil, ErrNoActiveItem.Errorf("no item of playlist %s is scheduled at %s", p.Uid, now.Format(time.RFC3339))
+}
+
+// evalSchedule tells whether a schedule is active at now and returns the next time it may change. Schedules are
+// validated when saved, invalid fields fall back to their defaults.
+func evalSchedule(schedule *ItemSchedule, now time.Time, loc *time.Location) (bool, time.Time) {
+	if schedule.Timezone != "" {
+		if l, err := time.LoadLocation(schedule.Timezone); err == nil {
+			loc = l
+		}
+	}
+	now = now.In(loc)
+	from, _ := parseClock(schedule.From, 0)
+	to, _ := parseClock(schedule.To, minutesPerDay)
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	minute := now.Hour()*60 + now.Minute()
+	var active bool
+	if from < to {
+		active = isScheduledOn(schedule, now.Weekday()) && minute >= from && minute < to
+	} else {
+		// the window spans midnight and belongs to the day it starts
+		active = (isScheduledOn(schedule, now.Weekday()) && minute >= from) ||
+			(isScheduledOn(schedule, midnight.AddDate(0, 0, -1).Weekday()) && minute < to)
+	}
+
+	change := midnight.AddDate(0, 0, 1)
+	for _, clock := range []int{from, to} {
+		next := midnight.Add(time.Duration(clock) * time.Minute)
+		if !next.After(now) {
+			next = midnight.AddDate(0, 0, 1).Add(time.Duration(clock) * time.Minute)
+		}
+		if next.Before(change) {
+			change = next
+		}
+	}
+	return active, change
+}
+
+func isScheduledOn(schedule *ItemSchedule, day time.Weekday) bool {
+	if len(schedule.Days) == 0 {
+		return true
+	}
+	for _, d := range schedule.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
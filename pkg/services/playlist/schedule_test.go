@@ -0,0 +1,119 @@
+package playlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateItems(t *testing.T) {
+	items := []PlaylistItem{
+		{Type: "dashboard_by_uid", Value: "a", Duration: "30s"},
+		{Type: "dashboard_by_uid", Value: "b", Schedule: &ItemSchedule{Days: []string{"MON", " fri"}, From: "08:00", To: "24:00", Timezone: "Europe/Paris"}},
+		{Type: "dashboard_by_tag", Value: "c", Variables: map[string]string{"env": "prod"}, TimeRange: &ItemTimeRange{From: "now-6h", To: "now"}},
+	}
+	require.NoError(t, ValidateItems(items))
+	assert.Equal(t, []string{"mon", "fri"}, items[1].Schedule.Days)
+
+	invalid := map[string]PlaylistItem{
+		"duration":    {Duration: "soon"},
+		"no duration": {Duration: "0s"},
+		"too short":   {Duration: "500ms"},
+		"day":         {Schedule: &ItemSchedule{Days: []string{"monday"}}},
+		"from":        {Schedule: &ItemSchedule{From: "8:00"}},
+		"to":          {Schedule: &ItemSchedule{To: "24:30"}},
+		"timezone":    {Schedule: &ItemSchedule{Timezone: "Mars/Olympus"}},
+		"variable":    {Variables: map[string]string{" ": "prod"}},
+		"time range":  {TimeRange: &ItemTimeRange{From: "now-6h"}},
+	}
+	for name, item := range invalid {
+		require.ErrorIs(t, ValidateItems([]PlaylistItem{item}), ErrInvalidItem, name)
+	}
+}
+
+func TestResolveCurrentItem(t *testing.T) {
+	t.Run("items take turns for their duration", func(t *testing.T) {
+		p := &PlaylistDTO{Uid: "tv", Interval: "1m", Items: []PlaylistItemDTO{
+			{Type: "dashboard_by_uid", Value: "a"},
+			{Type: "dashboard_by_uid", Value: "b", Duration: "2m"},
+		}}
+		cycleStart := time.UnixMilli(1000 * 3 * 60 * 1000)
+
+		current, err := ResolveCurrentItem(p, cycleStart.Add(30*time.Second), time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, 0, current.Index)
+		assert.Equal(t, cycleStart.UnixMilli(), current.Start)
+		assert.Equal(t, cycleStart.Add(time.Minute).UnixMilli(), current.End)
+
+		current, err = ResolveCurrentItem(p, cycleStart.Add(90*time.Second), time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, 1, current.Index)
+		assert.Equal(t, "b", current.Item.Value)
+		assert.Equal(t, cycleStart.Add(time.Minute).UnixMilli(), current.Start)
+		assert.Equal(t, cycleStart.Add(3*time.Minute).UnixMilli(), current.End)
+	})
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	p := &PlaylistDTO{Uid: "tv", Interval: "1m", Items: []PlaylistItemDTO{
+		{Type: "dashboard_by_uid", Value: "office", Duration: "8m", Schedule: &ItemSchedule{Days: []string{"mon", "tue", "wed", "thu", "fri"}, From: "08:00", To: "18:00", Timezone: "Europe/Paris"}},
+		{Type: "dashboard_by_uid", Value: "night", Schedule: &ItemSchedule{From: "18:00", To: "08:00"}},
+	}}
+
+	t.Run("only scheduled items are shown", func(t *testing.T) {
+		current, err := ResolveCurrentItem(p, time.Date(2024, 1, 15, 10, 0, 0, 0, paris), paris)
+		require.NoError(t, err)
+		assert.Equal(t, "office", current.Item.Value)
+
+		current, err = ResolveCurrentItem(p, time.Date(2024, 1, 15, 23, 0, 0, 0, paris), paris)
+		require.NoError(t, err)
+		assert.Equal(t, "night", current.Item.Value)
+
+		current, err = ResolveCurrentItem(p, time.Date(2024, 1, 16, 7, 0, 0, 0, paris), paris)
+		require.NoError(t, err)
+		assert.Equal(t, "night", current.Item.Value)
+	})
+
+	t.Run("turn ends when the schedule changes", func(t *testing.T) {
+		current, err := ResolveCurrentItem(p, time.Date(2024, 1, 15, 17, 57, 0, 0, paris), paris)
+		require.NoError(t, err)
+		assert.Equal(t, "office", current.Item.Value)
+		assert.Equal(t, time.Date(2024, 1, 15, 17, 56, 0, 0, paris).UnixMilli(), current.Start)
+		assert.Equal(t, time.Date(2024, 1, 15, 18, 0, 0, 0, paris).UnixMilli(), current.End)
+	})
+
+	t.Run("nothing scheduled", func(t *testing.T) {
+		_, err := ResolveCurrentItem(p, time.Date(2024, 1, 20, 12, 0, 0, 0, paris), paris)
+		require.ErrorIs(t, err, ErrNoActiveItem)
+	})
+
+	t.Run("durations shorter than the minimum", func(t *testing.T) {
+		short := &PlaylistDTO{Uid: "tv", Interval: "100ms", Items: []PlaylistItemDTO{
+			{Type: "dashboard_by_uid", Value: "a", Duration: "1ns"},
+			{Type: "dashboard_by_uid", Value: "b", Duration: "500us"},
+		}}
+		current, err := ResolveCurrentItem(short, time.UnixMilli(1500), time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, "b", current.Item.Value)
+		assert.Equal(t, int64(1000), current.Start)
+		assert.Equal(t, int64(2000), current.End)
+	})
+}
+
+func TestEvalSchedule(t *testing.T) {
+	// Friday night, until 2 in the morning
+	schedule := &ItemSchedule{Days: []string{"fri"}, From: "22:00", To: "02:00"}
+
+	active, change := evalSchedule(schedule, time.Date(2024, 1, 20, 1, 0, 0, 0, time.UTC), time.UTC)
+	assert.True(t, active, "saturday morning belongs to friday night")
+	assert.Equal(t, time.Date(2024, 1, 20, 2, 0, 0, 0, time.UTC), change)
+
+	active, _ = evalSchedule(schedule, time.Date(2024, 1, 19, 1, 0, 0, 0, time.UTC), time.UTC)
+	assert.False(t, active, "friday morning belongs to thursday night")
+
+	active, change = evalSchedule(schedule, time.Date(2024, 1, 19, 23, 0, 0, 0, time.UTC), time.UTC)
+	assert.True(t, active)
+	assert.Equal(t, time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC), change, "a new day may change the schedule")
+}
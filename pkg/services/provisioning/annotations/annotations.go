@@ -0,0 +1,94 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
+)
+
+// Provision scans a directory for provisioning config files
+// and provisions the annotation retention policies in those files.
+func Provision(ctx context.Context, configDirectory string, retentionService annotationretention.Service) error {
+	logger := log.New("provisioning.annotations")
+	ap := AnnotationProvisioner{
+		log:              logger,
+		cfgProvider:      &configReader{log: logger},
+		retentionService: retentionService,
+	}
+	return ap.applyChanges(ctx, configDirectory)
+}
+
+// AnnotationProvisioner is responsible for provisioning annotation retention policies based on
+// configuration read by the `configReader`
+type AnnotationProvisioner struct {
+	log              log.Logger
+	cfgProvider      *configReader
+	retentionService annotationretention.Service
+}
+
+func (ap *AnnotationProvisioner) apply(ctx context.Context, cfg *annotationsAsConfig) error {
+	for _, policy := range cfg.DeleteRetentionPolicies {
+		ap.log.Info("Deleting annotation retention policy from configuration", "orgId", policy.OrgID, "uid", policy.UID)
+		err := ap.retentionService.DeletePolicy(ctx, &annotationretention.DeletePolicyCommand{
+			UID:         policy.UID,
+			OrgID:       policy.OrgID,
+			Provisioned: true,
+		})
+		if err != nil && !errors.Is(err, annotationretention.ErrPolicyNotFound) {
+			return fmt.Errorf("failed to delete retention policy %s: %w", policy.UID, err)
+		}
+	}
+
+	for _, policy := range cfg.RetentionPolicies {
+		settings := annotationretention.PolicySettings{
+			Name:         policy.Name,
+			Tags:         policy.Tags,
+			DashboardUID: policy.DashboardUID,
+			MaxAge:       policy.MaxAge,
+			MaxCount:     policy.MaxCount,
+		}
+
+		_, err := ap.retentionService.GetPolicy(ctx, &annotationretention.GetPolicyQuery{UID: policy.UID, OrgID: policy.OrgID})
+		switch {
+		case errors.Is(err, annotationretention.ErrPolicyNotFound):
+			ap.log.Info("Inserting annotation retention policy from configuration", "orgId", policy.OrgID, "uid", policy.UID)
+			_, err = ap.retentionService.CreatePolicy(ctx, &annotationretention.CreatePolicyCommand{
+				PolicySettings: settings,
+				UID:            policy.UID,
+				OrgID:          policy.OrgID,
+				Provisioned:    true,
+			})
+		case err == nil:
+			ap.log.Info("Updating annotation retention policy from configuration", "orgId", policy.OrgID, "uid", policy.UID)
+			_, err = ap.retentionService.UpdatePolicy(ctx, &annotationretention.UpdatePolicyCommand{
+				PolicySettings: settings,
+				UID:            policy.UID,
+				OrgID:          policy.OrgID,
+				Provisioned:    true,
+			})
+		}
+		if err != nil {
+			return fmt.Errorf("failed to provision retention policy %s: %w", policy.UID, err)
+		}
+	}
+
+	return nil
+}
+
+func (ap *AnnotationProvisioner) applyChanges(ctx context.Context, configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		if err := ap.apply(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package annotations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention/annotationretentiontest"
+)
+
+const (
+	correctConfig = "./testdata/correct-config"
+	invalidConfig = "./testdata/invalid-config"
+	brokenYaml    = "./testdata/broken-yaml"
+	missingFolder = "./testdata/missing-folder"
+)
+
+func TestConfigReader(t *testing.T) {
+	reader := &configReader{log: log.New("test logger")}
+
+	t.Run("Broken yaml should return error", func(t *testing.T) {
+		_, err := reader.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("Skip missing directory", func(t *testing.T) {
+		cfg, err := reader.readConfig(missingFolder)
+		require.NoError(t, err)
+		require.Len(t, cfg, 0)
+	})
+
+	t.Run("Policy without uid should return error", func(t *testing.T) {
+		_, err := reader.readConfig(invalidConfig)
+		require.EqualError(t, err, "retention policy 1 in configuration doesn't contain required field uid")
+	})
+
+	t.Run("Can read correct properties", func(t *testing.T) {
+		t.Setenv("BUILD_TAG", "build:main")
+
+		cfg, err := reader.readConfig(correctConfig)
+		require.NoError(t, err)
+		require.Len(t, cfg, 1)
+
+		assert.Equal(t, []*retentionPolicyFromConfig{
+			{OrgID: 1, UID: "deploys", Name: "Deploys", Tags: []string{"deploy"}, MaxAge: "2y"},
+			{OrgID: 2, UID: "ci", Name: "CI builds", Tags: []string{"ci", "build:main"}, MaxAge: "30d", MaxCount: 1000},
+			{OrgID: 1, UID: "dashboard", Name: "Noisy dashboard", Tags: []string{}, DashboardUID: "noisy", MaxCount: 100},
+		}, cfg[0].RetentionPolicies)
+		assert.Equal(t, []*deleteRetentionPolicyConfig{{OrgID: 2, UID: "old"}}, cfg[0].DeleteRetentionPolicies)
+	})
+}
+
+func TestProvision(t *testing.T) {
+	t.Setenv("BUILD_TAG", "build:main")
+
+	retentionService := annotationretentiontest.NewFakeService(
+		&annotationretention.Policy{UID: "old", OrgID: 2, Name: "Old", MaxAge: "1y", Provisioned: true},
+		&annotationretention.Policy{UID: "deploys", OrgID: 1, Name: "Deploys", MaxAge: "1y"},
+	)
+	err := Provision(context.Background(), correctConfig, retentionService)
+	require.NoError(t, err)
+
+	require.Len(t, retentionService.Policies, 3)
+	for _, policy := range retentionService.Policies {
+		assert.True(t, policy.Provisioned, policy.UID)
+	}
+
+	deploys, err := retentionService.GetPolicy(context.Background(), &annotationretention.GetPolicyQuery{UID: "deploys", OrgID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "2y", deploys.MaxAge)
+
+	ci, err := retentionService.GetPolicy(context.Background(), &annotationretention.GetPolicyQuery{UID: "ci", OrgID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, annotationretention.Tags{"ci", "build:main"}, ci.Tags)
+	assert.Equal(t, int64(1000), ci.MaxCount)
+
+	t.Run("provisioning again is a no-op", func(t *testing.T) {
+		err := Provision(context.Background(), correctConfig, retentionService)
+		require.NoError(t, err)
+		require.Len(t, retentionService.Policies, 3)
+	})
+}
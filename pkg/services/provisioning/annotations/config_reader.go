@@ -0,0 +1,87 @@
+package annotations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*annotationsAsConfig, error) {
+	var configs []*annotationsAsConfig
+	cr.log.Debug("Looking for annotation provisioning files", "path", path)
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Failed to read annotation provisioning files from directory", "path", path, "error", err)
+		return configs, nil
+	}
+
+	for _, file := range files {
+		if !strings.HasSuffix(file.Name(), ".yaml") && !strings.HasSuffix(file.Name(), ".yml") {
+			continue
+		}
+
+		cr.log.Debug("Parsing annotation provisioning file", "path", path, "file.Name", file.Name())
+		cfg, err := cr.parseConfig(filepath.Join(path, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, cfg)
+	}
+
+	if err := validateConfigs(configs); err != nil {
+		return nil, err
+	}
+
+	return configs, nil
+}
+
+func (cr *configReader) parseConfig(filename string) (*annotationsAsConfig, error) {
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg *annotationsAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	return cfg.mapToAnnotationsFromConfig(), nil
+}
+
+// validateConfigs checks the fields that cannot be defaulted and defaults the organization to the main one
+func validateConfigs(configs []*annotationsAsConfig) error {
+	for _, cfg := range configs {
+		for i, policy := range cfg.RetentionPolicies {
+			if policy.UID == "" {
+				return fmt.Errorf("retention policy %d in configuration doesn't contain required field uid", i+1)
+			}
+			if policy.OrgID < 1 {
+				policy.OrgID = 1
+			}
+		}
+
+		for i, policy := range cfg.DeleteRetentionPolicies {
+			if policy.UID == "" {
+				return fmt.Errorf("deleted retention policy %d in configuration doesn't contain required field uid", i+1)
+			}
+			if policy.OrgID < 1 {
+				policy.OrgID = 1
+			}
+		}
+	}
+
+	return nil
+}
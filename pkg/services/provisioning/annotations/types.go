@@ -0,0 +1,81 @@
+package annotations
+
+import "github.com/grafana/grafana/pkg/services/provisioning/values"
+
+// annotationsAsConfig is a normalized data object for annotations config data. Any config version should be mappable
+// to this type.
+type annotationsAsConfig struct {
+	RetentionPolicies       []*retentionPolicyFromConfig
+	DeleteRetentionPolicies []*deleteRetentionPolicyConfig
+}
+
+type retentionPolicyFromConfig struct {
+	OrgID        int64
+	UID          string
+	Name         string
+	Tags         []string
+	DashboardUID string
+	MaxAge       string
+	MaxCount     int64
+}
+
+type deleteRetentionPolicyConfig struct {
+	OrgID int64
+	UID   string
+}
+
+type retentionPolicyFromConfigV1 struct {
+	OrgID        values.Int64Value    `json:"orgId" yaml:"orgId"`
+	UID          values.StringValue   `json:"uid" yaml:"uid"`
+	Name         values.StringValue   `json:"name" yaml:"name"`
+	Tags         []values.StringValue `json:"tags" yaml:"tags"`
+	DashboardUID values.StringValue   `json:"dashboardUid" yaml:"dashboardUid"`
+	MaxAge       values.StringValue   `json:"maxAge" yaml:"maxAge"`
+	MaxCount     values.Int64Value    `json:"maxCount" yaml:"maxCount"`
+}
+
+type deleteRetentionPolicyConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+// annotationsAsConfigV1 is a mapping for the first version of the config. This is mapped to its normalised version.
+type annotationsAsConfigV1 struct {
+	RetentionPolicies       []*retentionPolicyFromConfigV1   `json:"retentionPolicies" yaml:"retentionPolicies"`
+	DeleteRetentionPolicies []*deleteRetentionPolicyConfigV1 `json:"deleteRetentionPolicies" yaml:"deleteRetentionPolicies"`
+}
+
+// mapToAnnotationsFromConfig maps config syntax to a normalized annotationsAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *annotationsAsConfigV1) mapToAnnotationsFromConfig() *annotationsAsConfig {
+	r := &annotationsAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, policy := range cfg.RetentionPolicies {
+		tags := make([]string, 0, len(policy.Tags))
+		for _, t := range policy.Tags {
+			tags = append(tags, t.Value())
+		}
+
+		r.RetentionPolicies = append(r.RetentionPolicies, &retentionPolicyFromConfig{
+			OrgID:        policy.OrgID.Value(),
+			UID:          policy.UID.Value(),
+			Name:         policy.Name.Value(),
+			Tags:         tags,
+			DashboardUID: policy.DashboardUID.Value(),
+			MaxAge:       policy.MaxAge.Value(),
+			MaxCount:     policy.MaxCount.Value(),
+		})
+	}
+
+	for _, policy := range cfg.DeleteRetentionPolicies {
+		r.DeleteRetentionPolicies = append(r.DeleteRetentionPolicies, &deleteRetentionPolicyConfig{
+			OrgID: policy.OrgID.Value(),
+			UID:   policy.UID.Value(),
+		})
+	}
+
+	return r
+}
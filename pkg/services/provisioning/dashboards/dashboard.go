@@ -6,6 +6,7 @@ import (
 	"os"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -21,10 +22,11 @@ type DashboardProvisioner interface {
 	GetProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 	CleanUpOrphanedDashboards(ctx context.Context)
+	WriteBackDashboard(ctx context.Context, cmd WriteBackCommand) error
 }
 
 // DashboardProvisionerFactory creates DashboardProvisioners based on input
-type DashboardProvisionerFactory func(context.Context, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service) (DashboardProvisioner, error)
+type DashboardProvisionerFactory func(context.Context, string, dashboards.DashboardProvisioningService, org.Service, utils.DashboardStore, folder.Service, dashboardlint.Service) (DashboardProvisioner, error)
 
 // Provisioner is responsible for syncing dashboard from disk to Grafana's database.
 type Provisioner struct {
@@ -40,7 +42,7 @@ func (provider *Provisioner) HasDashboardSources() bool {
 }
 
 // New returns a new DashboardProvisioner
-func New(ctx context.Context, configDirectory string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service) (DashboardProvisioner, error) {
+func New(ctx context.Context, configDirectory string, provisioner dashboards.DashboardProvisioningService, orgService org.Service, dashboardStore utils.DashboardStore, folderService folder.Service, linter dashboardlint.Service) (DashboardProvisioner, error) {
 	logger := log.New("provisioning.dashboard")
 	cfgReader := &configReader{path: configDirectory, log: logger, orgService: orgService}
 	configs, err := cfgReader.readConfig(ctx)
@@ -48,7 +50,7 @@ func New(ctx context.Context, configDirectory string, provisioner dashboards.Das
 		return nil, fmt.Errorf("%v: %w", "Failed to read dashboards config", err)
 	}
 
-	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService)
+	fileReaders, err := getFileReaders(configs, logger, provisioner, dashboardStore, folderService, linter)
 	if err != nil {
 		return nil, fmt.Errorf("%v: %w", "Failed to initialize file readers", err)
 	}
@@ -130,12 +132,24 @@ func (provider *Provisioner) GetAllowUIUpdatesFromConfig(name string) bool {
 	return false
 }
 
+// WriteBackDashboard queues a dashboard edited in the UI to be committed back to the source it was provisioned from.
+// Providers which do not support write-back are ignored.
+func (provider *Provisioner) WriteBackDashboard(ctx context.Context, cmd WriteBackCommand) error {
+	for _, reader := range provider.fileReaders {
+		if reader.Cfg.Name == cmd.ProvisionerName {
+			return reader.writeBack(ctx, cmd)
+		}
+	}
+	return nil
+}
+
 func getFileReaders(
 	configs []*config,
 	logger log.Logger,
 	service dashboards.DashboardProvisioningService,
 	store utils.DashboardStore,
 	folderService folder.Service,
+	linter dashboardlint.Service,
 ) ([]*FileReader, error) {
 	var readers []*FileReader
 
@@ -152,7 +166,21 @@ func getFileReaders(
 			if err != nil {
 				return nil, fmt.Errorf("failed to create file reader for config %v: %w", config.Name, err)
 			}
+			fileReader.linter = linter
 			readers = append(readers, fileReader)
+		case "git":
+			gitReader, err := NewDashboardGitReader(
+				config,
+				logger.New("type", config.Type, "name", config.Name),
+				service,
+				store,
+				folderService,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create git reader for config %v: %w", config.Name, err)
+			}
+			gitReader.linter = linter
+			readers = append(readers, gitReader)
 		default:
 			return nil, fmt.Errorf("type %s is not supported", config.Type)
 		}
@@ -8,6 +8,7 @@ type calls struct {
 	PollChanges                 []any
 	GetProvisionerResolvedPath  []any
 	GetAllowUIUpdatesFromConfig []any
+	WriteBackDashboard          []any
 }
 
 // ProvisionerMock is a mock implementation of `Provisioner`
@@ -17,6 +18,7 @@ type ProvisionerMock struct {
 	PollChangesFunc                 func(ctx context.Context)
 	GetProvisionerResolvedPathFunc  func(name string) string
 	GetAllowUIUpdatesFromConfigFunc func(name string) bool
+	WriteBackDashboardFunc          func(ctx context.Context, cmd WriteBackCommand) error
 }
 
 // NewDashboardProvisionerMock returns a new dashboardprovisionermock
@@ -67,3 +69,12 @@ func (dpm *ProvisionerMock) GetAllowUIUpdatesFromConfig(name string) bool {
 
 // CleanUpOrphanedDashboards not implemented for mocks
 func (dpm *ProvisionerMock) CleanUpOrphanedDashboards(ctx context.Context) {}
+
+// WriteBackDashboard is a mock implementation of `Provisioner.WriteBackDashboard`
+func (dpm *ProvisionerMock) WriteBackDashboard(ctx context.Context, cmd WriteBackCommand) error {
+	dpm.Calls.WriteBackDashboard = append(dpm.Calls.WriteBackDashboard, cmd)
+	if dpm.WriteBackDashboardFunc != nil {
+		return dpm.WriteBackDashboardFunc(ctx, cmd)
+	}
+	return nil
+}
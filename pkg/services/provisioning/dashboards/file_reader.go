@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/provisioning/utils"
@@ -37,6 +38,10 @@ type FileReader struct {
 	dashboardStore               utils.DashboardStore
 	FoldersFromFilesStructure    bool
 	folderService                folder.Service
+	// git is set when dashboards are provisioned from a git repository checkout.
+	git *gitSource
+	// linter checks dashboards before they are saved when the provider rejects invalid dashboards.
+	linter dashboardlint.Service
 
 	mux                     sync.RWMutex
 	usageTracker            *usageTracker
@@ -92,6 +97,20 @@ func (fr *FileReader) pollChanges(ctx context.Context) {
 // walkDisk traverses the file system for the defined path, reading dashboard definition files,
 // and applies any change to the database.
 func (fr *FileReader) walkDisk(ctx context.Context) error {
+	var commit string
+	if fr.git != nil {
+		var changed bool
+		var err error
+		commit, changed, err = fr.git.sync(ctx)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			fr.log.Debug("No new commits in dashboards repository", "commit", commit)
+			return nil
+		}
+	}
+
 	fr.log.Debug("Start walking disk", "path", fr.Path)
 	resolvedPath := fr.resolvedPath()
 	if _, err := os.Stat(resolvedPath); err != nil {
@@ -121,6 +140,10 @@ func (fr *FileReader) walkDisk(ctx context.Context) error {
 		return err
 	}
 
+	if fr.git != nil {
+		fr.git.setProvisioned(commit)
+	}
+
 	fr.mux.Lock()
 	defer fr.mux.Unlock()
 
@@ -284,6 +307,17 @@ func (fr *FileReader) saveDashboard(ctx context.Context, path string, folderID i
 		return provisioningMetadata, nil
 	}
 
+	if fr.Cfg.RejectInvalid && fr.linter != nil {
+		report, err := fr.linter.Lint(ctx, fr.Cfg.OrgID, dash.Dashboard.Data)
+		if err != nil {
+			return provisioningMetadata, fmt.Errorf("failed to lint dashboard %s: %w", path, err)
+		}
+		if report.HasErrors() {
+			fr.log.Error("Not saving invalid dashboard", "provisioner", fr.Cfg.Name, "file", path, "errors", report.Errors, "findings", report.Findings)
+			return provisioningMetadata, nil
+		}
+	}
+
 	if dash.Dashboard.ID != 0 {
 		dash.Dashboard.Data.Set("id", nil)
 		dash.Dashboard.ID = 0
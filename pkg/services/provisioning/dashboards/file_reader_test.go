@@ -13,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
+	"github.com/grafana/grafana/pkg/services/dashboardlint/dashboardlinttest"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/util"
@@ -147,6 +149,33 @@ func TestDashboardFileReader(t *testing.T) {
 			assert.Equal(t, inserted, 1)
 		})
 
+		t.Run("Invalid dashboard is not saved when the provider rejects invalid dashboards", func(t *testing.T) {
+			setup()
+			cfg.Options["path"] = oneDashboard
+			cfg.RejectInvalid = true
+
+			linter := dashboardlinttest.NewFakeService()
+			linter.ExpectedReport = &dashboardlint.Report{
+				Findings: []dashboardlint.Finding{{Rule: dashboardlint.RuleDuplicatePanelID, Severity: dashboardlint.SeverityError, Message: "duplicate"}},
+				Errors:   1,
+			}
+			fakeService.On("GetProvisionedDashboardData", mock.Anything, configName).Return(nil, nil).Once()
+
+			reader, err := NewDashboardFileReader(cfg, logger, nil, fakeStore, nil)
+			reader.dashboardProvisioningService = fakeService
+			reader.linter = linter
+			require.NoError(t, err)
+
+			calls := len(fakeService.Calls)
+			err = reader.walkDisk(context.Background())
+			require.NoError(t, err)
+
+			assert.Equal(t, []string{"Grafana"}, linter.LintedTitles)
+			for _, call := range fakeService.Calls[calls:] {
+				assert.NotEqual(t, "SaveProvisionedDashboard", call.Method)
+			}
+		})
+
 		t.Run("Dashboard with older timestamp and the same checksum will not replace imported dashboard", func(t *testing.T) {
 			setup()
 			cfg.Options["path"] = oneDashboard
@@ -0,0 +1,417 @@
+package dashboards
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+)
+
+// WriteBackCommand describes a provisioned dashboard which was edited in the UI
+// and should be committed back to its provisioning source.
+type WriteBackCommand struct {
+	ProvisionerName string
+	ExternalID      string
+	Dashboard       *simplejson.Json
+	AuthorName      string
+	AuthorEmail     string
+	Message         string
+}
+
+const (
+	// writeBackQueueSize is the number of dashboard edits which can wait to be pushed.
+	writeBackQueueSize = 100
+	// writeBackTimeout limits the time a single write-back can take, pushes included.
+	writeBackTimeout = 2 * time.Minute
+)
+
+// gitCredentialHelper is a git credential helper which answers with the credentials
+// passed in the environment so that they never appear in arguments nor in the git config.
+const gitCredentialHelper = `!f() { test "$1" = get && echo "username=${GRAFANA_GIT_USERNAME}" && echo "password=${GRAFANA_GIT_PASSWORD}"; }; f`
+
+// writeBackJob is a dashboard edit waiting to be committed and pushed.
+type writeBackJob struct {
+	externalID  string
+	content     []byte
+	authorName  string
+	authorEmail string
+	message     string
+}
+
+// gitSource keeps a local checkout of a git repository which is used as a dashboard
+// provisioning source. All git operations are done through the git binary.
+type gitSource struct {
+	url             string
+	branch          string
+	subPath         string
+	checkoutPath    string
+	username        string
+	password        string
+	sshKeyPath      string
+	writeBack       bool
+	writeBackBranch string
+	log             log.Logger
+
+	mu sync.Mutex
+	// provisionedCommit is the last commit dashboards were successfully provisioned from.
+	provisionedCommit string
+
+	writeBacks      chan writeBackJob
+	startWriteBacks sync.Once
+}
+
+func newGitSource(cfg *config, log log.Logger) (*gitSource, error) {
+	repoURL, ok := cfg.Options["url"].(string)
+	if !ok || repoURL == "" {
+		return nil, fmt.Errorf("failed to load dashboards, url param is not a string")
+	}
+
+	source := &gitSource{
+		url:    repoURL,
+		branch: "main",
+		log:    log,
+	}
+	if branch, ok := cfg.Options["branch"].(string); ok && branch != "" {
+		source.branch = branch
+	}
+	if subPath, ok := cfg.Options["path"].(string); ok {
+		source.subPath = filepath.Clean(subPath)
+		if filepath.IsAbs(source.subPath) || strings.HasPrefix(source.subPath, "..") {
+			return nil, fmt.Errorf("path %q must be relative to the repository root", subPath)
+		}
+	}
+	source.username, _ = cfg.Options["username"].(string)
+	source.password, _ = cfg.Options["password"].(string)
+	source.sshKeyPath, _ = cfg.Options["sshKeyPath"].(string)
+	if source.username != "" || source.password != "" {
+		u, err := url.Parse(repoURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository url: %w", err)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("username and password are only supported for http(s) repositories")
+		}
+	}
+
+	source.checkoutPath, _ = cfg.Options["checkoutPath"].(string)
+	if source.checkoutPath == "" {
+		source.checkoutPath = filepath.Join(os.TempDir(), "grafana-provisioning-git", fmt.Sprintf("%d-%s", cfg.OrgID, cfg.Name))
+	}
+
+	source.writeBack, _ = cfg.Options["writeBack"].(bool)
+	source.writeBackBranch = source.branch
+	if branch, ok := cfg.Options["writeBackBranch"].(string); ok && branch != "" {
+		source.writeBackBranch = branch
+	}
+	if source.writeBack && !cfg.AllowUIUpdates {
+		return nil, fmt.Errorf("'writeBack' requires 'allowUiUpdates' to be enabled")
+	}
+	if source.writeBack {
+		source.writeBacks = make(chan writeBackJob, writeBackQueueSize)
+	}
+
+	return source, nil
+}
+
+// NewDashboardGitReader returns a new file reader which provisions dashboards from a
+// checkout of the git repository configured in `config`.
+func NewDashboardGitReader(cfg *config, log log.Logger, service dashboards.DashboardProvisioningService,
+	dashboardStore utils.DashboardStore, folderService folder.Service) (*FileReader, error) {
+	source, err := newGitSource(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	foldersFromFilesStructure, _ := cfg.Options["foldersFromFilesStructure"].(bool)
+	if foldersFromFilesStructure && cfg.Folder != "" && cfg.FolderUID != "" {
+		return nil, fmt.Errorf("'folder' and 'folderUID' should be empty using 'foldersFromFilesStructure' option")
+	}
+
+	return &FileReader{
+		Cfg:                          cfg,
+		Path:                         source.path(),
+		log:                          log,
+		dashboardProvisioningService: service,
+		dashboardStore:               dashboardStore,
+		folderService:                folderService,
+		FoldersFromFilesStructure:    foldersFromFilesStructure,
+		usageTracker:                 newUsageTracker(),
+		git:                          source,
+	}, nil
+}
+
+// path returns the directory dashboards are read from.
+func (g *gitSource) path() string {
+	return filepath.Join(g.checkoutPath, g.subPath)
+}
+
+// sync fetches the configured branch and checks out its latest commit. It returns the
+// commit and whether it differs from the last provisioned one.
+func (g *gitSource) sync(ctx context.Context) (string, bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.ensureCheckout(ctx); err != nil {
+		return "", false, err
+	}
+	if _, err := g.run(ctx, g.checkoutPath, nil, "fetch", "--quiet", "origin", g.branch); err != nil {
+		return "", false, err
+	}
+	commit, err := g.run(ctx, g.checkoutPath, nil, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", false, err
+	}
+	if commit == g.provisionedCommit {
+		return commit, false, nil
+	}
+
+	g.log.Debug("Checking out new commit", "url", g.redact(g.url), "branch", g.branch, "commit", commit)
+	if _, err := g.run(ctx, g.checkoutPath, nil, "reset", "--quiet", "--hard", commit); err != nil {
+		return "", false, err
+	}
+	if _, err := g.run(ctx, g.checkoutPath, nil, "clean", "--quiet", "-fd"); err != nil {
+		return "", false, err
+	}
+	return commit, true, nil
+}
+
+// setProvisioned records the commit dashboards were provisioned from.
+func (g *gitSource) setProvisioned(commit string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.provisionedCommit = commit
+}
+
+func (g *gitSource) ensureCheckout(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(g.checkoutPath, ".git")); err == nil {
+		// Setting the url also removes credentials stored in the url by earlier versions.
+		_, err := g.run(ctx, g.checkoutPath, nil, "remote", "set-url", "origin", g.url)
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(g.checkoutPath), 0o750); err != nil {
+		return err
+	}
+	g.log.Info("Cloning dashboards repository", "url", g.redact(g.url), "branch", g.branch, "path", g.checkoutPath)
+	_, err := g.run(ctx, "", nil, "clone", "--quiet", "--single-branch", "--branch", g.branch, g.url, g.checkoutPath)
+	return err
+}
+
+// queueCommit queues a dashboard edit to be committed and pushed in the background, so
+// that saving a dashboard does not wait for the git remote.
+func (g *gitSource) queueCommit(job writeBackJob) error {
+	g.startWriteBacks.Do(func() {
+		go g.processWriteBacks()
+	})
+	select {
+	case g.writeBacks <- job:
+		return nil
+	default:
+		return errors.New("too many dashboard changes waiting to be committed")
+	}
+}
+
+func (g *gitSource) processWriteBacks() {
+	for job := range g.writeBacks {
+		ctx, cancel := context.WithTimeout(context.Background(), writeBackTimeout)
+		if err := g.commit(ctx, job.externalID, job.content, job.authorName, job.authorEmail, job.message); err != nil {
+			g.log.Error("Failed to write back provisioned dashboard", "file", job.externalID, "error", err)
+		}
+		cancel()
+	}
+}
+
+// commit writes the file at externalID with the provided content on top of the
+// write-back branch, commits it with the given author and pushes it.
+func (g *gitSource) commit(ctx context.Context, externalID string, content []byte, authorName, authorEmail, message string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	checkoutPath, err := filepath.EvalSymlinks(g.checkoutPath)
+	if err != nil {
+		return err
+	}
+	relPath, err := filepath.Rel(checkoutPath, externalID)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(relPath, "..") {
+		return fmt.Errorf("dashboard file %q is outside of the repository checkout", externalID)
+	}
+
+	base := g.provisionedCommit
+	if _, err := g.run(ctx, g.checkoutPath, nil, "fetch", "--quiet", "origin", g.writeBackBranch); err == nil {
+		if base, err = g.run(ctx, g.checkoutPath, nil, "rev-parse", "FETCH_HEAD"); err != nil {
+			return err
+		}
+	} else if base == "" {
+		return err
+	}
+
+	worktree, err := os.MkdirTemp("", "grafana-git-write-back")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if _, err := g.run(context.Background(), g.checkoutPath, nil, "worktree", "remove", "--force", worktree); err != nil {
+			g.log.Warn("Failed to remove write-back worktree", "path", worktree, "error", err)
+		}
+		_ = os.RemoveAll(worktree)
+	}()
+	if _, err := g.run(ctx, g.checkoutPath, nil, "worktree", "add", "--quiet", "--detach", worktree, base); err != nil {
+		return err
+	}
+
+	filename := filepath.Join(worktree, relPath)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o750); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filename, content, 0o600); err != nil {
+		return err
+	}
+	if _, err := g.run(ctx, worktree, nil, "add", "--", relPath); err != nil {
+		return err
+	}
+	if _, err := g.run(ctx, worktree, nil, "diff", "--cached", "--quiet"); err == nil {
+		g.log.Debug("Dashboard file is unchanged, nothing to commit", "file", relPath)
+		return nil
+	}
+
+	env := []string{
+		"GIT_AUTHOR_NAME=" + authorName,
+		"GIT_AUTHOR_EMAIL=" + authorEmail,
+		"GIT_COMMITTER_NAME=" + authorName,
+		"GIT_COMMITTER_EMAIL=" + authorEmail,
+	}
+	if _, err := g.run(ctx, worktree, env, "commit", "--quiet", "-m", message); err != nil {
+		return err
+	}
+	if _, err := g.run(ctx, worktree, nil, "push", "--quiet", "origin", "HEAD:refs/heads/"+g.writeBackBranch); err != nil {
+		return err
+	}
+
+	// The edit is already stored in the database, so there is no need to provision
+	// the commit we have just pushed to the provisioning branch.
+	if g.writeBackBranch == g.branch && base == g.provisionedCommit {
+		commit, err := g.run(ctx, worktree, nil, "rev-parse", "HEAD")
+		if err != nil {
+			return err
+		}
+		// Keep the main checkout on the pushed commit, the next sync then has nothing to do.
+		if _, err := g.run(ctx, g.checkoutPath, nil, "reset", "--quiet", "--hard", commit); err != nil {
+			return err
+		}
+		g.provisionedCommit = commit
+	}
+	g.log.Info("Committed dashboard changes", "file", relPath, "branch", g.writeBackBranch, "author", authorName)
+	return nil
+}
+
+// env returns the environment git is run with. Credentials are passed to git through
+// a credential helper configured in the environment.
+func (g *gitSource) env() []string {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if g.sshKeyPath != "" {
+		env = append(env, "GIT_SSH_COMMAND=ssh -i "+shellQuote(g.sshKeyPath)+" -o IdentitiesOnly=yes")
+	}
+	if g.username != "" || g.password != "" {
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=credential.helper",
+			"GIT_CONFIG_VALUE_0="+gitCredentialHelper,
+			"GRAFANA_GIT_USERNAME="+g.username,
+			"GRAFANA_GIT_PASSWORD="+g.password,
+		)
+	}
+	return env
+}
+
+// shellQuote quotes s for sh, GIT_SSH_COMMAND is run by the shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// run executes git with the provided arguments and returns its trimmed output.
+func (g *gitSource) run(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	// nolint:gosec
+	// We can ignore the gosec G204 warning on this one because the arguments come from the provisioning configuration file.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(g.env(), env...)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s failed: %s", args[0], g.redact(strings.TrimSpace(stderr.String())))
+		}
+		return "", fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// redact removes credentials from git output, in their raw and url encoded forms.
+func (g *gitSource) redact(s string) string {
+	if g.password == "" {
+		return s
+	}
+	for _, secret := range []string{g.password, url.QueryEscape(g.password), url.PathEscape(g.password), url.UserPassword("", g.password).String()[1:]} {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, "*****")
+		}
+	}
+	return s
+}
+
+// writeBack queues the dashboard to be committed to the repository it was provisioned from.
+func (fr *FileReader) writeBack(_ context.Context, cmd WriteBackCommand) error {
+	if fr.git == nil || !fr.git.writeBack {
+		return nil
+	}
+
+	data, err := cmd.Dashboard.Map()
+	if err != nil {
+		return err
+	}
+	dashboardJSON := simplejson.New()
+	for key, value := range data {
+		if key != "id" {
+			dashboardJSON.Set(key, value)
+		}
+	}
+
+	content, err := json.MarshalIndent(dashboardJSON.Interface(), "", "  ")
+	if err != nil {
+		return err
+	}
+	content = append(content, '\n')
+
+	message := cmd.Message
+	if message == "" {
+		message = fmt.Sprintf("Update dashboard %q", dashboardJSON.Get("title").MustString())
+	}
+	return fr.git.queueCommit(writeBackJob{
+		externalID:  cmd.ExternalID,
+		content:     content,
+		authorName:  cmd.AuthorName,
+		authorEmail: cmd.AuthorEmail,
+		message:     message,
+	})
+}
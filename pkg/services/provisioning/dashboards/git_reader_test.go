@@ -0,0 +1,183 @@
+package dashboards
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+)
+
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// setupGitRepository creates a bare repository with a single dashboard in the dashboards directory
+// and returns the path of the bare repository and of a working copy to push new commits from.
+func setupGitRepository(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary is not available")
+	}
+
+	bare := filepath.Join(t.TempDir(), "dashboards.git")
+	runGit(t, "", "init", "--quiet", "--bare", bare)
+
+	work := t.TempDir()
+	runGit(t, work, "init", "--quiet")
+	runGit(t, work, "checkout", "--quiet", "-b", "main")
+	require.NoError(t, os.MkdirAll(filepath.Join(work, "dashboards"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(work, "dashboards", "dashboard.json"), []byte(`{"uid": "git-dash", "title": "Git dashboard"}`), 0o600))
+	runGit(t, work, "add", ".")
+	runGit(t, work, "commit", "--quiet", "-m", "Add dashboard")
+	runGit(t, work, "remote", "add", "origin", bare)
+	runGit(t, work, "push", "--quiet", "origin", "main")
+
+	return bare, work
+}
+
+func TestDashboardGitReader(t *testing.T) {
+	bare, work := setupGitRepository(t)
+	checkoutPath := filepath.Join(t.TempDir(), "checkout")
+
+	cfg := &config{
+		Name:           "git",
+		Type:           "git",
+		OrgID:          1,
+		AllowUIUpdates: true,
+		Options: map[string]any{
+			"url":          bare,
+			"branch":       "main",
+			"path":         "dashboards",
+			"checkoutPath": checkoutPath,
+			"writeBack":    true,
+		},
+	}
+
+	fakeService := &dashboards.FakeDashboardProvisioning{}
+	defer fakeService.AssertExpectations(t)
+
+	reader, err := NewDashboardGitReader(cfg, log.New("test-logger"), fakeService, &fakeDashboardStore{}, nil)
+	require.NoError(t, err)
+
+	t.Run("Provisions dashboards from the repository", func(t *testing.T) {
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return(nil, nil).Once()
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 1}, nil).Once()
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+		require.FileExists(t, filepath.Join(checkoutPath, "dashboards", "dashboard.json"))
+	})
+
+	t.Run("Skips provisioning without new commits", func(t *testing.T) {
+		require.NoError(t, reader.walkDisk(context.Background()))
+	})
+
+	t.Run("Provisions new commits", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(work, "dashboards", "other.json"), []byte(`{"uid": "other", "title": "Other dashboard"}`), 0o600))
+		runGit(t, work, "add", ".")
+		runGit(t, work, "commit", "--quiet", "-m", "Add other dashboard")
+		runGit(t, work, "push", "--quiet", "origin", "main")
+
+		fakeService.On("GetProvisionedDashboardData", mock.Anything, "git").Return(nil, nil).Once()
+		fakeService.On("SaveProvisionedDashboard", mock.Anything, mock.Anything, mock.Anything).Return(&dashboards.Dashboard{ID: 2}, nil).Twice()
+
+		require.NoError(t, reader.walkDisk(context.Background()))
+	})
+
+	t.Run("Commits UI edits back to the repository", func(t *testing.T) {
+		resolvedCheckout, err := filepath.EvalSymlinks(checkoutPath)
+		require.NoError(t, err)
+
+		dash := simplejson.New()
+		dash.Set("id", 1)
+		dash.Set("uid", "git-dash")
+		dash.Set("title", "Edited in Grafana")
+
+		err = reader.writeBack(context.Background(), WriteBackCommand{
+			ProvisionerName: "git",
+			ExternalID:      filepath.Join(resolvedCheckout, "dashboards", "dashboard.json"),
+			Dashboard:       dash,
+			AuthorName:      "Jane Doe",
+			AuthorEmail:     "jane@example.com",
+			Message:         "Edit title",
+		})
+		require.NoError(t, err)
+
+		// Changes are pushed in the background.
+		require.Eventually(t, func() bool {
+			return runGit(t, bare, "log", "-1", "--format=%s", "main") == "Edit title"
+		}, 10*time.Second, 10*time.Millisecond)
+		require.Equal(t, "Jane Doe <jane@example.com>", runGit(t, bare, "log", "-1", "--format=%an <%ae>", "main"))
+		require.Equal(t, "Edit title", runGit(t, bare, "log", "-1", "--format=%s", "main"))
+		shown := runGit(t, bare, "show", "main:dashboards/dashboard.json")
+		require.Contains(t, shown, "Edited in Grafana")
+		require.NotContains(t, shown, `"id"`)
+		var content []byte
+
+		// The pushed commit already matches the database, so it is not provisioned again.
+		require.Eventually(t, func() bool {
+			return runGit(t, checkoutPath, "rev-parse", "HEAD") == runGit(t, bare, "rev-parse", "main")
+		}, 10*time.Second, 10*time.Millisecond)
+		require.NoError(t, reader.walkDisk(context.Background()))
+		content, err = os.ReadFile(filepath.Join(checkoutPath, "dashboards", "dashboard.json"))
+		require.NoError(t, err)
+		require.Contains(t, string(content), "Edited in Grafana")
+	})
+}
+
+func TestNewDashboardGitReader(t *testing.T) {
+	t.Run("requires url", func(t *testing.T) {
+		_, err := NewDashboardGitReader(&config{Name: "git", Options: map[string]any{}}, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("requires relative path", func(t *testing.T) {
+		_, err := NewDashboardGitReader(&config{Name: "git", Options: map[string]any{"url": "https://example.com/repo.git", "path": "../outside"}}, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("write back requires ui updates", func(t *testing.T) {
+		_, err := NewDashboardGitReader(&config{Name: "git", Options: map[string]any{"url": "https://example.com/repo.git", "writeBack": true}}, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("credentials require http urls", func(t *testing.T) {
+		_, err := NewDashboardGitReader(&config{Name: "git", Options: map[string]any{"url": "git@example.com:repo.git", "username": "user", "password": "secret"}}, log.New("test-logger"), nil, nil, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("credentials are passed through the environment", func(t *testing.T) {
+		reader, err := NewDashboardGitReader(&config{Name: "git", Options: map[string]any{"url": "https://example.com/repo.git", "username": "user", "password": "s3cr:t/"}}, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		env := reader.git.env()
+		require.Contains(t, env, "GIT_CONFIG_KEY_0=credential.helper")
+		require.Contains(t, env, "GRAFANA_GIT_USERNAME=user")
+		require.Contains(t, env, "GRAFANA_GIT_PASSWORD=s3cr:t/")
+		require.Equal(t, "token ***** *****", reader.git.redact("token s3cr:t/ s3cr%3At%2F"))
+	})
+
+	t.Run("ssh key path is quoted", func(t *testing.T) {
+		reader, err := NewDashboardGitReader(&config{Name: "git", Options: map[string]any{"url": "git@example.com:repo.git", "sshKeyPath": "/keys/it's key"}}, log.New("test-logger"), nil, nil, nil)
+		require.NoError(t, err)
+		require.Contains(t, reader.git.env(), `GIT_SSH_COMMAND=ssh -i '/keys/it'\''s key' -o IdentitiesOnly=yes`)
+	})
+}
@@ -21,6 +21,8 @@ type config struct {
 	DisableDeletion       bool
 	UpdateIntervalSeconds int64
 	AllowUIUpdates        bool
+	// RejectInvalid skips dashboards with error-level lint findings instead of saving them.
+	RejectInvalid bool
 }
 
 type configV0 struct {
@@ -55,6 +57,7 @@ type configs struct {
 	DisableDeletion       values.BoolValue   `json:"disableDeletion" yaml:"disableDeletion"`
 	UpdateIntervalSeconds values.Int64Value  `json:"updateIntervalSeconds" yaml:"updateIntervalSeconds"`
 	AllowUIUpdates        values.BoolValue   `json:"allowUiUpdates" yaml:"allowUiUpdates"`
+	RejectInvalid         values.BoolValue   `json:"rejectInvalid" yaml:"rejectInvalid"`
 }
 
 func createDashboardJSON(data *simplejson.Json, lastModified time.Time, cfg *config, folderID int64, folderUID string) (*dashboards.SaveDashboardDTO, error) {
@@ -124,6 +127,7 @@ func (dc *configV1) mapToDashboardsAsConfig() ([]*config, error) {
 			DisableDeletion:       v.DisableDeletion.Value(),
 			UpdateIntervalSeconds: v.UpdateIntervalSeconds.Value(),
 			AllowUIUpdates:        v.AllowUIUpdates.Value(),
+			RejectInvalid:         v.RejectInvalid.Value(),
 		})
 	}
 
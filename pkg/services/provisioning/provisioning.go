@@ -10,7 +10,9 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations/annotationretention"
 	"github.com/grafana/grafana/pkg/services/correlations"
+	"github.com/grafana/grafana/pkg/services/dashboardlint"
 	dashboardservice "github.com/grafana/grafana/pkg/services/dashboards"
 	datasourceservice "github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/encryption"
@@ -24,6 +26,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	prov_alerting "github.com/grafana/grafana/pkg/services/provisioning/alerting"
+	prov_annotations "github.com/grafana/grafana/pkg/services/provisioning/annotations"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/plugins"
@@ -50,6 +53,8 @@ func ProvideService(
 	quotaService quota.Service,
 	secrectService secrets.Service,
 	orgService org.Service,
+	dashboardLintService dashboardlint.Service,
+	annotationRetentionService annotationretention.Service,
 ) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                          cfg,
@@ -62,6 +67,7 @@ func ProvideService(
 		provisionDatasources:         datasources.Provision,
 		provisionPlugins:             plugins.Provision,
 		provisionAlerting:            prov_alerting.Provision,
+		provisionAnnotations:         prov_annotations.Provision,
 		dashboardProvisioningService: dashboardProvisioningService,
 		dashboardService:             dashboardService,
 		datasourceService:            datasourceService,
@@ -73,6 +79,8 @@ func ProvideService(
 		log:                          log.New("provisioning"),
 		orgService:                   orgService,
 		folderService:                folderService,
+		dashboardLintService:         dashboardLintService,
+		annotationRetentionService:   annotationRetentionService,
 	}
 
 	err := s.setDashboardProvisioner()
@@ -85,7 +93,7 @@ func ProvideService(
 
 func (ps *ProvisioningServiceImpl) setDashboardProvisioner() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
-	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService)
+	dashProvisioner, err := ps.newDashboardProvisioner(context.Background(), dashboardPath, ps.dashboardProvisioningService, ps.orgService, ps.dashboardService, ps.folderService, ps.dashboardLintService)
 	if err != nil {
 		return fmt.Errorf("%v: %w", "Failed to create provisioner", err)
 	}
@@ -100,8 +108,10 @@ type ProvisioningService interface {
 	ProvisionPlugins(ctx context.Context) error
 	ProvisionDashboards(ctx context.Context) error
 	ProvisionAlerting(ctx context.Context) error
+	ProvisionAnnotations(ctx context.Context) error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
+	WriteBackDashboard(ctx context.Context, cmd dashboards.WriteBackCommand) error
 }
 
 // Add a public constructor for overriding service to be able to instantiate OSS as fallback
@@ -112,6 +122,7 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		newDashboardProvisioner: dashboards.New,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAnnotations:    prov_annotations.Provision,
 	}
 }
 
@@ -145,6 +156,7 @@ type ProvisioningServiceImpl struct {
 	provisionDatasources         func(context.Context, string, datasources.Store, datasources.CorrelationsStore, org.Service) error
 	provisionPlugins             func(context.Context, string, pluginstore.Store, pluginsettings.Service, org.Service) error
 	provisionAlerting            func(context.Context, prov_alerting.ProvisionerConfig) error
+	provisionAnnotations         func(context.Context, string, annotationretention.Service) error
 	mutex                        sync.Mutex
 	dashboardProvisioningService dashboardservice.DashboardProvisioningService
 	dashboardService             dashboardservice.DashboardService
@@ -155,6 +167,8 @@ type ProvisioningServiceImpl struct {
 	quotaService                 quota.Service
 	secretService                secrets.Service
 	folderService                folder.Service
+	dashboardLintService         dashboardlint.Service
+	annotationRetentionService   annotationretention.Service
 }
 
 func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) error {
@@ -176,6 +190,12 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners(ctx context.Context) erro
 		return err
 	}
 
+	err = ps.ProvisionAnnotations(ctx)
+	if err != nil {
+		ps.log.Error("Failed to provision annotations", "error", err)
+		return err
+	}
+
 	return nil
 }
 
@@ -231,6 +251,16 @@ func (ps *ProvisioningServiceImpl) ProvisionPlugins(ctx context.Context) error {
 	return nil
 }
 
+func (ps *ProvisioningServiceImpl) ProvisionAnnotations(ctx context.Context) error {
+	annotationsPath := filepath.Join(ps.Cfg.ProvisioningPath, "annotations")
+	if err := ps.provisionAnnotations(ctx, annotationsPath, ps.annotationRetentionService); err != nil {
+		err = fmt.Errorf("%v: %w", "annotation provisioning error", err)
+		ps.log.Error("Failed to provision annotations", "error", err)
+		return err
+	}
+	return nil
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards(ctx context.Context) error {
 	ps.mutex.Lock()
 	defer ps.mutex.Unlock()
@@ -298,6 +328,10 @@ func (ps *ProvisioningServiceImpl) GetAllowUIUpdatesFromConfig(name string) bool
 	return ps.dashboardProvisioner.GetAllowUIUpdatesFromConfig(name)
 }
 
+func (ps *ProvisioningServiceImpl) WriteBackDashboard(ctx context.Context, cmd dashboards.WriteBackCommand) error {
+	return ps.dashboardProvisioner.WriteBackDashboard(ctx, cmd)
+}
+
 func (ps *ProvisioningServiceImpl) cancelPolling() {
 	if ps.pollingCtxCancel != nil {
 		ps.log.Debug("Stop polling for dashboard changes")
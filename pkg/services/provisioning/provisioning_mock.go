@@ -1,6 +1,10 @@
 package provisioning
 
-import "context"
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
+)
 
 type Calls struct {
 	RunInitProvisioners                 []any
@@ -8,8 +12,10 @@ type Calls struct {
 	ProvisionPlugins                    []any
 	ProvisionDashboards                 []any
 	ProvisionAlerting                   []any
+	ProvisionAnnotations                []any
 	GetDashboardProvisionerResolvedPath []any
 	GetAllowUIUpdatesFromConfig         []any
+	WriteBackDashboard                  []any
 	Run                                 []any
 }
 
@@ -21,6 +27,7 @@ type ProvisioningServiceMock struct {
 	ProvisionDashboardsFunc                 func() error
 	GetDashboardProvisionerResolvedPathFunc func(name string) string
 	GetAllowUIUpdatesFromConfigFunc         func(name string) bool
+	WriteBackDashboardFunc                  func(ctx context.Context, cmd dashboards.WriteBackCommand) error
 	RunFunc                                 func(ctx context.Context) error
 }
 
@@ -67,6 +74,11 @@ func (mock *ProvisioningServiceMock) ProvisionAlerting(ctx context.Context) erro
 	return nil
 }
 
+func (mock *ProvisioningServiceMock) ProvisionAnnotations(ctx context.Context) error {
+	mock.Calls.ProvisionAnnotations = append(mock.Calls.ProvisionAnnotations, nil)
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) GetDashboardProvisionerResolvedPath(name string) string {
 	mock.Calls.GetDashboardProvisionerResolvedPath = append(mock.Calls.GetDashboardProvisionerResolvedPath, name)
 	if mock.GetDashboardProvisionerResolvedPathFunc != nil {
@@ -83,6 +95,14 @@ func (mock *ProvisioningServiceMock) GetAllowUIUpdatesFromConfig(name string) bo
 	return false
 }
 
+func (mock *ProvisioningServiceMock) WriteBackDashboard(ctx context.Context, cmd dashboards.WriteBackCommand) error {
+	mock.Calls.WriteBackDashboard = append(mock.Calls.WriteBackDashboard, cmd)
+	if mock.WriteBackDashboardFunc != nil {
+		return mock.WriteBackDashboardFunc(ctx, cmd)
+	}
+	return nil
+}
+
 func (mock *ProvisioningServiceMock) Run(ctx context.Context) error {
 	mock.Calls.Run = append(mock.Calls.Run, nil)
 	if mock.RunFunc != nil {
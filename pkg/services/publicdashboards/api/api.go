@@ -73,9 +73,9 @@ func (api *Api) RegisterAPIEndpoints() {
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
 	api.routeRegister.Group("/api/public/dashboards/:accessToken", func(apiRoute routing.RouteRegister) {
-		apiRoute.Get("/", routing.Wrap(api.ViewPublicDashboard))
-		apiRoute.Get("/annotations", routing.Wrap(api.GetPublicAnnotations))
-		apiRoute.Post("/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+		apiRoute.Get("/", RequiresAccessAllowed(api.PublicDashboardService, api.cfg.TrustedProxies, AccessActionView), routing.Wrap(api.ViewPublicDashboard))
+		apiRoute.Get("/annotations", RequiresAccessAllowed(api.PublicDashboardService, api.cfg.TrustedProxies, AccessActionAnnotations), routing.Wrap(api.GetPublicAnnotations))
+		apiRoute.Post("/panels/:panelId/query", RequiresAccessAllowed(api.PublicDashboardService, api.cfg.TrustedProxies, AccessActionQuery), routing.Wrap(api.QueryPublicDashboard))
 	}, api.Middleware.HandleApi)
 
 	// Auth endpoints
@@ -103,6 +103,11 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// Get Public dashboard access log
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-log",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.GetPublicDashboardAccessLog))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboard_public listPublicDashboards
@@ -300,6 +305,60 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, nil)
 }
 
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/access-log dashboard_public getPublicDashboardAccessLog
+//
+//	Get the access log of a public dashboard, most recent requests first
+//
+// Responses:
+// 200: getPublicDashboardAccessLogResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) GetPublicDashboardAccessLog(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardAccessLog: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("GetPublicDashboardAccessLog: invalid Uid %s", uid))
+	}
+
+	pd, err := api.PublicDashboardService.Find(c.Req.Context(), uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if pd == nil || pd.OrgId != c.SignedInUser.GetOrgID() || pd.DashboardUid != dashboardUid {
+		return response.Err(ErrPublicDashboardNotFound.Errorf("GetPublicDashboardAccessLog: public dashboard not found by uid: %s", uid))
+	}
+
+	perPage := c.QueryInt("perpage")
+	if perPage <= 0 {
+		perPage = 1000
+	}
+
+	page := c.QueryInt("page")
+	if page < 1 {
+		page = 1
+	}
+
+	resp, err := api.PublicDashboardService.FindAccessLog(c.Req.Context(), &AccessLogQuery{
+		OrgID:              c.SignedInUser.GetOrgID(),
+		PublicDashboardUid: uid,
+		Page:               page,
+		Limit:              perPage,
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, resp)
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(ctx context.Context, features featuremgmt.FeatureToggles, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
@@ -379,3 +438,25 @@ type DeletePublicDashboardParams struct {
 	// required:true
 	Uid string `json:"uid"`
 }
+
+// swagger:parameters getPublicDashboardAccessLog
+type GetPublicDashboardAccessLogParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:query
+	// default:1000
+	PerPage int `json:"perpage"`
+	// in:query
+	// default:1
+	Page int `json:"page"`
+}
+
+// swagger:response getPublicDashboardAccessLogResponse
+type GetPublicDashboardAccessLogResponse struct {
+	// in: body
+	Body AccessLogResponseWithPagination `json:"body"`
+}
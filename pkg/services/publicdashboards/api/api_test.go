@@ -622,3 +622,75 @@ func TestAPIUpdatePublicDashboard(t *testing.T) {
 		})
 	}
 }
+
+func TestApiGetPublicDashboardAccessLog(t *testing.T) {
+	const (
+		dashboardUid       = "abc1234"
+		publicDashboardUid = "pubdash1"
+	)
+
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		PublicDashboard      *PublicDashboard
+		ExpectedHttpResponse int
+		ShouldCallFindLog    bool
+	}{
+		{
+			Name:                 "Returns the access log of the public dashboard",
+			User:                 userAdmin,
+			PublicDashboard:      &PublicDashboard{Uid: publicDashboardUid, DashboardUid: dashboardUid, OrgId: 1},
+			ExpectedHttpResponse: http.StatusOK,
+			ShouldCallFindLog:    true,
+		},
+		{
+			Name:                 "Returns 404 when the public dashboard belongs to another dashboard",
+			User:                 userAdmin,
+			PublicDashboard:      &PublicDashboard{Uid: publicDashboardUid, DashboardUid: "other", OrgId: 1},
+			ExpectedHttpResponse: http.StatusNotFound,
+		},
+		{
+			Name:                 "Returns 404 when the public dashboard belongs to another org",
+			User:                 userAdmin,
+			PublicDashboard:      &PublicDashboard{Uid: publicDashboardUid, DashboardUid: dashboardUid, OrgId: 2},
+			ExpectedHttpResponse: http.StatusNotFound,
+		},
+		{
+			Name:                 "Viewers cannot read the access log",
+			User:                 userViewer,
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			if test.PublicDashboard != nil {
+				service.On("Find", mock.Anything, publicDashboardUid).Return(test.PublicDashboard, nil)
+			}
+			if test.ShouldCallFindLog {
+				service.On("FindAccessLog", mock.Anything, mock.MatchedBy(func(query *AccessLogQuery) bool {
+					return query.OrgID == 1 && query.PublicDashboardUid == publicDashboardUid && query.Page == 2 && query.Limit == 10
+				})).Return(&AccessLogResponseWithPagination{
+					Entries:    []*AccessLogEntry{{Id: 1, PublicDashboardUid: publicDashboardUid, Action: AccessActionView, IpAddress: "10.0.0.1"}},
+					TotalCount: 11,
+					Page:       2,
+					PerPage:    10,
+				}, nil)
+			}
+
+			testServer := setupTestServer(t, nil, service, test.User, true)
+			url := fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/access-log?page=2&perpage=10", dashboardUid, publicDashboardUid)
+			response := callAPI(testServer, http.MethodGet, url, nil, t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK {
+				var resp AccessLogResponseWithPagination
+				require.NoError(t, json.Unmarshal(response.Body.Bytes(), &resp))
+				require.Len(t, resp.Entries, 1)
+				assert.Equal(t, "10.0.0.1", resp.Entries[0].IpAddress)
+				assert.Equal(t, int64(11), resp.TotalCount)
+			}
+		})
+	}
+}
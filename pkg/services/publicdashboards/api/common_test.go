@@ -9,6 +9,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -73,6 +74,11 @@ func setupTestServer(
 		cfg.PublicDashboardsEnabled = true
 	}
 
+	// public dashboard access restrictions are covered by the middleware tests
+	if fakeService, ok := service.(*publicdashboards.FakePublicDashboardService); ok {
+		fakeService.On("AuthorizeAccess", mock.Anything, mock.Anything).Return(nil).Maybe()
+	}
+
 	// build api, this will mount the routes at the same time if the feature is enabled
 	license := licensingtest.NewFakeLicensing()
 	license.On("FeatureEnabled", publicdashboardModels.FeaturePublicDashboardsEmailSharing).Return(false)
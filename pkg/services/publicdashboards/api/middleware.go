@@ -1,11 +1,14 @@
 package api
 
 import (
+	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -62,6 +65,33 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresAccessAllowed Middleware to enforce the expiration, IP allow list and maximum number of views of a public
+// dashboard before continuing to handler. Allowed requests are recorded in the access log of the public dashboard.
+// The client address is only read from forwarding headers set by the trusted proxies, so that it cannot be forged.
+func RequiresAccessAllowed(publicDashboardService publicdashboards.Service, trustedProxies []*net.IPNet, action AccessAction) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		// invalid access tokens are rejected by the handlers
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			return
+		}
+
+		panelId, _ := strconv.ParseInt(web.Params(c.Req)[":panelId"], 10, 64)
+
+		err := publicDashboardService.AuthorizeAccess(c.Req.Context(), AccessRequest{
+			AccessToken: accessToken,
+			Action:      action,
+			PanelId:     panelId,
+			IpAddress:   web.ClientIP(c.Req, trustedProxies),
+			UserAgent:   c.Req.UserAgent(),
+		})
+		if err != nil {
+			c.WriteErr(err)
+			return
+		}
+	}
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
@@ -2,6 +2,7 @@ package api
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +11,7 @@ import (
 
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
@@ -83,6 +85,97 @@ func TestRequiresExistingAccessToken(t *testing.T) {
 	}
 }
 
+func TestRequiresAccessAllowed(t *testing.T) {
+	tests := []struct {
+		Name                 string
+		AccessToken          string
+		AuthorizeErr         error
+		ExpectedCalled       bool
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Continues when access is allowed",
+			AccessToken:          validAccessToken,
+			ExpectedCalled:       true,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Leaves invalid access tokens to the handler",
+			AccessToken:          "invalidAccessToken",
+			ExpectedCalled:       false,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 403 when public dashboard expired",
+			AccessToken:          validAccessToken,
+			AuthorizeErr:         ErrPublicDashboardExpired.Errorf("expired"),
+			ExpectedCalled:       true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 403 when ip is not allowed",
+			AccessToken:          validAccessToken,
+			AuthorizeErr:         ErrPublicDashboardIPNotAllowed.Errorf("not allowed"),
+			ExpectedCalled:       true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := publicdashboards.NewFakePublicDashboardService(t)
+			if tt.ExpectedCalled {
+				publicdashboardService.On("AuthorizeAccess", mock.Anything, mock.MatchedBy(func(req AccessRequest) bool {
+					return req.AccessToken == tt.AccessToken && req.Action == AccessActionQuery && req.PanelId == 2
+				})).Return(tt.AuthorizeErr).Once()
+			}
+
+			params := map[string]string{":accessToken": tt.AccessToken, ":panelId": "2"}
+			mw := RequiresAccessAllowed(publicdashboardService, nil, AccessActionQuery)
+			_, resp := runMw(t, nil, "POST", "/api/public/dashboards/myAccesstoken/panels/2/query", params, mw)
+			require.Equal(t, tt.ExpectedResponseCode, resp.Code)
+		})
+	}
+}
+
+func TestRequiresAccessAllowed_ClientAddress(t *testing.T) {
+	_, proxy, err := net.ParseCIDR("10.0.0.1/32")
+	require.NoError(t, err)
+
+	tests := []struct {
+		Name           string
+		RemoteAddr     string
+		ExpectedIP     string
+		TrustedProxies []*net.IPNet
+	}{
+		{Name: "Ignores forwarding headers of clients", RemoteAddr: "192.168.0.5:4000", ExpectedIP: "192.168.0.5"},
+		{Name: "Uses forwarding headers of trusted proxies", RemoteAddr: "10.0.0.1:4000", ExpectedIP: "1.2.3.4", TrustedProxies: []*net.IPNet{proxy}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := publicdashboards.NewFakePublicDashboardService(t)
+			publicdashboardService.On("AuthorizeAccess", mock.Anything, mock.MatchedBy(func(req AccessRequest) bool {
+				return req.IpAddress == tt.ExpectedIP
+			})).Return(nil).Once()
+
+			request, err := http.NewRequest("GET", "/api/public/dashboards/myAccesstoken", nil)
+			require.NoError(t, err)
+			request.RemoteAddr = tt.RemoteAddr
+			request.Header.Set("X-Real-IP", "1.2.3.4")
+			request = web.SetURLParams(request, map[string]string{":accessToken": validAccessToken})
+			response := httptest.NewRecorder()
+			ctx := &contextmodel.ReqContext{
+				Context:      &web.Context{Req: request, Resp: web.NewResponseWriter("GET", response)},
+				SignedInUser: &user.SignedInUser{},
+			}
+
+			RequiresAccessAllowed(publicdashboardService, tt.TrustedProxies, AccessActionView)(ctx)
+			require.Equal(t, http.StatusOK, response.Code)
+		})
+	}
+}
+
 func TestSetPublicDashboardOrgIdOnContext(t *testing.T) {
 	tests := []struct {
 		Name          string
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -247,12 +248,25 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		allowedIPsJSON, err := cmd.PublicDashboard.AllowedIPs.ToDB()
+		if err != nil {
+			return err
+		}
+
+		var expiresAt any
+		if cmd.PublicDashboard.ExpiresAt != nil {
+			expiresAt = cmd.PublicDashboard.ExpiresAt.UTC().Format("2006-01-02 15:04:05")
+		}
+
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, expires_at = ?, max_views = ?, allowed_ips = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			expiresAt,
+			cmd.PublicDashboard.MaxViews,
+			string(allowedIPsJSON),
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
@@ -273,10 +287,14 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
 
+		_, err = sess.Exec("DELETE FROM dashboard_public_access_log WHERE public_dashboard_uid = ?", uid)
 		return err
 	})
 
@@ -309,3 +327,82 @@ func (d *PublicDashboardStoreImpl) GetMetrics(ctx context.Context) (*Metrics, er
 
 	return metrics, nil
 }
+
+// IncrementViewCount Increments the view count of a public dashboard. Responds false without incrementing when the
+// public dashboard already reached its maximum number of views
+func (d *PublicDashboardStoreImpl) IncrementViewCount(ctx context.Context, uid string) (bool, error) {
+	var incremented bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET view_count = view_count + 1 WHERE uid = ? AND (max_views = 0 OR view_count < max_views)", uid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err := sqlResult.RowsAffected()
+		incremented = affectedRows > 0
+		return err
+	})
+
+	return incremented, err
+}
+
+// CreateAccessLogEntry Stores a request made to a public dashboard
+func (d *PublicDashboardStoreImpl) CreateAccessLogEntry(ctx context.Context, entry *AccessLogEntry) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(entry)
+		return err
+	})
+}
+
+// ExistsAccessLogEntry Responds true when the ip address made a request of the given action to the public dashboard
+// since the given time
+func (d *PublicDashboardStoreImpl) ExistsAccessLogEntry(ctx context.Context, uid string, action AccessAction, ipAddress string, since time.Time) (bool, error) {
+	var exists bool
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		exists, err = sess.Where("public_dashboard_uid = ? AND action = ? AND ip_address = ? AND accessed_at >= ?", uid, action, ipAddress, since).
+			Exist(&AccessLogEntry{})
+		return err
+	})
+
+	return exists, err
+}
+
+// DeleteAccessLogBefore Deletes the requests made to public dashboards before the given time
+func (d *PublicDashboardStoreImpl) DeleteAccessLogBefore(ctx context.Context, before time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Where("accessed_at < ?", before).Delete(&AccessLogEntry{})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// FindAccessLog Returns the requests made to a public dashboard, most recent first and with pagination
+func (d *PublicDashboardStoreImpl) FindAccessLog(ctx context.Context, query *AccessLogQuery) (*AccessLogResponseWithPagination, error) {
+	resp := &AccessLogResponseWithPagination{
+		Entries:    make([]*AccessLogEntry, 0),
+		TotalCount: 0,
+	}
+
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		err := sess.Where("org_id = ? AND public_dashboard_uid = ?", query.OrgID, query.PublicDashboardUid).
+			Desc("accessed_at", "id").
+			Limit(query.Limit, query.Offset).
+			Find(&resp.Entries)
+		if err != nil {
+			return err
+		}
+
+		resp.TotalCount, err = sess.Where("org_id = ? AND public_dashboard_uid = ?", query.OrgID, query.PublicDashboardUid).Count(&AccessLogEntry{})
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -23,6 +23,12 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Public Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Public Dashboard Access Token already exists"))
+	ErrInvalidExpiration                   = errutil.BadRequest("publicdashboards.invalidExpiration", errutil.WithPublicMessage("Expiration must be in the future"))
+	ErrInvalidMaxViews                     = errutil.BadRequest("publicdashboards.invalidMaxViews", errutil.WithPublicMessage("maxViews should be greater than or equal to 0"))
+	ErrInvalidAllowedIP                    = errutil.BadRequest("publicdashboards.invalidAllowedIp", errutil.WithPublicMessage("Invalid IP address or CIDR range"))
 
-	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardNotEnabled   = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrPublicDashboardExpired      = errutil.Forbidden("publicdashboards.expired", errutil.WithPublicMessage("Public dashboard expired"))
+	ErrPublicDashboardMaxViews     = errutil.Forbidden("publicdashboards.maxViewsReached", errutil.WithPublicMessage("Public dashboard reached its maximum number of views"))
+	ErrPublicDashboardIPNotAllowed = errutil.Forbidden("publicdashboards.ipNotAllowed", errutil.WithPublicMessage("Access to this public dashboard is not allowed from your IP address"))
 )
@@ -2,6 +2,8 @@ package models
 
 import (
 	"encoding/json"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/kinds/dashboard"
@@ -54,6 +56,16 @@ type PublicDashboard struct {
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
 	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	//access restrictions
+	ExpiresAt  *time.Time  `json:"expiresAt,omitempty" xorm:"expires_at"`
+	MaxViews   int64       `json:"maxViews" xorm:"max_views"`
+	ViewCount  int64       `json:"viewCount" xorm:"view_count"`
+	AllowedIPs IPAllowList `json:"allowedIps,omitempty" xorm:"allowed_ips"`
+}
+
+// IsExpired returns true when the public dashboard has an expiration date in the past
+func (pd PublicDashboard) IsExpired(now time.Time) bool {
+	return pd.ExpiresAt != nil && !pd.ExpiresAt.IsZero() && !now.Before(*pd.ExpiresAt)
 }
 
 type PublicDashboardDTO struct {
@@ -63,6 +75,12 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// ExpiresAt sets when the public dashboard stops being accessible, the zero time removes the expiration
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// MaxViews limits the number of times the public dashboard can be viewed, 0 means unlimited
+	MaxViews *int64 `json:"maxViews"`
+	// AllowedIPs restricts access to the listed IP addresses and CIDR ranges, an empty list allows any address
+	AllowedIPs []string `json:"allowedIps"`
 }
 
 type EmailDTO struct {
@@ -134,6 +152,105 @@ func (ts *TimeSettings) ToDB() ([]byte, error) {
 	return json.Marshal(ts)
 }
 
+// IPAllowList is a list of IP addresses and CIDR ranges stored as json
+type IPAllowList []string
+
+func (l *IPAllowList) FromDB(data []byte) error {
+	var list []string
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+	}
+
+	// an empty list is kept nil so it does not differ from a public dashboard without restrictions
+	*l = nil
+	if len(list) > 0 {
+		*l = list
+	}
+	return nil
+}
+
+func (l *IPAllowList) ToDB() ([]byte, error) {
+	if *l == nil {
+		return json.Marshal([]string{})
+	}
+	return json.Marshal(l)
+}
+
+// Contains returns true if the list is empty or the ip matches one of its addresses or ranges
+func (l IPAllowList) Contains(ip net.IP) bool {
+	if len(l) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+
+	for _, entry := range l {
+		if strings.Contains(entry, "/") {
+			_, ipNet, err := net.ParseCIDR(entry)
+			if err == nil && ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowed := net.ParseIP(entry); allowed != nil && allowed.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// AccessAction is the kind of public dashboard request recorded in the access log
+type AccessAction string
+
+const (
+	AccessActionView        AccessAction = "view"
+	AccessActionQuery       AccessAction = "query"
+	AccessActionAnnotations AccessAction = "annotations"
+)
+
+// AccessLogEntry is a request made to a public dashboard
+type AccessLogEntry struct {
+	Id                 int64        `json:"id" xorm:"pk autoincr 'id'"`
+	PublicDashboardUid string       `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	OrgId              int64        `json:"-" xorm:"org_id"`
+	Action             AccessAction `json:"action" xorm:"action"`
+	PanelId            int64        `json:"panelId,omitempty" xorm:"panel_id"`
+	IpAddress          string       `json:"ipAddress" xorm:"ip_address"`
+	UserAgent          string       `json:"userAgent" xorm:"user_agent"`
+	AccessedAt         time.Time    `json:"accessedAt" xorm:"accessed_at"`
+}
+
+func (e AccessLogEntry) TableName() string {
+	return "dashboard_public_access_log"
+}
+
+// AccessRequest describes an incoming request to a public dashboard to authorize and record
+type AccessRequest struct {
+	AccessToken string
+	Action      AccessAction
+	PanelId     int64
+	IpAddress   string
+	UserAgent   string
+}
+
+type AccessLogQuery struct {
+	OrgID              int64
+	PublicDashboardUid string
+	Page               int
+	Limit              int
+	Offset             int
+}
+
+type AccessLogResponseWithPagination struct {
+	Entries    []*AccessLogEntry `json:"entries"`
+	TotalCount int64             `json:"totalCount"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"perPage"`
+}
+
 // DTO for transforming user input in the api
 type SavePublicDashboardDTO struct {
 	Uid             string
@@ -1,11 +1,50 @@
 package models
 
 import (
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPublicDashboardTableName(t *testing.T) {
 	assert.Equal(t, "dashboard_public", PublicDashboard{}.TableName())
 }
+
+func TestAccessLogEntryTableName(t *testing.T) {
+	assert.Equal(t, "dashboard_public_access_log", AccessLogEntry{}.TableName())
+}
+
+func TestIPAllowListContains(t *testing.T) {
+	assert.True(t, IPAllowList{}.Contains(net.ParseIP("10.0.0.1")))
+	assert.True(t, IPAllowList{"10.0.0.1"}.Contains(net.ParseIP("10.0.0.1")))
+	assert.True(t, IPAllowList{"10.0.0.0/8"}.Contains(net.ParseIP("10.20.30.40")))
+	assert.True(t, IPAllowList{"2001:db8::/32"}.Contains(net.ParseIP("2001:db8::1")))
+	assert.False(t, IPAllowList{"10.0.0.1", "192.168.0.0/16"}.Contains(net.ParseIP("172.16.0.1")))
+	assert.False(t, IPAllowList{"10.0.0.1"}.Contains(nil))
+}
+
+func TestIPAllowListDB(t *testing.T) {
+	list := IPAllowList{"10.0.0.1"}
+	data, err := list.ToDB()
+	require.NoError(t, err)
+
+	var loaded IPAllowList
+	require.NoError(t, loaded.FromDB(data))
+	assert.Equal(t, list, loaded)
+
+	require.NoError(t, loaded.FromDB([]byte("[]")))
+	assert.Nil(t, loaded)
+}
+
+func TestPublicDashboardIsExpired(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	future := now.Add(time.Minute)
+
+	assert.False(t, PublicDashboard{}.IsExpired(now))
+	assert.True(t, PublicDashboard{ExpiresAt: &past}.IsExpired(now))
+	assert.False(t, PublicDashboard{ExpiresAt: &future}.IsExpired(now))
+}
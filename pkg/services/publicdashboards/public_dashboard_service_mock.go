@@ -23,6 +23,20 @@ type FakePublicDashboardService struct {
 	mock.Mock
 }
 
+// AuthorizeAccess provides a mock function with given fields: ctx, req
+func (_m *FakePublicDashboardService) AuthorizeAccess(ctx context.Context, req models.AccessRequest) error {
+	ret := _m.Called(ctx, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.AccessRequest) error); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, u, dto
 func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.SignedInUser, dto *models.SavePublicDashboardDTO) (*models.PublicDashboard, error) {
 	ret := _m.Called(ctx, u, dto)
@@ -77,6 +91,30 @@ func (_m *FakePublicDashboardService) DeleteByDashboard(ctx context.Context, das
 	return r0
 }
 
+// DeleteExpiredAccessLog provides a mock function with given fields: ctx
+func (_m *FakePublicDashboardService) DeleteExpiredAccessLog(ctx context.Context) (int64, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int64, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int64); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardService) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -151,6 +189,32 @@ func (_m *FakePublicDashboardService) Find(ctx context.Context, uid string) (*mo
 	return r0, r1
 }
 
+// FindAccessLog provides a mock function with given fields: ctx, query
+func (_m *FakePublicDashboardService) FindAccessLog(ctx context.Context, query *models.AccessLogQuery) (*models.AccessLogResponseWithPagination, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *models.AccessLogResponseWithPagination
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessLogQuery) (*models.AccessLogResponseWithPagination, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessLogQuery) *models.AccessLogResponseWithPagination); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessLogResponseWithPagination)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AccessLogQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardService) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
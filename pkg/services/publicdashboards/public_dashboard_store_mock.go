@@ -7,6 +7,8 @@ import (
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // FakePublicDashboardStore is an autogenerated mock type for the Store type
@@ -38,6 +40,20 @@ func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// CreateAccessLogEntry provides a mock function with given fields: ctx, entry
+func (_m *FakePublicDashboardStore) CreateAccessLogEntry(ctx context.Context, entry *models.AccessLogEntry) error {
+	ret := _m.Called(ctx, entry)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessLogEntry) error); ok {
+		r0 = rf(ctx, entry)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int64, error) {
 	ret := _m.Called(ctx, uid)
@@ -62,6 +78,54 @@ func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int
 	return r0, r1
 }
 
+// DeleteAccessLogBefore provides a mock function with given fields: ctx, before
+func (_m *FakePublicDashboardStore) DeleteAccessLogBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExistsAccessLogEntry provides a mock function with given fields: ctx, uid, action, ipAddress, since
+func (_m *FakePublicDashboardStore) ExistsAccessLogEntry(ctx context.Context, uid string, action models.AccessAction, ipAddress string, since time.Time) (bool, error) {
+	ret := _m.Called(ctx, uid, action, ipAddress, since)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.AccessAction, string, time.Time) (bool, error)); ok {
+		return rf(ctx, uid, action, ipAddress, since)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, models.AccessAction, string, time.Time) bool); ok {
+		r0 = rf(ctx, uid, action, ipAddress, since)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, models.AccessAction, string, time.Time) error); ok {
+		r1 = rf(ctx, uid, action, ipAddress, since)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ExistsEnabledByAccessToken provides a mock function with given fields: ctx, accessToken
 func (_m *FakePublicDashboardStore) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
 	ret := _m.Called(ctx, accessToken)
@@ -136,6 +200,32 @@ func (_m *FakePublicDashboardStore) Find(ctx context.Context, uid string) (*mode
 	return r0, r1
 }
 
+// FindAccessLog provides a mock function with given fields: ctx, query
+func (_m *FakePublicDashboardStore) FindAccessLog(ctx context.Context, query *models.AccessLogQuery) (*models.AccessLogResponseWithPagination, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 *models.AccessLogResponseWithPagination
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessLogQuery) (*models.AccessLogResponseWithPagination, error)); ok {
+		return rf(ctx, query)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.AccessLogQuery) *models.AccessLogResponseWithPagination); ok {
+		r0 = rf(ctx, query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AccessLogResponseWithPagination)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.AccessLogQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardStore) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -290,6 +380,30 @@ func (_m *FakePublicDashboardStore) GetOrgIdByAccessToken(ctx context.Context, a
 	return r0, r1
 }
 
+// IncrementViewCount provides a mock function with given fields: ctx, uid
+func (_m *FakePublicDashboardStore) IncrementViewCount(ctx context.Context, uid string) (bool, error) {
+	ret := _m.Called(ctx, uid)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, cmd
 func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SavePublicDashboardCommand) (int64, error) {
 	ret := _m.Called(ctx, cmd)
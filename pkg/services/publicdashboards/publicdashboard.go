@@ -2,6 +2,7 @@ package publicdashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -38,6 +39,10 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	AuthorizeAccess(ctx context.Context, req AccessRequest) error
+	FindAccessLog(ctx context.Context, query *AccessLogQuery) (*AccessLogResponseWithPagination, error)
+	DeleteExpiredAccessLog(ctx context.Context) (int64, error)
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,6 +68,12 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+
+	IncrementViewCount(ctx context.Context, uid string) (bool, error)
+	CreateAccessLogEntry(ctx context.Context, entry *AccessLogEntry) error
+	ExistsAccessLogEntry(ctx context.Context, uid string, action AccessAction, ipAddress string, since time.Time) (bool, error)
+	FindAccessLog(ctx context.Context, query *AccessLogQuery) (*AccessLogResponseWithPagination, error)
+	DeleteAccessLogBefore(ctx context.Context, before time.Time) (int64, error)
 }
 
 //go:generate mockery --name Middleware --structname FakePublicDashboardMiddleware --inpackage --filename public_dashboard_middleware_mock.go
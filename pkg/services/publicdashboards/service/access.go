@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// maxUserAgentLength is the size of the user_agent column of the access log
+const maxUserAgentLength = 255
+
+// countedViewQueryWindow is how long the clients whose view was counted can query the panels of a public dashboard
+// that reached its maximum number of views
+const countedViewQueryWindow = 24 * time.Hour
+
+// AuthorizeAccess enforces the expiration, IP allow list and maximum number of views of a public dashboard and
+// records the request in its access log. Unknown and paused public dashboards are left to the handlers.
+func (pd *PublicDashboardServiceImpl) AuthorizeAccess(ctx context.Context, req AccessRequest) error {
+	pubdash, err := pd.store.FindByAccessToken(ctx, req.AccessToken)
+	if err != nil {
+		return ErrInternalServerError.Errorf("AuthorizeAccess: failed to find a public dashboard: %w", err)
+	}
+	if pubdash == nil || !pubdash.IsEnabled {
+		return nil
+	}
+
+	if pubdash.IsExpired(time.Now()) {
+		return ErrPublicDashboardExpired.Errorf("AuthorizeAccess: public dashboard %s expired at %s", pubdash.Uid, pubdash.ExpiresAt)
+	}
+
+	if !pubdash.AllowedIPs.Contains(parseRemoteIP(req.IpAddress)) {
+		return ErrPublicDashboardIPNotAllowed.Errorf("AuthorizeAccess: ip %s is not allowed to access public dashboard %s", req.IpAddress, pubdash.Uid)
+	}
+
+	// only loading the dashboard counts as a view. Once the maximum is reached, the panel queries are only answered
+	// for the clients whose view was counted, the data would be readable without limit otherwise.
+	switch {
+	case req.Action == AccessActionView:
+		incremented, err := pd.store.IncrementViewCount(ctx, pubdash.Uid)
+		if err != nil {
+			return ErrInternalServerError.Errorf("AuthorizeAccess: failed to increment the view count of public dashboard %s: %w", pubdash.Uid, err)
+		}
+		if !incremented {
+			return ErrPublicDashboardMaxViews.Errorf("AuthorizeAccess: public dashboard %s reached its maximum of %d views", pubdash.Uid, pubdash.MaxViews)
+		}
+	case pubdash.MaxViews > 0 && pubdash.ViewCount >= pubdash.MaxViews:
+		viewed, err := pd.store.ExistsAccessLogEntry(ctx, pubdash.Uid, AccessActionView, req.IpAddress, time.Now().Add(-countedViewQueryWindow))
+		if err != nil {
+			return ErrInternalServerError.Errorf("AuthorizeAccess: failed to find the views of public dashboard %s: %w", pubdash.Uid, err)
+		}
+		if !viewed {
+			return ErrPublicDashboardMaxViews.Errorf("AuthorizeAccess: public dashboard %s reached its maximum of %d views", pubdash.Uid, pubdash.MaxViews)
+		}
+	}
+
+	userAgent := req.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	err = pd.store.CreateAccessLogEntry(ctx, &AccessLogEntry{
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Action:             req.Action,
+		PanelId:            req.PanelId,
+		IpAddress:          req.IpAddress,
+		UserAgent:          userAgent,
+		AccessedAt:         time.Now(),
+	})
+	if err != nil {
+		// a missing log entry should not prevent viewers from accessing the dashboard
+		pd.log.Warn("Failed to record public dashboard access", "publicDashboardUid", pubdash.Uid, "error", err)
+	}
+
+	return nil
+}
+
+// FindAccessLog Returns the access log of a public dashboard with pagination
+func (pd *PublicDashboardServiceImpl) FindAccessLog(ctx context.Context, query *AccessLogQuery) (*AccessLogResponseWithPagination, error) {
+	query.Offset = query.Limit * (query.Page - 1)
+	resp, err := pd.store.FindAccessLog(ctx, query)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindAccessLog: %w", err)
+	}
+
+	resp.Page = query.Page
+	resp.PerPage = query.Limit
+
+	return resp, nil
+}
+
+// DeleteExpiredAccessLog Deletes the access log entries older than the configured maximum age
+func (pd *PublicDashboardServiceImpl) DeleteExpiredAccessLog(ctx context.Context) (int64, error) {
+	if pd.cfg.PublicDashboardsAccessLogMaxAge <= 0 {
+		return 0, nil
+	}
+	deleted, err := pd.store.DeleteAccessLogBefore(ctx, time.Now().Add(-pd.cfg.PublicDashboardsAccessLogMaxAge))
+	if err != nil {
+		return 0, ErrInternalServerError.Errorf("DeleteExpiredAccessLog: %w", err)
+	}
+	return deleted, nil
+}
+
+// parseRemoteIP parses the client address of the request, tolerating the brackets of IPv6 addresses
+func parseRemoteIP(addr string) net.IP {
+	return net.ParseIP(strings.Trim(addr, "[]"))
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+func TestAuthorizeAccess(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		Name        string
+		Pubdash     *PublicDashboard
+		IpAddress   string
+		ExpectedErr error
+		ExpectedLog bool
+	}{
+		{
+			Name:        "allows and records access without restrictions",
+			Pubdash:     &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: true},
+			IpAddress:   "10.0.0.1",
+			ExpectedLog: true,
+		},
+		{
+			Name:    "ignores unknown public dashboards",
+			Pubdash: nil,
+		},
+		{
+			Name:    "ignores paused public dashboards",
+			Pubdash: &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: false, ExpiresAt: &past},
+		},
+		{
+			Name:        "rejects expired public dashboards",
+			Pubdash:     &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: true, ExpiresAt: &past},
+			ExpectedErr: ErrPublicDashboardExpired,
+		},
+		{
+			Name:        "allows public dashboards that did not expire yet",
+			Pubdash:     &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: true, ExpiresAt: &future},
+			IpAddress:   "10.0.0.1",
+			ExpectedLog: true,
+		},
+		{
+			Name:        "allows ip addresses in the allow list",
+			Pubdash:     &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: true, AllowedIPs: IPAllowList{"192.168.1.10", "10.0.0.0/8"}},
+			IpAddress:   "10.1.2.3",
+			ExpectedLog: true,
+		},
+		{
+			Name:        "allows ipv6 addresses in the allow list",
+			Pubdash:     &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: true, AllowedIPs: IPAllowList{"2001:db8::/32"}},
+			IpAddress:   "[2001:db8::1]",
+			ExpectedLog: true,
+		},
+		{
+			Name:        "rejects ip addresses outside of the allow list",
+			Pubdash:     &PublicDashboard{Uid: "pubdash", OrgId: 1, IsEnabled: true, AllowedIPs: IPAllowList{"192.168.1.10", "10.0.0.0/8"}},
+			IpAddress:   "172.16.0.1",
+			ExpectedErr: ErrPublicDashboardIPNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			fakeStore := NewFakePublicDashboardStore(t)
+			fakeStore.On("FindByAccessToken", mock.Anything, "token").Return(test.Pubdash, nil)
+			if test.ExpectedLog {
+				fakeStore.On("CreateAccessLogEntry", mock.Anything, mock.MatchedBy(func(entry *AccessLogEntry) bool {
+					return entry.PublicDashboardUid == "pubdash" && entry.OrgId == 1 && entry.Action == AccessActionQuery &&
+						entry.PanelId == 2 && entry.IpAddress == test.IpAddress && entry.UserAgent == "test-agent"
+				})).Return(nil).Once()
+			}
+
+			service := &PublicDashboardServiceImpl{store: fakeStore, log: log.New("test.logger")}
+			err := service.AuthorizeAccess(context.Background(), AccessRequest{
+				AccessToken: "token",
+				Action:      AccessActionQuery,
+				PanelId:     2,
+				IpAddress:   test.IpAddress,
+				UserAgent:   "test-agent",
+			})
+
+			if test.ExpectedErr != nil {
+				assert.ErrorIs(t, err, test.ExpectedErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	t.Run("rejects queries of clients without a counted view once the maximum of views is reached", func(t *testing.T) {
+		fakeStore := NewFakePublicDashboardStore(t)
+		fakeStore.On("FindByAccessToken", mock.Anything, "token").Return(&PublicDashboard{Uid: "pubdash", IsEnabled: true, MaxViews: 1, ViewCount: 1}, nil)
+		fakeStore.On("ExistsAccessLogEntry", mock.Anything, "pubdash", AccessActionView, "10.0.0.2", mock.Anything).Return(false, nil)
+
+		service := &PublicDashboardServiceImpl{store: fakeStore, log: log.New("test.logger")}
+		err := service.AuthorizeAccess(context.Background(), AccessRequest{AccessToken: "token", Action: AccessActionQuery, PanelId: 1, IpAddress: "10.0.0.2"})
+		require.ErrorIs(t, err, ErrPublicDashboardMaxViews)
+	})
+
+	t.Run("does not fail when the access log cannot be stored", func(t *testing.T) {
+		fakeStore := NewFakePublicDashboardStore(t)
+		fakeStore.On("FindByAccessToken", mock.Anything, "token").Return(&PublicDashboard{Uid: "pubdash", IsEnabled: true}, nil)
+		fakeStore.On("CreateAccessLogEntry", mock.Anything, mock.Anything).Return(errors.New("database error"))
+
+		service, _, _ := newPublicDashboardServiceImpl(t, fakeStore, nil, nil)
+		err := service.AuthorizeAccess(context.Background(), AccessRequest{AccessToken: "token", Action: AccessActionAnnotations})
+		require.NoError(t, err)
+	})
+}
+
+func TestIntegrationAuthorizeAccessMaxViews(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	service, sqlStore, cfg := newPublicDashboardServiceImpl(t, nil, nil, nil)
+	store := database.ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+
+	_, err := store.Create(context.Background(), SavePublicDashboardCommand{
+		PublicDashboard: PublicDashboard{
+			Uid:          "pubdash",
+			DashboardUid: "dash",
+			OrgId:        1,
+			AccessToken:  "token",
+			IsEnabled:    true,
+			MaxViews:     2,
+			TimeSettings: &TimeSettings{},
+			Share:        PublicShareType,
+			CreatedAt:    time.Now(),
+			UpdatedAt:    time.Now(),
+		},
+	})
+	require.NoError(t, err)
+
+	view := AccessRequest{AccessToken: "token", Action: AccessActionView, IpAddress: "10.0.0.1"}
+	query := AccessRequest{AccessToken: "token", Action: AccessActionQuery, PanelId: 1, IpAddress: "10.0.0.1"}
+
+	require.NoError(t, service.AuthorizeAccess(context.Background(), view))
+	require.NoError(t, service.AuthorizeAccess(context.Background(), query))
+	require.NoError(t, service.AuthorizeAccess(context.Background(), view))
+	require.ErrorIs(t, service.AuthorizeAccess(context.Background(), view), ErrPublicDashboardMaxViews)
+	// queries of the last allowed view are still answered
+	require.NoError(t, service.AuthorizeAccess(context.Background(), query))
+	// other clients can't query the panels once the maximum is reached
+	for _, action := range []AccessAction{AccessActionQuery, AccessActionAnnotations} {
+		err := service.AuthorizeAccess(context.Background(), AccessRequest{AccessToken: "token", Action: action, PanelId: 1, IpAddress: "10.0.0.2"})
+		require.ErrorIs(t, err, ErrPublicDashboardMaxViews)
+	}
+
+	pubdash, err := store.Find(context.Background(), "pubdash")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pubdash.ViewCount)
+
+	accessLog, err := service.FindAccessLog(context.Background(), &AccessLogQuery{OrgID: 1, PublicDashboardUid: "pubdash", Page: 1, Limit: 3})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), accessLog.TotalCount)
+	require.Len(t, accessLog.Entries, 3)
+	assert.Equal(t, AccessActionQuery, accessLog.Entries[0].Action)
+
+	// only entries older than the maximum age are deleted
+	require.NoError(t, store.CreateAccessLogEntry(context.Background(), &AccessLogEntry{
+		PublicDashboardUid: "pubdash",
+		OrgId:              1,
+		Action:             AccessActionView,
+		AccessedAt:         time.Now().Add(-2 * time.Hour),
+	}))
+	cfg.PublicDashboardsAccessLogMaxAge = time.Hour
+	service.cfg = cfg
+	deleted, err := service.DeleteExpiredAccessLog(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	accessLog, err = service.FindAccessLog(context.Background(), &AccessLogQuery{OrgID: 1, PublicDashboardUid: "pubdash", Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), accessLog.TotalCount)
+
+	// deleting the public dashboard removes its access log
+	_, err = store.Delete(context.Background(), "pubdash")
+	require.NoError(t, err)
+	accessLog, err = service.FindAccessLog(context.Background(), &AccessLogQuery{OrgID: 1, PublicDashboardUid: "pubdash", Page: 1, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), accessLog.TotalCount)
+}
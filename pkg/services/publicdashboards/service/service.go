@@ -505,6 +505,16 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 
 	now := time.Now()
 
+	var expiresAt *time.Time
+	if dto.PublicDashboard.ExpiresAt != nil && !dto.PublicDashboard.ExpiresAt.IsZero() {
+		expiresAt = dto.PublicDashboard.ExpiresAt
+	}
+
+	var maxViews int64
+	if dto.PublicDashboard.MaxViews != nil {
+		maxViews = *dto.PublicDashboard.MaxViews
+	}
+
 	return &PublicDashboard{
 		Uid:                  uid,
 		DashboardUid:         dto.DashboardUid,
@@ -519,6 +529,9 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            now,
 		AccessToken:          accessToken,
+		ExpiresAt:            expiresAt,
+		MaxViews:             maxViews,
+		AllowedIPs:           IPAllowList(dto.PublicDashboard.AllowedIPs),
 	}, nil
 }
 
@@ -533,6 +546,26 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	// a zero expiration removes it
+	expiresAt := pd.ExpiresAt
+	if pubdashDTO.ExpiresAt != nil {
+		expiresAt = pubdashDTO.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt = nil
+		}
+	}
+
+	maxViews := pd.MaxViews
+	if pubdashDTO.MaxViews != nil {
+		maxViews = *pubdashDTO.MaxViews
+	}
+
+	// an empty list removes the restriction, a missing one keeps it
+	allowedIPs := pd.AllowedIPs
+	if pubdashDTO.AllowedIPs != nil {
+		allowedIPs = IPAllowList(pubdashDTO.AllowedIPs)
+	}
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
@@ -540,6 +573,9 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		ExpiresAt:            expiresAt,
+		MaxViews:             maxViews,
+		AllowedIPs:           allowedIPs,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
@@ -1,6 +1,10 @@
 package validation
 
 import (
+	"net"
+	"strings"
+	"time"
+
 	"github.com/google/uuid"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
@@ -13,9 +17,33 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 		return ErrInvalidShareType.Errorf("ValidateSavePublicDashboard: invalid share type")
 	}
 
+	// a zero expiration removes it, any other value must be in the future
+	if dto.PublicDashboard.ExpiresAt != nil && !dto.PublicDashboard.ExpiresAt.IsZero() && !dto.PublicDashboard.ExpiresAt.After(time.Now()) {
+		return ErrInvalidExpiration.Errorf("ValidateSavePublicDashboard: expiration must be in the future")
+	}
+
+	if dto.PublicDashboard.MaxViews != nil && *dto.PublicDashboard.MaxViews < 0 {
+		return ErrInvalidMaxViews.Errorf("ValidateSavePublicDashboard: maxViews should be greater than or equal to 0")
+	}
+
+	for _, entry := range dto.PublicDashboard.AllowedIPs {
+		if !IsValidIPOrCIDR(entry) {
+			return ErrInvalidAllowedIP.Errorf("ValidateSavePublicDashboard: invalid IP address or CIDR range %q", entry)
+		}
+	}
+
 	return nil
 }
 
+// IsValidIPOrCIDR checks that the value is an IP address or a CIDR range
+func IsValidIPOrCIDR(value string) bool {
+	if strings.Contains(value, "/") {
+		_, _, err := net.ParseCIDR(value)
+		return err == nil
+	}
+	return net.ParseIP(value) != nil
+}
+
 func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *PublicDashboard) error {
 	if req.IntervalMs < 0 {
 		return ErrInvalidInterval.Errorf("ValidateQueryPublicDashboardRequest: intervalMS should be greater than 0")
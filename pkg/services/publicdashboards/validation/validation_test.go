@@ -2,6 +2,7 @@ package validation
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +23,38 @@ func TestValidatePublicDashboard(t *testing.T) {
 		err := ValidatePublicDashboard(dto)
 		require.Error(t, err)
 	})
+
+	t.Run("Returns error when expiration is in the past", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{ExpiresAt: &past}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidExpiration)
+	})
+
+	t.Run("Returns no error when expiration is removed", func(t *testing.T) {
+		zero := time.Time{}
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{ExpiresAt: &zero}}
+
+		err := ValidatePublicDashboard(dto)
+		require.NoError(t, err)
+	})
+
+	t.Run("Returns error when maxViews is negative", func(t *testing.T) {
+		maxViews := int64(-1)
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{MaxViews: &maxViews}}
+
+		err := ValidatePublicDashboard(dto)
+		require.ErrorIs(t, err, ErrInvalidMaxViews)
+	})
+
+	t.Run("Validates allowed IPs", func(t *testing.T) {
+		dto := &SavePublicDashboardDTO{DashboardUid: "abc123", UserId: 1, PublicDashboard: &PublicDashboardDTO{AllowedIPs: []string{"10.0.0.1", "192.168.0.0/16", "2001:db8::/32"}}}
+		require.NoError(t, ValidatePublicDashboard(dto))
+
+		dto.PublicDashboard.AllowedIPs = []string{"10.0.0.300"}
+		require.ErrorIs(t, ValidatePublicDashboard(dto), ErrInvalidAllowedIP)
+	})
 }
 
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
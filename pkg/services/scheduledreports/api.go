@@ -0,0 +1,327 @@
+package scheduledreports
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *ScheduledReportsService) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/scheduled-reports", func(reports routing.RouteRegister) {
+		reports.Get("/", authorize(accesscontrol.EvalPermission(ActionReportsRead)), routing.Wrap(s.searchHandler))
+		reports.Post("/", authorize(accesscontrol.EvalPermission(ActionReportsWrite)), routing.Wrap(s.createHandler))
+		reports.Get("/:uid", authorize(accesscontrol.EvalPermission(ActionReportsRead)), routing.Wrap(s.getHandler))
+		reports.Put("/:uid", authorize(accesscontrol.EvalPermission(ActionReportsWrite)), routing.Wrap(s.updateHandler))
+		reports.Delete("/:uid", authorize(accesscontrol.EvalPermission(ActionReportsWrite)), routing.Wrap(s.deleteHandler))
+		reports.Post("/:uid/send", authorize(accesscontrol.EvalPermission(ActionReportsWrite)), routing.Wrap(s.sendHandler))
+		reports.Get("/:uid/history", authorize(accesscontrol.EvalPermission(ActionReportsRead)), routing.Wrap(s.historyHandler))
+	}, middleware.ReqSignedIn)
+}
+
+// swagger:route GET /scheduled-reports scheduled_reports searchScheduledReports
+//
+// Get all scheduled reports of the organization.
+//
+// Only the reports of dashboards the user can read are returned.
+//
+// Responses:
+// 200: searchScheduledReportsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 500: internalServerError
+func (s *ScheduledReportsService) searchHandler(c *contextmodel.ReqContext) response.Response {
+	reports, err := s.SearchReports(c.Req.Context(), &SearchReportsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardUID: c.Query("dashboardUid"),
+	})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get reports", err)
+	}
+
+	filtered := make([]*Report, 0, len(reports))
+	for _, report := range reports {
+		ok, err := s.canReadDashboard(c, report.DashboardUID)
+		if err != nil {
+			return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+		}
+		if ok {
+			filtered = append(filtered, report)
+		}
+	}
+
+	return response.JSON(http.StatusOK, filtered)
+}
+
+// swagger:route POST /scheduled-reports scheduled_reports createScheduledReport
+//
+// Create a scheduled report.
+//
+// The dashboard is rendered with the permissions of the user who last saved the report.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ScheduledReportsService) createHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := CreateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if resp := s.requireDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+	report, err := s.CreateReport(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route GET /scheduled-reports/{uid} scheduled_reports getScheduledReport
+//
+// Get a scheduled report by UID.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ScheduledReportsService) getHandler(c *contextmodel.ReqContext) response.Response {
+	report, resp := s.getReadableReport(c)
+	if resp != nil {
+		return resp
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route PUT /scheduled-reports/{uid} scheduled_reports updateScheduledReport
+//
+// Update a scheduled report.
+//
+// The user updating the report becomes its owner and the dashboard is rendered with their permissions.
+//
+// Responses:
+// 200: getScheduledReportResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ScheduledReportsService) updateHandler(c *contextmodel.ReqContext) response.Response {
+	if _, resp := s.getReadableReport(c); resp != nil {
+		return resp
+	}
+
+	cmd := UpdateReportCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if resp := s.requireDashboardAccess(c, cmd.DashboardUID); resp != nil {
+		return resp
+	}
+
+	cmd.UID = web.Params(c.Req)[":uid"]
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.UserID = c.SignedInUser.UserID
+	report, err := s.UpdateReport(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, report)
+}
+
+// swagger:route DELETE /scheduled-reports/{uid} scheduled_reports deleteScheduledReport
+//
+// Delete a scheduled report and its history.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ScheduledReportsService) deleteHandler(c *contextmodel.ReqContext) response.Response {
+	report, resp := s.getReadableReport(c)
+	if resp != nil {
+		return resp
+	}
+
+	err := s.DeleteReport(c.Req.Context(), &DeleteReportCommand{UID: report.UID, OrgID: report.OrgID})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Report deleted")
+}
+
+// swagger:route POST /scheduled-reports/{uid}/send scheduled_reports sendScheduledReport
+//
+// Send a scheduled report now.
+//
+// The report is sent immediately and the result is added to its history. The schedule of the report is not changed.
+//
+// Responses:
+// 200: getScheduledReportHistoryEntryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ScheduledReportsService) sendHandler(c *contextmodel.ReqContext) response.Response {
+	report, resp := s.getReadableReport(c)
+	if resp != nil {
+		return resp
+	}
+
+	entry, err := s.SendReport(c.Req.Context(), &GetReportQuery{UID: report.UID, OrgID: report.OrgID}, c.SignedInUser.UserID)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, entry)
+}
+
+// swagger:route GET /scheduled-reports/{uid}/history scheduled_reports getScheduledReportHistory
+//
+// Get the delivery history of a scheduled report.
+//
+// Returns the most recent deliveries first. Use the `limit` parameter to control the number of entries returned; the default limit is 100.
+//
+// Responses:
+// 200: getScheduledReportHistoryResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (s *ScheduledReportsService) historyHandler(c *contextmodel.ReqContext) response.Response {
+	report, resp := s.getReadableReport(c)
+	if resp != nil {
+		return resp
+	}
+
+	history, err := s.GetReportHistory(c.Req.Context(), &GetReportHistoryQuery{
+		ReportUID: report.UID,
+		OrgID:     report.OrgID,
+		Limit:     c.QueryInt("limit"),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, history)
+}
+
+// getReadableReport returns the report of the request if the user can read its dashboard
+func (s *ScheduledReportsService) getReadableReport(c *contextmodel.ReqContext) (*Report, response.Response) {
+	report, err := s.GetReport(c.Req.Context(), &GetReportQuery{
+		UID:   web.Params(c.Req)[":uid"],
+		OrgID: c.SignedInUser.GetOrgID(),
+	})
+	if err != nil {
+		return nil, response.Err(err)
+	}
+	if resp := s.requireDashboardAccess(c, report.DashboardUID); resp != nil {
+		return nil, resp
+	}
+	return report, nil
+}
+
+func (s *ScheduledReportsService) requireDashboardAccess(c *contextmodel.ReqContext, dashboardUID string) response.Response {
+	ok, err := s.canReadDashboard(c, dashboardUID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to evaluate permissions", err)
+	}
+	if !ok {
+		return response.Err(ErrAccessDenied.Errorf("user cannot read dashboard %s", dashboardUID))
+	}
+	return nil
+}
+
+func (s *ScheduledReportsService) canReadDashboard(c *contextmodel.ReqContext, dashboardUID string) (bool, error) {
+	evaluator := accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID))
+	return s.accessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator)
+}
+
+// swagger:parameters searchScheduledReports
+type SearchScheduledReportsParams struct {
+	// Only return the reports of this dashboard
+	// in:query
+	// required:false
+	DashboardUID string `json:"dashboardUid"`
+}
+
+// swagger:parameters createScheduledReport
+type CreateScheduledReportParams struct {
+	// in:body
+	// required:true
+	Body ReportSettings `json:"body"`
+}
+
+// swagger:parameters updateScheduledReport
+type UpdateScheduledReportParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// in:body
+	// required:true
+	Body ReportSettings `json:"body"`
+}
+
+// swagger:parameters getScheduledReport deleteScheduledReport sendScheduledReport
+type ScheduledReportUIDParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters getScheduledReportHistory
+type GetScheduledReportHistoryParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Maximum number of entries to return
+	// in:query
+	// required:false
+	Limit int `json:"limit"`
+}
+
+// swagger:response searchScheduledReportsResponse
+type SearchScheduledReportsResponse struct {
+	// in: body
+	Body []*Report `json:"body"`
+}
+
+// swagger:response getScheduledReportResponse
+type GetScheduledReportResponse struct {
+	// in: body
+	Body *Report `json:"body"`
+}
+
+// swagger:response getScheduledReportHistoryEntryResponse
+type GetScheduledReportHistoryEntryResponse struct {
+	// in: body
+	Body *ReportHistory `json:"body"`
+}
+
+// swagger:response getScheduledReportHistoryResponse
+type GetScheduledReportHistoryResponse struct {
+	// in: body
+	Body []*ReportHistory `json:"body"`
+}
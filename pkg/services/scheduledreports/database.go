@@ -0,0 +1,120 @@
+package scheduledreports
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type sqlStore struct {
+	db db.DB
+}
+
+func (s *sqlStore) insert(ctx context.Context, report *Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(report)
+		return err
+	})
+}
+
+func (s *sqlStore) update(ctx context.Context, report *Report) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.ID(report.ID).AllCols().Update(report)
+		return err
+	})
+}
+
+func (s *sqlStore) get(ctx context.Context, orgID int64, uid string) (*Report, error) {
+	report := &Report{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound.Errorf("report %s not found", uid)
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *sqlStore) getByID(ctx context.Context, id int64) (*Report, error) {
+	report := &Report{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		exists, err := sess.ID(id).Get(report)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return ErrReportNotFound.Errorf("report %d not found", id)
+		}
+		return nil
+	})
+	return report, err
+}
+
+func (s *sqlStore) search(ctx context.Context, query *SearchReportsQuery) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Where("org_id = ?", query.OrgID)
+		if query.DashboardUID != "" {
+			sess.And("dashboard_uid = ?", query.DashboardUID)
+		}
+		return sess.Asc("name").Find(&reports)
+	})
+	return reports, err
+}
+
+// delete removes a report and its history
+func (s *sqlStore) delete(ctx context.Context, orgID int64, uid string) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			report := &Report{}
+			exists, err := sess.Where("org_id = ? AND uid = ?", orgID, uid).Get(report)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return ErrReportNotFound.Errorf("report %s not found", uid)
+			}
+
+			if _, err := sess.Exec("DELETE FROM scheduled_report_history WHERE report_id = ?", report.ID); err != nil {
+				return err
+			}
+			_, err = sess.Exec("DELETE FROM scheduled_report WHERE id = ?", report.ID)
+			return err
+		})
+	})
+}
+
+// findDue returns the enabled reports of all organizations that should have been sent by now
+func (s *sqlStore) findDue(ctx context.Context, now time.Time) ([]*Report, error) {
+	reports := make([]*Report, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("enabled = ? AND next_run_at <= ?", s.db.GetDialect().BooleanStr(true), now.UTC()).Asc("next_run_at").Find(&reports)
+	})
+	return reports, err
+}
+
+// recordRun stores the result of sending a report together with its new run times
+func (s *sqlStore) recordRun(ctx context.Context, report *Report, entry *ReportHistory) error {
+	return s.db.InTransaction(ctx, func(ctx context.Context) error {
+		return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+			if _, err := sess.Insert(entry); err != nil {
+				return err
+			}
+			_, err := sess.ID(report.ID).Cols("last_run_at", "next_run_at").Update(report)
+			return err
+		})
+	})
+}
+
+func (s *sqlStore) history(ctx context.Context, reportID int64, limit int) ([]*ReportHistory, error) {
+	entries := make([]*ReportHistory, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("report_id = ?", reportID).Desc("sent_at").Desc("id").Limit(limit).Find(&entries)
+	})
+	return entries, err
+}
@@ -0,0 +1,198 @@
+package scheduledreports
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util/errutil"
+)
+
+var (
+	ErrReportNotFound    = errutil.NotFound("scheduledreports.notFound", errutil.WithPublicMessage("Report not found"))
+	ErrDashboardNotFound = errutil.NotFound("scheduledreports.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
+	ErrInvalidName       = errutil.BadRequest("scheduledreports.invalidName", errutil.WithPublicMessage("Report name is required"))
+	ErrInvalidDashboard  = errutil.BadRequest("scheduledreports.invalidDashboard", errutil.WithPublicMessage("Dashboard UID is required"))
+	ErrInvalidSchedule   = errutil.BadRequest("scheduledreports.invalidSchedule", errutil.WithPublicMessage("Invalid schedule"))
+	ErrInvalidTimezone   = errutil.BadRequest("scheduledreports.invalidTimezone", errutil.WithPublicMessage("Invalid timezone"))
+	ErrInvalidTimeRange  = errutil.BadRequest("scheduledreports.invalidTimeRange", errutil.WithPublicMessage("Invalid time range"))
+	ErrInvalidRecipients = errutil.BadRequest("scheduledreports.invalidRecipients", errutil.WithPublicMessage("At least one valid recipient email address is required"))
+	ErrInvalidFormat     = errutil.BadRequest("scheduledreports.invalidFormat", errutil.WithPublicMessage("Format must be one of png, pdf or csv"))
+	ErrAccessDenied      = errutil.Forbidden("scheduledreports.accessDenied", errutil.WithPublicMessage("Access denied to the dashboard of the report"))
+	ErrInternal          = errutil.Internal("scheduledreports.internal")
+)
+
+const (
+	// ActionReportsRead is the RBAC action for reading the scheduled reports of an organization
+	ActionReportsRead = "scheduled-reports:read"
+	// ActionReportsWrite is the RBAC action for creating, updating, deleting and sending scheduled reports
+	ActionReportsWrite = "scheduled-reports:write"
+)
+
+// Format is the format of the attachment of a report.
+type Format string
+
+const (
+	// FormatPNG attaches an image of the whole dashboard.
+	FormatPNG Format = "png"
+	// FormatPDF attaches a PDF document of the whole dashboard.
+	FormatPDF Format = "pdf"
+	// FormatCSV attaches the query results of every panel as CSV files.
+	FormatCSV Format = "csv"
+)
+
+func (f Format) IsValid() bool {
+	return f == FormatPNG || f == FormatPDF || f == FormatCSV
+}
+
+// Status is the result of sending a report.
+type Status string
+
+const (
+	StatusSent   Status = "sent"
+	StatusFailed Status = "failed"
+)
+
+// Report is a dashboard that is sent by email on a schedule. It is rendered with the
+// permissions of the user who last saved it.
+type Report struct {
+	ID           int64      `json:"id" xorm:"pk autoincr 'id'"`
+	UID          string     `json:"uid" xorm:"uid"`
+	OrgID        int64      `json:"orgId" xorm:"org_id"`
+	Name         string     `json:"name"`
+	DashboardUID string     `json:"dashboardUid" xorm:"dashboard_uid"`
+	TimeFrom     string     `json:"timeFrom"`
+	TimeTo       string     `json:"timeTo"`
+	Variables    Variables  `json:"variables"`
+	Schedule     string     `json:"schedule"`
+	Timezone     string     `json:"timezone"`
+	Recipients   Recipients `json:"recipients"`
+	Format       Format     `json:"format"`
+	Message      string     `json:"message"`
+	Enabled      bool       `json:"enabled"`
+	UserID       int64      `json:"userId" xorm:"user_id"`
+	Created      time.Time  `json:"created"`
+	Updated      time.Time  `json:"updated"`
+	NextRunAt    *time.Time `json:"nextRunAt,omitempty" xorm:"next_run_at"`
+	LastRunAt    *time.Time `json:"lastRunAt,omitempty" xorm:"last_run_at"`
+}
+
+func (Report) TableName() string {
+	return "scheduled_report"
+}
+
+// ReportHistory is an entry of the delivery history of a report
+type ReportHistory struct {
+	ID         int64      `json:"id" xorm:"pk autoincr 'id'"`
+	ReportID   int64      `json:"reportId" xorm:"report_id"`
+	OrgID      int64      `json:"-" xorm:"org_id"`
+	SentAt     time.Time  `json:"sentAt"`
+	SentBy     int64      `json:"sentBy"`
+	Status     Status     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	Recipients Recipients `json:"recipients"`
+}
+
+func (ReportHistory) TableName() string {
+	return "scheduled_report_history"
+}
+
+// Variables are the values of the dashboard template variables by variable name
+type Variables map[string][]string
+
+// FromDB is called by xorm when reading the column
+func (v *Variables) FromDB(data []byte) error {
+	*v = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ToDB is called by xorm when writing the column
+func (v *Variables) ToDB() ([]byte, error) {
+	if *v == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(v)
+}
+
+// Recipients are the email addresses a report is sent to
+type Recipients []string
+
+// FromDB is called by xorm when reading the column
+func (r *Recipients) FromDB(data []byte) error {
+	*r = nil
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, r)
+}
+
+// ToDB is called by xorm when writing the column
+func (r *Recipients) ToDB() ([]byte, error) {
+	if *r == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(r)
+}
+
+// ReportSettings are the user editable properties of a report
+type ReportSettings struct {
+	// required: true
+	Name string `json:"name"`
+	// required: true
+	DashboardUID string `json:"dashboardUid"`
+	// Start of the time range, absolute or relative. Defaults to the dashboard time range.
+	TimeFrom string `json:"timeFrom"`
+	// End of the time range, absolute or relative. Defaults to the dashboard time range.
+	TimeTo string `json:"timeTo"`
+	// Template variable values by variable name. Variables that are not set keep their saved value.
+	Variables Variables `json:"variables"`
+	// Cron expression with five fields, or one of @hourly, @daily, @weekly or @monthly
+	// required: true
+	Schedule string `json:"schedule"`
+	// IANA time zone the schedule is evaluated in. Defaults to UTC.
+	Timezone string `json:"timezone"`
+	// required: true
+	Recipients []string `json:"recipients"`
+	// One of png, pdf or csv. Defaults to png.
+	Format Format `json:"format"`
+	// Message included in the body of the email
+	Message string `json:"message"`
+	// Disabled reports are not sent by the scheduler
+	Enabled bool `json:"enabled"`
+}
+
+type CreateReportCommand struct {
+	ReportSettings
+	OrgID  int64 `json:"-"`
+	UserID int64 `json:"-"`
+}
+
+type UpdateReportCommand struct {
+	ReportSettings
+	UID    string `json:"-"`
+	OrgID  int64  `json:"-"`
+	UserID int64  `json:"-"`
+}
+
+type DeleteReportCommand struct {
+	UID   string
+	OrgID int64
+}
+
+type GetReportQuery struct {
+	UID   string
+	OrgID int64
+}
+
+type SearchReportsQuery struct {
+	OrgID        int64
+	DashboardUID string
+}
+
+type GetReportHistoryQuery struct {
+	ReportUID string
+	OrgID     int64
+	Limit     int
+}
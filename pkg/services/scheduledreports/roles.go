@@ -0,0 +1,42 @@
+package scheduledreports
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+var (
+	reportsReaderRole = accesscontrol.RoleDTO{
+		Name:        "fixed:scheduled-reports:reader",
+		DisplayName: "Scheduled reports reader",
+		Description: "Read the scheduled reports of the organization and their history",
+		Group:       "Scheduled reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionReportsRead},
+		},
+	}
+
+	reportsWriterRole = accesscontrol.RoleDTO{
+		Name:        "fixed:scheduled-reports:writer",
+		DisplayName: "Scheduled reports writer",
+		Description: "Create, update, delete and send the scheduled reports of the organization",
+		Group:       "Scheduled reports",
+		Permissions: []accesscontrol.Permission{
+			{Action: ActionReportsRead},
+			{Action: ActionReportsWrite},
+		},
+	}
+)
+
+func declareFixedRoles(ac accesscontrol.Service) error {
+	return ac.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{
+			Role:   reportsReaderRole,
+			Grants: []string{string(org.RoleViewer)},
+		},
+		accesscontrol.RoleRegistration{
+			Role:   reportsWriterRole,
+			Grants: []string{string(org.RoleEditor)},
+		},
+	)
+}
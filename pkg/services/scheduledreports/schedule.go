@@ -0,0 +1,99 @@
+package scheduledreports
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// nextRun returns the first time the report is due after the given time.
+func nextRun(schedule, timezone string, after time.Time) (time.Time, error) {
+	spec, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return time.Time{}, ErrInvalidSchedule.Errorf("failed to parse schedule %q: %w", schedule, err)
+	}
+
+	loc, err := loadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	next := spec.Next(after.In(loc))
+	if next.IsZero() {
+		return time.Time{}, ErrInvalidSchedule.Errorf("schedule %q never runs", schedule)
+	}
+	return next.UTC(), nil
+}
+
+func loadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, ErrInvalidTimezone.Errorf("failed to load timezone %q: %w", timezone, err)
+	}
+	return loc, nil
+}
+
+// validateSettings checks the settings of a report and sets the defaults of the optional ones.
+func validateSettings(settings *ReportSettings) error {
+	settings.Name = strings.TrimSpace(settings.Name)
+	if settings.Name == "" {
+		return ErrInvalidName.Errorf("report name is empty")
+	}
+	if settings.DashboardUID == "" {
+		return ErrInvalidDashboard.Errorf("dashboard uid is empty")
+	}
+
+	if settings.Format == "" {
+		settings.Format = FormatPNG
+	}
+	if !settings.Format.IsValid() {
+		return ErrInvalidFormat.Errorf("invalid format %q", settings.Format)
+	}
+
+	if _, err := nextRun(settings.Schedule, settings.Timezone, time.Now()); err != nil {
+		return err
+	}
+
+	if settings.TimeFrom != "" || settings.TimeTo != "" {
+		if settings.TimeFrom == "" || settings.TimeTo == "" {
+			return ErrInvalidTimeRange.Errorf("both from and to must be set to override the dashboard time range")
+		}
+		timeRange := legacydata.NewDataTimeRange(settings.TimeFrom, settings.TimeTo)
+		from, err := timeRange.ParseFrom()
+		if err != nil {
+			return ErrInvalidTimeRange.Errorf("invalid from time %q: %w", settings.TimeFrom, err)
+		}
+		to, err := timeRange.ParseTo()
+		if err != nil {
+			return ErrInvalidTimeRange.Errorf("invalid to time %q: %w", settings.TimeTo, err)
+		}
+		if !from.Before(to) {
+			return ErrInvalidTimeRange.Errorf("from must be before to")
+		}
+	}
+
+	recipients := make([]string, 0, len(settings.Recipients))
+	for _, recipient := range settings.Recipients {
+		recipient = strings.TrimSpace(recipient)
+		if recipient == "" {
+			continue
+		}
+		if _, err := mail.ParseAddress(recipient); err != nil {
+			return ErrInvalidRecipients.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		recipients = append(recipients, recipient)
+	}
+	if len(recipients) == 0 {
+		return ErrInvalidRecipients.Errorf("report has no recipients")
+	}
+	settings.Recipients = recipients
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package scheduledreports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextRun(t *testing.T) {
+	// a Sunday
+	now := time.Date(2024, 6, 2, 12, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		schedule string
+		timezone string
+		expected time.Time
+	}{
+		{name: "every hour", schedule: "0 * * * *", expected: time.Date(2024, 6, 2, 13, 0, 0, 0, time.UTC)},
+		{name: "descriptor", schedule: "@daily", expected: time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{name: "monday morning", schedule: "0 9 * * 1", expected: time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{name: "monday morning in a timezone", schedule: "0 9 * * 1", timezone: "Europe/Paris", expected: time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next, err := nextRun(tc.schedule, tc.timezone, now)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, next)
+		})
+	}
+
+	t.Run("invalid schedule", func(t *testing.T) {
+		_, err := nextRun("every monday", "", now)
+		require.ErrorIs(t, err, ErrInvalidSchedule)
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		_, err := nextRun("@daily", "Mars/Olympus_Mons", now)
+		require.ErrorIs(t, err, ErrInvalidTimezone)
+	})
+}
+
+func TestValidateSettings(t *testing.T) {
+	valid := func() ReportSettings {
+		return ReportSettings{
+			Name:         "Weekly report",
+			DashboardUID: "dash",
+			Schedule:     "0 9 * * 1",
+			Recipients:   []string{"a@example.com", " ", "Team <team@example.com>"},
+		}
+	}
+
+	t.Run("sets defaults and removes empty recipients", func(t *testing.T) {
+		settings := valid()
+		require.NoError(t, validateSettings(&settings))
+		assert.Equal(t, FormatPNG, settings.Format)
+		assert.Equal(t, []string{"a@example.com", "Team <team@example.com>"}, settings.Recipients)
+	})
+
+	testCases := []struct {
+		name     string
+		modify   func(s *ReportSettings)
+		expected error
+	}{
+		{name: "missing name", modify: func(s *ReportSettings) { s.Name = " " }, expected: ErrInvalidName},
+		{name: "missing dashboard", modify: func(s *ReportSettings) { s.DashboardUID = "" }, expected: ErrInvalidDashboard},
+		{name: "invalid format", modify: func(s *ReportSettings) { s.Format = "docx" }, expected: ErrInvalidFormat},
+		{name: "invalid schedule", modify: func(s *ReportSettings) { s.Schedule = "" }, expected: ErrInvalidSchedule},
+		{name: "invalid timezone", modify: func(s *ReportSettings) { s.Timezone = "Nowhere" }, expected: ErrInvalidTimezone},
+		{name: "partial time range", modify: func(s *ReportSettings) { s.TimeFrom = "now-7d" }, expected: ErrInvalidTimeRange},
+		{name: "inverted time range", modify: func(s *ReportSettings) { s.TimeFrom, s.TimeTo = "now", "now-7d" }, expected: ErrInvalidTimeRange},
+		{name: "no recipients", modify: func(s *ReportSettings) { s.Recipients = []string{""} }, expected: ErrInvalidRecipients},
+		{name: "invalid recipient", modify: func(s *ReportSettings) { s.Recipients = []string{"not an email"} }, expected: ErrInvalidRecipients},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			settings := valid()
+			tc.modify(&settings)
+			require.ErrorIs(t, validateSettings(&settings), tc.expected)
+		})
+	}
+}
+
+func TestFramesToCSV(t *testing.T) {
+	first := data.NewFrame("",
+		data.NewField("time", nil, []time.Time{time.Date(2024, 6, 2, 12, 0, 0, 0, time.UTC)}),
+		data.NewField("value", data.Labels{"host": "a"}, []*float64{nil}),
+	)
+	second := data.NewFrame("",
+		data.NewField("name", nil, []string{"b,c"}),
+		data.NewField("count", nil, []int64{3}),
+	)
+
+	content, err := framesToCSV(data.Frames{first, second})
+	require.NoError(t, err)
+	assert.Equal(t, "time,value {host=a}\n2024-06-02T12:00:00Z,\n\nname,count\n\"b,c\",3\n", string(content))
+}
@@ -0,0 +1,247 @@
+package scheduledreports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type Service interface {
+	CreateReport(ctx context.Context, cmd *CreateReportCommand) (*Report, error)
+	UpdateReport(ctx context.Context, cmd *UpdateReportCommand) (*Report, error)
+	DeleteReport(ctx context.Context, cmd *DeleteReportCommand) error
+	GetReport(ctx context.Context, query *GetReportQuery) (*Report, error)
+	SearchReports(ctx context.Context, query *SearchReportsQuery) ([]*Report, error)
+	// SendReport sends a report immediately without changing its schedule
+	SendReport(ctx context.Context, query *GetReportQuery, sentBy int64) (*ReportHistory, error)
+	GetReportHistory(ctx context.Context, query *GetReportHistoryQuery) ([]*ReportHistory, error)
+}
+
+// Renderer renders dashboards to images and PDF documents, it is implemented by rendering.Service
+type Renderer interface {
+	Render(ctx context.Context, renderType rendering.RenderType, opts rendering.Opts, session rendering.Session) (*rendering.RenderResult, error)
+	IsCapabilitySupported(ctx context.Context, capability rendering.CapabilityName) error
+}
+
+type ScheduledReportsService struct {
+	cfg              *setting.Cfg
+	store            *sqlStore
+	serverLock       *serverlock.ServerLockService
+	renderer         Renderer
+	emailSender      notifications.EmailSender
+	dashboardService dashboards.DashboardService
+	userService      user.Service
+	queryService     query.Service
+	accessControl    accesscontrol.AccessControl
+	acService        accesscontrol.Service
+	routeRegister    routing.RouteRegister
+	log              log.Logger
+	now              func() time.Time
+	tick             time.Duration
+}
+
+func ProvideService(cfg *setting.Cfg, db db.DB, routeRegister routing.RouteRegister, serverLock *serverlock.ServerLockService,
+	renderService rendering.Service, emailSender notifications.EmailSender, dashboardService dashboards.DashboardService,
+	userService user.Service, queryService query.Service, accessControl accesscontrol.AccessControl,
+	accesscontrolService accesscontrol.Service) (*ScheduledReportsService, error) {
+	s := &ScheduledReportsService{
+		cfg:              cfg,
+		store:            &sqlStore{db: db},
+		serverLock:       serverLock,
+		renderer:         renderService,
+		emailSender:      emailSender,
+		dashboardService: dashboardService,
+		userService:      userService,
+		queryService:     queryService,
+		accessControl:    accessControl,
+		acService:        accesscontrolService,
+		routeRegister:    routeRegister,
+		log:              log.New("scheduledreports"),
+		now:              time.Now,
+		tick:             time.Minute,
+	}
+
+	if !cfg.ScheduledReportsEnabled {
+		return s, nil
+	}
+
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+	s.registerAPIEndpoints()
+
+	return s, nil
+}
+
+// Run sends the reports that are due every minute until the context is cancelled.
+func (s *ScheduledReportsService) Run(ctx context.Context) error {
+	if !s.cfg.ScheduledReportsEnabled {
+		return nil
+	}
+
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.sendDueReports(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *ScheduledReportsService) CreateReport(ctx context.Context, cmd *CreateReportCommand) (*Report, error) {
+	if err := validateSettings(&cmd.ReportSettings); err != nil {
+		return nil, err
+	}
+	if err := s.checkDashboardExists(ctx, cmd.OrgID, cmd.DashboardUID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	report := &Report{
+		UID:     util.GenerateShortUID(),
+		OrgID:   cmd.OrgID,
+		UserID:  cmd.UserID,
+		Created: now,
+	}
+	if err := applySettings(report, cmd.ReportSettings, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.insert(ctx, report); err != nil {
+		return nil, ErrInternal.Errorf("failed to create report: %w", err)
+	}
+	return report, nil
+}
+
+func (s *ScheduledReportsService) UpdateReport(ctx context.Context, cmd *UpdateReportCommand) (*Report, error) {
+	if err := validateSettings(&cmd.ReportSettings); err != nil {
+		return nil, err
+	}
+
+	report, err := s.store.get(ctx, cmd.OrgID, cmd.UID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDashboardExists(ctx, cmd.OrgID, cmd.DashboardUID); err != nil {
+		return nil, err
+	}
+
+	report.UserID = cmd.UserID
+	if err := applySettings(report, cmd.ReportSettings, s.now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.store.update(ctx, report); err != nil {
+		return nil, ErrInternal.Errorf("failed to update report: %w", err)
+	}
+	return report, nil
+}
+
+func (s *ScheduledReportsService) DeleteReport(ctx context.Context, cmd *DeleteReportCommand) error {
+	return s.store.delete(ctx, cmd.OrgID, cmd.UID)
+}
+
+func (s *ScheduledReportsService) GetReport(ctx context.Context, query *GetReportQuery) (*Report, error) {
+	return s.store.get(ctx, query.OrgID, query.UID)
+}
+
+func (s *ScheduledReportsService) SearchReports(ctx context.Context, query *SearchReportsQuery) ([]*Report, error) {
+	return s.store.search(ctx, query)
+}
+
+func (s *ScheduledReportsService) SendReport(ctx context.Context, query *GetReportQuery, sentBy int64) (*ReportHistory, error) {
+	report, err := s.store.get(ctx, query.OrgID, query.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	entry := newHistoryEntry(report, now, sentBy, s.send(ctx, report))
+	report.LastRunAt = &now
+	if err := s.store.recordRun(ctx, report, entry); err != nil {
+		return nil, ErrInternal.Errorf("failed to record report history: %w", err)
+	}
+	return entry, nil
+}
+
+func (s *ScheduledReportsService) GetReportHistory(ctx context.Context, query *GetReportHistoryQuery) ([]*ReportHistory, error) {
+	report, err := s.store.get(ctx, query.OrgID, query.ReportUID)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+	return s.store.history(ctx, report.ID, limit)
+}
+
+func (s *ScheduledReportsService) checkDashboardExists(ctx context.Context, orgID int64, uid string) error {
+	_, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: uid, OrgID: orgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return ErrDashboardNotFound.Errorf("dashboard %s not found", uid)
+		}
+		return ErrInternal.Errorf("failed to get dashboard %s: %w", uid, err)
+	}
+	return nil
+}
+
+// applySettings copies validated settings to the report and schedules its next run
+func applySettings(report *Report, settings ReportSettings, now time.Time) error {
+	report.Name = settings.Name
+	report.DashboardUID = settings.DashboardUID
+	report.TimeFrom = settings.TimeFrom
+	report.TimeTo = settings.TimeTo
+	report.Variables = settings.Variables
+	report.Schedule = settings.Schedule
+	report.Timezone = settings.Timezone
+	report.Recipients = settings.Recipients
+	report.Format = settings.Format
+	report.Message = settings.Message
+	report.Enabled = settings.Enabled
+	report.Updated = now
+
+	report.NextRunAt = nil
+	if report.Enabled {
+		next, err := nextRun(report.Schedule, report.Timezone, now)
+		if err != nil {
+			return err
+		}
+		report.NextRunAt = &next
+	}
+	return nil
+}
+
+func newHistoryEntry(report *Report, sentAt time.Time, sentBy int64, sendErr error) *ReportHistory {
+	entry := &ReportHistory{
+		ReportID:   report.ID,
+		OrgID:      report.OrgID,
+		SentAt:     sentAt,
+		SentBy:     sentBy,
+		Status:     StatusSent,
+		Recipients: report.Recipients,
+	}
+	if sendErr != nil {
+		entry.Status = StatusFailed
+		entry.Error = sendErr.Error()
+	}
+	return entry
+}
@@ -0,0 +1,368 @@
+package scheduledreports
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+const testDashboard = `{
+	"uid": "dash",
+	"title": "Weekly overview",
+	"time": {"from": "now-7d", "to": "now"},
+	"panels": [
+		{"id": 1, "title": "Requests", "type": "timeseries", "datasource": {"type": "prometheus", "uid": "prom"}, "targets": [{"refId": "A", "expr": "up"}]},
+		{"id": 2, "type": "text"}
+	]
+}`
+
+type fakeRenderer struct {
+	dir      string
+	err      error
+	requests []rendering.Opts
+}
+
+func (r *fakeRenderer) Render(_ context.Context, renderType rendering.RenderType, opts rendering.Opts, _ rendering.Session) (*rendering.RenderResult, error) {
+	r.requests = append(r.requests, opts)
+	if r.err != nil {
+		return nil, r.err
+	}
+	filePath := filepath.Join(r.dir, "dashboard."+string(renderType))
+	if err := os.WriteFile(filePath, []byte("rendered "+string(renderType)), 0600); err != nil {
+		return nil, err
+	}
+	return &rendering.RenderResult{FilePath: filePath}, nil
+}
+
+func (r *fakeRenderer) IsCapabilitySupported(_ context.Context, _ rendering.CapabilityName) error {
+	return nil
+}
+
+type fakeEmailSender struct {
+	sent []*notifications.SendEmailCommandSync
+}
+
+func (s *fakeEmailSender) SendEmailCommandHandlerSync(_ context.Context, cmd *notifications.SendEmailCommandSync) error {
+	s.sent = append(s.sent, cmd)
+	return nil
+}
+
+type testEnv struct {
+	service   *ScheduledReportsService
+	renderer  *fakeRenderer
+	email     *fakeEmailSender
+	queries   *query.FakeQueryService
+	acService *actest.FakeService
+	now       time.Time
+}
+
+func setupTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.ScheduledReportsEnabled = true
+	cfg.AppURL = "http://localhost:3000/"
+
+	dash, err := simplejson.NewJson([]byte(testDashboard))
+	require.NoError(t, err)
+	dashboardService := dashboards.NewFakeDashboardService(t)
+	dashboardService.On("GetDashboard", mock.Anything, mock.MatchedBy(func(q *dashboards.GetDashboardQuery) bool {
+		return q.UID == "dash"
+	})).Return(&dashboards.Dashboard{UID: "dash", OrgID: 1, Title: "Weekly overview", Slug: "weekly-overview", Data: dash}, nil).Maybe()
+	dashboardService.On("GetDashboard", mock.Anything, mock.Anything).Return(nil, dashboards.ErrDashboardNotFound).Maybe()
+
+	env := &testEnv{
+		renderer: &fakeRenderer{dir: t.TempDir()},
+		email:    &fakeEmailSender{},
+		queries:  query.NewFakeQueryService(t),
+		// the owner of the reports can read the dashboard and query its data source
+		acService: &actest.FakeService{ExpectedPermissions: []accesscontrol.Permission{
+			{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID("dash")},
+			{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID("prom")},
+		}},
+		now: time.Date(2024, 6, 2, 12, 30, 0, 0, time.UTC),
+	}
+	// the user service does not load the permissions of the owner, like in production
+	userService := &usertest.FakeUserService{ExpectedSignedInUser: &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleEditor}}
+
+	env.service, err = ProvideService(cfg, sqlStore, routing.NewRouteRegister(), serverlock.ProvideService(sqlStore, tracing.InitializeTracerForTest()),
+		nil, env.email, dashboardService, userService, env.queries, acimpl.ProvideAccessControl(featuremgmt.WithFeatures()), env.acService)
+	require.NoError(t, err)
+	env.service.renderer = env.renderer
+	env.service.now = func() time.Time { return env.now }
+	return env
+}
+
+func (env *testEnv) createReport(t *testing.T, settings ReportSettings) *Report {
+	t.Helper()
+	report, err := env.service.CreateReport(context.Background(), &CreateReportCommand{ReportSettings: settings, OrgID: 1, UserID: 2})
+	require.NoError(t, err)
+	return report
+}
+
+func weeklyReport() ReportSettings {
+	return ReportSettings{
+		Name:         "Weekly overview",
+		DashboardUID: "dash",
+		Variables:    Variables{"host": {"a", "b"}},
+		Schedule:     "0 9 * * 1",
+		Timezone:     "Europe/Paris",
+		Recipients:   []string{"team@example.com", "boss@example.com"},
+		Format:       FormatPDF,
+		Enabled:      true,
+	}
+}
+
+func TestIntegrationReports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	env := setupTestEnv(t)
+	ctx := context.Background()
+
+	report := env.createReport(t, weeklyReport())
+	require.NotEmpty(t, report.UID)
+	require.NotNil(t, report.NextRunAt)
+	assert.Equal(t, time.Date(2024, 6, 3, 7, 0, 0, 0, time.UTC), report.NextRunAt.UTC())
+
+	t.Run("rejects reports of unknown dashboards", func(t *testing.T) {
+		settings := weeklyReport()
+		settings.DashboardUID = "unknown"
+		_, err := env.service.CreateReport(ctx, &CreateReportCommand{ReportSettings: settings, OrgID: 1, UserID: 2})
+		require.ErrorIs(t, err, ErrDashboardNotFound)
+	})
+
+	t.Run("gets and searches reports", func(t *testing.T) {
+		stored, err := env.service.GetReport(ctx, &GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, report.Name, stored.Name)
+		assert.Equal(t, Variables{"host": {"a", "b"}}, stored.Variables)
+		assert.Equal(t, Recipients{"team@example.com", "boss@example.com"}, stored.Recipients)
+
+		_, err = env.service.GetReport(ctx, &GetReportQuery{UID: report.UID, OrgID: 2})
+		require.ErrorIs(t, err, ErrReportNotFound)
+
+		found, err := env.service.SearchReports(ctx, &SearchReportsQuery{OrgID: 1, DashboardUID: "dash"})
+		require.NoError(t, err)
+		require.Len(t, found, 1)
+
+		found, err = env.service.SearchReports(ctx, &SearchReportsQuery{OrgID: 1, DashboardUID: "other"})
+		require.NoError(t, err)
+		require.Empty(t, found)
+	})
+
+	t.Run("disabling a report clears its next run", func(t *testing.T) {
+		settings := weeklyReport()
+		settings.Enabled = false
+		updated, err := env.service.UpdateReport(ctx, &UpdateReportCommand{ReportSettings: settings, UID: report.UID, OrgID: 1, UserID: 3})
+		require.NoError(t, err)
+		assert.Nil(t, updated.NextRunAt)
+		assert.Equal(t, int64(3), updated.UserID)
+
+		due, err := env.service.store.findDue(ctx, env.now.Add(30*24*time.Hour))
+		require.NoError(t, err)
+		require.Empty(t, due)
+	})
+
+	t.Run("sends a report on demand", func(t *testing.T) {
+		entry, err := env.service.SendReport(ctx, &GetReportQuery{UID: report.UID, OrgID: 1}, 3)
+		require.NoError(t, err)
+		assert.Equal(t, StatusSent, entry.Status)
+		assert.Equal(t, int64(3), entry.SentBy)
+
+		require.Len(t, env.email.sent, 1)
+		cmd := env.email.sent[0]
+		assert.Equal(t, []string{"team@example.com", "boss@example.com"}, cmd.To)
+		assert.Equal(t, "scheduled_report", cmd.Template)
+		assert.Equal(t, "Weekly overview", cmd.Data["DashboardTitle"])
+		assert.Equal(t, "http://localhost:3000/d/dash/weekly-overview?orgId=1&timezone=Europe%2FParis&var-host=a&var-host=b", cmd.Data["DashboardURL"])
+		require.Len(t, cmd.AttachedFiles, 1)
+		assert.Equal(t, "Weekly overview.pdf", cmd.AttachedFiles[0].Name)
+		assert.Equal(t, "rendered pdf", string(cmd.AttachedFiles[0].Content))
+
+		require.Len(t, env.renderer.requests, 1)
+		opts := env.renderer.requests[0]
+		assert.Equal(t, int64(2), opts.UserID)
+		assert.Equal(t, org.RoleEditor, opts.OrgRole)
+		assert.True(t, strings.HasPrefix(opts.Path, "d/dash/weekly-overview?"))
+		assert.Contains(t, opts.Path, "kiosk=1")
+		assert.Equal(t, fullHeight, opts.Height)
+
+		history, err := env.service.GetReportHistory(ctx, &GetReportHistoryQuery{ReportUID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, Recipients{"team@example.com", "boss@example.com"}, history[0].Recipients)
+	})
+
+	t.Run("deleting a report removes its history", func(t *testing.T) {
+		require.NoError(t, env.service.DeleteReport(ctx, &DeleteReportCommand{UID: report.UID, OrgID: 1}))
+		_, err := env.service.GetReport(ctx, &GetReportQuery{UID: report.UID, OrgID: 1})
+		require.ErrorIs(t, err, ErrReportNotFound)
+
+		history, err := env.service.store.history(ctx, report.ID, 10)
+		require.NoError(t, err)
+		require.Empty(t, history)
+
+		require.ErrorIs(t, env.service.DeleteReport(ctx, &DeleteReportCommand{UID: report.UID, OrgID: 1}), ErrReportNotFound)
+	})
+}
+
+func TestIntegrationSendDueReports(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	t.Run("sends due reports once and schedules the next run", func(t *testing.T) {
+		env := setupTestEnv(t)
+		report := env.createReport(t, weeklyReport())
+
+		// not due yet
+		env.service.sendDueReports(ctx)
+		require.Empty(t, env.email.sent)
+
+		env.now = time.Date(2024, 6, 3, 7, 0, 30, 0, time.UTC)
+		env.service.sendDueReports(ctx)
+		require.Len(t, env.email.sent, 1)
+
+		// the next run is a week later
+		env.service.sendDueReports(ctx)
+		require.Len(t, env.email.sent, 1)
+
+		stored, err := env.service.GetReport(ctx, &GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 6, 10, 7, 0, 0, 0, time.UTC), stored.NextRunAt.UTC())
+		assert.Equal(t, env.now, stored.LastRunAt.UTC())
+
+		history, err := env.service.GetReportHistory(ctx, &GetReportHistoryQuery{ReportUID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, StatusSent, history[0].Status)
+		assert.Equal(t, int64(0), history[0].SentBy)
+	})
+
+	t.Run("skips reports locked by another instance", func(t *testing.T) {
+		env := setupTestEnv(t)
+		report := env.createReport(t, weeklyReport())
+		env.now = time.Date(2024, 6, 3, 7, 0, 30, 0, time.UTC)
+
+		err := env.service.serverLock.LockExecuteAndRelease(ctx, "send scheduled report "+report.UID, time.Minute, func(ctx context.Context) {
+			env.service.sendDueReports(ctx)
+		})
+		require.NoError(t, err)
+		require.Empty(t, env.email.sent)
+
+		// the lock is released, the report is still due
+		env.service.sendDueReports(ctx)
+		require.Len(t, env.email.sent, 1)
+	})
+
+	t.Run("records failures and still schedules the next run", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.renderer.err = errors.New("renderer unavailable")
+		report := env.createReport(t, weeklyReport())
+
+		env.now = time.Date(2024, 6, 3, 7, 0, 30, 0, time.UTC)
+		env.service.sendDueReports(ctx)
+		require.Empty(t, env.email.sent)
+
+		history, err := env.service.GetReportHistory(ctx, &GetReportHistoryQuery{ReportUID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, StatusFailed, history[0].Status)
+		assert.Contains(t, history[0].Error, "renderer unavailable")
+
+		stored, err := env.service.GetReport(ctx, &GetReportQuery{UID: report.UID, OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2024, 6, 10, 7, 0, 0, 0, time.UTC), stored.NextRunAt.UTC())
+	})
+}
+
+func TestIntegrationSendCSVReport(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	env := setupTestEnv(t)
+	settings := weeklyReport()
+	settings.Format = FormatCSV
+	report := env.createReport(t, settings)
+
+	ownerCanQuery := mock.MatchedBy(func(owner *user.SignedInUser) bool {
+		return len(owner.Permissions[1][datasources.ActionQuery]) == 1
+	})
+	env.queries.On("QueryData", mock.Anything, ownerCanQuery, false, mock.Anything).Return(&backend.QueryDataResponse{
+		Responses: backend.Responses{
+			"A": backend.DataResponse{Frames: data.Frames{data.NewFrame("", data.NewField("value", nil, []float64{1.5}))}},
+		},
+	}, nil).Once()
+
+	entry, err := env.service.SendReport(context.Background(), &GetReportQuery{UID: report.UID, OrgID: 1}, 2)
+	require.NoError(t, err)
+	require.Equal(t, StatusSent, entry.Status, entry.Error)
+
+	require.Empty(t, env.renderer.requests)
+	require.Len(t, env.email.sent, 1)
+	attachments := env.email.sent[0].AttachedFiles
+	require.Len(t, attachments, 1)
+	assert.Equal(t, "Weekly overview-Requests.csv", attachments[0].Name)
+	assert.Equal(t, "value\n1.5\n", string(attachments[0].Content))
+}
+
+func TestIntegrationSendReportOwnerPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	env := setupTestEnv(t)
+	report := env.createReport(t, weeklyReport())
+
+	// the owner lost access to the dashboard since the report was saved
+	env.acService.ExpectedPermissions = []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: dashboards.ScopeDashboardsProvider.GetResourceScopeUID("other")},
+	}
+
+	entry, err := env.service.SendReport(context.Background(), &GetReportQuery{UID: report.UID, OrgID: 1}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, entry.Status)
+	assert.Contains(t, entry.Error, "cannot read dashboard dash")
+	require.Empty(t, env.renderer.requests)
+	require.Empty(t, env.email.sent)
+}
@@ -0,0 +1,83 @@
+package scheduledreports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+)
+
+// lockTimeout is how long a report lock is held at most, in case the instance sending it dies.
+// It must be longer than it can take to render and send a report.
+const lockTimeout = 15 * time.Minute
+
+// sendDueReports sends every report whose next run is due. Each report is sent under its own
+// server lock so that in a high availability setup a report is sent by one instance only.
+func (s *ScheduledReportsService) sendDueReports(ctx context.Context) {
+	logger := s.log.FromContext(ctx)
+
+	due, err := s.store.findDue(ctx, s.now())
+	if err != nil {
+		logger.Error("Failed to find the reports to send", "error", err)
+		return
+	}
+
+	for _, report := range due {
+		if ctx.Err() != nil {
+			return
+		}
+
+		reportID := report.ID
+		err := s.serverLock.LockExecuteAndRelease(ctx, "send scheduled report "+report.UID, lockTimeout, func(ctx context.Context) {
+			s.sendScheduledReport(ctx, reportID)
+		})
+		if err != nil {
+			var lockErr *serverlock.ServerLockExistsError
+			if errors.As(err, &lockErr) {
+				logger.Debug("Report is being sent by another instance", "reportUid", report.UID)
+				continue
+			}
+			logger.Error("Failed to lock report", "reportUid", report.UID, "error", err)
+		}
+	}
+}
+
+// sendScheduledReport sends a report and schedules its next run. It must be called with the lock of the report held.
+func (s *ScheduledReportsService) sendScheduledReport(ctx context.Context, reportID int64) {
+	logger := s.log.FromContext(ctx)
+
+	// reload the report, another instance may have sent it while this one was waiting for the lock
+	report, err := s.store.getByID(ctx, reportID)
+	if err != nil {
+		if !errors.Is(err, ErrReportNotFound) {
+			logger.Error("Failed to get report", "reportId", reportID, "error", err)
+		}
+		return
+	}
+	now := s.now()
+	if !report.Enabled || report.NextRunAt == nil || report.NextRunAt.After(now) {
+		return
+	}
+
+	sendErr := s.send(ctx, report)
+	if sendErr != nil {
+		logger.Error("Failed to send report", "reportUid", report.UID, "error", sendErr)
+	} else {
+		logger.Info("Sent report", "reportUid", report.UID, "recipients", len(report.Recipients))
+	}
+
+	// missed runs are not caught up, the next run is always in the future
+	report.LastRunAt = &now
+	report.NextRunAt = nil
+	next, err := nextRun(report.Schedule, report.Timezone, now)
+	if err != nil {
+		logger.Error("Failed to schedule the next run of report", "reportUid", report.UID, "error", err)
+	} else {
+		report.NextRunAt = &next
+	}
+
+	if err := s.store.recordRun(ctx, report, newHistoryEntry(report, now, 0, sendErr)); err != nil {
+		logger.Error("Failed to record report history", "reportUid", report.UID, "error", err)
+	}
+}
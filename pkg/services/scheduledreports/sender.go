@@ -0,0 +1,321 @@
+package scheduledreports
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/notifications"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+const (
+	emailTemplate = "scheduled_report"
+	renderTimeout = 2 * time.Minute
+	// fullHeight renders the whole dashboard instead of the first screen only
+	fullHeight = -1
+)
+
+// send renders the dashboard of the report with the permissions of its owner and emails it to the recipients.
+func (s *ScheduledReportsService) send(ctx context.Context, report *Report) error {
+	dash, err := s.dashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: report.DashboardUID, OrgID: report.OrgID})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return ErrDashboardNotFound.Errorf("dashboard %s not found", report.DashboardUID)
+		}
+		return fmt.Errorf("failed to get dashboard %s: %w", report.DashboardUID, err)
+	}
+
+	owner, err := s.getOwner(ctx, report)
+	if err != nil {
+		return err
+	}
+
+	ok, err := s.accessControl.Evaluate(ctx, owner, accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dash.UID)))
+	if err != nil {
+		return fmt.Errorf("failed to evaluate the permissions of the owner of the report: %w", err)
+	}
+	if !ok {
+		return ErrAccessDenied.Errorf("owner of the report cannot read dashboard %s", dash.UID)
+	}
+
+	var attachments []*notifications.SendEmailAttachFile
+	switch report.Format {
+	case FormatCSV:
+		attachments, err = s.queryCSVAttachments(ctx, report, dash, owner)
+	default:
+		attachments, err = s.renderAttachments(ctx, report, dash, owner)
+	}
+	if err != nil {
+		return err
+	}
+
+	from, to := reportTimeRange(report, dash)
+	return s.emailSender.SendEmailCommandHandlerSync(ctx, &notifications.SendEmailCommandSync{
+		SendEmailCommand: notifications.SendEmailCommand{
+			To:       report.Recipients,
+			Template: emailTemplate,
+			Data: map[string]any{
+				"Name":           report.Name,
+				"Message":        report.Message,
+				"DashboardTitle": dash.Title,
+				"DashboardURL":   strings.TrimSuffix(s.cfg.AppURL, "/") + "/" + dashboardPath("d", report, dash, nil),
+				"From":           from,
+				"To":             to,
+			},
+			AttachedFiles: attachments,
+		},
+	})
+}
+
+// getOwner returns the owner of the report with their permissions in the organization of the report loaded,
+// the signed in user query does not load them and every permission check would be denied otherwise.
+func (s *ScheduledReportsService) getOwner(ctx context.Context, report *Report) (*user.SignedInUser, error) {
+	owner, err := s.userService.GetSignedInUser(ctx, &user.GetSignedInUserQuery{UserID: report.UserID, OrgID: report.OrgID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the owner of the report: %w", err)
+	}
+
+	permissions, err := s.acService.GetUserPermissions(ctx, owner, accesscontrol.Options{ReloadCache: false})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the permissions of the owner of the report: %w", err)
+	}
+	if owner.Permissions == nil {
+		owner.Permissions = make(map[int64]map[string][]string)
+	}
+	owner.Permissions[report.OrgID] = accesscontrol.GroupScopesByAction(permissions)
+
+	return owner, nil
+}
+
+// renderAttachments renders the whole dashboard as a PNG image or a PDF document.
+func (s *ScheduledReportsService) renderAttachments(ctx context.Context, report *Report, dash *dashboards.Dashboard, owner *user.SignedInUser) ([]*notifications.SendEmailAttachFile, error) {
+	renderType := rendering.RenderPNG
+	if report.Format == FormatPDF {
+		renderType = rendering.RenderPDF
+	}
+
+	height := s.cfg.RendererDefaultImageHeight
+	if err := s.renderer.IsCapabilitySupported(ctx, rendering.FullHeightImages); err == nil {
+		height = fullHeight
+	}
+
+	result, err := s.renderer.Render(ctx, renderType, rendering.Opts{
+		CommonOpts: rendering.CommonOpts{
+			TimeoutOpts: rendering.TimeoutOpts{Timeout: renderTimeout},
+			AuthOpts: rendering.AuthOpts{
+				OrgID:   owner.OrgID,
+				UserID:  owner.UserID,
+				OrgRole: owner.OrgRole,
+			},
+			Path:            dashboardPath("d", report, dash, url.Values{"kiosk": {"1"}}),
+			Timezone:        report.Timezone,
+			ConcurrentLimit: s.cfg.RendererConcurrentRequestLimit,
+		},
+		ErrorOpts: rendering.ErrorOpts{
+			ErrorConcurrentLimitReached: true,
+			ErrorRenderUnavailable:      true,
+		},
+		Width:             s.cfg.RendererDefaultImageWidth,
+		Height:            height,
+		DeviceScaleFactor: s.cfg.RendererDefaultImageScale,
+		Theme:             models.ThemeLight,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render dashboard: %w", err)
+	}
+
+	content, err := os.ReadFile(result.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered dashboard: %w", err)
+	}
+
+	return []*notifications.SendEmailAttachFile{{
+		Name:    fmt.Sprintf("%s.%s", attachmentName(dash.Title), report.Format),
+		Content: content,
+	}}, nil
+}
+
+// queryCSVAttachments runs the queries of every panel and attaches their results as one CSV file per panel.
+func (s *ScheduledReportsService) queryCSVAttachments(ctx context.Context, report *Report, dash *dashboards.Dashboard, owner *user.SignedInUser) ([]*notifications.SendEmailAttachFile, error) {
+	queryData := func(ctx context.Context, from, to string, queries []*simplejson.Json) (*backend.QueryDataResponse, error) {
+		return s.queryService.QueryData(ctx, owner, false, dtos.MetricRequest{From: from, To: to, Queries: queries})
+	}
+
+	panels, err := dashboardsnapshots.QueryDashboardPanels(ctx, dash.Data, dashboardsnapshots.GenerateDashboardSnapshotCommand{
+		DashboardUID: dash.UID,
+		From:         report.TimeFrom,
+		To:           report.TimeTo,
+		Variables:    report.Variables,
+	}, queryData)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := make([]*notifications.SendEmailAttachFile, 0, len(panels))
+	for _, panel := range panels {
+		content, err := framesToCSV(panel.Frames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write panel %d as CSV: %w", panel.ID, err)
+		}
+		name := panel.Title
+		if name == "" {
+			name = "panel-" + strconv.FormatInt(panel.ID, 10)
+		}
+		attachments = append(attachments, &notifications.SendEmailAttachFile{
+			Name:    fmt.Sprintf("%s-%s.csv", attachmentName(dash.Title), attachmentName(name)),
+			Content: content,
+		})
+	}
+	return attachments, nil
+}
+
+// dashboardPath returns the path of the dashboard with the time range and variables of the report
+func dashboardPath(prefix string, report *Report, dash *dashboards.Dashboard, extra url.Values) string {
+	params := url.Values{}
+	params.Set("orgId", strconv.FormatInt(report.OrgID, 10))
+	if report.TimeFrom != "" && report.TimeTo != "" {
+		params.Set("from", report.TimeFrom)
+		params.Set("to", report.TimeTo)
+	}
+	if report.Timezone != "" {
+		params.Set("timezone", report.Timezone)
+	}
+	for name, values := range report.Variables {
+		for _, value := range values {
+			params.Add("var-"+name, value)
+		}
+	}
+	for name, values := range extra {
+		params[name] = values
+	}
+
+	u := url.URL{Path: path.Join(prefix, dash.UID, dash.Slug), RawQuery: params.Encode()}
+	return u.String()
+}
+
+// reportTimeRange returns the absolute time range of the report formatted in its timezone
+func reportTimeRange(report *Report, dash *dashboards.Dashboard) (string, string) {
+	from, to := report.TimeFrom, report.TimeTo
+	if from == "" || to == "" {
+		from = dash.Data.GetPath("time", "from").MustString("now-6h")
+		to = dash.Data.GetPath("time", "to").MustString("now")
+	}
+
+	loc, err := loadLocation(report.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	timeRange := legacydata.NewDataTimeRange(from, to)
+	fromTime, err := timeRange.ParseFrom()
+	if err != nil {
+		return from, to
+	}
+	toTime, err := timeRange.ParseTo()
+	if err != nil {
+		return from, to
+	}
+
+	const layout = "2006-01-02 15:04 MST"
+	return fromTime.In(loc).Format(layout), toTime.In(loc).Format(layout)
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^\w\-. ]+`)
+
+func attachmentName(name string) string {
+	name = strings.TrimSpace(unsafeFileNameChars.ReplaceAllString(name, "_"))
+	if name == "" {
+		return "report"
+	}
+	return name
+}
+
+// framesToCSV writes every frame as a table with a header row, frames are separated by an empty line
+func framesToCSV(frames data.Frames) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	for i, frame := range frames {
+		if i > 0 {
+			if err := w.Write([]string{}); err != nil {
+				return nil, err
+			}
+		}
+
+		header := make([]string, len(frame.Fields))
+		for j, field := range frame.Fields {
+			header[j] = field.Name
+			if len(field.Labels) > 0 {
+				header[j] += " " + formatLabels(field.Labels)
+			}
+		}
+		if err := w.Write(header); err != nil {
+			return nil, err
+		}
+
+		rows, err := frame.RowLen()
+		if err != nil {
+			return nil, err
+		}
+		for row := 0; row < rows; row++ {
+			record := make([]string, len(frame.Fields))
+			for j, field := range frame.Fields {
+				record[j] = formatValue(field, row)
+			}
+			if err := w.Write(record); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func formatLabels(labels data.Labels) string {
+	pairs := make([]string, 0, len(labels))
+	for name, value := range labels {
+		pairs = append(pairs, name+"="+value)
+	}
+	sort.Strings(pairs)
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+func formatValue(field *data.Field, row int) string {
+	value, ok := field.ConcreteAt(row)
+	if !ok {
+		return ""
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,373 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+// directory is where SCIM resources are stored, scoped to the organization of the requester
+type directory interface {
+	users(ctx context.Context, requester identity.Requester) ([]*userRecord, error)
+	userByLogin(ctx context.Context, requester identity.Requester, login string) (*userRecord, error)
+	user(ctx context.Context, requester identity.Requester, id int64) (*userRecord, error)
+	// createUser creates a user, or adds an existing user that isn't a member of the organization yet. Creating a
+	// user requires users:create, adding an existing user only the org.users:add permission of the route.
+	createUser(ctx context.Context, requester identity.Requester, u *userRecord) (*userRecord, error)
+	updateUser(ctx context.Context, requester identity.Requester, u *userRecord) error
+	removeUser(ctx context.Context, requester identity.Requester, id int64) error
+
+	teams(ctx context.Context, requester identity.Requester, name string) ([]*teamRecord, error)
+	team(ctx context.Context, requester identity.Requester, id int64) (*teamRecord, error)
+	teamMembers(ctx context.Context, requester identity.Requester, teamID int64) ([]*memberRecord, error)
+	createTeam(ctx context.Context, requester identity.Requester, name string) (*teamRecord, error)
+	renameTeam(ctx context.Context, requester identity.Requester, id int64, name string) error
+	deleteTeam(ctx context.Context, requester identity.Requester, id int64) error
+	// addTeamMember and removeTeamMember manage the Member permission of a team, team admins are left unchanged
+	addTeamMember(ctx context.Context, requester identity.Requester, teamID, userID int64) error
+	removeTeamMember(ctx context.Context, requester identity.Requester, teamID, userID int64) error
+}
+
+type serviceDirectory struct {
+	accessControl   accesscontrol.AccessControl
+	userService     user.Service
+	orgService      org.Service
+	teamService     team.Service
+	teamPermissions accesscontrol.TeamPermissionsService
+	sessionService  auth.UserTokenService
+	// orgRole is the role of users created or added through SCIM
+	orgRole org.RoleType
+}
+
+func (d *serviceDirectory) users(ctx context.Context, requester identity.Requester) ([]*userRecord, error) {
+	return d.searchOrgUsers(ctx, requester, 0)
+}
+
+func (d *serviceDirectory) searchOrgUsers(ctx context.Context, requester identity.Requester, userID int64) ([]*userRecord, error) {
+	result, err := d.orgService.SearchOrgUsers(ctx, &org.SearchOrgUsersQuery{
+		OrgID:                    requester.GetOrgID(),
+		UserID:                   userID,
+		DontEnforceAccessControl: true,
+		User:                     requester,
+	})
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*userRecord, 0, len(result.OrgUsers))
+	for _, u := range result.OrgUsers {
+		users = append(users, &userRecord{
+			ID:       u.UserID,
+			Login:    u.Login,
+			Email:    u.Email,
+			Name:     u.Name,
+			Disabled: u.IsDisabled,
+			Created:  u.Created,
+			Updated:  u.Updated,
+		})
+	}
+	return users, nil
+}
+
+func (d *serviceDirectory) userByLogin(ctx context.Context, requester identity.Requester, login string) (*userRecord, error) {
+	u, err := d.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: login})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, errNotFound.Errorf("user %s not found", login)
+		}
+		return nil, err
+	}
+	// GetByLogin falls back to emails, SCIM user names are logins only
+	if u.Login != login {
+		return nil, errNotFound.Errorf("user %s not found", login)
+	}
+	return d.user(ctx, requester, u.ID)
+}
+
+func (d *serviceDirectory) user(ctx context.Context, requester identity.Requester, id int64) (*userRecord, error) {
+	if ok, err := d.isOrgMember(ctx, requester, id); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errNotFound.Errorf("user %d is not a member of org %d", id, requester.GetOrgID())
+	}
+
+	u, err := d.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: id})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, errNotFound.Errorf("user %d not found", id)
+		}
+		return nil, err
+	}
+
+	orgs, err := d.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: id})
+	if err != nil {
+		return nil, err
+	}
+	otherOrgs := false
+	for _, o := range orgs {
+		if o.OrgID != requester.GetOrgID() {
+			otherOrgs = true
+			break
+		}
+	}
+
+	return &userRecord{
+		ID:           u.ID,
+		Login:        u.Login,
+		Email:        u.Email,
+		Name:         u.Name,
+		Disabled:     u.IsDisabled,
+		GrafanaAdmin: u.IsAdmin,
+		OtherOrgs:    otherOrgs,
+		Created:      u.Created,
+		Updated:      u.Updated,
+	}, nil
+}
+
+func (d *serviceDirectory) isOrgMember(ctx context.Context, requester identity.Requester, userID int64) (bool, error) {
+	users, err := d.searchOrgUsers(ctx, requester, userID)
+	if err != nil {
+		return false, err
+	}
+	return len(users) > 0, nil
+}
+
+func (d *serviceDirectory) createUser(ctx context.Context, requester identity.Requester, u *userRecord) (*userRecord, error) {
+	existing, err := d.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: u.Login})
+	switch {
+	case err == nil && existing.Login == u.Login:
+		// users can be provisioned in several organizations, the first one creates them
+		if ok, err := d.isOrgMember(ctx, requester, existing.ID); err != nil {
+			return nil, err
+		} else if ok {
+			return nil, errUniqueness.Errorf("user %s already exists", u.Login)
+		}
+	case err == nil || errors.Is(err, user.ErrUserNotFound):
+		// users are global, creating them is not covered by the organization permissions of the route
+		if ok, err := d.accessControl.Evaluate(ctx, requester, accesscontrol.EvalPermission(accesscontrol.ActionUsersCreate)); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, errCreateUser.Errorf("creating user %s requires %s", u.Login, accesscontrol.ActionUsersCreate)
+		}
+		created, err := d.userService.Create(ctx, &user.CreateUserCommand{
+			Login:        u.Login,
+			Email:        u.Email,
+			Name:         u.Name,
+			IsDisabled:   u.Disabled,
+			SkipOrgSetup: true,
+		})
+		if err != nil {
+			if errors.Is(err, user.ErrUserAlreadyExists) {
+				return nil, errUniqueness.Errorf("user %s already exists", u.Login)
+			}
+			return nil, err
+		}
+		existing = created
+	default:
+		return nil, err
+	}
+
+	err = d.orgService.AddOrgUser(ctx, &org.AddOrgUserCommand{
+		OrgID:  requester.GetOrgID(),
+		UserID: existing.ID,
+		Role:   d.orgRole,
+	})
+	if err != nil {
+		if errors.Is(err, org.ErrOrgUserAlreadyAdded) {
+			return nil, errUniqueness.Errorf("user %s already exists", u.Login)
+		}
+		return nil, err
+	}
+	return d.user(ctx, requester, existing.ID)
+}
+
+func (d *serviceDirectory) updateUser(ctx context.Context, requester identity.Requester, u *userRecord) error {
+	current, err := d.user(ctx, requester, u.ID)
+	if err != nil {
+		return err
+	}
+
+	// the user service doesn't check for conflicts when updating users
+	for _, loginOrEmail := range []string{u.Login, u.Email} {
+		if loginOrEmail == "" {
+			continue
+		}
+		other, err := d.userService.GetByLogin(ctx, &user.GetUserByLoginQuery{LoginOrEmail: loginOrEmail})
+		if err != nil && !errors.Is(err, user.ErrUserNotFound) {
+			return err
+		}
+		if other != nil && other.ID != u.ID {
+			return errUniqueness.Errorf("login or email %s is used by user %d", loginOrEmail, other.ID)
+		}
+	}
+
+	err = d.userService.Update(ctx, &user.UpdateUserCommand{
+		UserID:     u.ID,
+		Login:      u.Login,
+		Email:      u.Email,
+		Name:       u.Name,
+		IsDisabled: &u.Disabled,
+	})
+	if err != nil {
+		return err
+	}
+
+	if u.Disabled && !current.Disabled {
+		return d.sessionService.RevokeAllUserTokens(ctx, u.ID)
+	}
+	return nil
+}
+
+func (d *serviceDirectory) removeUser(ctx context.Context, requester identity.Requester, id int64) error {
+	if _, err := d.user(ctx, requester, id); err != nil {
+		return err
+	}
+	cmd := &org.RemoveOrgUserCommand{UserID: id, OrgID: requester.GetOrgID(), ShouldDeleteOrphanedUser: true}
+	if err := d.orgService.RemoveOrgUser(ctx, cmd); err != nil {
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			return errMutability.Errorf("cannot remove the last admin of org %d", requester.GetOrgID())
+		}
+		return err
+	}
+	if !cmd.UserWasDeleted {
+		return d.sessionService.RevokeAllUserTokens(ctx, id)
+	}
+	return nil
+}
+
+func (d *serviceDirectory) teams(ctx context.Context, requester identity.Requester, name string) ([]*teamRecord, error) {
+	result, err := d.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        requester.GetOrgID(),
+		Name:         name,
+		SignedInUser: requester,
+		HiddenUsers:  map[string]struct{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	teams := make([]*teamRecord, 0, len(result.Teams))
+	for _, t := range result.Teams {
+		teams = append(teams, &teamRecord{ID: t.ID, Name: t.Name, Email: t.Email})
+	}
+	return teams, nil
+}
+
+func (d *serviceDirectory) team(ctx context.Context, requester identity.Requester, id int64) (*teamRecord, error) {
+	t, err := d.teamService.GetTeamByID(ctx, &team.GetTeamByIDQuery{
+		OrgID:        requester.GetOrgID(),
+		ID:           id,
+		SignedInUser: requester,
+		HiddenUsers:  map[string]struct{}{},
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, errNotFound.Errorf("team %d not found", id)
+		}
+		return nil, err
+	}
+	return &teamRecord{ID: t.ID, Name: t.Name, Email: t.Email}, nil
+}
+
+func (d *serviceDirectory) teamMembers(ctx context.Context, requester identity.Requester, teamID int64) ([]*memberRecord, error) {
+	members, err := d.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{
+		OrgID:        requester.GetOrgID(),
+		TeamID:       teamID,
+		SignedInUser: requester,
+	})
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*memberRecord, 0, len(members))
+	for _, m := range members {
+		records = append(records, &memberRecord{UserID: m.UserID, Login: m.Login})
+	}
+	return records, nil
+}
+
+func (d *serviceDirectory) createTeam(ctx context.Context, requester identity.Requester, name string) (*teamRecord, error) {
+	t, err := d.teamService.CreateTeam(ctx, name, "", requester.GetOrgID())
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return nil, errUniqueness.Errorf("team %s already exists", name)
+		}
+		return nil, err
+	}
+	return &teamRecord{ID: t.ID, Name: t.Name, Email: t.Email}, nil
+}
+
+func (d *serviceDirectory) renameTeam(ctx context.Context, requester identity.Requester, id int64, name string) error {
+	current, err := d.team(ctx, requester, id)
+	if err != nil {
+		return err
+	}
+	err = d.teamService.UpdateTeam(ctx, &team.UpdateTeamCommand{ID: id, OrgID: requester.GetOrgID(), Name: name, Email: current.Email})
+	if errors.Is(err, team.ErrTeamNameTaken) {
+		return errUniqueness.Errorf("team %s already exists", name)
+	}
+	return err
+}
+
+func (d *serviceDirectory) deleteTeam(ctx context.Context, requester identity.Requester, id int64) error {
+	err := d.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: requester.GetOrgID(), ID: id})
+	if errors.Is(err, team.ErrTeamNotFound) {
+		return errNotFound.Errorf("team %d not found", id)
+	}
+	return err
+}
+
+func (d *serviceDirectory) addTeamMember(ctx context.Context, requester identity.Requester, teamID, userID int64) error {
+	member, err := d.teamMember(ctx, requester, teamID, userID)
+	if err != nil {
+		return err
+	}
+	// admins are members already, setting the Member permission would demote them
+	if member != nil {
+		return nil
+	}
+	return d.setTeamPermission(ctx, requester, teamID, userID, team.MemberPermissionName)
+}
+
+func (d *serviceDirectory) removeTeamMember(ctx context.Context, requester identity.Requester, teamID, userID int64) error {
+	member, err := d.teamMember(ctx, requester, teamID, userID)
+	if err != nil {
+		return err
+	}
+	// clearing the permission of a team admin would also remove their admin permission, which SCIM doesn't manage
+	if member == nil || member.Permission == dashboardaccess.PERMISSION_ADMIN {
+		return nil
+	}
+	return d.setTeamPermission(ctx, requester, teamID, userID, "")
+}
+
+// teamMember returns the membership of the user in the team, or nil when they aren't a member
+func (d *serviceDirectory) teamMember(ctx context.Context, requester identity.Requester, teamID, userID int64) (*team.TeamMemberDTO, error) {
+	members, err := d.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{
+		OrgID:        requester.GetOrgID(),
+		TeamID:       teamID,
+		UserID:       userID,
+		SignedInUser: requester,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if m.UserID == userID {
+			return m, nil
+		}
+	}
+	return nil, nil
+}
+
+func (d *serviceDirectory) setTeamPermission(ctx context.Context, requester identity.Requester, teamID, userID int64, permission string) error {
+	_, err := d.teamPermissions.SetUserPermission(
+		ctx, requester.GetOrgID(), accesscontrol.User{ID: userID}, strconv.FormatInt(teamID, 10), permission,
+	)
+	return err
+}
@@ -0,0 +1,71 @@
+package scim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+)
+
+// recordingTeamPermissions records the team permissions set for users
+type recordingTeamPermissions struct {
+	actest.FakePermissionsService
+	set map[int64]string
+}
+
+func (r *recordingTeamPermissions) SetUserPermission(_ context.Context, _ int64, u accesscontrol.User, _, permission string) (*accesscontrol.ResourcePermission, error) {
+	r.set[u.ID] = permission
+	return nil, nil
+}
+
+func TestServiceDirectory_CreateUser(t *testing.T) {
+	requester := &user.SignedInUser{UserID: 1, OrgID: 1}
+
+	t.Run("creating a user requires users:create", func(t *testing.T) {
+		userService := usertest.NewUserServiceFake()
+		userService.ExpectedError = user.ErrUserNotFound
+		userService.CreateFn = func(context.Context, *user.CreateUserCommand) (*user.User, error) {
+			t.Fatal("user must not be created")
+			return nil, nil
+		}
+		dir := &serviceDirectory{accessControl: actest.FakeAccessControl{ExpectedEvaluate: false}, userService: userService}
+
+		_, err := dir.createUser(context.Background(), requester, &userRecord{Login: "new"})
+		require.ErrorIs(t, err, errCreateUser)
+	})
+}
+
+func TestServiceDirectory_TeamMembers(t *testing.T) {
+	requester := &user.SignedInUser{UserID: 1, OrgID: 1}
+	teamService := teamtest.NewFakeService()
+	teamService.ExpectedMembers = []*team.TeamMemberDTO{
+		{UserID: 2, Permission: dashboardaccess.PERMISSION_VIEW},
+		{UserID: 3, Permission: dashboardaccess.PERMISSION_ADMIN},
+	}
+	permissions := &recordingTeamPermissions{set: map[int64]string{}}
+	dir := &serviceDirectory{teamService: teamService, teamPermissions: permissions}
+
+	for _, userID := range []int64{2, 3} {
+		require.NoError(t, dir.removeTeamMember(context.Background(), requester, 1, userID))
+	}
+	assert.Equal(t, map[int64]string{2: ""}, permissions.set)
+
+	// adding an admin keeps their admin permission
+	permissions.set = map[int64]string{}
+	teamService.ExpectedMembers = teamService.ExpectedMembers[1:]
+	require.NoError(t, dir.addTeamMember(context.Background(), requester, 1, 3))
+	assert.Empty(t, permissions.set)
+
+	teamService.ExpectedMembers = nil
+	require.NoError(t, dir.addTeamMember(context.Background(), requester, 1, 4))
+	assert.Equal(t, map[int64]string{4: team.MemberPermissionName}, permissions.set)
+}
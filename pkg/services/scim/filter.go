@@ -0,0 +1,381 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// filter is a parsed SCIM filter expression (RFC 7644 section 3.4.2.2). It is evaluated against the JSON
+// representation of a resource.
+type filter interface {
+	matches(resource map[string]any) bool
+}
+
+type attrFilter struct {
+	path  []string
+	op    string
+	value any
+}
+
+type logicalFilter struct {
+	and         bool
+	left, right filter
+}
+
+type notFilter struct {
+	inner filter
+}
+
+var comparisonOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true, "gt": true, "ge": true, "lt": true, "le": true,
+}
+
+// parseFilter parses filters such as `userName eq "bjensen"` or
+// `displayName sw "dev" and not (members pr)`. Value path filters, like `emails[type eq "work"]`, are not supported.
+func parseFilter(expr string) (filter, error) {
+	tokens, err := tokenizeFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos].text)
+	}
+	return f, nil
+}
+
+type filterTokenKind int
+
+const (
+	tokenWord filterTokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+}
+
+func tokenizeFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		switch c := expr[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpenParen, text: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, filterToken{kind: tokenCloseParen, text: ")"})
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(expr) && expr[end] != '"'; end++ {
+				if expr[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			var s string
+			if err := json.Unmarshal([]byte(expr[i:end+1]), &s); err != nil {
+				return nil, fmt.Errorf("invalid string %s", expr[i:end+1])
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: s})
+			i = end + 1
+		case c == '[' || c == ']':
+			return nil, fmt.Errorf("value path filters are not supported")
+		default:
+			end := i
+			for ; end < len(expr) && !strings.ContainsRune(" \t()\"[]", rune(expr[end])); end++ {
+			}
+			tokens = append(tokens, filterToken{kind: tokenWord, text: expr[i:end]})
+			i = end
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty filter")
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenWord && strings.EqualFold(p.tokens[p.pos].text, keyword)
+}
+
+func (p *filterParser) parseOr() (filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filter, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalFilter{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenOpenParen {
+			return nil, fmt.Errorf("expected ( after not")
+		}
+		inner, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return &notFilter{inner: inner}, nil
+	}
+
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+
+	if tok.kind == tokenOpenParen {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokenCloseParen {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	if tok.kind != tokenWord {
+		return nil, fmt.Errorf("expected attribute, got %q", tok.text)
+	}
+
+	path := attributePath(tok.text)
+	if p.peekKeyword("pr") {
+		p.pos++
+		return &attrFilter{path: path, op: "pr"}, nil
+	}
+	if p.pos+1 >= len(p.tokens) || p.tokens[p.pos].kind != tokenWord {
+		return nil, fmt.Errorf("expected operator after %q", tok.text)
+	}
+	op := strings.ToLower(p.tokens[p.pos].text)
+	if !comparisonOperators[op] {
+		return nil, fmt.Errorf("unknown operator %q", p.tokens[p.pos].text)
+	}
+	value, err := parseFilterValue(p.tokens[p.pos+1])
+	if err != nil {
+		return nil, err
+	}
+	p.pos += 2
+	return &attrFilter{path: path, op: op, value: value}, nil
+}
+
+func parseFilterValue(tok filterToken) (any, error) {
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenWord:
+		switch strings.ToLower(tok.text) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		if n, err := strconv.ParseFloat(tok.text, 64); err == nil {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid value %q", tok.text)
+}
+
+// attributePath splits an attribute such as `name.givenName` and drops the schema URN prefix clients may send, as in
+// `urn:ietf:params:scim:schemas:core:2.0:User:userName`.
+func attributePath(attr string) []string {
+	if strings.HasPrefix(strings.ToLower(attr), "urn:") {
+		attr = attr[strings.LastIndex(attr, ":")+1:]
+	}
+	return strings.Split(attr, ".")
+}
+
+func (f *logicalFilter) matches(resource map[string]any) bool {
+	if f.and {
+		return f.left.matches(resource) && f.right.matches(resource)
+	}
+	return f.left.matches(resource) || f.right.matches(resource)
+}
+
+func (f *notFilter) matches(resource map[string]any) bool {
+	return !f.inner.matches(resource)
+}
+
+func (f *attrFilter) matches(resource map[string]any) bool {
+	values := resolveAttribute(resource, f.path)
+	switch f.op {
+	case "pr":
+		for _, v := range values {
+			if v != nil && v != "" {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		for _, v := range values {
+			if compareValues(v, "eq", f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, v := range values {
+		if compareValues(v, f.op, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveAttribute returns the values of an attribute, looking up names case-insensitively. Multi-valued complex
+// attributes without a sub-attribute resolve to their "value" sub-attribute, as in `emails eq "a@example.com"`.
+func resolveAttribute(resource map[string]any, path []string) []any {
+	current := []any{resource}
+	for _, name := range path {
+		var next []any
+		for _, v := range current {
+			m, ok := v.(map[string]any)
+			if !ok {
+				continue
+			}
+			child, ok := m[lookupKey(m, name)]
+			if !ok {
+				continue
+			}
+			if list, ok := child.([]any); ok {
+				next = append(next, list...)
+			} else {
+				next = append(next, child)
+			}
+		}
+		current = next
+	}
+
+	values := make([]any, 0, len(current))
+	for _, v := range current {
+		if m, ok := v.(map[string]any); ok {
+			values = append(values, m["value"])
+			continue
+		}
+		values = append(values, v)
+	}
+	return values
+}
+
+func compareValues(actual any, op string, expected any) bool {
+	switch e := expected.(type) {
+	case nil:
+		return op == "eq" && actual == nil
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == "eq" && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == e
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+		return false
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		a, e = strings.ToLower(a), strings.ToLower(e)
+		switch op {
+		case "eq":
+			return a == e
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	}
+	return false
+}
+
+// equalityFilter returns the value of filters of the form `<attribute> eq "<value>"`, which SCIM clients use to look
+// up a resource before creating it. They are answered without listing every resource.
+func equalityFilter(f filter, attribute string) (string, bool) {
+	af, ok := f.(*attrFilter)
+	if !ok || af.op != "eq" || len(af.path) != 1 || !strings.EqualFold(af.path[0], attribute) {
+		return "", false
+	}
+	value, ok := af.value.(string)
+	return value, ok
+}
+
+func lookupKey(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
@@ -0,0 +1,90 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	resource := map[string]any{
+		"userName":    "bjensen",
+		"displayName": "Barbara Jensen",
+		"active":      true,
+		"name":        map[string]any{"givenName": "Barbara", "familyName": "Jensen"},
+		"emails": []any{
+			map[string]any{"value": "bjensen@example.com", "type": "work", "primary": true},
+		},
+		"meta": map[string]any{"resourceType": "User"},
+	}
+
+	tests := []struct {
+		filter  string
+		matches bool
+	}{
+		{filter: `userName eq "bjensen"`, matches: true},
+		{filter: `USERNAME EQ "BJensen"`, matches: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "bjensen"`, matches: true},
+		{filter: `userName ne "bjensen"`, matches: false},
+		{filter: `userName sw "bj"`, matches: true},
+		{filter: `userName ew "sen"`, matches: true},
+		{filter: `displayName co "bara"`, matches: true},
+		{filter: `name.familyName eq "Jensen"`, matches: true},
+		{filter: `emails eq "bjensen@example.com"`, matches: true},
+		{filter: `emails.type eq "home"`, matches: false},
+		{filter: `active eq true`, matches: true},
+		{filter: `active eq false`, matches: false},
+		{filter: `nickName pr`, matches: false},
+		{filter: `displayName pr and meta.resourceType eq "User"`, matches: true},
+		{filter: `userName eq "alice" or userName eq "bjensen"`, matches: true},
+		{filter: `userName eq "alice" or userName eq "bob" and active eq true`, matches: false},
+		{filter: `not (userName eq "alice") and (active eq true or active eq false)`, matches: true},
+		{filter: `userName gt "a" and userName lt "c"`, matches: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := parseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, f.matches(resource))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName is "bjensen"`,
+		`userName eq "bjensen`,
+		`(userName eq "bjensen"`,
+		`userName eq "bjensen" and`,
+		`userName eq bjensen`,
+		`emails[type eq "work"]`,
+		`not userName pr`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := parseFilter(filter)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEqualityFilter(t *testing.T) {
+	f, err := parseFilter(`userName eq "bjensen"`)
+	require.NoError(t, err)
+
+	value, ok := equalityFilter(f, "username")
+	assert.True(t, ok)
+	assert.Equal(t, "bjensen", value)
+
+	_, ok = equalityFilter(f, "displayName")
+	assert.False(t, ok)
+
+	f, err = parseFilter(`userName eq "bjensen" and active eq true`)
+	require.NoError(t, err)
+	_, ok = equalityFilter(f, "userName")
+	assert.False(t, ok)
+}
@@ -0,0 +1,251 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) listGroups(c *contextmodel.ReqContext) response.Response {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+
+	name, _ := equalityFilter(q.filter, "displayName")
+	teams, err := s.dir.teams(c.Req.Context(), c.SignedInUser, name)
+	if err != nil {
+		return s.scimError(err)
+	}
+
+	// identity providers list groups to reconcile them, members are expensive to load and often excluded
+	withMembers := !excludesMembers(c.Query("excludedAttributes"))
+	resources := make([]any, 0, len(teams))
+	for _, t := range teams {
+		var members []*memberRecord
+		if withMembers {
+			if members, err = s.dir.teamMembers(c.Req.Context(), c.SignedInUser, t.ID); err != nil {
+				return s.scimError(err)
+			}
+		}
+		resources = append(resources, s.toGroup(t, members))
+	}
+	result, err := q.page(resources)
+	if err != nil {
+		return s.scimError(err)
+	}
+	return scimJSON(http.StatusOK, result)
+}
+
+func excludesMembers(excludedAttributes string) bool {
+	for _, attr := range strings.Split(excludedAttributes, ",") {
+		if path := attributePath(strings.TrimSpace(attr)); strings.EqualFold(path[0], "members") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) getGroup(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	t, err := s.dir.team(c.Req.Context(), c.SignedInUser, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	var members []*memberRecord
+	if !excludesMembers(c.Query("excludedAttributes")) {
+		if members, err = s.dir.teamMembers(c.Req.Context(), c.SignedInUser, id); err != nil {
+			return s.scimError(err)
+		}
+	}
+	return scimJSON(http.StatusOK, s.toGroup(t, members))
+}
+
+func (s *Service) createGroup(c *contextmodel.ReqContext) response.Response {
+	var body Group
+	if err := decodeBody(c, &body); err != nil {
+		return s.scimError(err)
+	}
+	if body.DisplayName == "" {
+		return s.scimError(errInvalidValue.Errorf("displayName is required"))
+	}
+	memberIDs, err := s.memberIDs(c.Req.Context(), c.SignedInUser, body.Members)
+	if err != nil {
+		return s.scimError(err)
+	}
+
+	t, err := s.dir.createTeam(c.Req.Context(), c.SignedInUser, body.DisplayName)
+	if err != nil {
+		return s.scimError(err)
+	}
+	for _, userID := range memberIDs {
+		if err := s.dir.addTeamMember(c.Req.Context(), c.SignedInUser, t.ID, userID); err != nil {
+			return s.scimError(err)
+		}
+	}
+
+	members, err := s.dir.teamMembers(c.Req.Context(), c.SignedInUser, t.ID)
+	if err != nil {
+		return s.scimError(err)
+	}
+	g := s.toGroup(t, members)
+	return scimJSON(http.StatusCreated, g).SetHeader("Location", g.Meta.Location)
+}
+
+func (s *Service) replaceGroup(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	var body Group
+	if err := decodeBody(c, &body); err != nil {
+		return s.scimError(err)
+	}
+	return s.updateGroup(c.Req.Context(), c.SignedInUser, id, &body)
+}
+
+func (s *Service) patchGroup(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	var body PatchRequest
+	if err := decodeBody(c, &body); err != nil {
+		return s.scimError(err)
+	}
+
+	t, err := s.dir.team(c.Req.Context(), c.SignedInUser, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	members, err := s.dir.teamMembers(c.Req.Context(), c.SignedInUser, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	resource, err := toMap(s.toGroup(t, members))
+	if err != nil {
+		return s.scimError(err)
+	}
+	if err := applyPatch(resource, body.Operations); err != nil {
+		return s.scimError(err)
+	}
+
+	var patched Group
+	if err := fromMap(resource, &patched); err != nil {
+		return s.scimError(err)
+	}
+	return s.updateGroup(c.Req.Context(), c.SignedInUser, id, &patched)
+}
+
+// updateGroup renames the team and adds and removes members so that they match the group
+func (s *Service) updateGroup(ctx context.Context, requester identity.Requester, id int64, g *Group) response.Response {
+	if g.DisplayName == "" {
+		return s.scimError(errInvalidValue.Errorf("displayName is required"))
+	}
+	t, err := s.dir.team(ctx, requester, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	desired, err := s.memberIDs(ctx, requester, g.Members)
+	if err != nil {
+		return s.scimError(err)
+	}
+
+	if g.DisplayName != t.Name {
+		if err := s.dir.renameTeam(ctx, requester, id, g.DisplayName); err != nil {
+			return s.scimError(err)
+		}
+		t.Name = g.DisplayName
+	}
+
+	members, err := s.dir.teamMembers(ctx, requester, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	current := make(map[int64]bool, len(members))
+	for _, m := range members {
+		current[m.UserID] = true
+	}
+	for _, userID := range desired {
+		if !current[userID] {
+			if err := s.dir.addTeamMember(ctx, requester, id, userID); err != nil {
+				return s.scimError(err)
+			}
+		}
+		delete(current, userID)
+	}
+	for userID := range current {
+		if err := s.dir.removeTeamMember(ctx, requester, id, userID); err != nil {
+			return s.scimError(err)
+		}
+	}
+
+	if members, err = s.dir.teamMembers(ctx, requester, id); err != nil {
+		return s.scimError(err)
+	}
+	return scimJSON(http.StatusOK, s.toGroup(t, members))
+}
+
+func (s *Service) deleteGroup(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	if _, err := s.dir.team(c.Req.Context(), c.SignedInUser, id); err != nil {
+		return s.scimError(err)
+	}
+	if err := s.dir.deleteTeam(c.Req.Context(), c.SignedInUser, id); err != nil {
+		return s.scimError(err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+// memberIDs returns the ids of group members, which must be users of the organization
+func (s *Service) memberIDs(ctx context.Context, requester identity.Requester, members []Member) ([]int64, error) {
+	ids := make([]int64, 0, len(members))
+	seen := make(map[int64]bool, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			return nil, errInvalidValue.Errorf("invalid member %q", m.Value)
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.dir.user(ctx, requester, id); err != nil {
+			if errors.Is(err, errNotFound) {
+				return nil, errInvalidValue.Errorf("member %d is not a user of the organization", id)
+			}
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (s *Service) toGroup(t *teamRecord, members []*memberRecord) *Group {
+	g := &Group{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		DisplayName: t.Name,
+		Meta: &Meta{
+			ResourceType: resourceTypeGroup,
+			Location:     s.baseURL + "/Groups/" + strconv.FormatInt(t.ID, 10),
+		},
+	}
+	for _, m := range members {
+		id := strconv.FormatInt(m.UserID, 10)
+		g.Members = append(g.Members, Member{Value: id, Display: m.Login, Ref: s.baseURL + "/Users/" + id})
+	}
+	return g
+}
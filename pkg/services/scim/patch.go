@@ -0,0 +1,217 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type patchPath struct {
+	attr   string
+	filter filter
+	sub    string
+}
+
+// parsePatchPath parses PATCH paths such as `displayName`, `name.givenName`, `members[value eq "2"]` or
+// `emails[type eq "work"].value`.
+func parsePatchPath(path string) (*patchPath, error) {
+	head := path
+	if i := strings.Index(path, "["); i >= 0 {
+		head = path[:i]
+	}
+	if strings.HasPrefix(strings.ToLower(head), "urn:") {
+		path = path[strings.LastIndex(head, ":")+1:]
+	}
+
+	open := strings.Index(path, "[")
+	if open < 0 {
+		attr, sub, _ := strings.Cut(path, ".")
+		if attr == "" {
+			return nil, errInvalidPath.Errorf("invalid path %q", path)
+		}
+		return &patchPath{attr: attr, sub: sub}, nil
+	}
+
+	closing := strings.LastIndex(path, "]")
+	if closing < open {
+		return nil, errInvalidPath.Errorf("invalid path %q", path)
+	}
+	f, err := parseFilter(path[open+1 : closing])
+	if err != nil {
+		return nil, errInvalidPath.Errorf("invalid filter in path %q: %w", path, err)
+	}
+	p := &patchPath{attr: path[:open], filter: f}
+	if rest := path[closing+1:]; rest != "" {
+		if !strings.HasPrefix(rest, ".") || len(rest) == 1 {
+			return nil, errInvalidPath.Errorf("invalid path %q", path)
+		}
+		p.sub = rest[1:]
+	}
+	return p, nil
+}
+
+// applyPatch applies PATCH operations to the JSON representation of a resource
+func applyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return errInvalidSyntax.Errorf("unknown patch operation %q", operation.Op)
+		}
+
+		var value any
+		if len(operation.Value) > 0 {
+			if err := json.Unmarshal(operation.Value, &value); err != nil {
+				return errInvalidValue.Errorf("invalid value for patch operation: %w", err)
+			}
+		}
+
+		if operation.Path == "" {
+			if op == "remove" {
+				return errNoTarget.Errorf("remove operations need a path")
+			}
+			attributes, ok := value.(map[string]any)
+			if !ok {
+				return errInvalidValue.Errorf("patch operations without a path need an object value")
+			}
+			for name, v := range attributes {
+				path, err := parsePatchPath(name)
+				if err != nil {
+					return err
+				}
+				if err := patchAttribute(resource, op, path, v); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		path, err := parsePatchPath(operation.Path)
+		if err != nil {
+			return err
+		}
+		if err := patchAttribute(resource, op, path, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchAttribute(resource map[string]any, op string, path *patchPath, value any) error {
+	key := lookupKey(resource, path.attr)
+
+	if path.filter != nil {
+		return patchFilteredValues(resource, key, op, path, value)
+	}
+
+	if path.sub != "" {
+		parent, _ := resource[key].(map[string]any)
+		if parent == nil {
+			if op == "remove" {
+				return nil
+			}
+			parent = map[string]any{}
+			resource[key] = parent
+		}
+		subKey := lookupKey(parent, path.sub)
+		if op == "remove" {
+			delete(parent, subKey)
+		} else {
+			parent[subKey] = value
+		}
+		return nil
+	}
+
+	existing, isList := resource[key].([]any)
+	switch op {
+	case "remove":
+		// some clients remove values of multi-valued attributes by listing them instead of using a filter
+		if removed, ok := value.([]any); ok && isList {
+			resource[key] = withoutValues(existing, removed)
+			return nil
+		}
+		delete(resource, key)
+	case "add":
+		if added, ok := value.([]any); ok && isList {
+			resource[key] = append(existing, withoutValues(added, existing)...)
+			return nil
+		}
+		resource[key] = value
+	case "replace":
+		resource[key] = value
+	}
+	return nil
+}
+
+// patchFilteredValues handles paths that select values of a multi-valued attribute with a filter
+func patchFilteredValues(resource map[string]any, key, op string, path *patchPath, value any) error {
+	existing, _ := resource[key].([]any)
+	kept := make([]any, 0, len(existing))
+	matched := false
+	for _, v := range existing {
+		element, ok := v.(map[string]any)
+		if !ok || !path.filter.matches(element) {
+			kept = append(kept, v)
+			continue
+		}
+		matched = true
+
+		switch {
+		case op == "remove" && path.sub == "":
+			continue
+		case op == "remove":
+			delete(element, lookupKey(element, path.sub))
+		case path.sub != "":
+			element[lookupKey(element, path.sub)] = value
+		default:
+			if replacement, ok := value.(map[string]any); ok {
+				for k, v := range replacement {
+					element[lookupKey(element, k)] = v
+				}
+			}
+		}
+		kept = append(kept, element)
+	}
+
+	// clients set values such as `emails[type eq "work"].value` whether or not the resource already has one
+	if !matched && op != "remove" {
+		af, ok := path.filter.(*attrFilter)
+		if !ok || af.op != "eq" || len(af.path) != 1 {
+			return errNoTarget.Errorf("no value of %s matches the filter", path.attr)
+		}
+		element := map[string]any{af.path[0]: af.value}
+		if path.sub != "" {
+			element[path.sub] = value
+		} else if replacement, ok := value.(map[string]any); ok {
+			for k, v := range replacement {
+				element[k] = v
+			}
+		}
+		kept = append(kept, element)
+	}
+
+	resource[key] = kept
+	return nil
+}
+
+// withoutValues returns the elements of list whose "value" is not listed in removed
+func withoutValues(list []any, removed []any) []any {
+	ids := map[string]bool{}
+	for _, v := range removed {
+		ids[elementValue(v)] = true
+	}
+	result := make([]any, 0, len(list))
+	for _, v := range list {
+		if !ids[elementValue(v)] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// elementValue returns the "value" of an element as a string, clients send ids as strings or numbers
+func elementValue(v any) string {
+	if m, ok := v.(map[string]any); ok {
+		v = m[lookupKey(m, "value")]
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,129 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		resource   string
+		operations string
+		expected   string
+	}{
+		{
+			name:       "replace an attribute",
+			resource:   `{"displayName": "Dev"}`,
+			operations: `[{"op": "replace", "path": "displayName", "value": "Developers"}]`,
+			expected:   `{"displayName": "Developers"}`,
+		},
+		{
+			name:       "operations and attribute names are case-insensitive",
+			resource:   `{"displayName": "Dev"}`,
+			operations: `[{"op": "Replace", "path": "DISPLAYNAME", "value": "Developers"}]`,
+			expected:   `{"displayName": "Developers"}`,
+		},
+		{
+			name:       "replace attributes without a path",
+			resource:   `{"active": true, "displayName": "Barbara"}`,
+			operations: `[{"op": "replace", "value": {"active": false, "name.givenName": "Babs"}}]`,
+			expected:   `{"active": false, "displayName": "Barbara", "name": {"givenName": "Babs"}}`,
+		},
+		{
+			name:       "replace a sub-attribute",
+			resource:   `{"name": {"givenName": "Barbara", "familyName": "Jensen"}}`,
+			operations: `[{"op": "replace", "path": "name.givenName", "value": "Babs"}]`,
+			expected:   `{"name": {"givenName": "Babs", "familyName": "Jensen"}}`,
+		},
+		{
+			name:       "schema prefixes are ignored",
+			resource:   `{"userName": "bjensen"}`,
+			operations: `[{"op": "replace", "path": "urn:ietf:params:scim:schemas:core:2.0:User:userName", "value": "babs"}]`,
+			expected:   `{"userName": "babs"}`,
+		},
+		{
+			name:       "add members",
+			resource:   `{"members": [{"value": "1"}]}`,
+			operations: `[{"op": "add", "path": "members", "value": [{"value": "1"}, {"value": "2"}]}]`,
+			expected:   `{"members": [{"value": "1"}, {"value": "2"}]}`,
+		},
+		{
+			name:       "add members to an empty group",
+			resource:   `{"displayName": "Dev"}`,
+			operations: `[{"op": "add", "path": "members", "value": [{"value": "2"}]}]`,
+			expected:   `{"displayName": "Dev", "members": [{"value": "2"}]}`,
+		},
+		{
+			name:       "remove members with a filter",
+			resource:   `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			operations: `[{"op": "remove", "path": "members[value eq \"1\"]"}]`,
+			expected:   `{"members": [{"value": "2"}]}`,
+		},
+		{
+			name:       "remove members by value",
+			resource:   `{"members": [{"value": "1"}, {"value": "2"}, {"value": "3"}]}`,
+			operations: `[{"op": "remove", "path": "members", "value": [{"value": "1"}, {"value": 3}]}]`,
+			expected:   `{"members": [{"value": "2"}]}`,
+		},
+		{
+			name:       "remove all members",
+			resource:   `{"members": [{"value": "1"}, {"value": "2"}]}`,
+			operations: `[{"op": "remove", "path": "members"}]`,
+			expected:   `{}`,
+		},
+		{
+			name:       "replace a sub-attribute of a filtered value",
+			resource:   `{"emails": [{"value": "a@example.com", "type": "work"}, {"value": "b@example.com", "type": "home"}]}`,
+			operations: `[{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "c@example.com"}]`,
+			expected:   `{"emails": [{"value": "c@example.com", "type": "work"}, {"value": "b@example.com", "type": "home"}]}`,
+		},
+		{
+			name:       "add a filtered value that doesn't exist",
+			resource:   `{"userName": "bjensen"}`,
+			operations: `[{"op": "add", "path": "emails[type eq \"work\"].value", "value": "a@example.com"}]`,
+			expected:   `{"userName": "bjensen", "emails": [{"value": "a@example.com", "type": "work"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resource map[string]any
+			require.NoError(t, json.Unmarshal([]byte(tt.resource), &resource))
+			var operations []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tt.operations), &operations))
+
+			require.NoError(t, applyPatch(resource, operations))
+
+			actual, err := json.Marshal(resource)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(actual))
+		})
+	}
+}
+
+func TestApplyPatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations string
+		err        error
+	}{
+		{name: "unknown operation", operations: `[{"op": "move", "path": "displayName"}]`, err: errInvalidSyntax},
+		{name: "remove without path", operations: `[{"op": "remove"}]`, err: errNoTarget},
+		{name: "value without path is not an object", operations: `[{"op": "replace", "value": "Dev"}]`, err: errInvalidValue},
+		{name: "invalid filter", operations: `[{"op": "remove", "path": "members[value]"}]`, err: errInvalidPath},
+		{name: "no filtered value", operations: `[{"op": "replace", "path": "members[value sw \"1\"]", "value": {}}]`, err: errNoTarget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tt.operations), &operations))
+			err := applyPatch(map[string]any{"displayName": "Dev"}, operations)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
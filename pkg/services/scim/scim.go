@@ -0,0 +1,287 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auth"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	defaultCount = 100
+	maxCount     = 1000
+)
+
+var (
+	errNotFound      = errutil.NotFound("scim.notFound", errutil.WithPublicMessage("Resource not found"))
+	errUniqueness    = errutil.Conflict("scim.uniqueness", errutil.WithPublicMessage("A resource with the same name already exists"))
+	errInvalidFilter = errutil.BadRequest("scim.invalidFilter", errutil.WithPublicMessage("Invalid filter"))
+	errInvalidPath   = errutil.BadRequest("scim.invalidPath", errutil.WithPublicMessage("Invalid path"))
+	errInvalidSyntax = errutil.BadRequest("scim.invalidSyntax", errutil.WithPublicMessage("Invalid request"))
+	errInvalidValue  = errutil.BadRequest("scim.invalidValue", errutil.WithPublicMessage("Invalid value"))
+	errNoTarget      = errutil.BadRequest("scim.noTarget", errutil.WithPublicMessage("No value matches the path"))
+	errMutability    = errutil.BadRequest("scim.mutability", errutil.WithPublicMessage("The resource cannot be changed"))
+	errForbidden     = errutil.Forbidden("scim.forbidden", errutil.WithPublicMessage("SCIM requests must use a service account token"))
+	errServerAdmin   = errutil.Forbidden("scim.serverAdmin", errutil.WithPublicMessage("Grafana server admins cannot be changed or removed through SCIM"))
+	errSharedUser    = errutil.Forbidden("scim.sharedUser", errutil.WithPublicMessage("Users that are members of other organizations cannot be changed through SCIM"))
+	errCreateUser    = errutil.Forbidden("scim.createUser", errutil.WithPublicMessage("Creating users requires the users:create permission"))
+)
+
+// Service is a SCIM 2.0 server (RFC 7643, RFC 7644) provisioning the users and teams of the organization of the
+// service account calling it. SCIM users are the members of the organization, SCIM groups are its teams.
+type Service struct {
+	dir           directory
+	routeRegister routing.RouteRegister
+	accessControl ac.AccessControl
+	log           log.Logger
+	baseURL       string
+}
+
+func ProvideService(
+	cfg *setting.Cfg, routeRegister routing.RouteRegister, accessControl ac.AccessControl,
+	userService user.Service, orgService org.Service, teamService team.Service,
+	teamPermissionsService ac.TeamPermissionsService, sessionService auth.UserTokenService,
+) *Service {
+	section := cfg.SectionWithEnvOverrides("auth.scim")
+	s := &Service{
+		dir: &serviceDirectory{
+			accessControl:   accessControl,
+			userService:     userService,
+			orgService:      orgService,
+			teamService:     teamService,
+			teamPermissions: teamPermissionsService,
+			sessionService:  sessionService,
+			orgRole:         org.RoleType(section.Key("org_role").In(string(org.RoleViewer), []string{string(org.RoleViewer), string(org.RoleEditor), string(org.RoleAdmin)})),
+		},
+		routeRegister: routeRegister,
+		accessControl: accessControl,
+		log:           log.New("scim"),
+		baseURL:       strings.TrimSuffix(cfg.AppURL, "/") + "/api/scim/v2",
+	}
+
+	if section.Key("enabled").MustBool(false) {
+		s.registerAPIEndpoints()
+	}
+	return s
+}
+
+func (s *Service) registerAPIEndpoints() {
+	authorize := ac.Middleware(s.accessControl)
+
+	s.routeRegister.Group("/api/scim/v2", func(scim routing.RouteRegister) {
+		scim.Get("/ServiceProviderConfig", routing.Wrap(s.getServiceProviderConfig))
+		scim.Get("/ResourceTypes", routing.Wrap(s.getResourceTypes))
+
+		scim.Get("/Users", authorize(ac.EvalPermission(ac.ActionOrgUsersRead)), routing.Wrap(s.listUsers))
+		scim.Get("/Users/:id", authorize(ac.EvalPermission(ac.ActionOrgUsersRead)), routing.Wrap(s.getUser))
+		scim.Post("/Users", authorize(ac.EvalPermission(ac.ActionOrgUsersAdd)), routing.Wrap(s.createUser))
+		scim.Put("/Users/:id", authorize(ac.EvalPermission(ac.ActionOrgUsersWrite)), routing.Wrap(s.replaceUser))
+		scim.Patch("/Users/:id", authorize(ac.EvalPermission(ac.ActionOrgUsersWrite)), routing.Wrap(s.patchUser))
+		scim.Delete("/Users/:id", authorize(ac.EvalPermission(ac.ActionOrgUsersRemove)), routing.Wrap(s.deleteUser))
+
+		scim.Get("/Groups", authorize(ac.EvalPermission(ac.ActionTeamsRead)), routing.Wrap(s.listGroups))
+		scim.Get("/Groups/:id", authorize(ac.EvalPermission(ac.ActionTeamsRead)), routing.Wrap(s.getGroup))
+		scim.Post("/Groups", authorize(ac.EvalPermission(ac.ActionTeamsCreate)), routing.Wrap(s.createGroup))
+		scim.Put("/Groups/:id", authorize(ac.EvalAll(ac.EvalPermission(ac.ActionTeamsWrite), ac.EvalPermission(ac.ActionTeamsPermissionsWrite))), routing.Wrap(s.replaceGroup))
+		scim.Patch("/Groups/:id", authorize(ac.EvalAll(ac.EvalPermission(ac.ActionTeamsWrite), ac.EvalPermission(ac.ActionTeamsPermissionsWrite))), routing.Wrap(s.patchGroup))
+		scim.Delete("/Groups/:id", authorize(ac.EvalPermission(ac.ActionTeamsDelete)), routing.Wrap(s.deleteGroup))
+	}, middleware.ReqSignedIn, requireServiceAccount)
+}
+
+// requireServiceAccount only lets service accounts call SCIM endpoints, SCIM clients are identity providers and
+// not people
+func requireServiceAccount(c *contextmodel.ReqContext) {
+	if !c.SignedInUser.GetID().IsNamespace(identity.NamespaceServiceAccount) {
+		scimError(errForbidden.Errorf("identity %s is not a service account", c.SignedInUser.GetID())).WriteTo(c)
+	}
+}
+
+func (s *Service) getServiceProviderConfig(c *contextmodel.ReqContext) response.Response {
+	return scimJSON(http.StatusOK, map[string]any{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          map[string]any{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": maxCount},
+		"changePassword": map[string]any{"supported": false},
+		"sort":           map[string]any{"supported": false},
+		"etag":           map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with a Grafana service account token",
+		}},
+	})
+}
+
+func (s *Service) getResourceTypes(c *contextmodel.ReqContext) response.Response {
+	resources := []any{
+		map[string]any{
+			"schemas": []string{schemaResourceType}, "id": resourceTypeUser, "name": resourceTypeUser,
+			"endpoint": "/Users", "schema": schemaUser,
+		},
+		map[string]any{
+			"schemas": []string{schemaResourceType}, "id": resourceTypeGroup, "name": resourceTypeGroup,
+			"endpoint": "/Groups", "schema": schemaGroup,
+		},
+	}
+	return scimJSON(http.StatusOK, &ListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   1,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// listQuery holds the filtering and pagination parameters of list requests
+type listQuery struct {
+	filter     filter
+	startIndex int
+	count      int
+}
+
+func parseListQuery(c *contextmodel.ReqContext) (*listQuery, error) {
+	q := &listQuery{startIndex: 1, count: defaultCount}
+	if expr := c.Query("filter"); expr != "" {
+		f, err := parseFilter(expr)
+		if err != nil {
+			return nil, errInvalidFilter.Errorf("invalid filter %q: %w", expr, err)
+		}
+		q.filter = f
+	}
+	if v := c.Query("startIndex"); v != "" {
+		startIndex, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errInvalidValue.Errorf("invalid startIndex %q", v)
+		}
+		// RFC 7644 treats values below 1 as 1
+		q.startIndex = max(startIndex, 1)
+	}
+	if v := c.Query("count"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errInvalidValue.Errorf("invalid count %q", v)
+		}
+		q.count = min(max(count, 0), maxCount)
+	}
+	return q, nil
+}
+
+// page filters resources and returns the requested page of them
+func (q *listQuery) page(resources []any) (*ListResponse, error) {
+	matching := make([]any, 0, len(resources))
+	for _, r := range resources {
+		if q.filter != nil {
+			m, err := toMap(r)
+			if err != nil {
+				return nil, err
+			}
+			if !q.filter.matches(m) {
+				continue
+			}
+		}
+		matching = append(matching, r)
+	}
+
+	from := min(q.startIndex-1, len(matching))
+	to := min(from+q.count, len(matching))
+	return &ListResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(matching),
+		StartIndex:   q.startIndex,
+		ItemsPerPage: to - from,
+		Resources:    matching[from:to],
+	}, nil
+}
+
+func resourceID(c *contextmodel.ReqContext) (int64, error) {
+	id, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return 0, errNotFound.Errorf("invalid id %q", web.Params(c.Req)[":id"])
+	}
+	return id, nil
+}
+
+func decodeBody(c *contextmodel.ReqContext, v any) error {
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil {
+		return errInvalidSyntax.Errorf("failed to decode request body: %w", err)
+	}
+	return nil
+}
+
+func toMap(v any) (map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]any{}
+	return m, json.Unmarshal(data, &m)
+}
+
+func fromMap(m map[string]any, v any) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errInvalidValue.Errorf("invalid resource after patch: %w", err)
+	}
+	return nil
+}
+
+func scimJSON(status int, body any) *response.NormalResponse {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to encode SCIM response", err)
+	}
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	return response.CreateNormalResponse(header, data, status)
+}
+
+// scimError renders errors with the SCIM error schema, the scimType comes from the message ID of scim errors
+func (s *Service) scimError(err error) response.Response {
+	resp := scimError(err)
+	if resp.Status() >= http.StatusInternalServerError {
+		s.log.Error("SCIM request failed", "error", err)
+	}
+	return resp
+}
+
+func scimError(err error) response.Response {
+	status := http.StatusInternalServerError
+	body := &Error{Schemas: []string{schemaError}, Detail: "Internal server error"}
+
+	var grafanaErr errutil.Error
+	if errors.As(err, &grafanaErr) {
+		status = grafanaErr.Reason.Status().HTTPStatus()
+		if status < http.StatusInternalServerError {
+			body.Detail = grafanaErr.PublicMessage
+		}
+		if scimType, ok := strings.CutPrefix(grafanaErr.MessageID, "scim."); ok && (status == http.StatusBadRequest || status == http.StatusConflict) {
+			body.ScimType = scimType
+		}
+	}
+	body.Status = strconv.Itoa(status)
+
+	data, _ := json.Marshal(body)
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	return response.CreateNormalResponse(header, data, status)
+}
@@ -0,0 +1,434 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func TestSCIM_ServiceAccountsOnly(t *testing.T) {
+	client := setupTestClient(t, newFakeDirectory())
+	client.signedInUser = &user.SignedInUser{UserID: 1, OrgID: 1, Login: "admin"}
+
+	var scimErr Error
+	client.do(http.MethodGet, "/Users", nil, http.StatusForbidden, &scimErr)
+	assert.Equal(t, []string{schemaError}, scimErr.Schemas)
+	assert.Equal(t, "403", scimErr.Status)
+}
+
+func TestSCIM_Users(t *testing.T) {
+	dir := newFakeDirectory()
+	client := setupTestClient(t, dir)
+
+	var created User
+	client.do(http.MethodPost, "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "bjensen",
+		"name": {"givenName": "Barbara", "familyName": "Jensen"},
+		"emails": [{"value": "bjensen@example.com", "type": "work", "primary": true}],
+		"active": true
+	}`, http.StatusCreated, &created)
+	assert.Equal(t, "bjensen", created.UserName)
+	assert.Equal(t, "Barbara Jensen", created.DisplayName)
+	assert.Equal(t, "http://localhost:3000/api/scim/v2/Users/"+created.ID, created.Meta.Location)
+	require.True(t, *created.Active)
+
+	t.Run("should reject duplicated users", func(t *testing.T) {
+		var scimErr Error
+		client.do(http.MethodPost, "/Users", `{"userName": "bjensen"}`, http.StatusConflict, &scimErr)
+		assert.Equal(t, "uniqueness", scimErr.ScimType)
+	})
+
+	t.Run("should look up users by user name", func(t *testing.T) {
+		var list ListResponse
+		client.do(http.MethodGet, `/Users?filter=userName+eq+%22BJENSEN%22`, nil, http.StatusOK, &list)
+		assert.Equal(t, 1, list.TotalResults)
+
+		client.do(http.MethodGet, `/Users?filter=userName+eq+%22unknown%22`, nil, http.StatusOK, &list)
+		assert.Equal(t, 0, list.TotalResults)
+		assert.Empty(t, list.Resources)
+	})
+
+	t.Run("should reject invalid filters", func(t *testing.T) {
+		var scimErr Error
+		client.do(http.MethodGet, `/Users?filter=userName+eq`, nil, http.StatusBadRequest, &scimErr)
+		assert.Equal(t, "invalidFilter", scimErr.ScimType)
+	})
+
+	t.Run("should disable users with a patch", func(t *testing.T) {
+		var patched User
+		client.do(http.MethodPatch, "/Users/"+created.ID, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [{"op": "Replace", "path": "active", "value": "False"}]
+		}`, http.StatusOK, &patched)
+		assert.False(t, *patched.Active)
+		assert.Equal(t, []int64{dir.idOf("bjensen")}, dir.revokedSessions)
+
+		var list ListResponse
+		client.do(http.MethodGet, `/Users?filter=active+eq+false`, nil, http.StatusOK, &list)
+		assert.Equal(t, 1, list.TotalResults)
+	})
+
+	t.Run("should patch names and emails", func(t *testing.T) {
+		var patched User
+		client.do(http.MethodPatch, "/Users/"+created.ID, `{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "replace", "path": "name.givenName", "value": "Babs"},
+				{"op": "replace", "path": "name.familyName", "value": "Jensen"},
+				{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "babs@example.com"}
+			]
+		}`, http.StatusOK, &patched)
+		assert.Equal(t, "Babs Jensen", patched.DisplayName)
+		assert.Equal(t, "babs@example.com", patched.Emails[0].Value)
+	})
+
+	t.Run("should replace users", func(t *testing.T) {
+		var replaced User
+		client.do(http.MethodPut, "/Users/"+created.ID, `{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+			"userName": "barbara",
+			"displayName": "Barbara J",
+			"emails": [{"value": "barbara@example.com", "primary": true}],
+			"active": true
+		}`, http.StatusOK, &replaced)
+		assert.Equal(t, "barbara", replaced.UserName)
+		assert.Equal(t, "Barbara J", replaced.DisplayName)
+		assert.True(t, *replaced.Active)
+	})
+
+	t.Run("should not change server admins", func(t *testing.T) {
+		admin := dir.addUser(&userRecord{Login: "admin", GrafanaAdmin: true})
+		client.do(http.MethodPatch, fmt.Sprintf("/Users/%d", admin.ID), `{
+			"Operations": [{"op": "replace", "path": "active", "value": false}]
+		}`, http.StatusForbidden, nil)
+		client.do(http.MethodPatch, fmt.Sprintf("/Users/%d", admin.ID), `{
+			"Operations": [{"op": "replace", "path": "userName", "value": "root"}]
+		}`, http.StatusForbidden, nil)
+		client.do(http.MethodDelete, fmt.Sprintf("/Users/%d", admin.ID), nil, http.StatusForbidden, nil)
+		assert.Equal(t, "admin", dir.usersByID[admin.ID].Login)
+	})
+
+	t.Run("should not change users of other organizations", func(t *testing.T) {
+		shared := dir.addUser(&userRecord{Login: "shared", Email: "shared@example.com", OtherOrgs: true})
+		for _, body := range []string{
+			`{"Operations": [{"op": "replace", "path": "active", "value": false}]}`,
+			`{"Operations": [{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "attacker@example.com"}]}`,
+		} {
+			var scimErr Error
+			client.do(http.MethodPatch, fmt.Sprintf("/Users/%d", shared.ID), body, http.StatusForbidden, &scimErr)
+			assert.Equal(t, "403", scimErr.Status)
+		}
+		assert.False(t, dir.usersByID[shared.ID].Disabled)
+		assert.Equal(t, "shared@example.com", dir.usersByID[shared.ID].Email)
+
+		// replacing the user without changes succeeds
+		client.do(http.MethodPut, fmt.Sprintf("/Users/%d", shared.ID), `{
+			"userName": "shared",
+			"emails": [{"value": "shared@example.com", "primary": true}]
+		}`, http.StatusOK, nil)
+
+		// removing the user from the organization is allowed
+		client.do(http.MethodDelete, fmt.Sprintf("/Users/%d", shared.ID), nil, http.StatusNoContent, nil)
+	})
+
+	t.Run("should paginate users", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			dir.addUser(&userRecord{Login: fmt.Sprintf("user%d", i)})
+		}
+		var list ListResponse
+		client.do(http.MethodGet, `/Users?startIndex=3&count=2`, nil, http.StatusOK, &list)
+		assert.Equal(t, 7, list.TotalResults)
+		assert.Equal(t, 3, list.StartIndex)
+		assert.Equal(t, 2, list.ItemsPerPage)
+		assert.Len(t, list.Resources, 2)
+	})
+
+	t.Run("should delete users", func(t *testing.T) {
+		client.do(http.MethodDelete, "/Users/"+created.ID, nil, http.StatusNoContent, nil)
+
+		var scimErr Error
+		client.do(http.MethodGet, "/Users/"+created.ID, nil, http.StatusNotFound, &scimErr)
+		assert.Equal(t, "404", scimErr.Status)
+	})
+}
+
+func TestSCIM_Groups(t *testing.T) {
+	dir := newFakeDirectory()
+	client := setupTestClient(t, dir)
+	alice := dir.addUser(&userRecord{Login: "alice"})
+	bob := dir.addUser(&userRecord{Login: "bob"})
+	carol := dir.addUser(&userRecord{Login: "carol"})
+
+	var created Group
+	client.do(http.MethodPost, "/Groups", fmt.Sprintf(`{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+		"displayName": "Developers",
+		"members": [{"value": "%d"}, {"value": "%d"}]
+	}`, alice.ID, bob.ID), http.StatusCreated, &created)
+	assert.Equal(t, "Developers", created.DisplayName)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, memberLogins(created))
+
+	t.Run("should reject members that are not users of the organization", func(t *testing.T) {
+		var scimErr Error
+		client.do(http.MethodPost, "/Groups", `{"displayName": "Ops", "members": [{"value": "999"}]}`, http.StatusBadRequest, &scimErr)
+		assert.Equal(t, "invalidValue", scimErr.ScimType)
+	})
+
+	t.Run("should look up groups by display name", func(t *testing.T) {
+		var list ListResponse
+		client.do(http.MethodGet, `/Groups?filter=displayName+eq+%22Developers%22&excludedAttributes=members`, nil, http.StatusOK, &list)
+		require.Equal(t, 1, list.TotalResults)
+		group := list.Resources[0].(map[string]any)
+		assert.Equal(t, created.ID, group["id"])
+		assert.NotContains(t, group, "members")
+	})
+
+	t.Run("should add and remove members with a patch", func(t *testing.T) {
+		var patched Group
+		client.do(http.MethodPatch, "/Groups/"+created.ID, fmt.Sprintf(`{
+			"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+			"Operations": [
+				{"op": "add", "path": "members", "value": [{"value": "%d"}]},
+				{"op": "remove", "path": "members[value eq \"%d\"]"}
+			]
+		}`, carol.ID, alice.ID), http.StatusOK, &patched)
+		assert.ElementsMatch(t, []string{"bob", "carol"}, memberLogins(patched))
+	})
+
+	t.Run("should replace groups", func(t *testing.T) {
+		var replaced Group
+		client.do(http.MethodPut, "/Groups/"+created.ID, fmt.Sprintf(`{
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+			"displayName": "Engineering",
+			"members": [{"value": "%d"}]
+		}`, alice.ID), http.StatusOK, &replaced)
+		assert.Equal(t, "Engineering", replaced.DisplayName)
+		assert.Equal(t, []string{"alice"}, memberLogins(replaced))
+	})
+
+	t.Run("should delete groups", func(t *testing.T) {
+		client.do(http.MethodDelete, "/Groups/"+created.ID, nil, http.StatusNoContent, nil)
+		client.do(http.MethodGet, "/Groups/"+created.ID, nil, http.StatusNotFound, nil)
+	})
+}
+
+func memberLogins(g Group) []string {
+	logins := make([]string, 0, len(g.Members))
+	for _, m := range g.Members {
+		logins = append(logins, m.Display)
+	}
+	return logins
+}
+
+// testClient is an in-process SCIM client authenticated as a service account
+type testClient struct {
+	t            *testing.T
+	server       *webtest.Server
+	signedInUser *user.SignedInUser
+}
+
+func setupTestClient(t *testing.T, dir directory) *testClient {
+	t.Helper()
+
+	s := &Service{
+		dir:           dir,
+		routeRegister: routing.NewRouteRegister(),
+		accessControl: actest.FakeAccessControl{ExpectedEvaluate: true},
+		log:           log.NewNopLogger(),
+		baseURL:       "http://localhost:3000/api/scim/v2",
+	}
+	s.registerAPIEndpoints()
+
+	return &testClient{
+		t:            t,
+		server:       webtest.NewServer(t, s.routeRegister),
+		signedInUser: &user.SignedInUser{UserID: 100, OrgID: 1, Login: "sa-scim", IsServiceAccount: true},
+	}
+}
+
+func (c *testClient) do(method, path string, body any, expectedStatus int, result any) {
+	c.t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		reader = strings.NewReader(body.(string))
+	}
+	req := c.server.NewRequest(method, "/api/scim/v2"+path, reader)
+	req.Header.Set("Content-Type", contentType)
+	req = webtest.RequestWithSignedInUser(req, c.signedInUser)
+
+	resp, err := c.server.Send(req)
+	require.NoError(c.t, err)
+	defer func() { require.NoError(c.t, resp.Body.Close()) }()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	require.Equal(c.t, expectedStatus, resp.StatusCode, string(data))
+	if len(data) > 0 {
+		assert.Equal(c.t, contentType, resp.Header.Get("Content-Type"))
+	}
+	if result != nil {
+		require.NoError(c.t, json.Unmarshal(data, result))
+	}
+}
+
+// fakeDirectory stores the users and teams of a single organization in memory
+type fakeDirectory struct {
+	nextID          int64
+	usersByID       map[int64]*userRecord
+	teamsByID       map[int64]*teamRecord
+	members         map[int64]map[int64]bool
+	revokedSessions []int64
+}
+
+func newFakeDirectory() *fakeDirectory {
+	return &fakeDirectory{
+		usersByID: map[int64]*userRecord{},
+		teamsByID: map[int64]*teamRecord{},
+		members:   map[int64]map[int64]bool{},
+	}
+}
+
+func (d *fakeDirectory) addUser(u *userRecord) *userRecord {
+	d.nextID++
+	u.ID = d.nextID
+	u.Created, u.Updated = time.Now(), time.Now()
+	d.usersByID[u.ID] = u
+	return u
+}
+
+func (d *fakeDirectory) idOf(login string) int64 {
+	for _, u := range d.usersByID {
+		if u.Login == login {
+			return u.ID
+		}
+	}
+	return 0
+}
+
+func (d *fakeDirectory) users(_ context.Context, _ identity.Requester) ([]*userRecord, error) {
+	users := make([]*userRecord, 0, len(d.usersByID))
+	for _, u := range d.usersByID {
+		copied := *u
+		users = append(users, &copied)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users, nil
+}
+
+func (d *fakeDirectory) userByLogin(ctx context.Context, requester identity.Requester, login string) (*userRecord, error) {
+	for _, u := range d.usersByID {
+		if strings.EqualFold(u.Login, login) {
+			return d.user(ctx, requester, u.ID)
+		}
+	}
+	return nil, errNotFound.Errorf("user %s not found", login)
+}
+
+func (d *fakeDirectory) user(_ context.Context, _ identity.Requester, id int64) (*userRecord, error) {
+	u, ok := d.usersByID[id]
+	if !ok {
+		return nil, errNotFound.Errorf("user %d not found", id)
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (d *fakeDirectory) createUser(ctx context.Context, requester identity.Requester, u *userRecord) (*userRecord, error) {
+	if d.idOf(u.Login) != 0 {
+		return nil, errUniqueness.Errorf("user %s already exists", u.Login)
+	}
+	return d.user(ctx, requester, d.addUser(u).ID)
+}
+
+func (d *fakeDirectory) updateUser(_ context.Context, _ identity.Requester, u *userRecord) error {
+	current := d.usersByID[u.ID]
+	if u.Disabled && !current.Disabled {
+		d.revokedSessions = append(d.revokedSessions, u.ID)
+	}
+	current.Login, current.Email, current.Name, current.Disabled = u.Login, u.Email, u.Name, u.Disabled
+	return nil
+}
+
+func (d *fakeDirectory) removeUser(_ context.Context, _ identity.Requester, id int64) error {
+	delete(d.usersByID, id)
+	for _, members := range d.members {
+		delete(members, id)
+	}
+	return nil
+}
+
+func (d *fakeDirectory) teams(_ context.Context, _ identity.Requester, name string) ([]*teamRecord, error) {
+	var teams []*teamRecord
+	for _, t := range d.teamsByID {
+		if name == "" || t.Name == name {
+			copied := *t
+			teams = append(teams, &copied)
+		}
+	}
+	return teams, nil
+}
+
+func (d *fakeDirectory) team(_ context.Context, _ identity.Requester, id int64) (*teamRecord, error) {
+	t, ok := d.teamsByID[id]
+	if !ok {
+		return nil, errNotFound.Errorf("team %d not found", id)
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (d *fakeDirectory) teamMembers(_ context.Context, _ identity.Requester, teamID int64) ([]*memberRecord, error) {
+	var members []*memberRecord
+	for userID := range d.members[teamID] {
+		members = append(members, &memberRecord{UserID: userID, Login: d.usersByID[userID].Login})
+	}
+	return members, nil
+}
+
+func (d *fakeDirectory) createTeam(_ context.Context, _ identity.Requester, name string) (*teamRecord, error) {
+	d.nextID++
+	t := &teamRecord{ID: d.nextID, Name: name}
+	d.teamsByID[t.ID] = t
+	d.members[t.ID] = map[int64]bool{}
+	copied := *t
+	return &copied, nil
+}
+
+func (d *fakeDirectory) renameTeam(_ context.Context, _ identity.Requester, id int64, name string) error {
+	d.teamsByID[id].Name = name
+	return nil
+}
+
+func (d *fakeDirectory) deleteTeam(_ context.Context, _ identity.Requester, id int64) error {
+	delete(d.teamsByID, id)
+	delete(d.members, id)
+	return nil
+}
+
+func (d *fakeDirectory) addTeamMember(_ context.Context, _ identity.Requester, teamID, userID int64) error {
+	d.members[teamID][userID] = true
+	return nil
+}
+
+func (d *fakeDirectory) removeTeamMember(_ context.Context, _ identity.Requester, teamID, userID int64) error {
+	delete(d.members[teamID], userID)
+	return nil
+}
@@ -0,0 +1,236 @@
+package scim
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+func (s *Service) listUsers(c *contextmodel.ReqContext) response.Response {
+	q, err := parseListQuery(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+
+	var records []*userRecord
+	if login, ok := equalityFilter(q.filter, "userName"); ok {
+		u, err := s.dir.userByLogin(c.Req.Context(), c.SignedInUser, login)
+		if err != nil && !errors.Is(err, errNotFound) {
+			return s.scimError(err)
+		}
+		if u != nil {
+			records = append(records, u)
+		}
+	} else {
+		records, err = s.dir.users(c.Req.Context(), c.SignedInUser)
+		if err != nil {
+			return s.scimError(err)
+		}
+	}
+
+	resources := make([]any, 0, len(records))
+	for _, u := range records {
+		resources = append(resources, s.toUser(u))
+	}
+	result, err := q.page(resources)
+	if err != nil {
+		return s.scimError(err)
+	}
+	return scimJSON(http.StatusOK, result)
+}
+
+func (s *Service) getUser(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	u, err := s.dir.user(c.Req.Context(), c.SignedInUser, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	return scimJSON(http.StatusOK, s.toUser(u))
+}
+
+func (s *Service) createUser(c *contextmodel.ReqContext) response.Response {
+	var body User
+	if err := decodeBody(c, &body); err != nil {
+		return s.scimError(err)
+	}
+	record, err := fromUser(&body)
+	if err != nil {
+		return s.scimError(err)
+	}
+
+	created, err := s.dir.createUser(c.Req.Context(), c.SignedInUser, record)
+	if err != nil {
+		return s.scimError(err)
+	}
+	u := s.toUser(created)
+	return scimJSON(http.StatusCreated, u).SetHeader("Location", u.Meta.Location)
+}
+
+func (s *Service) replaceUser(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	var body User
+	if err := decodeBody(c, &body); err != nil {
+		return s.scimError(err)
+	}
+	return s.updateUser(c.Req.Context(), c.SignedInUser, id, &body)
+}
+
+func (s *Service) patchUser(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	var body PatchRequest
+	if err := decodeBody(c, &body); err != nil {
+		return s.scimError(err)
+	}
+
+	current, err := s.dir.user(c.Req.Context(), c.SignedInUser, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	before := s.toUser(current)
+	resource, err := toMap(before)
+	if err != nil {
+		return s.scimError(err)
+	}
+	if err := applyPatch(resource, body.Operations); err != nil {
+		return s.scimError(err)
+	}
+	// some identity providers send booleans as strings, such as "False"
+	if key := lookupKey(resource, "active"); resource[key] != nil {
+		if active, ok := resource[key].(string); ok {
+			resource[key] = strings.EqualFold(active, "true")
+		}
+	}
+
+	var patched User
+	if err := fromMap(resource, &patched); err != nil {
+		return s.scimError(err)
+	}
+	// the display name takes precedence over name, unless only name was patched
+	if patched.DisplayName == before.DisplayName && !reflect.DeepEqual(patched.Name, before.Name) {
+		patched.DisplayName = ""
+	}
+	return s.updateUser(c.Req.Context(), c.SignedInUser, id, &patched)
+}
+
+func (s *Service) updateUser(ctx context.Context, requester identity.Requester, id int64, u *User) response.Response {
+	record, err := fromUser(u)
+	if err != nil {
+		return s.scimError(err)
+	}
+	record.ID = id
+
+	current, err := s.dir.user(ctx, requester, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	// logins, emails, names and the disabled flag are global, the organization of the service account only
+	// owns the users that are not shared with other organizations
+	if changesUser(current, record) {
+		if current.GrafanaAdmin {
+			return s.scimError(errServerAdmin.Errorf("cannot change server admin %d", id))
+		}
+		if current.OtherOrgs {
+			return s.scimError(errSharedUser.Errorf("cannot change user %d that is a member of other organizations", id))
+		}
+	}
+	if err := s.dir.updateUser(ctx, requester, record); err != nil {
+		return s.scimError(err)
+	}
+
+	updated, err := s.dir.user(ctx, requester, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	return scimJSON(http.StatusOK, s.toUser(updated))
+}
+
+// changesUser tells whether applying the record changes the user, identity providers send the whole user
+// even when nothing changed
+func changesUser(current, record *userRecord) bool {
+	return current.Login != record.Login || current.Email != record.Email || current.Name != record.Name ||
+		current.Disabled != record.Disabled
+}
+
+func (s *Service) deleteUser(c *contextmodel.ReqContext) response.Response {
+	id, err := resourceID(c)
+	if err != nil {
+		return s.scimError(err)
+	}
+	current, err := s.dir.user(c.Req.Context(), c.SignedInUser, id)
+	if err != nil {
+		return s.scimError(err)
+	}
+	if current.GrafanaAdmin {
+		return s.scimError(errServerAdmin.Errorf("cannot remove server admin %d", id))
+	}
+	if err := s.dir.removeUser(c.Req.Context(), c.SignedInUser, id); err != nil {
+		return s.scimError(err)
+	}
+	return response.Empty(http.StatusNoContent)
+}
+
+func (s *Service) toUser(u *userRecord) *User {
+	active := !u.Disabled
+	created, updated := u.Created, u.Updated
+	result := &User{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Login,
+		DisplayName: u.Name,
+		Active:      &active,
+		Meta: &Meta{
+			ResourceType: resourceTypeUser,
+			Created:      &created,
+			LastModified: &updated,
+			Location:     s.baseURL + "/Users/" + strconv.FormatInt(u.ID, 10),
+		},
+	}
+	if u.Name != "" {
+		result.Name = &Name{Formatted: u.Name}
+	}
+	if u.Email != "" {
+		result.Emails = []Email{{Value: u.Email, Type: "work", Primary: true}}
+	}
+	return result
+}
+
+func fromUser(u *User) (*userRecord, error) {
+	if u.UserName == "" {
+		return nil, errInvalidValue.Errorf("userName is required")
+	}
+	record := &userRecord{
+		Login:    u.UserName,
+		Name:     u.DisplayName,
+		Disabled: u.Active != nil && !*u.Active,
+	}
+
+	if record.Name == "" && u.Name != nil {
+		record.Name = strings.TrimSpace(u.Name.GivenName + " " + u.Name.FamilyName)
+		if record.Name == "" {
+			record.Name = u.Name.Formatted
+		}
+	}
+
+	for _, email := range u.Emails {
+		if email.Primary || record.Email == "" {
+			record.Email = email.Value
+		}
+	}
+	return record, nil
+}
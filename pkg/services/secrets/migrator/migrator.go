@@ -44,6 +44,7 @@ func ProvideSecretsMigrator(
 		jsonSecret{tableName: "data_source"},
 		jsonSecret{tableName: "plugin_setting"},
 		b64Secret{simpleSecret: simpleSecret{tableName: "signing_key", columnName: "private_key"}, encoding: base64.StdEncoding},
+		b64Secret{simpleSecret: simpleSecret{tableName: "user_mfa", columnName: "secret"}, hasUpdatedColumn: true, encoding: base64.StdEncoding},
 		alertingSecret{},
 		ssoSettingsSecret{},
 	}
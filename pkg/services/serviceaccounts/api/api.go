@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/middleware/requestmeta"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -63,6 +64,7 @@ func (api *ServiceAccountsAPI) RegisterAPIEndpoints() {
 		serviceAccountsRoute.Get("/:serviceAccountId/tokens", auth(accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(api.ListTokens))
 		serviceAccountsRoute.Post("/:serviceAccountId/tokens", auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.CreateToken))
 		serviceAccountsRoute.Delete("/:serviceAccountId/tokens/:tokenId", auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.DeleteToken))
+		serviceAccountsRoute.Post("/:serviceAccountId/tokens/:tokenId/rotate", auth(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(api.RotateToken))
 		serviceAccountsRoute.Post("/migrate", auth(accesscontrol.EvalPermission(serviceaccounts.ActionCreate)), routing.Wrap(api.MigrateApiKeysToServiceAccounts))
 		serviceAccountsRoute.Post("/migrate/:keyId", auth(accesscontrol.EvalPermission(serviceaccounts.ActionCreate)), routing.Wrap(api.ConvertToServiceAccount))
 	}, requestmeta.SetOwner(requestmeta.TeamAuth))
@@ -116,6 +118,8 @@ func (api *ServiceAccountsAPI) CreateServiceAccount(c *contextmodel.ReqContext)
 	// Clear permission cache for the user who's created the service account, so that new permissions are fetched for their next call
 	// Required for cases when caller wants to immediately interact with the newly created object
 	api.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, serviceAccountAuditResource(serviceAccount.Id, serviceAccount.Name), nil,
+		&serviceAccountAudit{Name: serviceAccount.Name, Login: serviceAccount.Login, Role: serviceAccount.Role, IsDisabled: serviceAccount.IsDisabled})
 
 	return response.JSON(http.StatusCreated, serviceAccount)
 }
@@ -191,10 +195,13 @@ func (api *ServiceAccountsAPI) UpdateServiceAccount(c *contextmodel.ReqContext)
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to update service account", err)
 	}
 
+	before := api.serviceAccountAuditBefore(c, scopeID)
 	resp, err := api.service.UpdateServiceAccount(c.Req.Context(), c.SignedInUser.GetOrgID(), scopeID, &cmd)
 	if err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, "Failed update service account", err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, serviceAccountAuditResource(resp.Id, resp.Name), before,
+		&serviceAccountAudit{Name: resp.Name, Login: resp.Login, Role: resp.Role, IsDisabled: resp.IsDisabled})
 
 	saIDString := strconv.FormatInt(resp.Id, 10)
 	metadata := api.getAccessControlMetadata(c, map[string]bool{saIDString: true})
@@ -237,13 +244,44 @@ func (api *ServiceAccountsAPI) DeleteServiceAccount(ctx *contextmodel.ReqContext
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Service account ID is invalid", err)
 	}
+	before := api.serviceAccountAuditBefore(ctx, scopeID)
 	err = api.service.DeleteServiceAccount(ctx.Req.Context(), ctx.SignedInUser.GetOrgID(), scopeID)
 	if err != nil {
 		return response.Error(http.StatusInternalServerError, "Service account deletion error", err)
 	}
+	resource := serviceAccountAuditResource(scopeID, "")
+	if before != nil {
+		resource.Name = before.Name
+	}
+	auditlog.Annotate(ctx.Req.Context(), auditlog.ActionDelete, resource, before, nil)
 	return response.Success("Service account deleted")
 }
 
+func serviceAccountAuditResource(id int64, name string) auditlog.Resource {
+	return auditlog.Resource{Kind: "serviceaccounts", UID: strconv.FormatInt(id, 10), Name: name}
+}
+
+// serviceAccountAudit describes a service account in the audit log
+type serviceAccountAudit struct {
+	Name       string `json:"name"`
+	Login      string `json:"login"`
+	Role       string `json:"role"`
+	IsDisabled bool   `json:"isDisabled"`
+}
+
+// serviceAccountAuditBefore returns the summary of a service account before it is changed, it is only loaded
+// when the request is recorded in the audit log
+func (api *ServiceAccountsAPI) serviceAccountAuditBefore(c *contextmodel.ReqContext, id int64) *serviceAccountAudit {
+	if !auditlog.IsAudited(c.Req.Context()) {
+		return nil
+	}
+	sa, err := api.service.RetrieveServiceAccount(c.Req.Context(), c.SignedInUser.GetOrgID(), id)
+	if err != nil {
+		return nil
+	}
+	return &serviceAccountAudit{Name: sa.Name, Login: sa.Login, Role: sa.Role, IsDisabled: sa.IsDisabled}
+}
+
 // swagger:route GET /serviceaccounts/search service_accounts searchOrgServiceAccountsWithPaging
 //
 // # Search service accounts with paging
@@ -8,6 +8,8 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/auditlog"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/web"
@@ -36,6 +38,16 @@ type TokenDTO struct {
 	HasExpired bool `json:"hasExpired"`
 	// example: false
 	IsRevoked *bool `json:"isRevoked"`
+	// IsRotationDue is set when the token is older than the configured rotation age
+	// example: false
+	IsRotationDue bool `json:"isRotationDue"`
+	// example: 10.0.0.1
+	LastUsedIP string `json:"lastUsedIp,omitempty"`
+	// Permissions the token is restricted to, the token has all the permissions of the service account when empty
+	Permissions []apikey.Permission `json:"permissions,omitempty"`
+	// Networks the token can be used from, the token can be used from anywhere when empty
+	// example: ["10.0.0.0/8"]
+	AllowedCIDRs []string `json:"allowedCidrs,omitempty"`
 }
 
 func hasExpired(expiration *int64) bool {
@@ -104,6 +116,10 @@ func (api *ServiceAccountsAPI) ListTokens(ctx *contextmodel.ReqContext) response
 			HasExpired:             isExpired,
 			LastUsedAt:             token.LastUsedAt,
 			IsRevoked:              token.IsRevoked,
+			IsRotationDue:          token.RotationDue,
+			LastUsedIP:             token.LastUsedIP,
+			Permissions:            token.Permissions,
+			AllowedCIDRs:           token.AllowedCIDRs,
 		}
 	}
 
@@ -144,21 +160,8 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 	// Force affected service account to be the one referenced in the URL
 	cmd.OrgId = c.SignedInUser.GetOrgID()
 
-	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
-		if cmd.SecondsToLive == 0 {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
-		}
-		if cmd.SecondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
-			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
-		}
-	}
-
-	if api.cfg.SATokenExpirationDayLimit > 0 {
-		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
-		expirationDate := time.Now().Add(time.Duration(cmd.SecondsToLive) * time.Second).Truncate(24 * time.Hour)
-		if expirationDate.After(dayExpireLimit) {
-			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
-		}
+	if errResp := api.validateSecondsToLive(cmd.SecondsToLive); errResp != nil {
+		return errResp
 	}
 
 	newKeyInfo, err := satokengen.New(ServiceID)
@@ -173,6 +176,10 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to add service account token", err)
 	}
 
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionCreate, serviceAccountTokenAuditResource(apiKey.ID, apiKey.Name), nil,
+		map[string]any{"serviceAccountId": saID, "name": apiKey.Name, "secondsToLive": cmd.SecondsToLive,
+			"permissions": cmd.Permissions, "allowedCidrs": cmd.AllowedCIDRs})
+
 	result := &dtos.NewApiKeyResult{
 		ID:   apiKey.ID,
 		Name: apiKey.Name,
@@ -217,10 +224,102 @@ func (api *ServiceAccountsAPI) DeleteToken(c *contextmodel.ReqContext) response.
 	if err = api.service.DeleteServiceAccountToken(c.Req.Context(), c.SignedInUser.GetOrgID(), saID, tokenID); err != nil {
 		return response.ErrOrFallback(http.StatusInternalServerError, failedToDeleteMsg, err)
 	}
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionDelete, serviceAccountTokenAuditResource(tokenID, ""),
+		map[string]any{"serviceAccountId": saID}, nil)
 
 	return response.Success("Service account token deleted")
 }
 
+// swagger:route POST /serviceaccounts/{serviceAccountId}/tokens/{tokenId}/rotate service_accounts rotateToken
+//
+// # RotateToken replaces a service account token by a new one
+//
+// The new token has the name, permissions and allowed networks of the rotated token. The rotated token is renamed
+// and keeps working until the end of the overlap window, one hour by default.
+//
+// Required permissions (See note in the [introduction](https://grafana.com/docs/grafana/latest/developers/http_api/serviceaccount/#service-account-api) for an explanation):
+// action: `serviceaccounts:write` scope: `serviceaccounts:id:1` (single service account)
+//
+// Responses:
+// 200: createTokenResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (api *ServiceAccountsAPI) RotateToken(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	tokenID, err := strconv.ParseInt(web.Params(c.Req)[":tokenId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Token ID is invalid", err)
+	}
+
+	cmd := serviceaccounts.RotateServiceAccountTokenCommand{}
+	if err = web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "Bad request data", err)
+	}
+	cmd.OrgId = c.SignedInUser.GetOrgID()
+
+	// the lifetime of the rotated token is kept when none is set
+	if cmd.SecondsToLive != 0 {
+		if errResp := api.validateSecondsToLive(cmd.SecondsToLive); errResp != nil {
+			return errResp
+		}
+	}
+	if cmd.OverlapSeconds < 0 {
+		return response.Error(http.StatusBadRequest, "Number of seconds of overlap can't be negative", nil)
+	}
+
+	newKeyInfo, err := satokengen.New(ServiceID)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Generating service account token failed", err)
+	}
+	cmd.Key = newKeyInfo.HashedKey
+
+	apiKey, err := api.service.RotateServiceAccountToken(c.Req.Context(), cmd.OrgId, saID, tokenID, &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to rotate service account token", err)
+	}
+
+	auditlog.Annotate(c.Req.Context(), auditlog.ActionUpdate, serviceAccountTokenAuditResource(tokenID, apiKey.Name),
+		map[string]any{"serviceAccountId": saID}, map[string]any{"rotatedBy": apiKey.ID, "overlapSeconds": cmd.OverlapSeconds})
+
+	return response.JSON(http.StatusOK, &dtos.NewApiKeyResult{
+		ID:   apiKey.ID,
+		Name: apiKey.Name,
+		Key:  newKeyInfo.ClientSecret,
+	})
+}
+
+// validateSecondsToLive checks the lifetime of a new token against the limits of the configuration
+func (api *ServiceAccountsAPI) validateSecondsToLive(secondsToLive int64) response.Response {
+	if api.cfg.ApiKeyMaxSecondsToLive != -1 {
+		if secondsToLive == 0 {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration should be set", nil)
+		}
+		if secondsToLive > api.cfg.ApiKeyMaxSecondsToLive {
+			return response.Error(http.StatusBadRequest, "Number of seconds before expiration is greater than the global limit", nil)
+		}
+	}
+
+	if api.cfg.SATokenExpirationDayLimit > 0 {
+		dayExpireLimit := time.Now().Add(time.Duration(api.cfg.SATokenExpirationDayLimit) * time.Hour * 24).Truncate(24 * time.Hour)
+		expirationDate := time.Now().Add(time.Duration(secondsToLive) * time.Second).Truncate(24 * time.Hour)
+		if expirationDate.After(dayExpireLimit) {
+			return response.Respond(http.StatusBadRequest, "The expiration date input exceeds the limit for service account access tokens expiration date")
+		}
+	}
+	return nil
+}
+
+func serviceAccountTokenAuditResource(id int64, name string) auditlog.Resource {
+	return auditlog.Resource{Kind: "serviceaccounts.tokens", UID: strconv.FormatInt(id, 10), Name: name}
+}
+
 // swagger:parameters listTokens
 type ListTokensParams struct {
 	// in:path
@@ -243,6 +342,16 @@ type DeleteTokenParams struct {
 	ServiceAccountId int64 `json:"serviceAccountId"`
 }
 
+// swagger:parameters rotateToken
+type RotateTokenParams struct {
+	// in:path
+	TokenId int64 `json:"tokenId"`
+	// in:path
+	ServiceAccountId int64 `json:"serviceAccountId"`
+	// in:body
+	Body serviceaccounts.RotateServiceAccountTokenCommand
+}
+
 // swagger:response listTokensResponse
 type ListTokensResponse struct {
 	// in:body
@@ -126,6 +126,69 @@ func TestServiceAccountsAPI_CreateToken(t *testing.T) {
 	}
 }
 
+func TestServiceAccountsAPI_RotateToken(t *testing.T) {
+	type TestCase struct {
+		desc           string
+		saID           int64
+		body           string
+		permissions    []accesscontrol.Permission
+		expectedErr    error
+		expectedAPIKey *apikey.APIKey
+		expectedCode   int
+	}
+
+	tests := []TestCase{
+		{
+			desc:           "should be able to rotate service account token with correct permission",
+			saID:           1,
+			body:           `{"overlapSeconds": 600}`,
+			permissions:    []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedAPIKey: &apikey.APIKey{ID: 3, Name: "test"},
+			expectedCode:   http.StatusOK,
+		},
+		{
+			desc:         "should not be able to rotate service account token with wrong permission",
+			saID:         2,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not be able to rotate service account token with a negative overlap",
+			saID:         1,
+			body:         `{"overlapSeconds": -1}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			desc:         "should not be able to rotate service account token that is revoked",
+			saID:         1,
+			body:         `{}`,
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:1"}},
+			expectedErr:  serviceaccounts.ErrCannotRotateToken.Errorf(""),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTests(t, func(a *ServiceAccountsAPI) {
+				a.service = &satests.FakeServiceAccountService{
+					ExpectedErr:    tt.expectedErr,
+					ExpectedAPIKey: tt.expectedAPIKey,
+				}
+			})
+			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/%d/tokens/2/rotate", tt.saID), strings.NewReader(tt.body))
+			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tt.permissions)}})
+			res, err := server.SendJSON(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
 func TestServiceAccountsAPI_DeleteToken(t *testing.T) {
 	type TestCase struct {
 		desc         string
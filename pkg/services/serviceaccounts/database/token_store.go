@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -56,6 +57,8 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 			Key:              cmd.Key,
 			SecondsToLive:    cmd.SecondsToLive,
 			ServiceAccountID: &serviceAccountId,
+			Permissions:      cmd.Permissions,
+			AllowedCIDRs:     cmd.AllowedCIDRs,
 		}
 
 		key, err := s.apiKeyService.AddAPIKey(ctx, addKeyCmd)
@@ -75,6 +78,82 @@ func (s *ServiceAccountsStoreImpl) AddServiceAccountToken(ctx context.Context, s
 	})
 }
 
+// RotateServiceAccountToken adds a token with the name and restrictions of an existing one. The existing token is
+// renamed and expires at the end of the overlap window.
+func (s *ServiceAccountsStoreImpl) RotateServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	var apiKey *apikey.APIKey
+	err := s.sqlStore.InTransaction(ctx, func(ctx context.Context) error {
+		var rotated apikey.APIKey
+		err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			exists, err := sess.Where("id=? AND org_id=? AND service_account_id=?", tokenId, orgId, serviceAccountId).Get(&rotated)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return serviceaccounts.ErrServiceAccountTokenNotFound.Errorf("service account token with id %d not found for service account with id %d", tokenId, serviceAccountId)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if (rotated.IsRevoked != nil && *rotated.IsRevoked) || (rotated.Expires != nil && *rotated.Expires <= now.Unix()) {
+			return serviceaccounts.ErrCannotRotateToken.Errorf("service account token with id %d is revoked or expired", tokenId)
+		}
+
+		secondsToLive := cmd.SecondsToLive
+		if secondsToLive == 0 && rotated.Expires != nil {
+			secondsToLive = *rotated.Expires - rotated.Created.Unix()
+		}
+
+		overlap := serviceaccounts.DefaultTokenRotationOverlap
+		if cmd.OverlapSeconds > 0 {
+			overlap = time.Duration(cmd.OverlapSeconds) * time.Second
+		}
+		expires := now.Add(overlap).Unix()
+		if rotated.Expires != nil && *rotated.Expires < expires {
+			expires = *rotated.Expires
+		}
+
+		// the name is freed for the new token, the rotated one can still be told apart in the list of tokens
+		name := rotated.Name
+		err = s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.ID(rotated.ID).Cols("name", "expires", "updated").Update(&apikey.APIKey{
+				Name:    fmt.Sprintf("%s-rotated-%d", name, rotated.ID),
+				Expires: &expires,
+				Updated: now,
+			})
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		key, err := s.apiKeyService.AddAPIKey(ctx, &apikey.AddCommand{
+			Name:             name,
+			Role:             rotated.Role,
+			OrgID:            orgId,
+			Key:              cmd.Key,
+			SecondsToLive:    secondsToLive,
+			ServiceAccountID: &serviceAccountId,
+			Permissions:      rotated.Permissions,
+			AllowedCIDRs:     rotated.AllowedCIDRs,
+		})
+		if err != nil {
+			if errors.Is(err, apikey.ErrInvalidExpiration) {
+				return serviceaccounts.ErrInvalidTokenExpiration.Errorf("invalid service account token expiration value %d", secondsToLive)
+			}
+			return err
+		}
+
+		apiKey = key
+		return nil
+	})
+	return apiKey, err
+}
+
 func (s *ServiceAccountsStoreImpl) DeleteServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error {
 	rawSQL := "DELETE FROM api_key WHERE id=? and org_id=? and service_account_id=?"
 
@@ -109,6 +188,25 @@ func (s *ServiceAccountsStoreImpl) RevokeServiceAccountToken(ctx context.Context
 	})
 }
 
+// FlagTokensDueForRotation flags the service account tokens created before createdBefore that are still valid as due
+// for rotation, it returns the number of newly flagged tokens
+func (s *ServiceAccountsStoreImpl) FlagTokensDueForRotation(ctx context.Context, createdBefore time.Time) (int64, error) {
+	rawSQL := "UPDATE api_key SET rotation_due = ? WHERE service_account_id IS NOT NULL AND rotation_due = ? AND created < ?" +
+		" AND (is_revoked IS NULL OR is_revoked = ?) AND (expires IS NULL OR expires > ?)"
+
+	var affected int64
+	err := s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		dialect := s.sqlStore.GetDialect()
+		result, err := sess.Exec(rawSQL, dialect.BooleanStr(true), dialect.BooleanStr(false), createdBefore, dialect.BooleanStr(false), time.Now().Unix())
+		if err != nil {
+			return err
+		}
+		affected, err = result.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
 // assignApiKeyToServiceAccount sets the API key service account ID
 func (s *ServiceAccountsStoreImpl) assignApiKeyToServiceAccount(ctx context.Context, apiKeyId int64, serviceAccountId int64) error {
 	return s.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
@@ -2,11 +2,14 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/apikeygen"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
 )
@@ -176,3 +179,99 @@ func TestStore_DeleteServiceAccountToken(t *testing.T) {
 		}
 	}
 }
+
+func TestStore_RotateServiceAccountToken(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	keyName := t.Name()
+	key, err := apikeygen.New(sa.OrgID, keyName)
+	require.NoError(t, err)
+
+	cmd := serviceaccounts.AddServiceAccountTokenCommand{
+		Name:          keyName,
+		OrgId:         sa.OrgID,
+		Key:           key.HashedKey,
+		SecondsToLive: 86400,
+		Permissions:   []apikey.Permission{{Action: "dashboards:write", Scope: "folders:uid:ci"}},
+		AllowedCIDRs:  []string{"10.0.0.0/8"},
+	}
+
+	oldKey, err := store.AddServiceAccountToken(context.Background(), sa.ID, &cmd)
+	require.NoError(t, err)
+
+	_, err = store.RotateServiceAccountToken(context.Background(), sa.OrgID, sa.ID+1, oldKey.ID, &serviceaccounts.RotateServiceAccountTokenCommand{Key: "rotated"})
+	require.ErrorIs(t, err, serviceaccounts.ErrServiceAccountTokenNotFound)
+
+	newKey, err := store.RotateServiceAccountToken(context.Background(), sa.OrgID, sa.ID, oldKey.ID, &serviceaccounts.RotateServiceAccountTokenCommand{
+		Key:            "rotated",
+		OverlapSeconds: 600,
+	})
+	require.NoError(t, err)
+	require.Equal(t, keyName, newKey.Name)
+	require.Equal(t, cmd.Permissions, newKey.Permissions)
+	require.Equal(t, cmd.AllowedCIDRs, newKey.AllowedCIDRs)
+	require.NotNil(t, newKey.Expires)
+	require.InDelta(t, *oldKey.Expires, *newKey.Expires, 5, "the new token should live as long as the rotated one")
+
+	keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+		OrgID:            &sa.OrgID,
+		ServiceAccountID: &sa.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	for _, k := range keys {
+		if k.ID == oldKey.ID {
+			require.Equal(t, fmt.Sprintf("%s-rotated-%d", keyName, oldKey.ID), k.Name)
+			require.InDelta(t, time.Now().Add(10*time.Minute).Unix(), *k.Expires, 5, "the rotated token should expire after the overlap")
+		}
+	}
+
+	err = store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, newKey.ID)
+	require.NoError(t, err)
+	_, err = store.RotateServiceAccountToken(context.Background(), sa.OrgID, sa.ID, newKey.ID, &serviceaccounts.RotateServiceAccountTokenCommand{Key: "rotated-again"})
+	require.ErrorIs(t, err, serviceaccounts.ErrCannotRotateToken)
+}
+
+func TestStore_FlagTokensDueForRotation(t *testing.T) {
+	userToCreate := tests.TestUser{Login: "servicetestwithTeam@admin", IsServiceAccount: true}
+	db, store := setupTestDatabase(t)
+	sa := tests.SetupUserServiceAccount(t, db, store.cfg, userToCreate)
+
+	tokens := make([]*apikey.APIKey, 0, 2)
+	for _, name := range []string{"due", "revoked"} {
+		key, err := apikeygen.New(sa.OrgID, name)
+		require.NoError(t, err)
+		token, err := store.AddServiceAccountToken(context.Background(), sa.ID, &serviceaccounts.AddServiceAccountTokenCommand{
+			Name:  name,
+			OrgId: sa.OrgID,
+			Key:   key.HashedKey,
+		})
+		require.NoError(t, err)
+		tokens = append(tokens, token)
+	}
+	require.NoError(t, store.RevokeServiceAccountToken(context.Background(), sa.OrgID, sa.ID, tokens[1].ID))
+
+	flagged, err := store.FlagTokensDueForRotation(context.Background(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, flagged, "tokens younger than the rotation age should not be flagged")
+
+	flagged, err = store.FlagTokensDueForRotation(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, int64(1), flagged)
+
+	flagged, err = store.FlagTokensDueForRotation(context.Background(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Zero(t, flagged, "flagged tokens should not be flagged again")
+
+	keys, err := store.ListTokens(context.Background(), &serviceaccounts.GetSATokensQuery{
+		OrgID:            &sa.OrgID,
+		ServiceAccountID: &sa.ID,
+	})
+	require.NoError(t, err)
+	for _, k := range keys {
+		require.Equal(t, k.ID == tokens[0].ID, k.RotationDue)
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/kvstore"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
 	"github.com/grafana/grafana/pkg/infra/usagestats"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
@@ -23,6 +25,7 @@ import (
 const (
 	metricsCollectionInterval = time.Minute * 30
 	defaultSecretScanInterval = time.Minute * 5
+	tokenRotationInterval     = time.Hour
 )
 
 type ServiceAccountsService struct {
@@ -31,9 +34,12 @@ type ServiceAccountsService struct {
 	log               log.Logger
 	backgroundLog     log.Logger
 	secretScanService secretscan.Checker
+	serverLock        *serverlock.ServerLockService
 
 	secretScanEnabled  bool
 	secretScanInterval time.Duration
+	// tokenRotationAge is the age after which tokens are flagged as due for rotation, disabled when zero
+	tokenRotationAge time.Duration
 }
 
 func ProvideServiceAccountsService(
@@ -45,6 +51,7 @@ func ProvideServiceAccountsService(
 	userService user.Service,
 	orgService org.Service,
 	accesscontrolService accesscontrol.Service,
+	serverLock *serverlock.ServerLockService,
 ) (*ServiceAccountsService, error) {
 	serviceAccountsStore := database.ProvideServiceAccountsStore(
 		cfg,
@@ -59,6 +66,10 @@ func ProvideServiceAccountsService(
 		store:         serviceAccountsStore,
 		log:           log.New("serviceaccounts"),
 		backgroundLog: log.New("serviceaccounts.background"),
+		serverLock:    serverLock,
+	}
+	if cfg.SATokenRotationDayLimit > 0 {
+		s.tokenRotationAge = time.Duration(cfg.SATokenRotationDayLimit) * 24 * time.Hour
 	}
 
 	if err := RegisterRoles(accesscontrolService); err != nil {
@@ -114,6 +125,15 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 		defer tokenCheckTicker.Stop()
 	}
 
+	tokenRotationTicker := time.NewTicker(tokenRotationInterval)
+	if sa.tokenRotationAge == 0 {
+		tokenRotationTicker.Stop()
+	} else {
+		sa.flagTokensDueForRotation(ctx)
+
+		defer tokenRotationTicker.Stop()
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -136,7 +156,30 @@ func (sa *ServiceAccountsService) Run(ctx context.Context) error {
 			if err := sa.secretScanService.CheckTokens(ctx); err != nil {
 				sa.backgroundLog.Warn("Failed to check for leaked tokens", "error", err.Error())
 			}
+		case <-tokenRotationTicker.C:
+			sa.flagTokensDueForRotation(ctx)
+		}
+	}
+}
+
+// flagTokensDueForRotation flags the tokens older than the rotation age. Tokens are not rotated automatically, the
+// new token could not be handed to its clients, they keep working until they are rotated through the API.
+func (sa *ServiceAccountsService) flagTokensDueForRotation(ctx context.Context) {
+	// only one instance flags the tokens of an HA setup in each interval
+	err := sa.serverLock.LockAndExecute(ctx, "flag service account tokens due for rotation", tokenRotationInterval, func(ctx context.Context) {
+		sa.backgroundLog.Debug("Flagging tokens due for rotation")
+
+		flagged, err := sa.store.FlagTokensDueForRotation(ctx, time.Now().Add(-sa.tokenRotationAge))
+		if err != nil {
+			sa.backgroundLog.Warn("Failed to flag tokens due for rotation", "error", err.Error())
+			return
+		}
+		if flagged > 0 {
+			sa.backgroundLog.Info("Flagged tokens due for rotation", "count", flagged)
 		}
+	})
+	if err != nil {
+		sa.backgroundLog.Warn("Failed to acquire lock to flag tokens due for rotation", "error", err.Error())
 	}
 }
 
@@ -218,9 +261,31 @@ func (sa *ServiceAccountsService) AddServiceAccountToken(ctx context.Context, se
 	if err := validServiceAccountID(serviceAccountID); err != nil {
 		return nil, err
 	}
+	if err := validTokenPermissions(query.Permissions); err != nil {
+		return nil, err
+	}
+	if err := validTokenAllowedCIDRs(query.AllowedCIDRs); err != nil {
+		return nil, err
+	}
 	return sa.store.AddServiceAccountToken(ctx, serviceAccountID, query)
 }
 
+func (sa *ServiceAccountsService) RotateServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if err := validOrgID(orgID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountID(serviceAccountID); err != nil {
+		return nil, err
+	}
+	if err := validServiceAccountTokenID(tokenID); err != nil {
+		return nil, err
+	}
+	if cmd.SecondsToLive < 0 {
+		return nil, serviceaccounts.ErrInvalidTokenExpiration.Errorf("invalid service account token expiration value %d", cmd.SecondsToLive)
+	}
+	return sa.store.RotateServiceAccountToken(ctx, orgID, serviceAccountID, tokenID, cmd)
+}
+
 func (sa *ServiceAccountsService) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID int64, tokenID int64) error {
 	if err := validOrgID(orgID); err != nil {
 		return err
@@ -268,6 +333,28 @@ func validServiceAccountTokenID(tokenID int64) error {
 	}
 	return nil
 }
+
+func validTokenPermissions(permissions []apikey.Permission) error {
+	for _, p := range permissions {
+		if p.Action == "" {
+			return serviceaccounts.ErrInvalidTokenPermissions.Errorf("token permissions must have an action")
+		}
+		if p.Scope != "" && !accesscontrol.ValidateScope(p.Scope) {
+			return serviceaccounts.ErrInvalidTokenPermissions.Errorf("invalid scope %s for action %s", p.Scope, p.Action)
+		}
+	}
+	return nil
+}
+
+func validTokenAllowedCIDRs(cidrs []string) error {
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return serviceaccounts.ErrInvalidTokenAllowedCIDRs.Errorf("invalid allowed network %s: %w", cidr, err)
+		}
+	}
+	return nil
+}
+
 func validAPIKeyID(apiKeyID int64) error {
 	if apiKeyID == 0 {
 		return serviceaccounts.ErrServiceAccountInvalidAPIKeyID.Errorf("invalid API key ID 0 has been specified")
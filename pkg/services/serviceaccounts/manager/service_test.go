@@ -3,6 +3,7 @@ package manager
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	"github.com/stretchr/testify/require"
@@ -98,6 +99,16 @@ func (f *FakeServiceAccountStore) AddServiceAccountToken(ctx context.Context, se
 	return f.ExpectedAPIKey, f.ExpectedError
 }
 
+// RotateServiceAccountToken is a fake rotating a service account token.
+func (f *FakeServiceAccountStore) RotateServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedError
+}
+
+// FlagTokensDueForRotation is a fake flagging the service account tokens due for rotation.
+func (f *FakeServiceAccountStore) FlagTokensDueForRotation(ctx context.Context, createdBefore time.Time) (int64, error) {
+	return 0, f.ExpectedError
+}
+
 // DeleteServiceAccountToken is a fake deleting a service account token.
 func (f *FakeServiceAccountStore) DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error {
 	return f.ExpectedError
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
@@ -13,6 +14,7 @@ type store interface {
 	DeleteServiceAccount(ctx context.Context, orgID, serviceAccountID int64) error
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
 	EnableServiceAccount(ctx context.Context, orgID, serviceAccountID int64, enable bool) error
+	FlagTokensDueForRotation(ctx context.Context, createdBefore time.Time) (int64, error)
 	GetUsageMetrics(ctx context.Context) (*serviceaccounts.Stats, error)
 	ListTokens(ctx context.Context, query *serviceaccounts.GetSATokensQuery) ([]apikey.APIKey, error)
 	MigrateApiKey(ctx context.Context, orgID int64, keyId int64) error
@@ -20,6 +22,7 @@ type store interface {
 	RetrieveServiceAccount(ctx context.Context, orgID, serviceAccountID int64) (*serviceaccounts.ServiceAccountProfileDTO, error)
 	RetrieveServiceAccountIdByName(ctx context.Context, orgID int64, name string) (int64, error)
 	RevokeServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64) error
+	RotateServiceAccountToken(ctx context.Context, orgId, serviceAccountId, tokenId int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error)
 	SearchOrgServiceAccounts(ctx context.Context, query *serviceaccounts.SearchOrgServiceAccountsQuery) (*serviceaccounts.SearchOrgServiceAccountsResult, error)
 	UpdateServiceAccount(ctx context.Context, orgID, serviceAccountID int64,
 		saForm *serviceaccounts.UpdateServiceAccountForm) (*serviceaccounts.ServiceAccountProfileDTO, error)
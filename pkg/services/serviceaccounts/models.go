@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/models/roletype"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/apikey"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/extsvcauth"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -43,8 +44,14 @@ var (
 	ErrServiceAccountTokenNotFound       = errutil.NotFound("serviceaccounts.ErrTokenNotFound", errutil.WithPublicMessage("service account token not found"))
 	ErrInvalidTokenExpiration            = errutil.ValidationFailed("serviceaccounts.ErrInvalidInput", errutil.WithPublicMessage("invalid SecondsToLive value"))
 	ErrDuplicateToken                    = errutil.BadRequest("serviceaccounts.ErrTokenAlreadyExists", errutil.WithPublicMessage("service account token with given name already exists in the organization"))
+	ErrInvalidTokenPermissions           = errutil.BadRequest("serviceaccounts.ErrInvalidTokenPermissions", errutil.WithPublicMessage("invalid service account token permissions"))
+	ErrInvalidTokenAllowedCIDRs          = errutil.BadRequest("serviceaccounts.ErrInvalidTokenAllowedCIDRs", errutil.WithPublicMessage("allowed networks must be in CIDR notation, such as 10.0.0.0/8"))
+	ErrCannotRotateToken                 = errutil.BadRequest("serviceaccounts.ErrCannotRotateToken", errutil.WithPublicMessage("revoked or expired service account tokens can't be rotated"))
 )
 
+// DefaultTokenRotationOverlap is how long a rotated token keeps working when no overlap is requested
+const DefaultTokenRotationOverlap = time.Hour
+
 type MigrationResult struct {
 	Total           int      `json:"total"`
 	Migrated        int      `json:"migrated"`
@@ -108,6 +115,19 @@ type AddServiceAccountTokenCommand struct {
 	OrgId         int64  `json:"-"`
 	Key           string `json:"-"`
 	SecondsToLive int64  `json:"secondsToLive"`
+	// Permissions restrict the token to a subset of the permissions of the service account
+	Permissions []apikey.Permission `json:"permissions,omitempty"`
+	// AllowedCIDRs restrict the networks the token can be used from, such as 10.0.0.0/8
+	AllowedCIDRs []string `json:"allowedCidrs,omitempty"`
+}
+
+type RotateServiceAccountTokenCommand struct {
+	OrgId int64  `json:"-"`
+	Key   string `json:"-"`
+	// SecondsToLive of the new token, it defaults to the lifetime of the rotated token
+	SecondsToLive int64 `json:"secondsToLive"`
+	// OverlapSeconds is how long the rotated token keeps working, it defaults to one hour
+	OverlapSeconds int64 `json:"overlapSeconds"`
 }
 
 type SearchOrgServiceAccountsQuery struct {
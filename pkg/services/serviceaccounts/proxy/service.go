@@ -105,6 +105,21 @@ func (s *ServiceAccountsProxy) DeleteServiceAccountToken(ctx context.Context, or
 	return s.proxiedService.DeleteServiceAccountToken(ctx, orgID, serviceAccountID, tokenID)
 }
 
+func (s *ServiceAccountsProxy) RotateServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	if s.isProxyEnabled {
+		sa, err := s.proxiedService.RetrieveServiceAccount(ctx, orgID, serviceAccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		if isExternalServiceAccount(sa.Login) {
+			s.log.Error("unable to rotate tokens for external service accounts", "serviceAccountID", serviceAccountID)
+			return nil, extsvcaccounts.ErrCannotCreateToken
+		}
+	}
+	return s.proxiedService.RotateServiceAccountToken(ctx, orgID, serviceAccountID, tokenID, cmd)
+}
+
 func (s *ServiceAccountsProxy) EnableServiceAccount(ctx context.Context, orgID int64, serviceAccountID int64, enable bool) error {
 	if s.isProxyEnabled {
 		sa, err := s.proxiedService.RetrieveServiceAccount(ctx, orgID, serviceAccountID)
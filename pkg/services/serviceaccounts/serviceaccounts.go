@@ -28,6 +28,10 @@ type Service interface {
 	AddServiceAccountToken(ctx context.Context, serviceAccountID int64,
 		cmd *AddServiceAccountTokenCommand) (*apikey.APIKey, error)
 	DeleteServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64) error
+	// RotateServiceAccountToken replaces a token by a new one with the same restrictions,
+	// the rotated token keeps working during the overlap window
+	RotateServiceAccountToken(ctx context.Context, orgID, serviceAccountID, tokenID int64,
+		cmd *RotateServiceAccountTokenCommand) (*apikey.APIKey, error)
 	ListTokens(ctx context.Context, query *GetSATokensQuery) ([]apikey.APIKey, error)
 
 	// API specific functions
@@ -25,6 +25,10 @@ func (f *FakeServiceAccountService) AddServiceAccountToken(ctx context.Context,
 	return f.ExpectedAPIKey, f.ExpectedErr
 }
 
+func (f *FakeServiceAccountService) RotateServiceAccountToken(ctx context.Context, orgID, id, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	return f.ExpectedAPIKey, f.ExpectedErr
+}
+
 func (f *FakeServiceAccountService) CreateServiceAccount(ctx context.Context, orgID int64, saForm *serviceaccounts.CreateServiceAccountForm) (*serviceaccounts.ServiceAccountDTO, error) {
 	return f.ExpectedServiceAccount, f.ExpectedErr
 }
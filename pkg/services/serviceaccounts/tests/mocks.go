@@ -227,6 +227,32 @@ func (_m *MockServiceAccountService) RetrieveServiceAccountIdByName(ctx context.
 	return r0, r1
 }
 
+// RotateServiceAccountToken provides a mock function with given fields: ctx, orgID, serviceAccountID, tokenID, cmd
+func (_m *MockServiceAccountService) RotateServiceAccountToken(ctx context.Context, orgID int64, serviceAccountID int64, tokenID int64, cmd *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error) {
+	ret := _m.Called(ctx, orgID, serviceAccountID, tokenID, cmd)
+
+	var r0 *apikey.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, *serviceaccounts.RotateServiceAccountTokenCommand) (*apikey.APIKey, error)); ok {
+		return rf(ctx, orgID, serviceAccountID, tokenID, cmd)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, int64, *serviceaccounts.RotateServiceAccountTokenCommand) *apikey.APIKey); ok {
+		r0 = rf(ctx, orgID, serviceAccountID, tokenID, cmd)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikey.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64, int64, *serviceaccounts.RotateServiceAccountTokenCommand) error); ok {
+		r1 = rf(ctx, orgID, serviceAccountID, tokenID, cmd)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SearchOrgServiceAccounts provides a mock function with given fields: ctx, query
 func (_m *MockServiceAccountService) SearchOrgServiceAccounts(ctx context.Context, query *serviceaccounts.SearchOrgServiceAccountsQuery) (*serviceaccounts.SearchOrgServiceAccountsResult, error) {
 	ret := _m.Called(ctx, query)
@@ -196,4 +196,20 @@ func AddMigration(mg *migrator.Migrator) {
 		Type: migrator.UniqueIndex,
 		Cols: []string{"role_id", "action", "scope"},
 	}))
+
+	mg.AddMigration("add column expires_at to permission table", migrator.NewAddColumnMigration(permissionV1, &migrator.Column{
+		Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add permission expires_at index", migrator.NewAddIndexMigration(permissionV1, &migrator.Index{
+		Cols: []string{"expires_at"},
+	}))
+
+	mg.AddMigration("add column expires_at to user_role table", migrator.NewAddColumnMigration(userRoleV1, &migrator.Column{
+		Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	mg.AddMigration("add column expires_at to team_role table", migrator.NewAddColumnMigration(teamRoleV1, &migrator.Column{
+		Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
 }
@@ -0,0 +1,53 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAnnotationImportMigrations(mg *Migrator) {
+	annotationWebhookV1 := Table{
+		Name: "annotation_webhook",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "mapping", Type: DB_Text, Nullable: false},
+			{Name: "tags", Type: DB_Text, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create annotation webhook table v1", NewAddTableMigration(annotationWebhookV1))
+	addTableIndicesMigrations(mg, "v1", annotationWebhookV1)
+
+	mg.AddMigration("add token_hash column to annotation_webhook", NewAddColumnMigration(annotationWebhookV1, &Column{
+		Name: "token_hash", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
+	mg.AddMigration("add created_by column to annotation_webhook", NewAddColumnMigration(annotationWebhookV1, &Column{
+		Name: "created_by", Type: DB_BigInt, Nullable: false, Default: "0",
+	}))
+	// webhook payloads are posted without a signed in user, the webhook is looked up by its uid alone
+	mg.AddMigration("add index annotation_webhook.uid", NewAddIndexMigration(annotationWebhookV1, &Index{
+		Cols: []string{"uid"},
+	}))
+
+	annotationExternalIDV1 := Table{
+		Name: "annotation_external_id",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "source", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "external_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "source", "external_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create annotation external id table v1", NewAddTableMigration(annotationExternalIDV1))
+	addTableIndicesMigrations(mg, "v1", annotationExternalIDV1)
+}
@@ -0,0 +1,28 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAnnotationRetentionMigrations(mg *Migrator) {
+	annotationRetentionPolicyV1 := Table{
+		Name: "annotation_retention_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "tags", Type: DB_Text, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "max_age", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "max_count", Type: DB_BigInt, Nullable: false},
+			{Name: "provisioned", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create annotation retention policy table v1", NewAddTableMigration(annotationRetentionPolicyV1))
+	addTableIndicesMigrations(mg, "v1", annotationRetentionPolicyV1)
+}
@@ -100,4 +100,20 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add is_revoked column to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "is_revoked", Type: DB_Bool, Nullable: true, Default: "0",
 	}))
+
+	mg.AddMigration("Add permissions to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "permissions", Type: DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("Add allowed_cidrs to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "allowed_cidrs", Type: DB_Text, Nullable: true,
+	}))
+
+	mg.AddMigration("Add last_used_ip to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "last_used_ip", Type: DB_NVarchar, Length: 64, Nullable: true,
+	}))
+
+	mg.AddMigration("Add rotation_due to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "rotation_due", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
 }
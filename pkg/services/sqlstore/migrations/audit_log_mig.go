@@ -0,0 +1,39 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addAuditLogMigrations(mg *Migrator) {
+	auditLogV1 := Table{
+		Name: "audit_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "actor", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "resource_kind", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_uid", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "resource_name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "before_state", Type: DB_Text, Nullable: true},
+			{Name: "after_state", Type: DB_Text, Nullable: true},
+			{Name: "method", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "path", Type: DB_Text, Nullable: false},
+			{Name: "route", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "status", Type: DB_Int, Nullable: false},
+			{Name: "remote_addr", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "user_agent", Type: DB_Text, Nullable: false},
+			{Name: "trace_id", Type: DB_NVarchar, Length: 64, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"created"}},
+			{Cols: []string{"org_id", "resource_kind", "resource_uid"}},
+		},
+	}
+
+	mg.AddMigration("create audit_log table", NewAddTableMigration(auditLogV1))
+	mg.AddMigration("add index audit_log.org_id_created", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[0]))
+	mg.AddMigration("add index audit_log.created", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[1]))
+	mg.AddMigration("add index audit_log.org_id_resource_kind_resource_uid", NewAddIndexMigration(auditLogV1, auditLogV1.Indices[2]))
+}
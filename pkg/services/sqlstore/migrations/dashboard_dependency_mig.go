@@ -0,0 +1,43 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addDashboardDependencyMigrations(mg *Migrator) {
+	dashboardDependencyV1 := Table{
+		Name: "dashboard_dependency",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false},
+			{Name: "panel_title", Type: DB_Text, Nullable: true},
+			{Name: "kind", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "target_uid", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "target_type", Type: DB_NVarchar, Length: 255, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "kind", "target_uid"}},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard dependency table v1", NewAddTableMigration(dashboardDependencyV1))
+	addTableIndicesMigrations(mg, "v1", dashboardDependencyV1)
+
+	dashboardDependencyIndexV1 := Table{
+		Name: "dashboard_dependency_index",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "version", Type: DB_Int, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "dashboard_uid"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create dashboard dependency index table v1", NewAddTableMigration(dashboardDependencyIndexV1))
+	addTableIndicesMigrations(mg, "v1", dashboardDependencyIndexV1)
+}
@@ -96,4 +96,51 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add expires_at column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "expires_at",
+		Type:     DB_DateTime,
+		Nullable: true,
+	}))
+
+	mg.AddMigration("add max_views column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "max_views",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add view_count column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "view_count",
+		Type:     DB_BigInt,
+		Nullable: false,
+		Default:  "0",
+	}))
+
+	mg.AddMigration("add allowed_ips column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "allowed_ips",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	var dashboardPublicAccessLogV1 = Table{
+		Name: "dashboard_public_access_log",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "panel_id", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "ip_address", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "user_agent", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "accessed_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"public_dashboard_uid", "accessed_at"}},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public access log table v1", NewAddTableMigration(dashboardPublicAccessLogV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicAccessLogV1)
 }
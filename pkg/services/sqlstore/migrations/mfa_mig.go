@@ -0,0 +1,41 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addMFAMigrations(mg *Migrator) {
+	userMFAV1 := Table{
+		Name: "user_mfa",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "recovery_codes", Type: DB_Text, Nullable: true},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "last_used_step", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create user_mfa table", NewAddTableMigration(userMFAV1))
+	mg.AddMigration("add unique index user_mfa.user_id", NewAddIndexMigration(userMFAV1, userMFAV1.Indices[0]))
+
+	mfaOrgSettingV1 := Table{
+		Name: "mfa_org_setting",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "required", Type: DB_Bool, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create mfa_org_setting table", NewAddTableMigration(mfaOrgSettingV1))
+	mg.AddMigration("add unique index mfa_org_setting.org_id", NewAddIndexMigration(mfaOrgSettingV1, mfaOrgSettingV1.Indices[0]))
+}
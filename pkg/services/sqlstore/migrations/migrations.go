@@ -123,6 +123,15 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	addScheduledReportMigrations(mg)
+	addDashboardDependencyMigrations(mg)
+	addAnnotationImportMigrations(mg)
+	addAnnotationRetentionMigrations(mg)
+	addPlaylistItemScheduleMigrations(mg)
+	addMFAMigrations(mg)
+	addAuditLogMigrations(mg)
+	addAccessRequestMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -60,6 +60,23 @@ func addPlaylistUIDMigration(mg *Migrator) {
 	}))
 }
 
+func addPlaylistItemScheduleMigrations(mg *Migrator) {
+	playlistItem := Table{Name: "playlist_item"}
+
+	mg.AddMigration("Add playlist_item column duration", NewAddColumnMigration(playlistItem, &Column{
+		Name: "duration", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
+	mg.AddMigration("Add playlist_item column schedule", NewAddColumnMigration(playlistItem, &Column{
+		Name: "schedule", Type: DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("Add playlist_item column variables", NewAddColumnMigration(playlistItem, &Column{
+		Name: "variables", Type: DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("Add playlist_item column time_range", NewAddColumnMigration(playlistItem, &Column{
+		Name: "time_range", Type: DB_Text, Nullable: true,
+	}))
+}
+
 func playlistV2() Table {
 	return Table{
 		Name: "playlist",
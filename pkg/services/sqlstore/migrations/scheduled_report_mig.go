@@ -0,0 +1,59 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addScheduledReportMigrations(mg *Migrator) {
+	scheduledReportV1 := Table{
+		Name: "scheduled_report",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "time_from", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "time_to", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "schedule", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "timezone", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+			{Name: "format", Type: DB_NVarchar, Length: 10, Nullable: false},
+			{Name: "message", Type: DB_Text, Nullable: true},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "next_run_at", Type: DB_DateTime, Nullable: true},
+			{Name: "last_run_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "dashboard_uid"}},
+			{Cols: []string{"enabled", "next_run_at"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled report table v1", NewAddTableMigration(scheduledReportV1))
+	addTableIndicesMigrations(mg, "v1", scheduledReportV1)
+
+	scheduledReportHistoryV1 := Table{
+		Name: "scheduled_report_history",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "report_id", Type: DB_BigInt, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "sent_at", Type: DB_DateTime, Nullable: false},
+			{Name: "sent_by", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "status", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "error", Type: DB_Text, Nullable: true},
+			{Name: "recipients", Type: DB_Text, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"report_id", "sent_at"}},
+			{Cols: []string{"org_id"}},
+		},
+	}
+
+	mg.AddMigration("create scheduled report history table v1", NewAddTableMigration(scheduledReportHistoryV1))
+	addTableIndicesMigrations(mg, "v1", scheduledReportHistoryV1)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -143,7 +144,8 @@ func (f *accessControlDashboardPermissionFilter) buildClauses() {
 
 	orgID := f.user.GetOrgID()
 	filter, params := accesscontrol.UserRolesFilter(orgID, userID, f.user.GetTeams(), accesscontrol.GetOrgRoles(f.user))
-	rolesFilter := " AND role_id IN(SELECT id FROM role " + filter + ") "
+	rolesFilter := " AND (permission.expires_at = 0 OR permission.expires_at > ?) AND role_id IN(SELECT id FROM role " + filter + ") "
+	params = append([]any{time.Now().Unix()}, params...)
 	var args []any
 	builder := strings.Builder{}
 	builder.WriteRune('(')
@@ -153,7 +155,9 @@ func (f *accessControlDashboardPermissionFilter) buildClauses() {
 
 	// useSelfContainedPermissions is true if the user's permissions are stored and set from the JWT token
 	// currently it's used for the extended JWT module (when the user is authenticated via a JWT token generated by Grafana)
-	useSelfContainedPermissions := f.user.IsAuthenticatedBy(login.ExtendedJWTModule)
+	// and for identities whose permissions are restricted by their credentials, the stored permissions of their roles
+	// would grant access beyond the restriction
+	useSelfContainedPermissions := f.user.IsAuthenticatedBy(login.ExtendedJWTModule) || f.user.HasRestrictedPermissions()
 
 	if len(f.dashboardActions) > 0 {
 		toCheck := actionsToCheck(f.dashboardActions, f.user.GetPermissions(), dashWildcards, folderWildcards)
@@ -3,6 +3,7 @@ package permissions
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
@@ -41,7 +42,8 @@ func (f *accessControlDashboardPermissionFilterNoFolderSubquery) buildClauses()
 
 	orgID := f.user.GetOrgID()
 	filter, params := accesscontrol.UserRolesFilter(orgID, userID, f.user.GetTeams(), accesscontrol.GetOrgRoles(f.user))
-	rolesFilter := " AND role_id IN(SELECT id FROM role " + filter + ") "
+	rolesFilter := " AND (permission.expires_at = 0 OR permission.expires_at > ?) AND role_id IN(SELECT id FROM role " + filter + ") "
+	params = append([]any{time.Now().Unix()}, params...)
 	var args []any
 	builder := strings.Builder{}
 	builder.WriteRune('(')
@@ -51,7 +53,8 @@ func (f *accessControlDashboardPermissionFilterNoFolderSubquery) buildClauses()
 
 	// useSelfContainedPermissions is true if the user's permissions are stored and set from the JWT token
 	// currently it's used for the extended JWT module (when the user is authenticated via a JWT token generated by Grafana)
-	useSelfContainedPermissions := f.user.GetAuthenticatedBy() == login.ExtendedJWTModule
+	// and for identities whose permissions are restricted by their credentials
+	useSelfContainedPermissions := f.user.GetAuthenticatedBy() == login.ExtendedJWTModule || f.user.HasRestrictedPermissions()
 
 	if len(f.dashboardActions) > 0 {
 		toCheck := actionsToCheck(f.dashboardActions, f.user.GetPermissions(), dashWildcards, folderWildcards)
@@ -377,6 +377,59 @@ func TestIntegration_DashboardPermissionFilter_WithSelfContainedPermissions(t *t
 	}
 }
 
+func TestIntegration_DashboardPermissionFilter_WithRestrictedPermissions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	// the role of the user grants more than the token it authenticated with
+	store := setupTest(t, 10, 110, []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:31"},
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:32"},
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:33"},
+	})
+	recursiveQueriesAreSupported, err := store.RecursiveQueriesAreSupported()
+	require.NoError(t, err)
+
+	tokenPermissions := []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:31"},
+	}
+
+	for _, restricted := range []bool{false, true} {
+		usr := &user.SignedInUser{
+			OrgID: 1, OrgRole: org.RoleViewer, AuthenticatedBy: login.APIKeyAuthModule, RestrictedPermissions: restricted,
+			Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByAction(tokenPermissions)},
+		}
+		expectedResult := 3
+		if restricted {
+			expectedResult = 1
+		}
+
+		for _, features := range []featuremgmt.FeatureToggles{featuremgmt.WithFeatures(), featuremgmt.WithFeatures(featuremgmt.FlagPermissionsFilterRemoveSubquery)} {
+			t.Run("restricted "+strconv.FormatBool(restricted), func(t *testing.T) {
+				filter := permissions.NewAccessControlDashboardPermissionFilter(usr, dashboardaccess.PERMISSION_VIEW, searchstore.TypeDashboard, features, recursiveQueriesAreSupported)
+
+				var result int
+				err = store.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+					q, params := filter.Where()
+					recQry, recQryParams := filter.With()
+					params = append(recQryParams, params...)
+					s := recQry + "\nSELECT COUNT(*) FROM dashboard WHERE " + q
+					leftJoin := filter.LeftJoin()
+					if leftJoin != "" {
+						s = recQry + "\nSELECT COUNT(*) FROM dashboard LEFT OUTER JOIN " + leftJoin + " WHERE " + q
+					}
+					_, err := sess.SQL(s, params...).Get(&result)
+					return err
+				})
+				require.NoError(t, err)
+
+				assert.Equal(t, expectedResult, result)
+			})
+		}
+	}
+}
+
 func TestIntegration_DashboardNestedPermissionFilter(t *testing.T) {
 	testCases := []struct {
 		desc           string
@@ -7,6 +7,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	"github.com/grafana/grafana/pkg/services/dashboards/dashboardaccess"
 	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
 // Typed errors
@@ -19,6 +20,9 @@ var (
 	ErrNotAllowedToUpdateTeamInDifferentOrg = errors.New("user not allowed to update team in another org")
 
 	ErrTeamMemberAlreadyAdded = errors.New("user is already added to this team")
+
+	ErrTeamMemberExternallyManaged = errutil.BadRequest("team.memberExternallyManaged",
+		errutil.WithPublicMessage("The team membership is managed by the identity provider"))
 )
 
 const MemberPermissionName = "Member"
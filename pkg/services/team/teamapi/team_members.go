@@ -52,7 +52,7 @@ func (tapi *TeamAPI) getTeamMembers(c *contextmodel.ReqContext) response.Respons
 		member.AvatarURL = dtos.GetGravatarUrl(tapi.cfg, member.Email)
 		member.Labels = []string{}
 
-		if tapi.license.FeatureEnabled("teamgroupsync") && member.External {
+		if member.External {
 			authProvider := login.GetAuthProviderLabel(member.AuthModule)
 			member.Labels = append(member.Labels, authProvider)
 		}
@@ -137,7 +137,7 @@ func (tapi *TeamAPI) updateTeamMember(c *contextmodel.ReqContext) response.Respo
 
 	err = addOrUpdateTeamMember(c.Req.Context(), tapi.teamPermissionsService, userId, orgId, teamId, getPermissionName(cmd.Permission))
 	if err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to update team member.", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update team member.", err)
 	}
 	return response.Success("Team member updated")
 }
@@ -213,6 +213,10 @@ func (tapi *TeamAPI) getTeamMembershipUpdates(ctx context.Context, orgID, teamID
 			}
 			continue
 		}
+		// memberships synced from an identity provider are left to the sync
+		if member.External {
+			continue
+		}
 		membersToRemove = append(membersToRemove, member.UserID)
 	}
 
@@ -293,7 +297,7 @@ func (tapi *TeamAPI) removeTeamMember(c *contextmodel.ReqContext) response.Respo
 			return response.Error(http.StatusNotFound, "Team member not found", nil)
 		}
 
-		return response.Error(http.StatusInternalServerError, "Failed to remove Member from Team", err)
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to remove Member from Team", err)
 	}
 	return response.Success("Team Member removed")
 }
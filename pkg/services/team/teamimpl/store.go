@@ -3,6 +3,7 @@ package teamimpl
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -435,6 +436,23 @@ func updateTeamMember(sess *db.Session, orgID, teamID, userID int64, permission
 	return err
 }
 
+// PreventExternalTeamMemberUpdateHook is called from team resource permission service
+// it fails when the membership of the user is managed by an external system, such as team sync
+func PreventExternalTeamMemberUpdateHook(sess *db.Session, userID, orgID, teamID int64) error {
+	member, err := getTeamMember(sess, orgID, teamID, userID)
+	if err != nil {
+		if errors.Is(err, team.ErrTeamMemberNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if member.External {
+		return team.ErrTeamMemberExternallyManaged.Errorf("membership of user %d in team %d is managed externally", userID, teamID)
+	}
+	return nil
+}
+
 // RemoveTeamMemberHook is called from team resource permission service
 // it removes a member from a team within the given transaction session
 func RemoveTeamMemberHook(sess *db.Session, cmd *team.RemoveTeamMemberCommand) error {
@@ -198,6 +198,40 @@ func TestIntegrationTeamCommandsAndQueries(t *testing.T) {
 				require.Equal(t, qAfterUpdateResult[0].Permission, dashboardaccess.PERMISSION_ADMIN)
 			})
 
+			t.Run("Should not be able to update external memberships", func(t *testing.T) {
+				sqlStore = db.InitTestDB(t)
+				setup()
+
+				err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+					if err := AddOrUpdateTeamMemberHook(sess, userIds[3], testOrgID, team2.ID, true, 0); err != nil {
+						return err
+					}
+					return AddOrUpdateTeamMemberHook(sess, userIds[4], testOrgID, team2.ID, false, 0)
+				})
+				require.NoError(t, err)
+
+				err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+					return PreventExternalTeamMemberUpdateHook(sess, userIds[3], testOrgID, team2.ID)
+				})
+				require.ErrorIs(t, err, team.ErrTeamMemberExternallyManaged)
+
+				err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+					if err := PreventExternalTeamMemberUpdateHook(sess, userIds[4], testOrgID, team2.ID); err != nil {
+						return err
+					}
+					return PreventExternalTeamMemberUpdateHook(sess, userIds[2], testOrgID, team2.ID)
+				})
+				require.NoError(t, err)
+
+				err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+					if err := RemoveTeamMemberHook(sess, &team.RemoveTeamMemberCommand{OrgID: testOrgID, UserID: userIds[3], TeamID: team2.ID}); err != nil {
+						return err
+					}
+					return RemoveTeamMemberHook(sess, &team.RemoveTeamMemberCommand{OrgID: testOrgID, UserID: userIds[4], TeamID: team2.ID})
+				})
+				require.NoError(t, err)
+			})
+
 			t.Run("Should default to member permission level when updating a user with invalid permission level", func(t *testing.T) {
 				sqlStore = db.InitTestDB(t)
 				setup()
@@ -41,6 +41,9 @@ type SignedInUser struct {
 	// Will only be set when featuremgmt.FlagIdForwarding is enabled.
 	IDToken      string `json:"-" xorm:"-"`
 	NamespacedID identity.NamespaceID
+	// RestrictedPermissions is set when Permissions are restricted by the credentials of the user, such as a
+	// service account token with permissions.
+	RestrictedPermissions bool `json:"-" xorm:"-"`
 }
 
 func (u *SignedInUser) ShouldUpdateLastSeenAt() bool {
@@ -225,6 +228,10 @@ func (u *SignedInUser) IsAuthenticatedBy(providers ...string) bool {
 	return false
 }
 
+func (u *SignedInUser) HasRestrictedPermissions() bool {
+	return u.RestrictedPermissions
+}
+
 // FIXME: remove this method once all services are using an interface
 func (u *SignedInUser) IsNil() bool {
 	return u == nil
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -110,6 +111,7 @@ type Cfg struct {
 	Domain            string
 	CDNRootURL        *url.URL
 	ReadTimeout       time.Duration
+	TrustedProxies    []*net.IPNet
 	EnableGzip        bool
 	EnforceDomain     bool
 	MinTLSVersion     string
@@ -180,6 +182,15 @@ type Cfg struct {
 	DisableGravatar                  bool
 	DataProxyWhiteList               map[string]bool
 
+	// Brute force login protection, logins are throttled by username, IP address and subnet with a lockout that
+	// doubles with every failed attempt past the limit
+	BruteForceLoginProtectionMaxAttempts        int64
+	BruteForceLoginProtectionIPMaxAttempts      int64
+	BruteForceLoginProtectionSubnetMaxAttempts  int64
+	BruteForceLoginProtectionLockoutDuration    time.Duration
+	BruteForceLoginProtectionMaxLockoutDuration time.Duration
+	BruteForceLoginProtectionNotifyUser         bool
+
 	TempDataLifetime time.Duration
 
 	// Plugins
@@ -226,6 +237,7 @@ type Cfg struct {
 	LoginMaxInactiveLifetime      time.Duration
 	LoginMaxLifetime              time.Duration
 	TokenRotationIntervalMinutes  int
+	SessionGeoIPDatabasePath      string
 	SigV4AuthEnabled              bool
 	SigV4VerboseLogging           bool
 	AzureAuthEnabled              bool
@@ -311,6 +323,8 @@ type Cfg struct {
 
 	// Service Accounts
 	SATokenExpirationDayLimit int
+	// SATokenRotationDayLimit is the age in days after which service account tokens are flagged as due for rotation
+	SATokenRotationDayLimit int
 
 	// Annotations
 	AnnotationCleanupJobBatchSize      int64
@@ -424,6 +438,9 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LivePipelineEnabled runs the channel rules of the Live pipeline, which are
+	// read from the live-channel-rules.json file of the data path.
+	LivePipelineEnabled bool
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -442,10 +459,15 @@ type Cfg struct {
 	// Query history
 	QueryHistoryEnabled bool
 
+	// Scheduled reports
+	ScheduledReportsEnabled bool
+
 	Storage StorageSettings
 
 	Search SearchSettings
 
+	QueryCaching QueryCachingSettings
+
 	SecureSocksDSProxy SecureSocksDSProxySettings
 
 	// SAML Auth
@@ -468,6 +490,8 @@ type Cfg struct {
 	RBACResetBasicRoles bool
 	// RBAC single organization. This configuration option is subject to change.
 	RBACSingleOrganization bool
+	// Longest duration a permission can be granted for through an access request
+	RBACAccessRequestMaxDuration time.Duration
 
 	// GRPC Server.
 	GRPCServerNetwork        string
@@ -490,6 +514,8 @@ type Cfg struct {
 
 	// Public dashboards
 	PublicDashboardsEnabled bool
+	// PublicDashboardsAccessLogMaxAge is how long entries of the public dashboards access log are kept, 0 keeps them forever
+	PublicDashboardsAccessLogMaxAge time.Duration
 
 	// Cloud Migration
 	CloudMigration CloudMigrationSettings
@@ -1164,6 +1190,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	queryHistory := iniFile.Section("query_history")
 	cfg.QueryHistoryEnabled = queryHistory.Key("enabled").MustBool(true)
 
+	scheduledReports := iniFile.Section("scheduled_reports")
+	cfg.ScheduledReportsEnabled = scheduledReports.Key("enabled").MustBool(true)
+
 	shortLinks := iniFile.Section("short_links")
 	cfg.ShortLinkExpiration = shortLinks.Key("expire_time").MustInt(7)
 
@@ -1221,6 +1250,7 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.Storage = readStorageSettings(iniFile)
 	cfg.Search = readSearchSettings(iniFile)
+	cfg.QueryCaching = readQueryCachingSettings(iniFile)
 
 	var err error
 	cfg.SecureSocksDSProxy, err = readSecureSocksDSProxySettings(iniFile)
@@ -1289,7 +1319,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.UserFacingDefaultError = logSection.Key("user_facing_default_error").MustString("please inspect Grafana server log for details")
 
 	cfg.readFeatureManagementConfig()
-	cfg.readPublicDashboardsSettings()
+	if err := cfg.readPublicDashboardsSettings(); err != nil {
+		return err
+	}
 	cfg.readCloudMigrationSettings()
 
 	// read experimental scopes settings.
@@ -1472,6 +1504,12 @@ func readSecuritySettings(iniFile *ini.File, cfg *Cfg) error {
 	cfg.SecretKey = valueAsString(security, "secret_key", "")
 	cfg.DisableGravatar = security.Key("disable_gravatar").MustBool(true)
 	cfg.DisableBruteForceLoginProtection = security.Key("disable_brute_force_login_protection").MustBool(false)
+	cfg.BruteForceLoginProtectionMaxAttempts = security.Key("brute_force_login_protection_max_attempts").MustInt64(5)
+	cfg.BruteForceLoginProtectionIPMaxAttempts = security.Key("brute_force_login_protection_ip_max_attempts").MustInt64(100)
+	cfg.BruteForceLoginProtectionSubnetMaxAttempts = security.Key("brute_force_login_protection_subnet_max_attempts").MustInt64(500)
+	cfg.BruteForceLoginProtectionLockoutDuration = security.Key("brute_force_login_protection_lockout_duration").MustDuration(5 * time.Minute)
+	cfg.BruteForceLoginProtectionMaxLockoutDuration = security.Key("brute_force_login_protection_max_lockout_duration").MustDuration(time.Hour)
+	cfg.BruteForceLoginProtectionNotifyUser = security.Key("brute_force_login_protection_notify_user").MustBool(false)
 
 	CookieSecure = security.Key("cookie_secure").MustBool(false)
 	cfg.CookieSecure = CookieSecure
@@ -1568,6 +1606,8 @@ func readAuthSettings(iniFile *ini.File, cfg *Cfg) (err error) {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
 
+	cfg.SessionGeoIPDatabasePath = valueAsString(auth, "session_geoip_database_path", "")
+
 	cfg.DisableLoginForm = auth.Key("disable_login_form").MustBool(false)
 	cfg.DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
 
@@ -1633,6 +1673,7 @@ func readAccessControlSettings(iniFile *ini.File, cfg *Cfg) {
 	cfg.RBACPermissionValidationEnabled = rbac.Key("permission_validation_enabled").MustBool(false)
 	cfg.RBACResetBasicRoles = rbac.Key("reset_basic_roles").MustBool(false)
 	cfg.RBACSingleOrganization = rbac.Key("single_organization").MustBool(false)
+	cfg.RBACAccessRequestMaxDuration = rbac.Key("access_request_max_duration").MustDuration(24 * time.Hour)
 }
 
 func readOAuth2ServerSettings(cfg *Cfg) {
@@ -1706,6 +1747,7 @@ func readUserSettings(iniFile *ini.File, cfg *Cfg) error {
 func readServiceAccountSettings(iniFile *ini.File, cfg *Cfg) error {
 	serviceAccount := iniFile.Section("service_accounts")
 	cfg.SATokenExpirationDayLimit = serviceAccount.Key("token_expiration_day_limit").MustInt(-1)
+	cfg.SATokenRotationDayLimit = serviceAccount.Key("token_rotation_day_limit").MustInt(0)
 	return nil
 }
 
@@ -1894,6 +1936,11 @@ func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 
 	cfg.ReadTimeout = server.Key("read_timeout").MustDuration(0)
 
+	cfg.TrustedProxies, err = parseTrustedProxies(util.SplitString(server.Key("trusted_proxies").String()))
+	if err != nil {
+		return err
+	}
+
 	headersSection := cfg.Raw.Section("server.custom_response_headers")
 	keys := headersSection.Keys()
 	cfg.CustomResponseHeaders = make(map[string]string, len(keys))
@@ -1905,6 +1952,27 @@ func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 	return nil
 }
 
+// parseTrustedProxies parses a list of IP addresses and CIDR networks
+func parseTrustedProxies(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", value, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
 // GetContentDeliveryURL returns full content delivery URL with /<edition>/<version> added to URL
 func (cfg *Cfg) GetContentDeliveryURL(prefix string) (string, error) {
 	if cfg.CDNRootURL == nil {
@@ -1978,6 +2046,7 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LivePipelineEnabled = section.Key("pipeline_enabled").MustBool(false)
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -1996,7 +2065,14 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	return nil
 }
 
-func (cfg *Cfg) readPublicDashboardsSettings() {
+func (cfg *Cfg) readPublicDashboardsSettings() error {
 	publicDashboards := cfg.Raw.Section("public_dashboards")
 	cfg.PublicDashboardsEnabled = publicDashboards.Key("enabled").MustBool(true)
+
+	maxAge, err := gtime.ParseDuration(valueAsString(publicDashboards, "access_log_max_age", "30d"))
+	if err != nil {
+		return fmt.Errorf("invalid public_dashboards access_log_max_age: %w", err)
+	}
+	cfg.PublicDashboardsAccessLogMaxAge = maxAge
+	return nil
 }
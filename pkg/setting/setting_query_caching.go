@@ -0,0 +1,25 @@
+package setting
+
+import (
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+type QueryCachingSettings struct {
+	Enabled bool
+	// TTL is how long query results are cached when the data source doesn't set its own
+	TTL time.Duration
+	// MaxValueSize is the size in bytes above which query results aren't cached
+	MaxValueSize int
+}
+
+func readQueryCachingSettings(iniFile *ini.File) QueryCachingSettings {
+	s := QueryCachingSettings{}
+
+	section := iniFile.Section("query_caching")
+	s.Enabled = section.Key("enabled").MustBool(false)
+	s.TTL = section.Key("ttl").MustDuration(time.Minute)
+	s.MaxValueSize = section.Key("max_value_mb").MustInt(1) * 1024 * 1024
+	return s
+}
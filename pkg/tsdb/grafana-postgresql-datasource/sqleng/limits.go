@@ -0,0 +1,134 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	limitReasonRows  = "rows"
+	limitReasonBytes = "bytes"
+	limitReasonTime  = "time"
+	// fixedValueSize is the size counted for the values that aren't strings or bytes
+	fixedValueSize = 8
+)
+
+var truncatedQueriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana_plugin",
+	Name:      "postgres_truncated_queries_total",
+	Help:      "Number of queries whose results were truncated because a limit of the data source was reached",
+}, []string{"reason"})
+
+// frameFromRows converts the rows to a frame like sqlutil.FrameFromRows. It stops scanning the rows as soon as
+// the row, byte or time limit of the data source is reached, and returns the rows scanned so far with a
+// warning notice.
+func (e *DataSourceHandler) frameFromRows(rows *sql.Rows, converters ...sqlutil.Converter) (*data.Frame, error) {
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	names, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	scanRow, err := sqlutil.MakeScanRow(types, names, converters...)
+	if err != nil {
+		return nil, err
+	}
+	frame := sqlutil.NewFrame(names, scanRow.Converters...)
+
+	var rowCount, byteCount int64
+	for {
+		for rows.Next() {
+			if e.rowLimit > 0 && rowCount == e.rowLimit {
+				return e.truncate(frame, limitReasonRows), nil
+			}
+
+			r := scanRow.NewScannableRow()
+			if err := rows.Scan(r...); err != nil {
+				return nil, err
+			}
+			if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+				return nil, err
+			}
+			rowCount++
+
+			byteCount += rowSize(frame, frame.Rows()-1)
+			if e.byteLimit > 0 && byteCount > e.byteLimit {
+				frame.DeleteRow(frame.Rows() - 1)
+				return e.truncate(frame, limitReasonBytes), nil
+			}
+		}
+		if !rows.NextResultSet() {
+			break
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		// the rows scanned before the time limit are returned, the query fails if there are none
+		if e.queryTimeout > 0 && errors.Is(err, context.DeadlineExceeded) && frame.Rows() > 0 {
+			return e.truncate(frame, limitReasonTime), nil
+		}
+		return nil, err
+	}
+
+	return frame, nil
+}
+
+func (e *DataSourceHandler) truncate(frame *data.Frame, reason string) *data.Frame {
+	truncatedQueriesTotal.WithLabelValues(reason).Inc()
+
+	var text string
+	switch reason {
+	case limitReasonRows:
+		text = fmt.Sprintf("Results have been limited to %v because the SQL row limit was reached", e.rowLimit)
+	case limitReasonBytes:
+		text = fmt.Sprintf("Results have been limited to %v rows because the result size limit of %v bytes was reached", frame.Rows(), e.byteLimit)
+	case limitReasonTime:
+		text = fmt.Sprintf("Results have been limited to %v rows because the query time limit of %v was reached", frame.Rows(), e.queryTimeout)
+	}
+	frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: text})
+	return frame
+}
+
+// rowSize returns an estimate of the memory used by the values of a row
+func rowSize(frame *data.Frame, row int) int64 {
+	var size int64
+	for _, field := range frame.Fields {
+		switch v := field.At(row).(type) {
+		case string:
+			size += int64(len(v))
+		case *string:
+			if v != nil {
+				size += int64(len(*v))
+			}
+		case json.RawMessage:
+			size += int64(len(v))
+		case *json.RawMessage:
+			if v != nil {
+				size += int64(len(*v))
+			}
+		case []byte:
+			size += int64(len(v))
+		default:
+			size += fixedValueSize
+		}
+	}
+	return size
+}
+
+// effectiveRowLimit returns the most restrictive of the row limits, a limit of zero or less is no limit
+func effectiveRowLimit(rowLimit, dataSourceRowLimit int64) int64 {
+	if dataSourceRowLimit > 0 && (rowLimit <= 0 || dataSourceRowLimit < rowLimit) {
+		return dataSourceRowLimit
+	}
+	return rowLimit
+}
@@ -0,0 +1,164 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+// eventsQueryModel is the model of the annotation queries of Graphite events
+type eventsQueryModel struct {
+	Target          string   `json:"target"`
+	TargetFull      string   `json:"targetFull"`
+	Tags            []string `json:"tags"`
+	FromAnnotations bool     `json:"fromAnnotations"`
+}
+
+// splitEventsQueries separates the annotation queries of events, which have tags instead of a target, from the
+// queries of series
+func splitEventsQueries(queries []backend.DataQuery) ([]backend.DataQuery, []backend.DataQuery) {
+	var events, targets []backend.DataQuery
+	for _, query := range queries {
+		model := eventsQueryModel{}
+		if err := json.Unmarshal(query.JSON, &model); err == nil && model.FromAnnotations && model.Target == "" && model.TargetFull == "" {
+			events = append(events, query)
+			continue
+		}
+		targets = append(targets, query)
+	}
+	return events, targets
+}
+
+// queryEvents returns the Graphite events of the time range that have all the tags of the query as an
+// annotations frame
+func (s *Service) queryEvents(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, query backend.DataQuery) backend.DataResponse {
+	model := eventsQueryModel{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("failed to parse query: %s", err))
+	}
+
+	from, until := epochMStoGraphiteTime(query.TimeRange)
+	params := url.Values{
+		"from":  []string{from},
+		"until": []string{until},
+	}
+	if len(model.Tags) > 0 {
+		params.Set("tags", strings.Join(model.Tags, " "))
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("failed to parse data source URL: %s", err))
+	}
+	u.Path = path.Join(u.Path, "events/get_data")
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, fmt.Sprintf("failed to create request: %s", err))
+	}
+
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusBadGateway, fmt.Sprintf("failed to query events: %s", err))
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusBadGateway, fmt.Sprintf("failed to read events: %s", err))
+	}
+	if res.StatusCode/100 != 2 {
+		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		return backend.ErrDataResponse(backend.Status(res.StatusCode), fmt.Sprintf("request failed, status: %s", res.Status))
+	}
+
+	var events []EventResponseDTO
+	if err := json.Unmarshal(body, &events); err != nil {
+		logger.Info("Failed to unmarshal graphite events", "error", err, "status", res.Status, "body", string(body))
+		return backend.ErrDataResponse(backend.StatusBadGateway, fmt.Sprintf("failed to parse events: %s", err))
+	}
+
+	frame, err := eventsToFrame(query.RefID, events)
+	if err != nil {
+		return backend.ErrDataResponse(backend.StatusInternal, err.Error())
+	}
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+func eventsToFrame(refID string, events []EventResponseDTO) (*data.Frame, error) {
+	times := make([]time.Time, 0, len(events))
+	titles := make([]string, 0, len(events))
+	texts := make([]string, 0, len(events))
+	tags := make([]json.RawMessage, 0, len(events))
+
+	for _, event := range events {
+		eventTags, err := parseEventTags(event.Tags)
+		if err != nil {
+			return nil, err
+		}
+		tagsJSON, err := json.Marshal(eventTags)
+		if err != nil {
+			return nil, err
+		}
+
+		times = append(times, time.Unix(0, int64(event.When*float64(time.Second))).UTC())
+		titles = append(titles, event.What)
+		texts = append(texts, event.Data)
+		tags = append(tags, tagsJSON)
+	}
+
+	frame := data.NewFrame(refID,
+		data.NewField("time", nil, times),
+		data.NewField("title", nil, titles),
+		data.NewField("text", nil, texts),
+		data.NewField("tags", nil, tags),
+	)
+	frame.RefID = refID
+	return frame, nil
+}
+
+// parseEventTags parses the tags of an event, Graphite returns them as a list or as a string of tags separated by
+// commas or spaces
+func parseEventTags(raw any) ([]string, error) {
+	tags := []string{}
+	switch v := raw.(type) {
+	case nil:
+	case string:
+		separator := ","
+		if !strings.Contains(v, ",") {
+			separator = " "
+		}
+		for _, tag := range strings.Split(v, separator) {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	case []any:
+		for _, tag := range v {
+			s, ok := tag.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid event tag: %v", tag)
+			}
+			tags = append(tags, s)
+		}
+	default:
+		return nil, fmt.Errorf("invalid event tags: %v", raw)
+	}
+	return tags, nil
+}
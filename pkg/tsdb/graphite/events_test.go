@@ -0,0 +1,83 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryEvents(t *testing.T) {
+	var received *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		_, _ = w.Write([]byte(`[
+			{"when": 1700000000, "what": "deploy", "data": "v1.2.3", "tags": ["deploy", "prod"]},
+			{"when": 1700000060.5, "what": "restart", "data": "", "tags": "ops,prod"}
+		]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := &Service{im: fakeServerInstanceManager{url: server.URL}}
+	from := time.Unix(1699999000, 0)
+	to := time.Unix(1700001000, 0)
+
+	res, err := service.QueryData(context.Background(), &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{{
+			RefID:     "Anno",
+			JSON:      []byte(`{"fromAnnotations": true, "tags": ["prod", "deploy"]}`),
+			TimeRange: backend.TimeRange{From: from, To: to},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/events/get_data", received.URL.Path)
+	assert.Equal(t, "1699999000", received.URL.Query().Get("from"))
+	assert.Equal(t, "1700001000", received.URL.Query().Get("until"))
+	assert.Equal(t, "prod deploy", received.URL.Query().Get("tags"))
+
+	response := res.Responses["Anno"]
+	require.NoError(t, response.Error)
+	require.Len(t, response.Frames, 1)
+	frame := response.Frames[0]
+	require.Equal(t, 2, frame.Rows())
+	assert.Equal(t, time.Unix(1700000000, 0).UTC(), frame.Fields[0].At(0))
+	assert.Equal(t, time.Unix(1700000060, int64(500*time.Millisecond)).UTC(), frame.Fields[0].At(1))
+	assert.Equal(t, "deploy", frame.Fields[1].At(0))
+	assert.Equal(t, "v1.2.3", frame.Fields[2].At(0))
+	assert.Equal(t, json.RawMessage(`["ops","prod"]`), frame.Fields[3].At(1))
+}
+
+func TestSplitEventsQueries(t *testing.T) {
+	events, targets := splitEventsQueries([]backend.DataQuery{
+		{RefID: "A", JSON: []byte(`{"target": "apps.*.count"}`)},
+		{RefID: "B", JSON: []byte(`{"fromAnnotations": true, "target": "apps.deploys"}`)},
+		{RefID: "C", JSON: []byte(`{"fromAnnotations": true, "tags": ["deploy"]}`)},
+	})
+
+	require.Len(t, events, 1)
+	assert.Equal(t, "C", events[0].RefID)
+	require.Len(t, targets, 2)
+}
+
+func TestParseEventTags(t *testing.T) {
+	for raw, expected := range map[string][]string{
+		`["a", "b"]`: {"a", "b"},
+		`"a,b"`:      {"a", "b"},
+		`"a b"`:      {"a", "b"},
+		`""`:         {},
+		`null`:       {},
+	} {
+		var v any
+		require.NoError(t, json.Unmarshal([]byte(raw), &v))
+		tags, err := parseEventTags(v)
+		require.NoError(t, err)
+		assert.Equal(t, expected, tags, raw)
+	}
+}
@@ -98,8 +98,17 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		return nil, err
 	}
 
+	responses := backend.Responses{}
+	eventsQueries, queries := splitEventsQueries(req.Queries)
+	for _, query := range eventsQueries {
+		responses[query.RefID] = s.queryEvents(ctx, logger, dsInfo, query)
+	}
+	if len(queries) == 0 {
+		return &backend.QueryDataResponse{Responses: responses}, nil
+	}
+
 	// take the first query in the request list, since all query should share the same timerange
-	q := req.Queries[0]
+	q := queries[0]
 
 	/*
 		graphite doc about from and until, with sdk we are getting absolute instead of relative time
@@ -115,7 +124,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	// Convert datasource query to graphite target request
-	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, req.Queries)
+	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, queries)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +133,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	if len(emptyQueries) != 0 {
 		logger.Warn("Found query models without targets", "models without targets", strings.Join(emptyQueries, "\n"))
 		// If no queries had a valid target, return an error; otherwise, attempt with the targets we have
-		if len(emptyQueries) == len(req.Queries) {
+		if len(emptyQueries) == len(queries) {
 			return &result, errors.New("no query target found for the alert rule")
 		}
 	}
@@ -177,7 +186,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	}
 
 	result = backend.QueryDataResponse{
-		Responses: make(backend.Responses),
+		Responses: responses,
 	}
 
 	for _, f := range frames {
@@ -0,0 +1,69 @@
+package graphite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// CheckHealth checks that Graphite answers to a metric find query
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Failed to get data source info",
+		}, err
+	}
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Failed to parse data source URL",
+		}, nil
+	}
+	u.Path = path.Join(u.Path, "metrics/find")
+	u.RawQuery = url.Values{"query": []string{"*"}}.Encode()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Failed to create request",
+		}, nil
+	}
+
+	res, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		logger.Error("Failed to do healthcheck request", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Failed to connect to Graphite: %s", err),
+		}, nil
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	if res.StatusCode/100 != 2 {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Graphite returned an error, status: %s", res.Status),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source is working",
+	}, nil
+}
@@ -0,0 +1,93 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// resourcePaths are the read endpoints of the Graphite API that can be called as resources along with their methods,
+// the metric finder posts its queries so they aren't limited by the length of the URL
+var resourcePaths = map[string][]string{
+	"metrics/find":             {http.MethodGet, http.MethodPost},
+	"metrics/expand":           {http.MethodGet},
+	"tags":                     {http.MethodGet},
+	"tags/autoComplete/tags":   {http.MethodGet},
+	"tags/autoComplete/values": {http.MethodGet},
+	"tags/findSeries":          {http.MethodGet},
+	"functions":                {http.MethodGet},
+}
+
+// CallResource forwards the calls to the metric finding, tags and functions endpoints of the Graphite API
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+
+	methods, ok := resourcePaths[req.Path]
+	if !ok {
+		logger.Warn("Invalid resource path", "path", req.Path)
+		return sender.Send(&backend.CallResourceResponse{
+			Status: http.StatusNotFound,
+			Body:   []byte(fmt.Sprintf(`{"message": "unknown resource: %s"}`, req.Path)),
+		})
+	}
+	if !slices.Contains(methods, req.Method) {
+		return sender.Send(&backend.CallResourceResponse{Status: http.StatusMethodNotAllowed})
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	graphiteURL, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse data source URL: %w", err)
+	}
+	graphiteURL.Path = path.Join(graphiteURL.Path, req.Path)
+	if reqURL, err := url.Parse(req.URL); err == nil {
+		graphiteURL.RawQuery = reqURL.RawQuery
+	}
+
+	graphiteReq, err := http.NewRequestWithContext(ctx, req.Method, graphiteURL.String(), bytes.NewReader(req.Body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType := req.Headers["Content-Type"]; len(contentType) > 0 {
+		graphiteReq.Header.Set("Content-Type", contentType[0])
+	}
+
+	res, err := dsInfo.HTTPClient.Do(graphiteReq)
+	if err != nil {
+		logger.Error("Failed to call Graphite resource", "error", err, "path", req.Path)
+		return err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string][]string{}
+	if contentType := res.Header.Get("Content-Type"); contentType != "" {
+		headers["content-type"] = []string{contentType}
+	}
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  res.StatusCode,
+		Headers: headers,
+		Body:    body,
+	})
+}
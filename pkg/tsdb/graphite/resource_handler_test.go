@@ -0,0 +1,123 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallResource(t *testing.T) {
+	var received *http.Request
+	var receivedBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ := io.ReadAll(r.Body)
+		receivedBody = string(body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"text": "apps", "expandable": 1}]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := &Service{im: fakeServerInstanceManager{url: server.URL + "/graphite"}}
+
+	call := func(t *testing.T, req *backend.CallResourceRequest) *backend.CallResourceResponse {
+		t.Helper()
+		var res *backend.CallResourceResponse
+		err := service.CallResource(context.Background(), req, backend.CallResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+			res = r
+			return nil
+		}))
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		return res
+	}
+
+	t.Run("should forward metric find queries", func(t *testing.T) {
+		res := call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "metrics/find", URL: "metrics/find?query=apps.*&from=-1h"})
+
+		assert.Equal(t, http.StatusOK, res.Status)
+		assert.JSONEq(t, `[{"text": "apps", "expandable": 1}]`, string(res.Body))
+		assert.Equal(t, []string{"application/json"}, res.Headers["content-type"])
+		assert.Equal(t, "/graphite/metrics/find", received.URL.Path)
+		assert.Equal(t, "apps.*", received.URL.Query().Get("query"))
+	})
+
+	t.Run("should forward posted metric find queries with the body", func(t *testing.T) {
+		call(t, &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "metrics/find",
+			URL:     "metrics/find",
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Body:    []byte("query=apps.*"),
+		})
+
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, "/graphite/metrics/find", received.URL.Path)
+		assert.Equal(t, "query=apps.*", receivedBody)
+	})
+
+	t.Run("should forward tags autocompletion", func(t *testing.T) {
+		call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags?tagPrefix=na"})
+
+		assert.Equal(t, "/graphite/tags/autoComplete/tags", received.URL.Path)
+		assert.Equal(t, "na", received.URL.Query().Get("tagPrefix"))
+	})
+
+	t.Run("should only post to the metric finder", func(t *testing.T) {
+		received = nil
+		res := call(t, &backend.CallResourceRequest{Method: http.MethodPost, Path: "tags/autoComplete/tags", URL: "tags/autoComplete/tags"})
+
+		assert.Equal(t, http.StatusMethodNotAllowed, res.Status)
+		assert.Nil(t, received)
+	})
+
+	t.Run("should not forward other paths", func(t *testing.T) {
+		received = nil
+		for _, path := range []string{"render", "events/get_data", "tags/../render", "metricsfind", "tags/tagSeries", "tags/tagMultiSeries", "tags/delSeries"} {
+			res := call(t, &backend.CallResourceRequest{Method: http.MethodGet, Path: path, URL: path})
+
+			assert.Equal(t, http.StatusNotFound, res.Status, path)
+		}
+		assert.Nil(t, received)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	service := &Service{im: fakeServerInstanceManager{url: server.URL}}
+
+	res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, backend.HealthStatusOk, res.Status)
+
+	status = http.StatusInternalServerError
+	res, err = service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, backend.HealthStatusError, res.Status)
+}
+
+// fakeServerInstanceManager returns a data source that sends its requests to the url
+type fakeServerInstanceManager struct {
+	url string
+}
+
+func (f fakeServerInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return datasourceInfo{HTTPClient: http.DefaultClient, URL: f.url}, nil
+}
+
+func (f fakeServerInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
@@ -8,3 +8,11 @@ type TargetResponseDTO struct {
 	// Graphite <=1.1.7 may return some tags as numbers requiring extra conversion. See https://github.com/grafana/grafana/issues/37614
 	Tags map[string]any `json:"tags"`
 }
+
+type EventResponseDTO struct {
+	When float64 `json:"when"`
+	What string  `json:"what"`
+	Data string  `json:"data"`
+	// Tags are a list or a string of tags separated by commas or spaces, depending on the version of Graphite
+	Tags any `json:"tags"`
+}
@@ -73,6 +73,7 @@ type QueryJSONModel struct {
 	dataquery.LokiDataQuery
 	Direction           *string `json:"direction,omitempty"`
 	SupportingQueryType *string `json:"supportingQueryType"`
+	SplitDuration       *string `json:"splitDuration,omitempty"`
 }
 
 type ResponseOpts struct {
@@ -164,10 +165,10 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logsDataplane:   s.features.IsEnabled(ctx, featuremgmt.FlagLokiLogsDataplane),
 	}
 
-	return queryData(ctx, req, dsInfo, responseOpts, s.tracer, logger, s.features.IsEnabled(ctx, featuremgmt.FlagLokiRunQueriesInParallel), s.features.IsEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata))
+	return queryData(ctx, req, dsInfo, responseOpts, s.tracer, logger, s.features.IsEnabled(ctx, featuremgmt.FlagLokiRunQueriesInParallel), s.features.IsEnabled(ctx, featuremgmt.FlagLokiStructuredMetadata), s.features.IsEnabled(ctx, featuremgmt.FlagLokiServerSideQuerySplitting))
 }
 
-func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datasourceInfo, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger, runInParallel bool, requestStructuredMetadata bool) (*backend.QueryDataResponse, error) {
+func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datasourceInfo, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger, runInParallel bool, requestStructuredMetadata bool, splitQueries bool) (*backend.QueryDataResponse, error) {
 	result := backend.NewQueryDataResponse()
 
 	api := newLokiAPI(dsInfo.HTTPClient, dsInfo.URL, plog, tracer, requestStructuredMetadata)
@@ -196,7 +197,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, splitQueries, api, responseOpts, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -205,7 +206,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, splitQueries, api, responseOpts, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -213,7 +214,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, splitQueries bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -226,7 +227,13 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	queryRes, err := runQuery(ctx, api, query, responseOpts, plog)
+	var queryRes *backend.DataResponse
+	var err error
+	if splitQueries && query.QueryType == QueryTypeRange && query.SplitDuration > 0 && query.End.Sub(query.Start) > query.SplitDuration {
+		queryRes, err = runSplitQuery(ctx, api, query, responseOpts, plog)
+	} else {
+		queryRes, err = runQuery(ctx, api, query, responseOpts, plog)
+	}
 	if queryRes == nil {
 		// we always want to return a backend.DataResponse object, even if we received just an error
 		queryRes = &backend.DataResponse{}
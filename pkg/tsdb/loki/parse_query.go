@@ -123,6 +123,18 @@ func parseSupportingQueryType(jsonPointerValue *string) SupportingQueryType {
 	}
 }
 
+func parseSplitDuration(jsonPointerValue *string) (time.Duration, error) {
+	if jsonPointerValue == nil || *jsonPointerValue == "" {
+		return defaultSplitDuration, nil
+	}
+
+	duration, err := gtime.ParseDuration(*jsonPointerValue)
+	if err != nil {
+		return 0, fmt.Errorf("invalid splitDuration: %s", *jsonPointerValue)
+	}
+	return duration, nil
+}
+
 func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 	qs := []*lokiQuery{}
 	for _, query := range queryContext.Queries {
@@ -171,6 +183,15 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 
 		supportingQueryType := parseSupportingQueryType(model.SupportingQueryType)
 
+		splitDuration, err := parseSplitDuration(model.SplitDuration)
+		if err != nil {
+			return nil, err
+		}
+		// the results of queries using the range variables depend on the whole time range
+		if queryType != QueryTypeRange || isQueryWithRangeVariable(depointerizer(model.Expr)) {
+			splitDuration = 0
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:                expr,
 			QueryType:           queryType,
@@ -182,6 +203,7 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			End:                 end,
 			RefID:               query.RefID,
 			SupportingQueryType: supportingQueryType,
+			SplitDuration:       splitDuration,
 		})
 	}
 
@@ -0,0 +1,307 @@
+package loki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// defaultSplitDuration is the duration of the chunks long range queries are split into, it is the same as the
+	// default of the frontend splitting
+	defaultSplitDuration = 24 * time.Hour
+	// maxConcurrentChunks is the number of chunks of a metric query that are queried at the same time
+	maxConcurrentChunks = 4
+)
+
+var errChunkFramesMismatch = errors.New("frames of the chunks don't have the same fields")
+
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+type chunkResult struct {
+	res *backend.DataResponse
+	err error
+}
+
+// runSplitQuery runs a range query as several queries over smaller time ranges and merges their results.
+// Metric queries are run with bounded parallelism. Logs queries are run one after the other in the order of the
+// direction of the query, so that no more lines than the limit are queried. Chunks that fail are skipped and
+// reported with a warning notice, unless they all fail.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, plog log.Logger) (*backend.DataResponse, error) {
+	logsQuery := isLogsQuery(query.Expr)
+
+	var ranges []timeRange
+	if logsQuery {
+		ranges = splitLogsTimeRange(query.Start, query.End, query.SplitDuration)
+	} else {
+		ranges = splitMetricTimeRange(query.Start, query.End, query.Step, query.SplitDuration)
+	}
+	if len(ranges) <= 1 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	plog.Debug("Splitting query", "refId", query.RefID, "chunks", len(ranges), "splitDuration", query.SplitDuration)
+
+	var results []chunkResult
+	if logsQuery {
+		results = runLogsChunks(ctx, api, query, ranges, responseOpts, plog)
+	} else {
+		results = runMetricChunks(ctx, api, query, ranges, responseOpts, plog)
+	}
+
+	return mergeChunkResults(results, logsQuery)
+}
+
+func runMetricChunks(ctx context.Context, api *LokiAPI, query *lokiQuery, ranges []timeRange, responseOpts ResponseOpts, plog log.Logger) []chunkResult {
+	results := make([]chunkResult, len(ranges))
+	resultLock := sync.Mutex{}
+	_ = concurrency.ForEachJob(ctx, len(ranges), maxConcurrentChunks, func(ctx context.Context, idx int) error {
+		chunk := *query
+		chunk.Start, chunk.End = ranges[idx].start, ranges[idx].end
+		res, err := runQuery(ctx, api, &chunk, responseOpts, plog)
+
+		resultLock.Lock()
+		defer resultLock.Unlock()
+		results[idx] = chunkResult{res: res, err: err}
+		return nil // errors are saved per chunk, always return nil
+	})
+	return results
+}
+
+func runLogsChunks(ctx context.Context, api *LokiAPI, query *lokiQuery, ranges []timeRange, responseOpts ResponseOpts, plog log.Logger) []chunkResult {
+	// the newest lines are queried first for backward queries
+	if query.Direction != DirectionForward {
+		reversed := make([]timeRange, len(ranges))
+		for i := range ranges {
+			reversed[len(ranges)-1-i] = ranges[i]
+		}
+		ranges = reversed
+	}
+
+	results := make([]chunkResult, 0, len(ranges))
+	lines := 0
+	for _, r := range ranges {
+		chunk := *query
+		chunk.Start, chunk.End = r.start, r.end
+		if query.MaxLines > 0 {
+			chunk.MaxLines = query.MaxLines - lines
+		}
+
+		res, err := runQuery(ctx, api, &chunk, responseOpts, plog)
+		results = append(results, chunkResult{res: res, err: err})
+		if err == nil && res != nil && res.Error == nil {
+			for _, frame := range res.Frames {
+				lines += frame.Rows()
+			}
+		}
+		if query.MaxLines > 0 && lines >= query.MaxLines {
+			break
+		}
+	}
+	return results
+}
+
+// mergeChunkResults merges the frames of the chunks in order. Metric series are concatenated and logs lines
+// are deduplicated.
+func mergeChunkResults(results []chunkResult, logsQuery bool) (*backend.DataResponse, error) {
+	merged := &backend.DataResponse{Frames: data.Frames{}}
+	bySeries := map[string]*data.Frame{}
+	seenLines := map[string]bool{}
+
+	var failures []error
+	var firstFailure *chunkResult
+	for i := range results {
+		result := results[i]
+		err := result.err
+		if err == nil && result.res != nil {
+			err = result.res.Error
+		}
+		if err != nil {
+			failures = append(failures, err)
+			if firstFailure == nil {
+				firstFailure = &result
+			}
+			continue
+		}
+		if result.res == nil {
+			continue
+		}
+
+		for _, frame := range result.res.Frames {
+			key := seriesKey(frame)
+			if logsQuery {
+				// logs are returned in a single frame
+				key = ""
+			}
+
+			target, ok := bySeries[key]
+			if !ok {
+				target = frame.EmptyCopy()
+				if frame.Meta != nil {
+					meta := *frame.Meta
+					meta.Stats = mergeStats(nil, frame.Meta.Stats)
+					target.Meta = &meta
+				}
+				bySeries[key] = target
+				merged.Frames = append(merged.Frames, target)
+			} else if target.Meta != nil && frame.Meta != nil {
+				target.Meta.Stats = mergeStats(target.Meta.Stats, frame.Meta.Stats)
+			}
+
+			if err := appendRows(target, frame, logsQuery, seenLines); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(failures) == len(results) && firstFailure != nil {
+		return firstFailure.res, firstFailure.err
+	}
+
+	if len(failures) > 0 {
+		if len(merged.Frames) == 0 {
+			merged.Frames = append(merged.Frames, data.NewFrame(""))
+		}
+		frame := merged.Frames[0]
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.Notices = append(frame.Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Failed to query %d of %d parts of the time range, results are partial: %s", len(failures), len(results), failures[0]),
+		})
+	}
+
+	return merged, nil
+}
+
+func appendRows(target, frame *data.Frame, dedup bool, seenLines map[string]bool) error {
+	if len(target.Fields) != len(frame.Fields) {
+		return errChunkFramesMismatch
+	}
+
+	idIdx := -1
+	for i, field := range frame.Fields {
+		if field.Name != target.Fields[i].Name || field.Type() != target.Fields[i].Type() {
+			return errChunkFramesMismatch
+		}
+		if dedup && field.Name == "id" && field.Type() == data.FieldTypeString {
+			idIdx = i
+		}
+	}
+
+	for row := 0; row < frame.Rows(); row++ {
+		if idIdx != -1 {
+			id := frame.Fields[idIdx].At(row).(string)
+			if seenLines[id] {
+				continue
+			}
+			seenLines[id] = true
+		}
+		for i, field := range frame.Fields {
+			target.Fields[i].Append(field.CopyAt(row))
+		}
+	}
+	return nil
+}
+
+// mergeStats sums the stats of the same name
+func mergeStats(stats, other []data.QueryStat) []data.QueryStat {
+	for _, o := range other {
+		found := false
+		for i := range stats {
+			if stats[i].DisplayName == o.DisplayName {
+				stats[i].Value += o.Value
+				found = true
+				break
+			}
+		}
+		if !found {
+			stats = append(stats, o)
+		}
+	}
+	return stats
+}
+
+// seriesKey identifies the series of a metric frame by its name and the names and labels of its fields
+func seriesKey(frame *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		b.WriteByte(0)
+		b.WriteString(field.Name)
+		b.WriteByte(0)
+		b.WriteString(field.Labels.String())
+	}
+	return b.String()
+}
+
+// isLogsQuery returns whether the query returns logs lines, metric queries always start with a function or an
+// aggregation while logs queries start with a stream selector
+func isLogsQuery(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), "{")
+}
+
+// isQueryWithRangeVariable returns whether the results of the query depend on the whole time range
+func isQueryWithRangeVariable(expr string) bool {
+	for _, v := range []string{varRange, varRangeS, varRangeMs, varRangeAlt, varRangeSAlt, varRangeMsAlt} {
+		if strings.Contains(expr, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitMetricTimeRange splits the time range in chunks whose duration is a multiple of the step. The end of the
+// chunks is inclusive, so it's one step before the start of the next chunk, and the last chunk may only contain
+// the end of the range.
+// It is compatible with https://github.com/grafana/loki/blob/089ec1b05f5ec15a8851d0e8230153e0eeb4dcec/pkg/querier/queryrange/split_by_interval.go#L327-L336
+func splitMetricTimeRange(start, end time.Time, step, duration time.Duration) []timeRange {
+	if step <= 0 || duration < step {
+		// we cannot create chunks smaller than `step`
+		return []timeRange{{start: start, end: end}}
+	}
+
+	// we make the duration a multiple of `step`, lowering it if necessary
+	alignedDuration := duration / step * step
+	alignedStart := time.UnixMilli(start.UnixMilli() - start.UnixMilli()%step.Milliseconds())
+
+	var ranges []timeRange
+	for chunkStart := alignedStart; !chunkStart.After(end); chunkStart = chunkStart.Add(alignedDuration) {
+		chunkEnd := chunkStart.Add(alignedDuration - step)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		ranges = append(ranges, timeRange{start: chunkStart, end: chunkEnd})
+	}
+	return ranges
+}
+
+// splitLogsTimeRange splits the time range in chunks of the duration. Loki includes the start of logs queries but
+// not their end, so the chunks share their boundaries. The oldest chunk is the shortest.
+func splitLogsTimeRange(start, end time.Time, duration time.Duration) []timeRange {
+	if duration <= 0 || end.Sub(start) <= duration {
+		return []timeRange{{start: start, end: end}}
+	}
+
+	var ranges []timeRange
+	for chunkEnd := end; chunkEnd.After(start); chunkEnd = chunkEnd.Add(-duration) {
+		chunkStart := chunkEnd.Add(-duration)
+		if chunkStart.Before(start) {
+			chunkStart = start
+		}
+		ranges = append([]timeRange{{start: chunkStart, end: chunkEnd}}, ranges...)
+	}
+	return ranges
+}
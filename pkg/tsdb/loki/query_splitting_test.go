@@ -0,0 +1,291 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestSplitMetricTimeRange(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	t.Run("should align the chunks on the step", func(t *testing.T) {
+		ranges := splitMetricTimeRange(start.Add(30*time.Second), start.Add(3*time.Hour), time.Minute, time.Hour)
+
+		require.Len(t, ranges, 4)
+		assert.Equal(t, timeRange{start: start.Add(-20 * time.Second), end: start.Add(59*time.Minute - 20*time.Second)}, ranges[0])
+		assert.Equal(t, start.Add(time.Hour-20*time.Second), ranges[1].start)
+		assert.Equal(t, start.Add(3*time.Hour), ranges[3].end)
+	})
+
+	t.Run("should make the duration a multiple of the step", func(t *testing.T) {
+		ranges := splitMetricTimeRange(start, start.Add(4*time.Hour), 7*time.Minute, time.Hour)
+
+		assert.Equal(t, 56*time.Minute, ranges[1].start.Sub(ranges[0].start))
+	})
+
+	t.Run("should not split in chunks smaller than the step", func(t *testing.T) {
+		ranges := splitMetricTimeRange(start, start.Add(4*time.Hour), 2*time.Hour, time.Hour)
+
+		assert.Equal(t, []timeRange{{start: start, end: start.Add(4 * time.Hour)}}, ranges)
+	})
+}
+
+func TestSplitLogsTimeRange(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	t.Run("should make the oldest chunk the shortest", func(t *testing.T) {
+		ranges := splitLogsTimeRange(start, start.Add(150*time.Minute), time.Hour)
+
+		assert.Equal(t, []timeRange{
+			{start: start, end: start.Add(30 * time.Minute)},
+			{start: start.Add(30 * time.Minute), end: start.Add(90 * time.Minute)},
+			{start: start.Add(90 * time.Minute), end: start.Add(150 * time.Minute)},
+		}, ranges)
+	})
+
+	t.Run("should not split ranges shorter than the duration", func(t *testing.T) {
+		ranges := splitLogsTimeRange(start, start.Add(time.Hour), time.Hour)
+
+		assert.Equal(t, []timeRange{{start: start, end: start.Add(time.Hour)}}, ranges)
+	})
+}
+
+func TestIsQueryWithRangeVariable(t *testing.T) {
+	assert.True(t, isQueryWithRangeVariable(`sum(count_over_time({app="grafana"}[$__range]))`))
+	assert.True(t, isQueryWithRangeVariable(`sum(count_over_time({app="grafana"}[${__range_s}s]))`))
+	assert.False(t, isQueryWithRangeVariable(`sum(count_over_time({app="grafana"}[$__interval]))`))
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	start := time.Unix(1700006400, 0)
+	end := start.Add(72 * time.Hour)
+
+	t.Run("should concatenate the series of metric chunks", func(t *testing.T) {
+		loki := &chunkedLoki{}
+		query := &lokiQuery{
+			Expr:          `sum by (level) (count_over_time({app="grafana"}[1h]))`,
+			QueryType:     QueryTypeRange,
+			Step:          time.Hour,
+			Start:         start,
+			End:           end,
+			RefID:         "A",
+			SplitDuration: 24 * time.Hour,
+		}
+
+		res, err := runSplitQuery(context.Background(), loki.api(), query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+
+		// the last chunk only contains the end of the range
+		require.Len(t, loki.requests(), 4)
+		require.Len(t, res.Frames, 2)
+		for _, frame := range res.Frames {
+			assert.Equal(t, 73, frame.Rows())
+			assert.Empty(t, frame.Meta.Notices)
+		}
+		assert.Equal(t, start.Unix(), res.Frames[0].Fields[0].At(0).(time.Time).Unix())
+		assert.Equal(t, end.Unix(), res.Frames[0].Fields[0].At(72).(time.Time).Unix())
+	})
+
+	t.Run("should return partial results with a warning when a chunk fails", func(t *testing.T) {
+		loki := &chunkedLoki{failAt: start.Add(24 * time.Hour)}
+		query := &lokiQuery{
+			Expr:          `sum by (level) (count_over_time({app="grafana"}[1h]))`,
+			QueryType:     QueryTypeRange,
+			Step:          time.Hour,
+			Start:         start,
+			End:           end,
+			RefID:         "A",
+			SplitDuration: 24 * time.Hour,
+		}
+
+		res, err := runSplitQuery(context.Background(), loki.api(), query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+
+		require.Len(t, res.Frames, 2)
+		assert.Equal(t, 49, res.Frames[0].Rows())
+		require.Len(t, res.Frames[0].Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, res.Frames[0].Meta.Notices[0].Severity)
+	})
+
+	t.Run("should return the error when all the chunks fail", func(t *testing.T) {
+		loki := &chunkedLoki{failAll: true}
+		query := &lokiQuery{
+			Expr:          `sum by (level) (count_over_time({app="grafana"}[1h]))`,
+			QueryType:     QueryTypeRange,
+			Step:          time.Hour,
+			Start:         start,
+			End:           end,
+			RefID:         "A",
+			SplitDuration: 24 * time.Hour,
+		}
+
+		_, err := runSplitQuery(context.Background(), loki.api(), query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.Error(t, err)
+	})
+
+	t.Run("should query the newest logs first until the limit is reached", func(t *testing.T) {
+		loki := &chunkedLoki{}
+		query := &lokiQuery{
+			Expr:          `{app="grafana"}`,
+			QueryType:     QueryTypeRange,
+			Direction:     DirectionBackward,
+			Step:          time.Minute,
+			MaxLines:      3,
+			Start:         start,
+			End:           end,
+			RefID:         "A",
+			SplitDuration: 24 * time.Hour,
+		}
+
+		res, err := runSplitQuery(context.Background(), loki.api(), query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+
+		requests := loki.requests()
+		require.Len(t, requests, 2)
+		assert.Equal(t, chunkRequest{start: start.Add(48 * time.Hour), end: end, limit: 3}, requests[0])
+		assert.Equal(t, chunkRequest{start: start.Add(24 * time.Hour), end: start.Add(48 * time.Hour), limit: 1}, requests[1])
+
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, 3, res.Frames[0].Rows())
+	})
+
+	t.Run("should deduplicate the logs of the chunks", func(t *testing.T) {
+		loki := &chunkedLoki{inclusiveEnd: true}
+		query := &lokiQuery{
+			Expr:          `{app="grafana"}`,
+			QueryType:     QueryTypeRange,
+			Direction:     DirectionForward,
+			Step:          time.Minute,
+			MaxLines:      100,
+			Start:         start,
+			End:           end,
+			RefID:         "A",
+			SplitDuration: 24 * time.Hour,
+		}
+
+		res, err := runSplitQuery(context.Background(), loki.api(), query, ResponseOpts{}, backend.NewLoggerWith("logger", "test"))
+		require.NoError(t, err)
+
+		requests := loki.requests()
+		require.Len(t, requests, 3)
+		assert.Equal(t, start, requests[0].start)
+
+		// the lines at the boundaries of the chunks are returned twice
+		require.Len(t, res.Frames, 1)
+		assert.Equal(t, 4, res.Frames[0].Rows())
+	})
+}
+
+type chunkRequest struct {
+	start time.Time
+	end   time.Time
+	limit int
+}
+
+// chunkedLoki answers metric queries with a sample per step for two series and logs queries with a line at the
+// end and at the start of the range, up to the limit. Like Loki, the end of logs queries is excluded unless
+// inclusiveEnd is set.
+type chunkedLoki struct {
+	failAt       time.Time
+	failAll      bool
+	inclusiveEnd bool
+
+	lock     sync.Mutex
+	received []chunkRequest
+}
+
+func (l *chunkedLoki) api() *LokiAPI {
+	return newLokiAPI(&http.Client{Transport: l}, "http://localhost:3100", backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest(), false)
+}
+
+func (l *chunkedLoki) requests() []chunkRequest {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.received
+}
+
+func (l *chunkedLoki) RoundTrip(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	startNs, err := strconv.ParseInt(params.Get("start"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	endNs, err := strconv.ParseInt(params.Get("end"), 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	request := chunkRequest{start: time.Unix(0, startNs), end: time.Unix(0, endNs), limit: limit}
+
+	l.lock.Lock()
+	l.received = append(l.received, request)
+	l.lock.Unlock()
+
+	if l.failAll || request.start.Equal(l.failAt) {
+		return lokiResponse(http.StatusBadRequest, map[string]any{"status": "error", "error": "query timed out"})
+	}
+
+	if isLogsQuery(params.Get("query")) {
+		last := request.end.Add(-time.Second)
+		if l.inclusiveEnd {
+			last = request.end
+		}
+		values := [][2]string{}
+		for _, ts := range []time.Time{last, request.start} {
+			if len(values) < limit {
+				values = append(values, [2]string{strconv.FormatInt(ts.UnixNano(), 10), fmt.Sprintf("line at %d", ts.Unix())})
+			}
+		}
+		return lokiResponse(http.StatusOK, map[string]any{
+			"status": "success",
+			"data": map[string]any{
+				"resultType": "streams",
+				"result":     []any{map[string]any{"stream": map[string]string{"app": "grafana"}, "values": values}},
+			},
+		})
+	}
+
+	step, err := time.ParseDuration(params.Get("step"))
+	if err != nil {
+		return nil, err
+	}
+	result := []any{}
+	for _, level := range []string{"error", "info"} {
+		values := [][2]any{}
+		for ts := request.start; !ts.After(request.end); ts = ts.Add(step) {
+			values = append(values, [2]any{ts.Unix(), "1"})
+		}
+		result = append(result, map[string]any{"metric": map[string]string{"level": level}, "values": values})
+	}
+	return lokiResponse(http.StatusOK, map[string]any{
+		"status": "success",
+		"data":   map[string]any{"resultType": "matrix", "result": result},
+	})
+}
+
+func lokiResponse(statusCode int, body map[string]any) (*http.Response, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(b)),
+	}, nil
+}
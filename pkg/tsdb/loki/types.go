@@ -39,4 +39,6 @@ type lokiQuery struct {
 	End                 time.Time
 	RefID               string
 	SupportingQueryType SupportingQueryType
+	// SplitDuration is the duration of the chunks the query is split into, queries aren't split when it's zero
+	SplitDuration time.Duration
 }
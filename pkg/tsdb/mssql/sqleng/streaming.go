@@ -0,0 +1,378 @@
+package sqleng
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+)
+
+const (
+	// streamPathPrefix is the prefix of the paths of the channels of live queries, it is followed by the hex encoded
+	// SHA-256 of the query model sent as the subscription data
+	streamPathPrefix = "stream/"
+	// lastSeenMacro is replaced by the last value of the stream column sent to the stream
+	lastSeenMacro         = "$__lastSeen"
+	defaultStreamInterval = 5 * time.Second
+)
+
+// minStreamInterval is the shortest interval between the executions of a live query
+var minStreamInterval = time.Second
+
+// errStreamColumnKind is returned when a stream without lastSeen can't start on its stream column, the stream
+// doesn't recover from it
+var errStreamColumnKind = errors.New("invalid stream column")
+
+// StreamQueryJson is the query of a live stream. It is executed again every interval, only the rows whose
+// value in the stream column is greater than the last one sent are sent to the stream.
+type StreamQueryJson struct {
+	QueryJson
+	// StreamColumn is a monotonic time or numeric column, it defaults to the time column
+	StreamColumn   string `json:"streamColumn"`
+	StreamInterval string `json:"streamInterval"`
+	// LastSeen is the value of the stream column the stream starts after, a number or a RFC 3339 time. The stream
+	// starts at the current time when it isn't set, which is only possible for time stream columns.
+	LastSeen json.RawMessage `json:"lastSeen"`
+}
+
+// streamCursor is the last value of the stream column that was sent to the stream
+type streamCursor struct {
+	// unset is true until the first run of a stream without lastSeen, which tells the kind of the stream column
+	unset   bool
+	numeric bool
+	number  float64
+	time    time.Time
+}
+
+// streamSender is the part of backend.StreamSender used to send the new rows
+type streamSender interface {
+	SendFrame(frame *data.Frame, include data.FrameInclude) error
+}
+
+func (e *DataSourceHandler) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	// a channel runs the query of its first subscriber, later subscribers must subscribe with the same query
+	if req.Path != streamPath(req.Data) {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, fmt.Errorf("expected %s followed by the SHA-256 of the query model in channel path", streamPathPrefix)
+	}
+
+	queryJson, interval, cursor, err := e.parseStreamQuery(req.Data)
+	if err != nil {
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, err
+	}
+
+	if cursor.unset {
+		// the stream column is checked before subscribing, the stream would stop on its first run otherwise
+		query, err := streamDataQuery(queryJson, interval)
+		if err != nil {
+			return nil, err
+		}
+		if _, _, err := e.queryNewRows(ctx, query, queryJson, cursor); errors.Is(err, errStreamColumnKind) {
+			return &backend.SubscribeStreamResponse{
+				Status: backend.SubscribeStreamStatusNotFound,
+			}, err
+		}
+	}
+
+	return &backend.SubscribeStreamResponse{
+		Status: backend.SubscribeStreamStatusOK,
+	}, nil
+}
+
+// streamPath returns the path of the channel of a query model
+func streamPath(queryModel json.RawMessage) string {
+	hash := sha256.Sum256(queryModel)
+	return streamPathPrefix + hex.EncodeToString(hash[:])
+}
+
+func (e *DataSourceHandler) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
+
+// RunStream executes the query of the channel every interval and sends the new rows as appends to the frame,
+// a single instance runs for each channel
+func (e *DataSourceHandler) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	if req.Path != streamPath(req.Data) {
+		return fmt.Errorf("channel path %s doesn't match the query model", req.Path)
+	}
+	return e.runStream(ctx, req.Data, sender)
+}
+
+func (e *DataSourceHandler) runStream(ctx context.Context, rawQuery json.RawMessage, sender streamSender) error {
+	queryJson, interval, cursor, err := e.parseStreamQuery(rawQuery)
+	if err != nil {
+		return err
+	}
+
+	query, err := streamDataQuery(queryJson, interval)
+	if err != nil {
+		return err
+	}
+
+	logger := e.log.FromContext(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	prev := data.FrameJSONCache{}
+	for {
+		frame, next, err := e.queryNewRows(ctx, query, queryJson, cursor)
+		switch {
+		case errors.Is(err, errStreamColumnKind):
+			return err
+		case err != nil:
+			// the query is executed again at the next tick, the stream only stops when nobody listens to it
+			logger.Warn("Failed to execute live query", "error", err)
+		case frame.Rows() > 0:
+			cursor = next
+			cache, err := data.FrameToJSONCache(frame)
+			if err != nil {
+				return err
+			}
+			include := data.IncludeAll
+			if cache.SameSchema(&prev) {
+				include = data.IncludeDataOnly
+			}
+			if err := sender.SendFrame(frame, include); err != nil {
+				return err
+			}
+			prev = cache
+		default:
+			// the first run of a stream without lastSeen sets its cursor without returning rows
+			cursor = next
+		}
+
+		select {
+		case <-ctx.Done():
+			logger.Debug("Stop streaming (context canceled)")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func streamDataQuery(queryJson StreamQueryJson, interval time.Duration) (backend.DataQuery, error) {
+	// newProcessCfg reads the format from the query, it is set by parseStreamQuery
+	queryModel, err := json.Marshal(queryJson.QueryJson)
+	if err != nil {
+		return backend.DataQuery{}, err
+	}
+
+	return backend.DataQuery{
+		JSON:     queryModel,
+		Interval: interval,
+	}, nil
+}
+
+// queryNewRows executes the query with the cursor and returns the rows after it along with the next cursor
+func (e *DataSourceHandler) queryNewRows(ctx context.Context, query backend.DataQuery, queryJson StreamQueryJson, cursor streamCursor) (*data.Frame, streamCursor, error) {
+	logger := e.log.FromContext(ctx)
+	now := time.Now()
+	timeRange := backend.TimeRange{From: now.Add(-query.Interval), To: now}
+
+	var lastSeen string
+	switch {
+	case cursor.unset:
+		// no row is after NULL whatever the type of the stream column, the empty result only tells that type
+		lastSeen = "NULL"
+	case cursor.numeric:
+		lastSeen = strconv.FormatFloat(cursor.number, 'f', -1, 64)
+	default:
+		// the data source specific macro renders the cursor as a time of the database
+		timeRange.From = cursor.time
+		lastSeen = "$__timeFrom()"
+	}
+	interpolatedQuery := strings.ReplaceAll(queryJson.RawSql, lastSeenMacro, lastSeen)
+	interpolatedQuery = Interpolate(query, timeRange, e.dsInfo.JsonData.TimeInterval, interpolatedQuery)
+	interpolatedQuery, err := e.macroEngine.Interpolate(&query, timeRange, interpolatedQuery)
+	if err != nil {
+		return nil, cursor, e.TransformQueryError(logger, err)
+	}
+
+	if e.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.queryTimeout)
+		defer cancel()
+	}
+
+	rows, err := e.db.QueryContext(ctx, interpolatedQuery)
+	if err != nil {
+		return nil, cursor, e.TransformQueryError(logger, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	qm, err := e.newProcessCfg(query, ctx, rows, interpolatedQuery)
+	if err != nil {
+		return nil, cursor, err
+	}
+
+	frame, err := e.frameFromRows(rows, sqlutil.ToConverters(e.queryResultTransformer.GetConverterList()...)...)
+	if err != nil {
+		return nil, cursor, err
+	}
+	if err := convertSQLTimeColumnsToEpochMS(frame, qm); err != nil {
+		return nil, cursor, err
+	}
+	if qm.Format == dataQueryFormatSeries && qm.timeIndex != -1 {
+		frame.Fields[qm.timeIndex].Name = data.TimeSeriesTimeFieldName
+	}
+
+	column := queryJson.StreamColumn
+	if column == "" {
+		column = e.timeColumnNames[0]
+	}
+	columnIdx := -1
+	for i, name := range qm.columnNames {
+		if name == column {
+			columnIdx = i
+			break
+		}
+	}
+	if columnIdx == -1 {
+		return nil, cursor, fmt.Errorf("stream column %q not found", column)
+	}
+
+	if cursor.unset {
+		next, err := startCursor(frame.Fields[columnIdx], column, cursor.time)
+		return frame.EmptyCopy(), next, err
+	}
+	return filterNewRows(frame, columnIdx, cursor)
+}
+
+// startCursor returns the cursor of a stream without lastSeen from the type of its stream column. Time streams start
+// at the time of the subscription, there is no such start for numeric columns so lastSeen must be set for them.
+func startCursor(field *data.Field, column string, start time.Time) (streamCursor, error) {
+	switch {
+	case field.Type().Time():
+		return streamCursor{time: start}, nil
+	case field.Type().Numeric():
+		return streamCursor{}, fmt.Errorf("%w: lastSeen must be set for the numeric stream column %q", errStreamColumnKind, column)
+	default:
+		return streamCursor{}, fmt.Errorf("%w: stream column %q must be a time or numeric column", errStreamColumnKind, column)
+	}
+}
+
+// filterNewRows returns the rows of the frame after the cursor, as macros may not render it precisely
+func filterNewRows(frame *data.Frame, columnIdx int, cursor streamCursor) (*data.Frame, streamCursor, error) {
+	newRows := frame.EmptyCopy()
+	next := cursor
+
+	field := frame.Fields[columnIdx]
+	for row := 0; row < frame.Rows(); row++ {
+		value, ok, err := cursorValueAt(field, row, cursor.numeric)
+		if err != nil {
+			return nil, cursor, err
+		}
+		if !ok || !value.after(cursor) {
+			continue
+		}
+		if value.after(next) {
+			next = value
+		}
+		for i, f := range frame.Fields {
+			newRows.Fields[i].Append(f.CopyAt(row))
+		}
+	}
+
+	return newRows, next, nil
+}
+
+func cursorValueAt(field *data.Field, row int, numeric bool) (streamCursor, bool, error) {
+	if !numeric {
+		switch v := field.At(row).(type) {
+		case time.Time:
+			return streamCursor{time: v}, true, nil
+		case *time.Time:
+			if v == nil {
+				return streamCursor{}, false, nil
+			}
+			return streamCursor{time: *v}, true, nil
+		default:
+			return streamCursor{}, false, errors.New("stream column is not a time column")
+		}
+	}
+
+	v, err := field.NullableFloatAt(row)
+	if err != nil {
+		return streamCursor{}, false, fmt.Errorf("stream column is not a numeric column: %w", err)
+	}
+	if v == nil {
+		return streamCursor{}, false, nil
+	}
+	return streamCursor{numeric: true, number: *v}, true, nil
+}
+
+func (c streamCursor) after(other streamCursor) bool {
+	if c.numeric {
+		return c.number > other.number
+	}
+	return c.time.After(other.time)
+}
+
+func (e *DataSourceHandler) parseStreamQuery(raw json.RawMessage) (StreamQueryJson, time.Duration, streamCursor, error) {
+	queryJson := StreamQueryJson{
+		QueryJson: QueryJson{Format: "table"},
+	}
+	if err := json.Unmarshal(raw, &queryJson); err != nil {
+		return queryJson, 0, streamCursor{}, fmt.Errorf("error unmarshal query json: %w", err)
+	}
+	if queryJson.Format != string(dataQueryFormatTable) && queryJson.Format != string(dataQueryFormatSeries) {
+		return queryJson, 0, streamCursor{}, fmt.Errorf("unrecognized query model format: %q", queryJson.Format)
+	}
+	if !strings.Contains(queryJson.RawSql, lastSeenMacro) {
+		return queryJson, 0, streamCursor{}, fmt.Errorf("live queries must filter on %s", lastSeenMacro)
+	}
+
+	interval := defaultStreamInterval
+	if queryJson.StreamInterval != "" {
+		var err error
+		interval, err = time.ParseDuration(queryJson.StreamInterval)
+		if err != nil {
+			return queryJson, 0, streamCursor{}, fmt.Errorf("invalid streamInterval: %w", err)
+		}
+	}
+	if interval < minStreamInterval {
+		interval = minStreamInterval
+	}
+
+	// the kind of the stream column is known after the first run, the stream starts at the current time for time columns
+	cursor := streamCursor{unset: true, time: time.Now()}
+	if len(queryJson.LastSeen) > 0 && string(queryJson.LastSeen) != "null" {
+		var lastSeen any
+		if err := json.Unmarshal(queryJson.LastSeen, &lastSeen); err != nil {
+			return queryJson, 0, streamCursor{}, fmt.Errorf("invalid lastSeen: %w", err)
+		}
+		switch v := lastSeen.(type) {
+		case float64:
+			cursor = streamCursor{numeric: true, number: v}
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return queryJson, 0, streamCursor{}, fmt.Errorf("invalid lastSeen: %w", err)
+			}
+			cursor = streamCursor{time: t}
+		default:
+			return queryJson, 0, streamCursor{}, fmt.Errorf("invalid lastSeen: expected a number or a time")
+		}
+	}
+
+	return queryJson, interval, cursor, nil
+}
@@ -0,0 +1,190 @@
+package sqleng
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng/util"
+)
+
+func TestRunStream(t *testing.T) {
+	interval := minStreamInterval
+	minStreamInterval = 10 * time.Millisecond
+	t.Cleanup(func() { minStreamInterval = interval })
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	// every connection has its own in-memory database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE logs (id INTEGER, msg TEXT); INSERT INTO logs VALUES (1, 'a'), (2, 'b'), (3, 'c')`)
+	require.NoError(t, err)
+
+	handler, err := NewQueryDataHandler("error", db, DataPluginConfiguration{RowLimit: 1000}, &testQueryResultTransformer{}, &testMacroEngine{},
+		backend.NewLoggerWith("logger", "test"))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sender := &testStreamSender{onSend: func(frames int) {
+		if frames == 1 {
+			_, err := db.Exec(`INSERT INTO logs VALUES (4, 'd'), (5, 'e')`)
+			require.NoError(t, err)
+			return
+		}
+		cancel()
+	}}
+
+	query := `{"rawSql": "SELECT id, msg FROM logs WHERE id > $__lastSeen ORDER BY id", "format": "table", "streamColumn": "id", "streamInterval": "10ms", "lastSeen": 1}`
+	require.NoError(t, handler.runStream(ctx, json.RawMessage(query), sender))
+
+	require.Len(t, sender.frames, 2)
+	assert.Equal(t, data.IncludeAll, sender.includes[0])
+	assert.Equal(t, []string{"b", "c"}, stringValues(sender.frames[0].Fields[1]))
+	assert.Equal(t, data.IncludeDataOnly, sender.includes[1])
+	assert.Equal(t, []string{"d", "e"}, stringValues(sender.frames[1].Fields[1]))
+}
+
+func TestSubscribeStream(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	handler, err := NewQueryDataHandler("error", db, DataPluginConfiguration{RowLimit: 1000}, &testQueryResultTransformer{}, &testMacroEngine{},
+		backend.NewLoggerWith("logger", "test"))
+	require.NoError(t, err)
+
+	query := json.RawMessage(`{"rawSql": "SELECT id, msg FROM logs WHERE id > $__lastSeen ORDER BY id", "format": "table", "streamColumn": "id", "lastSeen": 1}`)
+	hash := sha256.Sum256(query)
+	path := "stream/" + hex.EncodeToString(hash[:])
+
+	resp, err := handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{Path: path, Data: query})
+	require.NoError(t, err)
+	assert.Equal(t, backend.SubscribeStreamStatusOK, resp.Status)
+
+	// the channel of another query can't be joined with a different query
+	for _, path := range []string{"stream/logs", streamPath(json.RawMessage(`{"rawSql": "SELECT 1"}`))} {
+		resp, err = handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{Path: path, Data: query})
+		require.Error(t, err)
+		assert.Equal(t, backend.SubscribeStreamStatusNotFound, resp.Status)
+	}
+}
+
+func TestFilterNewRows(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	frame := data.NewFrame("",
+		data.NewField("time", nil, []*time.Time{util.Pointer(start), nil, util.Pointer(start.Add(2 * time.Second)), util.Pointer(start.Add(time.Second))}),
+		data.NewField("value", nil, []float64{1, 2, 3, 4}),
+	)
+
+	t.Run("should only keep the rows after the cursor", func(t *testing.T) {
+		rows, next, err := filterNewRows(frame, 0, streamCursor{time: start})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, rows.Rows())
+		assert.Equal(t, 3.0, rows.Fields[1].At(0))
+		assert.Equal(t, start.Add(2*time.Second), next.time)
+	})
+
+	t.Run("should fail on a numeric cursor for a time column", func(t *testing.T) {
+		_, _, err := filterNewRows(frame, 0, streamCursor{numeric: true})
+		require.Error(t, err)
+	})
+}
+
+func TestStartCursor(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("should start a time column at the subscription", func(t *testing.T) {
+		cursor, err := startCursor(data.NewField("time", nil, []*time.Time{}), "time", start)
+		require.NoError(t, err)
+
+		assert.False(t, cursor.unset)
+		assert.False(t, cursor.numeric)
+		assert.Equal(t, start, cursor.time)
+	})
+
+	t.Run("should require lastSeen for a numeric column", func(t *testing.T) {
+		_, err := startCursor(data.NewField("id", nil, []int64{}), "id", start)
+		require.ErrorIs(t, err, errStreamColumnKind)
+		assert.Contains(t, err.Error(), "lastSeen")
+	})
+
+	t.Run("should fail on other columns", func(t *testing.T) {
+		_, err := startCursor(data.NewField("msg", nil, []string{}), "msg", start)
+		require.ErrorIs(t, err, errStreamColumnKind)
+	})
+}
+
+func TestParseStreamQuery(t *testing.T) {
+	handler := &DataSourceHandler{}
+
+	t.Run("should require the last seen macro", func(t *testing.T) {
+		_, _, _, err := handler.parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM logs", "format": "table"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("should parse a time cursor", func(t *testing.T) {
+		_, interval, cursor, err := handler.parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM logs WHERE time > $__lastSeen", "lastSeen": "2024-01-01T00:00:00Z"}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, defaultStreamInterval, interval)
+		assert.False(t, cursor.numeric)
+		assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), cursor.time)
+	})
+
+	t.Run("should leave the cursor unset without lastSeen", func(t *testing.T) {
+		_, _, cursor, err := handler.parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM logs WHERE id > $__lastSeen", "streamColumn": "id"}`))
+		require.NoError(t, err)
+
+		assert.True(t, cursor.unset)
+	})
+
+	t.Run("should not run queries more often than the minimum interval", func(t *testing.T) {
+		_, interval, _, err := handler.parseStreamQuery(json.RawMessage(`{"rawSql": "SELECT * FROM logs WHERE id > $__lastSeen", "streamInterval": "1ms", "lastSeen": 10}`))
+		require.NoError(t, err)
+
+		assert.Equal(t, minStreamInterval, interval)
+	})
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+type testStreamSender struct {
+	frames   []*data.Frame
+	includes []data.FrameInclude
+	onSend   func(frames int)
+}
+
+func (s *testStreamSender) SendFrame(frame *data.Frame, include data.FrameInclude) error {
+	s.frames = append(s.frames, frame)
+	s.includes = append(s.includes, include)
+	s.onSend(len(s.frames))
+	return nil
+}
+
+func stringValues(field *data.Field) []string {
+	values := make([]string, field.Len())
+	for i := range values {
+		v, _ := field.ConcreteAt(i)
+		values[i] = v.(string)
+	}
+	return values
+}
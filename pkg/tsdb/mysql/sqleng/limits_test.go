@@ -0,0 +1,70 @@
+package sqleng
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrameFromRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, err = db.Exec(`CREATE TABLE logs (id INTEGER, msg TEXT)`)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err = db.Exec(`INSERT INTO logs VALUES (?, 'abcdefghij')`, i)
+		require.NoError(t, err)
+	}
+
+	query := func(t *testing.T, handler *DataSourceHandler) *data.Frame {
+		t.Helper()
+		rows, err := db.Query(`SELECT id, msg FROM logs ORDER BY id`)
+		require.NoError(t, err)
+		defer func() { _ = rows.Close() }()
+
+		frame, err := handler.frameFromRows(rows)
+		require.NoError(t, err)
+		return frame
+	}
+
+	t.Run("should return all the rows without limits", func(t *testing.T) {
+		frame := query(t, &DataSourceHandler{})
+
+		assert.Equal(t, 10, frame.Rows())
+		assert.Nil(t, frame.Meta)
+	})
+
+	t.Run("should stop at the row limit", func(t *testing.T) {
+		truncated := testutil.ToFloat64(truncatedQueriesTotal.WithLabelValues(limitReasonRows))
+		frame := query(t, &DataSourceHandler{rowLimit: 3})
+
+		assert.Equal(t, 3, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Equal(t, data.NoticeSeverityWarning, frame.Meta.Notices[0].Severity)
+		assert.Equal(t, truncated+1, testutil.ToFloat64(truncatedQueriesTotal.WithLabelValues(limitReasonRows)))
+	})
+
+	t.Run("should stop before the byte limit is exceeded", func(t *testing.T) {
+		// every row is 8 bytes for the id and 10 for the message
+		frame := query(t, &DataSourceHandler{byteLimit: 50})
+
+		assert.Equal(t, 2, frame.Rows())
+		require.Len(t, frame.Meta.Notices, 1)
+		assert.Contains(t, frame.Meta.Notices[0].Text, "result size limit of 50 bytes")
+	})
+}
+
+func TestEffectiveRowLimit(t *testing.T) {
+	assert.Equal(t, int64(100), effectiveRowLimit(1000, 100))
+	assert.Equal(t, int64(1000), effectiveRowLimit(1000, 10000))
+	assert.Equal(t, int64(1000), effectiveRowLimit(1000, 0))
+	assert.Equal(t, int64(100), effectiveRowLimit(0, 100))
+}
@@ -60,6 +60,11 @@ type JsonData struct {
 	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
 	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
 	AuthenticationType      string `json:"authenticationType"`
+	// RowLimit, ByteLimit and QueryTimeout limit the results of the queries, zero is no limit. The row limit
+	// can't be higher than the one of the configuration.
+	RowLimit     int64 `json:"rowLimit"`
+	ByteLimit    int64 `json:"byteLimit"`
+	QueryTimeout int   `json:"queryTimeout"`
 }
 
 type DataSourceInfo struct {
@@ -89,6 +94,8 @@ type DataSourceHandler struct {
 	log                    log.Logger
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
+	byteLimit              int64
+	queryTimeout           time.Duration
 	userError              string
 }
 
@@ -123,7 +130,9 @@ func NewQueryDataHandler(userFacingDefaultError string, db *sql.DB, config DataP
 		timeColumnNames:        []string{"time"},
 		log:                    log,
 		dsInfo:                 config.DSInfo,
-		rowLimit:               config.RowLimit,
+		rowLimit:               effectiveRowLimit(config.RowLimit, config.DSInfo.JsonData.RowLimit),
+		byteLimit:              config.DSInfo.JsonData.ByteLimit,
+		queryTimeout:           time.Duration(config.DSInfo.JsonData.QueryTimeout) * time.Second,
 		userError:              userFacingDefaultError,
 	}
 
@@ -258,8 +267,17 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	if e.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		queryContext, cancel = context.WithTimeout(queryContext, e.queryTimeout)
+		defer cancel()
+	}
+
 	rows, err := e.db.QueryContext(queryContext, interpolatedQuery)
 	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && e.queryTimeout > 0 {
+			err = fmt.Errorf("query time limit of %v exceeded: %w", e.queryTimeout, err)
+		}
 		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
 		return
 	}
@@ -277,7 +295,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	frame, err := e.frameFromRows(rows, sqlutil.ToConverters(stringConverters...)...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
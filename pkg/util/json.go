@@ -70,6 +70,13 @@ func SearchJSONForStringAttr(attributePath string, data any) (string, error) {
 	return "", nil
 }
 
+// SearchJSONForAttr searches for an attribute in a JSON object and returns its value, whatever its type.
+// The attributePath parameter is a JMESPath expression that specifies the path to the attribute.
+// The data parameter is the JSON object that we're searching. It can be a byte slice or a go type.
+func SearchJSONForAttr(attributePath string, data any) (any, error) {
+	return searchJSONForAttr(attributePath, data)
+}
+
 // searchJSONForAttr searches for a specific attribute in a JSON object.
 // The attributePath parameter is a string that specifies the path to the attribute.
 // The data parameter is the JSON object that we're searching.
@@ -95,6 +95,47 @@ func RemoteAddr(req *http.Request) string {
 	return addr
 }
 
+// ClientIP returns the IP address of the client of the request, without port nor brackets. The X-Real-IP and
+// X-Forwarded-For headers are only used when the request comes from one of the trusted proxies, otherwise they
+// could be set by the client itself. In X-Forwarded-For the last address which is not a trusted proxy is used.
+func ClientIP(req *http.Request, trustedProxies []*net.IPNet) string {
+	peer := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	peerIP := net.ParseIP(strings.Trim(peer, "[]"))
+	if peerIP == nil {
+		return peer
+	}
+	if !isTrustedProxy(peerIP, trustedProxies) {
+		return peerIP.String()
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(req.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	forwarded := strings.Split(req.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if ip == nil {
+			break
+		}
+		if i == 0 || !isTrustedProxy(ip, trustedProxies) {
+			return ip.String()
+		}
+	}
+	return peerIP.String()
+}
+
+func isTrustedProxy(ip net.IP, trustedProxies []*net.IPNet) bool {
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 const (
 	headerContentType = "Content-Type"
 	contentTypeJSON   = "application/json; charset=UTF-8"
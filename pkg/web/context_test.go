@@ -1,6 +1,7 @@
 package web
 
 import (
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -98,6 +99,36 @@ func TestContext_RemoteAddr(t *testing.T) {
 	}
 }
 
+func TestClientIP(t *testing.T) {
+	_, proxies, err := net.ParseCIDR("10.0.0.0/8")
+	assert.NoError(t, err)
+	trustedProxies := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{name: "connection address", remoteAddr: "192.168.1.5:5000", want: "192.168.1.5"},
+		{name: "ipv6 connection address without brackets", remoteAddr: "[::1]:51299", want: "::1"},
+		{name: "headers of untrusted clients are ignored", remoteAddr: "192.168.1.5:5000", headers: map[string]string{"X-Real-IP": "1.2.3.4", "X-Forwarded-For": "1.2.3.4"}, want: "192.168.1.5"},
+		{name: "x-real-ip of trusted proxies", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Real-IP": "1.2.3.4"}, want: "1.2.3.4"},
+		{name: "last untrusted x-forwarded-for address", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "6.6.6.6, 1.2.3.4, 10.0.0.2"}, want: "1.2.3.4"},
+		{name: "invalid x-forwarded-for address", remoteAddr: "10.0.0.1:5000", headers: map[string]string{"X-Forwarded-For": "not an ip, 10.0.0.2"}, want: "10.0.0.1"},
+		{name: "trusted proxy without headers", remoteAddr: "10.0.0.1:5000", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &http.Request{RemoteAddr: tt.remoteAddr, Header: http.Header{}}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, ClientIP(req, trustedProxies))
+		})
+	}
+}
+
 func TestContext_noHandler(t *testing.T) {
 	recorder := httptest.NewRecorder()
 